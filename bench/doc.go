@@ -0,0 +1,9 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package bench has benchmarks of the rendering of GoGi, which are run with
+go test -bench, in addition to the notes on profiling in bench.md.
+*/
+package bench
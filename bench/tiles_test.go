@@ -0,0 +1,102 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bench
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+)
+
+// size of a full window redraw on a 4K screen
+var benchSize = image.Point{X: 3840, Y: 2160}
+
+// renderScene renders a diagram of filled and stroked shapes with labels,
+// over the whole image, as in a large SVG drawing
+func renderScene(rs *girl.State, fsty *gist.Font) {
+	pc := &girl.Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(benchSize)
+	girl.OpenFont(fsty, &pc.UnContext)
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	bg, _ := gist.ColorFromName("white")
+	fill, _ := gist.ColorFromName("lightblue")
+	line, _ := gist.ColorFromName("navy")
+	rs.PushBounds(image.Rectangle{Max: benchSize})
+	pc.FillBoxColor(rs, mat32.Vec2{}, mat32.NewVec2FmPoint(benchSize), bg)
+	for y := 0; y < benchSize.Y; y += 120 {
+		for x := 0; x < benchSize.X; x += 160 {
+			pc.FillStyle.SetColor(fill)
+			pc.StrokeStyle.SetColor(line)
+			pc.StrokeStyle.Width.Dots = 2
+			pc.DrawRoundedRectangle(rs, float32(x+10), float32(y+10), 140, 70, 8)
+			pc.FillStrokeClear(rs)
+			pc.FillStyle.SetColor(nil)
+			pc.MoveTo(rs, float32(x+80), float32(y+80))
+			pc.CubicTo(rs, float32(x+80), float32(y+110), float32(x+160), float32(y+100), float32(x+240), float32(y+130))
+			pc.Stroke(rs)
+			txt := &girl.Text{}
+			txt.SetString(fmt.Sprintf("node %d, %d", x, y), fsty, &pc.UnContext, tsty, true, 0, 1)
+			txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: 130, Y: 30})
+			txt.Render(rs, mat32.Vec2{X: float32(x + 20), Y: float32(y + 30)})
+		}
+	}
+	rs.PopBounds()
+}
+
+// benchPrefs are the minimal gist.Prefs needed for rendering text
+type benchPrefs struct {
+	font gist.Color
+}
+
+func (pf *benchPrefs) PrefColor(name string) *gist.Color {
+	return &pf.font
+}
+
+func (pf *benchPrefs) PrefFontFamily() string {
+	return "Go"
+}
+
+func benchSetup() (*girl.State, *gist.Font) {
+	prefs := &benchPrefs{}
+	prefs.font.SetColor(color.Black)
+	gist.ThePrefs = prefs
+	girl.FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	girl.FontLibrary.Init()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	rs := &girl.State{}
+	rs.Init(benchSize.X, benchSize.Y, image.NewRGBA(image.Rectangle{Max: benchSize}))
+	return rs, fsty
+}
+
+// BenchmarkRenderSerial renders directly into the image, on one core
+func BenchmarkRenderSerial(b *testing.B) {
+	rs, fsty := benchSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		renderScene(rs, fsty)
+	}
+}
+
+// BenchmarkRenderTiles records the rendering and rasterizes it in tiles,
+// on all of the cores
+func BenchmarkRenderTiles(b *testing.B) {
+	rs, fsty := benchSetup()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rs.Vector = girl.NewTileVector(benchSize)
+		renderScene(rs, fsty)
+		vc := rs.Vector
+		rs.Vector = nil
+		vc.RasterTiles(rs.Image, rs.Image.Bounds(), girl.TileSize)
+	}
+}
@@ -0,0 +1,723 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+)
+
+// AnimFrameMSec is the number of milliseconds between animation frames,
+// while any animations are running in a window
+var AnimFrameMSec = 16
+
+/////////////////////////////////////////////////////////////////////////////
+//   Easing
+
+// EaseFunc is an easing (timing) function for animations, which maps the
+// linear progress of the animation in time, from 0 to 1, onto the progress
+// of the animated value, which is also 0 at the start and 1 at the end.
+type EaseFunc func(t float32) float32
+
+// EaseLinear is linear easing: the value changes at a constant rate
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad is quadratic easing that accelerates from zero velocity
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad is quadratic easing that decelerates to zero velocity
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad is quadratic easing that accelerates and then decelerates
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic is cubic easing that accelerates from zero velocity
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic is cubic easing that decelerates to zero velocity
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic is cubic easing that accelerates and then decelerates
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return 0.5*t*t*t + 1
+}
+
+// CubicBezier returns an easing function defined by a cubic Bezier curve
+// from (0,0) to (1,1) with given control points, as in the CSS
+// cubic-bezier(x1, y1, x2, y2) timing function.  The x values must be
+// in the 0-1 range.
+func CubicBezier(x1, y1, x2, y2 float32) EaseFunc {
+	x1 = mat32.Clamp(x1, 0, 1)
+	x2 = mat32.Clamp(x2, 0, 1)
+	bez := func(s, p1, p2 float32) float32 {
+		is := 1 - s
+		return 3*is*is*s*p1 + 3*is*s*s*p2 + s*s*s
+	}
+	return func(t float32) float32 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		// x is monotonic in s, so bisection always converges
+		lo, hi := float32(0), float32(1)
+		s := t
+		for i := 0; i < 24; i++ {
+			x := bez(s, x1, x2)
+			if mat32.Abs(x-t) < 1.0e-5 {
+				break
+			}
+			if x < t {
+				lo = s
+			} else {
+				hi = s
+			}
+			s = 0.5 * (lo + hi)
+		}
+		return bez(s, y1, y2)
+	}
+}
+
+var (
+	// EaseCSS is the CSS ease timing function, which is the default for
+	// animations and transitions
+	EaseCSS = CubicBezier(0.25, 0.1, 0.25, 1)
+
+	// EaseInCSS is the CSS ease-in timing function
+	EaseInCSS = CubicBezier(0.42, 0, 1, 1)
+
+	// EaseOutCSS is the CSS ease-out timing function
+	EaseOutCSS = CubicBezier(0, 0, 0.58, 1)
+
+	// EaseInOutCSS is the CSS ease-in-out timing function
+	EaseInOutCSS = CubicBezier(0.42, 0, 0.58, 1)
+)
+
+// EaseByName returns the easing function for given CSS timing function
+// name: linear, ease, ease-in, ease-out, ease-in-out, or
+// cubic-bezier(x1, y1, x2, y2) -- see gist.Transition.
+func EaseByName(nm string) (EaseFunc, error) {
+	switch nm {
+	case "linear":
+		return EaseLinear, nil
+	case "", "ease":
+		return EaseCSS, nil
+	case "ease-in":
+		return EaseInCSS, nil
+	case "ease-out":
+		return EaseOutCSS, nil
+	case "ease-in-out":
+		return EaseInOutCSS, nil
+	}
+	if strings.HasPrefix(nm, "cubic-bezier(") && strings.HasSuffix(nm, ")") {
+		args := strings.Split(strings.TrimSuffix(strings.TrimPrefix(nm, "cubic-bezier("), ")"), ",")
+		if len(args) == 4 {
+			var p [4]float32
+			var err error
+			for i, a := range args {
+				var f float64
+				f, err = strconv.ParseFloat(strings.TrimSpace(a), 32)
+				if err != nil {
+					break
+				}
+				p[i] = float32(f)
+			}
+			if err == nil {
+				return CubicBezier(p[0], p[1], p[2], p[3]), nil
+			}
+		}
+	}
+	return EaseCSS, fmt.Errorf("gi.EaseByName: easing function: %q not recognized", nm)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Anim
+
+// Anim is an animation that runs on the frame clock of a Window (see
+// Animator), calling Update on each frame with the eased progress of the
+// animation from 0 to 1, from the window event loop.  Update is always
+// called with 1 at the end, after which Done is called.
+type Anim struct {
+	Name     string               `desc:"name of the animation -- starting an animation on the same Target with the same non-empty Name cancels any existing one, e.g., use the name of the property being animated"`
+	Target   ki.Ki                `desc:"node being animated, if any -- the animation is cancelled if it is deleted or destroyed"`
+	Delay    time.Duration        `desc:"delay after starting before the animation begins"`
+	Duration time.Duration        `desc:"duration of the animation"`
+	Ease     EaseFunc             `desc:"easing function -- EaseCSS is used if nil"`
+	Update   func(t float32)      `desc:"function called on each frame with the eased progress, from 0 to 1, which must update the animated values and trigger re-rendering of the target"`
+	Done     func(completed bool) `desc:"optional function called when the animation is over -- completed is false if it was cancelled"`
+	start    time.Time
+	am       *Animator
+	over     bool
+	style    *styleTrans
+}
+
+// Progress returns the linear progress of the animation in time at given
+// time, from 0 to 1, which is negative during the delay.
+func (an *Anim) Progress(now time.Time) float32 {
+	el := now.Sub(an.start) - an.Delay
+	if el < 0 {
+		return -1
+	}
+	if an.Duration <= 0 || el >= an.Duration {
+		return 1
+	}
+	return float32(el) / float32(an.Duration)
+}
+
+// IsOver returns true if the animation has completed or been cancelled
+func (an *Anim) IsOver() bool {
+	if an.am == nil {
+		return an.over
+	}
+	an.am.Mu.Lock()
+	defer an.am.Mu.Unlock()
+	return an.over
+}
+
+// Cancel cancels the animation if it is still running, calling Done with
+// completed = false.  The animated values are left where they are.
+func (an *Anim) Cancel() {
+	if an.am != nil {
+		an.am.Cancel(an)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Animator
+
+// Animator runs the animations for a Window, on a frame clock that sends an
+// event through the window event loop every AnimFrameMSec while any
+// animations are running, so that all updates happen in the event loop.
+type Animator struct {
+	Win         *Window    `desc:"the window that the animations run in"`
+	ManualClock bool       `desc:"if true, animation time only advances by calling Advance, instead of in real time -- e.g., for testing animations deterministically"`
+	Now         time.Time  `desc:"animation time of the last frame"`
+	Anims       []*Anim    `desc:"the running animations"`
+	Mu          sync.Mutex `view:"-" desc:"mutex protecting the animations"`
+	ticking     bool
+	pending     int32
+}
+
+// winAnimFrame is sent as the data of a CustomEvent by the Animator
+// frame clock, and is handled directly by ProcessEvent.
+type winAnimFrame struct{}
+
+// Animate starts given animation in this window -- see Animator.Start.
+func (w *Window) Animate(an *Anim) *Anim {
+	return w.Anims.Start(an)
+}
+
+// animFrameEvent handles winAnimFrame events from ProcessEvent,
+// returning true if it was one.
+func (w *Window) animFrameEvent(evi oswin.Event) bool {
+	ce, ok := evi.(*oswin.CustomEvent)
+	if !ok {
+		return false
+	}
+	if _, ok := ce.Data.(*winAnimFrame); !ok {
+		return false
+	}
+	atomic.StoreInt32(&w.Anims.pending, 0)
+	if !w.Anims.ManualClock {
+		w.Anims.Step(time.Now())
+	}
+	return true
+}
+
+// now returns the current animation time
+func (am *Animator) now() time.Time {
+	if am.ManualClock {
+		if am.Now.IsZero() {
+			am.Now = time.Now()
+		}
+		return am.Now
+	}
+	return time.Now()
+}
+
+// Start starts given animation, and returns it.  If it has a Target and
+// Name, any other running animation with the same Target and Name is
+// cancelled.  Can be called from any goroutine.
+func (am *Animator) Start(an *Anim) *Anim {
+	if an.Ease == nil {
+		an.Ease = EaseCSS
+	}
+	am.Mu.Lock()
+	var cancel *Anim
+	if an.Target != nil && an.Name != "" {
+		cancel = am.find(an.Target, an.Name)
+		if cancel != nil {
+			am.remove(cancel)
+		}
+	}
+	an.am = am
+	an.over = false
+	an.start = am.now()
+	am.Anims = append(am.Anims, an)
+	if !am.ManualClock && !am.ticking && am.Win != nil {
+		am.ticking = true
+		go am.tick()
+	}
+	am.Mu.Unlock()
+	if cancel != nil && cancel.Done != nil {
+		cancel.Done(false)
+	}
+	return an
+}
+
+// Find returns the running animation with given target and name, or nil
+func (am *Animator) Find(target ki.Ki, name string) *Anim {
+	am.Mu.Lock()
+	defer am.Mu.Unlock()
+	return am.find(target, name)
+}
+
+func (am *Animator) find(target ki.Ki, name string) *Anim {
+	for _, an := range am.Anims {
+		if an.Target == target && an.Name == name {
+			return an
+		}
+	}
+	return nil
+}
+
+// remove removes given animation from the list, under the lock
+func (am *Animator) remove(an *Anim) {
+	an.over = true
+	for i, a := range am.Anims {
+		if a == an {
+			am.Anims = append(am.Anims[:i], am.Anims[i+1:]...)
+			return
+		}
+	}
+}
+
+// Cancel cancels given animation if it is still running, calling its
+// Done function with completed = false.
+func (am *Animator) Cancel(an *Anim) {
+	am.Mu.Lock()
+	if an.over {
+		am.Mu.Unlock()
+		return
+	}
+	am.remove(an)
+	am.Mu.Unlock()
+	if an.Done != nil {
+		an.Done(false)
+	}
+}
+
+// CancelTarget cancels all running animations for given target
+func (am *Animator) CancelTarget(target ki.Ki) {
+	am.Mu.Lock()
+	var cans []*Anim
+	for _, an := range am.Anims {
+		if an.Target == target {
+			cans = append(cans, an)
+		}
+	}
+	am.Mu.Unlock()
+	for _, an := range cans {
+		am.Cancel(an)
+	}
+}
+
+// IsAnimating returns true if there are any running animations
+func (am *Animator) IsAnimating() bool {
+	am.Mu.Lock()
+	defer am.Mu.Unlock()
+	return len(am.Anims) > 0
+}
+
+// Step advances all of the running animations to given time, calling their
+// Update functions, and Done for those that have completed.  This is
+// normally called by the frame clock, and must be called from the window
+// event loop.
+func (am *Animator) Step(now time.Time) {
+	am.Mu.Lock()
+	am.Now = now
+	anims := make([]*Anim, len(am.Anims))
+	copy(anims, am.Anims)
+	am.Mu.Unlock()
+	for _, an := range anims {
+		if an.IsOver() { // cancelled by an earlier update
+			continue
+		}
+		if tg := an.Target; tg != nil && (tg.This() == nil || tg.IsDeleted() || tg.IsDestroyed()) {
+			am.Cancel(an)
+			continue
+		}
+		t := an.Progress(now)
+		if t < 0 {
+			continue
+		}
+		if an.Update != nil {
+			an.Update(an.Ease(t))
+		}
+		if t < 1 {
+			continue
+		}
+		am.Mu.Lock()
+		over := an.over
+		am.remove(an)
+		am.Mu.Unlock()
+		if !over && an.Done != nil {
+			an.Done(true)
+		}
+	}
+}
+
+// Advance advances the animation time by given duration and runs a frame
+// (see Step) -- this is for use with ManualClock, and must be called from
+// the window event loop, e.g., using Window.SendSyncEvent.
+func (am *Animator) Advance(d time.Duration) {
+	am.Mu.Lock()
+	now := am.now().Add(d)
+	am.Mu.Unlock()
+	am.Step(now)
+}
+
+// tick is the frame clock goroutine, which sends a frame event to the
+// window every AnimFrameMSec, as long as there are animations running.
+func (am *Animator) tick() {
+	tk := time.NewTicker(time.Duration(AnimFrameMSec) * time.Millisecond)
+	defer tk.Stop()
+	for range tk.C {
+		am.Mu.Lock()
+		if len(am.Anims) == 0 || am.ManualClock || am.Win.IsClosed() {
+			am.ticking = false
+			am.Mu.Unlock()
+			return
+		}
+		am.Mu.Unlock()
+		if atomic.CompareAndSwapInt32(&am.pending, 0, 1) { // don't pile up frames
+			am.Win.SendCustomEvent(&winAnimFrame{})
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Animation of specific values
+
+// LerpColor returns the color that is t of the way from color a to b
+func LerpColor(a, b gist.Color, t float32) gist.Color {
+	return a.Blend(100*t, b)
+}
+
+// LerpColorSpec returns the color spec that is t of the way from a to b.
+// Solid colors, and gradients of the same kind with the same number of
+// stops, are interpolated, with a solid color acting as a gradient of
+// that color.  Otherwise, b is returned.
+func LerpColorSpec(a, b *gist.ColorSpec, t float32) gist.ColorSpec {
+	aSolid := a.Source == gist.SolidColor || a.Gradient == nil
+	bSolid := b.Source == gist.SolidColor || b.Gradient == nil
+	cs := *b
+	if aSolid && bSolid {
+		cs.SetColor(LerpColor(a.Color, b.Color, t))
+		return cs
+	}
+	ag, bg := a.Gradient, b.Gradient
+	switch {
+	case aSolid:
+		ag = solidGradient(bg, a.Color)
+	case bSolid:
+		bg = solidGradient(ag, b.Color)
+		cs.Source = a.Source
+	}
+	if len(ag.Stops) != len(bg.Stops) || ag.IsRadial != bg.IsRadial || (!aSolid && !bSolid && a.Source != b.Source) {
+		return *b
+	}
+	g := *bg
+	g.Stops = make([]rasterx.GradStop, len(bg.Stops))
+	for i := range g.Stops {
+		as, bs := &ag.Stops[i], &bg.Stops[i]
+		var ac, bc gist.Color
+		ac.SetColor(as.StopColor)
+		bc.SetColor(bs.StopColor)
+		tf := float64(t)
+		g.Stops[i] = rasterx.GradStop{StopColor: LerpColor(ac, bc, t), Offset: as.Offset + tf*(bs.Offset-as.Offset), Opacity: as.Opacity + tf*(bs.Opacity-as.Opacity)}
+	}
+	for i := range g.Points {
+		g.Points[i] = ag.Points[i] + float64(t)*(bg.Points[i]-ag.Points[i])
+	}
+	cs.Color = LerpColor(a.Color, b.Color, t)
+	cs.Gradient = &g
+	return cs
+}
+
+// solidGradient returns a copy of given gradient with all stops
+// set to given solid color
+func solidGradient(gr *rasterx.Gradient, clr gist.Color) *rasterx.Gradient {
+	g := *gr
+	g.Stops = make([]rasterx.GradStop, len(gr.Stops))
+	for i, st := range gr.Stops {
+		g.Stops[i] = rasterx.GradStop{StopColor: clr, Offset: st.Offset, Opacity: 1}
+	}
+	return &g
+}
+
+// LerpUnits returns the value that is t of the way from value a to b,
+// including the Dots if both have been computed -- a is converted
+// to the units of b if they differ, using given context (if non-nil),
+// and calc() expressions are interpolated in dots.
+func LerpUnits(a, b units.Value, t float32, uc *units.Context) units.Value {
+	if (a.Calc != nil || b.Calc != nil) && uc != nil {
+		a = a.Convert(units.Dot, uc)
+		b = b.Convert(units.Dot, uc)
+	} else if a.Un != b.Un && uc != nil {
+		a = a.Convert(b.Un, uc)
+	}
+	v := b
+	v.Val = a.Val + t*(b.Val-a.Val)
+	v.Dots = a.Dots + t*(b.Dots-a.Dots)
+	return v
+}
+
+// LerpSideValues returns the values that are t of the way from a to b,
+// for each side, as in LerpUnits
+func LerpSideValues(a, b gist.SideValues, t float32, uc *units.Context) gist.SideValues {
+	return gist.SideValues{Top: LerpUnits(a.Top, b.Top, t, uc), Right: LerpUnits(a.Right, b.Right, t, uc),
+		Bottom: LerpUnits(a.Bottom, b.Bottom, t, uc), Left: LerpUnits(a.Left, b.Left, t, uc)}
+}
+
+// LerpSideColors returns the colors that are t of the way from a to b,
+// for each side, as in LerpColor
+func LerpSideColors(a, b gist.SideColors, t float32) gist.SideColors {
+	return gist.SideColors{Top: LerpColor(a.Top, b.Top, t), Right: LerpColor(a.Right, b.Right, t),
+		Bottom: LerpColor(a.Bottom, b.Bottom, t), Left: LerpColor(a.Left, b.Left, t)}
+}
+
+// LerpShadows returns the shadows that are t of the way from a to b, each
+// as in LerpUnits and LerpColor.  As in CSS, the shorter list is padded
+// with transparent shadows of zero size, so shadows fade in and out, and
+// the shadows whose inset differs are not interpolated, but taken from b.
+func LerpShadows(a, b []gist.Shadow, t float32) []gist.Shadow {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	shs := make([]gist.Shadow, n)
+	for i := range shs {
+		var as, bs gist.Shadow
+		if i < len(a) {
+			as = a[i]
+		}
+		if i < len(b) {
+			bs = b[i]
+		}
+		if i >= len(a) {
+			as.Inset = bs.Inset
+		}
+		if i >= len(b) {
+			bs.Inset = as.Inset
+		}
+		if as.Inset != bs.Inset {
+			shs[i] = bs
+			continue
+		}
+		shs[i] = gist.Shadow{HOffset: LerpUnits(as.HOffset, bs.HOffset, t, nil), VOffset: LerpUnits(as.VOffset, bs.VOffset, t, nil),
+			Blur: LerpUnits(as.Blur, bs.Blur, t, nil), Spread: LerpUnits(as.Spread, bs.Spread, t, nil),
+			Color: LerpColor(as.Color, bs.Color, t), Inset: bs.Inset}
+	}
+	return shs
+}
+
+// PropLerpFunc returns a function that interpolates between given from and
+// to values of a style property, as used in ki.Props, for use in
+// animations.  Colors (gist.Color, color.Color, or color strings), units
+// values (units.Value or strings such as "2em") and numbers are supported.
+func PropLerpFunc(from, to interface{}) (func(t float32) interface{}, error) {
+	isClr := func(v interface{}) bool {
+		switch vt := v.(type) {
+		case gist.Color, *gist.Color, color.Color:
+			return true
+		case string:
+			_, err := gist.ColorFromString(vt, nil)
+			return err == nil
+		}
+		return false
+	}
+	isNum := func(v interface{}) bool {
+		if _, ok := v.(units.Value); ok {
+			return false
+		}
+		_, ok := kit.ToFloat32(v)
+		return ok
+	}
+	switch {
+	case isClr(from) && isClr(to):
+		var fc, tc gist.Color
+		fc.SetIFace(from, nil, "")
+		tc.SetIFace(to, nil, "")
+		return func(t float32) interface{} { return LerpColor(fc, tc, t) }, nil
+	case isNum(from) && isNum(to):
+		fv, _ := kit.ToFloat32(from)
+		tv, _ := kit.ToFloat32(to)
+		return func(t float32) interface{} { return fv + t*(tv-fv) }, nil
+	}
+	var fv, tv units.Value
+	if err := fv.SetIFace(from, ""); err != nil {
+		return nil, err
+	}
+	if err := tv.SetIFace(to, ""); err != nil {
+		return nil, err
+	}
+	if fv.Un != tv.Un {
+		return nil, fmt.Errorf("gi.PropLerpFunc: from value: %v and to value: %v have different units", fv, tv)
+	}
+	return func(t float32) interface{} { return LerpUnits(fv, tv, t, nil) }, nil
+}
+
+// AnimateProp animates the style property with given name of this node,
+// setting it as a property on the node on each frame, from given from
+// value (or the current property value if nil) to the to value, which can
+// be colors, sizes, or numbers, e.g., opacity -- see PropLerpFunc.
+// The node is fully re-rendered on each frame, so this is best for
+// relatively small nodes.
+func (nb *Node2DBase) AnimateProp(prop string, from, to interface{}, dur time.Duration, ease EaseFunc) (*Anim, error) {
+	win := nb.ParentWindow()
+	if win == nil {
+		return nil, fmt.Errorf("gi.AnimateProp: node: %v is not in a window", nb.Path())
+	}
+	if from == nil {
+		from = nb.Prop(prop)
+		if from == nil {
+			return nil, fmt.Errorf("gi.AnimateProp: node: %v does not have property: %v to animate from", nb.Path(), prop)
+		}
+	}
+	lerp, err := PropLerpFunc(from, to)
+	if err != nil {
+		return nil, err
+	}
+	nd := nb.This().(Node2D)
+	an := &Anim{Name: prop, Target: nd, Duration: dur, Ease: ease}
+	an.Update = func(t float32) {
+		updt := nd.UpdateStart()
+		nd.SetProp(prop, lerp(t))
+		nb.SetFullReRender()
+		nd.UpdateEnd(updt)
+	}
+	return win.Animate(an), nil
+}
+
+// AnimateSplits animates the splits from their current values to given
+// ones, e.g., to smoothly collapse or expand a panel.
+func (sv *SplitView) AnimateSplits(dur time.Duration, ease EaseFunc, splits ...float32) *Anim {
+	win := sv.ParentWindow()
+	sv.UpdateSplits()
+	from := make([]float32, len(sv.Splits))
+	copy(from, sv.Splits)
+	to := make([]float32, len(from))
+	copy(to, from)
+	copy(to, splits)
+	cur := make([]float32, len(from))
+	an := &Anim{Name: "splits", Target: sv.This(), Duration: dur, Ease: ease}
+	an.Update = func(t float32) {
+		for i := range cur {
+			cur[i] = from[i] + t*(to[i]-from[i])
+		}
+		sv.SetSplitsAction(cur...)
+	}
+	if win == nil {
+		an.Update(1)
+		return an
+	}
+	return win.Animate(an)
+}
+
+// PopupOpenDur is the duration of the animation that unrolls popups, e.g.,
+// menus, downward from their top edge when they are opened -- set to 0 to
+// open them instantly
+var PopupOpenDur = 100 * time.Millisecond
+
+// AnimatePopupOpen starts the animation that unrolls given popup viewport
+// downward from its top edge over PopupOpenDur, by growing the part of it
+// that is drawn in the window -- called by PushPopup.
+func (w *Window) AnimatePopupOpen(vp *Viewport2D) *Anim {
+	if PopupOpenDur <= 0 {
+		return nil
+	}
+	vp.openFrac = 0
+	an := &Anim{Name: "popup-open", Target: vp.This(), Duration: PopupOpenDur, Ease: EaseOutCSS}
+	an.Update = func(t float32) {
+		vp.openFrac = t
+		w.UploadAllViewports()
+	}
+	an.Done = func(completed bool) {
+		if vp.openAnim == an {
+			vp.openAnim = nil
+		}
+	}
+	vp.openAnim = w.Animate(an)
+	return vp.openAnim
+}
+
+// popupBBox returns the part of given window bounding box of this popup
+// viewport that is drawn in the window, which is clipped at the bottom
+// while it is animating open -- see AnimatePopupOpen.
+func (vp *Viewport2D) popupBBox(bb image.Rectangle) image.Rectangle {
+	if vp.openAnim == nil {
+		return bb
+	}
+	bb.Max.Y = bb.Min.Y + ints.MaxInt(1, int(mat32.Ceil(vp.openFrac*float32(bb.Dy()))))
+	return bb
+}
+
+// AnimateScroll animates the scrollbar in given dimension from its current
+// position to given position, emitting a ScrollSig signal at the end.
+func (ly *Layout) AnimateScroll(dim mat32.Dims, pos float32, dur time.Duration, ease EaseFunc) *Anim {
+	win := ly.ParentWindow()
+	if !ly.HasScroll[dim] || win == nil {
+		ly.ScrollActionPos(dim, pos)
+		return nil
+	}
+	from := ly.Scrolls[dim].Value
+	an := &Anim{Name: "scroll-" + dim.String(), Target: ly.This(), Duration: dur, Ease: ease}
+	an.Update = func(t float32) {
+		if !ly.HasScroll[dim] {
+			return
+		}
+		ly.ScrollToPos(dim, from+t*(pos-from))
+	}
+	an.Done = func(completed bool) {
+		if completed {
+			ly.ScrollSig.Emit(ly.This(), int64(dim), pos)
+		}
+	}
+	return win.Animate(an)
+}
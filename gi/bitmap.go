@@ -150,7 +150,10 @@ func (bm *Bitmap) DrawIntoViewport(parVp *Viewport2D) {
 		}
 		r = nr
 	}
-	draw.Draw(parVp.Pixels, r, bm.Pixels, sp, draw.Over)
+	if !parVp.Render.Deferred() { // otherwise only recorded, below
+		draw.Draw(parVp.Pixels, r, bm.Pixels, sp, draw.Over)
+	}
+	parVp.Render.VectorImage(bm.Pixels, r, sp)
 }
 
 func (bm *Bitmap) Render2D() {
@@ -254,7 +254,7 @@ func (bb *ButtonBase) SetButtonState(state ButtonStates) bool {
 	}
 	bb.State = state
 	bb.StyMu.Lock()
-	bb.Sty = bb.StateStyles[state]
+	bb.SetCurStyle(&bb.StateStyles[state])
 	bb.StyMu.Unlock()
 	if prev != bb.State {
 		bb.SetFullReRenderIconLabel() // needs full rerender to update text, icon
@@ -520,6 +520,9 @@ func (bb *ButtonBase) HoverTooltipEvent() {
 		me := d.(*mouse.HoverEvent)
 		wbb := recv.Embed(KiT_ButtonBase).(*ButtonBase)
 		tt := wbb.Tooltip
+		if tt == "" && wbb.PartsLabelTruncated() {
+			tt = wbb.Text // show the full text
+		}
 		if wbb.Shortcut != "" {
 			tt = "[ " + wbb.Shortcut.Shortcut() + " ]: " + tt
 		}
@@ -657,13 +660,19 @@ func (bb *ButtonBase) StyleButton() {
 			bb.StateStyles[i].CopyUnitContext(&bb.Sty.UnContext)
 		}
 	}
+	bb.ParentStyleRUnlock()
+	if !hasTempl || saveTempl {
+		for i := 0; i < int(ButtonStatesN); i++ {
+			StyleCSS(bb.This().(Node2D), bb.Viewport, &bb.StateStyles[i], bb.CSSAgg, ButtonSelectors[i])
+			bb.StateStyles[i].CopyUnitContext(&bb.Sty.UnContext)
+		}
+	}
 	if hasTempl && saveTempl {
 		for i := 0; i < int(ButtonStatesN); i++ {
 			bb.StateStyles[i].Template = bb.Sty.Template + ButtonSelectors[i]
 			bb.StateStyles[i].SaveTemplate()
 		}
 	}
-	bb.ParentStyleRUnlock()
 }
 
 func (bb *ButtonBase) Style2D() {
@@ -75,14 +75,16 @@ var ComboBoxProps = ki.Props{
 		"stroke":  &Prefs.Colors.Font,
 	},
 	"#label": ki.Props{
-		"margin":  units.NewPx(0),
-		"padding": units.NewPx(0),
+		"margin":        units.NewPx(0),
+		"padding":       units.NewPx(0),
+		"text-overflow": gist.TextOverflowEllipsis,
 	},
 	"#text": ki.Props{
-		"margin":    units.NewPx(1),
-		"padding":   units.NewPx(1),
-		"max-width": -1,
-		"width":     units.NewCh(12),
+		"margin":        units.NewPx(1),
+		"padding":       units.NewPx(1),
+		"max-width":     -1,
+		"width":         units.NewCh(12),
+		"text-overflow": gist.TextOverflowEllipsis,
 	},
 	"#indicator": ki.Props{
 		"width":          units.NewEx(1.5),
@@ -5,7 +5,9 @@
 package gi
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/aymerick/douceur/css"
 	"github.com/aymerick/douceur/parser"
@@ -53,31 +55,84 @@ func (ss *StyleSheet) ParseString(str string) error {
 }
 
 // CSSProps returns the properties for each of the rules in this style sheet,
-// suitable for setting the CSS value of a node -- returns nil if empty sheet
+// suitable for setting the CSS value of a node -- returns nil if empty sheet.
+// Each selector of a rule is a key (see CSSSelector), and @media rules are
+// keys containing the props for their rules (see CSSMediaMatches).
 func (ss *StyleSheet) CSSProps() ki.Props {
 	if ss.Sheet == nil {
 		return nil
 	}
-	sz := len(ss.Sheet.Rules)
-	if sz == 0 {
+	return CSSRulesProps(ss.Sheet.Rules)
+}
+
+// CSSOrderKey is the key of the props of each selector returned by
+// CSSRulesProps that holds the source order of its declarations, as
+// ki.Props of the index of each property in the whole sheet -- MatchCSS
+// uses it to apply rules of equal specificity in source order, as the
+// selectors themselves are not ordered.
+const CSSOrderKey = "_css-order"
+
+// CSSRulesProps returns the properties for each of the given rules,
+// as in StyleSheet.CSSProps -- returns nil if no rules.  Declarations
+// for the same selector are merged, with later ones taking precedence,
+// and their source order is recorded under CSSOrderKey.
+func CSSRulesProps(rules []*css.Rule) ki.Props {
+	idx := 0
+	return cssRulesProps(rules, &idx)
+}
+
+// cssRulesProps is CSSRulesProps with idx counting the declarations
+// in source order, including those in @media rules
+func cssRulesProps(rules []*css.Rule, idx *int) ki.Props {
+	if len(rules) == 0 {
 		return nil
 	}
-	pr := make(ki.Props, sz)
-	for _, r := range ss.Sheet.Rules {
+	pr := make(ki.Props, len(rules))
+	for _, r := range rules {
 		if r.Kind == css.AtRule {
-			continue // not supported
+			if r.Name == "@media" {
+				if mp := cssRulesProps(r.Rules, idx); mp != nil {
+					key := "@media " + strings.TrimSpace(r.Prelude)
+					if ep, ok := pr[key].(ki.Props); ok {
+						AggCSS(&ep, mp)
+					} else {
+						pr[key] = mp
+					}
+				}
+			}
+			continue // others not supported
 		}
 		nd := len(r.Declarations)
 		if nd == 0 {
 			continue
 		}
 		for _, sel := range r.Selectors {
-			sp := make(ki.Props, nd)
-			for _, de := range r.Declarations {
+			sp, ok := pr[sel].(ki.Props)
+			if !ok {
+				sp = make(ki.Props, nd+1)
+				pr[sel] = sp
+			}
+			op, ok := sp[CSSOrderKey].(ki.Props)
+			if !ok {
+				op = make(ki.Props, nd)
+				sp[CSSOrderKey] = op
+			}
+			for i, de := range r.Declarations {
 				sp[de.Property] = de.Value
+				op[de.Property] = *idx + i
 			}
-			pr[sel] = sp
 		}
+		*idx += nd
 	}
 	return pr
 }
+
+// ParseCSS parses given CSS style sheet string into properties,
+// as in StyleSheet.CSSProps
+func ParseCSS(str string) (ki.Props, error) {
+	pss, err := parser.Parse(str)
+	if err != nil {
+		return nil, fmt.Errorf("gi.ParseCSS: %v", err)
+	}
+	return CSSRulesProps(pss.Rules), nil
+}
@@ -0,0 +1,590 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// CSSSelector is a parsed CSS selector that is matched against the Ki tree:
+// a sequence of compound selectors, e.g., frame > button.primary:hover,
+// where the last one is the subject that must match the node being styled,
+// and the earlier ones must match its ancestors, as a direct parent for
+// the > child combinator, or any ancestor for the (space) descendant
+// combinator.  https://developer.mozilla.org/en-US/docs/Web/CSS/CSS_Selectors
+type CSSSelector struct {
+	Parts []CSSCompound `desc:"compound selectors, from the outermost ancestor to the subject"`
+}
+
+// CSSCompound is one compound selector within a CSSSelector, e.g.,
+// button.primary#ok[tooltip]:hover, which all must match the same node.
+// All names are lower case, as matching is case insensitive.
+type CSSCompound struct {
+	Child   bool      `desc:"must be a direct child of the node matching the previous compound (> combinator), instead of any descendant"`
+	Type    string    `desc:"type name, e.g., button -- empty or * matches any type"`
+	ID      string    `desc:"#id, matching the name of the node"`
+	Classes []string  `desc:".class names, all of which must be in the Class of the node"`
+	Attrs   []CSSAttr `desc:"[attribute] selectors, matching the properties of the node"`
+	Pseudos []string  `desc:"pseudo-classes, as the style selectors used for widget states, e.g., :hover or :inactive -- see CSSPseudoStates"`
+}
+
+// CSSAttr is an attribute selector, e.g., [tooltip] or [icon="close"],
+// which is matched against the properties of a node, or its name or class
+// for the id and class attributes
+type CSSAttr struct {
+	Name string `desc:"attribute (property) name"`
+	Op   string `desc:"comparison operator: empty to test for presence, or one of = ~= |= ^= $= *="`
+	Val  string `desc:"value to compare with"`
+}
+
+// CSSPseudoStates maps the CSS pseudo-classes to the style selectors used
+// for the corresponding widget states, e.g., in ButtonSelectors -- other
+// pseudo-classes are used as-is, so widget-specific states such as
+// :down or :value can also be selected directly.
+var CSSPseudoStates = map[string]string{
+	"hover":    ":hover",
+	"focus":    ":focus",
+	"active":   ":down",
+	"disabled": ":inactive",
+	"selected": ":selected",
+}
+
+// Specificity returns the CSS specificity of the selector: the number
+// of ids, of classes, attributes and pseudo-classes, and of types.
+// Rules with a higher specificity are applied later, overriding others.
+func (sel *CSSSelector) Specificity() [3]int {
+	var sp [3]int
+	for i := range sel.Parts {
+		cp := &sel.Parts[i]
+		if cp.ID != "" {
+			sp[0]++
+		}
+		sp[1] += len(cp.Classes) + len(cp.Attrs) + len(cp.Pseudos)
+		if cp.Type != "" && cp.Type != "*" {
+			sp[2]++
+		}
+	}
+	return sp
+}
+
+// State returns the state style selector (e.g., :hover) that the subject of
+// the selector applies to, which is empty for the normal (base) style
+func (sel *CSSSelector) State() string {
+	return strings.Join(sel.Parts[len(sel.Parts)-1].Pseudos, "")
+}
+
+// MatchesNode returns true if the selector matches given node, except for
+// any pseudo-classes on the subject, which select the state style to apply
+// to (see State), and are thus matched by the styling process.
+func (sel *CSSSelector) MatchesNode(node ki.Ki) bool {
+	return sel.matchFrom(len(sel.Parts)-1, node, true)
+}
+
+// matchFrom returns true if compound selector at given index and all those
+// before it match given node and its ancestors
+func (sel *CSSSelector) matchFrom(idx int, node ki.Ki, subj bool) bool {
+	cp := &sel.Parts[idx]
+	if !cp.Matches(node, !subj) {
+		return false
+	}
+	if idx == 0 {
+		return true
+	}
+	par := node.Parent()
+	if cp.Child {
+		return par != nil && sel.matchFrom(idx-1, par, false)
+	}
+	for ; par != nil; par = par.Parent() {
+		if sel.matchFrom(idx-1, par, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the compound selector matches given node --
+// pseudo-classes are only tested if pseudos is true, using the flags
+// of the node for :hover, :focus, :inactive and :selected.
+func (cp *CSSCompound) Matches(node ki.Ki, pseudos bool) bool {
+	if cp.Type != "" && cp.Type != "*" && cp.Type != strings.ToLower(ki.Type(node).Name()) {
+		return false
+	}
+	if cp.ID != "" && cp.ID != strings.ToLower(node.Name()) {
+		return false
+	}
+	var class string
+	if gn, ok := node.(Node); ok {
+		class = strings.ToLower(gn.AsGiNode().Class)
+	}
+	if len(cp.Classes) > 0 {
+		cls := strings.Fields(class)
+		for _, cl := range cp.Classes {
+			if !cssHasWord(cls, cl) {
+				return false
+			}
+		}
+	}
+	for _, at := range cp.Attrs {
+		if !at.Matches(node, class) {
+			return false
+		}
+	}
+	if pseudos {
+		for _, ps := range cp.Pseudos {
+			if !CSSNodeHasState(node, ps) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CSSNodeHasState returns true if given node is currently in the state
+// for given state style selector (e.g., :hover), based on its flags --
+// this is used for pseudo-classes on ancestors in a CSSSelector.
+func CSSNodeHasState(node ki.Ki, state string) bool {
+	switch state {
+	case ":hover":
+		return node.HasFlag(int(MouseHasEntered))
+	case ":focus":
+		return node.HasFlag(int(HasFocus))
+	case ":inactive":
+		return node.HasFlag(int(Inactive))
+	case ":selected":
+		return node.HasFlag(int(Selected))
+	}
+	return false
+}
+
+// Matches returns true if the attribute selector matches given node,
+// which has given (lower-cased) class
+func (at *CSSAttr) Matches(node ki.Ki, class string) bool {
+	var val string
+	switch at.Name {
+	case "id", "name":
+		val = node.Name()
+	case "class":
+		val = class
+	default:
+		pv, ok := (*node.Properties())[at.Name]
+		if !ok {
+			return false
+		}
+		val = kit.ToString(pv)
+	}
+	switch at.Op {
+	case "":
+		return true
+	case "=":
+		return val == at.Val
+	case "~=":
+		return cssHasWord(strings.Fields(val), at.Val)
+	case "|=":
+		return val == at.Val || strings.HasPrefix(val, at.Val+"-")
+	case "^=":
+		return at.Val != "" && strings.HasPrefix(val, at.Val)
+	case "$=":
+		return at.Val != "" && strings.HasSuffix(val, at.Val)
+	case "*=":
+		return at.Val != "" && strings.Contains(val, at.Val)
+	}
+	return false
+}
+
+// cssHasWord returns true if word is in given list of words
+func cssHasWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the CSS string representation of the selector
+func (sel *CSSSelector) String() string {
+	var sb strings.Builder
+	for i := range sel.Parts {
+		cp := &sel.Parts[i]
+		if i > 0 {
+			if cp.Child {
+				sb.WriteString(" > ")
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(cp.Type)
+		if cp.ID != "" {
+			sb.WriteString("#" + cp.ID)
+		}
+		for _, cl := range cp.Classes {
+			sb.WriteString("." + cl)
+		}
+		for _, at := range cp.Attrs {
+			if at.Op == "" {
+				sb.WriteString("[" + at.Name + "]")
+			} else {
+				sb.WriteString(fmt.Sprintf("[%s%s%q]", at.Name, at.Op, at.Val))
+			}
+		}
+		for _, ps := range cp.Pseudos {
+			sb.WriteString(ps)
+		}
+	}
+	return sb.String()
+}
+
+// ParseCSSSelectors parses a comma-separated list of CSS selectors,
+// e.g., "button:hover, frame > .primary" -- commas within quotes, brackets
+// or parentheses, e.g., in button[title="a,b"], do not separate selectors
+func ParseCSSSelectors(str string) ([]*CSSSelector, error) {
+	var sels []*CSSSelector
+	for _, s := range splitCSSSelectors(str) {
+		sel, err := ParseCSSSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	return sels, nil
+}
+
+// splitCSSSelectors splits given selector list at the commas that are not
+// within quotes, brackets or parentheses
+func splitCSSSelectors(str string) []string {
+	var strs []string
+	var quote byte
+	depth := 0
+	st := 0
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case c == ',' && depth == 0:
+			strs = append(strs, str[st:i])
+			st = i + 1
+		}
+	}
+	return append(strs, str[st:])
+}
+
+// ParseCSSSelector parses a single CSS selector, e.g., frame > button.primary:hover
+func ParseCSSSelector(str string) (*CSSSelector, error) {
+	sel := &CSSSelector{}
+	s := strings.TrimSpace(str)
+	child := false
+	for len(s) > 0 {
+		if s[0] == '>' {
+			if child || len(sel.Parts) == 0 {
+				return nil, fmt.Errorf("gi.ParseCSSSelector: misplaced > in: %q", str)
+			}
+			child = true
+			s = strings.TrimSpace(s[1:])
+			continue
+		}
+		cp := CSSCompound{Child: child}
+		n, err := cp.parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("gi.ParseCSSSelector: %v in: %q", err, str)
+		}
+		sel.Parts = append(sel.Parts, cp)
+		child = false
+		s = strings.TrimSpace(s[n:])
+	}
+	if len(sel.Parts) == 0 || child {
+		return nil, fmt.Errorf("gi.ParseCSSSelector: incomplete selector: %q", str)
+	}
+	return sel, nil
+}
+
+// parse parses the compound selector at the start of given string, returning
+// the number of bytes used
+func (cp *CSSCompound) parse(s string) (int, error) {
+	i := cssIdentEnd(s, 0)
+	if i == 0 && len(s) > 0 && s[0] == '*' {
+		i = 1
+	}
+	cp.Type = strings.ToLower(s[:i])
+	for i < len(s) {
+		c := s[i]
+		switch c {
+		case '#', '.', ':':
+			st := i + 1
+			if c == ':' && st < len(s) && s[st] == ':' {
+				return 0, fmt.Errorf("pseudo-elements are not supported")
+			}
+			i = cssIdentEnd(s, st)
+			if i == st {
+				return 0, fmt.Errorf("missing name after %c", c)
+			}
+			nm := strings.ToLower(s[st:i])
+			switch c {
+			case '#':
+				cp.ID = nm
+			case '.':
+				cp.Classes = append(cp.Classes, nm)
+			default:
+				ps, ok := CSSPseudoStates[nm]
+				if !ok {
+					ps = ":" + nm
+				}
+				cp.Pseudos = append(cp.Pseudos, ps)
+			}
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return 0, fmt.Errorf("missing ]")
+			}
+			at, err := parseCSSAttr(s[i+1 : i+end])
+			if err != nil {
+				return 0, err
+			}
+			cp.Attrs = append(cp.Attrs, at)
+			i += end + 1
+		default:
+			if c == ' ' || c == '\t' || c == '\n' || c == '>' {
+				return i, nil
+			}
+			return 0, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return i, nil
+}
+
+// parseCSSAttr parses the contents of an attribute selector, e.g., icon="close"
+func parseCSSAttr(s string) (CSSAttr, error) {
+	var at CSSAttr
+	s = strings.TrimSpace(s)
+	eq := strings.IndexByte(s, '=')
+	if eq < 0 {
+		at.Name = strings.ToLower(s)
+	} else {
+		nm := s[:eq]
+		at.Op = "="
+		if eq > 0 && strings.IndexByte("~|^$*", nm[eq-1]) >= 0 {
+			at.Op = nm[eq-1:] + "="
+			nm = nm[:eq-1]
+		}
+		at.Name = strings.ToLower(strings.TrimSpace(nm))
+		at.Val = strings.Trim(strings.TrimSpace(s[eq+1:]), `"'`)
+	}
+	if at.Name == "" || cssIdentEnd(at.Name, 0) != len(at.Name) {
+		return at, fmt.Errorf("invalid attribute selector: [%s]", s)
+	}
+	return at, nil
+}
+
+// cssIdentEnd returns the end of the CSS identifier starting at given index
+func cssIdentEnd(s string, st int) int {
+	i := st
+	for i < len(s) {
+		c := s[i]
+		if !(c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80) {
+			break
+		}
+		i++
+	}
+	return i
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Matching css props
+
+// cssSelectorCache caches the parsed selectors for css keys,
+// with nil for keys that are not valid selectors
+var cssSelectorCache = map[string][]*CSSSelector{}
+
+var cssSelectorCacheMu sync.RWMutex
+
+// CSSSelectorsCached returns the parsed selectors for given css key,
+// using a cache -- returns nil if not a valid selector
+func CSSSelectorsCached(key string) []*CSSSelector {
+	cssSelectorCacheMu.RLock()
+	sels, ok := cssSelectorCache[key]
+	cssSelectorCacheMu.RUnlock()
+	if ok {
+		return sels
+	}
+	sels, _ = ParseCSSSelectors(key)
+	cssSelectorCacheMu.Lock()
+	cssSelectorCache[key] = sels
+	cssSelectorCacheMu.Unlock()
+	return sels
+}
+
+// CSSMediaMatches returns true if given @media query (without the @media)
+// applies currently: only the screen and all media types, and the
+// prefers-color-scheme feature (based on Preferences.IsDarkMode) are
+// supported, and can be combined with and
+func CSSMediaMatches(query string) bool {
+	for _, cond := range strings.Split(strings.ToLower(query), " and ") {
+		cond = strings.TrimSpace(cond)
+		switch cond {
+		case "screen", "all", "only screen":
+			continue
+		}
+		if !strings.HasPrefix(cond, "(") || !strings.HasSuffix(cond, ")") {
+			return false
+		}
+		kv := strings.SplitN(cond[1:len(cond)-1], ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != "prefers-color-scheme" {
+			return false
+		}
+		if (strings.TrimSpace(kv[1]) == "dark") != Prefs.IsDarkMode() {
+			return false
+		}
+	}
+	return true
+}
+
+// cssMatch is a css rule that matches a node
+type cssMatch struct {
+	spec  [3]int
+	key   string
+	props ki.Props
+	order ki.Props // source order of the props, from CSSOrderKey, if known
+}
+
+// MatchCSS returns the properties of all the rules in given css that apply
+// to given node, when styling it for given state style selector (e.g., :hover,
+// or empty for the base style), in the order in which they should be applied:
+// by increasing specificity of their selectors, and then in source order for
+// rules from style sheets (see CSSRulesProps), which come after any others
+// of the same specificity, ordered by selector text.
+// The keys of css are selectors (see CSSSelector), or @media queries
+// (see CSSMediaMatches) containing further css props.  Rules whose subject has
+// no pseudo-class can contain props for states under a :state key, e.g.,
+// "button": ki.Props{":hover": ki.Props{...}}.
+func MatchCSS(node ki.Ki, css ki.Props, state string) []ki.Props {
+	var ms []cssMatch
+	ms = matchCSS(node, css, state, ms)
+	if len(ms) == 0 {
+		return nil
+	}
+	sort.SliceStable(ms, func(i, j int) bool {
+		si, sj := ms[i].spec, ms[j].spec
+		if si != sj {
+			return si[0] < sj[0] || si[0] == sj[0] && (si[1] < sj[1] || si[1] == sj[1] && si[2] < sj[2])
+		}
+		if oi, oj := ms[i].order != nil, ms[j].order != nil; oi != oj {
+			return oj
+		}
+		return ms[i].key < ms[j].key
+	})
+	pms := make([]ki.Props, 0, len(ms))
+	for i := 0; i < len(ms); {
+		j := i + 1
+		for j < len(ms) && ms[j].spec == ms[i].spec {
+			j++
+		}
+		pms = append(pms, cssMergeOrdered(ms[i:j])...)
+		i = j
+	}
+	return pms
+}
+
+// cssMergeOrdered returns the props of given matches of equal specificity,
+// with those that have a source order merged into one props in that order,
+// so that a later declaration takes precedence even if it is for another
+// selector.  The ordered matches must be at the end.
+func cssMergeOrdered(ms []cssMatch) []ki.Props {
+	pms := make([]ki.Props, 0, len(ms))
+	st := len(ms)
+	for i := range ms {
+		if ms[i].order != nil {
+			st = i
+			break
+		}
+		pms = append(pms, ms[i].props)
+	}
+	switch len(ms) - st {
+	case 0:
+		return pms
+	case 1:
+		return append(pms, ms[st].props)
+	}
+	type decl struct {
+		key string
+		val interface{}
+		idx int64
+	}
+	var decls []decl
+	mp := ki.Props{}
+	for _, m := range ms[st:] {
+		for key, val := range m.props {
+			if key == CSSOrderKey {
+				continue
+			}
+			idx, ok := kit.ToInt(m.order[key])
+			if !ok {
+				mp[key] = val // not a declaration, e.g., :state sub-props
+				continue
+			}
+			decls = append(decls, decl{key, val, idx})
+		}
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].idx < decls[j].idx })
+	for _, d := range decls {
+		mp[d.key] = d.val
+	}
+	return append(pms, mp)
+}
+
+func matchCSS(node ki.Ki, css ki.Props, state string, ms []cssMatch) []cssMatch {
+	for key, val := range css {
+		pmap, ok := val.(ki.Props)
+		if !ok || key == "" {
+			continue
+		}
+		if strings.HasPrefix(key, "@media") {
+			if CSSMediaMatches(strings.TrimPrefix(key, "@media")) {
+				ms = matchCSS(node, pmap, state, ms)
+			}
+			continue
+		}
+		if key[0] == ':' { // state sub-props
+			continue
+		}
+		for _, sel := range CSSSelectorsCached(key) {
+			if !sel.MatchesNode(node) {
+				continue
+			}
+			sst := sel.State()
+			var pm ki.Props
+			switch {
+			case sst == state:
+				pm = pmap
+			case sst == "":
+				pm, _ = gist.SubProps(pmap, state)
+			}
+			if pm != nil {
+				m := cssMatch{spec: sel.Specificity(), key: key, props: pm}
+				m.order, _ = pm[CSSOrderKey].(ki.Props)
+				ms = append(ms, m)
+			}
+		}
+	}
+	return ms
+}
@@ -0,0 +1,529 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// DatePicker
+
+// DatePicker is a calendar for choosing a date, or a range of dates, which
+// shows one month at a time as a grid of days, optionally with the week
+// numbers.  The arrow keys move the selected date by a day or a week,
+// PageUp / PageDown by a month, and Home / End go to the first / last day
+// of the month -- with Shift, the arrow keys extend the range, if Range.
+// Call SetDate or SetRange (or Config) after creating it.
+type DatePicker struct {
+	Frame
+	Date          time.Time    `desc:"selected date -- the start of the range if Range -- the time of day is kept when another day is selected"`
+	End           time.Time    `desc:"the other end of the selected range of dates, if Range -- zero while the end of a new range is being selected -- it can be before Date: see DateRange"`
+	Range         bool         `desc:"select a range of dates, from Date to End, instead of one date"`
+	Month         time.Time    `view:"-" desc:"first day of the month that is shown"`
+	HasMin        bool         `desc:"is there a minimum date to enforce"`
+	Min           time.Time    `desc:"minimum date that can be selected -- only the day is used"`
+	HasMax        bool         `desc:"is there a maximum date to enforce"`
+	Max           time.Time    `desc:"maximum date that can be selected -- only the day is used"`
+	WeekNums      bool         `desc:"show the ISO 8601 week numbers of the weeks"`
+	FirstDay      time.Weekday `desc:"first day of the week -- Sunday by default"`
+	DatePickerSig ki.Signal    `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for date picker -- has no signal types, just emitted when the selected dates change, with the Date as the data"`
+}
+
+var KiT_DatePicker = kit.Types.AddType(&DatePicker{}, DatePickerProps)
+
+// AddNewDatePicker adds a new date picker to given parent node, with given name.
+func AddNewDatePicker(parent ki.Ki, name string) *DatePicker {
+	return parent.AddNewChild(KiT_DatePicker, name).(*DatePicker)
+}
+
+func (dp *DatePicker) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*DatePicker)
+	dp.Frame.CopyFieldsFrom(&fr.Frame)
+	dp.Date = fr.Date
+	dp.End = fr.End
+	dp.Range = fr.Range
+	dp.Month = fr.Month
+	dp.HasMin = fr.HasMin
+	dp.Min = fr.Min
+	dp.HasMax = fr.HasMax
+	dp.Max = fr.Max
+	dp.WeekNums = fr.WeekNums
+	dp.FirstDay = fr.FirstDay
+}
+
+func (dp *DatePicker) Disconnect() {
+	dp.Frame.Disconnect()
+	dp.DatePickerSig.DisconnectAll()
+}
+
+var DatePickerProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"border-width":     units.NewPx(1),
+	"border-radius":    units.NewPx(4),
+	"border-color":     &Prefs.Colors.Border,
+	"padding":          units.NewPx(4),
+	"margin":           units.NewPx(2),
+	"color":            &Prefs.Colors.Font,
+	"background-color": &Prefs.Colors.Background,
+}
+
+// DayOf returns the start of the day of given time, in its location
+func DayOf(tm time.Time) time.Time {
+	y, m, d := tm.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, tm.Location())
+}
+
+// dayKey returns the day of given time, in UTC, for comparing the days of
+// times in different locations
+func dayKey(tm time.Time) time.Time {
+	y, m, d := tm.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DateWithTime returns the day of given date, with the time of day of
+// given time, in the location of the date
+func DateWithTime(date, tm time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), date.Location())
+}
+
+// SetDate sets the selected date, shows its month, and updates the display
+// -- a zero date selects nothing, and shows the current month
+func (dp *DatePicker) SetDate(date time.Time) {
+	dp.Date = date
+	dp.End = date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	dp.ShowMonth(date)
+}
+
+// SetRange sets Range and the selected range of dates, shows the month of
+// the start, and updates the display
+func (dp *DatePicker) SetRange(st, ed time.Time) {
+	dp.Range = true
+	dp.Date = st
+	dp.End = ed
+	if st.IsZero() {
+		st = time.Now()
+	}
+	dp.ShowMonth(st)
+}
+
+// SetMin sets the minimum date that can be selected
+func (dp *DatePicker) SetMin(min time.Time) {
+	dp.HasMin = true
+	dp.Min = min
+}
+
+// SetMax sets the maximum date that can be selected
+func (dp *DatePicker) SetMax(max time.Time) {
+	dp.HasMax = true
+	dp.Max = max
+}
+
+// SetMinMax sets the minimum and maximum dates that can be selected
+func (dp *DatePicker) SetMinMax(min, max time.Time) {
+	dp.SetMin(min)
+	dp.SetMax(max)
+}
+
+// DateRange returns the selected range of dates, in order -- the end is
+// the start if the end has not been selected yet, or if not Range
+func (dp *DatePicker) DateRange() (st, ed time.Time) {
+	st, ed = dp.Date, dp.End
+	if !dp.Range || ed.IsZero() {
+		return st, st
+	}
+	if ed.Before(st) {
+		st, ed = ed, st
+	}
+	return st, ed
+}
+
+// InBounds returns true if given day is within the Min and Max dates, if set
+func (dp *DatePicker) InBounds(day time.Time) bool {
+	day = dayKey(day)
+	if dp.HasMin && day.Before(dayKey(dp.Min)) {
+		return false
+	}
+	if dp.HasMax && day.After(dayKey(dp.Max)) {
+		return false
+	}
+	return true
+}
+
+// ClampDay returns given day, moved to the Min or Max date if it is not
+// within them
+func (dp *DatePicker) ClampDay(day time.Time) time.Time {
+	if dp.HasMin && dayKey(day).Before(dayKey(dp.Min)) {
+		return DateWithTime(time.Date(dp.Min.Year(), dp.Min.Month(), dp.Min.Day(), 0, 0, 0, 0, day.Location()), day)
+	}
+	if dp.HasMax && dayKey(day).After(dayKey(dp.Max)) {
+		return DateWithTime(time.Date(dp.Max.Year(), dp.Max.Month(), dp.Max.Day(), 0, 0, 0, 0, day.Location()), day)
+	}
+	return day
+}
+
+// IsSelectedDay returns true if given day is the selected date, or within
+// the selected range of dates
+func (dp *DatePicker) IsSelectedDay(day time.Time) bool {
+	if dp.Date.IsZero() {
+		return false
+	}
+	st, ed := dp.DateRange()
+	day = dayKey(day)
+	return !day.Before(dayKey(st)) && !day.After(dayKey(ed))
+}
+
+// ShowMonth shows the month of given date, and updates the display
+func (dp *DatePicker) ShowMonth(date time.Time) {
+	dp.Month = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	dp.Config()
+	dp.UpdateMonth()
+}
+
+// SelectDateAction selects given day (keeping the time of day of the
+// selected date), as for a click on it, and emits the signal -- if Range,
+// it starts a new range, or ends the range that was started
+func (dp *DatePicker) SelectDateAction(day time.Time) {
+	if !dp.InBounds(day) {
+		return
+	}
+	tm := dp.Date // zero is midnight
+	if dp.Range && !dp.Date.IsZero() && dp.End.IsZero() {
+		dp.End = DateWithTime(day, tm)
+		if dp.End.Before(dp.Date) {
+			dp.Date, dp.End = DateWithTime(dp.End, dp.Date), DateWithTime(dp.Date, dp.End)
+		}
+	} else {
+		dp.Date = DateWithTime(day, tm)
+		if dp.Range {
+			dp.End = time.Time{}
+		} else {
+			dp.End = dp.Date
+		}
+	}
+	dp.ShowMonth(day)
+	dp.DatePickerSig.Emit(dp.This(), 0, dp.Date)
+}
+
+// MoveDateAction moves the selected date by given number of months and
+// days, within the Min and Max dates, and emits the signal -- if extend
+// and Range, the End of the range is moved instead, extending the range
+func (dp *DatePicker) MoveDateAction(months, days int, extend bool) {
+	extend = extend && dp.Range
+	cur := dp.Date
+	if extend && !dp.End.IsZero() {
+		cur = dp.End
+	}
+	if cur.IsZero() {
+		cur = DayOf(time.Now())
+	}
+	nd := cur.AddDate(0, 0, days)
+	if months != 0 {
+		// keep the day within the month, instead of overflowing into the next one
+		y, m, d := cur.Date()
+		fst := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, cur.Location())
+		if last := fst.AddDate(0, 1, -1).Day(); d > last {
+			d = last
+		}
+		nd = DateWithTime(time.Date(fst.Year(), fst.Month(), d, 0, 0, 0, 0, cur.Location()), cur)
+	}
+	dp.setCursor(nd, extend)
+}
+
+// MoveMonthEndAction moves the selected date to the first day of its month,
+// or the last if last, within the Min and Max dates, and emits the signal
+// -- if extend and Range, the End of the range is moved instead
+func (dp *DatePicker) MoveMonthEndAction(last, extend bool) {
+	cur := dp.Date
+	if extend && dp.Range && !dp.End.IsZero() {
+		cur = dp.End
+	}
+	if cur.IsZero() {
+		cur = dp.Month
+	}
+	nd := cur.AddDate(0, 0, 1-cur.Day())
+	if last {
+		nd = nd.AddDate(0, 1, -1)
+	}
+	dp.setCursor(nd, extend && dp.Range)
+}
+
+// setCursor sets the date moved to by the keys, and emits the signal
+func (dp *DatePicker) setCursor(nd time.Time, extend bool) {
+	nd = dp.ClampDay(nd)
+	if extend {
+		if dp.Date.IsZero() {
+			dp.Date = nd
+		}
+		dp.End = nd
+	} else {
+		dp.Date = nd
+		dp.End = nd
+	}
+	dp.ShowMonth(nd)
+	dp.DatePickerSig.Emit(dp.This(), 0, dp.Date)
+}
+
+// Header returns the layout with the month and year at the top
+func (dp *DatePicker) Header() *Layout {
+	return dp.ChildByName("header", 0).(*Layout)
+}
+
+// Grid returns the grid of days
+func (dp *DatePicker) Grid() *Layout {
+	return dp.ChildByName("grid", 1).(*Layout)
+}
+
+// Config configures the header and grid, if they have not been configured
+// yet, and shows the month of the selected date if no month is shown
+func (dp *DatePicker) Config() {
+	if dp.Month.IsZero() {
+		date := dp.Date
+		if date.IsZero() {
+			date = time.Now()
+		}
+		dp.Month = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	}
+	if dp.HasChildren() {
+		return
+	}
+	updt := dp.UpdateStart()
+	dp.Lay = LayoutVert
+	dp.SetProp("spacing", units.NewPx(4))
+	hdr := AddNewLayout(dp, "header", LayoutHoriz)
+	hdr.SetStretchMaxWidth()
+	gr := AddNewLayout(dp, "grid", LayoutGrid)
+	gr.SetProp("spacing", units.NewPx(1))
+
+	prev := AddNewAction(hdr, "prev")
+	prev.SetIcon("wedge-left")
+	prev.Tooltip = "previous month"
+	prev.SetProp("no-focus", true)
+	prev.ActionSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dpp := recv.Embed(KiT_DatePicker).(*DatePicker)
+		dpp.ShowMonth(dpp.Month.AddDate(0, -1, 0))
+	})
+	AddNewStretch(hdr, "str-prev")
+	mon := AddNewComboBox(hdr, "month")
+	months := make([]string, 12)
+	for i := range months {
+		months[i] = time.Month(i + 1).String()
+	}
+	mon.ItemsFromStringList(months, false, 0)
+	mon.SetProp("no-focus", true)
+	mon.ComboSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dpp := recv.Embed(KiT_DatePicker).(*DatePicker)
+		dpp.ShowMonth(time.Date(dpp.Month.Year(), time.Month(sig+1), 1, 0, 0, 0, 0, dpp.Month.Location()))
+	})
+	yr := AddNewSpinBox(hdr, "year")
+	yr.Defaults()
+	yr.Step = 1
+	yr.PageStep = 10
+	yr.Format = "%d"
+	yr.SpinBoxSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dpp := recv.Embed(KiT_DatePicker).(*DatePicker)
+		sb := send.Embed(KiT_SpinBox).(*SpinBox)
+		dpp.ShowMonth(time.Date(int(sb.Value), dpp.Month.Month(), 1, 0, 0, 0, 0, dpp.Month.Location()))
+	})
+	AddNewStretch(hdr, "str-next")
+	next := AddNewAction(hdr, "next")
+	next.SetIcon("wedge-right")
+	next.Tooltip = "next month"
+	next.SetProp("no-focus", true)
+	next.ActionSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dpp := recv.Embed(KiT_DatePicker).(*DatePicker)
+		dpp.ShowMonth(dpp.Month.AddDate(0, 1, 0))
+	})
+	dp.UpdateEnd(updt)
+}
+
+// UpdateMonth updates the header and the grid of days for the month that
+// is shown, and the selected dates
+func (dp *DatePicker) UpdateMonth() {
+	updt := dp.UpdateStart()
+	hdr := dp.Header()
+	mon := hdr.ChildByName("month", 2).(*ComboBox)
+	mon.SetCurIndex(int(dp.Month.Month()) - 1)
+	yr := hdr.ChildByName("year", 3).(*SpinBox)
+	if dp.HasMin {
+		yr.SetMin(float32(dp.Min.Year()))
+	}
+	if dp.HasMax {
+		yr.SetMax(float32(dp.Max.Year()))
+	}
+	yr.SetValue(float32(dp.Month.Year()))
+	dp.UpdateGrid()
+	dp.SetFullReRender()
+	dp.UpdateEnd(updt)
+}
+
+// GridStart returns the first day shown in the grid, which is the first
+// day of the week of the first day of the month
+func (dp *DatePicker) GridStart() time.Time {
+	off := (int(dp.Month.Weekday()) - int(dp.FirstDay) + 7) % 7
+	return dp.Month.AddDate(0, 0, -off)
+}
+
+// UpdateGrid configures the grid of days for the month that is shown --
+// always 6 weeks, so the size does not change from month to month
+func (dp *DatePicker) UpdateGrid() {
+	gr := dp.Grid()
+	ncol := 7
+	if dp.WeekNums {
+		ncol++
+	}
+	gr.SetProp("columns", ncol)
+	st := dp.GridStart()
+	config := kit.TypeAndNameList{}
+	if dp.WeekNums {
+		config.Add(KiT_Label, "wk")
+	}
+	for c := 0; c < 7; c++ {
+		config.Add(KiT_Label, fmt.Sprintf("wd-%d", c))
+	}
+	for r := 0; r < 6; r++ {
+		if dp.WeekNums {
+			config.Add(KiT_Label, fmt.Sprintf("wk-%d", r))
+		}
+		for c := 0; c < 7; c++ {
+			day := st.AddDate(0, 0, r*7+c)
+			if day.Month() == dp.Month.Month() {
+				config.Add(KiT_Button, fmt.Sprintf("day-%d-%d", r, c))
+			} else {
+				config.Add(KiT_Label, fmt.Sprintf("day-%d-%d", r, c))
+			}
+		}
+	}
+	mods, updt := gr.ConfigChildren(config)
+	if !mods {
+		updt = gr.UpdateStart()
+	}
+	today := dayKey(time.Now())
+	idx := 0
+	if dp.WeekNums {
+		wk := gr.Child(idx).(*Label)
+		wk.SetText("Wk")
+		wk.SetProp("color", &Prefs.Colors.Border)
+		idx++
+	}
+	for c := 0; c < 7; c++ {
+		wd := gr.Child(idx).(*Label)
+		wd.SetText(time.Weekday((int(dp.FirstDay) + c) % 7).String()[:2])
+		wd.SetProp("horizontal-align", gist.AlignCenter)
+		wd.SetProp("font-weight", gist.WeightBold)
+		idx++
+	}
+	for r := 0; r < 6; r++ {
+		if dp.WeekNums {
+			_, wn := st.AddDate(0, 0, r*7+(11-int(st.Weekday()))%7).ISOWeek() // the thursday
+			wk := gr.Child(idx).(*Label)
+			wk.SetText(strconv.Itoa(wn))
+			wk.SetProp("color", &Prefs.Colors.Border)
+			wk.SetProp("horizontal-align", gist.AlignCenter)
+			idx++
+		}
+		for c := 0; c < 7; c++ {
+			day := st.AddDate(0, 0, r*7+c)
+			if day.Month() != dp.Month.Month() {
+				gr.Child(idx).(*Label).SetText("")
+				idx++
+				continue
+			}
+			bt := gr.Child(idx).(*Button)
+			idx++
+			bt.SetText(strconv.Itoa(day.Day()))
+			bt.SetProp("no-focus", true)
+			bt.SetProp("min-width", units.NewEm(2))
+			bt.SetProp("padding", units.NewPx(2))
+			bt.SetProp("margin", units.NewPx(1))
+			bt.SetProp("border-radius", units.NewPx(4))
+			bt.SetProp("text-align", gist.AlignCenter)
+			if dayKey(day).Equal(today) {
+				bt.SetProp("font-weight", gist.WeightBold)
+			} else {
+				bt.DeleteProp("font-weight")
+			}
+			bt.Tooltip = day.Format("Monday, January 2, 2006")
+			bt.SetSelectedState(dp.IsSelectedDay(day))
+			bt.SetInactiveState(!dp.InBounds(day))
+			bt.ButtonSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig != int64(ButtonClicked) {
+					return
+				}
+				dpp := recv.Embed(KiT_DatePicker).(*DatePicker)
+				dpp.GrabFocus()
+				dpp.SelectDateAction(day)
+			})
+		}
+	}
+	gr.UpdateEnd(updt)
+}
+
+func (dp *DatePicker) KeyInput(kt *key.ChordEvent) {
+	if KeyEventTrace {
+		fmt.Printf("DatePicker KeyInput: %v\n", dp.Path())
+	}
+	extend := kt.HasAnyModifier(key.Shift)
+	kf := KeyFun(kt.Chord())
+	switch kf {
+	case KeyFunMoveUp:
+		kt.SetProcessed()
+		dp.MoveDateAction(0, -7, extend)
+	case KeyFunMoveDown:
+		kt.SetProcessed()
+		dp.MoveDateAction(0, 7, extend)
+	case KeyFunMoveLeft:
+		kt.SetProcessed()
+		dp.MoveDateAction(0, -1, extend)
+	case KeyFunMoveRight:
+		kt.SetProcessed()
+		dp.MoveDateAction(0, 1, extend)
+	case KeyFunPageUp:
+		kt.SetProcessed()
+		dp.MoveDateAction(-1, 0, extend)
+	case KeyFunPageDown:
+		kt.SetProcessed()
+		dp.MoveDateAction(1, 0, extend)
+	case KeyFunHome:
+		kt.SetProcessed()
+		dp.MoveMonthEndAction(false, extend)
+	case KeyFunEnd:
+		kt.SetProcessed()
+		dp.MoveMonthEndAction(true, extend)
+	}
+}
+
+func (dp *DatePicker) KeyChordEvent() {
+	dp.ConnectEvent(oswin.KeyChordEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		dpp := recv.Embed(KiT_DatePicker).(*DatePicker)
+		if dpp.IsInactive() || !dpp.HasFocus() {
+			return
+		}
+		dpp.KeyInput(d.(*key.ChordEvent))
+	})
+}
+
+func (dp *DatePicker) Init2D() {
+	dp.Frame.Init2D()
+	dp.SetCanFocusIfActive()
+}
+
+func (dp *DatePicker) ConnectEvents2D() {
+	dp.Frame.ConnectEvents2D()
+	dp.KeyChordEvent()
+}
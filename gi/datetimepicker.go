@@ -0,0 +1,167 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"time"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// DateTimePicker
+
+// DateTimePicker chooses a date and time, or a range of them, with a
+// DatePicker for the days, and TimePickers for the times of day of the
+// start and (if Range) the end.  Call SetDateTime or SetDateRange after
+// creating it -- the DatePicker and TimePicker methods return the pickers,
+// e.g., to set the minimum and maximum dates.
+type DateTimePicker struct {
+	Frame
+	Start       time.Time `desc:"selected date and time -- the start of the range if Range"`
+	End         time.Time `desc:"end of the selected range, if Range -- always at or after Start"`
+	Range       bool      `desc:"select a range of dates and times, from Start to End"`
+	Hour24      bool      `desc:"show the hours from 0 to 23, instead of 1 to 12 with AM / PM"`
+	Seconds     bool      `desc:"show the seconds"`
+	DateTimeSig ki.Signal `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for date time picker -- has no signal types, just emitted when the selected dates or times change, with the Start as the data"`
+}
+
+var KiT_DateTimePicker = kit.Types.AddType(&DateTimePicker{}, DateTimePickerProps)
+
+// AddNewDateTimePicker adds a new date time picker to given parent node, with given name.
+func AddNewDateTimePicker(parent ki.Ki, name string) *DateTimePicker {
+	return parent.AddNewChild(KiT_DateTimePicker, name).(*DateTimePicker)
+}
+
+func (dt *DateTimePicker) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*DateTimePicker)
+	dt.Frame.CopyFieldsFrom(&fr.Frame)
+	dt.Start = fr.Start
+	dt.End = fr.End
+	dt.Range = fr.Range
+	dt.Hour24 = fr.Hour24
+	dt.Seconds = fr.Seconds
+}
+
+func (dt *DateTimePicker) Disconnect() {
+	dt.Frame.Disconnect()
+	dt.DateTimeSig.DisconnectAll()
+}
+
+var DateTimePickerProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"background-color": &Prefs.Colors.Background,
+	"color":            &Prefs.Colors.Font,
+}
+
+// SetDateTime sets the selected date and time, and updates the display
+func (dt *DateTimePicker) SetDateTime(tm time.Time) {
+	dt.Range = false
+	dt.Start = tm
+	dt.End = tm
+	dt.Config()
+	dt.DatePicker().SetDate(tm)
+	dt.TimePicker(false).SetTime(tm)
+}
+
+// SetDateRange sets Range and the selected range of dates and times, and
+// updates the display
+func (dt *DateTimePicker) SetDateRange(st, ed time.Time) {
+	if ed.Before(st) {
+		st, ed = ed, st
+	}
+	dt.Range = true
+	dt.Start = st
+	dt.End = ed
+	dt.Config()
+	dt.DatePicker().SetRange(st, ed)
+	dt.TimePicker(false).SetTime(st)
+	dt.TimePicker(true).SetTime(ed)
+}
+
+// DatePicker returns the date picker
+func (dt *DateTimePicker) DatePicker() *DatePicker {
+	return dt.ChildByName("date", 0).(*DatePicker)
+}
+
+// TimePicker returns the time picker for the start, or for the end if end
+// -- nil if end and not Range
+func (dt *DateTimePicker) TimePicker(end bool) *TimePicker {
+	times := dt.ChildByName("times", 1)
+	if end {
+		tp, _ := times.ChildByName("end", 3).(*TimePicker)
+		return tp
+	}
+	return times.ChildByName("start", 1).(*TimePicker)
+}
+
+// Config configures the date picker, and the time pickers for the current
+// Range, Hour24 and Seconds settings
+func (dt *DateTimePicker) Config() {
+	updt := dt.UpdateStart()
+	dt.Lay = LayoutVert
+	dt.SetProp("spacing", StdDialogVSpaceUnits)
+	if !dt.HasChildren() {
+		dp := AddNewDatePicker(dt, "date")
+		dp.DatePickerSig.ConnectOnly(dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dtt := recv.Embed(KiT_DateTimePicker).(*DateTimePicker)
+			dtt.UpdateFromPickers()
+		})
+		AddNewLayout(dt, "times", LayoutHoriz)
+	}
+	dt.DatePicker().Range = dt.Range
+	times := dt.ChildByName("times", 1).(*Layout)
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_Label, "start-lbl")
+	config.Add(KiT_TimePicker, "start")
+	if dt.Range {
+		config.Add(KiT_Label, "end-lbl")
+		config.Add(KiT_TimePicker, "end")
+	}
+	mods, tupdt := times.ConfigChildren(config)
+	if !mods {
+		tupdt = times.UpdateStart()
+	}
+	if dt.Range {
+		times.Child(0).(*Label).SetText("From: ")
+		times.Child(2).(*Label).SetText("  To: ")
+	} else {
+		times.Child(0).(*Label).SetText("Time: ")
+	}
+	for _, tp := range []*TimePicker{dt.TimePicker(false), dt.TimePicker(true)} {
+		if tp == nil {
+			continue
+		}
+		tp.Hour24 = dt.Hour24
+		tp.Seconds = dt.Seconds
+		tp.Config()
+		tp.TimePickerSig.ConnectOnly(dt.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dtt := recv.Embed(KiT_DateTimePicker).(*DateTimePicker)
+			dtt.UpdateFromPickers()
+		})
+	}
+	times.UpdateEnd(tupdt)
+	dt.UpdateEnd(updt)
+}
+
+// UpdateFromPickers sets the Start and End from the days selected in the
+// date picker and the times of day of the time pickers, and emits the
+// signal
+func (dt *DateTimePicker) UpdateFromPickers() {
+	st, ed := dt.DatePicker().DateRange()
+	if st.IsZero() {
+		return
+	}
+	dt.Start = DateWithTime(st, dt.TimePicker(false).Time)
+	dt.End = dt.Start
+	if dt.Range {
+		dt.End = DateWithTime(ed, dt.TimePicker(true).Time)
+		if dt.End.Before(dt.Start) { // same day, earlier time
+			dt.End = dt.Start
+		}
+	}
+	dt.DateTimeSig.Emit(dt.This(), 0, dt.Start)
+}
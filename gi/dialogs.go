@@ -9,6 +9,7 @@ import (
 	"image"
 	"log"
 	"reflect"
+	"time"
 
 	"github.com/iancoleman/strcase"
 
@@ -644,3 +645,59 @@ func StringPromptDialogValue(dlg *Dialog) string {
 	tf := frame.ChildByName("str-field", 0).(*TextField)
 	return tf.Text()
 }
+
+// DateTimeDialog prompts the user for a date and time, with a
+// DateTimePicker -- optionally connects to given signal receiving object
+// and function for dialog signals (nil to ignore).  Viewport is optional to
+// properly contextualize dialog to given master window.
+func DateTimeDialog(avp *Viewport2D, tm time.Time, opts DlgOpts, recv ki.Ki, fun ki.RecvFunc) *Dialog {
+	dlg := NewStdDialog(opts, AddOk, AddCancel)
+	dlg.Modal = true
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	dt := frame.InsertNewChild(KiT_DateTimePicker, prIdx+1, "date-time").(*DateTimePicker)
+	dt.SetDateTime(tm)
+
+	if recv != nil && fun != nil {
+		dlg.DialogSig.Connect(recv, fun)
+	}
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, nil)
+	return dlg
+}
+
+// DateTimeDialogValue gets the date and time the user set.
+func DateTimeDialogValue(dlg *Dialog) time.Time {
+	frame := dlg.Frame()
+	dt := frame.ChildByName("date-time", 0).(*DateTimePicker)
+	return dt.Start
+}
+
+// DateRangeDialog prompts the user for a range of dates and times, with a
+// DateTimePicker -- optionally connects to given signal receiving object
+// and function for dialog signals (nil to ignore).  Viewport is optional to
+// properly contextualize dialog to given master window.
+func DateRangeDialog(avp *Viewport2D, st, ed time.Time, opts DlgOpts, recv ki.Ki, fun ki.RecvFunc) *Dialog {
+	dlg := NewStdDialog(opts, AddOk, AddCancel)
+	dlg.Modal = true
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	dt := frame.InsertNewChild(KiT_DateTimePicker, prIdx+1, "date-time").(*DateTimePicker)
+	dt.SetDateRange(st, ed)
+
+	if recv != nil && fun != nil {
+		dlg.DialogSig.Connect(recv, fun)
+	}
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, nil)
+	return dlg
+}
+
+// DateRangeDialogValue gets the range of dates and times the user set.
+func DateRangeDialogValue(dlg *Dialog) (st, ed time.Time) {
+	frame := dlg.Frame()
+	dt := frame.ChildByName("date-time", 0).(*DateTimePicker)
+	return dt.Start, dt.End
+}
@@ -0,0 +1,508 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/ki"
+)
+
+// EventRecVersion is the version of the event recording file format,
+// saved in each EventRecording, and checked when opening a recording.
+const EventRecVersion = 1
+
+// EventRecording is a recorded sequence of oswin.Event's processed by a
+// Window, along with optional checkpoints of the focus path and widget
+// state, which can be saved to a JSON file and replayed with EventPlayer
+// to reproduce a sequence of user interactions exactly.
+type EventRecording struct {
+	Version      int              `desc:"version of the file format -- see EventRecVersion"`
+	Window       string           `desc:"name of the window that the events were recorded from"`
+	Size         image.Point      `desc:"size of the window in raw display pixels at the start of recording"`
+	LogicalDPI   float32          `desc:"logical DPI of the window at the start of recording"`
+	Created      time.Time        `desc:"when the recording was started"`
+	CheckWidgets []string         `desc:"paths of the widgets whose state is recorded at each checkpoint -- the widget in focus is always recorded"`
+	Events       []*EventRecEvent `desc:"the recorded events and checkpoints, in order"`
+}
+
+// EventRecEvent is one recorded event, or a checkpoint if Check is non-nil.
+// Only the data for the given event Type is set.
+type EventRecEvent struct {
+	T     int64          `desc:"time of the event, in nanoseconds since the start of recording"`
+	Type  string         `json:",omitempty" desc:"oswin.EventType of the event, as a string"`
+	Mouse *EventRecMouse `json:",omitempty" desc:"data for mouse events"`
+	Key   *EventRecKey   `json:",omitempty" desc:"data for key events"`
+	DND   *EventRecDND   `json:",omitempty" desc:"data for external DND events"`
+	IME   *EventRecIME   `json:",omitempty" desc:"data for IME text composition events"`
+	Size  *image.Point   `json:",omitempty" desc:"new window size in raw display pixels for resize events"`
+	Check *EventRecCheck `json:",omitempty" desc:"if non-nil, this is a checkpoint and not an event"`
+}
+
+// EventRecMouse is the data for a recorded mouse event
+type EventRecMouse struct {
+	Where     image.Point   `desc:"mouse location, in raw display pixels"`
+	From      image.Point   `desc:"previous mouse location for move and drag events"`
+	Start     image.Point   `desc:"start of the drag for drag events"`
+	Delta     image.Point   `desc:"scroll delta for scroll events"`
+	Last      int64         `json:",omitempty" desc:"nanoseconds between the previous mouse event and this one for move and drag events"`
+	Button    mouse.Buttons `json:",omitempty" desc:"mouse button"`
+	Action    mouse.Actions `json:",omitempty" desc:"mouse action"`
+	Modifiers int32         `json:",omitempty" desc:"key modifier bitflags"`
+}
+
+// EventRecKey is the data for a recorded key or key chord event
+type EventRecKey struct {
+	Rune      rune        `desc:"key rune"`
+	Code      key.Codes   `desc:"key code"`
+	Action    key.Actions `json:",omitempty" desc:"key action"`
+	Modifiers int32       `json:",omitempty" desc:"key modifier bitflags"`
+}
+
+// EventRecDND is the data for a recorded external DND event
+type EventRecDND struct {
+	Where     image.Point    `desc:"DND location, in raw display pixels"`
+	Action    dnd.Actions    `desc:"DND action"`
+	Modifiers int32          `json:",omitempty" desc:"key modifier bitflags"`
+	Mod       dnd.DropMods   `desc:"DND drop modifier"`
+	Data      mimedata.Mimes `desc:"the dropped data"`
+}
+
+// EventRecIME is the data for a recorded IME text composition event
+type EventRecIME struct {
+	Action   ime.Actions `desc:"composition action"`
+	Text     string      `json:",omitempty" desc:"preedit or committed text"`
+	Cursor   int         `json:",omitempty" desc:"caret position within the preedit text"`
+	SelStart int         `json:",omitempty" desc:"start of the clause being converted"`
+	SelEnd   int         `json:",omitempty" desc:"end of the clause being converted"`
+}
+
+// EventRecCheck records the focus path and widget state at a checkpoint,
+// which is compared with the state during replay.
+type EventRecCheck struct {
+	Name    string            `desc:"optional name of the checkpoint, for reporting"`
+	Focus   string            `desc:"path of the widget in focus, or empty if none"`
+	Widgets map[string]string `desc:"state of the widgets in focus and in CheckWidgets, by path, as returned by EventRecWidgetState"`
+}
+
+// OpenJSON opens a recording from a JSON-formatted file.
+func (er *EventRecording) OpenJSON(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*er = EventRecording{}
+	err = json.Unmarshal(b, er)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if er.Version > EventRecVersion {
+		err = fmt.Errorf("gi.EventRecording: file: %v has version: %v, newer than supported version: %v", filename, er.Version, EventRecVersion)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// SaveJSON saves the recording to a JSON-formatted file.
+func (er *EventRecording) SaveJSON(filename string) error {
+	b, err := json.MarshalIndent(er, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(filename, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// EventRecordable returns true if the given event type is recorded by an
+// EventRecorder: mouse, key, IME, DND and window resize events.  Other events are
+// either generated by the Window itself from these events, or do not
+// depend on the user.
+func EventRecordable(et oswin.EventType) bool {
+	switch et {
+	case oswin.MouseEvent, oswin.MouseMoveEvent, oswin.MouseDragEvent, oswin.MouseScrollEvent,
+		oswin.KeyEvent, oswin.KeyChordEvent, oswin.IMEEvent, oswin.DNDEvent, oswin.WindowResizeEvent:
+		return true
+	}
+	return false
+}
+
+// EventRecWidgetState returns a string summarizing the state of the given
+// widget for checkpoints: its focus, inactive, selected and checked state,
+// and its text for buttons, labels and text fields.
+func EventRecWidgetState(k ki.Ki) string {
+	var sb strings.Builder
+	if nb, ok := k.(Node); ok {
+		nbb := nb.AsGiNode()
+		if nbb.HasFocus() {
+			sb.WriteString("focus ")
+		}
+		if nbb.IsInactive() {
+			sb.WriteString("inactive ")
+		}
+		if nbb.IsSelected() {
+			sb.WriteString("selected ")
+		}
+	}
+	switch w := k.(type) {
+	case ButtonWidget:
+		bb := w.AsButtonBase()
+		if bb.IsChecked() {
+			sb.WriteString("checked ")
+		}
+		sb.WriteString(fmt.Sprintf("text: %q", bb.Text))
+	case *Label:
+		sb.WriteString(fmt.Sprintf("text: %q", w.Text))
+	case *TextField:
+		sb.WriteString(fmt.Sprintf("text: %q", string(w.EditTxt))) // not Text(), which applies edits
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// EventRecCheckpoint returns the current checkpoint state of the given
+// window: the focus path, and the state of the widget in focus and the
+// widgets at given paths, which can be relative to the window Viewport or
+// absolute paths.
+func EventRecCheckpoint(w *Window, name string, widgets []string) *EventRecCheck {
+	ck := &EventRecCheck{Name: name, Widgets: make(map[string]string)}
+	if foc := w.EventMgr.CurFocus(); foc != nil && foc.This() != nil {
+		ck.Focus = foc.Path()
+		ck.Widgets[ck.Focus] = EventRecWidgetState(foc)
+	}
+	for _, wp := range widgets {
+		var wk ki.Ki
+		if strings.HasPrefix(wp, "/") {
+			wk = w.FindPath(wp)
+		} else {
+			wk = w.Viewport.FindPath(wp)
+		}
+		if wk == nil {
+			ck.Widgets[wp] = "<not found>"
+			continue
+		}
+		ck.Widgets[wp] = EventRecWidgetState(wk)
+	}
+	return ck
+}
+
+// Diff returns a list of the differences between this (recorded)
+// checkpoint and the given (replayed) one, or nil if they are the same.
+func (ck *EventRecCheck) Diff(oc *EventRecCheck) []string {
+	var diffs []string
+	if ck.Focus != oc.Focus {
+		diffs = append(diffs, fmt.Sprintf("focus: %q, recorded: %q", oc.Focus, ck.Focus))
+	}
+	wps := make([]string, 0, len(ck.Widgets))
+	for wp := range ck.Widgets {
+		wps = append(wps, wp)
+	}
+	sort.Strings(wps)
+	for _, wp := range wps {
+		st := ck.Widgets[wp]
+		if ost, has := oc.Widgets[wp]; !has || ost != st {
+			diffs = append(diffs, fmt.Sprintf("widget: %v: %q, recorded: %q", wp, ost, st))
+		}
+	}
+	return diffs
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Recorder
+
+// EventRecorder records the events processed by a Window into an
+// EventRecording -- see Window.StartEventRecording.
+type EventRecorder struct {
+	Rec       EventRecording `desc:"the recording"`
+	AutoCheck bool           `desc:"if true, a checkpoint is automatically recorded after each mouse button, key chord and DND event has been processed"`
+	start     time.Time
+	mu        sync.Mutex
+}
+
+// StartEventRecording starts recording all of the events processed by the
+// window into a new EventRecorder, which is returned.  The recorder records
+// a checkpoint of the focus path and state of the given widgets (see
+// EventRecCheckpoint) after each mouse button, key chord and DND event if
+// autoCheck is true, and whenever its Checkpoint method is called.
+func (w *Window) StartEventRecording(autoCheck bool, checkWidgets ...string) *EventRecorder {
+	rec := &EventRecorder{AutoCheck: autoCheck}
+	rec.start = time.Now()
+	rec.Rec = EventRecording{
+		Version:      EventRecVersion,
+		Window:       w.Nm,
+		Size:         w.OSWin.Size(),
+		LogicalDPI:   w.OSWin.LogicalDPI(),
+		Created:      rec.start,
+		CheckWidgets: checkWidgets,
+	}
+	w.EventRec = rec
+	return rec
+}
+
+// StopEventRecording stops any current event recording, returning the
+// recording, or nil if not recording.
+func (w *Window) StopEventRecording() *EventRecording {
+	rec := w.EventRec
+	if rec == nil {
+		return nil
+	}
+	w.EventRec = nil
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return &rec.Rec
+}
+
+// RecordEvent records given event if it is EventRecordable, returning
+// true if so.  Called by Window.ProcessEvent prior to processing.
+func (rec *EventRecorder) RecordEvent(w *Window, evi oswin.Event) bool {
+	et := evi.Type()
+	if !EventRecordable(et) {
+		return false
+	}
+	re := &EventRecEvent{Type: et.String()}
+	tm := evi.Time()
+	if tm.Before(rec.start) {
+		tm = time.Now()
+	}
+	re.T = int64(tm.Sub(rec.start))
+	switch e := evi.(type) {
+	case *mouse.DragEvent:
+		re.Mouse = newEventRecMouse(&e.Event)
+		re.Mouse.From = e.From
+		re.Mouse.Start = e.Start
+		re.Mouse.Last = eventRecLast(tm, e.LastTime)
+	case *mouse.MoveEvent:
+		re.Mouse = newEventRecMouse(&e.Event)
+		re.Mouse.From = e.From
+		re.Mouse.Last = eventRecLast(tm, e.LastTime)
+	case *mouse.ScrollEvent:
+		re.Mouse = newEventRecMouse(&e.Event)
+		re.Mouse.Delta = e.Delta
+	case *mouse.Event:
+		re.Mouse = newEventRecMouse(e)
+	case *key.ChordEvent:
+		re.Key = newEventRecKey(&e.Event)
+	case *key.Event:
+		re.Key = newEventRecKey(e)
+	case *ime.Event:
+		re.IME = &EventRecIME{Action: e.Action, Text: e.Text, Cursor: e.Cursor, SelStart: e.SelStart, SelEnd: e.SelEnd}
+	case *dnd.Event:
+		if e.Source != nil { // internal events are regenerated from mouse events
+			return false
+		}
+		re.DND = &EventRecDND{Where: e.Where, Action: e.Action, Modifiers: e.Modifiers, Mod: e.Mod, Data: e.Data}
+	case *window.Event:
+		sz := w.OSWin.Size()
+		re.Size = &sz
+	default:
+		return false
+	}
+	rec.mu.Lock()
+	rec.Rec.Events = append(rec.Rec.Events, re)
+	rec.mu.Unlock()
+	return true
+}
+
+// EventProcessed is called by Window.ProcessEvent after processing an
+// event that was recorded, and records a checkpoint if AutoCheck is set.
+func (rec *EventRecorder) EventProcessed(w *Window, evi oswin.Event) {
+	if !rec.AutoCheck {
+		return
+	}
+	if EventRecAutoCheck(evi) {
+		rec.Checkpoint(w, "")
+	}
+}
+
+// EventRecAutoCheck returns true if given event is one where a checkpoint
+// is taken when AutoCheck is on: mouse button, key chord and DND events.
+func EventRecAutoCheck(evi oswin.Event) bool {
+	switch evi.Type() {
+	case oswin.MouseEvent, oswin.KeyChordEvent, oswin.DNDEvent:
+		return true
+	}
+	return false
+}
+
+// Checkpoint records a checkpoint with given name of the current focus
+// path and widget state of the window.  This should generally be called
+// from the window event loop, e.g., in a widget event handler, so that it
+// is properly ordered relative to the events.
+func (rec *EventRecorder) Checkpoint(w *Window, name string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	ck := EventRecCheckpoint(w, name, rec.Rec.CheckWidgets)
+	rec.Rec.Events = append(rec.Rec.Events, &EventRecEvent{T: int64(time.Since(rec.start)), Check: ck})
+}
+
+func newEventRecMouse(e *mouse.Event) *EventRecMouse {
+	return &EventRecMouse{Where: e.Where, Button: e.Button, Action: e.Action, Modifiers: e.Modifiers}
+}
+
+func newEventRecKey(e *key.Event) *EventRecKey {
+	return &EventRecKey{Rune: e.Rune, Code: e.Code, Action: e.Action, Modifiers: e.Modifiers}
+}
+
+// eventRecLast returns the nanoseconds between the last time and the
+// event time, or 0 if last is not set.
+func eventRecLast(tm, last time.Time) int64 {
+	if last.IsZero() || last.After(tm) {
+		return 0
+	}
+	return int64(tm.Sub(last))
+}
+
+// Event returns a new oswin.Event for this recorded event, initialized
+// to the current time, or nil for checkpoints and resize events (which
+// are replayed by resizing the window to Size).
+func (re *EventRecEvent) Event() (oswin.Event, error) {
+	if re.Check != nil {
+		return nil, nil
+	}
+	var et oswin.EventType
+	err := et.FromString(re.Type)
+	if err != nil {
+		return nil, err
+	}
+	if et == oswin.WindowResizeEvent {
+		return nil, nil
+	}
+	now := time.Now()
+	var evi oswin.Event
+	switch {
+	case re.Mouse != nil:
+		rm := re.Mouse
+		me := mouse.Event{Where: rm.Where, Button: rm.Button, Action: rm.Action, Modifiers: rm.Modifiers}
+		switch et {
+		case oswin.MouseEvent:
+			evi = &me
+		case oswin.MouseMoveEvent:
+			evi = &mouse.MoveEvent{Event: me, From: rm.From, LastTime: now.Add(-time.Duration(rm.Last))}
+		case oswin.MouseDragEvent:
+			evi = &mouse.DragEvent{MoveEvent: mouse.MoveEvent{Event: me, From: rm.From, LastTime: now.Add(-time.Duration(rm.Last))}, Start: rm.Start}
+		case oswin.MouseScrollEvent:
+			evi = &mouse.ScrollEvent{Event: me, Delta: rm.Delta}
+		}
+	case re.Key != nil:
+		rk := re.Key
+		ke := key.Event{Rune: rk.Rune, Code: rk.Code, Action: rk.Action, Modifiers: rk.Modifiers}
+		switch et {
+		case oswin.KeyEvent:
+			evi = &ke
+		case oswin.KeyChordEvent:
+			evi = &key.ChordEvent{Event: ke}
+		}
+	case re.IME != nil:
+		ri := re.IME
+		if et == oswin.IMEEvent {
+			evi = &ime.Event{Action: ri.Action, Text: ri.Text, Cursor: ri.Cursor, SelStart: ri.SelStart, SelEnd: ri.SelEnd}
+		}
+	case re.DND != nil:
+		rd := re.DND
+		if et == oswin.DNDEvent {
+			evi = &dnd.Event{Where: rd.Where, Action: rd.Action, Modifiers: rd.Modifiers, Mod: rd.Mod, Data: rd.Data}
+		}
+	}
+	if evi == nil {
+		return nil, fmt.Errorf("gi.EventRecEvent: event type: %v does not have data to be replayed", et)
+	}
+	evi.Init()
+	return evi, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Player
+
+// EventPlayer replays an EventRecording into a Window, by sending the
+// recorded events to the window's oswin.EventDeque.  The player waits for
+// each event to be fully processed before sending the next one, so that
+// replay is deterministic regardless of timing.
+type EventPlayer struct {
+	Rec            *EventRecording `desc:"the recording to play"`
+	RealTime       bool            `desc:"if true, events are sent at the same times relative to the start as when recorded -- otherwise they are sent as fast as possible"`
+	Check          bool            `desc:"if true, the focus path and widget state are compared with those recorded at each checkpoint"`
+	StopOnMismatch bool            `desc:"if true, stop playing at the first checkpoint that does not match"`
+	Mismatches     []string        `desc:"descriptions of the checkpoint mismatches from the last Play"`
+}
+
+// NewEventPlayer returns a new player for given recording.
+func NewEventPlayer(rec *EventRecording, realTime, check bool) *EventPlayer {
+	return &EventPlayer{Rec: rec, RealTime: realTime, Check: check}
+}
+
+// Play replays the recording into given window, returning when done.
+// This must NOT be called from the window's event loop.
+// If Check is set, an error is returned if any checkpoints did not
+// match, with the details in Mismatches.
+func (pl *EventPlayer) Play(w *Window) error {
+	pl.Mismatches = nil
+	if pl.Rec.Size != (image.Point{}) && w.OSWin.Size() != pl.Rec.Size {
+		w.OSWin.SetSize(pl.Rec.Size)
+		w.SendSyncEvent(nil)
+	}
+	start := time.Now()
+	for i, re := range pl.Rec.Events {
+		if w.IsClosed() {
+			return errors.New("gi.EventPlayer: window closed during play")
+		}
+		if pl.RealTime {
+			if dt := time.Duration(re.T) - time.Since(start); dt > 0 {
+				time.Sleep(dt)
+			}
+		}
+		if re.Check != nil {
+			if !pl.Check {
+				continue
+			}
+			w.SendSyncEvent(func(w *Window) {
+				ck := EventRecCheckpoint(w, re.Check.Name, pl.Rec.CheckWidgets)
+				for _, df := range re.Check.Diff(ck) {
+					pl.Mismatches = append(pl.Mismatches, fmt.Sprintf("event %d: checkpoint %q: %v", i, re.Check.Name, df))
+				}
+			})
+			if pl.StopOnMismatch && len(pl.Mismatches) > 0 {
+				break
+			}
+			continue
+		}
+		evi, err := re.Event()
+		if err != nil {
+			return err
+		}
+		if evi == nil {
+			if re.Size != nil {
+				w.OSWin.SetSize(*re.Size)
+			}
+		} else {
+			w.OSWin.Send(evi)
+		}
+		w.SendSyncEvent(nil)
+	}
+	if len(pl.Mismatches) > 0 {
+		return fmt.Errorf("gi.EventPlayer: %d checkpoint mismatches, first: %v", len(pl.Mismatches), pl.Mismatches[0])
+	}
+	return nil
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
-	"github.com/goki/mat32"
 )
 
 // Frame is a Layout that renders a background according to the
@@ -77,38 +76,37 @@ func (fr *Frame) FrameStdRender() {
 
 	pos := fr.LayState.Alloc.Pos
 	sz := fr.LayState.Alloc.Size
-	pc.FillBox(rs, pos, sz, &st.Font.BgColor)
-
-	rad := st.Border.Radius.Dots
-	pos = pos.AddScalar(st.Layout.Margin.Dots).SubScalar(0.5 * st.Border.Width.Dots)
-	sz = sz.SubScalar(2.0 * st.Layout.Margin.Dots).AddScalar(st.Border.Width.Dots)
-
-	// then any shadow -- todo: optimize!
-	if st.BoxShadow.HasShadow() {
-		spos := pos.Add(mat32.Vec2{st.BoxShadow.HOffset.Dots, st.BoxShadow.VOffset.Dots})
-		pc.StrokeStyle.SetColor(nil)
-		pc.FillStyle.SetColor(&st.BoxShadow.Color)
-		if rad == 0.0 {
-			pc.DrawRectangle(rs, spos.X, spos.Y, sz.X, sz.Y)
-		} else {
-			pc.DrawRoundedRectangle(rs, spos.X, spos.Y, sz.X, sz.Y, rad)
+
+	// border is centered on the edge of the margin
+	rad := st.Border.Radius.Dots()
+	mg := st.Layout.Margin.Dots()
+	hbw := st.Border.WidthDots().MulScalar(0.5)
+	pos = pos.Add(mg.Pos()).Sub(hbw.Pos())
+	sz = sz.Sub(mg.Size()).Add(hbw.Size())
+	// outer edges of the border
+	bpos := pos.Sub(hbw.Pos())
+	bsz := sz.Add(hbw.Size())
+
+	// with shadows, the background only fills the box within the margin,
+	// so the outset shadows show in the margin
+	if len(st.BoxShadows()) > 0 {
+		fr.RenderBoxShadows(bpos, bsz, rad, st, false)
+		if !st.Font.BgColor.IsNil() {
+			pc.FillStyle.SetColorSpec(&st.Font.BgColor)
+			pc.StrokeStyle.SetColor(nil)
+			fr.RenderBoxImpl(bpos, bsz, rad)
 		}
-		pc.FillStrokeClear(rs)
+		fr.RenderBoxShadows(bpos, bsz, rad, st, true)
+	} else {
+		pc.FillBox(rs, fr.LayState.Alloc.Pos, fr.LayState.Alloc.Size, &st.Font.BgColor)
 	}
 
 	if fr.Lay == LayoutGrid && fr.Stripes != NoStripes {
 		fr.RenderStripes()
 	}
 
-	pc.FillStyle.SetColor(nil)
-	pc.StrokeStyle.SetColor(&st.Border.Color)
-	pc.StrokeStyle.Width = st.Border.Width
-	if rad == 0.0 {
-		pc.DrawRectangle(rs, pos.X, pos.Y, sz.X, sz.Y)
-	} else {
-		pc.DrawRoundedRectangle(rs, pos.X, pos.Y, sz.X, sz.Y, rad)
-	}
-	pc.FillStrokeClear(rs)
+	// RenderBorder takes the outer edges of the border
+	fr.RenderBorder(bpos, bsz, &st.Border)
 }
 
 func (fr *Frame) RenderStripes() {
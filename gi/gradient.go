@@ -35,8 +35,12 @@ func (gr *Gradient) CopyFieldsFrom(frm interface{}) {
 	gr.StopsName = fr.StopsName
 }
 
-// GradientType returns the SVG-style type name of gradient: linearGradient or radialGradient
+// GradientType returns the SVG-style type name of gradient: linearGradient,
+// radialGradient or conicGradient
 func (gr *Gradient) GradientType() string {
+	if gr.Grad.Source == gist.ConicGradient {
+		return "conicGradient"
+	}
 	if gr.Grad.Gradient != nil && gr.Grad.Gradient.IsRadial {
 		return "radialGradient"
 	}
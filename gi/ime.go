@@ -0,0 +1,65 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/ki/ints"
+)
+
+// Preedit is the state of an input method editor (IME) text composition in
+// progress in a text editing widget: the uncommitted preedit text, which the
+// widget renders inline at its cursor, underlined -- see the ime package.
+type Preedit struct {
+	Text     []rune `desc:"the preedit text being composed -- empty if no composition is in progress"`
+	Cursor   int    `desc:"caret position within Text, in runes"`
+	SelStart int    `desc:"start of the clause being converted within Text, in runes, which is highlighted"`
+	SelEnd   int    `desc:"end of the clause being converted within Text, in runes"`
+}
+
+// IsActive returns true if there is preedit text
+func (pe *Preedit) IsActive() bool {
+	return len(pe.Text) > 0
+}
+
+// Set sets the preedit state from an ime.Preedit event, ensuring that
+// the cursor and selection are in range
+func (pe *Preedit) Set(e *ime.Event) {
+	pe.Text = []rune(e.Text)
+	sz := len(pe.Text)
+	pe.Cursor = ints.MinInt(ints.MaxInt(e.Cursor, 0), sz)
+	pe.SelStart = ints.MinInt(ints.MaxInt(e.SelStart, 0), sz)
+	pe.SelEnd = ints.MinInt(ints.MaxInt(e.SelEnd, pe.SelStart), sz)
+}
+
+// Reset resets the preedit state to no composition in progress
+func (pe *Preedit) Reset() {
+	*pe = Preedit{}
+}
+
+// Decorate underlines the preedit text within the given rendered text,
+// where the preedit starts at given rune index in it, and highlights the
+// clause being converted with given background color.
+func (pe *Preedit) Decorate(tr *girl.Text, st int, selBg color.Color) {
+	tr.SetDecoRange(st, st+len(pe.Text), gist.DecoUnderline, nil)
+	if pe.SelEnd > pe.SelStart && selBg != nil {
+		tr.SetDecoRange(st+pe.SelStart, st+pe.SelEnd, gist.DecoUnderline, selBg)
+	}
+}
+
+// SetIMECaret reports the rectangle of the text caret of the widget in
+// focus, in window coordinates, to the OS window, so that the IME can
+// position its candidate window next to it.
+func (w *Window) SetIMECaret(r image.Rectangle) {
+	if w.OSWin == nil {
+		return
+	}
+	w.OSWin.SetIMECaret(r)
+}
@@ -80,6 +80,11 @@ var LabelProps = ki.Props{
 	},
 }
 
+// LabelOverflowMinChars is the minimum width, in characters, needed by a
+// label whose text can be elided or shrunk to fit (see gist.Text Overflow
+// and ShrinkToFit) -- its preferred width is the full width of the text
+var LabelOverflowMinChars = float32(4)
+
 // LabelStates are mutually-exclusive label states -- determines appearance
 type LabelStates int32
 
@@ -141,7 +146,7 @@ func (lb *Label) SetText(txt string) {
 		sz = lb.LayState.SizePrefOrMax()
 	}
 	if !sz.IsNil() {
-		sz.SetSubScalar(2 * spc)
+		sz.SetSub(spc.Size())
 	}
 	lb.Render.LayoutStdLR(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
 	lb.StyMu.RUnlock()
@@ -151,19 +156,21 @@ func (lb *Label) SetText(txt string) {
 // SetStateStyle sets the style based on the inactive, selected flags
 func (lb *Label) SetStateStyle() {
 	lb.StyMu.Lock()
+	var st gist.Style
 	if lb.IsInactive() {
-		lb.Sty = lb.StateStyles[LabelInactive]
+		st = lb.StateStyles[LabelInactive]
 		if lb.Redrawable && !lb.CurBgColor.IsNil() {
-			lb.Sty.Font.BgColor.SetColor(lb.CurBgColor)
+			st.Font.BgColor.SetColor(lb.CurBgColor)
 		}
 	} else if lb.IsSelected() {
-		lb.Sty = lb.StateStyles[LabelSelected]
+		st = lb.StateStyles[LabelSelected]
 	} else {
-		lb.Sty = lb.StateStyles[LabelActive]
+		st = lb.StateStyles[LabelActive]
 		if (lb.Selectable || lb.Redrawable) && !lb.CurBgColor.IsNil() {
-			lb.Sty.Font.BgColor.SetColor(lb.CurBgColor)
+			st.Font.BgColor.SetColor(lb.CurBgColor)
 		}
 	}
+	lb.SetCurStyle(&st)
 	lb.StyMu.Unlock()
 }
 
@@ -204,13 +211,17 @@ func (lb *Label) HoverEvent() {
 				}
 			}
 		}
-		if llb.Tooltip != "" {
+		tt := llb.Tooltip
+		if tt == "" && llb.Render.Truncated {
+			tt = llb.Text // show the full text
+		}
+		if tt != "" {
 			me.SetProcessed()
 			llb.BBoxMu.RLock()
 			pos := llb.WinBBox.Max
 			llb.BBoxMu.RUnlock()
 			pos.X -= 20
-			PopupTooltip(llb.Tooltip, pos.X, pos.Y, llb.Viewport, llb.Nm)
+			PopupTooltip(tt, pos.X, pos.Y, llb.Viewport, llb.Nm)
 		}
 	})
 }
@@ -282,7 +293,7 @@ func (lb *Label) LabelEvents() {
 }
 
 func (lb *Label) GrabCurBgColor() {
-	if lb.Viewport == nil || lb.IsSelected() {
+	if lb.Viewport == nil || lb.IsSelected() || lb.Viewport.Render.Deferred() { // not rendered yet if deferred
 		return
 	}
 	if !gist.RebuildDefaultStyles && !lb.CurBgColor.IsNil() {
@@ -339,7 +350,7 @@ func (lb *Label) LayoutLabel() {
 	spc := lb.BoxSpace()
 	sz := lb.LayState.SizePrefOrMax()
 	if !sz.IsNil() {
-		sz.SetSubScalar(2 * spc)
+		sz.SetSub(spc.Size())
 	}
 	lb.Render.LayoutStdLR(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
 }
@@ -360,6 +371,13 @@ func (lb *Label) Size2D(iter int) {
 		sz := lb.LayState.Size.Pref // SizePrefOrMax()
 		sz = sz.Max(lb.Render.Size)
 		lb.Size2DFromWH(sz.X, sz.Y)
+		if lb.Sty.Text.HasTextOverflow() {
+			// text can be elided or shrunk, so we only need a few chars
+			lb.LayState.Size.Pref.X = mat32.Max(lb.LayState.Size.Pref.X, lb.LayState.Alloc.Size.X)
+			need := lb.BoxSpace().Size().X + LabelOverflowMinChars*lb.Sty.Font.Face.Metrics.Ch
+			need = mat32.Max(need, lb.LayState.Size.Need.X)
+			lb.LayState.Alloc.Size.X = mat32.Min(need, lb.LayState.Alloc.Size.X)
+		}
 	}
 }
 
@@ -386,7 +404,7 @@ func (lb *Label) Layout2D(parBBox image.Rectangle, iter int) bool {
 func (lb *Label) TextPos() mat32.Vec2 {
 	lb.StyMu.RLock()
 	sty := &lb.Sty
-	pos := lb.LayState.Alloc.Pos.AddScalar(sty.BoxSpace())
+	pos := lb.LayState.Alloc.Pos.Add(sty.BoxSpace().Pos())
 	lb.StyMu.RUnlock()
 	return pos
 }
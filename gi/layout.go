@@ -28,6 +28,17 @@ import (
 // when computing the preferred size (VpFlagPrefSizing)
 var LayoutPrefMaxRows = 20
 
+// PrefMaxRows returns the maximum number of rows to use for computing the
+// preferred size of a layout or view with given number of rows in given
+// viewport: LayoutPrefMaxRows, except when rendering for vector output
+// (VpFlagPrinting), which uses all of the rows
+func PrefMaxRows(vp *Viewport2D, nrows int) int {
+	if vp != nil && vp.HasFlag(int(VpFlagPrinting)) {
+		return nrows
+	}
+	return LayoutPrefMaxRows
+}
+
 // LayoutPrefMaxCols is maximum number of columns to use in a grid layout
 // when computing the preferred size (VpFlagPrefSizing)
 var LayoutPrefMaxCols = 20
@@ -100,6 +111,7 @@ type GridData struct {
 	SizeNeed    float32
 	SizePref    float32
 	SizeMax     float32
+	SizeFr      float32 `desc:"flexible fraction of the remaining space that this track takes (fr units), from the grid template -- 0 = not flexible"`
 	AllocSize   float32
 	AllocPosRel float32
 }
@@ -143,6 +155,7 @@ type Layout struct {
 	Scrolls       [2]*ScrollBar       `copy:"-" json:"-" xml:"-" desc:"scroll bars -- we fully manage them as needed"`
 	GridSize      image.Point         `copy:"-" json:"-" xml:"-" desc:"computed size of a grid layout based on all the constraints -- computed during Size2D pass"`
 	GridData      [RowColN][]GridData `copy:"-" json:"-" xml:"-" desc:"grid data for rows in [0] and cols in [1]"`
+	GridPos       []image.Rectangle   `copy:"-" json:"-" xml:"-" desc:"cells occupied by each child in a grid layout, with X = cols and Y = rows, and Max exclusive -- computed during Size2D pass"`
 	FlowBreaks    []int               `copy:"-" json:"-" xml:"-" desc:"line breaks for flow layout"`
 	NeedsRedo     bool                `copy:"-" json:"-" xml:"-" desc:"true if this layout got a redo = true on previous iteration -- otherwise it just skips any re-layout on subsequent iteration"`
 	FocusName     string              `copy:"-" json:"-" xml:"-" desc:"accumulated name to search for when keys are typed"`
@@ -188,12 +201,12 @@ const (
 	// LayoutVert arranges items vertically in a column
 	LayoutVert
 
-	// LayoutGrid arranges items according to a regular grid
+	// LayoutGrid arranges items according to a grid, with track sizes from the
+	// grid-template-columns and grid-template-rows styles (auto by default),
+	// and items placed by their grid-area in the grid-template-areas, their
+	// row and col, or otherwise in order, spanning row-span and col-span cells
 	LayoutGrid
 
-	// todo: add LayoutGridIrreg that deals with irregular grids with spans etc -- keep
-	// the basic grid for fully regular cases -- need high performance for large grids
-
 	// LayoutHorizFlow arranges items horizontally across a row, overflowing
 	// vertically as needed.  Ballpark target width or height props should be set
 	// to generate initial first-pass sizing estimates.
@@ -209,6 +222,13 @@ const (
 	// dimension
 	LayoutStacked
 
+	// LayoutFlex arranges items according to the CSS flexbox model, along the
+	// main axis given by the flex-direction style, optionally wrapping onto
+	// multiple lines (flex-wrap), with items sized by their flex-grow,
+	// flex-shrink and flex-basis, and positioned by justify-content,
+	// align-items, align-self and gap.  Spacing is not used -- use gap instead.
+	LayoutFlex
+
 	// LayoutNil is a nil layout -- doesn't do anything -- for cases when a
 	// parent wants to take over the job of the layout
 	LayoutNil
@@ -247,13 +267,14 @@ var LayoutDefault Layout
 // avail
 func (ly *Layout) AvailSize() mat32.Vec2 {
 	spc := ly.BoxSpace()
-	avail := ly.LayState.Alloc.Size.SubScalar(spc) // spc is for right size space
+	rbspc := mat32.Vec2{X: spc.Right, Y: spc.Bottom}
+	avail := ly.LayState.Alloc.Size.Sub(rbspc) // spc is for right size space
 	parni, _ := KiToNode2D(ly.Par)
 	if parni != nil {
 		vp := parni.AsViewport2D()
 		if vp != nil {
 			if vp.ViewportSafe() == nil {
-				avail = mat32.NewVec2FmPoint(ly.VpBBox.Size()).SubScalar(spc)
+				avail = mat32.NewVec2FmPoint(ly.VpBBox.Size()).Sub(rbspc)
 				// fmt.Printf("non-nil par ly: %v vp: %v %v\n", ly.Path(), vp.Path(), avail)
 			}
 		}
@@ -318,7 +339,7 @@ func (ly *Layout) SetScroll(d mat32.Dims) {
 		sc.Min = 0.0
 	}
 	spc := ly.BoxSpace()
-	avail := ly.AvailSize().SubScalar(spc * 2.0)
+	avail := ly.AvailSize().Sub(spc.Size())
 	sc := ly.Scrolls[d]
 	if d == mat32.X {
 		sc.SetFixedHeight(ly.Sty.Layout.ScrollBarWidth)
@@ -331,7 +352,7 @@ func (ly *Layout) SetScroll(d mat32.Dims) {
 	sc.Max = ly.ChildSize.Dim(d) + ly.ExtraSize.Dim(d) // only scrollbar
 	sc.Step = ly.Sty.Font.Size.Dots                    // step by lines
 	sc.PageStep = 10.0 * sc.Step                       // todo: more dynamic
-	sc.ThumbVal = avail.Dim(d) - spc
+	sc.ThumbVal = avail.Dim(d) - spc.Pos().Dim(d)
 	sc.TrackThr = sc.Step
 	sc.Value = mat32.Min(sc.Value, sc.Max-sc.ThumbVal) // keep in range
 	// fmt.Printf("set sc lay: %v  max: %v  val: %v\n", ly.Path(), sc.Max, sc.Value)
@@ -386,9 +407,9 @@ func (ly *Layout) LayoutScrolls() {
 		if ly.HasScroll[d] {
 			sc := ly.Scrolls[d]
 			sc.Size2D(0)
-			sc.LayState.Alloc.PosRel.SetDim(d, spc)
+			sc.LayState.Alloc.PosRel.SetDim(d, spc.Pos().Dim(d))
 			sc.LayState.Alloc.PosRel.SetDim(odim, avail.Dim(odim)-sbw-2.0)
-			sc.LayState.Alloc.Size.SetDim(d, avail.Dim(d)-spc)
+			sc.LayState.Alloc.Size.SetDim(d, avail.Dim(d)-spc.Pos().Dim(d))
 			if ly.HasScroll[odim] { // make room for other
 				sc.LayState.Alloc.Size.SetSubDim(d, sbw)
 			}
@@ -1162,6 +1183,8 @@ func (ly *Layout) Size2D(iter int) {
 		GatherSizesFlow(ly, iter)
 	case LayoutGrid:
 		GatherSizesGrid(ly)
+	case LayoutFlex:
+		GatherSizesFlex(ly, iter)
 	default:
 		GatherSizes(ly)
 	}
@@ -1192,6 +1215,8 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 		redo = LayoutFlow(ly, mat32.X, iter)
 	case LayoutVertFlow:
 		redo = LayoutFlow(ly, mat32.Y, iter)
+	case LayoutFlex:
+		redo = LayoutFlexLay(ly, iter)
 	case LayoutNil:
 		// nothing
 	}
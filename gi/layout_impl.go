@@ -6,6 +6,7 @@ package gi
 
 import (
 	"fmt"
+	"image"
 
 	"github.com/goki/gi/gist"
 	"github.com/goki/ki/ints"
@@ -121,8 +122,8 @@ func GatherSizes(ly *Layout) {
 	}
 
 	spc := ly.BoxSpace()
-	ly.LayState.Size.Need.SetAddScalar(2.0 * spc)
-	ly.LayState.Size.Pref.SetAddScalar(2.0 * spc)
+	ly.LayState.Size.Need.SetAdd(spc.Size())
+	ly.LayState.Size.Pref.SetAdd(spc.Size())
 
 	elspc := float32(0.0)
 	if sz >= 2 {
@@ -228,8 +229,8 @@ func GatherSizesFlow(ly *Layout, iter int) {
 	ly.LayState.Size.Pref.SetMaxDim(odim, oPref)
 
 	spc := ly.BoxSpace()
-	ly.LayState.Size.Need.SetAddScalar(2.0 * spc)
-	ly.LayState.Size.Pref.SetAddScalar(2.0 * spc)
+	ly.LayState.Size.Need.SetAdd(spc.Size())
+	ly.LayState.Size.Pref.SetAdd(spc.Size())
 
 	elspc := float32(0.0)
 	if sz >= 2 {
@@ -250,21 +251,99 @@ func GatherSizesFlow(ly *Layout, iter int) {
 	}
 }
 
-// todo: grid does not process spans at all yet -- assumes = 1
-
-// GatherSizesGrid is size first pass: gather the size information from the
-// children, grid version
-func GatherSizesGrid(ly *Layout) {
-	if len(ly.Kids) == 0 {
+// GatherSizesFlex is size first pass: gather the size information from the
+// children, flex version.  The need along the main axis is the sum of the
+// item needs, or the largest single item need if wrapping, and the pref
+// is the sum of the item flex-basis sizes, all on one line.  For wrapping,
+// the actual cross size is only known after layout, which is used
+// on subsequent iterations.
+func GatherSizesFlex(ly *Layout, iter int) {
+	sz := len(ly.Kids)
+	if sz == 0 {
 		return
 	}
+	ly.ChildrenUpdateSizes()
+	dim, odim, _ := ly.Sty.Layout.FlexDims()
+	items := flexItems(ly, dim)
+	if len(items) == 0 {
+		return
+	}
+	wrap := ly.Sty.Layout.FlexWrap
+	gaps := float32(len(items)-1) * ly.Sty.Layout.Gap.Dots
+
+	var sumNeed, sumHyp, maxNeed mat32.Vec2
+	for i := range items {
+		fi := &items[i]
+		sumNeed.SetAddDim(dim, fi.need)
+		sumHyp.SetAddDim(dim, fi.hyp)
+		maxNeed = maxNeed.Max(fi.ni.LayState.Size.Need)
+		sumHyp.SetMaxDim(odim, fi.ni.LayState.Size.Pref.Dim(odim))
+	}
+	need := maxNeed
+	if !wrap {
+		need.SetDim(dim, sumNeed.Dim(dim)+gaps)
+	}
+	pref := sumHyp
+	pref.SetAddDim(dim, gaps)
+
+	prefSizing := false
+	mvp := ly.ViewportSafe()
+	if mvp != nil && mvp.HasFlag(int(VpFlagPrefSizing)) {
+		prefSizing = ly.Sty.Layout.Overflow == gist.OverflowScroll // special case
+	}
+
+	for d := mat32.X; d <= mat32.Y; d++ {
+		spref := ly.LayState.Size.Pref.Dim(d)
+		if prefSizing || spref == 0 {
+			ly.LayState.Size.Need.SetMaxDim(d, need.Dim(d))
+			ly.LayState.Size.Pref.SetMaxDim(d, pref.Dim(d))
+		} else { // use target size from style
+			ly.LayState.Size.Need.SetDim(d, spref)
+		}
+	}
+
+	spc := ly.BoxSpace()
+	ly.LayState.Size.Need.SetAdd(spc.Size())
+	ly.LayState.Size.Pref.SetAdd(spc.Size())
+
+	if iter > 0 && wrap {
+		osz := ly.ChildSize.Dim(odim) + spc.Size().Dim(odim)
+		ly.LayState.Size.Need.SetMaxDim(odim, osz)
+		ly.LayState.Size.Pref.SetMaxDim(odim, osz)
+	}
+
+	ly.LayState.UpdateSizes() // enforce max and normal ordering, etc
+	if Layout2DTrace {
+		fmt.Printf("Size:   %v gather sizes flex need: %v, pref: %v\n", ly.Path(), ly.LayState.Size.Need, ly.LayState.Size.Pref)
+	}
+}
 
-	cols := ly.Sty.Layout.Columns
-	rows := 0
+// GridPlaceItems determines the cells occupied by each child of a grid
+// layout, in GridPos, and the overall number of columns and rows, in GridSize.
+// Children are placed in the area of the grid-template-areas named by their
+// grid-area, or at their explicit row, col (if either is > 0), and otherwise
+// auto-placed in order, row by row, in the next free cells that fit their
+// row-span and col-span, as in CSS grid-auto-flow: row.  The number of
+// columns is the max of the columns style, the grid-template-columns and
+// areas, and the explicitly placed items, or the square root of the number
+// of children if none of these is set.
+func GridPlaceItems(ly *Layout) {
+	lst := &ly.Sty.Layout
+	areas := lst.GridTemplateAreas
+	cols := ints.MaxInt(lst.Columns, len(lst.GridTemplateColumns))
+	rows := ints.MaxInt(len(lst.GridTemplateRows), len(areas))
+	if len(areas) > 0 {
+		cols = ints.MaxInt(cols, len(areas[0]))
+	}
 
 	sz := len(ly.Kids)
-	// collect overall size
-	for _, c := range ly.Kids {
+	if len(ly.GridPos) != sz {
+		ly.GridPos = make([]image.Rectangle, sz)
+	}
+	placed := make([]bool, sz)
+	nitems := 0
+	for i, c := range ly.Kids {
+		ly.GridPos[i] = image.Rectangle{}
 		if c == nil {
 			continue
 		}
@@ -272,107 +351,239 @@ func GatherSizesGrid(ly *Layout) {
 		if ni == nil {
 			continue
 		}
+		nitems++
 		ni.StyMu.RLock()
-		lst := ni.Sty.Layout
+		clst := ni.Sty.Layout
 		ni.StyMu.RUnlock()
-		if lst.Col > 0 {
-			cols = ints.MaxInt(cols, lst.Col+lst.ColSpan)
+		span := image.Pt(ints.MaxInt(clst.ColSpan, 1), ints.MaxInt(clst.RowSpan, 1))
+		gp := image.Rectangle{Max: span}
+		if clst.GridArea != "" {
+			if ar := gist.GridAreaRect(areas, clst.GridArea); !ar.Empty() {
+				gp = ar
+				placed[i] = true
+			}
+		} else if clst.Col > 0 || clst.Row > 0 {
+			gp = gp.Add(image.Pt(clst.Col, clst.Row))
+			placed[i] = true
 		}
-		if lst.Row > 0 {
-			rows = ints.MaxInt(rows, lst.Row+lst.RowSpan)
+		ly.GridPos[i] = gp
+		cols = ints.MaxInt(cols, gp.Max.X)
+		if placed[i] {
+			rows = ints.MaxInt(rows, gp.Max.Y)
 		}
 	}
-
 	if cols == 0 {
-		cols = int(mat32.Sqrt(float32(sz))) // whatever -- not well defined
+		cols = ints.MaxInt(int(mat32.Sqrt(float32(nitems))), 1) // whatever -- not well defined
 	}
-	if rows == 0 {
-		rows = sz / cols
+
+	// occupancy of cells, by row, grown as needed
+	var occ [][]bool
+	cell := func(x, y int) *bool {
+		for y >= len(occ) {
+			occ = append(occ, make([]bool, cols))
+		}
+		return &occ[y][x]
 	}
-	for rows*cols < sz { // not defined to have multiple items per cell -- make room for everyone
-		rows++
+	for i, gp := range ly.GridPos {
+		if !placed[i] {
+			continue
+		}
+		for y := gp.Min.Y; y < gp.Max.Y; y++ {
+			for x := gp.Min.X; x < gp.Max.X; x++ {
+				*cell(x, y) = true
+			}
+		}
+	}
+	fits := func(gp image.Rectangle) bool {
+		for y := gp.Min.Y; y < gp.Max.Y; y++ {
+			for x := gp.Min.X; x < gp.Max.X; x++ {
+				if *cell(x, y) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	cx, cy := 0, 0
+	for i, c := range ly.Kids {
+		if placed[i] || c == nil || ly.GridPos[i].Empty() {
+			continue
+		}
+		span := ly.GridPos[i].Size()
+		span.X = ints.MinInt(span.X, cols)
+		for {
+			if cx+span.X > cols {
+				cx = 0
+				cy++
+			}
+			gp := image.Rectangle{Max: span}.Add(image.Pt(cx, cy))
+			if fits(gp) {
+				for y := gp.Min.Y; y < gp.Max.Y; y++ {
+					for x := gp.Min.X; x < gp.Max.X; x++ {
+						*cell(x, y) = true
+					}
+				}
+				ly.GridPos[i] = gp
+				rows = ints.MaxInt(rows, gp.Max.Y)
+				cx += span.X
+				break
+			}
+			cx++
+		}
 	}
 
 	ly.GridSize.X = cols
-	ly.GridSize.Y = rows
+	ly.GridSize.Y = ints.MaxInt(rows, 1)
+}
 
-	if len(ly.GridData[Row]) != rows {
-		ly.GridData[Row] = make([]GridData, rows)
+// GridTrack returns the grid-template track for given row or column index,
+// or nil if it is not in the template (i.e., auto)
+func (ly *Layout) GridTrack(rowcol RowCol, idx int) *gist.GridTrack {
+	gts := ly.Sty.Layout.GridTemplateColumns
+	if rowcol == Row {
+		gts = ly.Sty.Layout.GridTemplateRows
 	}
-	if len(ly.GridData[Col]) != cols {
-		ly.GridData[Col] = make([]GridData, cols)
+	if idx < len(gts) {
+		return &gts[idx]
 	}
+	return nil
+}
 
-	for i := range ly.GridData[Row] {
-		gd := &ly.GridData[Row][i]
-		gd.SizeNeed = 0
-		gd.SizePref = 0
-	}
-	for i := range ly.GridData[Col] {
-		gd := &ly.GridData[Col][i]
-		gd.SizeNeed = 0
-		gd.SizePref = 0
+// GridGap returns the space between the rows and columns of a grid layout,
+// in dots: the gap style if set, otherwise the layout Spacing
+func (ly *Layout) GridGap() float32 {
+	if ly.Sty.Layout.Gap.Dots > 0 {
+		return ly.Sty.Layout.Gap.Dots
 	}
+	return ly.Spacing.Dots
+}
 
-	col := 0
-	row := 0
-	for _, c := range ly.Kids {
-		if c == nil {
+// gridInitTracks initializes the grid data for rows or cols from the
+// sizes of the grid template tracks, if any
+func gridInitTracks(ly *Layout, rowcol RowCol, n int) {
+	if len(ly.GridData[rowcol]) != n {
+		ly.GridData[rowcol] = make([]GridData, n)
+	}
+	for i := range ly.GridData[rowcol] {
+		gd := &ly.GridData[rowcol][i]
+		*gd = GridData{}
+		tr := ly.GridTrack(rowcol, i)
+		if tr == nil {
 			continue
 		}
-		ni := c.(Node2D).AsWidget()
-		if ni == nil {
-			continue
+		if !tr.MinAuto {
+			gd.SizeNeed = tr.Min.Dots
 		}
-		ni.LayState.UpdateSizes()
-		ni.StyMu.RLock()
-		lst := ni.Sty.Layout
-		ni.StyMu.RUnlock()
-		if lst.Col > 0 {
-			col = lst.Col
-		}
-		if lst.Row > 0 {
-			row = lst.Row
-		}
-		// r   0   1   col X = max(ea in col) (Y = not used)
-		//   +--+---+
-		// 0 |  |   |  row Y = max(ea in row) (X = not used)
-		//   +--+---+
-		// 1 |  |   |
-		//   +--+---+
-
-		rgd := &(ly.GridData[Row][row])
-		cgd := &(ly.GridData[Col][col])
-
-		// todo: need to deal with span in sums..
-		mat32.SetMax(&(rgd.SizeNeed), ni.LayState.Size.Need.Y)
-		mat32.SetMax(&(rgd.SizePref), ni.LayState.Size.Pref.Y)
-		mat32.SetMax(&(cgd.SizeNeed), ni.LayState.Size.Need.X)
-		mat32.SetMax(&(cgd.SizePref), ni.LayState.Size.Pref.X)
-
-		// for max: any -1 stretch dominates, else accumulate any max
-		if rgd.SizeMax >= 0 {
-			if ni.LayState.Size.Max.Y < 0 { // stretch
-				rgd.SizeMax = -1
-			} else {
-				mat32.SetMax(&(rgd.SizeMax), ni.LayState.Size.Max.Y)
-			}
+		if tr.Fr > 0 {
+			gd.SizeFr = tr.Fr
+		} else if !tr.MaxAuto {
+			gd.SizePref = tr.Max.Dots
+			gd.SizeMax = tr.Max.Dots
 		}
-		if cgd.SizeMax >= 0 {
-			if ni.LayState.Size.Max.Y < 0 { // stretch
-				cgd.SizeMax = -1
-			} else {
-				mat32.SetMax(&(cgd.SizeMax), ni.LayState.Size.Max.X)
+	}
+}
+
+// gridSizeItem updates the sizes of the given range of tracks (rows or cols)
+// with the need, pref, and max sizes of an item spanning them.  A single
+// track takes the max of its items, while any extra size needed by an item
+// spanning multiple tracks is divided evenly among the content-sized
+// (auto) tracks that it spans.
+func gridSizeItem(ly *Layout, rowcol RowCol, st, ed int, need, pref, max float32) {
+	gds := ly.GridData[rowcol]
+	var autoNeed, autoPref []int
+	sumNeed := float32(ed-st-1) * ly.GridGap()
+	sumPref := sumNeed
+	for i := st; i < ed; i++ {
+		gd := &gds[i]
+		tr := ly.GridTrack(rowcol, i)
+		sumNeed += gd.SizeNeed
+		sumPref += mat32.Max(gd.SizePref, gd.SizeNeed)
+		if tr == nil || tr.MinAuto {
+			autoNeed = append(autoNeed, i)
+		}
+		if tr == nil || tr.MaxAuto || tr.Fr > 0 {
+			autoPref = append(autoPref, i)
+			// for max: any -1 stretch dominates, else accumulate any max
+			if gd.SizeMax >= 0 {
+				if max < 0 { // stretch
+					gd.SizeMax = -1
+				} else if ed-st == 1 {
+					mat32.SetMax(&(gd.SizeMax), max)
+				}
 			}
 		}
+	}
+	if ed-st == 1 {
+		if len(autoNeed) > 0 {
+			mat32.SetMax(&(gds[st].SizeNeed), need)
+		}
+		if len(autoPref) > 0 {
+			mat32.SetMax(&(gds[st].SizePref), pref)
+		}
+		return
+	}
+	if extra := need - sumNeed; extra > 0 && len(autoNeed) > 0 {
+		for _, i := range autoNeed {
+			gds[i].SizeNeed += extra / float32(len(autoNeed))
+		}
+	}
+	if extra := pref - sumPref; extra > 0 && len(autoPref) > 0 {
+		for _, i := range autoPref {
+			gds[i].SizePref = mat32.Max(gds[i].SizePref, gds[i].SizeNeed) + extra/float32(len(autoPref))
+		}
+	}
+}
 
-		col++
-		if col >= cols { // todo: really only works if NO items specify row,col or ALL do..
-			col = 0
-			row++
-			if row >= rows { // wrap-around.. no other good option
-				row = 0
+// GatherSizesGrid is size first pass: gather the size information from the
+// children, grid version
+func GatherSizesGrid(ly *Layout) {
+	if len(ly.Kids) == 0 {
+		return
+	}
+
+	GridPlaceItems(ly)
+	cols := ly.GridSize.X
+	rows := ly.GridSize.Y
+
+	gridInitTracks(ly, Row, rows)
+	gridInitTracks(ly, Col, cols)
+
+	// r   0   1   col X = max(ea in col) (Y = not used)
+	//   +--+---+
+	// 0 |  |   |  row Y = max(ea in row) (X = not used)
+	//   +--+---+
+	// 1 |  |   |
+	//   +--+---+
+
+	// single-span items first, then spanning items in order of span
+	maxSpan := 1
+	for span := 1; span <= maxSpan; span++ {
+		for i, c := range ly.Kids {
+			if c == nil || ly.GridPos[i].Empty() {
+				continue
 			}
+			ni := c.(Node2D).AsWidget()
+			if ni == nil {
+				continue
+			}
+			gp := ly.GridPos[i]
+			if span == 1 {
+				ni.LayState.UpdateSizes()
+				maxSpan = ints.MaxInt(maxSpan, ints.MaxInt(gp.Dx(), gp.Dy()))
+			}
+			sz := &ni.LayState.Size
+			if gp.Dy() == span {
+				gridSizeItem(ly, Row, gp.Min.Y, gp.Max.Y, sz.Need.Y, sz.Pref.Y, sz.Max.Y)
+			}
+			if gp.Dx() == span {
+				gridSizeItem(ly, Col, gp.Min.X, gp.Max.X, sz.Need.X, sz.Pref.X, sz.Max.X)
+			}
+		}
+	}
+	for rc := Row; rc < RowColN; rc++ {
+		for i := range ly.GridData[rc] {
+			gd := &ly.GridData[rc][i]
+			gd.SizePref = mat32.Max(gd.SizePref, gd.SizeNeed)
 		}
 	}
 
@@ -388,7 +599,7 @@ func GatherSizesGrid(ly *Layout) {
 		maxRow := len(ly.GridData[Row])
 		maxCol := len(ly.GridData[Col])
 		if prefSizing {
-			maxRow = ints.MinInt(LayoutPrefMaxRows, maxRow)
+			maxRow = ints.MinInt(PrefMaxRows(mvp, maxRow), maxRow)
 			maxCol = ints.MinInt(LayoutPrefMaxCols, maxCol)
 		}
 
@@ -430,13 +641,14 @@ func GatherSizesGrid(ly *Layout) {
 	}
 
 	spc := ly.BoxSpace()
-	ly.LayState.Size.Need.SetAddScalar(2.0 * spc)
-	ly.LayState.Size.Pref.SetAddScalar(2.0 * spc)
+	ly.LayState.Size.Need.SetAdd(spc.Size())
+	ly.LayState.Size.Pref.SetAdd(spc.Size())
 
-	ly.LayState.Size.Need.X += float32(cols-1) * ly.Spacing.Dots
-	ly.LayState.Size.Pref.X += float32(cols-1) * ly.Spacing.Dots
-	ly.LayState.Size.Need.Y += float32(rows-1) * ly.Spacing.Dots
-	ly.LayState.Size.Pref.Y += float32(rows-1) * ly.Spacing.Dots
+	gap := ly.GridGap()
+	ly.LayState.Size.Need.X += float32(cols-1) * gap
+	ly.LayState.Size.Pref.X += float32(cols-1) * gap
+	ly.LayState.Size.Need.Y += float32(rows-1) * gap
+	ly.LayState.Size.Pref.Y += float32(rows-1) * gap
 
 	ly.LayState.UpdateSizes() // enforce max and normal ordering, etc
 	if Layout2DTrace {
@@ -535,7 +747,7 @@ func LayoutSharedDimImpl(ly *Layout, avail, need, pref, max, spc float32, al gis
 // share the same space, e.g., Horiz for a Vert layout, and vice-versa.
 func LayoutSharedDim(ly *Layout, dim mat32.Dims) {
 	spc := ly.BoxSpace()
-	avail := ly.LayState.Alloc.Size.Dim(dim) - 2.0*spc
+	avail := ly.LayState.Alloc.Size.Dim(dim) - spc.Size().Dim(dim)
 	for i, c := range ly.Kids {
 		if c == nil {
 			continue
@@ -553,7 +765,7 @@ func LayoutSharedDim(ly *Layout, dim mat32.Dims) {
 		pref := ni.LayState.Size.Pref.Dim(dim)
 		need := ni.LayState.Size.Need.Dim(dim)
 		max := ni.LayState.Size.Max.Dim(dim)
-		pos, size := LayoutSharedDimImpl(ly, avail, need, pref, max, spc, al)
+		pos, size := LayoutSharedDimImpl(ly, avail, need, pref, max, spc.Pos().Dim(dim), al)
 		ni.LayState.Alloc.Size.SetDim(dim, size)
 		ni.LayState.Alloc.PosRel.SetDim(dim, pos)
 	}
@@ -570,7 +782,7 @@ func LayoutAlongDim(ly *Layout, dim mat32.Dims) {
 	elspc := float32(sz-1) * ly.Spacing.Dots
 	al := ly.Sty.Layout.AlignDim(dim)
 	spc := ly.BoxSpace()
-	exspc := 2.0*spc + elspc
+	exspc := spc.Size().Dim(dim) + elspc
 	avail := ly.LayState.Alloc.Size.Dim(dim) - exspc
 	pref := ly.LayState.Size.Pref.Dim(dim) - exspc
 	need := ly.LayState.Size.Need.Dim(dim) - exspc
@@ -634,7 +846,7 @@ func LayoutAlongDim(ly *Layout, dim mat32.Dims) {
 	}
 
 	// now arrange everyone
-	pos := spc
+	pos := spc.Pos().Dim(dim)
 
 	// todo: need a direction setting too
 	if gist.IsAlignEnd(al) && !stretchNeed && !stretchMax {
@@ -691,12 +903,12 @@ func LayoutFlow(ly *Layout, dim mat32.Dims, iter int) bool {
 
 	elspc := float32(sz-1) * ly.Spacing.Dots
 	spc := ly.BoxSpace()
-	exspc := 2.0*spc + elspc
+	exspc := spc.Size().Dim(dim) + elspc
 
 	avail := ly.LayState.Alloc.Size.Dim(dim) - exspc
 	odim := mat32.OtherDim(dim)
 
-	pos := spc
+	pos := spc.Pos().Dim(dim)
 	for i, c := range ly.Kids {
 		if c == nil {
 			continue
@@ -708,7 +920,7 @@ func LayoutFlow(ly *Layout, dim mat32.Dims, iter int) bool {
 		size := ni.LayState.Size.Need.Dim(dim)
 		if pos+size > avail {
 			ly.FlowBreaks = append(ly.FlowBreaks, i)
-			pos = spc
+			pos = spc.Pos().Dim(dim)
 		}
 		ni.LayState.Alloc.Size.SetDim(dim, size)
 		ni.LayState.Alloc.PosRel.SetDim(dim, pos)
@@ -720,7 +932,7 @@ func LayoutFlow(ly *Layout, dim mat32.Dims, iter int) bool {
 	ly.FlowBreaks = append(ly.FlowBreaks, len(ly.Kids))
 
 	nrows := len(ly.FlowBreaks)
-	oavail := ly.LayState.Alloc.Size.Dim(odim) - exspc
+	oavail := ly.LayState.Alloc.Size.Dim(odim) - (spc.Size().Dim(odim) + elspc)
 	oavPerRow := oavail / float32(nrows)
 	ci := 0
 	rpos := float32(0)
@@ -742,7 +954,7 @@ func LayoutFlow(ly *Layout, dim mat32.Dims, iter int) bool {
 			pref := ni.LayState.Size.Pref.Dim(odim)
 			need := ni.LayState.Size.Need.Dim(odim)
 			max := ni.LayState.Size.Max.Dim(odim)
-			pos, size := LayoutSharedDimImpl(ly, oavPerRow, need, pref, max, spc, al)
+			pos, size := LayoutSharedDimImpl(ly, oavPerRow, need, pref, max, spc.Pos().Dim(odim), al)
 			ni.LayState.Alloc.Size.SetDim(odim, size)
 			ni.LayState.Alloc.PosRel.SetDim(odim, rpos+pos)
 			rmax = mat32.Max(rmax, size)
@@ -763,6 +975,269 @@ func LayoutFlow(ly *Layout, dim mat32.Dims, iter int) bool {
 	return true
 }
 
+// flexItem has the data for one item (child) in a flex layout, along the
+// main axis, except for the align cross axis alignment
+type flexItem struct {
+	ni     *WidgetBase
+	basis  float32    // flex-basis, or pref size for auto
+	hyp    float32    // hypothetical size: basis within need, max
+	need   float32    // minimum size
+	max    float32    // maximum size, 0 = no constraint
+	grow   float32    // flex-grow
+	shrink float32    // flex-shrink
+	size   float32    // resolved size
+	viol   float32    // amount size was clamped by need, max on last pass
+	frozen bool       // size is final
+	align  gist.Align // cross axis alignment: align-self or align-items
+}
+
+// clamp returns given size within the need and max of the item
+func (fi *flexItem) clamp(size float32) float32 {
+	if fi.max > 0 {
+		size = mat32.Min(size, fi.max)
+	}
+	return mat32.Max(size, fi.need)
+}
+
+// flexItems returns the flex items for the children of given
+// layout, along given main axis dimension
+func flexItems(ly *Layout, dim mat32.Dims) []flexItem {
+	items := make([]flexItem, 0, len(ly.Kids))
+	for _, c := range ly.Kids {
+		if c == nil {
+			continue
+		}
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		ni.StyMu.RLock()
+		lst := &ni.Sty.Layout
+		fi := flexItem{ni: ni, grow: lst.FlexGrow, shrink: lst.FlexShrink, align: lst.AlignSelf}
+		basis := lst.FlexBasis.Dots
+		ni.StyMu.RUnlock()
+		if fi.align == gist.AlignAuto {
+			fi.align = ly.Sty.Layout.AlignItems
+		}
+		fi.need = ni.LayState.Size.Need.Dim(dim)
+		fi.max = mat32.Max(ni.LayState.Size.Max.Dim(dim), 0) // stretch is only by grow
+		if basis < 0 {
+			basis = ni.LayState.Size.Pref.Dim(dim)
+		}
+		fi.basis = basis
+		fi.hyp = fi.clamp(basis)
+		items = append(items, fi)
+	}
+	return items
+}
+
+// flexResolve resolves the sizes of the items in one line of a flex layout,
+// distributing the free space relative to avail according to their
+// flex-grow or flex-shrink factors, and freezing items at their need
+// or max size when they hit those limits, as in the CSS flexible lengths
+// algorithm: https://www.w3.org/TR/css-flexbox-1/#resolve-flexible-lengths
+func flexResolve(items []flexItem, avail float32) {
+	sumHyp := float32(0)
+	for i := range items {
+		sumHyp += items[i].hyp
+	}
+	growing := sumHyp < avail
+	for i := range items {
+		fi := &items[i]
+		fi.size = fi.hyp
+		if growing {
+			fi.frozen = fi.grow == 0 || fi.basis > fi.hyp
+		} else {
+			fi.frozen = fi.shrink == 0 || fi.basis < fi.hyp
+		}
+	}
+	for {
+		free := avail
+		nfree := 0
+		sumGrow := float32(0)
+		sumShrink := float32(0)
+		for i := range items {
+			fi := &items[i]
+			if fi.frozen {
+				free -= fi.size
+				continue
+			}
+			nfree++
+			free -= fi.basis
+			sumGrow += fi.grow
+			sumShrink += fi.shrink * fi.basis
+		}
+		if nfree == 0 {
+			return
+		}
+		viol := float32(0)
+		for i := range items {
+			fi := &items[i]
+			if fi.frozen {
+				continue
+			}
+			targ := fi.basis
+			if growing && sumGrow > 0 {
+				targ += free * fi.grow / sumGrow
+			} else if !growing && sumShrink > 0 {
+				targ += free * fi.shrink * fi.basis / sumShrink
+			}
+			fi.size = fi.clamp(targ)
+			fi.viol = fi.size - targ
+			viol += fi.viol
+		}
+		// freeze all if no net violation, else those in the direction of the net violation
+		for i := range items {
+			fi := &items[i]
+			if fi.frozen {
+				continue
+			}
+			if mat32.Abs(viol) < 0.01 || (viol > 0 && fi.viol > 0) || (viol < 0 && fi.viol < 0) {
+				fi.frozen = true
+			}
+		}
+	}
+}
+
+// flexJustify returns the starting position and extra space between
+// items for distributing given free space among n items in a line
+// of a flex layout, according to justify-content alignment
+func flexJustify(al gist.Align, free float32, n int) (start, between float32) {
+	if free <= 0 {
+		return
+	}
+	switch {
+	case al == gist.AlignJustify:
+		if n > 1 {
+			between = free / float32(n-1)
+		}
+	case al == gist.AlignSpaceAround:
+		between = free / float32(n)
+		start = 0.5 * between
+	case al == gist.AlignSpaceEvenly:
+		between = free / float32(n+1)
+		start = between
+	case gist.IsAlignMiddle(al):
+		start = 0.5 * free
+	case gist.IsAlignEnd(al):
+		start = free
+	}
+	return
+}
+
+// flexCross returns the position and size of given item along the cross
+// axis dimension, within a line of given size in a flex layout
+func flexCross(fi *flexItem, odim mat32.Dims, avail float32) (pos, size float32) {
+	need := fi.ni.LayState.Size.Need.Dim(odim)
+	max := fi.ni.LayState.Size.Max.Dim(odim)
+	if fi.align == gist.AlignStretch {
+		size = avail
+		if max > 0 {
+			size = mat32.Min(size, max)
+		}
+		return 0, mat32.Max(size, need)
+	}
+	size = fi.ni.LayState.Size.Pref.Dim(odim)
+	if size > avail {
+		size = mat32.Max(need, avail)
+	}
+	extra := mat32.Max(avail-size, 0)
+	if gist.IsAlignMiddle(fi.align) {
+		pos = 0.5 * extra
+	} else if gist.IsAlignEnd(fi.align) {
+		pos = extra
+	}
+	return
+}
+
+// LayoutFlexLay lays out the children according to the CSS flexbox model,
+// using the flex styles of the layout and its children.
+// FlowBreaks records the start of each line after the first, for flex-wrap.
+// returns true if needs another iteration (only if iter == 0, when wrapped
+// onto multiple lines)
+func LayoutFlexLay(ly *Layout, iter int) bool {
+	ly.FlowBreaks = nil
+	dim, odim, rev := ly.Sty.Layout.FlexDims()
+	items := flexItems(ly, dim)
+	if len(items) == 0 {
+		return false
+	}
+
+	gap := ly.Sty.Layout.Gap.Dots
+	spc := ly.BoxSpace()
+	avail := ly.LayState.Alloc.Size.Dim(dim) - spc.Size().Dim(dim)
+	oavail := ly.LayState.Alloc.Size.Dim(odim) - spc.Size().Dim(odim)
+
+	if ly.Sty.Layout.FlexWrap {
+		pos := float32(0)
+		for i := range items {
+			size := items[i].hyp
+			if pos > 0 && pos+size > avail {
+				ly.FlowBreaks = append(ly.FlowBreaks, i)
+				pos = 0
+			}
+			pos += size + gap
+		}
+	}
+	ly.FlowBreaks = append(ly.FlowBreaks, len(items))
+
+	// cross size of each line: single line fills the layout, and
+	// otherwise extra space is divided among lines (align-content: stretch)
+	nlines := len(ly.FlowBreaks)
+	lsize := make([]float32, nlines)
+	if nlines == 1 {
+		lsize[0] = oavail
+	} else {
+		st := 0
+		extra := oavail - float32(nlines-1)*gap
+		for li, bi := range ly.FlowBreaks {
+			for i := st; i < bi; i++ {
+				lsize[li] = mat32.Max(lsize[li], items[i].ni.LayState.Size.Pref.Dim(odim))
+			}
+			extra -= lsize[li]
+			st = bi
+		}
+		if extra > 0 {
+			for li := range lsize {
+				lsize[li] += extra / float32(nlines)
+			}
+		}
+	}
+
+	st := 0
+	opos := spc.Pos().Dim(odim)
+	for li, bi := range ly.FlowBreaks {
+		line := items[st:bi]
+		gaps := float32(len(line)-1) * gap
+		flexResolve(line, avail-gaps)
+		free := avail - gaps
+		for i := range line {
+			free -= line[i].size
+		}
+		pos, between := flexJustify(ly.Sty.Layout.JustifyContent, free, len(line))
+		for i := range line {
+			fi := &line[i]
+			ni := fi.ni
+			mpos := pos
+			if rev {
+				mpos = avail - pos - fi.size
+			}
+			ni.LayState.Alloc.Size.SetDim(dim, fi.size)
+			ni.LayState.Alloc.PosRel.SetDim(dim, spc.Pos().Dim(dim)+mpos)
+			cpos, csize := flexCross(fi, odim, lsize[li])
+			ni.LayState.Alloc.Size.SetDim(odim, csize)
+			ni.LayState.Alloc.PosRel.SetDim(odim, opos+cpos)
+			if Layout2DTrace {
+				fmt.Printf("Layout: %v Flex Child: %v, line: %v, pos: %v, size: %v\n", ly.Path(), ni.Nm, li, ni.LayState.Alloc.PosRel, ni.LayState.Alloc.Size)
+			}
+			pos += fi.size + gap + between
+		}
+		opos += lsize[li] + gap
+		st = bi
+	}
+	return nlines > 1
+}
+
 // LayoutGridDim lays out grid data along each dimension (row, Y; col, X),
 // same as LayoutAlongDim.  For cols, X has width prefs of each -- turn that
 // into an actual allocated width for each column, and likewise for rows.
@@ -772,10 +1247,11 @@ func LayoutGridDim(ly *Layout, rowcol RowCol, dim mat32.Dims) {
 	if sz == 0 {
 		return
 	}
-	elspc := float32(sz-1) * ly.Spacing.Dots
+	gap := ly.GridGap()
+	elspc := float32(sz-1) * gap
 	al := ly.Sty.Layout.AlignDim(dim)
 	spc := ly.BoxSpace()
-	exspc := 2.0*spc + elspc
+	exspc := spc.Size().Dim(dim) + elspc
 	avail := ly.LayState.Alloc.Size.Dim(dim) - exspc
 	pref := ly.LayState.Size.Pref.Dim(dim) - exspc
 	need := ly.LayState.Size.Need.Dim(dim) - exspc
@@ -790,6 +1266,10 @@ func LayoutGridDim(ly *Layout, rowcol RowCol, dim mat32.Dims) {
 	}
 	extra = mat32.Max(extra, 0.0) // no negatives
 
+	if LayoutGridFr(ly, rowcol, dim, avail) {
+		return
+	}
+
 	nstretch := 0
 	stretchTot := float32(0.0)
 	stretchNeed := false        // stretch relative to need
@@ -825,7 +1305,7 @@ func LayoutGridDim(ly *Layout, rowcol RowCol, dim mat32.Dims) {
 	}
 
 	// now arrange everyone
-	pos := spc
+	pos := spc.Pos().Dim(dim)
 
 	// todo: need a direction setting too
 	if gist.IsAlignEnd(al) && !stretchNeed && !stretchMax {
@@ -861,8 +1341,81 @@ func LayoutGridDim(ly *Layout, rowcol RowCol, dim mat32.Dims) {
 		if Layout2DTrace {
 			fmt.Printf("Grid %v pos: %v, size: %v\n", rowcol, pos, size)
 		}
-		pos += size + ly.Spacing.Dots
+		pos += size + gap
+	}
+}
+
+// LayoutGridFr lays out the tracks (rows or cols) of a grid if any has a
+// flexible fr size, returning false otherwise.  The other tracks get their
+// pref size if it fits, else their need, and the fr tracks divide the
+// remaining avail space in proportion to their fr, except that they do not
+// go below their need, as in the CSS grid "find the size of an fr" algorithm.
+func LayoutGridFr(ly *Layout, rowcol RowCol, dim mat32.Dims, avail float32) bool {
+	gds := ly.GridData[rowcol]
+	sumFr := float32(0)
+	var fixPref, frNeed float32
+	for _, gd := range gds {
+		if gd.SizeFr > 0 {
+			sumFr += gd.SizeFr
+			frNeed += gd.SizeNeed
+		} else {
+			fixPref += gd.SizePref
+		}
+	}
+	if sumFr == 0 {
+		return false
+	}
+	usePref := avail-fixPref-frNeed >= 0
+	free := avail
+	for i := range gds {
+		gd := &gds[i]
+		gd.AllocSize = 0
+		if gd.SizeFr > 0 {
+			continue
+		}
+		gd.AllocSize = gd.SizeNeed
+		if usePref {
+			gd.AllocSize = gd.SizePref
+		}
+		free -= gd.AllocSize
+	}
+	// fr tracks whose share is less than their need are frozen at their need
+	frozen := make([]bool, len(gds))
+	frSize := float32(0)
+	for {
+		frSize = mat32.Max(free, 0) / sumFr
+		nfrz := 0
+		for i, gd := range gds {
+			if gd.SizeFr == 0 || frozen[i] || gd.SizeFr*frSize >= gd.SizeNeed {
+				continue
+			}
+			frozen[i] = true
+			free -= gd.SizeNeed
+			sumFr -= gd.SizeFr
+			nfrz++
+		}
+		if nfrz == 0 || sumFr <= 0 {
+			break
+		}
 	}
+	pos := ly.BoxSpace().Pos().Dim(dim)
+	gap := ly.GridGap()
+	for i := range gds {
+		gd := &gds[i]
+		if gd.SizeFr > 0 {
+			if frozen[i] {
+				gd.AllocSize = gd.SizeNeed
+			} else {
+				gd.AllocSize = gd.SizeFr * frSize
+			}
+		}
+		gd.AllocPosRel = pos
+		if Layout2DTrace {
+			fmt.Printf("Grid %v pos: %v, size: %v, fr: %v\n", rowcol, pos, gd.AllocSize, gd.SizeFr)
+		}
+		pos += gd.AllocSize + gap
+	}
+	return true
 }
 
 // LayoutGridLay manages overall grid layout of children
@@ -872,19 +1425,14 @@ func LayoutGridLay(ly *Layout) {
 		return
 	}
 
-	LayoutGridDim(ly, Row, mat32.Y)
-	LayoutGridDim(ly, Col, mat32.X)
-
-	col := 0
-	row := 0
-	cols := ly.GridSize.X
-	rows := ly.GridSize.Y
-
-	if cols*rows != ly.NumChildren() {
+	if len(ly.GridPos) != sz {
 		GatherSizesGrid(ly)
 	}
 
-	for _, c := range ly.Kids {
+	LayoutGridDim(ly, Row, mat32.Y)
+	LayoutGridDim(ly, Col, mat32.X)
+
+	for i, c := range ly.Kids {
 		if c == nil {
 			continue
 		}
@@ -892,54 +1440,41 @@ func LayoutGridLay(ly *Layout) {
 		if ni == nil {
 			continue
 		}
-
+		gp := ly.GridPos[i]
 		ni.StyMu.RLock()
 		lst := ni.Sty.Layout
 		ni.StyMu.RUnlock()
-		if lst.Col > 0 {
-			col = lst.Col
-		}
-		if lst.Row > 0 {
-			row = lst.Row
-		}
 
 		{ // col, X dim
 			dim := mat32.X
-			gd := ly.GridData[Col][col]
-			avail := gd.AllocSize
+			gst := ly.GridData[Col][gp.Min.X]
+			ged := ly.GridData[Col][gp.Max.X-1]
+			avail := ged.AllocPosRel + ged.AllocSize - gst.AllocPosRel
 			al := lst.AlignDim(dim)
 			pref := ni.LayState.Size.Pref.Dim(dim)
 			need := ni.LayState.Size.Need.Dim(dim)
 			max := ni.LayState.Size.Max.Dim(dim)
 			pos, size := LayoutSharedDimImpl(ly, avail, need, pref, max, 0, al)
 			ni.LayState.Alloc.Size.SetDim(dim, size)
-			ni.LayState.Alloc.PosRel.SetDim(dim, pos+gd.AllocPosRel)
+			ni.LayState.Alloc.PosRel.SetDim(dim, pos+gst.AllocPosRel)
 
 		}
 		{ // row, Y dim
 			dim := mat32.Y
-			gd := ly.GridData[Row][row]
-			avail := gd.AllocSize
+			gst := ly.GridData[Row][gp.Min.Y]
+			ged := ly.GridData[Row][gp.Max.Y-1]
+			avail := ged.AllocPosRel + ged.AllocSize - gst.AllocPosRel
 			al := lst.AlignDim(dim)
 			pref := ni.LayState.Size.Pref.Dim(dim)
 			need := ni.LayState.Size.Need.Dim(dim)
 			max := ni.LayState.Size.Max.Dim(dim)
 			pos, size := LayoutSharedDimImpl(ly, avail, need, pref, max, 0, al)
 			ni.LayState.Alloc.Size.SetDim(dim, size)
-			ni.LayState.Alloc.PosRel.SetDim(dim, pos+gd.AllocPosRel)
+			ni.LayState.Alloc.PosRel.SetDim(dim, pos+gst.AllocPosRel)
 		}
 
 		if Layout2DTrace {
-			fmt.Printf("Layout: %v grid col: %v row: %v pos: %v size: %v\n", ly.Path(), col, row, ni.LayState.Alloc.PosRel, ni.LayState.Alloc.Size)
-		}
-
-		col++
-		if col >= cols { // todo: really only works if NO items specify row,col or ALL do..
-			col = 0
-			row++
-			if row >= rows { // wrap-around.. no other good option
-				row = 0
-			}
+			fmt.Printf("Layout: %v grid cells: %v pos: %v size: %v\n", ly.Path(), gp, ni.LayState.Alloc.PosRel, ni.LayState.Alloc.Size)
 		}
 	}
 }
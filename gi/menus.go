@@ -668,15 +668,16 @@ func (sp *Separator) RenderSeparator() {
 	rs, pc, st := sp.RenderLock()
 	defer sp.RenderUnlock(rs)
 
-	pos := sp.LayState.Alloc.Pos.AddScalar(st.Layout.Margin.Dots)
-	sz := sp.LayState.Alloc.Size.AddScalar(-2.0 * st.Layout.Margin.Dots)
+	mg := st.Layout.Margin.Dots()
+	pos := sp.LayState.Alloc.Pos.Add(mg.Pos())
+	sz := sp.LayState.Alloc.Size.Sub(mg.Size())
 
 	if !st.Font.BgColor.IsNil() {
 		pc.FillBox(rs, pos, sz, &st.Font.BgColor)
 	}
 
-	pc.StrokeStyle.Width = st.Border.Width
-	pc.StrokeStyle.SetColor(&st.Border.Color)
+	pc.StrokeStyle.Width = st.Border.Width.Top
+	pc.StrokeStyle.SetColor(&st.Border.Color.Top)
 	if sp.Horiz {
 		pc.DrawLine(rs, pos.X, pos.Y+0.5*sz.Y, pos.X+sz.X, pos.Y+0.5*sz.Y)
 	} else {
@@ -93,6 +93,14 @@ var Update2DTrace bool = false
 // (just printfs to stdout) -- can be set in PrefsDebug from prefs gui
 var Render2DTrace bool = false
 
+// Render2DTiles is the size of the tiles for tiled rendering of viewports,
+// which records the rendering of a viewport and then rasterizes it in tiles
+// that are rendered concurrently, using all of the cores -- 0 renders
+// directly, on one core.  Only viewports of at least 4 tiles are tiled,
+// and not while display lists are recorded -- can be set in PrefsDebug
+// from prefs gui
+var Render2DTiles int = 0
+
 // Layout2DTrace reports a trace of all layouts (just
 // printfs to stdout) -- can be set in PrefsDebug from prefs gui
 var Layout2DTrace bool = false
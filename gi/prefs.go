@@ -37,7 +37,7 @@ type Preferences struct {
 	KeyMap               KeyMapName             `desc:"select the active keymap from list of available keymaps -- see Edit KeyMaps for editing / saving / loading that list"`
 	SaveKeyMaps          bool                   `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
 	SaveDetailed         bool                   `desc:"if set, the detailed preferences are saved and loaded at startup -- only "`
-	CustomStyles         ki.Props               `desc:"a custom style sheet -- add a separate Props entry for each type of object, e.g., button, or class using .classname, or specific named element using #name -- all are case insensitive"`
+	CustomStyles         ki.Props               `desc:"a custom style sheet -- add a separate Props entry for each CSS selector, e.g., button for a type of object, .classname for a class, #name for a specific named element, frame > button:hover etc (see CSSSelector) -- all are case insensitive -- can be loaded from a .css file with OpenCustomStyles"`
 	CustomStylesOverride bool                   `desc:"if true my custom styles override other styling (i.e., they come <i>last</i> in styling process -- otherwise they provide defaults that can be overridden by app-specific styling (i.e, they come first)."`
 	FontFamily           FontName               `desc:"default font family when otherwise not specified"`
 	MonoFont             FontName               `desc:"default mono-spaced font family"`
@@ -128,6 +128,22 @@ func (pf *Preferences) IsDarkMode() bool {
 	return pf.Colors.Background.IsDark()
 }
 
+// OpenCustomStyles sets the CustomStyles from a CSS style sheet file,
+// which can contain @media (prefers-color-scheme: dark) rules for dark mode
+func (pf *Preferences) OpenCustomStyles(filename FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	css, err := ParseCSS(string(b))
+	if err != nil {
+		return err
+	}
+	pf.CustomStyles = css
+	pf.Changed = true
+	return nil
+}
+
 // OpenColors colors from a JSON-formatted file.
 func (pf *Preferences) OpenColors(filename FileName) error {
 	err := pf.Colors.OpenJSON(filename)
@@ -408,6 +424,18 @@ var PreferencesProps = ki.Props{
 			"desc": "Set color mode to Dark mode as defined in ColorSchemes -- automatically does Save and UpdateAll",
 			"icon": "color",
 		}},
+		{"NewColorScheme", ki.Props{
+			"desc": "Adds a new color scheme to ColorSchemes, generated from a primary seed color and an optional secondary seed color (derived from the primary if not set), for light or dark mode.",
+			"icon": "plus",
+			"Args": ki.PropSlice{
+				{"Name", ki.Props{}},
+				{"Primary", ki.Props{
+					"default": gist.Color{R: 0x30, G: 0x60, B: 0xc0, A: 0xff},
+				}},
+				{"Secondary", ki.Props{}},
+				{"Dark", ki.Props{}},
+			},
+		}},
 		{"sep-scrn", ki.BlankProp{}},
 		{"SaveZoom", ki.Props{
 			"icon": "zoom-in",
@@ -533,6 +561,81 @@ func (pf *ColorPrefs) PrefColor(clrName string) *gist.Color {
 	return nil
 }
 
+// ColorContrast is the WCAG contrast ratio of a foreground color on a
+// background color in ColorPrefs, along with the minimum ratio it should have
+type ColorContrast struct {
+	Fg    string  `desc:"name of the foreground color, as in PrefColor"`
+	Bg    string  `desc:"name of the background color, as in PrefColor"`
+	Min   float32 `desc:"minimum contrast ratio, e.g., gist.ContrastAA for text"`
+	Ratio float32 `desc:"contrast ratio of the colors, from 1 to 21"`
+}
+
+// String returns a description of the contrast, e.g., for reports
+func (cc *ColorContrast) String() string {
+	return fmt.Sprintf("%s on %s: %.2f (min %.2g)", cc.Fg, cc.Bg, cc.Ratio, cc.Min)
+}
+
+// ColorContrastPairs are the foreground and background colors in ColorPrefs
+// whose contrast is checked in Contrasts, with the minimum WCAG contrast
+// ratio for each: gist.ContrastAA for text, and gist.ContrastAALarge for
+// icons and borders
+var ColorContrastPairs = []ColorContrast{
+	{Fg: "Font", Bg: "Background", Min: gist.ContrastAA},
+	{Fg: "Font", Bg: "Control", Min: gist.ContrastAA},
+	{Fg: "Font", Bg: "Select", Min: gist.ContrastAA},
+	{Fg: "Font", Bg: "Highlight", Min: gist.ContrastAA},
+	{Fg: "Link", Bg: "Background", Min: gist.ContrastAA},
+	{Fg: "Icon", Bg: "Control", Min: gist.ContrastAALarge},
+	{Fg: "Border", Bg: "Background", Min: gist.ContrastAALarge},
+}
+
+// Contrasts returns the contrast ratios of all the ColorContrastPairs
+func (pf *ColorPrefs) Contrasts() []ColorContrast {
+	ccs := make([]ColorContrast, len(ColorContrastPairs))
+	for i, cc := range ColorContrastPairs {
+		cc.Ratio = pf.PrefColor(cc.Fg).ContrastRatio(pf.PrefColor(cc.Bg))
+		ccs[i] = cc
+	}
+	return ccs
+}
+
+// ContrastIssues returns the ColorContrastPairs whose contrast ratio is
+// below their minimum, i.e., which are hard to read
+func (pf *ColorPrefs) ContrastIssues() []ColorContrast {
+	var ccs []ColorContrast
+	for _, cc := range pf.Contrasts() {
+		if cc.Ratio < cc.Min {
+			ccs = append(ccs, cc)
+		}
+	}
+	return ccs
+}
+
+// AuditContrast shows a dialog with the colors that do not have enough
+// contrast with their background (see ContrastIssues)
+func (pf *ColorPrefs) AuditContrast() {
+	ccs := pf.ContrastIssues()
+	prompt := "All colors have enough contrast with their backgrounds."
+	if len(ccs) > 0 {
+		strs := make([]string, len(ccs))
+		for i := range ccs {
+			strs[i] = ccs[i].String()
+		}
+		prompt = "These colors do not have enough contrast (use Fix Contrast to fix them):<br>" + strings.Join(strs, "<br>")
+	}
+	PromptDialog(nil, DlgOpts{Title: "Color Contrast", Prompt: prompt}, AddOk, NoCancel, nil, nil)
+}
+
+// FixContrast changes the lightness of the foreground colors that do not
+// have enough contrast with their background (see ContrastIssues), as
+// little as possible to reach the minimum contrast ratio
+func (pf *ColorPrefs) FixContrast() {
+	for _, cc := range pf.ContrastIssues() {
+		fg := pf.PrefColor(cc.Fg)
+		*fg = fg.ReadableOn(pf.PrefColor(cc.Bg), cc.Min)
+	}
+}
+
 // OpenJSON opens colors from a JSON-formatted file.
 func (pf *ColorPrefs) OpenJSON(filename FileName) error {
 	b, err := ioutil.ReadFile(string(filename))
@@ -593,6 +696,31 @@ var ColorPrefsProps = ki.Props{
 			"desc": "Sets this color scheme as the current active color scheme in Prefs.",
 			"icon": "reset",
 		}},
+		{"GenerateTheme", ki.Props{
+			"label": "Generate...",
+			"desc":  "Sets all the colors from a primary seed color and an optional secondary seed color (derived from the primary if not set), for light or dark mode, along with a matching highlighting style.",
+			"icon":  "color",
+			"Args": ki.PropSlice{
+				{"Primary", ki.Props{
+					"default-field": "Link",
+				}},
+				{"Secondary", ki.Props{}},
+				{"Dark", ki.Props{}},
+			},
+		}},
+		{"sep-contrast", ki.BlankProp{}},
+		{"AuditContrast", ki.Props{
+			"desc": "Shows the colors that do not have enough contrast with their background to be easily readable, according to the WCAG guidelines.",
+			"icon": "search",
+		}},
+		{"FixContrast", ki.Props{
+			"desc": "Changes the lightness of the colors that do not have enough contrast with their background, as little as possible.",
+			"icon": "update",
+			"updtfunc": func(pfi interface{}, act *Action) {
+				pf := pfi.(*ColorPrefs)
+				act.SetActiveStateUpdt(len(pf.ContrastIssues()) > 0)
+			},
+		}},
 	},
 }
 
@@ -1006,6 +1134,10 @@ type PrefsDebug struct {
 
 	Render2DTrace *bool `desc:"reports trace of the nodes rendering (printfs to stdout)"`
 
+	Render2DTiles *int `desc:"size of the tiles for tiled rendering of viewports, which are rasterized concurrently on all cores -- 0 renders directly, on one core"`
+
+	GlyphCacheMaxBytes int `desc:"maximum memory used by the shared cache of the rendered glyphs of text, in bytes -- GlyphCacheStats in the toolbar shows how well it is working"`
+
 	Layout2DTrace *bool `desc:"reports trace of all layouts (printfs to stdout)"`
 
 	WinEventTrace *bool `desc:"reports trace of window events (printfs to stdout)"`
@@ -1043,6 +1175,11 @@ var PrefsDebugProps = ki.Props{
 			"desc": "Toggle profiling of program on or off -- does both targeted and global CPU and Memory profiling.",
 			"icon": "update",
 		}},
+		{"GlyphCacheStats", ki.Props{
+			"desc":        "shows the statistics of the shared cache of the rendered glyphs of text: the number of glyphs and memory used, and the hits and misses",
+			"icon":        "info",
+			"show-return": true,
+		}},
 	},
 }
 
@@ -1050,6 +1187,8 @@ var PrefsDebugProps = ki.Props{
 func (pf *PrefsDebug) Connect() {
 	pf.Update2DTrace = &Update2DTrace
 	pf.Render2DTrace = &Render2DTrace
+	pf.Render2DTiles = &Render2DTiles
+	pf.GlyphCacheMaxBytes = girl.TheGlyphCache.MaxBytes()
 	pf.Layout2DTrace = &Layout2DTrace
 	pf.WinEventTrace = &WinEventTrace
 	pf.WinPublishTrace = &WinPublishTrace
@@ -1063,7 +1202,19 @@ func (pf *PrefsDebug) Connect() {
 	pf.StructViewIfDebug = &StructViewIfDebug
 }
 
+// Apply applies the debugging params that are not connected directly to
+// the variables controlling them (see Connect) -- called after edits
+func (pf *PrefsDebug) Apply() {
+	girl.TheGlyphCache.SetMaxBytes(pf.GlyphCacheMaxBytes)
+}
+
 // Profile toggles profiling on / off
 func (pf *PrefsDebug) Profile() {
 	ProfileToggle()
 }
+
+// GlyphCacheStats returns the statistics of the shared cache of the
+// rendered glyphs of text
+func (pf *PrefsDebug) GlyphCacheStats() string {
+	return girl.TheGlyphCache.Stats().String()
+}
@@ -9,6 +9,7 @@ import (
 	"image"
 	"sync"
 
+	"github.com/goki/gi/girl"
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/key"
@@ -59,6 +60,18 @@ type SliderBase struct {
 	TrackThr    float32                   `xml:"track-thr" desc:"threshold for amount of change in scroll value before emitting a signal in Tracking mode"`
 	Snap        bool                      `xml:"snap" desc:"snap the values to Step size increments"`
 	Off         bool                      `desc:"can turn off e.g., scrollbar rendering with this flag -- just prevents rendering"`
+	Range       bool                      `xml:"range" desc:"if true, selects a range of values from Value to HighValue, with a low and a high thumb -- the segment between the thumbs can be dragged to move the whole range, and Space switches the thumb moved by the keyboard -- not for ValThumb sliders, and the Icon is not used"`
+	HighValue   float32                   `xml:"high-value" desc:"current high value of the range, if Range -- use SetRangeValues to set both values"`
+	EmitHigh    float32                   `copy:"-" xml:"-" json:"-" desc:"previous emitted high value - don't re-emit if it is the same"`
+	HighPos     float32                   `xml:"-" desc:"logical position of the high thumb relative to Size, if Range"`
+	HighDragPos float32                   `xml:"-" desc:"underlying drag position of the high thumb -- not subject to snapping"`
+	HighThumb   bool                      `xml:"-" desc:"if Range, the high thumb is the current one, moved by the keyboard -- otherwise the low one"`
+	DragRange   bool                      `xml:"-" desc:"if Range, the segment between the thumbs is being dragged, moving both of them"`
+	Log         bool                      `xml:"log" desc:"logarithmic scaling of the values along the slider -- only if Min > 0"`
+	MajorTick   float32                   `xml:"major-tick" desc:"interval between major tick marks, in value units -- if Log, the ratio between them, e.g., 10 -- 0 for no ticks"`
+	MinorTicks  int                       `xml:"minor-ticks" desc:"number of minor tick marks between major ones"`
+	TickLabels  bool                      `xml:"tick-labels" desc:"label the major ticks with their values, formatted with TickFormat"`
+	TickFormat  string                    `xml:"tick-format" desc:"format for the tick labels -- %g if empty"`
 	State       SliderStates              `json:"-" xml:"-" desc:"state of slider"`
 	StateStyles [SliderStatesN]gist.Style `copy:"-" json:"-" xml:"-" desc:"styles for different states of the slider, one for each state -- everything inherits from the base Style which is styled first according to the user-set styles, and then subsequent style settings can override that"`
 	SliderSig   ki.Signal                 `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for slider -- see SliderSignals for the types"`
@@ -92,6 +105,15 @@ func (sb *SliderBase) CopyFieldsFrom(frm interface{}) {
 	sb.TrackThr = fr.TrackThr
 	sb.Snap = fr.Snap
 	sb.Off = fr.Off
+	sb.Range = fr.Range
+	sb.HighValue = fr.HighValue
+	sb.HighPos = fr.HighPos
+	sb.HighDragPos = fr.HighDragPos
+	sb.Log = fr.Log
+	sb.MajorTick = fr.MajorTick
+	sb.MinorTicks = fr.MinorTicks
+	sb.TickLabels = fr.TickLabels
+	sb.TickFormat = fr.TickFormat
 }
 
 func (sb *SliderBase) Disconnect() {
@@ -105,7 +127,8 @@ type SliderSignals int64
 const (
 	// SliderValueChanged indicates that the value has changed -- if tracking
 	// is enabled, then this tracks online changes -- otherwise only at the
-	// end.  The data on the signal is the float32 Value.
+	// end.  The data on the signal is the float32 Value -- if Range, it is
+	// emitted when either Value or HighValue changes.
 	SliderValueChanged SliderSignals = iota
 
 	// SliderPressed means slider was pushed down but not yet up.
@@ -185,9 +208,14 @@ func (sb *SliderBase) SetSliderState(state SliderStates) {
 // not yet up -- emits SliderPress signal
 func (sb *SliderBase) SliderPress(pos float32) {
 	sb.EmitValue = sb.Min - 1.0 // invalid value
+	sb.EmitHigh = sb.Min - 1.0
 	updt := sb.UpdateStart()
 	sb.SetSliderState(SliderDown)
-	sb.SetSliderPos(pos)
+	if sb.Range {
+		sb.RangePress(pos)
+	} else {
+		sb.SetSliderPos(pos)
+	}
 	sb.SliderSig.Emit(sb.This(), int64(SliderPressed), sb.Value)
 	// bitflasb.Set(&sb.Flag, int(SliderFlagDragging))
 	sb.UpdateEnd(updt)
@@ -199,6 +227,7 @@ func (sb *SliderBase) SliderPress(pos float32) {
 func (sb *SliderBase) SliderRelease() {
 	wasPressed := (sb.State == SliderDown)
 	updt := sb.UpdateStart()
+	sb.DragRange = false
 	sb.SetSliderState(SliderActive)
 	sb.SliderSig.Emit(sb.This(), int64(SliderReleased), sb.Value)
 	if wasPressed {
@@ -234,7 +263,7 @@ func (sb *SliderBase) SizeFromAlloc() {
 		sb.Defaults()
 	}
 	spc := sb.BoxSpace()
-	sb.Size = sb.LayState.Alloc.Size.Dim(sb.Dim) - 2.0*spc
+	sb.Size = sb.LayState.Alloc.Size.Dim(sb.Dim) - spc.Size().Dim(sb.Dim)
 	if sb.Size <= 0 {
 		return
 	}
@@ -243,23 +272,68 @@ func (sb *SliderBase) SizeFromAlloc() {
 	}
 	sb.UpdatePosFromValue()
 	sb.DragPos = sb.Pos
+	sb.HighDragPos = sb.HighPos
 }
 
 // EmitNewValue emits new Value, if it has not already been emitted.
-// Compares Value to EmitValue and only emits if different, sets EmitValue.
-// Returns true if value emitted, false otherwise.
+// Compares Value to EmitValue (and HighValue to EmitHigh if Range) and only
+// emits if different, sets EmitValue.  Returns true if value emitted, false
+// otherwise.
 func (sb *SliderBase) EmitNewValue() bool {
-	if sb.Value == sb.EmitValue {
+	if sb.Value == sb.EmitValue && (!sb.Range || sb.HighValue == sb.EmitHigh) {
 		return false
 	}
 	sb.SliderSig.Emit(sb.This(), int64(SliderValueChanged), sb.Value)
 	sb.EmitValue = sb.Value
+	sb.EmitHigh = sb.HighValue
 	return true
 }
 
+// IsLog returns true if the values are scaled logarithmically along the
+// slider, which requires Log and a Min > 0
+func (sb *SliderBase) IsLog() bool {
+	return sb.Log && sb.Min > 0 && sb.Max > sb.Min
+}
+
+// ValueToFrac returns the fraction of the way along the slider, from Min to
+// Max, of given value, taking into account Log scaling
+func (sb *SliderBase) ValueToFrac(val float32) float32 {
+	if sb.IsLog() {
+		if val <= sb.Min {
+			return 0
+		}
+		return mat32.Log(val/sb.Min) / mat32.Log(sb.Max/sb.Min)
+	}
+	return (val - sb.Min) / (sb.Max - sb.Min)
+}
+
+// FracToValue returns the value at given fraction of the way along the
+// slider, from Min to Max, taking into account Log scaling
+func (sb *SliderBase) FracToValue(frac float32) float32 {
+	if sb.IsLog() {
+		return sb.Min * mat32.Pow(sb.Max/sb.Min, frac)
+	}
+	return sb.Min + (sb.Max-sb.Min)*frac
+}
+
+// PosValue returns the value at given position in pixels, truncated to Prec,
+// clamped to Min, Max and snapped to Step if Snap
+func (sb *SliderBase) PosValue(pos float32) float32 {
+	val := mat32.Truncate(sb.FracToValue(pos/sb.Size), sb.Prec)
+	val = mat32.Clamp(val, sb.Min, sb.Max)
+	if sb.Snap {
+		val = mat32.Truncate(mat32.IntMultiple(val, sb.Step), sb.Prec)
+	}
+	return val
+}
+
 // SetSliderPos sets the position of the slider at the given position in pixels,
 // and updates the corresponding Value based on that position.
 func (sb *SliderBase) SetSliderPos(pos float32) {
+	if sb.Range {
+		sb.SetThumbPos(pos, sb.HighThumb)
+		return
+	}
 	updt := sb.UpdateStart()
 	sb.Pos = pos
 	sb.Pos = mat32.Min(sb.Size, sb.Pos)
@@ -273,7 +347,7 @@ func (sb *SliderBase) SetSliderPos(pos float32) {
 		}
 	}
 	sb.Pos = mat32.Max(0, sb.Pos)
-	sb.Value = mat32.Truncate(sb.Min+(sb.Max-sb.Min)*(sb.Pos/effSz), sb.Prec)
+	sb.Value = mat32.Truncate(sb.FracToValue(sb.Pos/effSz), sb.Prec)
 	sb.Value = mat32.Clamp(sb.Value, sb.Min, sb.Max)
 	if sb.ValThumb {
 		sb.Value = mat32.Min(sb.Value, sb.Max-sb.ThumbVal)
@@ -289,10 +363,90 @@ func (sb *SliderBase) SetSliderPos(pos float32) {
 	sb.UpdateEnd(updt)
 }
 
+// SetThumbPos sets the position of the low thumb of a Range slider, or the
+// high one if high, at the given position in pixels, and updates the
+// corresponding value -- a thumb cannot move past the other one
+func (sb *SliderBase) SetThumbPos(pos float32, high bool) {
+	if sb.Size <= 0 {
+		return
+	}
+	updt := sb.UpdateStart()
+	pos = mat32.Clamp(pos, 0, sb.Size)
+	val := sb.PosValue(pos)
+	if high {
+		sb.HighValue = mat32.Max(val, sb.Value)
+		sb.HighDragPos = pos
+	} else {
+		sb.Value = mat32.Min(val, sb.HighValue)
+		sb.DragPos = pos
+	}
+	sb.UpdatePosFromValue()
+	sb.TrackRange()
+	sb.UpdateEnd(updt)
+}
+
+// SetRangePos moves both thumbs of a Range slider, putting the low one at the
+// given position in pixels and keeping the distance between them, and
+// updates the values
+func (sb *SliderBase) SetRangePos(pos float32) {
+	if sb.Size <= 0 {
+		return
+	}
+	updt := sb.UpdateStart()
+	wd := sb.HighDragPos - sb.DragPos
+	pos = mat32.Clamp(pos, 0, sb.Size-wd)
+	sb.Value = sb.PosValue(pos)
+	sb.HighValue = mat32.Max(sb.PosValue(pos+wd), sb.Value)
+	sb.DragPos = pos
+	sb.HighDragPos = pos + wd
+	sb.UpdatePosFromValue()
+	sb.TrackRange()
+	sb.UpdateEnd(updt)
+}
+
+// TrackRange emits the new values of a Range slider if Tracking and either
+// of them has changed by more than TrackThr
+func (sb *SliderBase) TrackRange() {
+	if !sb.Tracking {
+		return
+	}
+	if mat32.Abs(sb.Value-sb.EmitValue) > sb.TrackThr || mat32.Abs(sb.HighValue-sb.EmitHigh) > sb.TrackThr {
+		sb.EmitNewValue()
+	}
+}
+
+// RangePress handles a press at given position in pixels on a Range slider:
+// between the thumbs, and not on either of them, it starts dragging the
+// whole range -- otherwise the closest thumb becomes the current one, and
+// moves to the position unless it was pressed directly
+func (sb *SliderBase) RangePress(pos float32) {
+	ht := 0.5 * sb.ThSize
+	sb.DragPos = sb.Pos
+	sb.HighDragPos = sb.HighPos
+	if pos > sb.Pos+ht && pos < sb.HighPos-ht {
+		sb.DragRange = true
+		return
+	}
+	dl := mat32.Abs(pos - sb.Pos)
+	dh := mat32.Abs(pos - sb.HighPos)
+	sb.HighThumb = dh < dl || (dh == dl && pos > sb.HighPos)
+	if mat32.Min(dl, dh) <= ht {
+		return
+	}
+	sb.SetThumbPos(pos, sb.HighThumb)
+}
+
 // SliderMove called when slider moved along relevant axis
 func (sb *SliderBase) SliderMove(start, end float32) {
 	del := end - start
-	sb.SetSliderPos(sb.DragPos + del)
+	switch {
+	case sb.Range && sb.DragRange:
+		sb.SetRangePos(sb.DragPos + del)
+	case sb.Range && sb.HighThumb:
+		sb.SetThumbPos(sb.HighDragPos+del, true)
+	default:
+		sb.SetSliderPos(sb.DragPos + del)
+	}
 	sb.SliderSig.Emit(sb.This(), int64(SliderMoved), sb.Value)
 }
 
@@ -309,14 +463,21 @@ func (sb *SliderBase) UpdatePosFromValue() {
 			effSz -= 0.5 // rounding errors
 		}
 	}
-	sb.Pos = effSz * (sb.Value - sb.Min) / (sb.Max - sb.Min)
+	sb.Pos = effSz * sb.ValueToFrac(sb.Value)
+	if sb.Range {
+		sb.HighPos = effSz * sb.ValueToFrac(sb.HighValue)
+	}
 }
 
 // SetValue sets the value and updates the slider position, but does not
-// emit an updated signal (see SetValueAction)
+// emit an updated signal (see SetValueAction) -- if Range, the value cannot
+// go above HighValue (see SetRangeValues)
 func (sb *SliderBase) SetValue(val float32) {
 	updt := sb.UpdateStart()
 	val = mat32.Min(val, sb.Max)
+	if sb.Range {
+		val = mat32.Min(val, sb.HighValue)
+	}
 	if sb.ValThumb {
 		val = mat32.Min(val, sb.Max-sb.ThumbVal)
 	}
@@ -339,6 +500,59 @@ func (sb *SliderBase) SetValueAction(val float32) {
 	sb.EmitNewValue()
 }
 
+// SetHighValue sets the high value of a Range slider, which cannot go below
+// Value, and updates the slider position, but does not emit an updated
+// signal (see SetHighValueAction)
+func (sb *SliderBase) SetHighValue(val float32) {
+	updt := sb.UpdateStart()
+	val = mat32.Clamp(val, sb.Min, sb.Max)
+	val = mat32.Max(val, sb.Value)
+	if sb.HighValue != val {
+		sb.HighValue = val
+		sb.UpdatePosFromValue()
+		sb.HighDragPos = sb.HighPos
+	}
+	sb.UpdateEnd(updt)
+}
+
+// SetHighValueAction sets the high value of a Range slider and updates the
+// slider representation, and emits a changed signal
+func (sb *SliderBase) SetHighValueAction(val float32) {
+	if sb.HighValue == val {
+		return
+	}
+	sb.SetHighValue(val)
+	sb.EmitNewValue()
+}
+
+// SetRangeValues sets Range and the low and high values, in either order,
+// and updates the slider position, but does not emit an updated signal
+func (sb *SliderBase) SetRangeValues(low, high float32) {
+	updt := sb.UpdateStart()
+	if high < low {
+		low, high = high, low
+	}
+	sb.Range = true
+	sb.Value = mat32.Clamp(low, sb.Min, sb.Max)
+	sb.HighValue = mat32.Clamp(high, sb.Min, sb.Max)
+	sb.UpdatePosFromValue()
+	sb.DragPos = sb.Pos
+	sb.HighDragPos = sb.HighPos
+	sb.UpdateEnd(updt)
+}
+
+// MoveRangeAction moves both values of a Range slider by given amount,
+// keeping the distance between them within Min and Max, and emits a changed
+// signal
+func (sb *SliderBase) MoveRangeAction(del float32) {
+	del = mat32.Clamp(del, sb.Min-sb.Value, sb.Max-sb.HighValue)
+	if del == 0 {
+		return
+	}
+	sb.SetRangeValues(sb.Value+del, sb.HighValue+del)
+	sb.EmitNewValue()
+}
+
 // SetThumbValue sets the thumb value to given value and updates the thumb size
 // -- for scrollbar-style sliders where the thumb size represents visible range
 func (sb *SliderBase) SetThumbValue(val float32) {
@@ -359,41 +573,123 @@ func (sb *SliderBase) UpdateThumbValSize() {
 	sb.ThSize = mat32.Max(sb.ThSizeReal, SliderMinThumbSize)
 }
 
+// SliderMaxTicks is the maximum number of tick marks, major and minor, on a
+// slider -- limits the cost of a small MajorTick relative to the range
+var SliderMaxTicks = 500
+
+// TickValues returns the values of the major and minor tick marks within Min
+// and Max: major ones at multiples of MajorTick, or at its powers if Log,
+// with MinorTicks evenly spaced ones between them
+func (sb *SliderBase) TickValues() (major, minor []float32) {
+	if sb.MajorTick <= 0 || sb.Max <= sb.Min {
+		return
+	}
+	nmin := ints.MaxInt(sb.MinorTicks, 0)
+	if sb.IsLog() {
+		if sb.MajorTick <= 1 {
+			return
+		}
+		lmin, lmax := sb.Min*(1-1.0e-4), sb.Max*(1+1.0e-4)
+		v := mat32.Pow(sb.MajorTick, mat32.Floor(mat32.Log(sb.Min)/mat32.Log(sb.MajorTick)))
+		for ; v <= lmax && len(major)+len(minor) < SliderMaxTicks; v *= sb.MajorTick {
+			if v >= lmin {
+				major = append(major, mat32.Truncate(v, sb.Prec))
+			}
+			stp := (v*sb.MajorTick - v) / float32(nmin+1)
+			for i := 1; i <= nmin; i++ {
+				if mv := v + float32(i)*stp; mv >= lmin && mv <= lmax {
+					minor = append(minor, mat32.Truncate(mv, sb.Prec))
+				}
+			}
+		}
+		return
+	}
+	tol := 1.0e-4 * (sb.Max - sb.Min)
+	stp := sb.MajorTick / float32(nmin+1)
+	st := mat32.Floor(sb.Min/sb.MajorTick) * sb.MajorTick
+	for i := 0; len(major)+len(minor) < SliderMaxTicks; i++ {
+		v := st + float32(i)*stp
+		if v > sb.Max+tol {
+			break
+		}
+		if v < sb.Min-tol {
+			continue
+		}
+		v = mat32.Truncate(v, sb.Prec)
+		if i%(nmin+1) == 0 {
+			major = append(major, v)
+		} else {
+			minor = append(minor, v)
+		}
+	}
+	return
+}
+
+// TickLabel returns the label for a major tick at given value, formatted
+// with TickFormat
+func (sb *SliderBase) TickLabel(val float32) string {
+	if sb.TickFormat == "" {
+		return fmt.Sprintf("%g", val)
+	}
+	return fmt.Sprintf(sb.TickFormat, val)
+}
+
+// TickLen returns the length of the major tick marks in dots -- the minor
+// ones are half as long
+func (sb *SliderBase) TickLen() float32 {
+	return mat32.Round(0.4 * sb.ThSize)
+}
+
 func (sb *SliderBase) KeyInput(kt *key.ChordEvent) {
 	if KeyEventTrace {
 		fmt.Printf("SliderBase KeyInput: %v\n", sb.Path())
 	}
+	if sb.Range && kt.Rune == ' ' && !kt.HasAnyModifier(key.Control, key.Meta, key.Alt) {
+		sb.HighThumb = !sb.HighThumb
+		kt.SetProcessed()
+		sb.UpdateSig()
+		return
+	}
+	// the current thumb moves, unless shift moves the whole range
+	val, setVal := sb.Value, sb.SetValueAction
+	if sb.Range {
+		if kt.HasAnyModifier(key.Shift) {
+			setVal = func(v float32) { sb.MoveRangeAction(v - sb.Value) }
+		} else if sb.HighThumb {
+			val, setVal = sb.HighValue, sb.SetHighValueAction
+		}
+	}
 	kf := KeyFun(kt.Chord())
 	switch kf {
 	case KeyFunMoveUp:
-		sb.SetValueAction(sb.Value - sb.Step)
+		setVal(val - sb.Step)
 		kt.SetProcessed()
 	case KeyFunMoveLeft:
-		sb.SetValueAction(sb.Value - sb.Step)
+		setVal(val - sb.Step)
 		kt.SetProcessed()
 	case KeyFunMoveDown:
-		sb.SetValueAction(sb.Value + sb.Step)
+		setVal(val + sb.Step)
 		kt.SetProcessed()
 	case KeyFunMoveRight:
-		sb.SetValueAction(sb.Value + sb.Step)
+		setVal(val + sb.Step)
 		kt.SetProcessed()
 	case KeyFunPageUp:
-		sb.SetValueAction(sb.Value - sb.PageStep)
+		setVal(val - sb.PageStep)
 		kt.SetProcessed()
 	// case KeyFunPageLeft:
 	// 	sb.SetValueAction(sb.Value - sb.PageStep)
 	// 	kt.SetProcessed()
 	case KeyFunPageDown:
-		sb.SetValueAction(sb.Value + sb.PageStep)
+		setVal(val + sb.PageStep)
 		kt.SetProcessed()
 	// case KeyFunPageRight:
 	// 	sb.SetValueAction(sb.Value + sb.PageStep)
 	// 	kt.SetProcessed()
 	case KeyFunHome:
-		sb.SetValueAction(sb.Min)
+		setVal(sb.Min)
 		kt.SetProcessed()
 	case KeyFunEnd:
-		sb.SetValueAction(sb.Max)
+		setVal(sb.Max)
 		kt.SetProcessed()
 	}
 }
@@ -439,7 +735,7 @@ func (sb *SliderBase) MouseEvent() {
 				if me.Action == mouse.Press {
 					ed := sbb.This().(SliderPositioner).PointToRelPos(me.Where)
 					st := &sbb.Sty
-					spc := st.Layout.Margin.Dots + 0.5*sbb.ThSizeReal
+					spc := st.Layout.Margin.Dots().Pos().Dim(sbb.Dim) + 0.5*sbb.ThSizeReal
 					if sbb.Dim == mat32.X {
 						sbb.SliderPress(float32(ed.X) - spc)
 					} else {
@@ -527,12 +823,12 @@ func (sb *SliderBase) ConfigPartsIfNeeded(render bool) {
 		ick := sb.Parts.ChildByType(KiT_Icon, ki.Embeds, 0)
 		if ick != nil {
 			ic := ick.(*Icon)
-			mrg := sb.Sty.Layout.Margin.Dots
-			pad := sb.Sty.Layout.Padding.Dots
-			spc := mrg + pad
+			mrg := sb.Sty.Layout.Margin.Dots()
+			pad := sb.Sty.Layout.Padding.Dots()
+			spc := mrg.Add(pad)
 			odim := mat32.OtherDim(sb.Dim)
-			ic.LayState.Alloc.PosRel.SetDim(sb.Dim, sb.Pos+spc-0.5*sb.ThSize)
-			ic.LayState.Alloc.PosRel.SetDim(odim, -pad)
+			ic.LayState.Alloc.PosRel.SetDim(sb.Dim, sb.Pos+spc.Pos().Dim(sb.Dim)-0.5*sb.ThSize)
+			ic.LayState.Alloc.PosRel.SetDim(odim, -pad.Pos().Dim(odim))
 			ic.LayState.Alloc.Size.X = sb.ThSize
 			ic.LayState.Alloc.Size.Y = sb.ThSize
 			if render {
@@ -603,6 +899,32 @@ func (sr *SliderBase) StyleFromProps(props ki.Props, vp *Viewport2D) {
 			if bv, ok := kit.ToBool(val); ok {
 				sr.Snap = bv
 			}
+		case "range":
+			if bv, ok := kit.ToBool(val); ok {
+				sr.Range = bv
+			}
+		case "high-value":
+			if iv, ok := kit.ToFloat32(val); ok {
+				sr.HighValue = iv
+			}
+		case "log":
+			if bv, ok := kit.ToBool(val); ok {
+				sr.Log = bv
+			}
+		case "major-tick":
+			if iv, ok := kit.ToFloat32(val); ok {
+				sr.MajorTick = iv
+			}
+		case "minor-ticks":
+			if iv, ok := kit.ToInt(val); ok {
+				sr.MinorTicks = int(iv)
+			}
+		case "tick-labels":
+			if bv, ok := kit.ToBool(val); ok {
+				sr.TickLabels = bv
+			}
+		case "tick-format":
+			sr.TickFormat = kit.ToString(val)
 		}
 	}
 }
@@ -621,6 +943,7 @@ func (sr *SliderBase) StyleSlider() {
 	for i := 0; i < int(SliderStatesN); i++ {
 		sr.StateStyles[i].CopyFrom(&sr.Sty)
 		sr.StateStyles[i].SetStyleProps(pst, sr.StyleProps(SliderSelectors[i]), sr.Viewport)
+		StyleCSS(sr.This().(Node2D), sr.Viewport, &sr.StateStyles[i], sr.CSSAgg, SliderSelectors[i])
 		sr.StateStyles[i].CopyUnitContext(&sr.Sty.UnContext)
 	}
 	sr.StyleFromProps(sr.Props, sr.Viewport)           // does all the min / max / step etc
@@ -638,7 +961,9 @@ func (sr *SliderBase) StyleSlider() {
 //  Slider
 
 // Slider is a standard value slider with a fixed-sized thumb knob -- if an
-// Icon is set, it is used for the knob of the slider
+// Icon is set, it is used for the knob of the slider.  If Range, it has a low
+// and a high thumb selecting a range of values.  Tick marks, optionally
+// labeled, are drawn below (or to the right of) the slider if MajorTick > 0.
 type Slider struct {
 	SliderBase
 }
@@ -730,8 +1055,34 @@ func (sr *Slider) Size2D(iter int) {
 	}
 	st := &sr.Sty
 	// get at least thumbsize + margin + border.size
-	sz := sr.ThSize + 2.0*(st.Layout.Margin.Dots+st.Border.Width.Dots)
-	sr.LayState.Alloc.Size.SetDim(mat32.OtherDim(sr.Dim), sz)
+	odim := mat32.OtherDim(sr.Dim)
+	sz := sr.ThSize + st.Layout.Margin.Dots().Add(st.Border.WidthDots()).Size().Dim(odim)
+	sz += sr.TicksSize()
+	sr.LayState.Alloc.Size.SetDim(odim, sz)
+}
+
+// TicksSize returns the size in dots, in the other dimension, of the area
+// for the tick marks and their labels -- 0 if no ticks
+func (sr *Slider) TicksSize() float32 {
+	major, minor := sr.TickValues()
+	if len(major)+len(minor) == 0 {
+		return 0
+	}
+	sz := sr.TickLen() + 2
+	if !sr.TickLabels || len(major) == 0 {
+		return sz
+	}
+	st := &sr.Sty
+	if sr.Dim == mat32.X {
+		return sz + st.Font.Face.Metrics.Height
+	}
+	mx := float32(0)
+	var tr girl.Text
+	for _, v := range major {
+		tr.SetString(sr.TickLabel(v), &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+		mx = mat32.Max(mx, tr.Size.X)
+	}
+	return sz + mx
 }
 
 func (sr *Slider) Layout2D(parBBox image.Rectangle, iter int) bool {
@@ -772,8 +1123,8 @@ func (sr *Slider) Render2DDefaultStyle() {
 	// overall fill box
 	sr.RenderStdBox(&sr.StateStyles[SliderBox])
 
-	pc.StrokeStyle.SetColor(&st.Border.Color)
-	pc.StrokeStyle.Width = st.Border.Width
+	pc.StrokeStyle.SetColor(&st.Border.Color.Top)
+	pc.StrokeStyle.Width = st.Border.Width.Top
 	pc.FillStyle.SetColorSpec(&st.Font.BgColor)
 
 	// layout is as follows, for width dimension
@@ -792,23 +1143,50 @@ func (sr *Slider) Render2DDefaultStyle() {
 	tpos := pos // thumb pos
 
 	ht := 0.5 * sr.ThSize
+	tksz := sr.TicksSize()
 
 	odim := mat32.OtherDim(sr.Dim)
-	bpos.SetAddDim(odim, spc)
-	bsz.SetSubDim(odim, 2.0*spc)
-	bpos.SetAddDim(sr.Dim, spc+ht)
-	bsz.SetSubDim(sr.Dim, 2.0*(spc+ht))
-	sr.RenderBoxImpl(bpos, bsz, st.Border.Radius.Dots)
-
-	bsz.SetDim(sr.Dim, sr.Pos)
+	bpos.SetAddDim(odim, spc.Pos().Dim(odim))
+	bsz.SetSubDim(odim, spc.Size().Dim(odim)+tksz)
+	bpos.SetAddDim(sr.Dim, spc.Pos().Dim(sr.Dim)+ht)
+	bsz.SetSubDim(sr.Dim, spc.Size().Dim(sr.Dim)+2.0*ht)
+	sr.RenderBoxImpl(bpos, bsz, st.Border.Radius.Dots())
+
+	vpos := bpos
+	if sr.Range {
+		vpos.SetAddDim(sr.Dim, sr.Pos)
+		bsz.SetDim(sr.Dim, sr.HighPos-sr.Pos)
+	} else {
+		bsz.SetDim(sr.Dim, sr.Pos)
+	}
 	pc.FillStyle.SetColorSpec(&sr.StateStyles[SliderValue].Font.BgColor)
-	sr.RenderBoxImpl(bpos, bsz, st.Border.Radius.Dots)
+	sr.RenderBoxImpl(vpos, bsz, st.Border.Radius.Dots())
 
 	tpos.SetDim(sr.Dim, bpos.Dim(sr.Dim)+sr.Pos)
-	tpos.SetAddDim(odim, 0.5*sz.Dim(odim)) // ctr
+	tpos.SetAddDim(odim, 0.5*(sz.Dim(odim)-tksz)) // ctr
+	if tksz > 0 {
+		sr.RenderTicks(rs, pc, st, bpos.Dim(sr.Dim), tpos.Dim(odim)+ht+1)
+		pc.StrokeStyle.SetColor(&st.Border.Color.Top)
+		pc.StrokeStyle.Width = st.Border.Width.Top
+	}
 	pc.FillStyle.SetColorSpec(&st.Font.BgColor)
 
-	if sr.Icon.IsValid() && sr.Parts.HasChildren() {
+	if sr.Range {
+		// the current thumb has the border of the state, the other the normal one
+		hpos := tpos
+		hpos.SetDim(sr.Dim, bpos.Dim(sr.Dim)+sr.HighPos)
+		cpos, opos := tpos, hpos
+		if sr.HighThumb {
+			cpos, opos = hpos, tpos
+		}
+		pc.StrokeStyle.Width = sr.StateStyles[SliderActive].Border.Width.Top
+		pc.DrawCircle(rs, opos.X, opos.Y, ht)
+		pc.FillStrokeClear(rs)
+		pc.StrokeStyle.Width = st.Border.Width.Top
+		pc.DrawCircle(rs, cpos.X, cpos.Y, ht)
+		pc.FillStrokeClear(rs)
+		sr.RenderUnlock(rs)
+	} else if sr.Icon.IsValid() && sr.Parts.HasChildren() {
 		sr.RenderUnlock(rs)
 		sr.Parts.Render2DTree()
 	} else {
@@ -818,6 +1196,43 @@ func (sr *Slider) Render2DDefaultStyle() {
 	}
 }
 
+// RenderTicks renders the tick marks, and their labels if TickLabels, with
+// the slider track starting at given start position in the slider
+// dimension, and the tick area at given position in the other dimension
+func (sr *Slider) RenderTicks(rs *girl.State, pc *girl.Paint, st *gist.Style, start, tkpos float32) {
+	major, minor := sr.TickValues()
+	odim := mat32.OtherDim(sr.Dim)
+	tl := sr.TickLen()
+	pc.StrokeStyle.SetColor(&st.Font.Color)
+	pc.StrokeStyle.Width.SetDot(1)
+	tick := func(val, ln float32) {
+		var p1 mat32.Vec2
+		p1.SetDim(sr.Dim, start+sr.Size*sr.ValueToFrac(val))
+		p1.SetDim(odim, tkpos)
+		p2 := p1
+		p2.SetAddDim(odim, ln)
+		pc.DrawLine(rs, p1.X, p1.Y, p2.X, p2.Y)
+	}
+	for _, v := range minor {
+		tick(v, 0.5*tl)
+	}
+	for _, v := range major {
+		tick(v, tl)
+	}
+	pc.Stroke(rs)
+	if !sr.TickLabels {
+		return
+	}
+	var tr girl.Text
+	for _, v := range major {
+		tr.SetString(sr.TickLabel(v), &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+		var tp mat32.Vec2
+		tp.SetDim(sr.Dim, start+sr.Size*sr.ValueToFrac(v)-0.5*tr.Size.Dim(sr.Dim))
+		tp.SetDim(odim, tkpos+tl+1)
+		tr.RenderTopPos(rs, tp)
+	}
+}
+
 func (sr *Slider) ConnectEvents2D() {
 	sr.SliderEvents()
 }
@@ -955,20 +1370,20 @@ func (sb *ScrollBar) Render2DDefaultStyle() {
 	// overall fill box
 	sb.RenderStdBox(&sb.StateStyles[SliderBox])
 
-	pc.StrokeStyle.SetColor(&st.Border.Color)
-	pc.StrokeStyle.Width = st.Border.Width
+	pc.StrokeStyle.SetColor(&st.Border.Color.Top)
+	pc.StrokeStyle.Width = st.Border.Width.Top
 	pc.FillStyle.SetColorSpec(&st.Font.BgColor)
 
 	// scrollbar is basic box in content size
 	spc := st.BoxSpace()
-	pos := sb.LayState.Alloc.Pos.AddScalar(spc)
-	sz := sb.LayState.Alloc.Size.SubScalar(2.0 * spc)
+	pos := sb.LayState.Alloc.Pos.Add(spc.Pos())
+	sz := sb.LayState.Alloc.Size.Sub(spc.Size())
 
-	sb.RenderBoxImpl(pos, sz, st.Border.Radius.Dots) // surround box
-	pos.SetAddDim(sb.Dim, sb.Pos)                    // start of thumb
+	sb.RenderBoxImpl(pos, sz, st.Border.Radius.Dots()) // surround box
+	pos.SetAddDim(sb.Dim, sb.Pos)                      // start of thumb
 	sz.SetDim(sb.Dim, sb.ThSize)
 	pc.FillStyle.SetColorSpec(&sb.StateStyles[SliderValue].Font.BgColor)
-	sb.RenderBoxImpl(pos, sz, st.Border.Radius.Dots)
+	sb.RenderBoxImpl(pos, sz, st.Border.Radius.Dots())
 }
 
 func (sb *ScrollBar) ConnectEvents2D() {
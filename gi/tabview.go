@@ -106,6 +106,9 @@ func (tv *TabView) InsertTabOnlyAt(widg Node2D, label string, idx int) {
 	tab.Data = idx
 	tab.Tooltip = label
 	tab.NoDelete = tv.NoDeleteTabs
+	if tv.MaxChars > 0 { // label is elided to fit, plus room for close button
+		tab.SetProp("max-width", units.NewCh(float32(tv.MaxChars+2)))
+	}
 	tab.SetText(label)
 	tab.ActionSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		tvv := recv.Embed(KiT_TabView).(*TabView)
@@ -510,9 +513,9 @@ func (tv *TabView) RenderTabSeps() {
 	rs, pc, st := tv.RenderLock()
 	defer tv.RenderUnlock(rs)
 
-	pc.StrokeStyle.Width = st.Border.Width
-	pc.StrokeStyle.SetColor(&st.Border.Color)
-	bw := st.Border.Width.Dots
+	pc.StrokeStyle.Width = st.Border.Width.Left
+	pc.StrokeStyle.SetColor(&st.Border.Color.Left)
+	bw := st.Border.Width.Left.Dots
 
 	tbs := tv.Tabs()
 	sz := len(tbs.Kids)
@@ -521,7 +524,7 @@ func (tv *TabView) RenderTabSeps() {
 		ni := tb.AsWidget()
 
 		pos := ni.LayState.Alloc.Pos
-		sz := ni.LayState.Alloc.Size.AddScalar(-2.0 * st.Layout.Margin.Dots)
+		sz := ni.LayState.Alloc.Size.Sub(st.Layout.Margin.Dots().Size())
 		pc.DrawLine(rs, pos.X-bw, pos.Y, pos.X-bw, pos.Y+sz.Y)
 	}
 	pc.FillStrokeClear(rs)
@@ -579,8 +582,9 @@ var TabButtonProps = ki.Props{
 		"stroke":  &Prefs.Colors.Font,
 	},
 	"#label": ki.Props{
-		"margin":  units.NewPx(0),
-		"padding": units.NewPx(0),
+		"margin":        units.NewPx(0),
+		"padding":       units.NewPx(0),
+		"text-overflow": gist.TextOverflowEllipsis,
 	},
 	"#close-stretch": ki.Props{
 		"width": units.NewCh(1),
@@ -6,6 +6,7 @@ package gi
 
 import (
 	"fmt"
+	"html"
 	"image"
 	"image/draw"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/ime"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
@@ -67,6 +69,8 @@ type TextField struct {
 	CursorMu     sync.Mutex                   `copy:"-" json:"-" xml:"-" view:"-" desc:"mutex for updating cursor between blinker and field"`
 	Complete     *Complete                    `copy:"-" json:"-" xml:"-" desc:"functions and data for textfield completion"`
 	NoEcho       bool                         `copy:"-" json:"-" xml:"-" desc:"replace displayed characters with bullets to conceal text"`
+	Preedit      Preedit                      `copy:"-" json:"-" xml:"-" desc:"input method editor (IME) text composition in progress -- the preedit text is inserted into EditTxt at PreeditPos while composing"`
+	PreeditPos   int                          `copy:"-" json:"-" xml:"-" desc:"position in EditTxt where the IME preedit text is inserted"`
 }
 
 var KiT_TextField = kit.Types.AddType(&TextField{}, TextFieldProps)
@@ -224,6 +228,7 @@ func (tf *TextField) SetText(txt string) {
 // EditDone completes editing and copies the active edited text to the text --
 // called when the return key is pressed or goes out of focus
 func (tf *TextField) EditDone() {
+	tf.ClearPreedit()
 	if tf.Edited {
 		tf.Edited = false
 		tf.Txt = string(tf.EditTxt)
@@ -236,6 +241,7 @@ func (tf *TextField) EditDone() {
 // EditDeFocused completes editing and copies the active edited text to the text --
 // called when field is made inactive due to interactions elsewhere.
 func (tf *TextField) EditDeFocused() {
+	tf.ClearPreedit()
 	if tf.Edited {
 		tf.Edited = false
 		tf.Txt = string(tf.EditTxt)
@@ -251,6 +257,7 @@ func (tf *TextField) Revert() {
 	defer tf.UpdateEnd(updt)
 	tf.EditTxt = []rune(tf.Txt)
 	tf.Edited = false
+	tf.Preedit.Reset()
 	tf.StartPos = 0
 	tf.EndPos = tf.CharWidth
 	tf.SelectReset()
@@ -629,6 +636,100 @@ func (tf *TextField) InsertAtCursor(str string) {
 	tf.TextFieldSig.Emit(tf.This(), int64(TextFieldInsert), tf.EditTxt)
 }
 
+///////////////////////////////////////////////////////////////////////////////
+//    IME text composition
+
+// SetPreedit sets the input method editor (IME) preedit text and cursor from
+// given ime.Preedit event: the preedit text is inserted into the edit text at
+// the cursor, replacing any previous preedit text, and is rendered
+// underlined, but it does not count as an edit until it is committed.
+func (tf *TextField) SetPreedit(e *ime.Event) {
+	updt := tf.UpdateStart()
+	defer tf.UpdateEnd(updt)
+	tf.removePreedit()
+	if e.Text == "" {
+		return
+	}
+	if tf.HasSelection() {
+		tf.DeleteSelection()
+	}
+	tf.Preedit.Set(e)
+	tf.PreeditPos = tf.CursorPos
+	rs := tf.Preedit.Text
+	nt := make([]rune, 0, len(tf.EditTxt)+len(rs))
+	nt = append(nt, tf.EditTxt[:tf.PreeditPos]...)
+	nt = append(nt, rs...)
+	nt = append(nt, tf.EditTxt[tf.PreeditPos:]...)
+	tf.EditTxt = nt
+	tf.EndPos += len(rs)
+	tf.CursorPos = tf.PreeditPos + tf.Preedit.Cursor
+}
+
+// ClearPreedit removes any IME preedit text, e.g., when the composition
+// is cancelled.
+func (tf *TextField) ClearPreedit() {
+	if !tf.Preedit.IsActive() {
+		return
+	}
+	updt := tf.UpdateStart()
+	defer tf.UpdateEnd(updt)
+	tf.removePreedit()
+}
+
+// removePreedit removes the preedit text from the edit text, restoring
+// the cursor to where it was inserted
+func (tf *TextField) removePreedit() {
+	n := len(tf.Preedit.Text)
+	if n == 0 {
+		return
+	}
+	tf.EditTxt = append(tf.EditTxt[:tf.PreeditPos], tf.EditTxt[tf.PreeditPos+n:]...)
+	tf.EndPos = ints.MaxInt(tf.EndPos-n, 0)
+	tf.CursorPos = tf.PreeditPos
+	tf.Preedit.Reset()
+}
+
+// CommitPreedit replaces any IME preedit text with given final text,
+// which is inserted at the cursor as a regular edit.
+func (tf *TextField) CommitPreedit(txt string) {
+	updt := tf.UpdateStart()
+	defer tf.UpdateEnd(updt)
+	tf.removePreedit()
+	if txt != "" {
+		tf.InsertAtCursor(txt)
+	}
+}
+
+// IMECaretRect returns the rectangle of the text cursor, in window
+// coordinates, for positioning the IME candidate window.
+func (tf *TextField) IMECaretRect() image.Rectangle {
+	cpos := tf.CharStartPos(tf.CursorPos, true).ToPointFloor()
+	csz := image.Point{int(mat32.Ceil(tf.CursorWidth.Dots)), int(mat32.Ceil(tf.FontHeight))}
+	return image.Rectangle{Min: cpos, Max: cpos.Add(csz)}
+}
+
+// IMEInput handles IME text composition events
+func (tf *TextField) IMEInput(e *ime.Event) {
+	if tf.IsInactive() {
+		return
+	}
+	e.SetProcessed()
+	switch e.Action {
+	case ime.Start:
+		tf.CancelComplete()
+		tf.ClearPreedit()
+	case ime.Preedit:
+		tf.SetPreedit(e)
+	case ime.Commit:
+		tf.CommitPreedit(e.Text)
+	case ime.Cancel:
+		tf.ClearPreedit()
+	}
+	if win := tf.ParentWindow(); win != nil {
+		win.SetIMECaret(tf.IMECaretRect())
+	}
+}
+
 func (tf *TextField) MakeContextMenu(m *Menu) {
 	cpsc := ActiveKeyMap.ChordForFun(KeyFunCopy)
 	ac := m.AddAction(ActOpts{Label: "Copy", Shortcut: cpsc},
@@ -759,7 +860,7 @@ func (tf *TextField) StartCharPos(idx int) float32 {
 func (tf *TextField) CharStartPos(charidx int, wincoords bool) mat32.Vec2 {
 	st := &tf.Sty
 	spc := st.BoxSpace()
-	pos := tf.LayState.Alloc.Pos.AddScalar(spc)
+	pos := tf.LayState.Alloc.Pos.Add(spc.Pos())
 	if wincoords {
 		mvp := tf.ViewportSafe()
 		mvp.BBoxMu.RLock()
@@ -979,7 +1080,7 @@ func (tf *TextField) AutoScroll() {
 		return
 	}
 	spc := st.BoxSpace()
-	maxw := tf.EffSize.X - 2.0*spc
+	maxw := tf.EffSize.X - spc.Size().X
 	tf.CharWidth = int(maxw / st.UnContext.ToDotsFactor(units.Ch)) // rough guess in chars
 
 	// first rationalize all the values
@@ -1058,7 +1159,7 @@ func (tf *TextField) PixelToCursor(pixOff float32) int {
 	st := &tf.Sty
 
 	spc := st.BoxSpace()
-	px := pixOff - spc
+	px := pixOff - spc.Left
 
 	if px <= 0 {
 		return tf.StartPos
@@ -1132,6 +1233,7 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 	if KeyEventTrace {
 		fmt.Printf("TextField KeyInput: %v\n", tf.Path())
 	}
+	tf.ClearPreedit() // keys are normally consumed by the IME while composing
 	kf := KeyFun(kt.Chord())
 	win := tf.ParentWindow()
 
@@ -1342,12 +1444,45 @@ func (tf *TextField) KeyChordEvent() {
 	}
 }
 
+func (tf *TextField) IMEEvent() {
+	tf.ConnectEvent(oswin.IMEEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tff := recv.Embed(KiT_TextField).(*TextField)
+		tff.IMEInput(d.(*ime.Event))
+	})
+}
+
+// HoverTooltipEvent shows the Tooltip on hover, preceded by the full text
+// if it was elided when rendered
+func (tf *TextField) HoverTooltipEvent() {
+	tf.ConnectEvent(oswin.MouseHoverEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.HoverEvent)
+		tff := recv.Embed(KiT_TextField).(*TextField)
+		tt := tff.Tooltip
+		if tff.IsElided() && tff.RenderVis.Truncated {
+			if tt != "" {
+				tt = html.EscapeString(tff.Txt) + "<br>" + tt
+			} else {
+				tt = html.EscapeString(tff.Txt)
+			}
+		}
+		if tt != "" {
+			me.SetProcessed()
+			tff.BBoxMu.RLock()
+			pos := tff.WinBBox.Max
+			tff.BBoxMu.RUnlock()
+			pos.X -= 20
+			PopupTooltip(tt, pos.X, pos.Y, tff.ViewportSafe(), tff.Nm)
+		}
+	})
+}
+
 func (tf *TextField) TextFieldEvents() {
 	tf.HoverTooltipEvent()
 	tf.MouseDragEvent()
 	tf.MouseEvent()
 	tf.MouseFocusEvent()
 	tf.KeyChordEvent()
+	tf.IMEEvent()
 }
 
 func (tf *TextField) ConfigParts() {
@@ -1464,6 +1599,13 @@ func (tf *TextField) Size2D(iter int) {
 	w += 2.0 // give some extra buffer
 	// fmt.Printf("fontheight: %v width: %v\n", tf.FontHeight, w)
 	tf.Size2DFromWH(w, tf.FontHeight)
+	if tf.Sty.Text.HasTextOverflow() {
+		// text is elided when not editing, so we only need the min-width
+		tf.LayState.Size.Pref.X = mat32.Max(tf.LayState.Size.Pref.X, tf.LayState.Alloc.Size.X)
+		need := tf.BoxSpace().Size().X + LabelOverflowMinChars*tf.Sty.Font.Face.Metrics.Ch
+		need = mat32.Max(need, tf.LayState.Size.Need.X)
+		tf.LayState.Alloc.Size.X = mat32.Min(need, tf.LayState.Alloc.Size.X)
+	}
 	tf.EditTxt = tmptxt
 }
 
@@ -1490,27 +1632,27 @@ func (tf *TextField) RenderTextField() {
 	tf.AutoScroll() // inits paint with our style
 	if tf.IsInactive() {
 		if tf.IsSelected() {
-			tf.Sty = tf.StateStyles[TextFieldSel]
+			tf.SetCurStyle(&tf.StateStyles[TextFieldSel])
 		} else {
-			tf.Sty = tf.StateStyles[TextFieldInactive]
+			tf.SetCurStyle(&tf.StateStyles[TextFieldInactive])
 		}
 	} else if tf.HasFocus() {
 		if tf.IsFocusActive() {
-			tf.Sty = tf.StateStyles[TextFieldFocus]
+			tf.SetCurStyle(&tf.StateStyles[TextFieldFocus])
 		} else {
-			tf.Sty = tf.StateStyles[TextFieldActive]
+			tf.SetCurStyle(&tf.StateStyles[TextFieldActive])
 		}
 	} else if tf.IsSelected() {
-		tf.Sty = tf.StateStyles[TextFieldSel]
+		tf.SetCurStyle(&tf.StateStyles[TextFieldSel])
 	} else {
-		tf.Sty = tf.StateStyles[TextFieldActive]
+		tf.SetCurStyle(&tf.StateStyles[TextFieldActive])
 	}
 	st = &tf.Sty // update
 	girl.OpenFont(&st.Font, &st.UnContext)
 	tf.RenderStdBox(st)
 	cur := tf.EditTxt[tf.StartPos:tf.EndPos]
 	tf.RenderSelect()
-	pos := tf.LayState.Alloc.Pos.AddScalar(st.BoxSpace())
+	pos := tf.LayState.Alloc.Pos.Add(st.BoxSpace().Pos())
 	if len(tf.EditTxt) == 0 && len(tf.Placeholder) > 0 {
 		st.Font.Color = st.Font.Color.Highlight(50)
 		tf.RenderVis.SetString(tf.Placeholder, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
@@ -1520,11 +1662,28 @@ func (tf *TextField) RenderTextField() {
 		if tf.NoEcho {
 			cur = concealDots(len(cur))
 		}
+		if tf.IsElided() {
+			tf.RenderVis.SetRunes(tf.EditTxt, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+			sz := tf.EffSize.Sub(st.BoxSpace().Size())
+			tf.RenderVis.LayoutStdLR(&st.Text, &st.Font, &st.UnContext, mat32.Vec2{X: sz.X})
+			tf.RenderVis.Render(rs, pos)
+			return
+		}
 		tf.RenderVis.SetRunes(cur, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+		if tf.Preedit.IsActive() {
+			tf.Preedit.Decorate(&tf.RenderVis, tf.PreeditPos-tf.StartPos, &tf.StateStyles[TextFieldSel].Font.BgColor.Color)
+		}
 		tf.RenderVis.RenderTopPos(rs, pos)
 	}
 }
 
+// IsElided returns true if the text is rendered in full, with any text that
+// does not fit elided per the text-overflow style, instead of the visible
+// window of editable text -- only when not editing
+func (tf *TextField) IsElided() bool {
+	return tf.Sty.Text.HasTextOverflow() && !tf.NoEcho && !tf.HasFocus() && !tf.HasSelection()
+}
+
 func (tf *TextField) Render2D() {
 	if tf.HasFocus() && tf.IsFocusActive() && BlinkingTextField == tf {
 		tf.ScrollLayoutToCursor()
@@ -0,0 +1,112 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"log"
+	"strings"
+
+	"github.com/goki/gi/gist"
+)
+
+// ThemeTones are the tones (perceptual OKLCH lightness, 0..100) used for
+// each of the ColorPrefs colors generated from seed colors by GenerateTheme
+type ThemeTones struct {
+	Font       float32 `desc:"tone of the Font color, from the neutral palette"`
+	Background float32 `desc:"tone of the Background color, from the neutral palette"`
+	Shadow     float32 `desc:"tone of the Shadow color, from the neutral palette"`
+	Border     float32 `desc:"tone of the Border color, from the neutral variant palette"`
+	Control    float32 `desc:"tone of the Control color, from a low chroma primary palette"`
+	Icon       float32 `desc:"tone of the Icon color, from the primary palette"`
+	Select     float32 `desc:"tone of the Select color, from a reduced chroma primary palette"`
+	Highlight  float32 `desc:"tone of the Highlight color, from a reduced chroma secondary palette"`
+	Link       float32 `desc:"tone of the Link color, from the primary palette"`
+}
+
+// LightThemeTones are the ThemeTones for light mode themes
+var LightThemeTones = ThemeTones{Font: 20, Background: 98, Shadow: 86, Border: 55, Control: 95, Icon: 50, Select: 86, Highlight: 92, Link: 45}
+
+// DarkThemeTones are the ThemeTones for dark mode themes
+var DarkThemeTones = ThemeTones{Font: 88, Background: 16, Shadow: 28, Border: 55, Control: 28, Icon: 75, Select: 38, Highlight: 36, Link: 78}
+
+// ThemeSecondaryHue is the hue rotation in degrees from the primary seed
+// color used for the secondary palette if no secondary seed color is given
+var ThemeSecondaryHue = float32(60)
+
+// GenerateTheme sets all the colors from given primary seed color and
+// optional secondary seed color (if it is nil, a secondary color is derived
+// from the primary), for light or dark mode, using tonal palettes of the
+// seed colors at the LightThemeTones or DarkThemeTones, so that all the
+// colors are consistent with each other.  The foreground colors are then
+// adjusted if needed to be readable on their backgrounds (see FixContrast),
+// and a matching HiStyle highlighting style is generated.
+func (pf *ColorPrefs) GenerateTheme(primary, secondary gist.Color, dark bool) {
+	pp := gist.NewTonalPalette(primary)
+	sp := pp.WithHue(ThemeSecondaryHue)
+	if !secondary.IsNil() {
+		sp = gist.NewTonalPalette(secondary)
+	}
+	np := pp.WithChroma(0.012)
+	nvp := pp.WithChroma(0.04)
+	tn := &LightThemeTones
+	pf.HiStyle = "emacs"
+	if dark {
+		tn = &DarkThemeTones
+		pf.HiStyle = "monokai"
+	}
+	pf.Font = np.Tone(tn.Font)
+	pf.Background = np.Tone(tn.Background)
+	pf.Shadow = np.Tone(tn.Shadow)
+	pf.Border = nvp.Tone(tn.Border)
+	pf.Control = pp.WithChroma(0.05).Tone(tn.Control)
+	pf.Icon = pp.Tone(tn.Icon)
+	pf.Select = pp.WithChroma(0.1).Tone(tn.Select)
+	pf.Highlight = sp.WithChroma(0.12).Tone(tn.Highlight)
+	pf.Link = pp.Tone(tn.Link)
+	pf.FixContrast()
+	if TheViewIFace != nil {
+		nm := ThemeHiStyleName(primary, secondary, dark)
+		if err := TheViewIFace.SetHiStyleFromColors(nm, pf, pp.Tone(50), sp.Tone(50)); err != nil {
+			log.Println(err)
+		}
+		pf.HiStyle = nm
+	}
+}
+
+// ThemeHiStylePrefix is the prefix of the names of the highlighting styles
+// generated by GenerateTheme
+const ThemeHiStylePrefix = "theme-"
+
+// ThemeHiStyleName returns the name of the highlighting style generated
+// by GenerateTheme for given primary and secondary seed colors and mode --
+// the secondary is the one derived from the primary if it is nil
+func ThemeHiStyleName(primary, secondary gist.Color, dark bool) HiStyleName {
+	if secondary.IsNil() {
+		secondary = gist.NewTonalPalette(primary).WithHue(ThemeSecondaryHue).Tone(50)
+	}
+	md := "light"
+	if dark {
+		md = "dark"
+	}
+	hex := func(c gist.Color) string {
+		return strings.ToLower(c.HexString()[1:7])
+	}
+	return HiStyleName(ThemeHiStylePrefix + hex(primary) + "-" + hex(secondary) + "-" + md)
+}
+
+// NewColorScheme adds a new color scheme of given name to ColorSchemes,
+// generated from given seed colors for light or dark mode -- see
+// ColorPrefs.GenerateTheme.  The scheme can then be further edited in
+// the ColorSchemes, and made active with ColorPrefs.SetToPrefs.
+func (pf *Preferences) NewColorScheme(name string, primary, secondary gist.Color, dark bool) *ColorPrefs {
+	cp := &ColorPrefs{}
+	cp.GenerateTheme(primary, secondary, dark)
+	if pf.ColorSchemes == nil {
+		pf.ColorSchemes = DefaultColorSchemes()
+	}
+	pf.ColorSchemes[name] = cp
+	pf.Changed = true
+	return cp
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"time"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// TimePicker
+
+// TimePicker chooses a time of day, with spin boxes for the hours, minutes
+// and (optionally) seconds, and an AM / PM chooser unless Hour24.  Call
+// SetTime (or Config) after creating it.
+type TimePicker struct {
+	Layout
+	Time          time.Time `desc:"the time -- only the time of day is changed, the date is kept"`
+	Hour24        bool      `desc:"show the hours from 0 to 23, instead of 1 to 12 with AM / PM"`
+	Seconds       bool      `desc:"show the seconds"`
+	TimePickerSig ki.Signal `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for time picker -- has no signal types, just emitted when the time changes, with the Time as the data"`
+}
+
+var KiT_TimePicker = kit.Types.AddType(&TimePicker{}, TimePickerProps)
+
+// AddNewTimePicker adds a new time picker to given parent node, with given name.
+func AddNewTimePicker(parent ki.Ki, name string) *TimePicker {
+	return parent.AddNewChild(KiT_TimePicker, name).(*TimePicker)
+}
+
+func (tp *TimePicker) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*TimePicker)
+	tp.Layout.CopyFieldsFrom(&fr.Layout)
+	tp.Time = fr.Time
+	tp.Hour24 = fr.Hour24
+	tp.Seconds = fr.Seconds
+}
+
+func (tp *TimePicker) Disconnect() {
+	tp.Layout.Disconnect()
+	tp.TimePickerSig.DisconnectAll()
+}
+
+var TimePickerProps = ki.Props{
+	"EnumType:Flag": KiT_NodeFlags,
+}
+
+// SetTime sets the time, and updates the display
+func (tp *TimePicker) SetTime(tm time.Time) {
+	tp.Time = tm
+	tp.Config()
+	tp.UpdateTime()
+}
+
+// Config configures the spin boxes for the current Hour24 and Seconds
+// settings
+func (tp *TimePicker) Config() {
+	tp.Lay = LayoutHoriz
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_SpinBox, "hour")
+	config.Add(KiT_Label, "hour-sep")
+	config.Add(KiT_SpinBox, "min")
+	if tp.Seconds {
+		config.Add(KiT_Label, "min-sep")
+		config.Add(KiT_SpinBox, "sec")
+	}
+	if !tp.Hour24 {
+		config.Add(KiT_ComboBox, "am-pm")
+	}
+	mods, updt := tp.ConfigChildren(config)
+	if !mods {
+		updt = tp.UpdateStart()
+	}
+	for _, nm := range []string{"hour", "min", "sec"} {
+		sb, ok := tp.ChildByName(nm, 0).(*SpinBox)
+		if !ok {
+			continue
+		}
+		sb.Defaults()
+		sb.Step = 1
+		sb.Format = "%02d"
+		switch nm {
+		case "hour":
+			sb.PageStep = 6
+			if tp.Hour24 {
+				sb.SetMinMax(true, 0, true, 23)
+			} else {
+				sb.SetMinMax(true, 1, true, 12)
+			}
+		default:
+			sb.PageStep = 10
+			sb.SetMinMax(true, 0, true, 59)
+		}
+		sb.SpinBoxSig.ConnectOnly(tp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tpp := recv.Embed(KiT_TimePicker).(*TimePicker)
+			tpp.TimeFromWidgets()
+		})
+	}
+	tp.ChildByName("hour-sep", 1).(*Label).SetText(":")
+	if tp.Seconds {
+		tp.ChildByName("min-sep", 3).(*Label).SetText(":")
+	}
+	if !tp.Hour24 {
+		ap := tp.ChildByName("am-pm", 3).(*ComboBox)
+		if len(ap.Items) == 0 {
+			ap.ItemsFromStringList([]string{"AM", "PM"}, true, 0)
+		}
+		ap.ComboSig.ConnectOnly(tp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tpp := recv.Embed(KiT_TimePicker).(*TimePicker)
+			tpp.TimeFromWidgets()
+		})
+	}
+	tp.UpdateEnd(updt)
+}
+
+// UpdateTime updates the spin boxes from the Time
+func (tp *TimePicker) UpdateTime() {
+	updt := tp.UpdateStart()
+	hr := tp.Time.Hour()
+	if !tp.Hour24 {
+		ap := tp.ChildByName("am-pm", 3).(*ComboBox)
+		ap.SetCurIndex(hr / 12)
+		hr %= 12
+		if hr == 0 {
+			hr = 12
+		}
+	}
+	tp.ChildByName("hour", 0).(*SpinBox).SetValue(float32(hr))
+	tp.ChildByName("min", 2).(*SpinBox).SetValue(float32(tp.Time.Minute()))
+	if sb, ok := tp.ChildByName("sec", 4).(*SpinBox); ok {
+		sb.SetValue(float32(tp.Time.Second()))
+	}
+	tp.UpdateEnd(updt)
+}
+
+// TimeFromWidgets sets the Time from the spin boxes, keeping the date, and
+// emits the signal
+func (tp *TimePicker) TimeFromWidgets() {
+	hr := int(tp.ChildByName("hour", 0).(*SpinBox).Value)
+	if !tp.Hour24 {
+		hr %= 12
+		if tp.ChildByName("am-pm", 3).(*ComboBox).CurIndex == 1 {
+			hr += 12
+		}
+	}
+	min := int(tp.ChildByName("min", 2).(*SpinBox).Value)
+	tm := tp.Time
+	if tm.IsZero() {
+		tm = DayOf(time.Now())
+	}
+	sec := tm.Second() // kept if not shown
+	if sb, ok := tp.ChildByName("sec", 4).(*SpinBox); ok {
+		sec = int(sb.Value)
+	}
+	y, m, d := tm.Date()
+	tp.Time = time.Date(y, m, d, hr, min, sec, 0, tm.Location())
+	tp.TimePickerSig.Emit(tp.This(), 0, tp.Time)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"log"
+
+	"github.com/goki/gi/gist"
+)
+
+// StyleTransProp is a style property that can be animated by a
+// transition (gist.Transition)
+type StyleTransProp struct {
+
+	// Set sets the property in st to the value that is t of the way
+	// from the from style to the to style -- st and to can be the same
+	Set func(st, from, to *gist.Style, t float32)
+
+	// Same returns true if the property is the same in both styles
+	Same func(a, b *gist.Style) bool
+}
+
+// StyleTransProps are the style properties that can be animated by
+// transitions, by property name.  These are the properties that are
+// rendered by the widget box itself (see WidgetBase.RenderStdBox).
+var StyleTransProps = map[string]*StyleTransProp{
+	"background-color": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			st.Font.BgColor = LerpColorSpec(&from.Font.BgColor, &to.Font.BgColor, t)
+		},
+		Same: func(a, b *gist.Style) bool {
+			return a.Font.BgColor.Source == b.Font.BgColor.Source && a.Font.BgColor.Color == b.Font.BgColor.Color && a.Font.BgColor.Gradient == b.Font.BgColor.Gradient
+		},
+	},
+	"color": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			st.Font.Color = LerpColor(from.Font.Color, to.Font.Color, t)
+		},
+		Same: func(a, b *gist.Style) bool { return a.Font.Color == b.Font.Color },
+	},
+	"opacity": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			st.Font.Opacity = from.Font.Opacity + t*(to.Font.Opacity-from.Font.Opacity)
+		},
+		Same: func(a, b *gist.Style) bool { return a.Font.Opacity == b.Font.Opacity },
+	},
+	"border-color": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			st.Border.Color = LerpSideColors(from.Border.Color, to.Border.Color, t)
+		},
+		Same: func(a, b *gist.Style) bool { return a.Border.Color == b.Border.Color },
+	},
+	"border-width": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			st.Border.Width = LerpSideValues(from.Border.Width, to.Border.Width, t, nil)
+		},
+		Same: func(a, b *gist.Style) bool { return a.Border.Width.Dots() == b.Border.Width.Dots() },
+	},
+	"border-radius": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			st.Border.Radius = LerpSideValues(from.Border.Radius, to.Border.Radius, t, nil)
+		},
+		Same: func(a, b *gist.Style) bool { return a.Border.Radius.Dots() == b.Border.Radius.Dots() },
+	},
+	"box-shadow": {
+		Set: func(st, from, to *gist.Style, t float32) {
+			shs := LerpShadows(from.BoxShadows(), to.BoxShadows(), t)
+			if len(shs) == 0 {
+				st.BoxShadow, st.ExtraShadows = to.BoxShadow, to.ExtraShadows
+				return
+			}
+			st.BoxShadow, st.ExtraShadows = shs[0], shs[1:]
+		},
+		Same: func(a, b *gist.Style) bool { return sameShadows(a.BoxShadows(), b.BoxShadows()) },
+	},
+}
+
+// sameShadows returns true if the given shadows render the same
+func sameShadows(a, b []gist.Shadow) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		as, bs := &a[i], &b[i]
+		if as.HOffset.Dots != bs.HOffset.Dots || as.VOffset.Dots != bs.VOffset.Dots || as.Blur.Dots != bs.Blur.Dots ||
+			as.Spread.Dots != bs.Spread.Dots || as.Color != bs.Color || as.Inset != bs.Inset {
+			return false
+		}
+	}
+	return true
+}
+
+// styleTrans is the state of a style transition animation
+type styleTrans struct {
+	prop *StyleTransProp
+	from gist.Style
+	t    float32
+}
+
+// SetCurStyle sets the current style of the widget (Sty) to given style,
+// e.g., one of its state styles on a change in hover, focus or selection
+// state, starting style transitions from the current style for any
+// properties that have a transition (see gist.Transition) and change.
+// All widgets should set their state-dependent styles with this method, so
+// that transitions work on all of them.  Any locking of StyMu must be done
+// by the caller.
+func (wb *WidgetBase) SetCurStyle(st *gist.Style) {
+	if !wb.Sty.IsSet || len(st.Transition) == 0 {
+		wb.Sty = *st
+		return
+	}
+	from := wb.Sty
+	wb.Sty = *st
+	wb.StartStyleTransitions(&from, st)
+}
+
+// StartStyleTransitions starts animated transitions from the from style to
+// the to style, for each property that has a transition in the to style
+// (see gist.Transition) and differs between the two.  Any transitions
+// already in progress to the from style start over from their current
+// values.  This is called by SetCurStyle, and the transitions in progress
+// are rendered by RenderStdBox (see TransStyle).
+func (wb *WidgetBase) StartStyleTransitions(from, to *gist.Style) {
+	if len(to.Transition) == 0 {
+		return
+	}
+	win := wb.ParentWindow()
+	if win == nil || win.IsClosed() {
+		return
+	}
+	cur := *from
+	wb.ApplyStyleTransitions(&cur)
+	for nm, tp := range StyleTransProps {
+		tr, ok := to.TransitionFor(nm)
+		if !ok || tr.Duration <= 0 || tp.Same(from, to) {
+			continue
+		}
+		ease, err := EaseByName(tr.Ease)
+		if err != nil {
+			log.Println(err)
+		}
+		st := &styleTrans{prop: tp, from: cur}
+		an := &Anim{Name: "transition:" + nm, Target: wb.This(), Delay: tr.Delay, Duration: tr.Duration, Ease: ease, style: st}
+		an.Update = func(t float32) {
+			st.t = t
+			wb.UpdateSig()
+		}
+		win.Animate(an)
+	}
+}
+
+// ApplyStyleTransitions applies any style transitions in progress for this
+// widget to given style, which is the style that the transitions are going
+// to -- see StartStyleTransitions.
+func (wb *WidgetBase) ApplyStyleTransitions(st *gist.Style) {
+	win := wb.ParentWindow()
+	if win == nil {
+		return
+	}
+	am := &win.Anims
+	am.Mu.Lock()
+	defer am.Mu.Unlock()
+	for _, an := range am.Anims {
+		if an.style == nil || an.Target != wb.This() {
+			continue
+		}
+		an.style.prop.Set(st, &an.style.from, st, an.style.t)
+	}
+}
+
+// TransStyle returns the style to render for given style, which is a copy
+// of it with any style transitions in progress applied if it is the current
+// style of the widget (Sty), and otherwise the style itself.
+func (wb *WidgetBase) TransStyle(st *gist.Style) *gist.Style {
+	if st != &wb.Sty || len(st.Transition) == 0 {
+		return st
+	}
+	win := wb.ParentWindow()
+	if win == nil || !win.Anims.IsAnimating() {
+		return st
+	}
+	tst := *st
+	wb.ApplyStyleTransitions(&tst)
+	return &tst
+}
@@ -91,6 +91,8 @@ type Viewport2D struct {
 	ReStack      []Node2D     `copy:"-" json:"-" xml:"-" view:"-" desc:"stack of nodes requiring a ReRender (i.e., anchors)"`
 	StackMu      sync.Mutex   `copy:"-" json:"-" xml:"-" view:"-" desc:"StackMu is mutex for adding to UpdtStack"`
 	StyleMu      sync.RWMutex `copy:"-" json:"-" xml:"-" view:"-" desc:"StyleMu is RW mutex protecting access to Style-related global vars"`
+	openAnim     *Anim
+	openFrac     float32
 }
 
 var KiT_Viewport2D = kit.Types.AddType(&Viewport2D{}, Viewport2DProps)
@@ -201,6 +203,17 @@ const (
 	// only for Over
 	VpFlagPrefSizing
 
+	// VpFlagOffscreen means that this viewport renders offscreen into its
+	// Pixels image without being in a window (e.g., for tests) -- it is
+	// visible for rendering purposes even though it has no window
+	VpFlagOffscreen
+
+	// VpFlagPrinting means that this viewport is rendering for vector
+	// output (see RenderVector) -- layouts use their full preferred size,
+	// e.g., all the rows of slice views, and the rendering is not uploaded
+	// to the window
+	VpFlagPrinting
+
 	VpFlagsN
 )
 
@@ -240,6 +253,10 @@ func (vp *Viewport2D) IsDoingFullRender() bool {
 	return vp.HasFlag(int(VpFlagDoingFullRender))
 }
 
+func (vp *Viewport2D) IsOffscreen() bool {
+	return vp.HasFlag(int(VpFlagOffscreen))
+}
+
 func (vp *Viewport2D) IsVisible() bool {
 	if vp == nil || vp.This() == nil || vp.IsInvisible() {
 		return false
@@ -297,9 +314,12 @@ func (vp *Viewport2D) VpEventMgr() *EventMgr {
 }
 
 func (vp *Viewport2D) VpIsVisible() bool {
-	if vp == nil || vp.This() == nil || vp.Win == nil || vp.Pixels == nil {
+	if vp == nil || vp.This() == nil || vp.Pixels == nil {
 		return false
 	}
+	if vp.Win == nil {
+		return vp.IsOffscreen()
+	}
 	return vp.Win.IsVisible()
 }
 
@@ -310,7 +330,7 @@ func (vp *Viewport2D) VpIsVisible() bool {
 // calls UploadAllViewports in parent window, which uploads the main viewport
 // and any active popups etc over the top of that
 func (vp *Viewport2D) VpUploadAll() {
-	if !vp.This().(Viewport).VpIsVisible() {
+	if !vp.This().(Viewport).VpIsVisible() || vp.Win == nil { // offscreen: nothing to upload
 		return
 	}
 	vp.Win.UploadAllViewports()
@@ -319,7 +339,7 @@ func (vp *Viewport2D) VpUploadAll() {
 // VpUploadVp uploads our viewport image into the parent window -- e.g., called
 // by popups when updating separately
 func (vp *Viewport2D) VpUploadVp() {
-	if !vp.This().(Viewport).VpIsVisible() {
+	if !vp.This().(Viewport).VpIsVisible() || vp.Win == nil { // offscreen: nothing to upload
 		return
 	}
 	vp.BBoxMu.RLock()
@@ -329,7 +349,7 @@ func (vp *Viewport2D) VpUploadVp() {
 
 // VpUploadRegion uploads node region of our viewport image
 func (vp *Viewport2D) VpUploadRegion(vpBBox, winBBox image.Rectangle) {
-	if !vp.This().(Viewport).VpIsVisible() {
+	if !vp.This().(Viewport).VpIsVisible() || vp.Win == nil { // offscreen: nothing to upload
 		return
 	}
 	vpin := vpBBox.Intersect(vp.Pixels.Bounds())
@@ -372,7 +392,12 @@ func (vp *Viewport2D) DrawIntoParent(parVp *Viewport2D) {
 	if Render2DTrace {
 		fmt.Printf("Render: vp DrawIntoParent: %v parVp: %v rect: %v sp: %v\n", vp.Path(), parVp.Path(), r, sp)
 	}
-	draw.Draw(parVp.Pixels, r, vp.Pixels, sp, draw.Over)
+	if !parVp.Render.Deferred() { // otherwise only recorded, below
+		draw.Draw(parVp.Pixels, r, vp.Pixels, sp, draw.Over)
+	}
+	if vp.Render.Vector != parVp.Render.Vector { // not recorded as vector graphics
+		parVp.Render.VectorImage(vp.Pixels, r, sp)
+	}
 }
 
 // ReRender2DNode re-renders a specific node, including uploading updated bits to
@@ -393,6 +418,15 @@ func (vp *Viewport2D) ReRender2DNode(gni Node2D) {
 	gn.BBoxMu.RLock()
 	wbb := gn.WinBBox
 	gn.BBoxMu.RUnlock()
+	if dr := vp.Render.Lists; dr != nil { // only upload what changed
+		for _, r := range dr.TakeDirty() {
+			r = r.Intersect(gn.VpBBox)
+			if !r.Empty() {
+				vp.This().(Viewport).VpUploadRegion(r, r.Add(wbb.Min.Sub(gn.VpBBox.Min)))
+			}
+		}
+		return
+	}
 	vp.This().(Viewport).VpUploadRegion(gn.VpBBox, wbb)
 }
 
@@ -543,6 +577,7 @@ func (vp *Viewport2D) ChildrenBBox2D() image.Rectangle {
 // RenderViewport2D is the render action for the viewport itself -- either
 // uploads image to window or draws into parent viewport
 func (vp *Viewport2D) RenderViewport2D() {
+	vp.RasterTiles()
 	if vp.IsPopup() { // popup has a parent that is the window
 		vp.SetCurWin()
 		if Render2DTrace {
@@ -554,7 +589,7 @@ func (vp *Viewport2D) RenderViewport2D() {
 			fmt.Printf("Render: %v at %v DrawIntoParent\n", vp.Path(), vp.VpBBox)
 		}
 		vp.DrawIntoParent(vp.Viewport)
-	} else { // we are the main vp
+	} else if !vp.HasFlag(int(VpFlagPrinting)) { // we are the main vp
 		if Render2DTrace {
 			fmt.Printf("Render: %v at %v VpUploadAll\n", vp.Path(), vp.VpBBox)
 		}
@@ -574,7 +609,15 @@ func (vp *Viewport2D) FullRender2DTree() {
 	if Render2DTrace {
 		fmt.Printf("Render: %v doing full render\n", vp.Path())
 	}
+	dr := vp.Render.Lists
+	seq := 0
+	if dr != nil {
+		seq = dr.Seq()
+	}
 	vp.WidgetBase.FullRender2DTree()
+	if dr != nil && !vp.Render.RecordingVector() { // lists of nodes that were not rendered are gone
+		dr.DeleteBefore(seq)
+	}
 	vp.ClearFlag(int(VpFlagDoingFullRender))
 }
 
@@ -601,6 +644,17 @@ func (vp *Viewport2D) PushBounds() bool {
 	rs := &vp.Render
 	bb := vp.Pixels.Bounds() // our bounds.. not vp.VpBBox)
 	rs.PushBounds(bb)
+	if vp.Viewport != nil && !vp.IsPopup() { // record into parent's vector graphics, if any
+		clip := vp.Geom.Bounds()
+		if vp.Par != nil {
+			pni, _ := KiToNode2D(vp.Par)
+			clip = clip.Intersect(pni.ChildrenBBox2D())
+		}
+		rs.VectorFrom(&vp.Viewport.Render, vp.Geom.Pos, clip)
+	}
+	if ts := Render2DTiles; ts > 0 && rs.Vector == nil && rs.Lists == nil && bb.Dx()*bb.Dy() >= 4*ts*ts {
+		rs.Vector = girl.NewTileVector(bb.Size())
+	}
 	if Render2DTrace {
 		fmt.Printf("Render: %v at %v\n", vp.Path(), bb)
 	}
@@ -609,9 +663,23 @@ func (vp *Viewport2D) PushBounds() bool {
 
 func (vp *Viewport2D) PopBounds() {
 	rs := &vp.Render
+	vp.RasterTiles()
 	rs.PopBounds()
 }
 
+// RasterTiles renders the painting that was recorded for tiled rendering
+// (see Render2DTiles) into our Pixels, concurrently in tiles -- does
+// nothing if it was already rendered or we are not tiled.
+func (vp *Viewport2D) RasterTiles() {
+	rs := &vp.Render
+	if !rs.Deferred() {
+		return
+	}
+	vc := rs.Vector
+	rs.Vector = nil
+	vc.RasterTiles(vp.Pixels, vp.Pixels.Bounds(), Render2DTiles)
+}
+
 func (vp *Viewport2D) Move2D(delta image.Point, parBBox image.Rectangle) {
 	if vp == nil {
 		return
@@ -963,3 +1031,92 @@ func (vp *Viewport2D) SavePNG(path string) error {
 func (vp *Viewport2D) EncodePNG(w io.Writer) error {
 	return png.Encode(w, vp.Pixels)
 }
+
+//////////////////////////////////////////////////////////////////////////////////
+//  Display lists
+
+// SetDisplayLists turns on or off the recording of the painting of each
+// widget into its own display list (see girl.DisplayLists), in
+// Render.Lists.  When on, ReRender2DNode only uploads the regions that
+// changed, and the lists can be used for hit testing (see HitTestLists)
+// or replayed.  Does a full render to record the lists when turned on.
+func (vp *Viewport2D) SetDisplayLists(on bool) {
+	if !on {
+		vp.Render.Lists = nil
+		return
+	}
+	if vp.Render.Lists == nil {
+		vp.Render.Lists = girl.NewDisplayLists()
+		vp.FullRender2DTree()
+		vp.Render.Lists.TakeDirty()
+	}
+}
+
+// HitTestLists returns the topmost widget that paints at given point in
+// the viewport, according to the display lists (see SetDisplayLists), or
+// nil if none does or display lists are off
+func (vp *Viewport2D) HitTestLists(pt image.Point) Node2D {
+	if vp.Render.Lists == nil {
+		return nil
+	}
+	key, _ := vp.Render.Lists.HitTest(pt)
+	if k, ok := key.(ki.Ki); ok {
+		ni, _ := KiToNode2D(k)
+		return ni
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+//  Vector output
+
+// RenderVector renders the viewport and all of its children as vector
+// graphics (see girl.Vector), laid out at given width in dots (0 = current
+// width), and the full height of the content (VpFlagPrinting), e.g.,
+// including all the rows of a TableView.  The viewport is restored to its
+// size and re-rendered after.
+func (vp *Viewport2D) RenderVector(width int) *girl.Vector {
+	osz := vp.Geom.Size
+	if width <= 0 {
+		width = osz.X
+	}
+	vp.SetFlag(int(VpFlagPrinting))
+	sz := image.Point{X: width, Y: osz.Y}
+	if vp.ChildByType(KiT_Layout, ki.Embeds, 0) != nil {
+		sz.Y = ints.MaxInt(vp.PrefSize(sz).Y, osz.Y)
+	}
+	vp.Resize(sz)
+	vc := girl.NewVector(sz)
+	vp.Render.Vector = vc
+	vp.FullRender2DTree()
+	vp.Render.Vector = nil
+	vp.ClearFlag(int(VpFlagPrinting))
+	vp.Resize(osz)
+	vp.FullRender2DTree()
+	return vc
+}
+
+// SavePDF renders the viewport as vector graphics (see RenderVector) and
+// saves it as a PDF file, scaled to fit the width of the pages and broken
+// into as many pages as needed -- nil opts uses girl.DefaultPDFOptions.
+func (vp *Viewport2D) SavePDF(path string, opts *girl.PDFOptions) error {
+	return vp.RenderVector(0).SavePDF(path, opts)
+}
+
+// EncodePDF renders the viewport as vector graphics (see RenderVector) and
+// writes it as PDF to the provided io.Writer (see SavePDF).
+func (vp *Viewport2D) EncodePDF(w io.Writer, opts *girl.PDFOptions) error {
+	return vp.RenderVector(0).WritePDF(w, opts)
+}
+
+// SaveSVG renders the viewport as vector graphics (see RenderVector) and
+// saves it as an SVG file.
+func (vp *Viewport2D) SaveSVG(path string) error {
+	return vp.RenderVector(0).SaveSVG(path)
+}
+
+// EncodeSVG renders the viewport as vector graphics (see RenderVector) and
+// writes it as SVG to the provided io.Writer.
+func (vp *Viewport2D) EncodeSVG(w io.Writer) error {
+	return vp.RenderVector(0).WriteSVG(w)
+}
@@ -4,7 +4,10 @@
 
 package gi
 
-import "github.com/goki/ki/ki"
+import (
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ki"
+)
 
 // ViewIFace is an interface into the View GUI types in giv subpackage,
 // allowing it to be a sub-package with just this narrow set of dependencies
@@ -40,6 +43,12 @@ type ViewIFace interface {
 	// SetHiStyleDefault sets the current default histyle.StyleDefault
 	SetHiStyleDefault(hsty HiStyleName)
 
+	// SetHiStyleFromColors generates a highlighting style of given name
+	// from given colors and primary and secondary accent colors, and adds
+	// it to the custom highlighting styles, which are saved in the prefs
+	// if it changed -- see ColorPrefs.GenerateTheme
+	SetHiStyleFromColors(name HiStyleName, colors *ColorPrefs, primary, secondary gist.Color) error
+
 	// HiStyleInit initializes the histyle package -- called during overall gi init.
 	HiStyleInit()
 
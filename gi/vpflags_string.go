@@ -24,12 +24,14 @@ func _() {
 	_ = x[VpFlagNeedsFullRender-32]
 	_ = x[VpFlagDoingFullRender-33]
 	_ = x[VpFlagPrefSizing-34]
-	_ = x[VpFlagsN-35]
+	_ = x[VpFlagOffscreen-35]
+	_ = x[VpFlagPrinting-36]
+	_ = x[VpFlagsN-37]
 }
 
-const _VpFlags_name = "VpFlagPopupVpFlagMenuVpFlagCompleterVpFlagCorrectorVpFlagTooltipVpFlagPopupDestroyAllVpFlagSVGVpFlagUpdatingNodeVpFlagNeedsFullRenderVpFlagDoingFullRenderVpFlagPrefSizingVpFlagsN"
+const _VpFlags_name = "VpFlagPopupVpFlagMenuVpFlagCompleterVpFlagCorrectorVpFlagTooltipVpFlagPopupDestroyAllVpFlagSVGVpFlagUpdatingNodeVpFlagNeedsFullRenderVpFlagDoingFullRenderVpFlagPrefSizingVpFlagOffscreenVpFlagPrintingVpFlagsN"
 
-var _VpFlags_index = [...]uint8{0, 11, 21, 36, 51, 64, 85, 94, 112, 133, 154, 170, 178}
+var _VpFlags_index = [...]uint8{0, 11, 21, 36, 51, 64, 85, 94, 112, 133, 154, 170, 185, 199, 207}
 
 func (i VpFlags) String() string {
 	i -= 24
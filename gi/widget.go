@@ -83,7 +83,7 @@ func (wb *WidgetBase) StyleRUnlock() {
 }
 
 // BoxSpace returns the style BoxSpace value under read lock
-func (wb *WidgetBase) BoxSpace() float32 {
+func (wb *WidgetBase) BoxSpace() gist.SideFloats {
 	wb.StyMu.RLock()
 	bs := wb.Sty.BoxSpace()
 	wb.StyMu.RUnlock()
@@ -297,19 +297,27 @@ func ApplyCSS(node Node2D, vp *Viewport2D, st *gist.Style, css ki.Props, key, se
 	return true
 }
 
-// StyleCSS applies css style properties to given Widget node, parsing out
-// type, .class, and #name selectors, along with optional sub-selector
-// (:hover, :active etc)
+// StyleCSS applies css style properties to given Widget node, from all the
+// rules in css whose selectors match the node (see MatchCSS), for optional
+// state style selector (:hover, :active etc), along with the
+// Preferences.CustomStyles, which come first unless CustomStylesOverride
 func StyleCSS(node Node2D, vp *Viewport2D, st *gist.Style, css ki.Props, selector string) {
-	tyn := strings.ToLower(ki.Type(node).Name()) // type is most general, first
-	ApplyCSS(node, vp, st, css, tyn, selector)
-	classes := strings.Split(strings.ToLower(node.AsNode2D().Class), " ")
-	for _, cl := range classes {
-		cln := "." + strings.TrimSpace(cl)
-		ApplyCSS(node, vp, st, css, cln, selector)
+	var pms []ki.Props
+	if Prefs.CustomStyles != nil && !Prefs.CustomStylesOverride {
+		pms = append(pms, MatchCSS(node, Prefs.CustomStyles, selector)...)
+	}
+	pms = append(pms, MatchCSS(node, css, selector)...)
+	if Prefs.CustomStyles != nil && Prefs.CustomStylesOverride {
+		pms = append(pms, MatchCSS(node, Prefs.CustomStyles, selector)...)
+	}
+	if len(pms) == 0 {
+		return
+	}
+	parSty := node.AsNode2D().ParentStyle()
+	for _, pmap := range pms {
+		st.SetStyleProps(parSty, pmap, vp)
 	}
-	idnm := "#" + strings.ToLower(node.Name()) // then name
-	ApplyCSS(node, vp, st, css, idnm, selector)
+	node.AsNode2D().ParentStyleRUnlock()
 }
 
 func (wb *WidgetBase) Style2D() {
@@ -326,6 +334,11 @@ func (wb *WidgetBase) Style2D() {
 	wb.LayState.SetFromStyle(&wb.Sty.Layout) // also does reset
 }
 
+// NoWinDPI is the logical DPI used for styling widgets in viewports that
+// are not in a window, e.g., when rendering offscreen into an image --
+// defaults to the standard 96 DPI.
+var NoWinDPI = float32(units.PxPerInch)
+
 // SetUnitContext sets the unit context based on size of viewport and parent
 // element (from bbox) and then cache everything out in terms of raw pixel
 // dots for rendering -- call at start of render
@@ -333,6 +346,8 @@ func SetUnitContext(st *gist.Style, vp *Viewport2D, el mat32.Vec2) {
 	if vp != nil {
 		if vp.Win != nil {
 			st.UnContext.DPI = vp.Win.LogicalDPI()
+		} else {
+			st.UnContext.DPI = NoWinDPI
 		}
 		if vp.Render.Image != nil {
 			sz := vp.Geom.Size // Render.Image.Bounds().Size()
@@ -425,11 +440,11 @@ func (wb *WidgetBase) Layout2D(parBBox image.Rectangle, iter int) bool {
 // margin and padding to children -- call in ChildrenBBox2D for most widgets
 func (wb *WidgetBase) ChildrenBBox2DWidget() image.Rectangle {
 	nb := wb.VpBBox
-	spc := int(wb.BoxSpace())
-	nb.Min.X += spc
-	nb.Min.Y += spc
-	nb.Max.X -= spc
-	nb.Max.Y -= spc
+	spc := wb.BoxSpace()
+	nb.Min.X += int(spc.Left)
+	nb.Min.Y += int(spc.Top)
+	nb.Max.X -= int(spc.Right)
+	nb.Max.Y -= int(spc.Bottom)
 	return nb
 }
 
@@ -474,6 +489,7 @@ func (wb *WidgetBase) PushBounds() bool {
 	mvp := wb.ViewportSafe()
 	rs := &mvp.Render
 	rs.PushBounds(wb.VpBBox)
+	rs.PushList(wb.This())
 	wb.ConnectToViewport()
 	if Render2DTrace {
 		fmt.Printf("Render: %v at %v\n", wb.Path(), wb.VpBBox)
@@ -493,6 +509,7 @@ func (wb *WidgetBase) PopBounds() {
 		return
 	}
 	rs := &mvp.Render
+	rs.PopList()
 	rs.PopBounds()
 }
 
@@ -758,60 +775,152 @@ func (wb *WidgetBase) RenderUnlock(rs *girl.State) {
 }
 
 // RenderBoxImpl implements the standard box model rendering -- assumes all
-// paint params have already been set
-func (wb *WidgetBase) RenderBoxImpl(pos mat32.Vec2, sz mat32.Vec2, rad float32) {
+// paint params have already been set.  rad is the radius of each corner,
+// in the order: top-left, top-right, bottom-right, bottom-left.
+func (wb *WidgetBase) RenderBoxImpl(pos mat32.Vec2, sz mat32.Vec2, rad gist.SideFloats) {
 	rs := &wb.Viewport.Render
 	pc := &rs.Paint
-	if rad == 0.0 {
+	if rad.IsZero() {
 		pc.DrawRectangle(rs, pos.X, pos.Y, sz.X, sz.Y)
 	} else {
-		pc.DrawRoundedRectangle(rs, pos.X, pos.Y, sz.X, sz.Y, rad)
+		pc.DrawRoundedRectangleSides(rs, pos.X, pos.Y, sz.X, sz.Y, rad)
 	}
 	pc.FillStrokeClear(rs)
 }
 
-// RenderStdBox draws standard box using given style.
+// RenderBorder draws given border for the box with given position and size,
+// which are the outer edges of the border.  If all sides of the border are
+// the same, it is drawn as one stroked box, and otherwise each side is drawn
+// separately, with its own width, color and style.
+// girl.State must already be locked at this point (RenderLock)
+func (wb *WidgetBase) RenderBorder(pos mat32.Vec2, sz mat32.Vec2, bs *gist.Border) {
+	rs := &wb.Viewport.Render
+	pc := &rs.Paint
+	wd := bs.WidthDots()
+	rad := bs.Radius.Dots()
+	pc.FillStyle.SetColor(nil)
+	if bs.IsUniform() {
+		pc.StrokeStyle.SetColor(&bs.Color.Top)
+		pc.StrokeStyle.Width = bs.Width.Top
+		pc.StrokeStyle.Width.Dots = wd.Top
+		pc.StrokeStyle.Dashes = BorderDashes(bs.Style.Top, wd.Top)
+		wb.RenderBoxImpl(pos.AddScalar(0.5*wd.Top), sz.SubScalar(wd.Top), rad)
+		pc.StrokeStyle.Dashes = nil
+		return
+	}
+	// each side is stroked along the middle of its width, from the middle
+	// of the corner before it to the middle of the corner after it
+	x0, y0 := pos.X+0.5*wd.Left, pos.Y+0.5*wd.Top
+	x1, y1 := pos.X+sz.X-0.5*wd.Right, pos.Y+sz.Y-0.5*wd.Bottom
+	mr := 0.5 * mat32.Min(x1-x0, y1-y0)
+	tl, tr, br, bl := mat32.Min(rad.Top, mr), mat32.Min(rad.Right, mr), mat32.Min(rad.Bottom, mr), mat32.Min(rad.Left, mr)
+	for side := gist.BoxTop; side < gist.BoxN; side++ {
+		w := wd.Side(side)
+		if w <= 0 {
+			continue
+		}
+		pc.StrokeStyle.SetColor(bs.Color.Side(side))
+		pc.StrokeStyle.Width = *bs.Width.Side(side)
+		pc.StrokeStyle.Width.Dots = w
+		pc.StrokeStyle.Dashes = BorderDashes(*bs.Style.Side(side), w)
+		pc.NewSubPath(rs)
+		switch side {
+		case gist.BoxTop:
+			wb.renderBorderCorner(x0+tl, y0+tl, tl, 225, pos.X, y0)
+			wb.renderBorderCorner(x1-tr, y0+tr, tr, 270, pos.X+sz.X, y0)
+		case gist.BoxRight:
+			wb.renderBorderCorner(x1-tr, y0+tr, tr, 315, x1, pos.Y)
+			wb.renderBorderCorner(x1-br, y1-br, br, 0, x1, pos.Y+sz.Y)
+		case gist.BoxBottom:
+			wb.renderBorderCorner(x1-br, y1-br, br, 45, pos.X+sz.X, y1)
+			wb.renderBorderCorner(x0+bl, y1-bl, bl, 90, pos.X, y1)
+		case gist.BoxLeft:
+			wb.renderBorderCorner(x0+bl, y1-bl, bl, 135, x0, pos.Y+sz.Y)
+			wb.renderBorderCorner(x0+tl, y0+tl, tl, 180, x0, pos.Y)
+		}
+		pc.Stroke(rs)
+	}
+	pc.StrokeStyle.Dashes = nil
+}
+
+// renderBorderCorner adds half of a rounded corner to the border path,
+// centered at cx, cy with radius r, starting at given angle in degrees,
+// or a line to the square corner at x, y if the radius is 0
+func (wb *WidgetBase) renderBorderCorner(cx, cy, r, ang float32, x, y float32) {
+	rs := &wb.Viewport.Render
+	pc := &rs.Paint
+	if r <= 0 {
+		pc.LineTo(rs, x, y)
+		return
+	}
+	a := mat32.DegToRad(ang)
+	pc.LineTo(rs, cx+r*mat32.Cos(a), cy+r*mat32.Sin(a))
+	pc.DrawArc(rs, cx, cy, r, a, a+mat32.DegToRad(45))
+}
+
+// BorderDashes returns the stroke dash pattern for given border style
+// and width, which is nil for solid borders
+func BorderDashes(st gist.BorderStyles, wd float32) []float64 {
+	switch st {
+	case gist.BorderDotted:
+		return []float64{float64(wd), float64(wd)}
+	case gist.BorderDashed:
+		return []float64{3 * float64(wd), 3 * float64(wd)}
+	}
+	return nil
+}
+
+// RenderStdBox draws standard box using given style, with any style
+// transitions in progress applied if it is the current style (see TransStyle).
 // girl.State and Style must already be locked at this point (RenderLock)
 func (wb *WidgetBase) RenderStdBox(st *gist.Style) {
 	wb.StyMu.RLock()
 	defer wb.StyMu.RUnlock()
+	st = wb.TransStyle(st)
 
 	rs := &wb.Viewport.Render
 	pc := &rs.Paint
 
-	pos := wb.LayState.Alloc.Pos.AddScalar(st.Layout.Margin.Dots)
-	sz := wb.LayState.Alloc.Size.AddScalar(-2.0 * st.Layout.Margin.Dots)
-	rad := st.Border.Radius.Dots
-
-	// first do any shadow
-	if st.BoxShadow.HasShadow() {
-		spos := pos.Add(mat32.Vec2{st.BoxShadow.HOffset.Dots, st.BoxShadow.VOffset.Dots})
-		pc.StrokeStyle.SetColor(nil)
-		pc.FillStyle.Color.SetShadowGradient(st.BoxShadow.Color, "")
-		// todo: this is not rendering a transparent gradient
-		// pc.FillStyle.Opacity = .5
-		wb.RenderBoxImpl(spos, sz, rad)
-		// pc.FillStyle.Opacity = 1.0
-	}
+	mg := st.Layout.Margin.Dots()
+	pos := wb.LayState.Alloc.Pos.Add(mg.Pos())
+	sz := wb.LayState.Alloc.Size.Sub(mg.Size())
+	rad := st.Border.Radius.Dots()
+
+	// first do any outset shadows
+	wb.RenderBoxShadows(pos, sz, rad, st, false)
 	// then draw the box over top of that -- note: won't work well for
 	// transparent! need to set clipping to box first..
 	if !st.Font.BgColor.IsNil() {
-		if rad == 0 {
+		if rad.IsZero() {
 			pc.FillBox(rs, pos, sz, &st.Font.BgColor)
 		} else {
 			pc.FillStyle.SetColorSpec(&st.Font.BgColor)
-			pc.DrawRoundedRectangle(rs, pos.X, pos.Y, sz.X, sz.Y, rad)
+			pc.DrawRoundedRectangleSides(rs, pos.X, pos.Y, sz.X, sz.Y, rad)
 			pc.Fill(rs)
 		}
 	}
+	wb.RenderBoxShadows(pos, sz, rad, st, true)
 
-	pc.StrokeStyle.SetColor(&st.Border.Color)
-	pc.StrokeStyle.Width = st.Border.Width
-	// pc.FillStyle.SetColor(&st.Font.BgColor)
-	pos = pos.AddScalar(0.5 * st.Border.Width.Dots)
-	sz = sz.SubScalar(st.Border.Width.Dots)
-	pc.FillStyle.SetColor(nil)
-	wb.RenderBoxImpl(pos, sz, st.Border.Radius.Dots)
+	wb.RenderBorder(pos, sz, &st.Border)
+}
+
+// RenderBoxShadows draws the outset (or inset) box shadows of given style
+// for the box with given position, size and corner radii, from the bottom
+// shadow to the top one.  Outset shadows are drawn before the background,
+// and inset shadows after it.
+// girl.State must already be locked at this point (RenderLock)
+func (wb *WidgetBase) RenderBoxShadows(pos mat32.Vec2, sz mat32.Vec2, rad gist.SideFloats, st *gist.Style, inset bool) {
+	shs := st.BoxShadows()
+	if len(shs) == 0 {
+		return
+	}
+	rs := &wb.Viewport.Render
+	pc := &rs.Paint
+	for i := len(shs) - 1; i >= 0; i-- {
+		if shs[i].Inset == inset {
+			pc.DrawBoxShadow(rs, pos, sz, rad, &shs[i])
+		}
+	}
 }
 
 // set our LayState.Alloc.Size from constraints
@@ -823,22 +932,22 @@ func (wb *WidgetBase) Size2DFromWH(w, h float32) {
 	if st.Layout.Height.Dots > 0 {
 		h = mat32.Max(st.Layout.Height.Dots, h)
 	}
-	spc := st.BoxSpace()
-	w += 2.0 * spc
-	h += 2.0 * spc
+	spc := st.BoxSpace().Size()
+	w += spc.X
+	h += spc.Y
 	wb.LayState.Alloc.Size = mat32.Vec2{w, h}
 }
 
 // Size2DAddSpace adds space to existing AllocSize
 func (wb *WidgetBase) Size2DAddSpace() {
 	spc := wb.BoxSpace()
-	wb.LayState.Alloc.Size.SetAddScalar(2 * spc)
+	wb.LayState.Alloc.Size.SetAdd(spc.Size())
 }
 
-// Size2DSubSpace returns AllocSize minus 2 * BoxSpace -- the amount avail to the internal elements
+// Size2DSubSpace returns AllocSize minus the BoxSpace on all sides -- the amount avail to the internal elements
 func (wb *WidgetBase) Size2DSubSpace() mat32.Vec2 {
 	spc := wb.BoxSpace()
-	return wb.LayState.Alloc.Size.SubScalar(2 * spc)
+	return wb.LayState.Alloc.Size.Sub(spc.Size())
 }
 
 ///////////////////////////////////////////////////////////////////
@@ -902,8 +1011,8 @@ func (wb *PartsWidgetBase) ComputeBBox2D(parBBox image.Rectangle, delta image.Po
 
 func (wb *PartsWidgetBase) Layout2DParts(parBBox image.Rectangle, iter int) {
 	spc := wb.BoxSpace()
-	wb.Parts.LayState.Alloc.Pos = wb.LayState.Alloc.Pos.AddScalar(spc)
-	wb.Parts.LayState.Alloc.Size = wb.LayState.Alloc.Size.AddScalar(-2.0 * spc)
+	wb.Parts.LayState.Alloc.Pos = wb.LayState.Alloc.Pos.Add(spc.Pos())
+	wb.Parts.LayState.Alloc.Size = wb.LayState.Alloc.Size.Sub(spc.Size())
 	wb.Parts.Layout2D(parBBox, iter)
 }
 
@@ -1012,6 +1121,16 @@ func (wb *PartsWidgetBase) PartsNeedUpdateIconLabel(icnm string, txt string) boo
 	return false
 }
 
+// PartsLabelTruncated returns true if the text of the label part was
+// elided in the last layout (see gist.Text Overflow and MaxLines), so the
+// full text should be shown in a tooltip
+func (wb *PartsWidgetBase) PartsLabelTruncated() bool {
+	if lblk := wb.Parts.ChildByName("label", 2); lblk != nil {
+		return lblk.(*Label).Render.Truncated
+	}
+	return false
+}
+
 // SetFullReRenderIconLabel sets the icon and label to be re-rendered, needed
 // when styles change
 func (wb *PartsWidgetBase) SetFullReRenderIconLabel() {
@@ -5,6 +5,7 @@
 package gi
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/draw"
@@ -15,7 +16,9 @@ import (
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goki/gi/colormap"
@@ -113,7 +116,7 @@ var (
 	WindowOpenTimer time.Time
 )
 
-// These constants define use of Texture images in vdraw.Drawer
+// These constants define use of Texture images in oswin.Drawer
 // for updating the window efficiently.  They are allocated in
 // sets of 16:
 // Set 0: 0 = main viewport, DirectUploads, Popups
@@ -160,8 +163,9 @@ const (
 // what you're doing (and it might change over time too..)
 //
 // Rendering logic:
-//   - vdraw.Drawer manages all rendering to the window surface, provided via
-//     the OSWin window, using vulkan stored images (16 max)
+//   - oswin.Drawer manages all rendering to the window surface, provided via
+//     the OSWin window, using vulkan stored images (16 max) or in-memory
+//     images for the offscreen driver
 //   - Order is: Base Viewport2D (image 0), then direct uploads, popups, and sprites.
 //   - DirectUps (e.g., gi3d.Scene) directly upload their own texture to a Draw image
 //     (note: cannot upload directly to window as this prevents popups and overlays)
@@ -170,28 +174,33 @@ const (
 //     unlimited number packed into a few descriptors for standard sizes.
 type Window struct {
 	NodeBase
-	Title             string       `desc:"displayed name of window, for window manager etc -- window object name is the internal handle and is used for tracking property info etc"`
-	Data              interface{}  `json:"-" xml:"-" view:"-" desc:"the main data element represented by this window -- used for Recycle* methods for windows that represent a given data element -- prevents redundant windows"`
-	OSWin             oswin.Window `json:"-" xml:"-" desc:"OS-specific window interface -- handles all the os-specific functions, including delivering events etc"`
-	EventMgr          EventMgr     `json:"-" xml:"-" desc:"event manager that handles dispersing events to nodes"`
-	Viewport          *Viewport2D  `json:"-" xml:"-" desc:"convenience pointer to window's master viewport child that handles the rendering"`
-	MasterVLay        *Layout      `json:"-" xml:"-" desc:"main vertical layout under Viewport -- first element is MainMenu (always -- leave empty to not render)"`
-	MainMenu          *MenuBar     `json:"-" xml:"-" desc:"main menu -- is first element of MasterVLay always -- leave empty to not render.  On MacOS, this drives screen main menu"`
-	Sprites           Sprites      `json:"-" xml:"-" desc:"sprites are named images that are rendered last overlaying everything else."`
-	SpriteDragging    string       `json:"-" xml:"-" desc:"name of sprite that is being dragged -- sprite event function is responsible for setting this."`
-	UpMu              sync.Mutex   `json:"-" xml:"-" view:"-" desc:"mutex that protects all updating / uploading of Textures"`
-	Shortcuts         Shortcuts    `json:"-" xml:"-" desc:"currently active shortcuts for this window (shortcuts are always window-wide -- use widget key event processing for more local key functions)"`
-	Popup             ki.Ki        `json:"-" xml:"-" desc:"Current popup viewport that gets all events"`
-	PopupStack        []ki.Ki      `json:"-" xml:"-" desc:"stack of popups"`
-	NextPopup         ki.Ki        `json:"-" xml:"-" desc:"this popup will be pushed at the end of the current event cycle -- use SetNextPopup"`
-	PopupFocus        ki.Ki        `json:"-" xml:"-" desc:"node to focus on when next popup is activated -- use SetNextPopup"`
-	DelPopup          ki.Ki        `json:"-" xml:"-" desc:"this popup will be popped at the end of the current event cycle -- use SetDelPopup"`
-	PopMu             sync.RWMutex `json:"-" xml:"-" view:"-" desc:"read-write mutex that protects popup updating and access"`
+	Title             string         `desc:"displayed name of window, for window manager etc -- window object name is the internal handle and is used for tracking property info etc"`
+	Data              interface{}    `json:"-" xml:"-" view:"-" desc:"the main data element represented by this window -- used for Recycle* methods for windows that represent a given data element -- prevents redundant windows"`
+	OSWin             oswin.Window   `json:"-" xml:"-" desc:"OS-specific window interface -- handles all the os-specific functions, including delivering events etc"`
+	EventMgr          EventMgr       `json:"-" xml:"-" desc:"event manager that handles dispersing events to nodes"`
+	Viewport          *Viewport2D    `json:"-" xml:"-" desc:"convenience pointer to window's master viewport child that handles the rendering"`
+	MasterVLay        *Layout        `json:"-" xml:"-" desc:"main vertical layout under Viewport -- first element is MainMenu (always -- leave empty to not render)"`
+	MainMenu          *MenuBar       `json:"-" xml:"-" desc:"main menu -- is first element of MasterVLay always -- leave empty to not render.  On MacOS, this drives screen main menu"`
+	Sprites           Sprites        `json:"-" xml:"-" desc:"sprites are named images that are rendered last overlaying everything else."`
+	SpriteDragging    string         `json:"-" xml:"-" desc:"name of sprite that is being dragged -- sprite event function is responsible for setting this."`
+	UpMu              sync.Mutex     `json:"-" xml:"-" view:"-" desc:"mutex that protects all updating / uploading of Textures"`
+	Shortcuts         Shortcuts      `json:"-" xml:"-" desc:"currently active shortcuts for this window (shortcuts are always window-wide -- use widget key event processing for more local key functions)"`
+	Popup             ki.Ki          `json:"-" xml:"-" desc:"Current popup viewport that gets all events"`
+	PopupStack        []ki.Ki        `json:"-" xml:"-" desc:"stack of popups"`
+	NextPopup         ki.Ki          `json:"-" xml:"-" desc:"this popup will be pushed at the end of the current event cycle -- use SetNextPopup"`
+	PopupFocus        ki.Ki          `json:"-" xml:"-" desc:"node to focus on when next popup is activated -- use SetNextPopup"`
+	DelPopup          ki.Ki          `json:"-" xml:"-" desc:"this popup will be popped at the end of the current event cycle -- use SetDelPopup"`
+	PopMu             sync.RWMutex   `json:"-" xml:"-" view:"-" desc:"read-write mutex that protects popup updating and access"`
+	EventRec          *EventRecorder `json:"-" xml:"-" view:"-" desc:"if non-nil, records the events processed by this window -- see StartEventRecording"`
+	Anims             Animator       `json:"-" xml:"-" view:"-" desc:"runs the animations in this window -- see Animate"`
 	lastWinMenuUpdate time.Time
 	// below are internal vars used during the event loop
 	delPop        bool
 	skippedResize *window.Event
 	lastEt        oswin.EventType
+	loopGoID      uint64        // goroutine id (atomic) of the running EventLoop, for SendSyncEvent
+	closedCh      chan struct{} // closed once the window is closed or its event loop exits
+	closedOnce    sync.Once
 	DirDraws      WindowDrawers       `desc:"dir draws are direct upload regions -- direct uploaders upload their images directly to an image here"`
 	PopDraws      WindowDrawers       // popup regions
 	UpdtRegs      WindowUpdates       // misc vp update regions
@@ -339,6 +348,8 @@ func NewWindow(name, title string, opts *oswin.NewWindowOptions) *Window {
 	win := &Window{}
 	win.InitName(win, name)
 	win.EventMgr.Master = win
+	win.Anims.Win = win
+	win.closedCh = make(chan struct{})
 	win.Title = title
 	win.SetOnlySelfUpdate() // has its own PublishImage update logic
 	var err error
@@ -831,6 +842,17 @@ func (w *Window) Closed() {
 	// these are managed by the window itself
 	w.Sprites.Reset()
 	w.UpMu.Unlock()
+	w.signalClosed()
+}
+
+// signalClosed closes closedCh, releasing anyone waiting in SendSyncEvent
+// for an event that will now never be processed.
+func (w *Window) signalClosed() {
+	w.closedOnce.Do(func() {
+		if w.closedCh != nil {
+			close(w.closedCh)
+		}
+	})
 }
 
 // IsClosed reports if the window has been closed
@@ -945,6 +967,70 @@ func (w *Window) SendCustomEvent(data interface{}) {
 	oswin.SendCustomEvent(w.OSWin, data)
 }
 
+// winSyncEvent is sent as the data of a CustomEvent by SendSyncEvent,
+// and is handled directly by ProcessEvent, which calls fun and then
+// closes done.
+type winSyncEvent struct {
+	fun  func(w *Window)
+	done chan struct{}
+}
+
+// SendSyncEvent sends an event through the event loop and waits until it
+// has been processed, at which point all events sent prior to it have also
+// been fully processed.  If fun is non-nil, it is called from the event
+// loop at that point.  This is used for replaying and synthesizing events
+// (e.g., EventPlayer).  If called from the event loop goroutine itself,
+// fun is just called directly, as waiting would deadlock.  It returns
+// without waiting if the window is closed before the event is processed.
+func (w *Window) SendSyncEvent(fun func(w *Window)) {
+	if w.IsClosed() {
+		return
+	}
+	if gid := atomic.LoadUint64(&w.loopGoID); gid != 0 && gid == curGoID() {
+		if fun != nil {
+			fun(w)
+		}
+		return
+	}
+	se := &winSyncEvent{fun: fun, done: make(chan struct{})}
+	oswin.SendCustomEvent(w.OSWin, se)
+	select {
+	case <-se.done:
+	case <-w.closedCh:
+	}
+}
+
+// syncEvent handles winSyncEvent events from ProcessEvent,
+// returning true if it was one.
+func (w *Window) syncEvent(evi oswin.Event) bool {
+	ce, ok := evi.(*oswin.CustomEvent)
+	if !ok {
+		return false
+	}
+	se, ok := ce.Data.(*winSyncEvent)
+	if !ok {
+		return false
+	}
+	defer close(se.done) // even if fun panics
+	if se.fun != nil {
+		se.fun(w)
+	}
+	return true
+}
+
+// curGoID returns the id of the calling goroutine, parsed from the
+// "goroutine N [...]" header of its stack trace.
+func curGoID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fs := bytes.Fields(buf[:n])
+	if len(fs) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fs[1]), 10, 64)
+	return id
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //                   Rendering
 
@@ -1072,7 +1158,7 @@ func (w *Window) UploadVp(vp *Viewport2D, offset image.Point) {
 		// pr := prof.Start("win.UploadVp")
 		gii, _ := KiToNode2D(vp.This())
 		if gii != nil {
-			idx, _ = w.PopDraws.Add(gii, winBBox)
+			idx, _ = w.PopDraws.Add(gii, vp.popupBBox(winBBox))
 			drw.SetGoImage(idx, 0, vp.Pixels, vgpu.NoFlipY)
 		}
 	}
@@ -1153,7 +1239,7 @@ func (w *Window) ResetUpdateRegionsImpl() {
 			if gii != nil {
 				vp := gii.AsViewport2D()
 				r := vp.Geom.Bounds()
-				idx, _ := w.PopDraws.Add(gii, vp.WinBBox)
+				idx, _ := w.PopDraws.Add(gii, vp.popupBBox(vp.WinBBox))
 				drw.SetGoImage(idx, 0, vp.Pixels, vgpu.NoFlipY)
 				if Render2DTrace {
 					fmt.Printf("Win: %v uploading popup stack Vp %v, win pos: %v, vp bounds: %v  idx: %d\n", w.Path(), vp.Path(), r.Min, vp.Pixels.Bounds(), idx)
@@ -1166,7 +1252,7 @@ func (w *Window) ResetUpdateRegionsImpl() {
 		if gii != nil {
 			vp := gii.AsViewport2D()
 			r := vp.Geom.Bounds()
-			idx, _ := w.PopDraws.Add(gii, vp.WinBBox)
+			idx, _ := w.PopDraws.Add(gii, vp.popupBBox(vp.WinBBox))
 			drw.SetGoImage(idx, 0, vp.Pixels, vgpu.NoFlipY)
 			if Render2DTrace || WinEventTrace {
 				fmt.Printf("Win: %v uploading top popup Vp %v, win pos: %v, vp bounds: %v  idx: %d\n", w.Path(), vp.Path(), r.Min, vp.Pixels.Bounds(), idx)
@@ -1216,8 +1302,7 @@ func (w *Window) Publish() {
 	}
 
 	drw := w.OSWin.Drawer()
-	vpv := drw.GetImageVal(0).Texture
-	if !vpv.IsActive() {
+	if !drw.IsImageActive(0) {
 		if w.Viewport.Pixels == nil {
 			if Update2DTrace {
 				fmt.Printf("Win %s didn't have active image, viewport is nil\n", w.Nm)
@@ -1235,7 +1320,7 @@ func (w *Window) Publish() {
 	drw.SyncImages()
 	drw.StartDraw(0)
 	drw.UseTextureSet(0)
-	drw.Scale(0, 0, drw.Surf.Format.Bounds(), image.ZR, draw.Src, vgpu.NoFlipY)
+	drw.Scale(0, 0, drw.DestBounds(), image.ZR, draw.Src, vgpu.NoFlipY)
 	if len(w.UpdtRegs.BeforeDir) > 0 {
 		drw.UseTextureSet(1)
 		w.UpdtRegs.DrawImages(drw, true) // before direct
@@ -1434,7 +1519,7 @@ func (w *Window) ConfigSprites() {
 	for gpi, ga := range sa.GpAllocs {
 		gsz := sa.GpSizes[gpi]
 		imgidx := SpriteStart + gpi
-		drw.ConfigImage(imgidx, vgpu.NewImageFormat(gsz.X, gsz.Y, len(ga)))
+		drw.ConfigImageDefaultFormat(imgidx, gsz.X, gsz.Y, len(ga))
 		for ii, spi := range ga {
 			if err := w.Sprites.Names.IdxIsValid(spi); err != nil {
 				fmt.Println(err)
@@ -1545,6 +1630,7 @@ func (w *Window) PollEvents() {
 // events for the window and dispatches them to receiving nodes, and manages
 // other state etc (popups, etc).
 func (w *Window) EventLoop() {
+	atomic.StoreUint64(&w.loopGoID, curGoID())
 	for {
 		if w.HasFlag(int(WinFlagStopEventLoop)) {
 			w.ClearFlag(int(WinFlagStopEventLoop))
@@ -1557,6 +1643,8 @@ func (w *Window) EventLoop() {
 		}
 		w.ProcessEvent(evi)
 	}
+	atomic.StoreUint64(&w.loopGoID, 0)
+	w.signalClosed() // no more events will be processed
 	if WinEventTrace {
 		fmt.Printf("Win: %v out of event loop\n", w.Nm)
 	}
@@ -1575,6 +1663,14 @@ func (w *Window) ProcessEvent(evi oswin.Event) {
 		fmt.Printf("Win: %v got out-of-range event: %v\n", w.Nm, et)
 		return
 	}
+	if et == oswin.CustomEventType && (w.syncEvent(evi) || w.animFrameEvent(evi)) {
+		return
+	}
+	if rec := w.EventRec; rec != nil {
+		if rec.RecordEvent(w, evi) {
+			defer rec.EventProcessed(w, evi)
+		}
+	}
 
 	{ // popup delete check
 		w.PopMu.RLock()
@@ -2141,11 +2237,14 @@ func (w *Window) PushPopup(pop ki.Ki) {
 	ki.SetParent(pop, w.This()) // popup has parent as window -- draws directly in to assoc vp
 	w.PopupStack = append(w.PopupStack, w.Popup)
 	w.Popup = pop
-	_, ni := KiToNode2D(pop)
+	nii, ni := KiToNode2D(pop)
 	pfoc := w.PopupFocus
 	w.PopupFocus = nil
 	w.PopMu.Unlock()
 	if ni != nil {
+		if vp := nii.AsViewport2D(); vp != nil {
+			w.AnimatePopupOpen(vp)
+		}
 		ni.FullRender2DTree() // this locks viewport -- do it after unlocking popup
 	}
 	if pfoc != nil {
@@ -12,7 +12,6 @@ import (
 	"github.com/goki/gi/oswin"
 	"github.com/goki/ki/kit"
 	"github.com/goki/kigen/ordmap"
-	"github.com/goki/vgpu/vdraw"
 	"github.com/goki/vgpu/vgpu"
 	"golang.org/x/exp/slices"
 	"golang.org/x/image/draw"
@@ -259,9 +258,9 @@ func (wu *WindowUpdates) Idx(idx int) int {
 }
 
 // DrawImages iterates over regions and calls Copy on given
-// vdraw.Drawer for each region.  beforeDir calls items on the
+// oswin.Drawer for each region.  beforeDir calls items on the
 // BeforeDir list, else regular Order.
-func (wu *WindowUpdates) DrawImages(drw *vdraw.Drawer, beforeDir bool) {
+func (wu *WindowUpdates) DrawImages(drw oswin.Drawer, beforeDir bool) {
 	if wu.Updates == nil {
 		return
 	}
@@ -346,8 +345,8 @@ func (wu *WindowDrawers) SetWinBBox(idx int, bbox image.Rectangle) {
 }
 
 // DrawImages iterates over regions and calls Copy on given
-// vdraw.Drawer for each region
-func (wu *WindowDrawers) DrawImages(drw *vdraw.Drawer) {
+// oswin.Drawer for each region
+func (wu *WindowDrawers) DrawImages(drw oswin.Drawer) {
 	if wu.Nodes == nil {
 		return
 	}
@@ -45,6 +45,17 @@ func (ps *Pose) CopyFrom(op *Pose) {
 	ps.UpdateMatrix()
 }
 
+// SetLerp sets the pose information to that which is t of the way from
+// the from pose to the to pose, e.g., for animation: the position and
+// scale are interpolated linearly and the rotation spherically.
+func (ps *Pose) SetLerp(from, to *Pose, t float32) {
+	ps.Pos = from.Pos.Lerp(to.Pos, t)
+	ps.Scale = from.Scale.Lerp(to.Scale, t)
+	q := from.Quat
+	q.Slerp(to.Quat, t)
+	ps.Quat = q
+}
+
 // GenGoSet returns code to set values at given path (var.member etc)
 func (ps *Pose) GenGoSet(path string) string {
 	return ps.Pos.GenGoSet(path+".Pos") + "; " + ps.Scale.GenGoSet(path+".Scale") + "; " + ps.Quat.GenGoSet(path+".Quat")
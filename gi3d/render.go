@@ -13,6 +13,7 @@ import (
 	"github.com/goki/gi/oswin"
 	"github.com/goki/ki/ki"
 	"github.com/goki/mat32"
+	"github.com/goki/vgpu/vdraw"
 	"github.com/goki/vgpu/vgpu"
 
 	vk "github.com/goki/vulkan"
@@ -35,6 +36,26 @@ const (
 /////////////////////////////////////////////////////////////////////////////////////
 // 		Rendering
 
+// VkDrawer is implemented by oswin.Drawer's that are backed by a
+// vgpu vdraw.Drawer, which is required for GPU rendering of the Scene.
+type VkDrawer interface {
+	VkDrawer() *vdraw.Drawer
+}
+
+// WinDrawer returns the vdraw.Drawer for the window of the scene,
+// or nil if the window does not render using the GPU
+// (e.g., with the offscreen driver).
+func (sc *Scene) WinDrawer() *vdraw.Drawer {
+	if sc.Win == nil || sc.Win.OSWin == nil {
+		return nil
+	}
+	vd, ok := sc.Win.OSWin.Drawer().(VkDrawer)
+	if !ok {
+		return nil
+	}
+	return vd.VkDrawer()
+}
+
 // IsConfiged Returns true if the scene has already been configured
 func (sc *Scene) IsConfiged() bool {
 	return sc.Frame != nil
@@ -44,8 +65,11 @@ func (sc *Scene) IsConfiged() bool {
 // returns false if not possible
 func (sc *Scene) ConfigFrame() bool {
 	if sc.Frame == nil {
+		drw := sc.WinDrawer()
+		if drw == nil {
+			return false
+		}
 		oswin.TheApp.RunOnMain(func() {
-			drw := sc.Win.OSWin.Drawer()
 			sf := drw.Surf
 			sz := sc.Geom.Size
 			if sz == image.ZP {
@@ -157,7 +181,9 @@ func (sc *Scene) UpdateMVPMatrix() {
 
 // ConfigRender configures all the rendering elements: Phong system and frame
 func (sc *Scene) ConfigRender() {
-	sc.ConfigFrame()
+	if !sc.ConfigFrame() {
+		return
+	}
 	oswin.TheApp.RunOnMain(func() {
 		sc.ConfigLights()
 		sc.ConfigMeshesTextures()
@@ -244,7 +270,7 @@ func (sc *Scene) Render() bool {
 	sc.UpdateMVPMatrix()
 	sc.Render3D(false) // not offscreen
 
-	drw := sc.Win.OSWin.Drawer()
+	drw := sc.WinDrawer()
 	drw.SetFrameImage(sc.DirUpIdx, sc.Frame.Frames[0])
 	sc.Win.DirDraws.SetWinBBox(sc.DirUpIdx, sc.WinBBox)
 	drw.SyncImages()
@@ -9,6 +9,7 @@ import (
 	"image"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -646,3 +647,26 @@ const (
 
 	SceneFlagsN
 )
+
+// AnimatePose animates the Pose of given node in this scene from its
+// current pose to the given pose (only Pos, Scale and Quat are used),
+// in the window animations (see gi.Animator).
+func (sc *Scene) AnimatePose(nd Node3D, to *Pose, dur time.Duration, ease gi.EaseFunc) *gi.Anim {
+	nb := nd.AsNode3D()
+	nb.PoseMu.RLock()
+	from := nb.Pose
+	nb.PoseMu.RUnlock()
+	tp := *to
+	an := &gi.Anim{Name: "pose", Target: nd, Duration: dur, Ease: ease}
+	an.Update = func(t float32) {
+		nb.PoseMu.Lock()
+		nb.Pose.SetLerp(&from, &tp, t)
+		nb.PoseMu.Unlock()
+		sc.UpdateSig()
+	}
+	if sc.Win == nil {
+		an.Update(1)
+		return an
+	}
+	return sc.Win.Animate(an)
+}
@@ -12,11 +12,11 @@ import (
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/girl"
 	"github.com/goki/gi/gist"
-	"github.com/goki/gi/oswin/driver/vkos"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
+	"github.com/goki/vgpu/vgpu"
 )
 
 // Text2D presents 2D rendered text on a vertically-oriented plane, using a texture.
@@ -124,9 +124,9 @@ func (txt *Text2D) RenderText(sc *Scene) {
 			sz = txt.TxtRender.Size
 		}
 	}
-	marg := txt.Sty.Layout.Margin.Dots
-	sz.SetAddScalar(2 * marg)
-	txt.TxtPos.SetScalar(marg)
+	marg := txt.Sty.Layout.Margin.Dots()
+	sz.SetAdd(marg.Size())
+	txt.TxtPos = marg.Pos()
 	szpt := sz.ToPoint()
 	if szpt == image.ZP {
 		szpt = image.Point{10, 10}
@@ -145,7 +145,7 @@ func (txt *Text2D) RenderText(sc *Scene) {
 			tx.SetImage(img)
 			txt.Mat.SetTexture(sc, tx)
 		} else {
-			if vkos.VkOsDebug {
+			if vgpu.Debug {
 				fmt.Printf("gi3d.Text2D: error: texture name conflict: %s\n", txname)
 			}
 			txt.Mat.SetTexture(sc, tx)
@@ -0,0 +1,322 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+
+	tsfont "github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/font/opentype"
+	"github.com/goki/freetype/truetype"
+	"github.com/goki/mat32"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ColorLayer is one layer of a color glyph in a COLR table, which is the
+// outline of another glyph filled with a color from the palette
+type ColorLayer struct {
+	Glyph   truetype.Index `desc:"glyph with the outline of the layer"`
+	Palette uint16         `desc:"index of the color in the palette -- 0xFFFF is the text color"`
+}
+
+// ColorFace is a font face for a color font, e.g., for emoji, which renders
+// glyphs in color (see ColorGlyphAtIndex) from either the COLR / CPAL color
+// layers of outline glyphs, or from color bitmaps (CBDT, sbix).  Metrics and
+// monochrome rendering use the outline face when the font has outlines.
+type ColorFace struct {
+	Face    font.Face                       `desc:"face for the outline glyphs -- nil for a font that only has bitmaps"`
+	Font    *tsfont.Face                    `desc:"parsed font, for the character map, metrics and bitmaps"`
+	Size    int                             `desc:"size of the face in dots"`
+	Layers  map[truetype.Index][]ColorLayer `desc:"color layers for each color glyph, from the COLR table"`
+	Palette []color.NRGBA                   `desc:"color palette for the layers, from the CPAL table"`
+
+	bitmaps   map[truetype.Index]*colorBitmap
+	bitmapsMu sync.Mutex
+}
+
+// colorBitmap is a color bitmap glyph scaled to the face size, with the
+// offset of its upper-left corner relative to the dot
+type colorBitmap struct {
+	img *image.RGBA
+	off image.Point
+}
+
+var (
+	tagCOLR = opentype.MustNewTag("COLR")
+	tagCPAL = opentype.MustNewTag("CPAL")
+	tagCBDT = opentype.MustNewTag("CBDT")
+	tagSbix = opentype.MustNewTag("sbix")
+)
+
+// NewColorFace returns a ColorFace for given font file contents at given
+// size, if the font has color glyphs -- otherwise nil.  The face is the
+// outline face for the font if it has outlines, else nil.
+func NewColorFace(fontBytes []byte, face font.Face, size int) *ColorFace {
+	ld, err := opentype.NewLoader(bytes.NewReader(fontBytes))
+	if err != nil {
+		return nil
+	}
+	hasLayers := ld.HasTable(tagCOLR) && ld.HasTable(tagCPAL)
+	hasBitmaps := ld.HasTable(tagCBDT) || ld.HasTable(tagSbix)
+	if !hasLayers && !hasBitmaps {
+		return nil
+	}
+	tf, err := tsfont.ParseTTF(bytes.NewReader(fontBytes))
+	if err != nil {
+		return nil
+	}
+	tf.SetPpem(uint16(size), uint16(size))
+	cf := &ColorFace{Face: face, Font: tf, Size: size}
+	isColor := false
+	if _, isx := face.(truetype.IndexableFace); isx && hasLayers {
+		colr, _ := ld.RawTable(tagCOLR)
+		cpal, _ := ld.RawTable(tagCPAL)
+		var err1, err2 error
+		cf.Layers, err1 = ParseCOLR(colr)
+		cf.Palette, err2 = ParseCPAL(cpal)
+		isColor = err1 == nil && err2 == nil && len(cf.Layers) > 0
+	}
+	if hasBitmaps {
+		cf.bitmaps = make(map[truetype.Index]*colorBitmap)
+		isColor = true
+	}
+	if !isColor {
+		return nil
+	}
+	return cf
+}
+
+// ParseCOLR parses the layers of each base glyph in a version 0 COLR table
+func ParseCOLR(data []byte) (map[truetype.Index][]ColorLayer, error) {
+	if len(data) < 14 {
+		return nil, errors.New("girl.ParseCOLR: table too short")
+	}
+	nbase := int(binary.BigEndian.Uint16(data[2:]))
+	baseOff := int(binary.BigEndian.Uint32(data[4:]))
+	layerOff := int(binary.BigEndian.Uint32(data[8:]))
+	nlayer := int(binary.BigEndian.Uint16(data[12:]))
+	if baseOff+6*nbase > len(data) || layerOff+4*nlayer > len(data) {
+		return nil, errors.New("girl.ParseCOLR: invalid record offsets")
+	}
+	layers := make(map[truetype.Index][]ColorLayer, nbase)
+	for i := 0; i < nbase; i++ {
+		rec := data[baseOff+6*i:]
+		gid := truetype.Index(binary.BigEndian.Uint16(rec))
+		first := int(binary.BigEndian.Uint16(rec[2:]))
+		n := int(binary.BigEndian.Uint16(rec[4:]))
+		if first+n > nlayer {
+			return nil, errors.New("girl.ParseCOLR: invalid layer index")
+		}
+		gl := make([]ColorLayer, n)
+		for li := range gl {
+			lrec := data[layerOff+4*(first+li):]
+			gl[li] = ColorLayer{Glyph: truetype.Index(binary.BigEndian.Uint16(lrec)), Palette: binary.BigEndian.Uint16(lrec[2:])}
+		}
+		layers[gid] = gl
+	}
+	return layers, nil
+}
+
+// ParseCPAL parses the first (default) palette of a CPAL table
+func ParseCPAL(data []byte) ([]color.NRGBA, error) {
+	if len(data) < 14 {
+		return nil, errors.New("girl.ParseCPAL: table too short")
+	}
+	nentries := int(binary.BigEndian.Uint16(data[2:]))
+	crecOff := int(binary.BigEndian.Uint32(data[8:]))
+	first := int(binary.BigEndian.Uint16(data[12:]))
+	if crecOff+4*(first+nentries) > len(data) {
+		return nil, errors.New("girl.ParseCPAL: invalid color record offsets")
+	}
+	pal := make([]color.NRGBA, nentries)
+	for i := range pal {
+		rec := data[crecOff+4*(first+i):]
+		pal[i] = color.NRGBA{R: rec[2], G: rec[1], B: rec[0], A: rec[3]}
+	}
+	return pal, nil
+}
+
+// GlyphIndex returns the index of the glyph for given rune, and false if
+// the font does not have it
+func (cf *ColorFace) GlyphIndex(r rune) (truetype.Index, bool) {
+	gid, ok := cf.Font.NominalGlyph(r)
+	return truetype.Index(gid), ok
+}
+
+// scale returns the scaling from font units to dots
+func (cf *ColorFace) scale() float32 {
+	return float32(cf.Size) / float32(cf.Font.Upem())
+}
+
+// ColorGlyph returns the color image of the glyph for given rune, drawn
+// with the dot at given position, as for ColorGlyphAtIndex
+func (cf *ColorFace) ColorGlyph(dot fixed.Point26_6, r rune, clr color.Color) (dr image.Rectangle, img image.Image, ok bool) {
+	idx, ok := cf.GlyphIndex(r)
+	if !ok {
+		return
+	}
+	return cf.ColorGlyphAtIndex(dot, idx, clr)
+}
+
+// ColorGlyphAtIndex returns the color image of the glyph with given index,
+// drawn with the dot at given position, and the rectangle it covers in the
+// destination image -- clr is the text color used for the layers that use
+// it.  ok is false if the glyph does not have a color version.
+func (cf *ColorFace) ColorGlyphAtIndex(dot fixed.Point26_6, idx truetype.Index, clr color.Color) (dr image.Rectangle, img image.Image, ok bool) {
+	if layers := cf.Layers[idx]; len(layers) > 0 {
+		ixf := cf.Face.(truetype.IndexableFace)
+		for li, ly := range layers {
+			ldr, _, _, _, lok := ixf.GlyphAtIndex(dot, ly.Glyph)
+			if !lok {
+				continue
+			}
+			if li == 0 {
+				dr = ldr
+			} else {
+				dr = dr.Union(ldr)
+			}
+		}
+		if dr.Empty() {
+			return
+		}
+		rgba := image.NewRGBA(image.Rectangle{Max: dr.Size()})
+		for _, ly := range layers {
+			ldr, mask, maskp, _, lok := ixf.GlyphAtIndex(dot, ly.Glyph)
+			if !lok {
+				continue
+			}
+			lclr := clr
+			if int(ly.Palette) < len(cf.Palette) {
+				lclr = cf.Palette[ly.Palette]
+			}
+			draw.DrawMask(rgba, ldr.Sub(dr.Min), image.NewUniform(lclr), image.Point{}, mask, maskp, draw.Over)
+		}
+		return dr, rgba, true
+	}
+	if cf.bitmaps == nil {
+		return
+	}
+	bm := cf.bitmap(idx)
+	if bm == nil {
+		return
+	}
+	dp := image.Point{X: dot.X.Round(), Y: dot.Y.Round()}
+	return bm.img.Bounds().Add(dp.Add(bm.off)), bm.img, true
+}
+
+// bitmap returns the color bitmap for given glyph, scaled to the face size,
+// using a cache -- nil if there is none
+func (cf *ColorFace) bitmap(idx truetype.Index) *colorBitmap {
+	cf.bitmapsMu.Lock()
+	defer cf.bitmapsMu.Unlock()
+	if bm, has := cf.bitmaps[idx]; has {
+		return bm
+	}
+	var bm *colorBitmap
+	gd, isbm := cf.Font.GlyphData(tsfont.GID(idx)).(tsfont.GlyphBitmap)
+	ext, hasExt := cf.Font.GlyphExtents(tsfont.GID(idx))
+	if isbm && hasExt && gd.Format == tsfont.PNG {
+		if src, err := png.Decode(bytes.NewReader(gd.Data)); err == nil {
+			sc := cf.scale()
+			w := int(mat32.Round(ext.Width * sc))
+			h := int(mat32.Round(-ext.Height * sc))
+			if w > 0 && h > 0 {
+				img := image.NewRGBA(image.Rectangle{Max: image.Point{X: w, Y: h}})
+				draw.BiLinear.Scale(img, img.Bounds(), src, src.Bounds(), draw.Src, nil)
+				bm = &colorBitmap{img: img, off: image.Point{X: int(mat32.Round(ext.XBearing * sc)), Y: -int(mat32.Round(ext.YBearing * sc))}}
+			}
+		}
+	}
+	cf.bitmaps[idx] = bm
+	return bm
+}
+
+/////////////////////////////////////////////////////////////////
+// font.Face interface
+
+func (cf *ColorFace) Close() error {
+	if cf.Face != nil {
+		return cf.Face.Close()
+	}
+	return nil
+}
+
+func (cf *ColorFace) Metrics() font.Metrics {
+	if cf.Face != nil {
+		return cf.Face.Metrics()
+	}
+	sc := cf.scale()
+	ext, _ := cf.Font.FontHExtents()
+	return font.Metrics{
+		Height:  fixed.Int26_6((ext.Ascender - ext.Descender + ext.LineGap) * sc * 64),
+		Ascent:  fixed.Int26_6(ext.Ascender * sc * 64),
+		Descent: fixed.Int26_6(-ext.Descender * sc * 64),
+	}
+}
+
+func (cf *ColorFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if cf.Face != nil {
+		return cf.Face.Kern(r0, r1)
+	}
+	return 0
+}
+
+func (cf *ColorFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	if cf.Face != nil {
+		return cf.Face.GlyphAdvance(r)
+	}
+	idx, ok := cf.GlyphIndex(r)
+	return fixed.Int26_6(cf.Font.HorizontalAdvance(tsfont.GID(idx)) * cf.scale() * 64), ok
+}
+
+func (cf *ColorFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	if cf.Face != nil {
+		return cf.Face.GlyphBounds(r)
+	}
+	idx, ok := cf.GlyphIndex(r)
+	if !ok {
+		return
+	}
+	advance, _ = cf.GlyphAdvance(r)
+	if ext, has := cf.Font.GlyphExtents(tsfont.GID(idx)); has {
+		sc := cf.scale() * 64
+		bounds.Min = fixed.Point26_6{X: fixed.Int26_6(ext.XBearing * sc), Y: fixed.Int26_6(-ext.YBearing * sc)}
+		bounds.Max = fixed.Point26_6{X: fixed.Int26_6((ext.XBearing + ext.Width) * sc), Y: fixed.Int26_6(-(ext.YBearing + ext.Height) * sc)}
+	}
+	return
+}
+
+func (cf *ColorFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	if cf.Face != nil {
+		return cf.Face.Glyph(dot, r)
+	}
+	idx, ok := cf.GlyphIndex(r)
+	if !ok {
+		return
+	}
+	return cf.GlyphAtIndex(dot, idx)
+}
+
+// GlyphAtIndex satisfies the truetype.IndexableFace interface -- for a
+// bitmap font, the mask is the alpha of the color bitmap
+func (cf *ColorFace) GlyphAtIndex(dot fixed.Point26_6, idx truetype.Index) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	if ixf, isx := cf.Face.(truetype.IndexableFace); isx {
+		return ixf.GlyphAtIndex(dot, idx)
+	}
+	advance = fixed.Int26_6(cf.Font.HorizontalAdvance(tsfont.GID(idx)) * cf.scale() * 64)
+	dr, mask, ok = cf.ColorGlyphAtIndex(dot, idx, color.Black)
+	if ok {
+		maskp = mask.Bounds().Min
+	}
+	return
+}
@@ -0,0 +1,686 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/goki/freetype/truetype"
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+	"github.com/srwiley/scanx"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+)
+
+// displaylist.go has the recording of the painting of each node of a scene
+// into its own display list, using the same recording of paint operations
+// as Vector (see vector.go).  The lists can be replayed into any bounds,
+// used for hit testing, or combined into a Vector for output, and the
+// regions that changed are found by diffing the list of each node with its
+// list from the previous render.
+
+// DisplayList is the recorded painting of one node, in the coordinates of
+// the image that it was rendered into
+type DisplayList struct {
+	Key    interface{}     `desc:"key of the node that the list was recorded for, as passed to PushList"`
+	Bounds image.Rectangle `desc:"bounds of the node when the list was recorded"`
+	Ops    []VectorOp      `desc:"recorded paint operations, in order -- does not include the painting of nodes with their own list, e.g., children"`
+	Seq    int             `desc:"sequence number of the recording, in the order that lists were started -- later lists are drawn on top of earlier ones"`
+}
+
+// displayListRec is an entry on the stack of lists being recorded
+type displayListRec struct {
+	dl   *DisplayList // nil if not recording, e.g., while recording a Vector for output
+	vc   *Vector      // recording for the list
+	prev *Vector      // Vector of the state before the list
+}
+
+// DisplayLists records the painting of each node into its own DisplayList,
+// when set as the Lists of a State -- nodes call PushList and PopList
+// around their rendering.  Each new list is diffed with the previous list
+// of the same node, to accumulate the Dirty regions that changed.
+type DisplayLists struct {
+	Lists map[interface{}]*DisplayList `desc:"most recent list of each node, by key"`
+	Dirty []image.Rectangle            `desc:"regions of the image that changed, from diffing the lists recorded since the last TakeDirty"`
+	Fonts map[string]*VecFont          `desc:"fonts used by the glyphs of all of the lists, by lower-case name"`
+
+	faces map[font.Face]*vecFace
+	stack []displayListRec
+	seq   int
+}
+
+// NewDisplayLists returns a new DisplayLists recorder
+func NewDisplayLists() *DisplayLists {
+	return &DisplayLists{Lists: make(map[interface{}]*DisplayList), Fonts: make(map[string]*VecFont), faces: make(map[font.Face]*vecFace)}
+}
+
+// Reset deletes all of the lists and dirty regions
+func (dr *DisplayLists) Reset() {
+	dr.Lists = make(map[interface{}]*DisplayList)
+	dr.Dirty = nil
+}
+
+// Delete deletes the list of given key, e.g., for a node that is deleted --
+// its bounds are added to the dirty regions
+func (dr *DisplayLists) Delete(key interface{}) {
+	if dl, has := dr.Lists[key]; has {
+		dr.Dirty = append(dr.Dirty, dl.BBox())
+		delete(dr.Lists, key)
+	}
+}
+
+// Seq returns the sequence number of the most recently started list -- lists
+// started after this have a greater Seq
+func (dr *DisplayLists) Seq() int {
+	return dr.seq
+}
+
+// DeleteBefore deletes the lists that were started before given sequence
+// number, e.g., after a full render starting at that number, for nodes that
+// were not rendered -- their bounds are added to the dirty regions
+func (dr *DisplayLists) DeleteBefore(seq int) {
+	for key, dl := range dr.Lists {
+		if dl.Seq <= seq {
+			dr.Delete(key)
+		}
+	}
+}
+
+// TakeDirty returns the dirty regions accumulated since the last call,
+// merged where they overlap, and clears them
+func (dr *DisplayLists) TakeDirty() []image.Rectangle {
+	dirty := MergeRects(dr.Dirty)
+	dr.Dirty = nil
+	return dirty
+}
+
+// Sorted returns the lists in the order of their Seq, i.e., the order in
+// which they are drawn
+func (dr *DisplayLists) Sorted() []*DisplayList {
+	dls := make([]*DisplayList, 0, len(dr.Lists))
+	for _, dl := range dr.Lists {
+		dls = append(dls, dl)
+	}
+	sort.Slice(dls, func(i, j int) bool {
+		return dls[i].Seq < dls[j].Seq
+	})
+	return dls
+}
+
+// HitTest returns the key of the topmost list that draws at given point in
+// the image, and the index of the op within it that does, or nil, -1 if
+// none do
+func (dr *DisplayLists) HitTest(pt image.Point) (interface{}, int) {
+	dls := dr.Sorted()
+	for i := len(dls) - 1; i >= 0; i-- {
+		if oi := dls[i].HitTest(pt); oi >= 0 {
+			return dls[i].Key, oi
+		}
+	}
+	return nil, -1
+}
+
+// Vector returns a Vector recording of given size with the ops of all of
+// the lists, in the order of their Seq
+func (dr *DisplayLists) Vector(size image.Point) *Vector {
+	vc := &Vector{Size: size, Fonts: dr.Fonts}
+	for _, dl := range dr.Sorted() {
+		vc.Ops = append(vc.Ops, dl.Ops...)
+	}
+	return vc
+}
+
+// PushList starts recording a new display list for given key (e.g., a
+// node), if the state has Lists, with the current Bounds as the bounds of
+// the list -- must be balanced with PopList.  Painting is not recorded
+// into lists while a Vector is being recorded for output.
+func (rs *State) PushList(key interface{}) {
+	dr := rs.Lists
+	if dr == nil {
+		return
+	}
+	var cur *Vector
+	if n := len(dr.stack); n > 0 && dr.stack[n-1].dl != nil {
+		cur = dr.stack[n-1].vc
+	}
+	if rs.Vector != cur {
+		dr.stack = append(dr.stack, displayListRec{prev: rs.Vector})
+		return
+	}
+	dr.seq++
+	dl := &DisplayList{Key: key, Bounds: rs.Bounds, Seq: dr.seq}
+	vc := &Vector{Fonts: dr.Fonts, faces: dr.faces, list: true}
+	if rs.Image != nil {
+		vc.Size = rs.Image.Bounds().Size()
+	}
+	dr.stack = append(dr.stack, displayListRec{dl: dl, vc: vc, prev: rs.Vector})
+	rs.Vector = vc
+}
+
+// PopList finishes recording the current display list, which replaces
+// the previous list of its key, adding the regions where they differ to
+// the Dirty regions.
+func (rs *State) PopList() {
+	dr := rs.Lists
+	if dr == nil {
+		return
+	}
+	n := len(dr.stack)
+	if n == 0 {
+		return
+	}
+	rec := dr.stack[n-1]
+	dr.stack = dr.stack[:n-1]
+	rs.Vector = rec.prev
+	if rec.dl == nil {
+		return
+	}
+	dl := rec.dl
+	dl.Ops = rec.vc.Ops
+	dr.Dirty = append(dr.Dirty, DiffDisplayLists(dr.Lists[dl.Key], dl)...)
+	dr.Lists[dl.Key] = dl
+}
+
+// BBox returns the bounding box of everything that the list draws
+func (dl *DisplayList) BBox() image.Rectangle {
+	var bb image.Rectangle
+	for oi := range dl.Ops {
+		bb = bb.Union(dl.Ops[oi].BBox)
+	}
+	return bb
+}
+
+// DiffDisplayLists returns the regions where the drawing of given lists
+// differs: the bounding boxes of the ops that are different in either
+// list -- a nil list draws nothing
+func DiffDisplayLists(a, b *DisplayList) []image.Rectangle {
+	var aops, bops []VectorOp
+	if a != nil {
+		aops = a.Ops
+	}
+	if b != nil {
+		bops = b.Ops
+	}
+	var dirty []image.Rectangle
+	for i := 0; i < len(aops) || i < len(bops); i++ {
+		switch {
+		case i >= len(aops):
+			dirty = append(dirty, bops[i].BBox)
+		case i >= len(bops):
+			dirty = append(dirty, aops[i].BBox)
+		case !vecOpsEqual(&aops[i], &bops[i]):
+			dirty = append(dirty, aops[i].BBox, bops[i].BBox)
+		}
+	}
+	return MergeRects(dirty)
+}
+
+// MergeRects returns given rectangles with the ones that overlap merged
+// into their union, and empty ones removed
+func MergeRects(rs []image.Rectangle) []image.Rectangle {
+	var mrg []image.Rectangle
+	for _, r := range rs {
+		if r.Empty() {
+			continue
+		}
+		for merged := true; merged; {
+			merged = false
+			for i, m := range mrg {
+				if m.Overlaps(r) {
+					r = r.Union(m)
+					mrg = append(mrg[:i], mrg[i+1:]...)
+					merged = true
+					break
+				}
+			}
+		}
+		mrg = append(mrg, r)
+	}
+	return mrg
+}
+
+// vecOpsEqual returns true if the given ops draw the same thing
+func vecOpsEqual(a, b *VectorOp) bool {
+	if a.Op != b.Op || a.Bounds != b.Bounds || a.BBox != b.BBox || a.EvenOdd != b.EvenOdd || a.Color != b.Color || a.XForm != b.XForm || a.Src != b.Src {
+		return false
+	}
+	if !pathsEqual(a.Path, b.Path) || len(a.Clips) != len(b.Clips) || len(a.Glyphs) != len(b.Glyphs) {
+		return false
+	}
+	for i := range a.Clips {
+		if a.Clips[i].EvenOdd != b.Clips[i].EvenOdd || !pathsEqual(a.Clips[i].Path, b.Clips[i].Path) {
+			return false
+		}
+	}
+	for i := range a.Glyphs {
+		if a.Glyphs[i] != b.Glyphs[i] {
+			return false
+		}
+	}
+	as, bs := &a.Stroke, &b.Stroke
+	if as.Width != bs.Width || as.MiterLimit != bs.MiterLimit || as.Cap != bs.Cap || as.Join != bs.Join || len(as.Dashes) != len(bs.Dashes) {
+		return false
+	}
+	for i := range as.Dashes {
+		if as.Dashes[i] != bs.Dashes[i] {
+			return false
+		}
+	}
+	return imagesEqual(a.Image, b.Image)
+}
+
+// pathsEqual returns true if the given paths are the same
+func pathsEqual(a, b []PathSeg) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// imagesEqual returns true if the given images are the same image, or
+// RGBA or NRGBA images with the same pixels
+func imagesEqual(a, b image.Image) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || a.Bounds() != b.Bounds() {
+		return false
+	}
+	pix := func(img image.Image) ([]byte, int, func(x, y int) int) {
+		switch im := img.(type) {
+		case *image.RGBA:
+			return im.Pix, im.Stride, im.PixOffset
+		case *image.NRGBA:
+			return im.Pix, im.Stride, im.PixOffset
+		}
+		return nil, 0, nil
+	}
+	apix, _, aoff := pix(a)
+	bpix, _, boff := pix(b)
+	if aoff == nil || boff == nil {
+		return false
+	}
+	r := a.Bounds()
+	n := 4 * r.Dx()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		ao, bo := aoff(r.Min.X, y), boff(r.Min.X, y)
+		if !bytes.Equal(apix[ao:ao+n], bpix[bo:bo+n]) {
+			return false
+		}
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+//  Replay
+
+// Replay renders the list into the image of given state, moved from the
+// Bounds of the list to given bounds (which it is clipped to).  Only the
+// painting of the list itself is rendered, not that of the nodes with
+// their own lists.
+func (dl *DisplayList) Replay(rs *State, bounds image.Rectangle) {
+	off := bounds.Min.Sub(dl.Bounds.Min)
+	rp := newReplayer(rs.Image)
+	for oi := range dl.Ops {
+		rp.replay(&dl.Ops[oi], off, bounds)
+	}
+}
+
+// replayer renders recorded ops into an image, reusing its rasterizer for
+// all of the ops -- each goroutine needs its own
+type replayer struct {
+	img *image.RGBA
+	sc  *scanx.Scanner
+	ds  *rasterx.Dasher
+}
+
+// newReplayer returns a new replayer for given image
+func newReplayer(img *image.RGBA) *replayer {
+	sz := img.Bounds().Max
+	sc := scanx.NewScanner(scanx.NewImgSpanner(img), sz.X, sz.Y)
+	return &replayer{img: img, sc: sc, ds: rasterx.NewDasher(sz.X, sz.Y, sc)}
+}
+
+// replay renders given op, moved by off and clipped to given bounds and
+// to the clips of the op -- ops with clip paths are rendered into a
+// separate image, which is drawn through the mask of the clips
+func (rp *replayer) replay(op *VectorOp, off image.Point, bounds image.Rectangle) {
+	clip := op.Bounds.Add(off).Intersect(op.BBox.Add(off)).Intersect(bounds).Intersect(rp.img.Bounds())
+	if clip.Empty() {
+		return
+	}
+	if len(op.Clips) == 0 {
+		rp.draw(op, off, clip)
+		return
+	}
+	csz := image.Rectangle{Max: clip.Size()} // rendered at the origin, to limit the size
+	coff := off.Sub(clip.Min)
+	tmp := image.NewRGBA(csz)
+	newReplayer(tmp).draw(op, coff, csz)
+	mask := image.NewAlpha(csz)
+	for ci, cl := range op.Clips {
+		cm := mask
+		if ci > 0 {
+			cm = image.NewAlpha(csz)
+		}
+		fillMask(cm, cl.Path, cl.EvenOdd, coff)
+		if ci > 0 { // intersect
+			for i, a := range cm.Pix {
+				mask.Pix[i] = uint8(uint32(mask.Pix[i]) * uint32(a) / 255)
+			}
+		}
+	}
+	draw.DrawMask(rp.img, clip, tmp, image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// draw renders given op, moved by off and clipped to given region
+func (rp *replayer) draw(op *VectorOp, off image.Point, clip image.Rectangle) {
+	offv := mat32.NewVec2FmPoint(off)
+	switch op.Op {
+	case VecFill:
+		if op.Src {
+			draw.Draw(rp.img, op.BBox.Add(off).Intersect(clip), image.NewUniform(op.Color), image.Point{}, draw.Src)
+			break
+		}
+		var clr interface{} = op.Color
+		if op.Image != nil {
+			inv := op.XForm.Mul(mat32.Translate2D(offv.X, offv.Y)).Inverse()
+			src := op.Image
+			clr = rasterx.ColorFunc(func(x, y int) color.Color {
+				p := inv.MulVec2AsPt(mat32.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+				return src.At(int(mat32.Floor(p.X)), int(mat32.Floor(p.Y)))
+			})
+		}
+		rf := &rp.ds.Filler
+		rf.SetWinding(!op.EvenOdd)
+		rp.sc.SetClip(clip)
+		addPath(rf, op.Path, offv)
+		rf.SetColor(clr)
+		rf.Draw()
+		rf.Clear()
+	case VecStroke:
+		st := &op.Stroke
+		var dash []float64
+		for _, d := range st.Dashes {
+			dash = append(dash, float64(d))
+		}
+		rp.ds.SetStroke(mat32.ToFixed(st.Width), mat32.ToFixed(st.MiterLimit), rasterCap(st.Cap), nil, nil, rasterJoin(st.Join), dash, 0)
+		rp.sc.SetClip(clip)
+		addPath(rp.ds, op.Path, offv)
+		rp.ds.SetColor(op.Color)
+		rp.ds.Draw()
+		rp.ds.Clear()
+	case VecImage:
+		m := op.XForm.Mul(mat32.Translate2D(offv.X, offv.Y))
+		s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+		draw.BiLinear.Transform(rp.img.SubImage(clip).(*image.RGBA), s2d, op.Image, op.Image.Bounds(), draw.Over, nil)
+	case VecGlyphs:
+		replayGlyphs(rp.img, op, off, clip)
+	}
+}
+
+// replayGlyphs renders the glyphs of given op, moved by off and clipped to
+// given region, into given image, using the faces they were recorded from,
+// or the fonts of the FontLibrary at the nearest size if not recorded here.
+// The glyphs in the GlyphCache are drawn without TextFontRenderMu, which is
+// only needed to render the others with their face, so the tiles can draw
+// their text concurrently.
+func replayGlyphs(img *image.RGBA, op *VectorOp, off image.Point, clip image.Rectangle) {
+	dst := img.SubImage(clip).(*image.RGBA)
+	src := image.NewUniform(op.Color)
+	offv := mat32.NewVec2FmPoint(off)
+	for gi := range op.Glyphs {
+		g := &op.Glyphs[gi]
+		rp := g.Pos.Add(offv)
+		if gr := int(mat32.Ceil(2 * g.Size * mat32.Max(g.ScaleX, 1))); !clip.Overlaps(image.Rect(int(rp.X)-gr, int(rp.Y)-gr, int(rp.X)+gr, int(rp.Y)+gr)) {
+			continue // only the glyphs within the clip, e.g., a tile
+		}
+		face := g.face
+		if face == nil {
+			ff, err := FontLibrary.Font(g.Font, int(mat32.Round(g.Size)))
+			if err != nil {
+				continue
+			}
+			face = ff.Face
+		}
+		ixf, isx := face.(truetype.IndexableFace)
+		if !isx {
+			continue
+		}
+		dr, mask, maskp, ok, cached := TheGlyphCache.CachedGlyphAtIndex(ixf, rp.Fixed(), truetype.Index(g.Index))
+		if !cached {
+			TextFontRenderMu.Lock()
+			dr, mask, maskp, ok = TheGlyphCache.GlyphAtIndex(ixf, rp.Fixed(), truetype.Index(g.Index))
+			TextFontRenderMu.Unlock()
+		}
+		if !ok {
+			continue
+		}
+		rr := &Rune{ScaleX: g.ScaleX, RotRad: g.RotRad}
+		if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
+			draw.DrawMask(dst, dr, src, image.Point{}, mask, maskp, draw.Over)
+			continue
+		}
+		m := rr.glyphXForm(rp, dr)
+		s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+		draw.BiLinear.Transform(dst, s2d, src, dr.Sub(dr.Min), draw.Over, &draw.Options{SrcMask: mask, SrcMaskP: maskp})
+	}
+}
+
+// fillMask fills given path, moved by off, in given mask
+func fillMask(mask *image.Alpha, path []PathSeg, evenOdd bool, off image.Point) {
+	sz := mask.Bounds().Max
+	sc := rasterx.NewScannerGV(sz.X, sz.Y, mask, mask.Bounds())
+	rf := rasterx.NewFiller(sz.X, sz.Y, sc)
+	rf.SetWinding(!evenOdd)
+	addPath(rf, path, mat32.NewVec2FmPoint(off))
+	rf.SetColor(color.Alpha{A: 255})
+	rf.Draw()
+}
+
+// addPath adds given recorded path, moved by off, to given rasterx adder
+func addPath(ad rasterx.Adder, path []PathSeg, off mat32.Vec2) {
+	pt := func(p mat32.Vec2) fixed.Point26_6 {
+		return p.Add(off).Fixed()
+	}
+	for _, sg := range path {
+		p := sg.Pts
+		switch sg.Op {
+		case PathMoveTo:
+			ad.Start(pt(p[0]))
+		case PathLineTo:
+			ad.Line(pt(p[0]))
+		case PathQuadTo:
+			ad.QuadBezier(pt(p[0]), pt(p[1]))
+		case PathCubicTo:
+			ad.CubeBezier(pt(p[0]), pt(p[1]), pt(p[2]))
+		case PathClose:
+			ad.Stop(true)
+		}
+	}
+	ad.Stop(false)
+}
+
+// rasterCap returns the rasterx cap function for given line cap
+func rasterCap(c gist.LineCaps) rasterx.CapFunc {
+	switch c {
+	case gist.LineCapButt:
+		return rasterx.ButtCap
+	case gist.LineCapRound:
+		return rasterx.RoundCap
+	case gist.LineCapSquare:
+		return rasterx.SquareCap
+	case gist.LineCapCubic:
+		return rasterx.CubicCap
+	case gist.LineCapQuadratic:
+		return rasterx.QuadraticCap
+	}
+	return nil
+}
+
+// rasterJoin returns the rasterx join mode for given line join
+func rasterJoin(j gist.LineJoins) rasterx.JoinMode {
+	switch j {
+	case gist.LineJoinMiter:
+		return rasterx.Miter
+	case gist.LineJoinMiterClip:
+		return rasterx.MiterClip
+	case gist.LineJoinRound:
+		return rasterx.Round
+	case gist.LineJoinBevel:
+		return rasterx.Bevel
+	case gist.LineJoinArcs:
+		return rasterx.Arc
+	case gist.LineJoinArcsClip:
+		return rasterx.ArcClip
+	}
+	return rasterx.Arc
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+//  Hit testing
+
+// HitTest returns the index of the topmost op of the list that draws at
+// given point in the image, or -1 if none do: within the outline of fills
+// and clips, within half the width of strokes, and within the bounding box
+// of images and glyphs
+func (dl *DisplayList) HitTest(pt image.Point) int {
+	p := mat32.Vec2{X: float32(pt.X) + 0.5, Y: float32(pt.Y) + 0.5}
+	for oi := len(dl.Ops) - 1; oi >= 0; oi-- {
+		op := &dl.Ops[oi]
+		if !pt.In(op.BBox) {
+			continue
+		}
+		in := true
+		for _, cl := range op.Clips {
+			if !PathContains(cl.Path, cl.EvenOdd, p) {
+				in = false
+				break
+			}
+		}
+		if !in {
+			continue
+		}
+		switch op.Op {
+		case VecFill:
+			in = PathContains(op.Path, op.EvenOdd, p)
+		case VecStroke:
+			in = PathNear(op.Path, p, op.Stroke.Width/2)
+		}
+		if in {
+			return oi
+		}
+	}
+	return -1
+}
+
+// flattenPath returns the subpaths of given path as polylines, with the
+// curves approximated by line segments
+func flattenPath(path []PathSeg) [][]mat32.Vec2 {
+	const nseg = 8
+	var polys [][]mat32.Vec2
+	var cur []mat32.Vec2
+	var last mat32.Vec2
+	for _, sg := range path {
+		p := sg.Pts
+		switch sg.Op {
+		case PathMoveTo:
+			if len(cur) > 1 {
+				polys = append(polys, cur)
+			}
+			cur = []mat32.Vec2{p[0]}
+			last = p[0]
+			continue
+		case PathLineTo:
+			cur = append(cur, p[0])
+		case PathQuadTo:
+			for i := 1; i <= nseg; i++ {
+				t := float32(i) / nseg
+				u := 1 - t
+				cur = append(cur, last.MulScalar(u*u).Add(p[0].MulScalar(2*u*t)).Add(p[1].MulScalar(t*t)))
+			}
+		case PathCubicTo:
+			for i := 1; i <= nseg; i++ {
+				t := float32(i) / nseg
+				u := 1 - t
+				cur = append(cur, last.MulScalar(u*u*u).Add(p[0].MulScalar(3*u*u*t)).Add(p[1].MulScalar(3*u*t*t)).Add(p[2].MulScalar(t*t*t)))
+			}
+		case PathClose:
+			if len(cur) > 0 {
+				cur = append(cur, cur[0])
+				polys = append(polys, cur)
+				last = cur[0]
+				cur = []mat32.Vec2{last}
+			}
+			continue
+		}
+		if len(cur) > 0 {
+			last = cur[len(cur)-1]
+		}
+	}
+	if len(cur) > 1 {
+		polys = append(polys, cur)
+	}
+	return polys
+}
+
+// PathContains returns true if given point is inside of given path, as
+// filled with the even-odd or non-zero fill rule
+func PathContains(path []PathSeg, evenOdd bool, p mat32.Vec2) bool {
+	wind := 0
+	for _, poly := range flattenPath(path) {
+		n := len(poly)
+		for i := 0; i < n; i++ {
+			a, b := poly[i], poly[(i+1)%n]
+			if (a.Y <= p.Y) == (b.Y <= p.Y) {
+				continue
+			}
+			x := a.X + (p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if x <= p.X {
+				continue
+			}
+			if b.Y > a.Y {
+				wind++
+			} else {
+				wind--
+			}
+		}
+	}
+	if evenOdd {
+		return wind%2 != 0
+	}
+	return wind != 0
+}
+
+// PathNear returns true if given point is within given distance of the
+// line of given path
+func PathNear(path []PathSeg, p mat32.Vec2, dist float32) bool {
+	for _, poly := range flattenPath(path) {
+		for i := 0; i+1 < len(poly); i++ {
+			a, b := poly[i], poly[i+1]
+			ab := b.Sub(a)
+			t := float32(0)
+			if l2 := ab.Dot(ab); l2 > 0 {
+				t = mat32.Clamp(p.Sub(a).Dot(ab)/l2, 0, 1)
+			}
+			if a.Add(ab.MulScalar(t)).DistTo(p) <= dist {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gist"
+	"golang.org/x/image/font"
+)
+
+// IsEmojiRune returns true if given rune is in one of the main emoji and
+// pictographic symbol blocks
+func IsEmojiRune(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF)
+}
+
+// IsEmojiSeqRune returns true if given rune combines with other runes in an
+// emoji sequence (joiners, variation selectors, skin tones, flags), which
+// requires shaping to select the combined glyph
+func IsEmojiSeqRune(r rune) bool {
+	return r == 0x200D || (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0x1F3FB && r <= 0x1F3FF) || (r >= 0x1F1E6 && r <= 0x1F1FF)
+}
+
+// IsModifierRune returns true if given rune modifies the rune before it, and
+// is thus rendered in the same font: combining marks, joiners, variation
+// selectors, emoji skin tones, keycaps and tags
+func IsModifierRune(r rune) bool {
+	switch {
+	case r < 0x0300:
+		return false
+	case r == 0x200C || r == 0x200D: // zero width (non) joiner
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tones
+		return true
+	case r == 0x20E3: // keycap
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tags
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+// faceFallbacks holds the fallback info for one font face in the library
+type faceFallbacks struct {
+	name  string             // lower-case font name in library
+	size  int                // size of face
+	chain []string           // fallback font names to try, after the face itself
+	faces map[rune]font.Face // cached fallback face for each rune -- nil if none
+}
+
+// fallbacks has the faceFallbacks for each face, under TextFontRenderMu
+var fallbacks = map[font.Face]*faceFallbacks{}
+
+// FallbackFace returns the face to use for given rune that is missing from
+// given font face, which must have been loaded from the library.  The
+// fallback chain is the family of the face and its FontFallbacks, then
+// the same for the Prefs font family, and then all of the other fonts
+// available (with emoji fonts first for emoji runes), in the same style
+// and size as the face where possible.  The coverage of each font and the
+// face found for each rune are cached.  Returns nil if the face has the rune
+// or no fallback was found.
+func (fl *FontLib) FallbackFace(face font.Face, r rune) font.Face {
+	TextFontRenderMu.Lock()
+	defer TextFontRenderMu.Unlock()
+	return fl.fallbackFace(face, r)
+}
+
+// fallbackFace is FallbackFace under TextFontRenderMu
+func (fl *FontLib) fallbackFace(face font.Face, r rune) font.Face {
+	fb := fl.faceFallbacks(face)
+	if fb == nil || fl.hasRune(fb.name, r, true) {
+		return nil
+	}
+	if ff, has := fb.faces[r]; has {
+		return ff
+	}
+	var ff font.Face
+	for _, fn := range fb.chain {
+		if ff = fl.fallbackFont(fn, fb.size, r); ff != nil {
+			break
+		}
+	}
+	if ff == nil {
+		ff = fl.fallbackSystem(fb, r)
+	}
+	fb.faces[r] = ff
+	return ff
+}
+
+// fallbackSystem returns a face for given rune from all of the available
+// fonts, preferring emoji fonts for emoji runes, and the style of given face
+func (fl *FontLib) fallbackSystem(fb *faceFallbacks, r rune) font.Face {
+	loadFontMu.RLock()
+	fns := make([]string, 0, len(fl.FontInfo))
+	for _, fi := range fl.FontInfo {
+		fns = append(fns, strings.ToLower(fi.Name))
+	}
+	loadFontMu.RUnlock()
+	if IsEmojiRune(r) {
+		for _, fn := range fns {
+			if strings.Contains(fn, "emoji") {
+				if ff := fl.fallbackFont(fn, fb.size, r); ff != nil {
+					return ff
+				}
+			}
+		}
+	}
+	_, str, wt, sty := gist.FontNameToMods(fl.FontInfoName(fb.name))
+	for iter := 0; iter < 2; iter++ {
+		for _, fn := range fns {
+			_, fstr, fwt, fsty := gist.FontNameToMods(fl.FontInfoName(fn))
+			if iter == 0 && (fstr != str || fwt != wt || fsty != sty) {
+				continue
+			}
+			if ff := fl.fallbackFont(fn, fb.size, r); ff != nil {
+				return ff
+			}
+		}
+	}
+	return nil
+}
+
+// fallbackFont returns the face for given font name and size if it has given
+// rune, else nil
+func (fl *FontLib) fallbackFont(fontnm string, size int, r rune) font.Face {
+	if !fl.hasRune(fontnm, r, false) {
+		return nil
+	}
+	ff, err := fl.Font(fontnm, size)
+	if err != nil {
+		return nil
+	}
+	return ff.Face
+}
+
+// hasRune returns true if the font with given lower-case name has a glyph
+// for given rune, using the cached character map of the font -- returns
+// unknown if the character map cannot be loaded.
+func (fl *FontLib) hasRune(fontnm string, r rune, unknown bool) bool {
+	tf := shapeFont(fontnm)
+	if tf == nil {
+		return unknown
+	}
+	_, has := tf.NominalGlyph(r)
+	return has
+}
+
+// faceFallbacks returns the faceFallbacks for given face, creating it if
+// needed -- nil if the face is not in the library
+func (fl *FontLib) faceFallbacks(face font.Face) *faceFallbacks {
+	if fb, has := fallbacks[face]; has {
+		return fb
+	}
+	var fb *faceFallbacks
+	if fontnm, size := fl.FaceNameSize(face); fontnm != "" {
+		fb = &faceFallbacks{name: fontnm, size: size, faces: make(map[rune]font.Face)}
+		basenm, str, wt, sty := gist.FontNameToMods(fl.FontInfoName(fontnm))
+		fams, _, _ := FontAlts(basenm)
+		if gist.ThePrefs != nil {
+			pfams, _, _ := FontAlts(gist.ThePrefs.PrefFontFamily())
+			fams = append(fams, pfams...)
+		}
+		for _, fam := range fams {
+			for _, fn := range []string{gist.FontNameFromMods(fam, str, wt, sty), fam} {
+				fn = strings.ToLower(fn)
+				if fn != fontnm && fl.FontAvail(fn) {
+					addUniqueFont(&fb.chain, fn)
+				}
+			}
+		}
+	}
+	fallbacks[face] = fb
+	return fb
+}
+
+// FontInfoName returns the regularized name of the font with given
+// lower-case name, from the FontInfo -- returns the name itself if not found
+func (fl *FontLib) FontInfoName(fontnm string) string {
+	loadFontMu.RLock()
+	defer loadFontMu.RUnlock()
+	for _, fi := range fl.FontInfo {
+		if strings.ToLower(fi.Name) == fontnm {
+			return fi.Name
+		}
+	}
+	return fontnm
+}
+
+// SetFallbackFaces sets the face of each rune that is missing from its font
+// face to a fallback face that has it (see FontLib.FallbackFace), and sets
+// the original face again for the runes after it.  Modifier runes
+// (combining marks, joiners etc) stay in the face of the rune before them.
+func (sr *Span) SetFallbackFaces() {
+	if len(sr.Render) == 0 || sr.Render[0].Face == nil {
+		return
+	}
+	TextFontRenderMu.Lock()
+	defer TextFontRenderMu.Unlock()
+	var styFace, curFace font.Face // face from styling, and current rendering face
+	for i, r := range sr.Text {
+		rr := &(sr.Render[i])
+		if rr.Face != nil {
+			styFace = rr.Face
+		}
+		face := styFace
+		switch {
+		case i > 0 && IsModifierRune(r):
+			face = curFace
+		case unicode.IsSpace(r) || !unicode.IsPrint(r):
+		default:
+			if ff := FontLibrary.fallbackFace(styFace, r); ff != nil {
+				face = ff
+			}
+		}
+		if face != curFace {
+			rr.Face = face
+			curFace = face
+		}
+	}
+}
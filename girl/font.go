@@ -78,6 +78,10 @@ func OpenFontFace(name, path string, size int, strokeWidth int) (*gist.FontFace,
 	} else {
 		f, err := truetype.Parse(fontBytes)
 		if err != nil {
+			// color bitmap fonts (e.g., emoji) can lack outlines
+			if cf := NewColorFace(fontBytes, nil, size); cf != nil {
+				return gist.NewFontFace(name, size, cf), nil
+			}
 			return nil, err
 		}
 		face := truetype.NewFace(f, &truetype.Options{
@@ -86,6 +90,9 @@ func OpenFontFace(name, path string, size int, strokeWidth int) (*gist.FontFace,
 			// Hinting: font.HintingFull,
 			// GlyphCacheEntries: 1024, // default is 512 -- todo benchmark
 		})
+		if cf := NewColorFace(fontBytes, face, size); cf != nil {
+			return gist.NewFontFace(name, size, cf), nil
+		}
 		ff := gist.NewFontFace(name, size, face)
 		return ff, nil
 	}
@@ -16,7 +16,10 @@ import (
 	"github.com/fatih/camelcase"
 	"github.com/goki/freetype/truetype"
 	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
 	"github.com/iancoleman/strcase"
+	"golang.org/x/image/font"
 	"golang.org/x/image/font/gofont/gobold"
 	"golang.org/x/image/font/gofont/gobolditalic"
 	"golang.org/x/image/font/gofont/goitalic"
@@ -140,6 +143,42 @@ func (fl *FontLib) Font(fontnm string, size int) (*gist.FontFace, error) {
 	return nil, fmt.Errorf("gi.FontLib: Font named: %v not found in list of available fonts, try adding to FontPaths in gi.FontLibrary, searched paths: %v\n", fontnm, fl.FontPaths)
 }
 
+// FaceNameSize returns the font name and integer size of given font face,
+// which must have been loaded from the library -- returns "" if it is not
+// found in the library.
+func (fl *FontLib) FaceNameSize(face font.Face) (string, int) {
+	loadFontMu.RLock()
+	defer loadFontMu.RUnlock()
+	for nm, facemap := range fl.Faces {
+		for sz, ff := range facemap {
+			if ff.Face == face {
+				return nm, sz
+			}
+		}
+	}
+	return "", 0
+}
+
+// ScaledFace returns the given font face, which must have been loaded from
+// the library, at given scaling factor of its size (rounded down to an
+// integer size), e.g., for shrinking text to fit -- returns the face itself
+// if it is not found in the library.
+func (fl *FontLib) ScaledFace(face font.Face, scale float32) font.Face {
+	fontnm, size := fl.FaceNameSize(face)
+	if fontnm == "" {
+		return face
+	}
+	nsz := ints.MaxInt(int(mat32.Floor(float32(size)*scale)), 1)
+	if nsz == size {
+		return face
+	}
+	ff, err := fl.Font(fontnm, nsz)
+	if err != nil {
+		return face
+	}
+	return ff.Face
+}
+
 // DeleteFont removes given font from list of available fonts -- if not supported etc
 func (fl *FontLib) DeleteFont(fontnm string) {
 	loadFontMu.Lock()
@@ -193,6 +232,7 @@ func (fl *FontLib) UpdateFontsAvail() bool {
 	if len(fl.FontsAvail) > 0 {
 		fl.FontsAvail = make(map[string]string)
 	}
+	TheGlyphCache.Reset() // the fonts are reloaded
 	fl.GoFontsAvail()
 	for _, p := range fl.FontPaths {
 		fl.FontsAvailFromPath(p)
@@ -0,0 +1,287 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// fontsubset.go has the subsetting of TrueType fonts, for embedding only
+// the glyphs that are used in PDF output.  The subsets keep the glyph
+// indexes of the font (unused glyphs are emptied), so glyphs can be drawn
+// by their index in the full font.
+
+// TTFTables returns the tables of given TrueType font data, by tag, or of
+// the first font of a TrueType collection
+func TTFTables(data []byte) (map[string][]byte, error) {
+	if len(data) >= 16 && string(data[:4]) == "ttcf" {
+		off := int(binary.BigEndian.Uint32(data[12:]))
+		if off >= len(data) {
+			return nil, errors.New("girl.TTFTables: invalid font collection")
+		}
+		return ttfTables(data, off)
+	}
+	return ttfTables(data, 0)
+}
+
+// ttfTables returns the tables of the font with table directory at given
+// offset in the data
+func ttfTables(data []byte, off int) (map[string][]byte, error) {
+	if len(data) < off+12 {
+		return nil, errors.New("girl.TTFTables: font data too short")
+	}
+	n := int(binary.BigEndian.Uint16(data[off+4:]))
+	if len(data) < off+12+16*n {
+		return nil, errors.New("girl.TTFTables: font table directory too short")
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		rec := data[off+12+16*i:]
+		toff := int(binary.BigEndian.Uint32(rec[8:]))
+		tlen := int(binary.BigEndian.Uint32(rec[12:]))
+		if toff < 0 || tlen < 0 || toff+tlen > len(data) {
+			return nil, errors.New("girl.TTFTables: font table out of range")
+		}
+		tables[string(rec[:4])] = data[toff : toff+tlen]
+	}
+	return tables, nil
+}
+
+// IsTrueTypeGlyf returns true if given font data is a TrueType font (or
+// collection) with glyf outlines, which can be subset with SubsetTTF
+func IsTrueTypeGlyf(data []byte) bool {
+	tables, err := TTFTables(data)
+	if err != nil {
+		return false
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "loca", "glyf", "maxp"} {
+		if _, has := tables[tag]; !has {
+			return false
+		}
+	}
+	return len(tables["head"]) >= 54 && len(tables["hhea"]) >= 36 && len(tables["maxp"]) >= 6
+}
+
+// ttfLoca returns the offsets of the glyphs in the glyf table
+func ttfLoca(tables map[string][]byte) []int {
+	head, loca := tables["head"], tables["loca"]
+	ng := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+	offs := make([]int, ng+1)
+	long := binary.BigEndian.Uint16(head[50:]) != 0
+	for i := range offs {
+		switch {
+		case long && 4*i+4 <= len(loca):
+			offs[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		case !long && 2*i+2 <= len(loca):
+			offs[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		case i > 0:
+			offs[i] = offs[i-1]
+		}
+	}
+	return offs
+}
+
+// ttfGlyph returns the data of given glyph in the glyf table
+func ttfGlyph(glyf []byte, loca []int, gid int) []byte {
+	if gid+1 >= len(loca) {
+		return nil
+	}
+	st, ed := loca[gid], loca[gid+1]
+	if st >= ed || ed > len(glyf) {
+		return nil
+	}
+	return glyf[st:ed]
+}
+
+// ttfComponents returns the glyphs that given composite glyph is composed of
+func ttfComponents(gd []byte) []int {
+	if len(gd) < 10 || int16(binary.BigEndian.Uint16(gd)) >= 0 {
+		return nil
+	}
+	var comps []int
+	for p := 10; p+4 <= len(gd); {
+		flags := binary.BigEndian.Uint16(gd[p:])
+		comps = append(comps, int(binary.BigEndian.Uint16(gd[p+2:])))
+		p += 4
+		if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&0x0008 != 0: // WE_HAVE_A_SCALE
+			p += 2
+		case flags&0x0040 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			p += 4
+		case flags&0x0080 != 0: // WE_HAVE_A_TWO_BY_TWO
+			p += 8
+		}
+		if flags&0x0020 == 0 { // MORE_COMPONENTS
+			break
+		}
+	}
+	return comps
+}
+
+// SubsetTTF returns TrueType font data with only the outlines of given
+// glyphs (and the glyphs that they are composed of, and the .notdef
+// glyph 0) from given font data -- the glyph indexes stay the same as in
+// the font.  Only the tables needed for rendering glyphs by index are kept.
+func SubsetTTF(data []byte, gids map[uint16]bool) ([]byte, error) {
+	if !IsTrueTypeGlyf(data) {
+		return nil, errors.New("girl.SubsetTTF: not a TrueType font with glyf outlines")
+	}
+	tables, _ := TTFTables(data)
+	glyf := tables["glyf"]
+	loca := ttfLoca(tables)
+	ng := len(loca) - 1
+	keep := make([]bool, ng)
+	var todo []int
+	add := func(gid int) {
+		if gid < ng && !keep[gid] {
+			keep[gid] = true
+			todo = append(todo, gid)
+		}
+	}
+	add(0)
+	for gid := range gids {
+		add(int(gid))
+	}
+	for len(todo) > 0 {
+		gid := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		for _, c := range ttfComponents(ttfGlyph(glyf, loca, gid)) {
+			add(c)
+		}
+	}
+	var nglyf []byte
+	nloca := make([]byte, 4*(ng+1))
+	for gid := 0; gid < ng; gid++ {
+		binary.BigEndian.PutUint32(nloca[4*gid:], uint32(len(nglyf)))
+		if keep[gid] {
+			nglyf = append(nglyf, ttfGlyph(glyf, loca, gid)...)
+			for len(nglyf)%4 != 0 {
+				nglyf = append(nglyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(nloca[4*ng:], uint32(len(nglyf)))
+	head := append([]byte(nil), tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment, set below
+	binary.BigEndian.PutUint16(head[50:], 1) // long loca offsets
+	ntables := map[string][]byte{"head": head, "hhea": tables["hhea"], "hmtx": tables["hmtx"], "maxp": tables["maxp"], "loca": nloca, "glyf": nglyf}
+	for _, tag := range []string{"cmap", "cvt ", "fpgm", "prep"} {
+		if tb, has := tables[tag]; has {
+			ntables[tag] = tb
+		}
+	}
+	font := writeTTF(ntables)
+	hoff := int(binary.BigEndian.Uint32(font[12+16*ttfTableIndex(ntables, "head")+8:]))
+	binary.BigEndian.PutUint32(font[hoff+8:], 0xB1B0AFBA-ttfChecksum(font))
+	return font, nil
+}
+
+// ttfTableIndex returns the index of given table in the directory written
+// by writeTTF, which sorts the tables by tag
+func ttfTableIndex(tables map[string][]byte, tag string) int {
+	n := 0
+	for t := range tables {
+		if t < tag {
+			n++
+		}
+	}
+	return n
+}
+
+// ttfChecksum returns the TrueType checksum of given data
+func ttfChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var w [4]byte
+		copy(w[:], data[i:])
+		sum += binary.BigEndian.Uint32(w[:])
+	}
+	return sum
+}
+
+// writeTTF returns the TrueType font data with given tables
+func writeTTF(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	n := len(tags)
+	es := 0
+	for 1<<(es+1) <= n {
+		es++
+	}
+	font := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(font, 0x00010000)
+	binary.BigEndian.PutUint16(font[4:], uint16(n))
+	binary.BigEndian.PutUint16(font[6:], uint16(16<<es))
+	binary.BigEndian.PutUint16(font[8:], uint16(es))
+	binary.BigEndian.PutUint16(font[10:], uint16(16*n-16<<es))
+	for i, tag := range tags {
+		tb := tables[tag]
+		rec := font[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], ttfChecksum(tb))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(font)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(tb)))
+		font = append(font, tb...)
+		for len(font)%4 != 0 {
+			font = append(font, 0)
+		}
+	}
+	return font
+}
+
+// TTFMetrics are the metrics of a TrueType font needed for embedding it,
+// in font units
+type TTFMetrics struct {
+	UnitsPerEm int
+	Ascent     int
+	Descent    int
+	BBox       [4]int
+	Advances   []int
+}
+
+// GetTTFMetrics returns the metrics of given TrueType font data
+func GetTTFMetrics(data []byte) (*TTFMetrics, error) {
+	if !IsTrueTypeGlyf(data) {
+		return nil, errors.New("girl.GetTTFMetrics: not a TrueType font with glyf outlines")
+	}
+	tables, _ := TTFTables(data)
+	head, hhea, hmtx := tables["head"], tables["hhea"], tables["hmtx"]
+	mt := &TTFMetrics{UnitsPerEm: int(binary.BigEndian.Uint16(head[18:]))}
+	for i := range mt.BBox {
+		mt.BBox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	mt.Ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	mt.Descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	nhm := int(binary.BigEndian.Uint16(hhea[34:]))
+	for i := 0; i < nhm && 4*i+2 <= len(hmtx); i++ {
+		mt.Advances = append(mt.Advances, int(binary.BigEndian.Uint16(hmtx[4*i:])))
+	}
+	if mt.UnitsPerEm == 0 {
+		mt.UnitsPerEm = 1000
+	}
+	return mt, nil
+}
+
+// Advance returns the advance width of given glyph
+func (mt *TTFMetrics) Advance(gid uint16) int {
+	n := len(mt.Advances)
+	switch {
+	case n == 0:
+		return 0
+	case int(gid) < n:
+		return mt.Advances[gid]
+	}
+	return mt.Advances[n-1]
+}
@@ -5,17 +5,22 @@
 package girl
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/png"
 	"log"
 	"os"
 	"strings"
 	"testing"
+	"unicode"
 
+	"github.com/goki/freetype/truetype"
 	"github.com/goki/gi/gist"
 	"github.com/goki/mat32"
+	"golang.org/x/image/math/fixed"
 )
 
 // TestPrefs are needed for setting gist.ThePrefs, for any text-based
@@ -161,3 +166,749 @@ func TestRender(t *testing.T) {
 	defer file.Close()
 	png.Encode(file, img)
 }
+
+func TestBoxShadow(t *testing.T) {
+	imgsz := image.Point{100, 100}
+	img := image.NewRGBA(image.Rectangle{Max: imgsz})
+	rs := &State{}
+	rs.Init(imgsz.X, imgsz.Y, img)
+	rs.PushBounds(img.Rect)
+	pc := &rs.Paint
+	pc.FillStyle.SetColor(color.White)
+	pc.Clear(rs)
+
+	sh := &gist.Shadow{Color: gist.Black}
+	sh.VOffset.SetDot(4)
+	sh.Blur.SetDot(8)
+	pos, sz := mat32.Vec2{X: 30, Y: 30}, mat32.Vec2{X: 40, Y: 40}
+	rad := gist.NewSideFloats(6)
+	pc.DrawBoxShadow(rs, pos, sz, rad, sh)
+
+	gray := func(x, y int) uint8 { return img.RGBAAt(x, y).R }
+	if g := gray(50, 54); g > 10 {
+		t.Errorf("shadow center should be black: %v", g)
+	}
+	if g := gray(50, 86); g != 255 {
+		t.Errorf("shadow should not extend beyond blur: %v", g)
+	}
+	if g := gray(50, 74); g < 50 || g > 200 {
+		t.Errorf("shadow edge should be blurred: %v", g)
+	}
+	if gray(50, 72) >= gray(50, 76) {
+		t.Errorf("shadow should fade out: %v %v", gray(50, 72), gray(50, 76))
+	}
+
+	key := shadowKey{W: 40, H: 40, Rad: rad, Blur: 8}
+	if shadowMask(key) != shadowMask(key) {
+		t.Errorf("shadow mask not cached")
+	}
+
+	// the cache is bounded by bytes, evicting the least recently used masks
+	defer func(mx int) { ShadowCacheMaxBytes = mx }(ShadowCacheMaxBytes)
+	kbytes := (&shadowEntry{mask: shadowMask(key)}).bytes()
+	ShadowCacheMaxBytes = 3 * kbytes
+	for i := 1; i <= 4; i++ {
+		shadowMask(shadowKey{W: 40, H: 40, Rad: rad, Blur: 8, Spread: float32(i) / 4})
+	}
+	shadowCacheMu.Lock()
+	nb, n := shadowCacheBytes, shadowCacheLRU.Len()
+	_, hasOld := shadowCache[key]
+	shadowCacheMu.Unlock()
+	if nb > ShadowCacheMaxBytes || n > 3 || hasOld {
+		t.Errorf("shadow cache not bounded: %d bytes > %d, %d masks, has least recent: %v", nb, ShadowCacheMaxBytes, n, hasOld)
+	}
+
+	sh.Inset = true
+	sh.VOffset.SetDot(0)
+	pc.Clear(rs)
+	pc.DrawBoxShadow(rs, pos, sz, rad, sh)
+	if g := gray(50, 31); g > 200 {
+		t.Errorf("inset shadow edge should be dark: %v", g)
+	}
+	if g := gray(50, 50); g < 250 {
+		t.Errorf("inset shadow center should be clear: %v", g)
+	}
+	if g := gray(50, 25); g != 255 {
+		t.Errorf("inset shadow should be clipped to box: %v", g)
+	}
+}
+
+func TestTextOverflow(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(image.Point{X: 320, Y: 240})
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	tsty.WhiteSpace = gist.WhiteSpacePre
+	fsty := &gist.Font{}
+	fsty.Defaults()
+
+	str := "The quick brown fox jumps over the lazy dog"
+	layout := func(wd float32) (*Text, string) {
+		txt := &Text{}
+		txt.SetHTML(str, fsty, tsty, &pc.UnContext, nil)
+		txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: wd})
+		return txt, string(txt.Spans[0].Text)
+	}
+
+	full, _ := layout(0)
+	fw := full.Size.X
+	if full.Truncated {
+		t.Errorf("text without width constraint should not be truncated")
+	}
+
+	_, clip := layout(fw / 2)
+	if clip != str {
+		t.Errorf("clipped text should not change: %v", clip)
+	}
+
+	tsty.Overflow = gist.TextOverflowEllipsis
+	txt, end := layout(fw / 2)
+	if !txt.Truncated || !strings.HasPrefix(end, "The quick") || !strings.HasSuffix(end, "…") {
+		t.Errorf("bad end ellipsis: %v", end)
+	}
+	if txt.Size.X > fw/2 {
+		t.Errorf("elided text does not fit: %v > %v", txt.Size.X, fw/2)
+	}
+
+	tsty.Overflow = gist.TextOverflowEllipsisStart
+	_, start := layout(fw / 2)
+	if !strings.HasPrefix(start, "…") || !strings.HasSuffix(start, "lazy dog") {
+		t.Errorf("bad start ellipsis: %v", start)
+	}
+
+	tsty.Overflow = gist.TextOverflowEllipsisMiddle
+	_, mid := layout(fw / 2)
+	if !strings.HasPrefix(mid, "The") || !strings.HasSuffix(mid, "dog") || !strings.Contains(mid, "…") {
+		t.Errorf("bad middle ellipsis: %v", mid)
+	}
+
+	txt, _ = layout(fw + 10)
+	if txt.Truncated {
+		t.Errorf("text that fits should not be truncated")
+	}
+
+	tsty.Overflow = gist.TextOverflowClip
+	tsty.ShrinkToFit = 0.5
+	txt, shrink := layout(fw * 0.8)
+	if shrink != str || txt.Truncated || txt.Size.X > fw*0.8 {
+		t.Errorf("text should shrink to fit: %v %v", shrink, txt.Size.X)
+	}
+	tsty.ShrinkToFit = 0
+
+	tsty.WhiteSpace = gist.WhiteSpaceNormal
+	tsty.MaxLines = 2
+	txt, _ = layout(fw / 3)
+	if len(txt.Spans) != 2 || !txt.Truncated {
+		t.Fatalf("text should be clamped to 2 lines: %v", len(txt.Spans))
+	}
+	if ln := string(txt.Spans[1].Text); !strings.HasSuffix(ln, "…") {
+		t.Errorf("last clamped line should end with ellipsis: %v", ln)
+	}
+}
+
+func TestShaping(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+	if !FontLibrary.FontAvail("DejaVuSans") {
+		t.Skip("DejaVuSans font not available")
+	}
+
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(image.Point{X: 320, Y: 240})
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	fsty.Family = "DejaVuSans"
+	OpenFont(fsty, &pc.UnContext)
+
+	layout := func(str string) *Span {
+		txt := &Text{}
+		txt.SetString(str, fsty, &pc.UnContext, tsty, true, 0, 1)
+		return &txt.Spans[0]
+	}
+
+	// hebrew is reversed, followed by the latin text
+	sr := layout("שלום abc")
+	if len(sr.Glyphs) == 0 || sr.Glyphs[0].ByRune {
+		t.Fatalf("text was not shaped")
+	}
+	if sr.Render[3].RelPos.X >= sr.Render[0].RelPos.X || sr.Render[0].RelPos.X >= sr.Render[5].RelPos.X {
+		t.Errorf("bad bidi order: %v %v %v", sr.Render[3].RelPos.X, sr.Render[0].RelPos.X, sr.Render[5].RelPos.X)
+	}
+	if sr.Render[3].RelPos.X != 0 || sr.SizeHV().X != sr.LastPos.X {
+		t.Errorf("shaped text should start at 0: %v %v", sr.Render[3].RelPos.X, sr.SizeHV())
+	}
+
+	// a combining mark is in the same cluster as its base
+	sr = layout("שָלום")
+	if sr.Render[1].RelPos.X != sr.Render[0].RelPos.X || sr.Render[1].Size.X != 0 || sr.Render[0].Size.X == 0 {
+		t.Errorf("mark should be in base cluster: %v %v", sr.Render[0], sr.Render[1])
+	}
+
+	// arabic letters take contextual forms
+	lam := layout("ل").Glyphs[0].Index
+	sr = layout("سلام")
+	for _, g := range sr.Glyphs {
+		if g.Rune == 1 && g.Index == lam {
+			t.Errorf("lam should be in medial form in: %v", string(sr.Text))
+		}
+	}
+
+	// rtl base direction puts the latin text after the hebrew on the left
+	tsty.Direction = gist.RTL
+	sr = layout("abc שלום")
+	if sr.Dir != gist.RLTB || sr.Render[0].Level != 2 || sr.Render[4].Level != 1 {
+		t.Errorf("bad rtl levels: %v %v", sr.Render[0].Level, sr.Render[4].Level)
+	}
+	if sr.Render[0].RelPos.X <= sr.Render[4].RelPos.X {
+		t.Errorf("latin text should be right of hebrew: %v %v", sr.Render[0].RelPos.X, sr.Render[4].RelPos.X)
+	}
+	tsty.Direction = gist.LTR
+
+	img := image.NewRGBA(image.Rectangle{Max: image.Point{X: 100, Y: 40}})
+	rs := &State{}
+	rs.Init(100, 40, img)
+	rs.PushBounds(img.Rect)
+	txt := &Text{}
+	txt.SetString("سلام", fsty, &pc.UnContext, tsty, true, 0, 1)
+	txt.Render(rs, mat32.Vec2{X: 10, Y: 30})
+	drawn := false
+	for _, p := range img.Pix {
+		if p != 0 {
+			drawn = true
+			break
+		}
+	}
+	if !drawn {
+		t.Errorf("shaped glyphs were not rendered")
+	}
+}
+
+func TestFallback(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+	if !FontLibrary.FontAvail("DejaVuSans") {
+		t.Skip("DejaVuSans font not available")
+	}
+
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(image.Point{X: 320, Y: 240})
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	fsty.Family = "Go"
+	OpenFont(fsty, &pc.UnContext)
+
+	txt := &Text{}
+	txt.SetString("ab שלום cd", fsty, &pc.UnContext, tsty, true, 0, 1)
+	sr := &txt.Spans[0]
+	goFace := sr.Render[0].Face
+	heFace := sr.Render[3].Face
+	if heFace == nil || heFace == goFace {
+		t.Fatalf("hebrew did not get a fallback face")
+	}
+	if nm, _ := FontLibrary.FaceNameSize(heFace); nm == "" || strings.HasPrefix(nm, "go") {
+		t.Errorf("unexpected fallback font: %v", nm)
+	}
+	if sr.Render[7].Face != goFace {
+		t.Errorf("face not restored after fallback runes")
+	}
+	if ff := FontLibrary.FallbackFace(goFace, 'a'); ff != nil {
+		t.Errorf("fallback face for rune in font")
+	}
+
+	// COLR v0: glyph 3 has layers 4, 5 in palette entries 1, 0
+	colr := []byte{0, 0, 0, 1, 0, 0, 0, 14, 0, 0, 0, 20, 0, 2,
+		0, 3, 0, 0, 0, 2,
+		0, 4, 0, 1, 0, 5, 0, 0}
+	layers, err := ParseCOLR(colr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ly := layers[3]; len(ly) != 2 || ly[0].Glyph != 4 || ly[0].Palette != 1 || ly[1].Glyph != 5 {
+		t.Errorf("bad COLR layers: %v", ly)
+	}
+	// CPAL v0: 2 entries in one palette, in BGRA order
+	cpal := []byte{0, 0, 0, 2, 0, 1, 0, 2, 0, 0, 0, 14, 0, 0,
+		0, 0, 255, 255, 255, 0, 0, 128}
+	pal, err := ParseCPAL(cpal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pal) != 2 || pal[0] != (color.NRGBA{R: 255, A: 255}) || pal[1] != (color.NRGBA{B: 255, A: 128}) {
+		t.Errorf("bad CPAL palette: %v", pal)
+	}
+}
+
+func TestHyphenJustify(t *testing.T) {
+	hy := NewHyphenator("en", HyphenPatternsEn, HyphenExceptionsEn)
+	for word, hyps := range map[string]string{"nation": "na-tion", "running": "run-ning", "payment": "pay-ment", "thinking": "think-ing", "union": "union", "clothing": "cloth-ing", "string": "string"} {
+		w := []rune(word)
+		var hw []rune
+		hp := hy.Hyphenate(w)
+		for i, r := range w {
+			if len(hp) > 0 && hp[0] == i {
+				hw = append(hw, '-')
+				hp = hp[1:]
+			}
+			hw = append(hw, r)
+		}
+		if string(hw) != hyps {
+			t.Errorf("hyphenate %v: got %v, want %v", word, string(hw), hyps)
+		}
+	}
+	pats, exc := ParseHyphenTeX("% comment\n\\patterns{ .ab1c 2b1 }\n\\hyphenation{ ta-ble }")
+	if strings.Fields(pats)[1] != "2b1" || strings.TrimSpace(exc) != "ta-ble" {
+		t.Errorf("bad TeX parse: %q %q", pats, exc)
+	}
+
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(image.Point{X: 320, Y: 240})
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	OpenFont(fsty, &pc.UnContext)
+
+	layout := func(str string, width float32) *Text {
+		txt := &Text{}
+		txt.SetString(str, fsty, &pc.UnContext, tsty, true, 0, 1)
+		txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: width})
+		return txt
+	}
+	str := "The quick brown fox jumps over the lazy dog, and then the information technology department implements the recommendations."
+
+	tsty.Align = gist.AlignJustify
+	txt := layout(str, 150)
+	if len(txt.Spans) < 3 {
+		t.Fatalf("text not wrapped: %v lines", len(txt.Spans))
+	}
+	for si, sr := range txt.Spans[:len(txt.Spans)-1] {
+		ed := len(sr.Text) - 1
+		for unicode.IsSpace(sr.Text[ed]) {
+			ed--
+		}
+		end := sr.RelPos.X + sr.Render[ed].RelPosAfterLR()
+		if sr.Hyphen {
+			end += sr.HyphenWidthAt(ed)
+		}
+		if mat32.Abs(end-150) > 0.5 && strings.Count(strings.TrimSpace(string(sr.Text)), " ") > 0 {
+			t.Errorf("justified line %v ends at: %v", si, end)
+		}
+	}
+
+	tsty.Align = gist.AlignLeft
+	tsty.Hyphens = gist.HyphensAuto
+	txt = layout(str, 150)
+	hyph := false
+	for _, sr := range txt.Spans {
+		if sr.Hyphen {
+			hyph = true
+		}
+		if ssz := sr.SizeHV(); sr.RelPos.X+ssz.X > 150 && strings.Contains(strings.TrimSpace(string(sr.Text)), " ") {
+			t.Errorf("line too wide: %v %q", ssz.X, string(sr.Text))
+		}
+	}
+	if !hyph {
+		t.Errorf("no lines were hyphenated")
+	}
+
+	tsty.Hyphens = gist.HyphensManual
+	txt = layout("supercalifragi­listicexpialidocious", 120)
+	if len(txt.Spans) != 2 || !txt.Spans[0].Hyphen || txt.Spans[0].Text[len(txt.Spans[0].Text)-1] != SoftHyphen {
+		t.Errorf("not broken at soft hyphen: %v lines", len(txt.Spans))
+	}
+
+	tsty.WrapStyle = gist.WrapStylePretty
+	txt = layout(str, 150)
+	if len(txt.Spans) < 3 {
+		t.Errorf("pretty text not wrapped: %v lines", len(txt.Spans))
+	}
+	for _, sr := range txt.Spans {
+		if ssz := sr.SizeHV(); sr.RelPos.X+ssz.X > 150 && strings.Contains(strings.TrimSpace(string(sr.Text)), " ") {
+			t.Errorf("pretty line too wide: %v %q", ssz.X, string(sr.Text))
+		}
+	}
+	tsty.WrapStyle = gist.WrapStyleAuto
+
+	tsty.HangingPunct = 1 << gist.HangFirst
+	txt = layout("“quoted” text", 300)
+	if txt.Spans[0].RelPos.X >= 0 {
+		t.Errorf("opening quote not hanging: %v", txt.Spans[0].RelPos.X)
+	}
+}
+
+func TestVector(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+
+	imgsz := image.Point{X: 320, Y: 240}
+	szrec := image.Rectangle{Max: imgsz}
+	img := image.NewRGBA(szrec)
+	rs := &State{}
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(imgsz)
+	rs.Init(imgsz.X, imgsz.Y, img)
+	rs.Vector = NewVector(imgsz)
+	rs.PushBounds(szrec)
+	rs.Lock()
+
+	blk, _ := gist.ColorFromName("black")
+	pc.StrokeStyle.SetColor(blk)
+	pc.FillStyle.Color.SetString("linear-gradient(red, blue)", nil)
+	pc.StrokeStyle.Width.SetDot(2)
+	pc.DrawRoundedRectangle(rs, 20, 20, 150, 100, 6)
+	pc.FillStrokeClear(rs)
+
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	fsty.Family = "DejaVu Sans"
+	txt := &Text{}
+	txt.SetHTML("Vector <b>text</b>", fsty, tsty, &pc.UnContext, nil)
+	txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: 200, Y: 40})
+	txt.Render(rs, mat32.Vec2{X: 30, Y: 150})
+	rs.Unlock()
+
+	vc := rs.Vector
+	ops := map[VectorOps]int{}
+	for _, op := range vc.Ops {
+		ops[op.Op]++
+	}
+	if ops[VecFill] == 0 || ops[VecStroke] == 0 || ops[VecGlyphs] == 0 {
+		t.Errorf("missing vector ops: %v", ops)
+	}
+
+	var pb strings.Builder
+	if err := vc.WritePDF(&pb, nil); err != nil {
+		t.Fatal(err)
+	}
+	pdf := pb.String()
+	for _, s := range []string{"%PDF-", "/FontFile2", "/ToUnicode", "/Identity-H", "/Subtype /Image", "%%EOF"} {
+		if !strings.Contains(pdf, s) {
+			t.Errorf("PDF is missing: %v", s)
+		}
+	}
+	for _, vf := range vc.Fonts {
+		gids := map[uint16]bool{}
+		for gid := range vf.Glyphs {
+			gids[gid] = true
+		}
+		sub, err := SubsetTTF(vf.Data, gids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := truetype.Parse(sub); err != nil {
+			t.Errorf("subset of %v does not parse: %v", vf.Name, err)
+		}
+		if len(sub) >= len(vf.Data) {
+			t.Errorf("subset of %v not smaller: %v >= %v", vf.Name, len(sub), len(vf.Data))
+		}
+	}
+
+	var sb strings.Builder
+	if err := vc.WriteSVG(&sb); err != nil {
+		t.Fatal(err)
+	}
+	svg := sb.String()
+	if !strings.Contains(svg, "<path") || !strings.Contains(svg, ">Vector</text>") && !strings.Contains(svg, ">V</text>") {
+		t.Errorf("SVG is missing paths or text")
+	}
+}
+
+func TestDisplayList(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+
+	imgsz := image.Point{X: 320, Y: 240}
+	img := image.NewRGBA(image.Rectangle{Max: imgsz})
+	rs := &State{}
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(imgsz)
+	rs.Init(imgsz.X, imgsz.Y, img)
+	rs.Lists = NewDisplayLists()
+	blu, _ := gist.ColorFromName("blue")
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	fsty.Family = "DejaVu Sans"
+	OpenFont(fsty, &pc.UnContext)
+
+	node := func(key string, bounds image.Rectangle, rad float32, label string) {
+		rs.PushBounds(bounds)
+		rs.PushList(key)
+		pc.FillStyle.SetColor(blu)
+		pc.StrokeStyle.SetColor(nil)
+		pc.DrawCircle(rs, float32(bounds.Min.X+20), float32(bounds.Min.Y+20), rad)
+		pc.FillStrokeClear(rs)
+		txt := &Text{}
+		txt.SetString(label, fsty, &pc.UnContext, tsty, true, 0, 1)
+		txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: 100, Y: 20})
+		txt.Render(rs, mat32.NewVec2FmPoint(bounds.Min).Add(mat32.Vec2{X: 45, Y: 10}))
+		rs.PopList()
+		rs.PopBounds()
+	}
+	ab := image.Rect(0, 0, 150, 50)
+	bb := image.Rect(0, 60, 150, 110)
+	node("a", ab, 15, "alpha")
+	node("b", bb, 15, "beta")
+	dirty := rs.Lists.TakeDirty()
+	ina, inb := false, false
+	for _, r := range dirty {
+		ina = ina || r.In(ab)
+		inb = inb || r.In(bb)
+	}
+	if !ina || !inb {
+		t.Errorf("first render should dirty both nodes: %v", dirty)
+	}
+	first := image.NewRGBA(img.Rect)
+	copy(first.Pix, img.Pix)
+
+	node("a", ab, 15, "alpha")
+	node("b", bb, 10, "beta")
+	dirty = rs.Lists.TakeDirty()
+	if len(dirty) != 1 || !dirty[0].In(bb) || dirty[0].Dx() > 30 {
+		t.Errorf("only the changed circle should be dirty: %v", dirty)
+	}
+
+	key, oi := rs.Lists.HitTest(image.Point{X: 20, Y: 80})
+	if key != "b" || oi != 0 {
+		t.Errorf("hit test in circle: %v %v", key, oi)
+	}
+	if key, _ := rs.Lists.HitTest(image.Point{X: 2, Y: 62}); key != nil {
+		t.Errorf("hit test outside circle: %v", key)
+	}
+
+	// replaying a list at new bounds draws the same pixels there
+	da := rs.Lists.Lists["a"]
+	rimg := image.NewRGBA(image.Rectangle{Max: imgsz})
+	rrs := &State{}
+	rrs.Init(imgsz.X, imgsz.Y, rimg)
+	off := image.Point{X: 100, Y: 150}
+	da.Replay(rrs, ab.Add(off))
+	ndiff := 0
+	for y := ab.Min.Y; y < ab.Max.Y; y++ {
+		for x := ab.Min.X; x < ab.Max.X; x++ {
+			a, b := first.RGBAAt(x, y), rimg.RGBAAt(x+off.X, y+off.Y)
+			if d := int(a.A) - int(b.A); d > 8 || d < -8 {
+				ndiff++
+			}
+		}
+	}
+	if ndiff > 20 {
+		t.Errorf("replayed list differs in %v pixels", ndiff)
+	}
+}
+
+func TestTiles(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+
+	imgsz := image.Point{X: 320, Y: 240}
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	fsty.Family = "DejaVu Sans"
+	blu, _ := gist.ColorFromName("blue")
+	red, _ := gist.ColorFromName("red")
+	wht, _ := gist.ColorFromName("white")
+
+	render := func(rs *State) {
+		pc := &Paint{}
+		pc.Defaults()
+		pc.SetUnitContextExt(imgsz)
+		OpenFont(fsty, &pc.UnContext)
+		rs.PushBounds(image.Rectangle{Max: imgsz})
+		pc.FillBoxColor(rs, mat32.Vec2{}, mat32.NewVec2FmPoint(imgsz), wht)
+		for i := 0; i < 8; i++ {
+			pc.FillStyle.SetColor(blu)
+			pc.StrokeStyle.SetColor(red)
+			pc.StrokeStyle.Width.Dots = 3
+			pc.DrawCircle(rs, float32(30+i*37), float32(40+i*23), 35)
+			pc.FillStrokeClear(rs)
+		}
+		txt := &Text{}
+		txt.SetString("tiles of text across the tile edges", fsty, &pc.UnContext, tsty, true, 0, 1)
+		txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: 300, Y: 40})
+		txt.Render(rs, mat32.Vec2{X: 10, Y: 58})
+		rs.PopBounds()
+	}
+
+	simg := image.NewRGBA(image.Rectangle{Max: imgsz})
+	srs := &State{}
+	srs.Init(imgsz.X, imgsz.Y, simg)
+	render(srs)
+
+	timg := image.NewRGBA(image.Rectangle{Max: imgsz})
+	trs := &State{}
+	trs.Init(imgsz.X, imgsz.Y, timg)
+	trs.Vector = NewTileVector(imgsz)
+	render(trs)
+	for _, c := range timg.Pix {
+		if c != 0 {
+			t.Fatalf("deferred painting should not render into the image")
+		}
+	}
+	nglyph := 0
+	for _, op := range trs.Vector.Ops {
+		for _, g := range op.Glyphs {
+			if g.face != fsty.Face.Face {
+				t.Fatalf("glyph %q not recorded with the face it was rendered with", g.Text)
+			}
+			nglyph++
+		}
+	}
+	if nglyph == 0 {
+		t.Errorf("no glyphs recorded")
+	}
+	trs.Vector.RasterTiles(timg, timg.Rect, 64)
+
+	ndiff := 0
+	for i := range simg.Pix {
+		if d := int(simg.Pix[i]) - int(timg.Pix[i]); d > 8 || d < -8 {
+			ndiff++
+		}
+	}
+	if ndiff > 20 {
+		t.Errorf("tiled rendering differs in %v values", ndiff)
+	}
+}
+
+func TestGlyphCache(t *testing.T) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	FontLibrary.Init()
+
+	imgsz := image.Point{X: 320, Y: 80}
+	pc := &Paint{}
+	pc.Defaults()
+	pc.SetUnitContextExt(imgsz)
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	fsty.Family = "DejaVu Sans"
+	OpenFont(fsty, &pc.UnContext)
+
+	render := func() *image.RGBA {
+		img := image.NewRGBA(image.Rectangle{Max: imgsz})
+		rs := &State{}
+		rs.Init(imgsz.X, imgsz.Y, img)
+		rs.PushBounds(img.Rect)
+		txt := &Text{}
+		txt.SetString("cached glyphs, cached glyphs", fsty, &pc.UnContext, tsty, true, 0, 1)
+		txt.LayoutStdLR(tsty, fsty, &pc.UnContext, mat32.Vec2{X: 300, Y: 40})
+		txt.Render(rs, mat32.Vec2{X: 10.3, Y: 30})
+		rs.PopBounds()
+		return img
+	}
+
+	TheGlyphCache.Reset()
+	st0 := TheGlyphCache.Stats()
+	img1 := render()
+	st1 := TheGlyphCache.Stats()
+	if st1.Misses == st0.Misses || st1.Hits == st0.Hits || st1.Glyphs == 0 {
+		t.Errorf("first render should rasterize each glyph once and reuse repeated ones: %v", st1)
+	}
+	img2 := render()
+	st2 := TheGlyphCache.Stats()
+	if st2.Misses != st1.Misses || st2.Hits <= st1.Hits {
+		t.Errorf("second render should only use cached glyphs: %v", st2)
+	}
+	if !bytes.Equal(img1.Pix, img2.Pix) {
+		t.Errorf("rendering from the cache differs")
+	}
+
+	// the cached glyphs are the same as those of the face, at sub-pixel offsets
+	face := fsty.Face.Face
+	for _, x := range []fixed.Int26_6{640, 645, 660, 671} {
+		dot := fixed.Point26_6{X: x, Y: 1280}
+		cdr, cmask, cmp, cok := TheGlyphCache.Glyph(face, dot, 'g')
+		cimg := image.NewAlpha(cdr)
+		draw.Draw(cimg, cdr, cmask, cmp, draw.Src)
+		fdr, fmask, fmp, _, fok := face.Glyph(dot, 'g')
+		fimg := image.NewAlpha(fdr)
+		draw.Draw(fimg, fdr, fmask, fmp, draw.Src)
+		if !cok || !fok || cdr != fdr || !bytes.Equal(cimg.Pix, fimg.Pix) {
+			t.Errorf("cached glyph at %v differs from the face: %v %v", x, cdr, fdr)
+		}
+	}
+
+	// looking up only in the cache does not render with the face
+	ixf := face.(truetype.IndexableFace)
+	idot := fixed.Point26_6{X: 700, Y: 1280}
+	if _, _, _, _, cached := TheGlyphCache.CachedGlyphAtIndex(ixf, idot, 40); cached {
+		t.Errorf("glyph index should not be cached before it is rendered")
+	}
+	gdr, _, _, gok := TheGlyphCache.GlyphAtIndex(ixf, idot, 40)
+	cdr, _, _, cok, cached := TheGlyphCache.CachedGlyphAtIndex(ixf, idot, 40)
+	if !cached || cok != gok || cdr != gdr {
+		t.Errorf("glyph index should be cached once rendered: %v %v %v", cached, cdr, gdr)
+	}
+
+	TheGlyphCache.SetMaxBytes(2000)
+	st3 := TheGlyphCache.Stats()
+	if st3.Bytes > 2000 || st3.Evictions == 0 {
+		t.Errorf("cache should be within its max: %v", st3)
+	}
+
+	// faces whose glyphs are all evicted are removed too
+	fsty2 := *fsty
+	fsty2.Size.SetPt(31)
+	fsty2.Size.ToDots(&pc.UnContext)
+	OpenFont(&fsty2, &pc.UnContext)
+	TheGlyphCache.Glyph(fsty2.Face.Face, fixed.Point26_6{X: 640, Y: 1280}, 'g')
+	if st := TheGlyphCache.Stats(); st.Faces != 2 {
+		t.Errorf("cache should have glyphs of both faces: %v", st)
+	}
+	TheGlyphCache.SetMaxBytes(0) // keeps only the most recent glyph
+	if st := TheGlyphCache.Stats(); st.Glyphs != 1 || st.Faces != 1 {
+		t.Errorf("cache should only keep the face of the last glyph: %v", st)
+	}
+	st3 = TheGlyphCache.Stats()
+	TheGlyphCache.SetMaxBytes(GlyphCacheMaxBytes)
+
+	FontLibrary.UpdateFontsAvail()
+	if st4 := TheGlyphCache.Stats(); st4.Glyphs != 0 || st4.Resets != st3.Resets+1 {
+		t.Errorf("reloading fonts should reset the cache: %v", st4)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"container/list"
+	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+
+	"github.com/goki/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// glyphcache.go has the cache of rasterized glyph masks that is shared by
+// all of the text rendering, so the glyphs of text that is rendered again,
+// e.g., when scrolling, do not need to be rasterized again by the faces.
+
+// GlyphSubPixels is the number of sub-pixel positions of the glyphs in the
+// GlyphCache, horizontally -- vertically, glyphs are at whole pixels.
+// This is the same as the default of the truetype faces, so the cached
+// glyphs are the same as those rendered by the faces.
+const GlyphSubPixels = 4
+
+// GlyphCacheMaxBytes is the default maximum memory used by the glyphs in
+// the GlyphCache, in bytes
+var GlyphCacheMaxBytes = 8 << 20
+
+// TheGlyphCache is the glyph cache used for all text rendering
+var TheGlyphCache = NewGlyphCache(GlyphCacheMaxBytes)
+
+// GlyphCacheStats are the statistics of the use of a GlyphCache
+type GlyphCacheStats struct {
+	Hits      int64 `desc:"number of glyphs that were found in the cache"`
+	Misses    int64 `desc:"number of glyphs that were not found in the cache, and were rasterized by the face"`
+	Evictions int64 `desc:"number of glyphs that were removed from the cache to keep it within its maximum size"`
+	Resets    int64 `desc:"number of times the cache was reset, e.g., because the fonts were reloaded"`
+	Glyphs    int   `desc:"number of glyphs in the cache"`
+	Faces     int   `desc:"number of faces with glyphs in the cache"`
+	Bytes     int   `desc:"memory used by the glyphs in the cache, mostly by their alpha masks, in bytes"`
+	MaxBytes  int   `desc:"maximum memory used by the glyphs in the cache, in bytes"`
+}
+
+// String returns a summary of the stats, with the rate of hits
+func (gs GlyphCacheStats) String() string {
+	rate := 0.0
+	if n := gs.Hits + gs.Misses; n > 0 {
+		rate = 100 * float64(gs.Hits) / float64(n)
+	}
+	return fmt.Sprintf("glyphs: %d  faces: %d  bytes: %d / %d  hits: %d (%.1f%%)  misses: %d  evictions: %d  resets: %d",
+		gs.Glyphs, gs.Faces, gs.Bytes, gs.MaxBytes, gs.Hits, rate, gs.Misses, gs.Evictions, gs.Resets)
+}
+
+// glyphKey is the key of a glyph in the GlyphCache
+type glyphKey struct {
+	face  font.Face
+	size  fixed.Int26_6 // height of the face
+	glyph rune          // glyph index if index, else rune
+	index bool
+	subX  uint8 // sub-pixel position, in 1 / GlyphSubPixels of a pixel
+}
+
+// glyphEntry is a glyph in the GlyphCache
+type glyphEntry struct {
+	key  glyphKey
+	dr   image.Rectangle // relative to the whole-pixel position of the dot
+	mask *image.Alpha    // at the origin
+	ok   bool
+}
+
+// glyphEntryBytes is the approximate memory used by a glyph in the
+// GlyphCache, in addition to its mask
+const glyphEntryBytes = 160
+
+// bytes returns the approximate memory used by the glyph
+func (ge *glyphEntry) bytes() int {
+	if ge.mask == nil {
+		return glyphEntryBytes
+	}
+	return glyphEntryBytes + len(ge.mask.Pix)
+}
+
+// at returns the glyph with the dot at given whole-pixel position
+func (ge *glyphEntry) at(ip image.Point) (image.Rectangle, image.Image, image.Point, bool) {
+	if !ge.ok {
+		return image.Rectangle{}, nil, image.Point{}, false
+	}
+	return ge.dr.Add(ip), ge.mask, image.Point{}, true
+}
+
+// glyphFace is a face with glyphs in the GlyphCache
+type glyphFace struct {
+	size fixed.Int26_6 // height of the face
+	n    int           // number of glyphs of the face in the cache
+}
+
+// GlyphCache is a cache of the alpha masks of rasterized glyphs, by face,
+// size, rune or glyph index, and sub-pixel position, which is limited to a
+// maximum memory -- the glyphs that were used least recently are removed
+// to stay within it.  It is safe for concurrent use.
+type GlyphCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	glyphs   map[glyphKey]*list.Element
+	lru      list.List // front is most recently used
+	faces    map[font.Face]*glyphFace
+	stats    GlyphCacheStats
+}
+
+// NewGlyphCache returns a new glyph cache with given maximum memory used
+// by the glyphs, in bytes
+func NewGlyphCache(maxBytes int) *GlyphCache {
+	gc := &GlyphCache{maxBytes: maxBytes}
+	gc.glyphs = make(map[glyphKey]*list.Element)
+	gc.faces = make(map[font.Face]*glyphFace)
+	return gc
+}
+
+// Glyph returns the glyph of given rune in given face at given dot, as
+// face.Glyph does, from the cache if it is there.  The mask must not be
+// modified.
+func (gc *GlyphCache) Glyph(face font.Face, dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, ok bool) {
+	return gc.glyph(face, dot, r, false)
+}
+
+// GlyphAtIndex returns the glyph of given glyph index in given face at
+// given dot, as face.GlyphAtIndex does, from the cache if it is there.
+// The mask must not be modified.
+func (gc *GlyphCache) GlyphAtIndex(face truetype.IndexableFace, dot fixed.Point26_6, idx truetype.Index) (dr image.Rectangle, mask image.Image, maskp image.Point, ok bool) {
+	return gc.glyph(face, dot, rune(idx), true)
+}
+
+// CachedGlyphAtIndex returns the glyph of given glyph index in given face
+// at given dot, as GlyphAtIndex does, only if it is already in the cache,
+// with cached false otherwise.  It never renders with the face, so it can
+// be called without the lock that the face needs (TextFontRenderMu).
+func (gc *GlyphCache) CachedGlyphAtIndex(face truetype.IndexableFace, dot fixed.Point26_6, idx truetype.Index) (dr image.Rectangle, mask image.Image, maskp image.Point, ok, cached bool) {
+	qdot, ip := glyphDot(dot)
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gf, has := gc.faces[face]
+	if !has {
+		return
+	}
+	key := glyphKey{face: face, size: gf.size, glyph: rune(idx), index: true, subX: glyphSubX(qdot)}
+	el, has := gc.glyphs[key]
+	if !has {
+		return
+	}
+	gc.stats.Hits++
+	gc.lru.MoveToFront(el)
+	dr, mask, maskp, ok = el.Value.(*glyphEntry).at(ip)
+	return dr, mask, maskp, ok, true
+}
+
+// glyphDot returns given dot quantized as in the truetype faces, to the
+// sub-pixel positions horizontally and whole pixels vertically, and its
+// whole pixel position
+func glyphDot(dot fixed.Point26_6) (fixed.Point26_6, image.Point) {
+	const subQ = 64 / GlyphSubPixels
+	qd := fixed.Point26_6{X: (dot.X + subQ/2) &^ (subQ - 1), Y: (dot.Y + 32) &^ 63}
+	return qd, image.Point{X: int(qd.X >> 6), Y: int(qd.Y >> 6)}
+}
+
+// glyphSubX returns the sub-pixel position of given quantized dot
+func glyphSubX(qd fixed.Point26_6) uint8 {
+	return uint8((qd.X & 63) / (64 / GlyphSubPixels))
+}
+
+func (gc *GlyphCache) glyph(face font.Face, dot fixed.Point26_6, glyph rune, index bool) (image.Rectangle, image.Image, image.Point, bool) {
+	qdot, ip := glyphDot(dot)
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gf, has := gc.faces[face]
+	if !has {
+		gf = &glyphFace{size: face.Metrics().Height}
+		gc.faces[face] = gf
+	}
+	key := glyphKey{face: face, size: gf.size, glyph: glyph, index: index, subX: glyphSubX(qdot)}
+	if el, has := gc.glyphs[key]; has {
+		gc.stats.Hits++
+		gc.lru.MoveToFront(el)
+		return el.Value.(*glyphEntry).at(ip)
+	}
+	gc.stats.Misses++
+	var dr image.Rectangle
+	var mask image.Image
+	var maskp image.Point
+	var ok bool
+	if index {
+		dr, mask, maskp, _, ok = face.(truetype.IndexableFace).GlyphAtIndex(qdot, truetype.Index(glyph))
+	} else {
+		dr, mask, maskp, _, ok = face.Glyph(qdot, glyph)
+	}
+	ge := &glyphEntry{key: key, ok: ok}
+	if ok {
+		// the mask of the face is only valid until it renders another glyph
+		ge.mask = image.NewAlpha(image.Rectangle{Max: dr.Size()})
+		draw.Draw(ge.mask, ge.mask.Rect, mask, maskp, draw.Src)
+		ge.dr = dr.Sub(ip)
+	}
+	gc.glyphs[key] = gc.lru.PushFront(ge)
+	gf.n++
+	gc.stats.Bytes += ge.bytes()
+	gc.evict()
+	return ge.at(ip)
+}
+
+// evict removes the least recently used glyphs until they are within
+// the maximum bytes, and the faces that no longer have any glyphs --
+// must be called under the lock
+func (gc *GlyphCache) evict() {
+	for gc.stats.Bytes > gc.maxBytes && gc.lru.Len() > 1 {
+		el := gc.lru.Back()
+		ge := el.Value.(*glyphEntry)
+		gc.lru.Remove(el)
+		delete(gc.glyphs, ge.key)
+		if gf := gc.faces[ge.key.face]; gf != nil {
+			gf.n--
+			if gf.n <= 0 {
+				delete(gc.faces, ge.key.face)
+			}
+		}
+		gc.stats.Bytes -= ge.bytes()
+		gc.stats.Evictions++
+	}
+}
+
+// MaxBytes returns the maximum memory used by the glyphs, in bytes
+func (gc *GlyphCache) MaxBytes() int {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	return gc.maxBytes
+}
+
+// SetMaxBytes sets the maximum memory used by the glyphs, in bytes,
+// removing the least recently used glyphs if they no longer fit
+func (gc *GlyphCache) SetMaxBytes(maxBytes int) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gc.maxBytes = maxBytes
+	gc.evict()
+}
+
+// Reset removes all of the glyphs from the cache, e.g., when the fonts are
+// reloaded, so the faces are no longer used
+func (gc *GlyphCache) Reset() {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gc.glyphs = make(map[glyphKey]*list.Element)
+	gc.lru.Init()
+	gc.faces = make(map[font.Face]*glyphFace)
+	gc.stats.Bytes = 0
+	gc.stats.Resets++
+}
+
+// Stats returns the current statistics of the cache
+func (gc *GlyphCache) Stats() GlyphCacheStats {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	st := gc.stats
+	st.Glyphs = gc.lru.Len()
+	st.Faces = len(gc.faces)
+	st.MaxBytes = gc.maxBytes
+	return st
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+	"golang.org/x/image/font"
+)
+
+// SoftHyphen is the soft hyphen rune (&shy;), which marks a position where a
+// word can be hyphenated, and is only rendered (as a hyphen) if the line is
+// broken there
+const SoftHyphen = '\u00AD'
+
+// Hyphenator finds the positions where words can be hyphenated, using
+// Liang's algorithm (as used in TeX) with the hyphenation patterns of a
+// language
+type Hyphenator struct {
+	Lang     string `desc:"language of the patterns, e.g., en-us"`
+	LeftMin  int    `desc:"minimum number of runes in a word before a hyphen"`
+	RightMin int    `desc:"minimum number of runes in a word after a hyphen"`
+
+	patterns   map[string][]uint8
+	maxLen     int
+	exceptions map[string][]int
+}
+
+// NewHyphenator returns a new Hyphenator for given language, with given
+// patterns and exceptions in the TeX format (see AddPatterns, AddExceptions)
+func NewHyphenator(lang, patterns, exceptions string) *Hyphenator {
+	hy := &Hyphenator{Lang: lang, LeftMin: 2, RightMin: 3}
+	hy.AddPatterns(patterns)
+	hy.AddExceptions(exceptions)
+	return hy
+}
+
+// AddPatterns adds given white-space separated hyphenation patterns, in the
+// TeX format: letters with digits between them, where odd digits allow a
+// hyphen and even digits prevent one, and . marks the start or end of a word,
+// e.g., .un1 1tion n2t
+func (hy *Hyphenator) AddPatterns(patterns string) {
+	if hy.patterns == nil {
+		hy.patterns = make(map[string][]uint8)
+	}
+	for _, pat := range strings.Fields(patterns) {
+		var ltrs []rune
+		vals := []uint8{0}
+		for _, r := range pat {
+			if r >= '0' && r <= '9' {
+				vals[len(vals)-1] = uint8(r - '0')
+				continue
+			}
+			ltrs = append(ltrs, unicode.ToLower(r))
+			vals = append(vals, 0)
+		}
+		if len(ltrs) == 0 {
+			continue
+		}
+		hy.patterns[string(ltrs)] = vals
+		if len(ltrs) > hy.maxLen {
+			hy.maxLen = len(ltrs)
+		}
+	}
+}
+
+// AddExceptions adds given white-space separated words with their hyphens
+// given explicitly, e.g., ta-ble, which are used instead of the patterns
+func (hy *Hyphenator) AddExceptions(exceptions string) {
+	if hy.exceptions == nil {
+		hy.exceptions = make(map[string][]int)
+	}
+	for _, ex := range strings.Fields(exceptions) {
+		var ltrs []rune
+		var hyps []int
+		for _, r := range ex {
+			if r == '-' {
+				hyps = append(hyps, len(ltrs))
+				continue
+			}
+			ltrs = append(ltrs, unicode.ToLower(r))
+		}
+		hy.exceptions[string(ltrs)] = hyps
+	}
+}
+
+// Hyphenate returns the positions in given word where it can be
+// hyphenated, as the index of the rune after the hyphen, in increasing order
+func (hy *Hyphenator) Hyphenate(word []rune) []int {
+	n := len(word)
+	if n < hy.LeftMin+hy.RightMin {
+		return nil
+	}
+	lw := make([]rune, n+2)
+	lw[0], lw[n+1] = '.', '.'
+	for i, r := range word {
+		lw[i+1] = unicode.ToLower(r)
+	}
+	if hyps, has := hy.exceptions[string(lw[1:n+1])]; has {
+		return hyps
+	}
+	pts := make([]uint8, n+3)
+	for i := range lw {
+		for j := i + 1; j <= len(lw) && j-i <= hy.maxLen; j++ {
+			vals, has := hy.patterns[string(lw[i:j])]
+			if !has {
+				continue
+			}
+			for k, v := range vals {
+				if v > pts[i+k] {
+					pts[i+k] = v
+				}
+			}
+		}
+	}
+	var hyps []int
+	for i := hy.LeftMin; i <= n-hy.RightMin; i++ {
+		if pts[i+1]%2 == 1 { // pts index is offset by the leading .
+			hyps = append(hyps, i)
+		}
+	}
+	return hyps
+}
+
+// ParseHyphenTeX returns the patterns and exceptions from the contents of a
+// TeX hyphenation file, with \patterns{...} and \hyphenation{...} sections
+func ParseHyphenTeX(src string) (patterns, exceptions string) {
+	var lines []string
+	for _, ln := range strings.Split(src, "\n") {
+		if ci := strings.Index(ln, "%"); ci >= 0 {
+			ln = ln[:ci]
+		}
+		lines = append(lines, ln)
+	}
+	src = strings.Join(lines, "\n")
+	section := func(cmd string) string {
+		st := strings.Index(src, cmd+"{")
+		if st < 0 {
+			return ""
+		}
+		st += len(cmd) + 1
+		ed := strings.Index(src[st:], "}")
+		if ed < 0 {
+			return src[st:]
+		}
+		return src[st : st+ed]
+	}
+	return section(`\patterns`), section(`\hyphenation`)
+}
+
+// HyphenPaths are the directories searched for the hyphenation patterns of
+// a language, in the file names used by the hyph-utf8 package: either
+// hyph-<lang>.pat.txt with the patterns and hyph-<lang>.hyp.txt with the
+// exceptions, or a TeX file hyph-<lang>.tex
+var HyphenPaths = []string{
+	"/usr/share/texlive/texmf-dist/tex/generic/hyph-utf8/patterns/txt",
+	"/usr/share/texlive/texmf-dist/tex/generic/hyph-utf8/patterns/tex",
+	"/usr/share/texmf/tex/generic/hyph-utf8/patterns/txt",
+	"/usr/share/texmf/tex/generic/hyph-utf8/patterns/tex",
+	"/usr/local/share/hyph-utf8",
+}
+
+// DefaultHyphenLang is the language used for hyphenation when the text does
+// not specify one
+var DefaultHyphenLang = "en-us"
+
+// Hyphenators are the hyphenators for each language (lower case), loaded as
+// needed by HyphenatorForLang -- nil if none was found
+var Hyphenators = map[string]*Hyphenator{}
+
+// hyphenatorsMu protects the Hyphenators
+var hyphenatorsMu sync.Mutex
+
+// HyphenatorForLang returns the hyphenator for given language, e.g., en-us
+// (using DefaultHyphenLang if empty), loading its patterns from the
+// HyphenPaths if needed, and trying the base language (e.g., en) if there
+// are none for the specific one -- english uses built-in patterns if none
+// are found.  Returns nil if there are no patterns for the language.
+func HyphenatorForLang(lang string) *Hyphenator {
+	if lang == "" {
+		lang = DefaultHyphenLang
+	}
+	lang = strings.ToLower(strings.Replace(lang, "_", "-", -1))
+	hyphenatorsMu.Lock()
+	defer hyphenatorsMu.Unlock()
+	if hy, has := Hyphenators[lang]; has {
+		return hy
+	}
+	hy, err := LoadHyphenator(lang)
+	if err != nil {
+		if di := strings.Index(lang, "-"); di > 0 {
+			hy, err = LoadHyphenator(lang[:di])
+		}
+	}
+	if err != nil && strings.HasPrefix(lang, "en") {
+		hy = NewHyphenator(lang, HyphenPatternsEn, HyphenExceptionsEn)
+	}
+	Hyphenators[lang] = hy
+	return hy
+}
+
+// LoadHyphenator loads the hyphenation patterns for given language from
+// the HyphenPaths
+func LoadHyphenator(lang string) (*Hyphenator, error) {
+	for _, dir := range HyphenPaths {
+		fn := filepath.Join(dir, "hyph-"+lang)
+		if pats, err := os.ReadFile(fn + ".pat.txt"); err == nil {
+			exc, _ := os.ReadFile(fn + ".hyp.txt")
+			return NewHyphenator(lang, string(pats), string(exc)), nil
+		}
+		if src, err := os.ReadFile(fn + ".tex"); err == nil {
+			pats, exc := ParseHyphenTeX(string(src))
+			return NewHyphenator(lang, pats, exc), nil
+		}
+	}
+	return nil, fmt.Errorf("girl.LoadHyphenator: no hyphenation patterns found for language: %v in paths: %v", lang, HyphenPaths)
+}
+
+// HyphenPatternsEn is a small built-in set of english hyphenation patterns,
+// for common prefixes, suffixes and doubled consonants, used if the full
+// patterns are not found in the HyphenPaths -- it finds fewer hyphenation
+// points than the full patterns, but rarely a wrong one
+var HyphenPatternsEn = `
+.anti1 .com1 .con1 .counter1 .dis1 .inter1 .inter2es .mis1 .non1 .over1
+.st2i .sub1 .sub2tl .super1 .th2in .trans1 .un1 .un2i .under1
+1body 1cial 1cious 1ful 1graph 1hood 1less 1ment 1ness 1phone 1self
+1selves 1ship 1sion 1thing 1tial 1tion 1tious 1ture 1ward 1where
+b1b c1c d1d f1f g1g l1l m1m n1n p1p r1r s1s t1t z1z ck1
+bb2ing dd2ing gg2ing ll2ing mm2ing nn2ing pp2ing rr2ing ss2ing tt2ing
+d1ing k1ing l1ing n1ing t1ing
+`
+
+// HyphenExceptionsEn are the exceptions for HyphenPatternsEn
+var HyphenExceptionsEn = `
+cloth-ing
+`
+
+// WordBreak is a position within a word of a span where a line can be
+// broken
+type WordBreak struct {
+	Pos    int  `desc:"index of the rune that starts the next line"`
+	Hyphen bool `desc:"a hyphen is rendered at the end of the line, for a soft hyphen or hyphenation point -- false after an existing hyphen"`
+}
+
+// WordBreaksLR returns the positions within the words of the span where
+// lines can be broken, in increasing order: after hyphens and soft hyphens,
+// and at the hyphenation points found by given hyphenator (if non-nil), for
+// words without soft hyphens.  Right-to-left text is not hyphenated.
+func (sr *Span) WordBreaksLR(hy *Hyphenator) []WordBreak {
+	var brks []WordBreak
+	sz := len(sr.Text)
+	isLetter := func(i int) bool {
+		return i >= 0 && i < sz && unicode.IsLetter(sr.Text[i]) && !IsRTLRune(sr.Text[i])
+	}
+	st := 0
+	for st < sz {
+		for st < sz && unicode.IsSpace(sr.Text[st]) {
+			st++
+		}
+		ed := st
+		soft := false
+		for ed < sz && !unicode.IsSpace(sr.Text[ed]) {
+			if sr.Text[ed] == SoftHyphen {
+				soft = true
+			}
+			ed++
+		}
+		for i := st; i < ed; i++ {
+			switch r := sr.Text[i]; {
+			case r == SoftHyphen && i > st && i < ed-1:
+				brks = append(brks, WordBreak{Pos: i + 1, Hyphen: true})
+			case (r == '-' || r == '\u2010') && isLetter(i-1) && isLetter(i+1):
+				brks = append(brks, WordBreak{Pos: i + 1})
+			case hy != nil && !soft && isLetter(i) && !isLetter(i-1):
+				we := i
+				for isLetter(we) {
+					we++
+				}
+				for _, hp := range hy.Hyphenate(sr.Text[i:we]) {
+					brks = append(brks, WordBreak{Pos: i + hp, Hyphen: true})
+				}
+			}
+		}
+		st = ed
+	}
+	return brks
+}
+
+// FindHyphenPosLR returns the position at which to break the span into a
+// line within given target width that is longer than the one ending at
+// given wrap position from FindWrapPosLR, by breaking the next word at one
+// of given word breaks (from WordBreaksLR) -- returns -1 if there is none.
+func (sr *Span) FindHyphenPosLR(trgSize float32, wp int, brks []WordBreak) (int, bool) {
+	if len(brks) == 0 || sr.Dir == gist.RLTB {
+		return -1, false
+	}
+	posAfter := sr.logicalPosFunc()
+	hyw := sr.HyphenWidthAt(brks[len(brks)-1].Pos - 1)
+	best := -1
+	if wp > 0 && wp < len(sr.Text) {
+		ed := wp
+		for ed > 0 && unicode.IsSpace(sr.Text[ed-1]) {
+			ed--
+		}
+		if ed == 0 || sr.RelPos.X+posAfter(ed-1) <= trgSize {
+			best = wp
+		}
+	}
+	hyph := false
+	for _, br := range brks {
+		if br.Pos <= best || br.Pos >= len(sr.Text)-1 {
+			continue
+		}
+		w := sr.RelPos.X + posAfter(br.Pos-1)
+		if br.Hyphen {
+			w += hyw
+		}
+		if w > trgSize {
+			break
+		}
+		best = br.Pos
+		hyph = br.Hyphen
+	}
+	if best == wp {
+		return -1, false
+	}
+	return best, hyph
+}
+
+// logicalPosFunc returns a function for the position after each rune in
+// logical order, for LR text
+func (sr *Span) logicalPosFunc() func(i int) float32 {
+	var lpos []float32
+	if len(sr.Glyphs) > 0 { // shaped runes can be in a different visual order
+		lpos = sr.LogicalPosLR()
+	}
+	return func(i int) float32 {
+		if i < 0 {
+			return 0
+		}
+		if lpos != nil {
+			return lpos[i]
+		}
+		return sr.Render[i].RelPosAfterLR()
+	}
+}
+
+// faceAt returns the font face for the rune at given index
+func (sr *Span) faceAt(idx int) font.Face {
+	for i := ints.MinInt(idx, len(sr.Render)-1); i >= 0; i-- {
+		if sr.Render[i].Face != nil {
+			return sr.Render[i].Face
+		}
+	}
+	return nil
+}
+
+// HyphenWidthAt returns the width of a hyphen in the font face of the rune
+// at given index
+func (sr *Span) HyphenWidthAt(idx int) float32 {
+	TextFontRenderMu.Lock()
+	defer TextFontRenderMu.Unlock()
+	return sr.hyphenWidthAt(idx)
+}
+
+// hyphenWidthAt is HyphenWidthAt under TextFontRenderMu
+func (sr *Span) hyphenWidthAt(idx int) float32 {
+	face := sr.faceAt(idx)
+	if face == nil {
+		return 0
+	}
+	a, _ := face.GlyphAdvance('-')
+	return mat32.FromFixed(a)
+}
+
+// hyphenWidth returns the width of the hyphen at the end of the span if it
+// is Hyphen, else 0 -- under TextFontRenderMu
+func (sr *Span) hyphenWidth() float32 {
+	if !sr.Hyphen || len(sr.Text) == 0 {
+		return 0
+	}
+	return sr.hyphenWidthAt(len(sr.Text) - 1)
+}
+
+// RenderHyphen renders the hyphen at the end of a Hyphen span, at the end
+// of its text -- under TextFontRenderMu
+func (sr *Span) RenderHyphen(rs *State, d *font.Drawer, tpos mat32.Vec2) {
+	li := len(sr.Text) - 1
+	face, clr := sr.LastFont()
+	hyw := sr.hyphenWidthAt(li)
+	rr := &(sr.Render[li])
+	rp := tpos.Add(mat32.Vec2{X: sr.LastPos.X - hyw, Y: rr.RelPos.Y})
+	if !rr.InBounds(rs, face, rp) {
+		return
+	}
+	hd := *d
+	hd.Face = face
+	hd.Src = image.NewUniform(clr)
+	hd.Dot = rp.Fixed()
+	dr, mask, maskp, ok := TheGlyphCache.Glyph(face, hd.Dot, '-')
+	if !ok {
+		return
+	}
+	if rs.Vector != nil {
+		rr.vectorGlyph(rs, face, '-', -1, "-", rp, clr, dr, mask, maskp)
+	}
+	rr.DrawGlyph(rs, &hd, rp, dr, mask, maskp)
+}
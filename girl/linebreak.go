@@ -0,0 +1,261 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"sort"
+	"unicode"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+)
+
+// linebreak.go has the paragraph-level layout of wrapped lines: optimal
+// line breaking, justification and hanging punctuation
+
+// LineBreakParams are the parameters of the Knuth-Plass optimal line
+// breaking in BreakLinesPrettyLR, with the values used by TeX
+type LineBreakParams struct {
+	Tolerance     float32 `desc:"maximum badness of a line before it is only used if there is no other way to break the paragraph"`
+	HyphenPenalty float32 `desc:"penalty for breaking a line at a hyphenation point"`
+	DoubleHyphen  float32 `desc:"extra demerits for two hyphenated lines in a row"`
+	LinePenalty   float32 `desc:"penalty added to the badness of each line, which favors fewer lines"`
+	RaggedStretch float32 `desc:"stretch of each line for text that is not justified, in multiples of the font height -- the line lengths vary within this without much badness"`
+}
+
+// TheLineBreakParams are the parameters used for optimal line breaking
+var TheLineBreakParams = LineBreakParams{Tolerance: 200, HyphenPenalty: 50, DoubleHyphen: 3000, LinePenalty: 10, RaggedStretch: 3}
+
+// lineBreakNode is a feasible break in the optimal line breaking
+type lineBreakNode struct {
+	brk      WordBreak
+	demerits float32
+	prev     int
+	bad      bool // a line up to this break exceeds the tolerance
+}
+
+// BreakLinesPrettyLR returns the positions at which to break the span, a
+// full paragraph, into lines that fit within given target width (the first
+// starting at sr.RelPos.X), with the breaks chosen for the paragraph as a
+// whole to minimize the total demerits of the lines, using the Knuth-Plass
+// algorithm.  The lines can break after spaces, and at given word breaks
+// (from WordBreaksLR, with a penalty for hyphens).  For justified text, the
+// spaces can stretch and shrink, and the demerits come from how much they
+// do -- otherwise from how much shorter than the width the lines are.  emsz
+// is the size of the font, for the stretch of ragged lines.
+func (sr *Span) BreakLinesPrettyLR(trgSize float32, justify bool, brks []WordBreak, emsz float32) []WordBreak {
+	sz := len(sr.Text)
+	if sz == 0 {
+		return nil
+	}
+	lp := &TheLineBreakParams
+	posAfter := sr.logicalPosFunc()
+	for i := 1; i < sz; i++ {
+		if unicode.IsSpace(sr.Text[i-1]) && !unicode.IsSpace(sr.Text[i]) {
+			brks = append(brks, WordBreak{Pos: i})
+		}
+	}
+	sort.Slice(brks, func(i, j int) bool {
+		return brks[i].Pos < brks[j].Pos
+	})
+	brks = append(brks, WordBreak{Pos: sz})
+	var hyw float32
+	if len(brks) > 1 {
+		hyw = sr.HyphenWidthAt(brks[0].Pos - 1)
+	}
+
+	// line returns the natural width, stretch and shrink of the line from st to ed
+	line := func(st int, ed WordBreak) (w, stretch, shrink float32) {
+		e := ed.Pos
+		for e > st && unicode.IsSpace(sr.Text[e-1]) {
+			e--
+		}
+		w = posAfter(e-1) - posAfter(st-1)
+		if ed.Hyphen {
+			w += hyw
+		}
+		var spc float32
+		for i := st; i < e; i++ {
+			if unicode.IsSpace(sr.Text[i]) {
+				spc += posAfter(i) - posAfter(i-1)
+			}
+		}
+		if justify {
+			return w, spc / 2, spc / 3
+		}
+		return w, lp.RaggedStretch * emsz, 0
+	}
+
+	lines, ok := sr.breakLines(trgSize, brks, line, lp.Tolerance)
+	if !ok { // second pass, as in TeX, if the paragraph cannot be set within tolerance
+		lines, _ = sr.breakLines(trgSize, brks, line, 10000)
+	}
+	return lines
+}
+
+// breakLines does the optimal line breaking for BreakLinesPrettyLR, with
+// given line function and tolerance for the badness of the lines, returning
+// false if any line exceeds the tolerance
+func (sr *Span) breakLines(trgSize float32, brks []WordBreak, line func(st int, ed WordBreak) (w, stretch, shrink float32), tolerance float32) ([]WordBreak, bool) {
+	lp := &TheLineBreakParams
+	sz := len(sr.Text)
+	nodes := []lineBreakNode{{prev: -1}}
+	for bi, br := range brks {
+		if bi > 0 && br.Pos == brks[bi-1].Pos {
+			continue
+		}
+		best := lineBreakNode{brk: br, prev: -1}
+		last := br.Pos == sz
+		for ni := len(nodes) - 1; ni >= 0; ni-- {
+			nd := &nodes[ni]
+			lsz := trgSize
+			if nd.brk.Pos == 0 {
+				lsz -= sr.RelPos.X
+			}
+			w, stretch, shrink := line(nd.brk.Pos, br)
+			var r float32 // adjustment ratio of the spaces
+			switch {
+			case w < lsz && last:
+				r = 0 // last line can be any length
+			case w < lsz:
+				r = 1000
+				if stretch > 0 {
+					r = (lsz - w) / stretch
+				}
+			case w > lsz:
+				r = -1000
+				if shrink > 0 {
+					r = (lsz - w) / shrink
+				}
+			}
+			overfull := r < -1
+			if overfull && ni < len(nodes)-1 {
+				break // earlier breaks are even more overfull
+			}
+			bad := float32(10000)
+			if !overfull {
+				bad = mat32.Min(100*mat32.Pow(mat32.Abs(r), 3), 10000)
+			}
+			if bad > tolerance && ni < len(nodes)-1 {
+				continue
+			}
+			dm := (lp.LinePenalty + bad) * (lp.LinePenalty + bad)
+			if br.Hyphen {
+				dm += lp.HyphenPenalty * lp.HyphenPenalty
+				if nd.brk.Hyphen {
+					dm += lp.DoubleHyphen
+				}
+			}
+			dm += nd.demerits
+			if best.prev < 0 || dm < best.demerits {
+				best.demerits = dm
+				best.prev = ni
+				best.bad = nd.bad || bad > tolerance
+			}
+		}
+		nodes = append(nodes, best)
+	}
+	var lines []WordBreak
+	for ni := nodes[len(nodes)-1].prev; ni > 0; ni = nodes[ni].prev {
+		lines = append(lines, nodes[ni].brk)
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines, !nodes[len(nodes)-1].bad
+}
+
+// JustifyLR justifies the span, a line of LR text, to fill given width, by
+// adding the extra space equally to each of the spaces between words
+// (trailing spaces are not counted) -- the spaces shrink if the line is
+// wider than the width.  Returns false if the line has no spaces to adjust.
+func (sr *Span) JustifyLR(trgSize float32) bool {
+	sz := len(sr.Text)
+	ed := sz
+	for ed > 0 && unicode.IsSpace(sr.Text[ed-1]) {
+		ed--
+	}
+	if ed == 0 {
+		return false
+	}
+	posAfter := sr.logicalPosFunc()
+	extra := trgSize - posAfter(ed-1)
+	if sr.Hyphen {
+		extra -= sr.HyphenWidthAt(ed - 1)
+	}
+	var spcs []float32 // visual positions of the spaces
+	for i := 0; i < ed; i++ {
+		if unicode.IsSpace(sr.Text[i]) {
+			spcs = append(spcs, sr.Render[i].RelPos.X)
+		}
+	}
+	if extra == 0 || len(spcs) == 0 {
+		return false
+	}
+	extra /= float32(len(spcs))
+	for i := range sr.Render {
+		rr := &(sr.Render[i])
+		n := 0
+		for _, sp := range spcs {
+			if sp < rr.RelPos.X {
+				n++
+			}
+		}
+		rr.RelPos.X += float32(n) * extra
+		if i < ed && unicode.IsSpace(sr.Text[i]) {
+			rr.Size.X += extra
+		}
+	}
+	sr.LastPos.X += float32(len(spcs)) * extra
+	return true
+}
+
+// IsOpenPunct returns true if given rune is an opening bracket or quote,
+// which can hang at the start of a paragraph
+func IsOpenPunct(r rune) bool {
+	return unicode.In(r, unicode.Ps, unicode.Pi) || r == '"' || r == '\''
+}
+
+// IsClosePunct returns true if given rune is a closing bracket or quote,
+// which can hang at the end of a paragraph
+func IsClosePunct(r rune) bool {
+	return unicode.In(r, unicode.Pe, unicode.Pf) || r == '"' || r == '\''
+}
+
+// IsStopComma returns true if given rune is a stop or comma, which can hang
+// at the end of each line
+func IsStopComma(r rune) bool {
+	switch r {
+	case '.', ',', '、', '。', '，', '．', '､', '｡', '٫', '٬', '۔':
+		return true
+	}
+	return false
+}
+
+// HangingPunctLR returns the widths of the punctuation that hangs outside
+// the start and end of the span, a line of LR text, per the
+// hanging-punctuation style -- paraStart and paraEnd are true if the line
+// is the first or last of a paragraph.  The end width includes any
+// trailing spaces after the hanging punctuation.
+func (sr *Span) HangingPunctLR(txtSty *gist.Text, paraStart, paraEnd bool) (st, ed float32) {
+	sz := len(sr.Text)
+	if txtSty.HangingPunct == 0 || sz == 0 || len(sr.Glyphs) > 0 {
+		return
+	}
+	if paraStart && txtSty.HasHangingPunct(gist.HangFirst) && IsOpenPunct(sr.Text[0]) {
+		st = sr.Render[0].Size.X
+	}
+	li := sz - 1
+	for li > 0 && unicode.IsSpace(sr.Text[li]) {
+		li--
+	}
+	r := sr.Text[li]
+	hang := paraEnd && txtSty.HasHangingPunct(gist.HangLast) && IsClosePunct(r)
+	hang = hang || ((txtSty.HasHangingPunct(gist.HangAllowEnd) || txtSty.HasHangingPunct(gist.HangForceEnd)) && IsStopComma(r))
+	if hang && li > 0 {
+		ed = sr.LastPos.X - sr.Render[li].RelPos.X
+	}
+	return
+}
@@ -113,6 +113,9 @@ func (pc *Paint) MoveTo(rs *State, x, y float32) {
 	}
 	p := pc.TransformPoint(rs, x, y)
 	rs.Path.Start(p.Fixed())
+	if rs.Vector != nil {
+		rs.vecPathAdd(PathMoveTo, p)
+	}
 	rs.Start = p
 	rs.Current = p
 	rs.HasCurrent = true
@@ -126,6 +129,9 @@ func (pc *Paint) LineTo(rs *State, x, y float32) {
 	} else {
 		p := pc.TransformPoint(rs, x, y)
 		rs.Path.Line(p.Fixed())
+		if rs.Vector != nil {
+			rs.vecPathAdd(PathLineTo, p)
+		}
 		rs.Current = p
 	}
 }
@@ -140,6 +146,9 @@ func (pc *Paint) QuadraticTo(rs *State, x1, y1, x2, y2 float32) {
 	p1 := pc.TransformPoint(rs, x1, y1)
 	p2 := pc.TransformPoint(rs, x2, y2)
 	rs.Path.QuadBezier(p1.Fixed(), p2.Fixed())
+	if rs.Vector != nil {
+		rs.vecPathAdd(PathQuadTo, p1, p2)
+	}
 	rs.Current = p2
 }
 
@@ -156,6 +165,9 @@ func (pc *Paint) CubicTo(rs *State, x1, y1, x2, y2, x3, y3 float32) {
 	d := pc.TransformPoint(rs, x3, y3)
 
 	rs.Path.CubeBezier(b.Fixed(), c.Fixed(), d.Fixed())
+	if rs.Vector != nil {
+		rs.vecPathAdd(PathCubicTo, b, c, d)
+	}
 	rs.Current = d
 }
 
@@ -164,6 +176,9 @@ func (pc *Paint) CubicTo(rs *State, x1, y1, x2, y2, x3, y3 float32) {
 func (pc *Paint) ClosePath(rs *State) {
 	if rs.HasCurrent {
 		rs.Path.Stop(true)
+		if rs.Vector != nil {
+			rs.vecPathAdd(PathClose)
+		}
 		rs.Current = rs.Start
 	}
 }
@@ -172,6 +187,7 @@ func (pc *Paint) ClosePath(rs *State) {
 // operation.
 func (pc *Paint) ClearPath(rs *State) {
 	rs.Path.Clear()
+	rs.VecPath = rs.VecPath[:0]
 	rs.HasCurrent = false
 }
 
@@ -187,37 +203,11 @@ func (pc *Paint) NewSubPath(rs *State) {
 // Path Drawing
 
 func (pc *Paint) capfunc() rasterx.CapFunc {
-	switch pc.StrokeStyle.Cap {
-	case gist.LineCapButt:
-		return rasterx.ButtCap
-	case gist.LineCapRound:
-		return rasterx.RoundCap
-	case gist.LineCapSquare:
-		return rasterx.SquareCap
-	case gist.LineCapCubic:
-		return rasterx.CubicCap
-	case gist.LineCapQuadratic:
-		return rasterx.QuadraticCap
-	}
-	return nil
+	return rasterCap(pc.StrokeStyle.Cap)
 }
 
 func (pc *Paint) joinmode() rasterx.JoinMode {
-	switch pc.StrokeStyle.Join {
-	case gist.LineJoinMiter:
-		return rasterx.Miter
-	case gist.LineJoinMiterClip:
-		return rasterx.MiterClip
-	case gist.LineJoinRound:
-		return rasterx.Round
-	case gist.LineJoinBevel:
-		return rasterx.Bevel
-	case gist.LineJoinArcs:
-		return rasterx.Arc
-	case gist.LineJoinArcsClip:
-		return rasterx.ArcClip
-	}
-	return rasterx.Arc
+	return rasterJoin(pc.StrokeStyle.Join)
 }
 
 // StrokeWidth obtains the current stoke width subject to transform (or not
@@ -268,14 +258,29 @@ func (pc *Paint) stroke(rs *State) {
 		mat32.ToFixed(pc.StrokeStyle.MiterLimit),
 		pc.capfunc(), nil, nil, pc.joinmode(), // todo: supports leading / trailing caps, and "gaps"
 		dash, 0)
-	rs.Scanner.SetClip(rs.Bounds)
-	rs.Path.AddTo(rs.Raster)
-	fbox := rs.Raster.Scanner.GetPathExtent()
-	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
-	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
-		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
-	rs.Raster.SetColor(pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm))
-	rs.Raster.Draw()
+	if rs.Deferred() { // only recorded, so only the bounding box of the path is needed
+		hw := int(mat32.Ceil(pc.StrokeWidth(rs) * mat32.Max(pc.StrokeStyle.MiterLimit, 1) / 2))
+		rs.LastRenderBBox = PathBBox(rs.VecPath).Inset(-hw)
+	} else {
+		rs.Scanner.SetClip(rs.Bounds)
+		rs.Path.AddTo(rs.Raster)
+		fbox := rs.Raster.Scanner.GetPathExtent()
+		// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
+		rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
+			Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
+	}
+	clr := pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm)
+	rs.Raster.SetColor(clr)
+	if !rs.Deferred() {
+		rs.Raster.Draw()
+	}
+	if rs.Vector != nil {
+		st := VecStrokeStyle{Width: pc.StrokeWidth(rs), MiterLimit: pc.StrokeStyle.MiterLimit, Cap: pc.StrokeStyle.Cap, Join: pc.StrokeStyle.Join}
+		for _, d := range dash {
+			st.Dashes = append(st.Dashes, float32(d))
+		}
+		rs.vecStroke(clr, st, pc.capfunc(), pc.joinmode(), dash)
+	}
 	rs.Raster.Clear()
 
 	/*
@@ -297,18 +302,24 @@ func (pc *Paint) fill(rs *State) {
 
 	rf := &rs.Raster.Filler
 	rf.SetWinding(pc.FillStyle.Rule == gist.FillRuleNonZero)
-	rs.Scanner.SetClip(rs.Bounds)
-	rs.Path.AddTo(rf)
-	fbox := rs.Scanner.GetPathExtent()
-	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
-	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
-		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
-	if pc.FillStyle.Color.Source == gist.RadialGradient {
-		rf.SetColor(pc.FillStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.FillStyle.Opacity, rs.LastRenderBBox, rs.XForm))
+	if rs.Deferred() { // only recorded, so only the bounding box of the path is needed
+		rs.LastRenderBBox = PathBBox(rs.VecPath)
 	} else {
-		rf.SetColor(pc.FillStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.FillStyle.Opacity, rs.LastRenderBBox, rs.XForm))
+		rs.Scanner.SetClip(rs.Bounds)
+		rs.Path.AddTo(rf)
+		fbox := rs.Scanner.GetPathExtent()
+		// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
+		rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
+			Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
+	}
+	clr := pc.FillStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.FillStyle.Opacity, rs.LastRenderBBox, rs.XForm)
+	rf.SetColor(clr)
+	if !rs.Deferred() {
+		rf.Draw()
+	}
+	if rs.Vector != nil {
+		rs.vecFill(clr, pc.FillStyle.Rule != gist.FillRuleNonZero)
 	}
-	rf.Draw()
 	rf.Clear()
 
 	/*
@@ -351,7 +362,12 @@ func (pc *Paint) Fill(rs *State) {
 func (pc *Paint) FillBox(rs *State, pos, size mat32.Vec2, clr *gist.ColorSpec) {
 	if clr.Source == gist.SolidColor {
 		b := rs.Bounds.Intersect(mat32.RectFromPosSizeMax(pos, size))
-		draw.Draw(rs.Image, b, &image.Uniform{clr.Color}, image.ZP, draw.Src)
+		if !rs.Deferred() {
+			draw.Draw(rs.Image, b, &image.Uniform{clr.Color}, image.ZP, draw.Src)
+		}
+		if rs.Vector != nil {
+			rs.vecRect(b, clr.Color)
+		}
 	} else {
 		pc.FillStyle.SetColorSpec(clr)
 		pc.DrawRectangle(rs, pos.X, pos.Y, size.X, size.Y)
@@ -362,7 +378,12 @@ func (pc *Paint) FillBox(rs *State, pos, size mat32.Vec2, clr *gist.ColorSpec) {
 // FillBoxColor is an optimized fill of a square region with given uniform color
 func (pc *Paint) FillBoxColor(rs *State, pos, size mat32.Vec2, clr color.Color) {
 	b := rs.Bounds.Intersect(mat32.RectFromPosSizeMax(pos, size))
-	draw.Draw(rs.Image, b, &image.Uniform{clr}, image.ZP, draw.Src)
+	if !rs.Deferred() {
+		draw.Draw(rs.Image, b, &image.Uniform{clr}, image.ZP, draw.Src)
+	}
+	if rs.Vector != nil {
+		rs.vecRect(b, clr)
+	}
 }
 
 // ClipPreserve updates the clipping region by intersecting the current
@@ -371,7 +392,13 @@ func (pc *Paint) FillBoxColor(rs *State, pos, size mat32.Vec2, clr color.Color)
 func (pc *Paint) ClipPreserve(rs *State) {
 	clip := image.NewAlpha(rs.Image.Bounds())
 	// painter := raster.NewAlphaOverPainter(clip) // todo!
+	vc := rs.Vector
+	rs.Vector = nil // the clip is recorded, not the fill
 	pc.fill(rs)
+	rs.Vector = vc
+	if vc != nil {
+		rs.vecClip(pc.FillStyle.Rule != gist.FillRuleNonZero)
+	}
 	if rs.Mask == nil {
 		rs.Mask = clip
 	} else { // todo: this one operation MASSIVELY slows down clip usage -- unclear why
@@ -413,6 +440,7 @@ func (pc *Paint) Clip(rs *State) {
 // ResetClip clears the clipping region.
 func (pc *Paint) ResetClip(rs *State) {
 	rs.Mask = nil
+	rs.VecClips = nil
 }
 
 //////////////////////////////////////////////////////////////////////////////////
@@ -421,7 +449,12 @@ func (pc *Paint) ResetClip(rs *State) {
 // Clear fills the entire image with the current fill color.
 func (pc *Paint) Clear(rs *State) {
 	src := image.NewUniform(&pc.FillStyle.Color.Color)
-	draw.Draw(rs.Image, rs.Image.Bounds(), src, image.ZP, draw.Src)
+	if !rs.Deferred() {
+		draw.Draw(rs.Image, rs.Image.Bounds(), src, image.ZP, draw.Src)
+	}
+	if rs.Vector != nil {
+		rs.vecRect(rs.Image.Bounds(), &pc.FillStyle.Color.Color)
+	}
 }
 
 // SetPixel sets the color of the specified pixel using the current stroke color.
@@ -492,6 +525,35 @@ func (pc *Paint) DrawRoundedRectangle(rs *State, x, y, w, h, r float32) {
 	pc.ClosePath(rs)
 }
 
+// DrawRoundedRectangleSides draws a rectangle with a different radius for
+// each corner, in the CSS border-radius order used in gist.SideFloats:
+// Top = top-left, Right = top-right, Bottom = bottom-right, Left = bottom-left.
+// Radii are limited to half of the smaller of the width and height.
+func (pc *Paint) DrawRoundedRectangleSides(rs *State, x, y, w, h float32, r gist.SideFloats) {
+	mr := 0.5 * mat32.Min(w, h)
+	tl, tr, br, bl := mat32.Min(r.Top, mr), mat32.Min(r.Right, mr), mat32.Min(r.Bottom, mr), mat32.Min(r.Left, mr)
+	x1, y1 := x+w, y+h
+	pc.NewSubPath(rs)
+	pc.MoveTo(rs, x+tl, y)
+	pc.LineTo(rs, x1-tr, y)
+	if tr > 0 {
+		pc.DrawArc(rs, x1-tr, y+tr, tr, mat32.DegToRad(270), mat32.DegToRad(360))
+	}
+	pc.LineTo(rs, x1, y1-br)
+	if br > 0 {
+		pc.DrawArc(rs, x1-br, y1-br, br, mat32.DegToRad(0), mat32.DegToRad(90))
+	}
+	pc.LineTo(rs, x+bl, y1)
+	if bl > 0 {
+		pc.DrawArc(rs, x+bl, y1-bl, bl, mat32.DegToRad(90), mat32.DegToRad(180))
+	}
+	pc.LineTo(rs, x, y+tl)
+	if tl > 0 {
+		pc.DrawArc(rs, x+tl, y+tl, tl, mat32.DegToRad(180), mat32.DegToRad(270))
+	}
+	pc.ClosePath(rs)
+}
+
 // DrawEllipticalArc draws arc between angle1 and angle2 along an ellipse,
 // using quadratic bezier curves -- centers of ellipse are at cx, cy with
 // radii rx, ry -- see DrawEllipticalArcPath for a version compatible with SVG
@@ -698,6 +760,12 @@ func (pc *Paint) DrawImageAnchored(rs *State, fmIm image.Image, x, y, ax, ay flo
 	transformer := draw.BiLinear
 	m := rs.XForm.Translate(x, y)
 	s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+	if rs.Vector != nil {
+		rs.vecImage(fmIm, m)
+	}
+	if rs.Deferred() {
+		return
+	}
 	if rs.Mask == nil {
 		transformer.Transform(rs.Image, s2d, fmIm, fmIm.Bounds(), draw.Over, nil)
 	} else {
@@ -719,6 +787,12 @@ func (pc *Paint) DrawImageScaled(rs *State, fmIm image.Image, x, y, w, h float32
 	transformer := draw.BiLinear
 	m := rs.XForm.Translate(x, y).Scale(isc.X, isc.Y)
 	s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+	if rs.Vector != nil {
+		rs.vecImage(fmIm, m)
+	}
+	if rs.Deferred() {
+		return
+	}
 	if rs.Mask == nil {
 		transformer.Transform(rs.Image, s2d, fmIm, fmIm.Bounds(), draw.Over, nil)
 	} else {
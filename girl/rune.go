@@ -6,11 +6,14 @@ package girl
 
 import (
 	"errors"
+	"image"
 	"image/color"
 
 	"github.com/goki/gi/gist"
 	"github.com/goki/mat32"
+	"golang.org/x/image/draw"
 	"golang.org/x/image/font"
+	"golang.org/x/image/math/f64"
 )
 
 // Rune contains fully explicit data needed for rendering a single rune
@@ -27,6 +30,7 @@ type Rune struct {
 	Size    mat32.Vec2           `desc:"size of the rune itself, exclusive of spacing that might surround it"`
 	RotRad  float32              `desc:"rotation in radians for this character, relative to its lower-left baseline rendering position"`
 	ScaleX  float32              `desc:"scaling of the X dimension, in case of non-uniform scaling, 0 = no separate scaling"`
+	Level   uint8                `desc:"unicode bidi embedding level -- even = left-to-right, odd = right-to-left -- see Span.SetBidiLevels"`
 }
 
 // HasNil returns error if any of the key info (face, color) is nil -- only
@@ -72,3 +76,77 @@ func (rr *Rune) RelPosAfterRL() float32 {
 func (rr *Rune) RelPosAfterTB() float32 {
 	return rr.RelPos.Y + rr.Size.Y
 }
+
+// InBounds returns true if the rune, rendered in given face at given
+// absolute position, is at least partly within the bounds of the render state
+func (rr *Rune) InBounds(rs *State, face font.Face, rp mat32.Vec2) bool {
+	dsc32 := mat32.FromFixed(face.Metrics().Descent)
+	scx := float32(1)
+	if rr.ScaleX != 0 {
+		scx = rr.ScaleX
+	}
+	tx := mat32.Scale2D(scx, 1).Rotate(rr.RotRad)
+	ll := rp.Add(tx.MulVec2AsVec(mat32.Vec2{X: 0, Y: dsc32}))
+	ur := ll.Add(tx.MulVec2AsVec(mat32.Vec2{X: rr.Size.X, Y: -rr.Size.Y}))
+	if int(mat32.Floor(ll.X)) > rs.Bounds.Max.X || int(mat32.Floor(ur.Y)) > rs.Bounds.Max.Y ||
+		int(mat32.Ceil(ur.X)) < rs.Bounds.Min.X || int(mat32.Ceil(ll.Y)) < rs.Bounds.Min.Y {
+		return false
+	}
+	return true
+}
+
+// DrawGlyph draws the given glyph mask, as returned from the font face for
+// absolute position rp, using the source color of given drawer, with the
+// rotation and scaling of this rune
+func (rr *Rune) DrawGlyph(rs *State, d *font.Drawer, rp mat32.Vec2, dr image.Rectangle, mask image.Image, maskp image.Point) {
+	if rs.Deferred() {
+		return
+	}
+	if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
+		idr := dr.Intersect(rs.Bounds)
+		soff := image.ZP
+		if dr.Min.X < rs.Bounds.Min.X {
+			soff.X = rs.Bounds.Min.X - dr.Min.X
+			maskp.X += rs.Bounds.Min.X - dr.Min.X
+		}
+		if dr.Min.Y < rs.Bounds.Min.Y {
+			soff.Y = rs.Bounds.Min.Y - dr.Min.Y
+			maskp.Y += rs.Bounds.Min.Y - dr.Min.Y
+		}
+		draw.DrawMask(d.Dst, idr, d.Src, soff, mask, maskp, draw.Over)
+		return
+	}
+	srect := dr.Sub(dr.Min)
+	transformer := draw.BiLinear
+	m := rr.glyphXForm(rp, dr)
+	s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+	transformer.Transform(d.Dst, s2d, d.Src, srect, draw.Over, &draw.Options{
+		SrcMask:  mask,
+		SrcMaskP: maskp,
+	})
+}
+
+// glyphXForm returns the transform from the pixels of a glyph image, as
+// returned from the font face for absolute position rp with bounds dr, to
+// the image, with the rotation and scaling of this rune
+func (rr *Rune) glyphXForm(rp mat32.Vec2, dr image.Rectangle) mat32.Mat2 {
+	scx := float32(1)
+	if rr.ScaleX != 0 {
+		scx = rr.ScaleX
+	}
+	dbase := mat32.Vec2{X: rp.X - float32(dr.Min.X), Y: rp.Y - float32(dr.Min.Y)}
+	fx, fy := float32(dr.Min.X), float32(dr.Min.Y)
+	return mat32.Translate2D(fx+dbase.X, fy+dbase.Y).Scale(scx, 1).Rotate(rr.RotRad).Translate(-dbase.X, -dbase.Y)
+}
+
+// DrawColorGlyph draws the given color glyph image, as returned from a
+// ColorFace for absolute position rp, with the rotation and scaling of this
+// rune
+func (rr *Rune) DrawColorGlyph(rs *State, d *font.Drawer, rp mat32.Vec2, dr image.Rectangle, img image.Image) {
+	if rs.Vector != nil {
+		rs.vecImage(img, rr.glyphXForm(rp, dr))
+	}
+	cd := *d
+	cd.Src = img
+	rr.DrawGlyph(rs, &cd, rp, dr, nil, image.Point{})
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"container/list"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"github.com/anthonynsimon/bild/convolution"
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+)
+
+// Box shadows are rendered by drawing the shadow color through an alpha
+// mask of the (spread) box shape, blurred with a Gaussian blur, as in
+// CSS: https://www.w3.org/TR/css-backgrounds-3/#shadow-blur -- the masks
+// only depend on the size, radius and shadow geometry, not the color or
+// position, so they are cached and re-used across renders.
+
+// ShadowCacheMaxBytes is the maximum memory used by the blurred shadow
+// masks kept in the shadow mask cache, in bytes -- the masks that were
+// used least recently are removed to stay within it
+var ShadowCacheMaxBytes = 4 << 20
+
+// shadowKey is the key for the shadow mask cache
+type shadowKey struct {
+	W, H   int
+	Rad    gist.SideFloats
+	Blur   float32
+	Spread float32
+	Inset  bool
+	Off    image.Point
+}
+
+// shadowEntry is a shadow mask in the shadow mask cache
+type shadowEntry struct {
+	key  shadowKey
+	mask *image.Alpha
+}
+
+// shadowEntryBytes is the approximate memory used by a shadow mask in the
+// cache, in addition to its pixels
+const shadowEntryBytes = 160
+
+// bytes returns the approximate memory used by the shadow mask
+func (se *shadowEntry) bytes() int {
+	if se.mask == nil {
+		return shadowEntryBytes
+	}
+	return shadowEntryBytes + len(se.mask.Pix)
+}
+
+var (
+	shadowCache      map[shadowKey]*list.Element
+	shadowCacheLRU   list.List // front is most recently used
+	shadowCacheBytes int
+	shadowCacheMu    sync.Mutex
+)
+
+// DrawBoxShadow draws the given box shadow for a box with given position,
+// size and corner radii.  Outset shadows should be drawn before the box
+// background, and inset shadows after it.
+func (pc *Paint) DrawBoxShadow(rs *State, pos, sz mat32.Vec2, rad gist.SideFloats, sh *gist.Shadow) {
+	if !sh.HasShadow() {
+		return
+	}
+	ipos := image.Point{int(mat32.Round(pos.X)), int(mat32.Round(pos.Y))}
+	isz := image.Point{int(mat32.Round(sz.X)), int(mat32.Round(sz.Y))}
+	if isz.X <= 0 || isz.Y <= 0 {
+		return
+	}
+	off := image.Point{int(mat32.Round(sh.HOffset.Dots)), int(mat32.Round(sh.VOffset.Dots))}
+	key := shadowKey{W: isz.X, H: isz.Y, Rad: rad, Blur: mat32.Max(sh.Blur.Dots, 0), Spread: sh.Spread.Dots, Inset: sh.Inset}
+	if sh.Inset {
+		key.Off = off // inset masks are clipped to the box, so depend on offset
+	}
+	mask := shadowMask(key)
+	if mask == nil {
+		return
+	}
+	mpos := ipos.Add(mask.Rect.Min)
+	if !sh.Inset {
+		mpos = mpos.Add(off)
+	}
+	dr := image.Rectangle{Min: mpos, Max: mpos.Add(mask.Rect.Size())}.Intersect(rs.Bounds)
+	if dr.Empty() {
+		return
+	}
+	src := image.NewUniform(color.Color(sh.Color))
+	if !rs.Deferred() {
+		draw.DrawMask(rs.Image, dr, src, image.Point{}, mask, dr.Min.Sub(mpos).Add(mask.Rect.Min), draw.Over)
+	}
+	if rs.Vector != nil {
+		mr := image.Rectangle{Min: dr.Min.Sub(mpos).Add(mask.Rect.Min), Max: dr.Max.Sub(mpos).Add(mask.Rect.Min)}
+		rs.vecMask(sh.Color, mask, mr, mat32.Translate2D(float32(dr.Min.X), float32(dr.Min.Y)))
+	}
+}
+
+// shadowMask returns the blurred shadow mask for given key, from the cache
+// if available.  The mask bounds are relative to the box position.
+func shadowMask(key shadowKey) *image.Alpha {
+	if mask, ok := cachedShadowMask(key); ok {
+		return mask
+	}
+	// the blur is slow, so masks are made outside of the lock, and the
+	// first one cached wins if the same one is made concurrently
+	var mask *image.Alpha
+	if key.Inset {
+		mask = insetShadowMask(key)
+	} else {
+		mask = outsetShadowMask(key)
+	}
+	shadowCacheMu.Lock()
+	defer shadowCacheMu.Unlock()
+	if el, ok := shadowCache[key]; ok {
+		shadowCacheLRU.MoveToFront(el)
+		return el.Value.(*shadowEntry).mask
+	}
+	if shadowCache == nil {
+		shadowCache = make(map[shadowKey]*list.Element)
+	}
+	se := &shadowEntry{key: key, mask: mask}
+	shadowCache[key] = shadowCacheLRU.PushFront(se)
+	shadowCacheBytes += se.bytes()
+	for shadowCacheBytes > ShadowCacheMaxBytes && shadowCacheLRU.Len() > 1 {
+		el := shadowCacheLRU.Back()
+		ose := el.Value.(*shadowEntry)
+		shadowCacheLRU.Remove(el)
+		delete(shadowCache, ose.key)
+		shadowCacheBytes -= ose.bytes()
+	}
+	return mask
+}
+
+// cachedShadowMask returns the shadow mask for given key if it is in the
+// cache, marking it as the most recently used
+func cachedShadowMask(key shadowKey) (*image.Alpha, bool) {
+	shadowCacheMu.Lock()
+	defer shadowCacheMu.Unlock()
+	el, ok := shadowCache[key]
+	if !ok {
+		return nil, false
+	}
+	shadowCacheLRU.MoveToFront(el)
+	return el.Value.(*shadowEntry).mask, true
+}
+
+// shadowBlurPad returns the number of pixels that a shadow extends beyond
+// its shape due to blur: 3 sigma, where sigma is half the blur radius
+func shadowBlurPad(blur float32) int {
+	return int(math.Ceil(1.5 * float64(blur)))
+}
+
+// spreadRadius returns the corner radii adjusted for given spread, which
+// increases (or decreases) the radius of rounded corners
+func spreadRadius(rad gist.SideFloats, spread float32) gist.SideFloats {
+	adj := func(r float32) float32 {
+		if r <= 0 {
+			return 0
+		}
+		return mat32.Max(r+spread, 0)
+	}
+	return gist.NewSideFloats(adj(rad.Top), adj(rad.Right), adj(rad.Bottom), adj(rad.Left))
+}
+
+// outsetShadowMask returns the mask for an outset shadow: the box shape
+// expanded by spread and blurred
+func outsetShadowMask(key shadowKey) *image.Alpha {
+	w := float32(key.W) + 2*key.Spread
+	h := float32(key.H) + 2*key.Spread
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	pad := shadowBlurPad(key.Blur)
+	sp := int(math.Ceil(float64(key.Spread)))
+	bw := int(math.Ceil(float64(w))) + 2*pad
+	bh := int(math.Ceil(float64(h))) + 2*pad
+	fp := float32(pad) + float32(sp) - key.Spread
+	mask := shapeMask(bw, bh, fp, fp, w, h, spreadRadius(key.Rad, key.Spread))
+	mask = blurMask(mask, key.Blur)
+	mask.Rect = mask.Rect.Add(image.Point{-pad - sp, -pad - sp})
+	return mask
+}
+
+// insetShadowMask returns the mask for an inset shadow: everything outside
+// of the box shape shrunk by spread and offset, blurred and clipped to the
+// box shape
+func insetShadowMask(key shadowKey) *image.Alpha {
+	pad := shadowBlurPad(key.Blur)
+	bw, bh := key.W+2*pad, key.H+2*pad
+	hw := float32(key.W) - 2*key.Spread
+	hh := float32(key.H) - 2*key.Spread
+	var hole *image.Alpha
+	if hw > 0 && hh > 0 {
+		hx := float32(pad+key.Off.X) + key.Spread
+		hy := float32(pad+key.Off.Y) + key.Spread
+		hole = shapeMask(bw, bh, hx, hy, hw, hh, spreadRadius(key.Rad, -key.Spread))
+	} else {
+		hole = image.NewAlpha(image.Rect(0, 0, bw, bh))
+	}
+	for i, a := range hole.Pix {
+		hole.Pix[i] = 255 - a
+	}
+	sm := blurMask(hole, key.Blur)
+	box := shapeMask(key.W, key.H, 0, 0, float32(key.W), float32(key.H), key.Rad)
+	for y := 0; y < key.H; y++ {
+		for x := 0; x < key.W; x++ {
+			bi := box.PixOffset(x, y)
+			box.Pix[bi] = uint8(uint32(box.Pix[bi]) * uint32(sm.Pix[sm.PixOffset(x+pad, y+pad)]) / 255)
+		}
+	}
+	return box
+}
+
+// shapeMask returns an alpha mask of given size with a (rounded) rectangle
+// of given position, size and corner radii filled in
+func shapeMask(mw, mh int, x, y, w, h float32, rad gist.SideFloats) *image.Alpha {
+	img := image.NewRGBA(image.Rect(0, 0, mw, mh))
+	rs := &State{}
+	rs.Init(mw, mh, img)
+	rs.Bounds = img.Rect
+	pc := &rs.Paint
+	pc.StrokeStyle.SetColor(nil)
+	pc.FillStyle.SetColor(color.White)
+	if rad.IsZero() {
+		pc.DrawRectangle(rs, x, y, w, h)
+	} else {
+		pc.DrawRoundedRectangleSides(rs, x, y, w, h, rad)
+	}
+	pc.Fill(rs)
+	mask := image.NewAlpha(img.Rect)
+	draw.Draw(mask, mask.Rect, img, image.Point{}, draw.Src)
+	return mask
+}
+
+// blurMask returns the mask blurred with a Gaussian blur for given CSS blur
+// radius, which is twice the standard deviation of the Gaussian
+func blurMask(mask *image.Alpha, blur float32) *image.Alpha {
+	sigma := float64(blur) / 2
+	if sigma < 0.5 {
+		return mask
+	}
+	rad := shadowBlurPad(blur)
+	k := convolution.NewKernel(2*rad+1, 1)
+	for i := range k.Matrix {
+		x := float64(i - rad)
+		k.Matrix[i] = math.Exp(-x * x / (2 * sigma * sigma))
+	}
+	nk := k.Normalized()
+	opts := &convolution.Options{Bias: 0, Wrap: false, KeepAlpha: false}
+	img := convolution.Convolve(mask, nk, opts)
+	img = convolution.Convolve(img, nk.Transposed(), opts)
+	res := image.NewAlpha(mask.Rect)
+	draw.Draw(res, res.Rect, img, image.Point{}, draw.Src)
+	return res
+}
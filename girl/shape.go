@@ -0,0 +1,526 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-text/typesetting/di"
+	tsfont "github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/language"
+	"github.com/go-text/typesetting/shaping"
+	"github.com/goki/freetype/truetype"
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/mat32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// ShapeAllText causes all text to be shaped by the text shaping engine (see
+// Span.ShapeLR), instead of only the text that requires it (complex scripts
+// and right-to-left text) -- this enables ligatures and other OpenType
+// features for all text, at some cost in layout speed.
+var ShapeAllText = false
+
+// ComplexScripts are the unicode scripts that require complex shaping
+// (contextual forms, reordering, combining marks etc), and are thus always
+// shaped by the text shaping engine
+var ComplexScripts = []*unicode.RangeTable{
+	unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko,
+	unicode.Devanagari, unicode.Bengali, unicode.Gurmukhi, unicode.Gujarati,
+	unicode.Oriya, unicode.Tamil, unicode.Telugu, unicode.Kannada,
+	unicode.Malayalam, unicode.Sinhala, unicode.Thai, unicode.Lao,
+	unicode.Tibetan, unicode.Myanmar, unicode.Khmer, unicode.Mongolian,
+	unicode.Balinese, unicode.Javanese,
+}
+
+// IsComplexRune returns true if given rune is in one of the ComplexScripts,
+// or otherwise has a strong right-to-left direction
+func IsComplexRune(r rune) bool {
+	if r < 0x0590 { // latin, greek, cyrillic etc
+		return false
+	}
+	if unicode.In(r, ComplexScripts...) {
+		return true
+	}
+	return IsRTLRune(r)
+}
+
+// IsRTLRune returns true if given rune has a strong right-to-left direction
+func IsRTLRune(r rune) bool {
+	if r < 0x0590 {
+		return false
+	}
+	p, _ := bidi.LookupRune(r)
+	cl := p.Class()
+	return cl == bidi.R || cl == bidi.AL
+}
+
+// Glyph is one glyph of shaped text, which renders a cluster of one or more
+// runes -- the first rune of the cluster holds the position and size of the
+// entire cluster, and the glyph is positioned relative to that
+type Glyph struct {
+	Index  truetype.Index `desc:"index of the glyph in the font"`
+	Rune   int            `desc:"index of the first rune of the cluster rendered by this glyph"`
+	Off    mat32.Vec2     `desc:"offset of the glyph relative to the position of its cluster rune"`
+	Face   font.Face      `json:"-" xml:"-" desc:"font face to render glyph in"`
+	Color  color.Color    `json:"-" xml:"-" desc:"color to render glyph in"`
+	ByRune bool           `desc:"text could not be shaped in this face, so the Rune itself is rendered instead of the glyph Index"`
+}
+
+// NeedsShaping returns true if the span must be laid out by the text shaping
+// engine, because it has complex script or right-to-left text, or
+// ShapeAllText is set
+func (sr *Span) NeedsShaping() bool {
+	if ShapeAllText || sr.Dir == gist.RLTB {
+		return true
+	}
+	for _, r := range sr.Text {
+		if IsComplexRune(r) || IsEmojiSeqRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBidiLevels sets the unicode bidi embedding Level of each rune, using
+// the unicode bidi algorithm with given base direction -- this should be
+// called on each paragraph prior to wrapping it into lines, so that the
+// levels reflect the entire paragraph.  If override is true
+// (unicode-bidi: bidi-override) all runes get the base direction.
+func (sr *Span) SetBidiLevels(rtl, override bool) {
+	base := uint8(0)
+	sr.Dir = gist.LRTB
+	if rtl {
+		base = 1
+		sr.Dir = gist.RLTB
+	}
+	sr.BidiDone = true
+	hasRTL := false
+	for i, r := range sr.Text {
+		sr.Render[i].Level = base
+		if IsRTLRune(r) {
+			hasRTL = true
+		}
+	}
+	if override || len(sr.Text) == 0 || (!rtl && !hasRTL) {
+		return
+	}
+	// the paragraph direction is otherwise determined by the first strong
+	// rune, so it is set by starting with a left-to-right or
+	// right-to-left mark, which offsets all the positions by one
+	var p bidi.Paragraph
+	def, mark := bidi.LeftToRight, "\u200e"
+	if rtl {
+		def, mark = bidi.RightToLeft, "\u200f"
+	}
+	p.SetString(mark+string(sr.Text), bidi.DefaultDirection(def))
+	ord, err := p.Order()
+	if err != nil {
+		return
+	}
+	for i := 0; i < ord.NumRuns(); i++ {
+		run := ord.Run(i)
+		lvl := base
+		switch {
+		case run.Direction() == bidi.RightToLeft:
+			lvl = 1
+		case rtl:
+			lvl = 2
+		}
+		st, ed := run.Pos()
+		for j := st; j <= ed && j <= len(sr.Render); j++ {
+			if j > 0 {
+				sr.Render[j-1].Level = lvl
+			}
+		}
+	}
+}
+
+// BidiVisualOrder returns the visual order of a sequence of runs with given
+// bidi embedding levels, in logical order, by reversing each sequence of
+// runs at or above each level, from the highest down to the lowest odd
+// level (rule L2 of the unicode bidi algorithm)
+func BidiVisualOrder(levels []uint8) []int {
+	n := len(levels)
+	ord := make([]int, n)
+	maxl, minodd := 0, 255
+	for i, l := range levels {
+		ord[i] = i
+		if int(l) > maxl {
+			maxl = int(l)
+		}
+		if l%2 == 1 && int(l) < minodd {
+			minodd = int(l)
+		}
+	}
+	for lvl := maxl; lvl >= minodd; lvl-- {
+		for i := 0; i < n; {
+			if int(levels[ord[i]]) < lvl {
+				i++
+				continue
+			}
+			j := i
+			for j < n && int(levels[ord[j]]) >= lvl {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				ord[a], ord[b] = ord[b], ord[a]
+			}
+			i = j
+		}
+	}
+	return ord
+}
+
+// shapeRun is a run of runes with the same bidi level, face and script,
+// which is shaped as a unit
+type shapeRun struct {
+	st, ed int
+	level  uint8
+	face   font.Face
+	script language.Script
+}
+
+// shapeRuns returns the runs of runes in logical order, given the resolved
+// face for each rune
+func (sr *Span) shapeRuns(faces []font.Face) []shapeRun {
+	var runs []shapeRun
+	for i, r := range sr.Text {
+		lvl := sr.Render[i].Level
+		sc := language.LookupScript(r)
+		neutral := sc == language.Common || sc == language.Inherited || sc == language.Unknown
+		if nr := len(runs); nr > 0 {
+			cr := &runs[nr-1]
+			if cr.level == lvl && cr.face == faces[i] {
+				if neutral || cr.script == sc {
+					cr.ed = i + 1
+					continue
+				}
+				if cr.script == language.Common {
+					cr.script = sc
+					cr.ed = i + 1
+					continue
+				}
+			}
+		}
+		if neutral {
+			sc = language.Common
+		}
+		runs = append(runs, shapeRun{st: i, ed: i + 1, level: lvl, face: faces[i], script: sc})
+	}
+	return runs
+}
+
+// ShapeLR sets the relative positions of each rune using the text shaping
+// engine, which computes the glyphs to render for each cluster of runes,
+// and lays out runs of different bidi directions in their visual order.  As
+// for SetRunePosLR, the resulting layout is flat left-to-right, with extra
+// letter and word spacing between clusters.  Each rune of a cluster gets
+// the position of the cluster start, and the first rune gets the size of
+// the whole cluster, so cursor positioning is at cluster boundaries.
+func (sr *Span) ShapeLR(letterSpace, wordSpace, chsz float32, tabSize int) {
+	if err := sr.IsValid(); err != nil {
+		return
+	}
+	if !sr.BidiDone {
+		sr.SetBidiLevels(sr.Dir == gist.RLTB, false)
+	}
+	if tabSize == 0 {
+		tabSize = 4
+	}
+	TextFontRenderMu.Lock()
+	defer TextFontRenderMu.Unlock()
+
+	sz := len(sr.Text)
+	faces := make([]font.Face, sz)
+	colors := make([]color.Color, sz)
+	curFace := sr.Render[0].Face
+	curColor := sr.Render[0].Color
+	for i := range sr.Render {
+		rr := &(sr.Render[i])
+		curFace = rr.CurFace(curFace)
+		curColor = rr.CurColor(curColor)
+		faces[i] = curFace
+		colors[i] = curColor
+		rr.RelPos = mat32.Vec2{}
+		rr.Size = mat32.Vec2{}
+	}
+	runs := sr.shapeRuns(faces)
+	lvls := make([]uint8, len(runs))
+	for i := range runs {
+		lvls[i] = runs[i].level
+	}
+	sr.Glyphs = make([]Glyph, 0, sz)
+	var fpos float32
+	nclust := 0
+	// setCluster positions the cluster of nr runes starting at st, which has
+	// given advance -- returns the position after it
+	setCluster := func(st, nr int, adv float32) {
+		r := sr.Text[st]
+		if nclust > 0 {
+			fpos += letterSpace
+			if unicode.IsSpace(sr.Text[st]) {
+				fpos += wordSpace
+			}
+		}
+		nclust++
+		if r == '\t' {
+			col := int(mat32.Ceil(fpos / chsz))
+			col = (col/tabSize + 1) * tabSize
+			if cpos := chsz * float32(col); cpos > fpos {
+				adv = cpos - fpos
+			}
+		}
+		for ri := st; ri < st+nr && ri < sz; ri++ {
+			rr := &(sr.Render[ri])
+			fm := faces[ri].Metrics()
+			rr.RelPos.X = fpos
+			if bitflag.Has32(int32(rr.Deco), int(gist.DecoSuper)) {
+				rr.RelPos.Y = -0.45 * mat32.FromFixed(fm.Ascent)
+			}
+			if bitflag.Has32(int32(rr.Deco), int(gist.DecoSub)) {
+				rr.RelPos.Y = 0.15 * mat32.FromFixed(fm.Ascent)
+			}
+			rr.Size.Y = mat32.FromFixed(fm.Height)
+		}
+		sr.Render[st].Size.X = adv
+		fpos += adv
+	}
+
+	for _, ri := range BidiVisualOrder(lvls) {
+		run := &runs[ri]
+		sf := shapeFaceFor(run.face)
+		if sf == nil {
+			for i := 0; i < run.ed-run.st; i++ {
+				ci := run.st + i
+				if run.level%2 == 1 {
+					ci = run.ed - 1 - i
+				}
+				a, _ := run.face.GlyphAdvance(sr.Text[ci])
+				sr.Glyphs = append(sr.Glyphs, Glyph{Rune: ci, Face: run.face, Color: colors[ci], ByRune: true})
+				setCluster(ci, 1, mat32.FromFixed(a))
+			}
+			continue
+		}
+		dir := di.DirectionLTR
+		if run.level%2 == 1 {
+			dir = di.DirectionRTL
+		}
+		out := textShaper.Shape(shaping.Input{Text: sr.Text, RunStart: run.st, RunEnd: run.ed, Direction: dir, Face: sf.face, Size: fixed.I(sf.size), Script: run.script, Language: shapeLang})
+		for gi := 0; gi < len(out.Glyphs); {
+			g0 := &out.Glyphs[gi]
+			st := g0.ClusterIndex
+			ng := g0.GlyphCount
+			if ng < 1 {
+				ng = 1
+			}
+			nr := g0.RuneCount
+			if nr < 1 {
+				nr = 1
+			}
+			var adv float32
+			for k := 0; k < ng && gi+k < len(out.Glyphs); k++ {
+				g := &out.Glyphs[gi+k]
+				if sr.Text[st] != '\t' {
+					sr.Glyphs = append(sr.Glyphs, Glyph{Index: truetype.Index(g.GlyphID), Rune: st, Off: mat32.Vec2{X: adv + mat32.FromFixed(g.XOffset), Y: -mat32.FromFixed(g.YOffset)}, Face: run.face, Color: colors[st]})
+				}
+				adv += mat32.FromFixed(g.XAdvance)
+			}
+			setCluster(st, nr, adv)
+			gi += ng
+		}
+	}
+	sr.LastPos.X = fpos + sr.hyphenWidth()
+	sr.LastPos.Y = 0
+}
+
+// RenderGlyphs renders the shaped Glyphs of the span, at given absolute
+// position of the span, using given drawer
+func (sr *Span) RenderGlyphs(rs *State, d *font.Drawer, tpos mat32.Vec2) {
+	var curColor color.Color
+	var txts []string
+	if rs.Vector != nil {
+		txts = sr.glyphTexts()
+	}
+	for gi := range sr.Glyphs {
+		g := &sr.Glyphs[gi]
+		rr := &(sr.Render[g.Rune])
+		if g.Color != curColor {
+			curColor = g.Color
+			d.Src = image.NewUniform(curColor)
+		}
+		r := sr.Text[g.Rune]
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		rp := tpos.Add(rr.RelPos).Add(g.Off)
+		if !rr.InBounds(rs, g.Face, rp) {
+			continue
+		}
+		d.Face = g.Face
+		d.Dot = rp.Fixed()
+		if cf, isc := g.Face.(*ColorFace); isc {
+			cidx, cok := g.Index, !g.ByRune
+			if g.ByRune {
+				cidx, cok = cf.GlyphIndex(r)
+			}
+			if cok {
+				if dr, img, ok := cf.ColorGlyphAtIndex(d.Dot, cidx, curColor); ok {
+					rr.DrawColorGlyph(rs, d, rp, dr, img)
+					continue
+				}
+			}
+		}
+		var dr image.Rectangle
+		var mask image.Image
+		var maskp image.Point
+		var ok bool
+		if ixf, isx := g.Face.(truetype.IndexableFace); isx && !g.ByRune {
+			dr, mask, maskp, ok = TheGlyphCache.GlyphAtIndex(ixf, d.Dot, g.Index)
+		} else {
+			dr, mask, maskp, ok = TheGlyphCache.Glyph(g.Face, d.Dot, r)
+		}
+		if !ok {
+			continue
+		}
+		if rs.Vector != nil {
+			idx := int(g.Index)
+			if g.ByRune {
+				idx = -1
+			}
+			rr.vectorGlyph(rs, g.Face, r, idx, txts[gi], rp, curColor, dr, mask, maskp)
+		}
+		rr.DrawGlyph(rs, d, rp, dr, mask, maskp)
+	}
+}
+
+// glyphTexts returns the text rendered by each of the Glyphs: the runes of
+// its cluster for the first glyph of each cluster, and empty for the others
+func (sr *Span) glyphTexts() []string {
+	starts := make([]int, len(sr.Glyphs))
+	for gi := range sr.Glyphs {
+		starts[gi] = sr.Glyphs[gi].Rune
+	}
+	sort.Ints(starts)
+	txts := make([]string, len(sr.Glyphs))
+	done := make(map[int]bool)
+	for gi := range sr.Glyphs {
+		st := sr.Glyphs[gi].Rune
+		if done[st] {
+			continue
+		}
+		done[st] = true
+		ed := len(sr.Text)
+		if i := sort.SearchInts(starts, st+1); i < len(starts) {
+			ed = starts[i]
+		}
+		txts[gi] = string(sr.Text[st:ed])
+	}
+	return txts
+}
+
+// LogicalPosLR returns the position after each rune in logical order, for
+// text with rune positions already set (e.g., SetRunePosLR) -- this is the
+// same as the RelPos after each rune for unshaped text, and is computed
+// from the rune sizes for shaped text, which can be in a different visual
+// order.
+func (sr *Span) LogicalPosLR() []float32 {
+	pos := make([]float32, len(sr.Render))
+	if len(sr.Glyphs) == 0 {
+		for i := range sr.Render {
+			pos[i] = sr.Render[i].RelPosAfterLR()
+		}
+		return pos
+	}
+	var fpos float32
+	for i := range sr.Render {
+		fpos += sr.Render[i].Size.X
+		pos[i] = fpos
+	}
+	return pos
+}
+
+// textShaper is the shaper used for all text, under TextFontRenderMu
+var textShaper shaping.HarfbuzzShaper
+
+// shapeLang is the language used for shaping text
+var shapeLang = language.DefaultLanguage()
+
+// shapeFace is the font for the text shaping engine for a font face,
+// at its size in dots
+type shapeFace struct {
+	face *tsfont.Face
+	size int
+}
+
+var (
+	// shapeFaces caches the shapeFace for each font face -- nil if the
+	// face is not in the FontLibrary or cannot be shaped
+	shapeFaces = map[font.Face]*shapeFace{}
+
+	// shapeFonts caches the parsed shaping fonts by font name
+	shapeFonts = map[string]*tsfont.Face{}
+)
+
+// shapeFaceFor returns the shapeFace for given font face, which must have
+// been loaded from the FontLibrary as a truetype face -- returns nil if
+// that is not the case or the font cannot be parsed.  Must be called under
+// TextFontRenderMu.
+func shapeFaceFor(face font.Face) *shapeFace {
+	if sf, ok := shapeFaces[face]; ok {
+		return sf
+	}
+	var sf *shapeFace
+	if _, isx := face.(truetype.IndexableFace); isx {
+		fontnm, size := FontLibrary.FaceNameSize(face)
+		if fontnm != "" {
+			if tf := shapeFont(fontnm); tf != nil {
+				sf = &shapeFace{face: tf, size: size}
+			}
+		}
+	}
+	shapeFaces[face] = sf
+	return sf
+}
+
+// shapeFont returns the parsed shaping font for given font name in the
+// FontLibrary -- nil if it cannot be loaded
+func shapeFont(fontnm string) *tsfont.Face {
+	if tf, ok := shapeFonts[fontnm]; ok {
+		return tf
+	}
+	fontBytes := fontData(fontnm)
+	var tf *tsfont.Face
+	if len(fontBytes) > 0 {
+		tf, _ = tsfont.ParseTTF(bytes.NewReader(fontBytes))
+	}
+	shapeFonts[fontnm] = tf
+	return tf
+}
+
+// fontData returns the font file data for given font name in the
+// FontLibrary -- nil if it cannot be loaded
+func fontData(fontnm string) []byte {
+	loadFontMu.RLock()
+	path := FontLibrary.FontsAvail[fontnm]
+	loadFontMu.RUnlock()
+	if strings.HasPrefix(path, "gofont") {
+		return GoFonts[path].ttf
+	}
+	if path == "" {
+		return nil
+	}
+	data, _ := ioutil.ReadFile(path)
+	return data
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/ints"
 	"github.com/goki/mat32"
 	"golang.org/x/image/font"
 )
@@ -32,12 +33,15 @@ import (
 // span-as-line.  The first Rune RelPos for LR text should be at X=0
 // (LastPos = 0 for RL) -- i.e., relpos positions are minimal for given span.
 type Span struct {
-	Text    []rune               `desc:"text as runes"`
-	Render  []Rune               `desc:"render info for each rune in one-to-one correspondence"`
-	RelPos  mat32.Vec2           `desc:"position for start of text relative to an absolute coordinate that is provided at the time of rendering -- this typically includes the baseline offset to align all rune rendering there -- individual rune RelPos are added to this plus the render-time offset to get the final position"`
-	LastPos mat32.Vec2           `desc:"rune position for further edge of last rune -- for standard flat strings this is the overall length of the string -- used for size / layout computations -- you do not add RelPos to this -- it is in same Text relative coordinates"`
-	Dir     gist.TextDirections  `desc:"where relevant, this is the (default, dominant) text direction for the span"`
-	HasDeco gist.TextDecorations `desc:"mask of decorations that have been set on this span -- optimizes rendering passes"`
+	Text     []rune               `desc:"text as runes"`
+	Render   []Rune               `desc:"render info for each rune in one-to-one correspondence"`
+	RelPos   mat32.Vec2           `desc:"position for start of text relative to an absolute coordinate that is provided at the time of rendering -- this typically includes the baseline offset to align all rune rendering there -- individual rune RelPos are added to this plus the render-time offset to get the final position"`
+	LastPos  mat32.Vec2           `desc:"rune position for further edge of last rune -- for standard flat strings this is the overall length of the string -- used for size / layout computations -- you do not add RelPos to this -- it is in same Text relative coordinates"`
+	Dir      gist.TextDirections  `desc:"where relevant, this is the (default, dominant) text direction for the span"`
+	HasDeco  gist.TextDecorations `desc:"mask of decorations that have been set on this span -- optimizes rendering passes"`
+	Glyphs   []Glyph              `desc:"for text laid out by the text shaping engine (see ShapeLR), the glyphs to render in visual order -- otherwise each rune is rendered as its own glyph"`
+	BidiDone bool                 `desc:"true if the bidi Level of each rune has been set for the paragraph containing this span (see SetBidiLevels) -- otherwise it is set for the span itself when shaping"`
+	Hyphen   bool                 `desc:"true if this line ends within a word that was hyphenated when wrapping the text, so a hyphen is rendered after the last rune (which is included in LastPos)"`
 }
 
 // Init initializes a new span with given capacity
@@ -45,6 +49,9 @@ func (sr *Span) Init(capsz int) {
 	sr.Text = make([]rune, 0, capsz)
 	sr.Render = make([]Rune, 0, capsz)
 	sr.HasDeco = 0
+	sr.Glyphs = nil
+	sr.BidiDone = false
+	sr.Hyphen = false
 }
 
 // IsValid ensures that at least some text is represented and the sizes of
@@ -73,6 +80,9 @@ func (sr *Span) SizeHV() mat32.Vec2 {
 	if sz.Y < 0 {
 		sz.Y = -sz.Y
 	}
+	if len(sr.Glyphs) > 0 { // shaped text starts at 0, but first rune may be anywhere
+		sz.X = sr.LastPos.X
+	}
 	return sz
 }
 
@@ -196,6 +206,9 @@ func (sr *Span) SetRenders(sty *gist.Font, ctxt *units.Context, noBG bool, rot,
 
 	sr.HasDecoUpdate(bgc, sty.Deco)
 	sr.Render = make([]Rune, sz)
+	sr.Glyphs = nil
+	sr.BidiDone = false
+	sr.Hyphen = false
 	if sty.Face == nil {
 		sr.Render[0].Face = ucfont.Face.Face
 	} else {
@@ -262,12 +275,19 @@ var TextFontRenderMu sync.Mutex
 
 // SetRunePosLR sets relative positions of each rune using a flat
 // left-to-right text layout, based on font size info and additional extra
-// letter and word spacing parameters (which can be negative)
+// letter and word spacing parameters (which can be negative) -- text that
+// needs shaping is laid out by ShapeLR instead.
 func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int) {
 	if err := sr.IsValid(); err != nil {
 		// log.Println(err)
 		return
 	}
+	sr.SetFallbackFaces()
+	if sr.NeedsShaping() {
+		sr.ShapeLR(letterSpace, wordSpace, chsz, tabSize)
+		return
+	}
+	sr.Glyphs = nil
 	sr.Dir = gist.LRTB
 	sz := len(sr.Text)
 	prevR := rune(-1)
@@ -301,7 +321,9 @@ func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int)
 		// todo: could check for various types of special unicode space chars here
 		a, _ := curFace.GlyphAdvance(r)
 		a32 := mat32.FromFixed(a)
-		if a32 == 0 {
+		if r == SoftHyphen { // only rendered at the end of a hyphenated line
+			a32 = 0
+		} else if a32 == 0 {
 			a32 = .1 * fht // something..
 		}
 		rr.Size = mat32.Vec2{a32, fht}
@@ -326,7 +348,7 @@ func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int)
 		}
 		prevR = r
 	}
-	sr.LastPos.X = fpos
+	sr.LastPos.X = fpos + sr.hyphenWidth()
 	sr.LastPos.Y = 0
 }
 
@@ -338,6 +360,7 @@ func (sr *Span) SetRunePosTB(letterSpace, wordSpace, chsz float32, tabSize int)
 		// log.Println(err)
 		return
 	}
+	sr.SetFallbackFaces()
 	sr.Dir = gist.TB
 	sz := len(sr.Text)
 	lspc := letterSpace
@@ -368,7 +391,9 @@ func (sr *Span) SetRunePosTB(letterSpace, wordSpace, chsz float32, tabSize int)
 		// todo: could check for various types of special unicode space chars here
 		a, _ := curFace.GlyphAdvance(r)
 		a32 := mat32.FromFixed(a)
-		if a32 == 0 {
+		if r == SoftHyphen { // only rendered at the end of a hyphenated line
+			a32 = 0
+		} else if a32 == 0 {
 			a32 = .1 * fht // something..
 		}
 		rr.Size = mat32.Vec2{a32, fht}
@@ -405,6 +430,7 @@ func (sr *Span) SetRunePosTBRot(letterSpace, wordSpace, chsz float32, tabSize in
 		// log.Println(err)
 		return
 	}
+	sr.SetFallbackFaces()
 	sr.Dir = gist.TB
 	sz := len(sr.Text)
 	prevR := rune(-1)
@@ -440,7 +466,9 @@ func (sr *Span) SetRunePosTBRot(letterSpace, wordSpace, chsz float32, tabSize in
 		// todo: could check for various types of special unicode space chars here
 		a, _ := curFace.GlyphAdvance(r)
 		a32 := mat32.FromFixed(a)
-		if a32 == 0 {
+		if r == SoftHyphen { // only rendered at the end of a hyphenated line
+			a32 = 0
+		} else if a32 == 0 {
 			a32 = .1 * fht // something..
 		}
 		rr.Size = mat32.Vec2{fht, a32}
@@ -480,11 +508,21 @@ func (sr *Span) FindWrapPosLR(trgSize, curSize float32) int {
 	if idx >= sz {
 		idx = sz - 1
 	}
+	var lpos []float32
+	if len(sr.Glyphs) > 0 { // shaped runes can be in a different visual order
+		lpos = sr.LogicalPosLR()
+	}
+	posAfter := func(i int) float32 {
+		if lpos != nil {
+			return lpos[i]
+		}
+		return sr.Render[i].RelPosAfterLR()
+	}
 	// find starting index that is just within size
-	csz := sr.RelPos.X + sr.Render[idx].RelPosAfterLR()
+	csz := sr.RelPos.X + posAfter(idx)
 	if csz > trgSize {
 		for idx > 0 {
-			csz = sr.RelPos.X + sr.Render[idx].RelPosAfterLR()
+			csz = sr.RelPos.X + posAfter(idx)
 			if csz <= trgSize {
 				break
 			}
@@ -492,7 +530,7 @@ func (sr *Span) FindWrapPosLR(trgSize, curSize float32) int {
 		}
 	} else {
 		for idx < sz-1 {
-			nsz := sr.RelPos.X + sr.Render[idx+1].RelPosAfterLR()
+			nsz := sr.RelPos.X + posAfter(idx+1)
 			if nsz > trgSize {
 				break
 			}
@@ -528,6 +566,162 @@ func (sr *Span) FindWrapPosLR(trgSize, curSize float32) int {
 	return idx
 }
 
+// Ellipsis is the rune used in place of elided text that does not fit --
+// see EllipsisPosLR and ElideLR
+var Ellipsis = '…'
+
+// EllipsisWidth returns the width of the Ellipsis rune in given face
+func EllipsisWidth(face font.Face) float32 {
+	if face == nil {
+		return 0
+	}
+	TextFontRenderMu.Lock()
+	a, _ := face.GlyphAdvance(Ellipsis)
+	TextFontRenderMu.Unlock()
+	return mat32.FromFixed(a)
+}
+
+// EllipsisPosLR returns the range of runes, from st up to ed, that must be
+// replaced with an Ellipsis (see ElideLR) for the span to fit within
+// trgSize, eliding at the end, start or middle according to given overflow
+// mode -- RelPos positions must have already been set (e.g., SetRunePosLR).
+// Whitespace adjacent to the ellipsis is included in the elided range.
+func (sr *Span) EllipsisPosLR(trgSize float32, ov gist.TextOverflows) (st, ed int) {
+	sz := len(sr.Text)
+	if sz == 0 {
+		return 0, 0
+	}
+	face, _ := sr.LastFont()
+	if ov != gist.TextOverflowEllipsis {
+		face = sr.Render[0].Face
+	}
+	avail := trgSize - sr.RelPos.X - EllipsisWidth(face)
+	head := func(avail float32) int {
+		n := 0
+		for n < sz && sr.Render[n].RelPosAfterLR() <= avail {
+			n++
+		}
+		for n > 0 && unicode.IsSpace(sr.Text[n-1]) {
+			n--
+		}
+		return n
+	}
+	tail := func(from int, avail float32) int {
+		j := sz
+		for j > from && sr.LastPos.X-sr.Render[j-1].RelPos.X <= avail {
+			j--
+		}
+		for j < sz && unicode.IsSpace(sr.Text[j]) {
+			j++
+		}
+		return j
+	}
+	switch ov {
+	case gist.TextOverflowEllipsisStart:
+		return 0, tail(0, avail)
+	case gist.TextOverflowEllipsisMiddle:
+		st = head(avail / 2)
+		hw := float32(0)
+		if st > 0 {
+			hw = sr.Render[st-1].RelPosAfterLR()
+		}
+		return st, tail(st, avail-hw)
+	default:
+		return head(avail), sz
+	}
+}
+
+// ElideLR replaces the runes from st up to ed with a single Ellipsis rune,
+// in the font and color in effect at st, keeping the font and color of the
+// remaining runes after ed -- st == ed inserts an ellipsis at that point.
+// Rune positions must be updated after this (e.g., SetRunePosLR).
+func (sr *Span) ElideLR(st, ed int) {
+	sz := len(sr.Text)
+	if sz == 0 || st < 0 || ed > sz || st > ed {
+		return
+	}
+	para := sr.IsNewPara()
+	face, clr := sr.FontAt(st)
+	tface, tclr := sr.FontAt(ed)
+	ref := sr.Render[ints.MinInt(st, sz-1)]
+	er := Rune{Face: face, Color: clr, BgColor: ref.BgColor, Deco: ref.Deco, RotRad: ref.RotRad, ScaleX: ref.ScaleX, Level: ref.Level}
+	bitflag.Clear32((*int32)(&er.Deco), int(gist.DecoParaStart))
+	txt := make([]rune, 0, sz-(ed-st)+1)
+	txt = append(txt, sr.Text[:st]...)
+	txt = append(txt, Ellipsis)
+	txt = append(txt, sr.Text[ed:]...)
+	rend := make([]Rune, 0, sz-(ed-st)+1)
+	rend = append(rend, sr.Render[:st]...)
+	rend = append(rend, er)
+	rend = append(rend, sr.Render[ed:]...)
+	if ed < sz {
+		trr := &(rend[st+1])
+		if trr.Face == nil {
+			trr.Face = tface
+		}
+		if trr.Color == nil {
+			trr.Color = tclr
+		}
+	}
+	sr.Text = txt
+	sr.Render = rend
+	sr.LastPos.X = 0
+	if para {
+		sr.SetNewPara()
+	}
+}
+
+// ElideIdx returns the new index of the rune at given index after the runes
+// from st up to ed have been replaced with a single Ellipsis (see ElideLR)
+func ElideIdx(idx, st, ed int) int {
+	switch {
+	case idx < st:
+		return idx
+	case idx < ed:
+		return st
+	default:
+		return idx - (ed - st) + 1
+	}
+}
+
+// ScaleFaces replaces all the font faces in the span with the same faces
+// scaled by given factor (see FontLib.ScaledFace) -- rune positions must
+// be updated after this (e.g., SetRunePosLR).
+func (sr *Span) ScaleFaces(scale float32) {
+	scaled := make(map[font.Face]font.Face)
+	for i := range sr.Render {
+		rr := &(sr.Render[i])
+		if rr.Face == nil {
+			continue
+		}
+		sf, ok := scaled[rr.Face]
+		if !ok {
+			sf = FontLibrary.ScaledFace(rr.Face, scale)
+			scaled[rr.Face] = sf
+		}
+		rr.Face = sf
+	}
+	sr.LastPos.X = 0
+}
+
+// FontAt returns the font face and color in effect at given rune index,
+// i.e., the last non-nil ones at or before that index
+func (sr *Span) FontAt(idx int) (face font.Face, color color.Color) {
+	for i := ints.MinInt(idx, len(sr.Render)-1); i >= 0; i-- {
+		srr := sr.Render[i]
+		if face == nil {
+			face = srr.Face
+		}
+		if color == nil {
+			color = srr.Color
+		}
+		if face != nil && color != nil {
+			break
+		}
+	}
+	return
+}
+
 // ZeroPos ensures that the positions start at 0, for LR direction
 func (sr *Span) ZeroPosLR() {
 	sz := len(sr.Text)
@@ -601,10 +795,12 @@ func (sr *Span) SplitAtLR(idx int) *Span {
 	if idx <= 0 || idx >= len(sr.Text)-1 { // shouldn't happen
 		return nil
 	}
-	nsr := Span{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco}
+	nsr := Span{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco, BidiDone: sr.BidiDone}
 	sr.Text = sr.Text[:idx]
 	sr.Render = sr.Render[:idx]
 	sr.LastPos.X = sr.Render[idx-1].RelPosAfterLR()
+	sr.Glyphs = nil // must be shaped again
+	sr.Hyphen = false
 	// sr.TrimSpaceLR()
 	// nsr.TrimSpaceLeftLR() // don't trim right!
 	// go back and find latest face and color -- each sr must start with valid one
@@ -38,6 +38,13 @@ type State struct {
 	PaintBack      Paint             `desc:"backup of paint -- don't need a full stack but sometimes safer to backup and restore"`
 	RenderMu       sync.Mutex        `desc:"mutex for overall rendering"`
 	RasterMu       sync.Mutex        `desc:"mutex for final rasterx rendering -- only one at a time"`
+	Vector         *Vector           `desc:"if non-nil, all painting is also recorded into this as vector graphics, e.g., for PDF or SVG output"`
+	VecOff         image.Point       `desc:"offset of the image of this state within the Vector recording, e.g., for a sub-viewport"`
+	VecBounds      image.Rectangle   `desc:"region of the Vector recording that this state draws into -- empty = no limit"`
+	VecPath        []PathSeg         `desc:"current path, recorded for the Vector"`
+	VecClips       []VecClip         `desc:"current clip paths, recorded for the Vector"`
+	VecClipStack   [][]VecClip       `desc:"stack of clip paths for the Vector, as for ClipStack"`
+	Lists          *DisplayLists     `desc:"if non-nil, the painting of each node is also recorded into its own display list, between PushList and PopList"`
 }
 
 // Init initializes State -- must be called whenever image size changes
@@ -157,6 +164,7 @@ func (rs *State) PushClip() {
 		rs.ClipStack = make([]*image.Alpha, 0, 10)
 	}
 	rs.ClipStack = append(rs.ClipStack, rs.Mask)
+	rs.VecClipStack = append(rs.VecClipStack, rs.VecClips)
 }
 
 // PopClip pops Mask off the clip stack and set to current mask
@@ -165,11 +173,16 @@ func (rs *State) PopClip() {
 	if sz == 0 {
 		log.Printf("gi.State PopClip: stack is empty -- programmer error\n")
 		rs.Mask = nil // implied
+		rs.VecClips = nil
 		return
 	}
 	rs.Mask = rs.ClipStack[sz-1]
 	rs.ClipStack[sz-1] = nil
 	rs.ClipStack = rs.ClipStack[:sz-1]
+	if vsz := len(rs.VecClipStack); vsz > 0 {
+		rs.VecClips = rs.VecClipStack[vsz-1]
+		rs.VecClipStack = rs.VecClipStack[:vsz-1]
+	}
 }
 
 // BackupPaint copies style settings from Paint to PaintBack
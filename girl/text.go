@@ -9,6 +9,7 @@ import (
 	"encoding/xml"
 	"html"
 	"image"
+	"image/color"
 	"io"
 	"math"
 	"strings"
@@ -22,9 +23,7 @@ import (
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
 	"github.com/goki/mat32"
-	"golang.org/x/image/draw"
 	"golang.org/x/image/font"
-	"golang.org/x/image/math/f64"
 	"golang.org/x/net/html/charset"
 )
 
@@ -42,10 +41,11 @@ import (
 // Text contains one or more Span elements, typically with each
 // representing a separate line of text (but they can be anything).
 type Text struct {
-	Spans []Span
-	Size  mat32.Vec2          `desc:"last size of overall rendered text"`
-	Dir   gist.TextDirections `desc:"where relevant, this is the (default, dominant) text direction for the span"`
-	Links []TextLink          `desc:"hyperlinks within rendered text"`
+	Spans     []Span
+	Size      mat32.Vec2          `desc:"last size of overall rendered text"`
+	Dir       gist.TextDirections `desc:"where relevant, this is the (default, dominant) text direction for the span"`
+	Links     []TextLink          `desc:"hyperlinks within rendered text"`
+	Truncated bool                `desc:"true if the last layout elided or dropped some of the text, per the text-overflow and max-lines options, so that the full text is not visible -- widgets should then make the full text available, e.g., as a tooltip"`
 }
 
 // InsertSpan inserts a new span at given index
@@ -106,62 +106,45 @@ func (tr *Text) Render(rs *State, pos mat32.Vec2) {
 			sr.RenderLine(rs, tpos, gist.DecoOverline, 1.1)
 		}
 
-		for i, r := range sr.Text {
-			rr := &(sr.Render[i])
-			if rr.Color != nil {
-				curColor = rr.Color
-				d.Src = image.NewUniform(curColor)
-			}
-			curFace = rr.CurFace(curFace)
-			if !unicode.IsPrint(r) {
-				continue
-			}
-			dsc32 := mat32.FromFixed(curFace.Metrics().Descent)
-			rp := tpos.Add(rr.RelPos)
-			scx := float32(1)
-			if rr.ScaleX != 0 {
-				scx = rr.ScaleX
-			}
-			tx := mat32.Scale2D(scx, 1).Rotate(rr.RotRad)
-			ll := rp.Add(tx.MulVec2AsVec(mat32.Vec2{0, dsc32}))
-			ur := ll.Add(tx.MulVec2AsVec(mat32.Vec2{rr.Size.X, -rr.Size.Y}))
-			if int(mat32.Floor(ll.X)) > rs.Bounds.Max.X || int(mat32.Floor(ur.Y)) > rs.Bounds.Max.Y ||
-				int(mat32.Ceil(ur.X)) < rs.Bounds.Min.X || int(mat32.Ceil(ll.Y)) < rs.Bounds.Min.Y {
-				continue
-			}
-			d.Face = curFace
-			d.Dot = rp.Fixed()
-			dr, mask, maskp, _, ok := d.Face.Glyph(d.Dot, r)
-			if !ok {
-				// fmt.Printf("not ok rendering rune: %v\n", string(r))
-				continue
-			}
-			if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
-				idr := dr.Intersect(rs.Bounds)
-				soff := image.ZP
-				if dr.Min.X < rs.Bounds.Min.X {
-					soff.X = rs.Bounds.Min.X - dr.Min.X
-					maskp.X += rs.Bounds.Min.X - dr.Min.X
+		if len(sr.Glyphs) > 0 {
+			sr.RenderGlyphs(rs, d, tpos)
+		} else {
+			for i, r := range sr.Text {
+				rr := &(sr.Render[i])
+				if rr.Color != nil {
+					curColor = rr.Color
+					d.Src = image.NewUniform(curColor)
 				}
-				if dr.Min.Y < rs.Bounds.Min.Y {
-					soff.Y = rs.Bounds.Min.Y - dr.Min.Y
-					maskp.Y += rs.Bounds.Min.Y - dr.Min.Y
+				curFace = rr.CurFace(curFace)
+				if !unicode.IsPrint(r) {
+					continue
 				}
-				draw.DrawMask(d.Dst, idr, d.Src, soff, mask, maskp, draw.Over)
-			} else {
-				srect := dr.Sub(dr.Min)
-				dbase := mat32.Vec2{rp.X - float32(dr.Min.X), rp.Y - float32(dr.Min.Y)}
-
-				transformer := draw.BiLinear
-				fx, fy := float32(dr.Min.X), float32(dr.Min.Y)
-				m := mat32.Translate2D(fx+dbase.X, fy+dbase.Y).Scale(scx, 1).Rotate(rr.RotRad).Translate(-dbase.X, -dbase.Y)
-				s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
-				transformer.Transform(d.Dst, s2d, d.Src, srect, draw.Over, &draw.Options{
-					SrcMask:  mask,
-					SrcMaskP: maskp,
-				})
+				rp := tpos.Add(rr.RelPos)
+				if !rr.InBounds(rs, curFace, rp) {
+					continue
+				}
+				d.Face = curFace
+				d.Dot = rp.Fixed()
+				if cf, isc := curFace.(*ColorFace); isc {
+					if dr, img, ok := cf.ColorGlyph(d.Dot, r, curColor); ok {
+						rr.DrawColorGlyph(rs, d, rp, dr, img)
+						continue
+					}
+				}
+				dr, mask, maskp, ok := TheGlyphCache.Glyph(d.Face, d.Dot, r)
+				if !ok {
+					// fmt.Printf("not ok rendering rune: %v\n", string(r))
+					continue
+				}
+				if rs.Vector != nil {
+					rr.vectorGlyph(rs, curFace, r, -1, string(r), rp, curColor, dr, mask, maskp)
+				}
+				rr.DrawGlyph(rs, d, rp, dr, mask, maskp)
 			}
 		}
+		if sr.Hyphen {
+			sr.RenderHyphen(rs, d, tpos)
+		}
 		if bitflag.Has32(int32(sr.HasDeco), int(gist.DecoLineThrough)) {
 			sr.RenderLine(rs, tpos, gist.DecoLineThrough, 0.25)
 		}
@@ -197,8 +180,10 @@ func (tr *Text) SetString(str string, fontSty *gist.Font, ctxt *units.Context, t
 		tr.Spans = make([]Span, 1)
 	}
 	tr.Links = nil
+	tr.Truncated = false
 	sr := &(tr.Spans[0])
 	sr.SetString(str, fontSty, ctxt, noBG, rot, scalex)
+	sr.SetBidiLevels(txtSty.IsRTL(), txtSty.UnicodeBidi == gist.BidiBidiOverride)
 	sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
 	ssz := sr.SizeHV()
 	vht := fontSty.Face.Face.Metrics().Height
@@ -216,6 +201,7 @@ func (tr *Text) SetStringRot90(str string, fontSty *gist.Font, ctxt *units.Conte
 		tr.Spans = make([]Span, 1)
 	}
 	tr.Links = nil
+	tr.Truncated = false
 	sr := &(tr.Spans[0])
 	rot := float32(mat32.Pi / 2)
 	sr.SetString(str, fontSty, ctxt, noBG, rot, scalex)
@@ -237,8 +223,10 @@ func (tr *Text) SetRunes(str []rune, fontSty *gist.Font, ctxt *units.Context, tx
 		tr.Spans = make([]Span, 1)
 	}
 	tr.Links = nil
+	tr.Truncated = false
 	sr := &(tr.Spans[0])
 	sr.SetRunes(str, fontSty, ctxt, noBG, rot, scalex)
+	sr.SetBidiLevels(txtSty.IsRTL(), txtSty.UnicodeBidi == gist.BidiBidiOverride)
 	sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
 	ssz := sr.SizeHV()
 	vht := fontSty.Face.Face.Metrics().Height
@@ -349,6 +337,7 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 	}
 	tr.Spans = make([]Span, 1)
 	tr.Links = nil
+	tr.Truncated = false
 	curSp := &(tr.Spans[0])
 	initsz := ints.MinInt(sz, 1020)
 	curSp.Init(initsz)
@@ -512,6 +501,7 @@ func (tr *Text) SetHTMLPre(str []byte, font *gist.Font, txtSty *gist.Text, ctxt
 	sz := len(str)
 	tr.Spans = make([]Span, 1)
 	tr.Links = nil
+	tr.Truncated = false
 	if sz == 0 {
 		return
 	}
@@ -807,6 +797,31 @@ func (tx *Text) RuneEndPos(idx int) (pos mat32.Vec2, si, ri int, ok bool) {
 	return mat32.Vec2Zero, -1, -1, false
 }
 
+// SetDecoRange adds given decoration (e.g., underline) to the runes in given
+// range of absolute rune indexes (end exclusive), counting progressively
+// through all spans present, and also sets their background color if bg is
+// non-nil -- e.g., for marking text that is being composed by an input
+// method.  Indexes out of range are ignored.
+func (tx *Text) SetDecoRange(st, ed int, deco gist.TextDecorations, bg color.Color) {
+	var mask gist.TextDecorations // deco is a bit index, as in gist.Font.SetDeco
+	bitflag.Set32((*int32)(&mask), int(deco))
+	idx := 0
+	for si := range tx.Spans {
+		sr := &tx.Spans[si]
+		for ri := range sr.Render {
+			if idx >= st && idx < ed {
+				rr := &sr.Render[ri]
+				rr.Deco |= mask
+				if bg != nil {
+					rr.BgColor = bg
+				}
+				sr.HasDecoUpdate(bg, mask)
+			}
+			idx++
+		}
+	}
+}
+
 //////////////////////////////////////////////////////////////////////////////////
 //  TextStyle-based Layout Routines
 
@@ -825,7 +840,11 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 	// defer pr.End()
 	//
 	tr.Dir = gist.LRTB
+	tr.Truncated = false
 	OpenFont(fontSty, ctxt)
+	if txtSty.ShrinkToFit > 0 && size.X > 0 && !txtSty.HasWordWrap() {
+		tr.ShrinkToFitLR(txtSty, fontSty, size.X)
+	}
 	fht := fontSty.Face.Metrics.Height
 	dsc := mat32.FromFixed(fontSty.Face.Face.Metrics().Descent)
 	lspc := fht * txtSty.EffLineHeight()
@@ -842,6 +861,10 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 			si++
 			continue
 		}
+		if !sr.BidiDone { // each span is a full paragraph prior to wrapping
+			sr.SetBidiLevels(txtSty.IsRTL(), txtSty.UnicodeBidi == gist.BidiBidiOverride)
+			sr.LastPos.X = 0
+		}
 		if sr.LastPos.X == 0 { // don't re-do unless necessary
 			sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
 		}
@@ -853,10 +876,51 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		ssz := sr.SizeHV()
 		ssz.X += sr.RelPos.X
 		if size.X > 0 && ssz.X > size.X && txtSty.HasWordWrap() {
+			var wbrks []WordBreak // breaks within words, relative to current sr
+			if txtSty.Hyphens != gist.HyphensNone {
+				var hy *Hyphenator
+				if txtSty.Hyphens == gist.HyphensAuto {
+					hy = HyphenatorForLang(txtSty.Lang)
+				}
+				wbrks = sr.WordBreaksLR(hy)
+			}
+			pretty := txtSty.WrapStyle == gist.WrapStylePretty
+			var lines []WordBreak // line breaks for the whole paragraph, if pretty
+			if pretty {
+				lines = sr.BreakLinesPrettyLR(size.X, txtSty.Align == gist.AlignJustify, wbrks, fht)
+			}
+			off := 0 // offset of current sr within the paragraph
 			for {
-				wp := sr.FindWrapPosLR(size.X, ssz.X)
+				wp := -1
+				hyph := false
+				if pretty {
+					if len(lines) > 0 {
+						wp, hyph = lines[0].Pos-off, lines[0].Hyphen
+						lines = lines[1:]
+					}
+				} else {
+					wp = sr.FindWrapPosLR(size.X, ssz.X)
+					if hp, hh := sr.FindHyphenPosLR(size.X, wp, wbrks); hp > 0 {
+						wp, hyph = hp, hh
+					}
+				}
 				if wp > 0 && wp < len(sr.Text)-1 {
 					nsr := sr.SplitAtLR(wp)
+					sr.Hyphen = hyph
+					if sr.NeedsShaping() { // re-order the line on its own
+						sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
+					} else if hyph {
+						sr.LastPos.X += sr.HyphenWidthAt(wp - 1)
+					}
+					off += wp
+					nwb := wbrks[:0]
+					for _, wb := range wbrks {
+						if wb.Pos > wp {
+							wb.Pos -= wp
+							nwb = append(nwb, wb)
+						}
+					}
+					wbrks = nwb
 					tr.InsertSpan(si+1, nsr)
 					ssz = sr.SizeHV()
 					ssz.X += sr.RelPos.X
@@ -889,7 +953,7 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 						}
 					}
 
-					if ssz.X <= size.X {
+					if (pretty && len(lines) == 0) || (!pretty && ssz.X <= size.X) {
 						if ssz.X > maxw {
 							maxw = ssz.X
 						}
@@ -909,6 +973,14 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		}
 		si++
 	}
+	clamp := txtSty.MaxLines > 0 && len(tr.Spans) > txtSty.MaxLines
+	if clamp {
+		tr.ClampLines(txtSty.MaxLines)
+	}
+	if clamp || (txtSty.Overflow != gist.TextOverflowClip && size.X > 0 && maxw > size.X) {
+		maxw = tr.EllipsisLR(txtSty, fontSty, size.X, clamp)
+	}
+
 	// have maxw, can do alignment cases..
 
 	// make sure links are still in range
@@ -961,6 +1033,8 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 
 	for si := range tr.Spans {
 		sr := &(tr.Spans[si])
+		paraStart := si == 0 || sr.IsNewPara()
+		paraEnd := si == nsp-1 || tr.Spans[si+1].IsNewPara()
 		if si > 0 && sr.IsNewPara() {
 			vpos += txtSty.ParaSpacing.Dots
 		}
@@ -968,8 +1042,13 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		sr.LastPos.Y = vpos
 		ssz := sr.SizeHV()
 		ssz.X += sr.RelPos.X
+		hst, hed := sr.HangingPunctLR(txtSty, paraStart, paraEnd)
+		sr.RelPos.X -= hst
+		ssz.X -= hst + hed
 		hextra := size.X - ssz.X
-		if hextra > 0 {
+		if txtSty.Align == gist.AlignJustify && !paraEnd {
+			sr.JustifyLR(size.X - sr.RelPos.X + hed)
+		} else if hextra > 0 {
 			switch {
 			case gist.IsAlignMiddle(txtSty.Align):
 				sr.RelPos.X += hextra / 2
@@ -985,6 +1064,119 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 //////////////////////////////////////////////////////////////////////////////////
 //  Utilities
 
+// ShrinkToFitLR scales down the fonts of all the spans, by at most the
+// txtSty.ShrinkToFit factor, so that the widest span fits within given
+// width, for LR text.  The line height is not changed, so that the overall
+// layout remains stable.
+func (tr *Text) ShrinkToFitLR(txtSty *gist.Text, fontSty *gist.Font, trgSize float32) {
+	maxw := float32(0)
+	for si := range tr.Spans {
+		sr := &(tr.Spans[si])
+		if sr.IsValid() != nil {
+			continue
+		}
+		if sr.LastPos.X == 0 {
+			sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
+		}
+		wd := sr.LastPos.X
+		if sr.IsNewPara() {
+			wd += txtSty.Indent.Dots
+		}
+		maxw = mat32.Max(maxw, wd)
+	}
+	if maxw <= trgSize {
+		return
+	}
+	scale := mat32.Max(trgSize/maxw, txtSty.ShrinkToFit)
+	if scale >= 1 {
+		return
+	}
+	for si := range tr.Spans {
+		sr := &(tr.Spans[si])
+		if sr.IsValid() != nil {
+			continue
+		}
+		sr.ScaleFaces(scale)
+		sr.SetRunePosLR(txtSty.LetterSpacing.Dots*scale, txtSty.WordSpacing.Dots*scale, fontSty.Face.Metrics.Ch*scale, txtSty.TabSize)
+	}
+}
+
+// ClampLines drops all spans beyond the given max number of lines, along
+// with any links within them, and marks the text as Truncated -- the last
+// remaining line should then be ended with an ellipsis (see EllipsisLR)
+func (tr *Text) ClampLines(maxLines int) {
+	if maxLines <= 0 || len(tr.Spans) <= maxLines {
+		return
+	}
+	tr.Spans = tr.Spans[:maxLines]
+	tr.Truncated = true
+	lsi := maxLines - 1
+	links := tr.Links[:0]
+	for _, tl := range tr.Links {
+		if tl.StartSpan > lsi {
+			continue
+		}
+		if tl.EndSpan > lsi {
+			tl.EndSpan = lsi
+			tl.EndIdx = len(tr.Spans[lsi].Text) - 1
+		}
+		links = append(links, tl)
+	}
+	tr.Links = links
+}
+
+// EllipsisLR elides the text of spans that do not fit within given width
+// with an Ellipsis, at the end, start or middle per txtSty.Overflow, for LR
+// text with rune positions already set.  If clampLast is true, the last
+// span always ends with an ellipsis, after ClampLines.  Marks the text as
+// Truncated if anything is elided, and returns the new max width.
+func (tr *Text) EllipsisLR(txtSty *gist.Text, fontSty *gist.Font, trgSize float32, clampLast bool) float32 {
+	maxw := float32(0)
+	lsi := len(tr.Spans) - 1
+	for si := range tr.Spans {
+		sr := &(tr.Spans[si])
+		if sr.IsValid() != nil {
+			continue
+		}
+		wd := sr.RelPos.X + sr.LastPos.X
+		force := clampLast && si == lsi
+		over := trgSize > 0 && wd > trgSize
+		if force || (over && txtSty.Overflow != gist.TextOverflowClip) {
+			ov := txtSty.Overflow
+			if force || ov == gist.TextOverflowClip {
+				ov = gist.TextOverflowEllipsis
+			}
+			trg := trgSize
+			if !over {
+				face, _ := sr.LastFont()
+				trg = wd + EllipsisWidth(face)
+			}
+			st, ed := sr.EllipsisPosLR(trg, ov)
+			tr.ElideLinks(si, st, ed)
+			sr.ElideLR(st, ed)
+			sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
+			tr.Truncated = true
+			wd = sr.RelPos.X + sr.LastPos.X
+		}
+		maxw = mat32.Max(maxw, wd)
+	}
+	return maxw
+}
+
+// ElideLinks updates the rune indexes of links within given span for the
+// runes from st up to ed being replaced with a single Ellipsis (see ElideLR)
+func (tr *Text) ElideLinks(si, st, ed int) {
+	for li := range tr.Links {
+		tl := &tr.Links[li]
+		if tl.StartSpan == si {
+			tl.StartIdx = ElideIdx(tl.StartIdx, st, ed)
+		}
+		if tl.EndSpan == si {
+			tl.EndIdx = ElideIdx(tl.EndIdx, st, ed)
+		}
+	}
+}
+
 // NextRuneAt returns the next rune starting from given index -- could be at
 // that index or some point thereafter -- returns utf8.RuneError if no valid
 // rune could be found -- this should be a standard function!
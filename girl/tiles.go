@@ -0,0 +1,105 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/image/font"
+)
+
+// tiles.go has the tiled rendering of a deferred Vector recording: the
+// image is split into tiles, which are rasterized concurrently, each
+// clipped to its tile, so the rendering uses all of the cores.
+
+// TileSize is the default size of the tiles for RasterTiles, in dots
+var TileSize = 256
+
+// tileFonts and tileFaces are the fonts and faces of all of the Vectors
+// for deferred painting, so the font data is only loaded once -- only
+// accessed under TextFontRenderMu, as in Vector.face
+var (
+	tileFonts = make(map[string]*VecFont)
+	tileFaces = make(map[font.Face]*vecFace)
+)
+
+// NewTileVector returns a new Vector of given size for deferred painting,
+// which is rendered with RasterTiles -- set it as the Vector of a State
+// to only record the painting done with it
+func NewTileVector(size image.Point) *Vector {
+	return &Vector{Size: size, Fonts: tileFonts, faces: tileFaces, Deferred: true}
+}
+
+// RasterTiles renders the ops of the recording that are within given
+// bounds into given image, in tiles of given size (TileSize if <= 0) that
+// are rendered concurrently, one goroutine per processor.  Each op is
+// rendered in the tiles that its bounding box overlaps, clipped to each
+// tile, in the order of the ops within each tile, so the result is the
+// same as rendering the ops in order.
+func (vc *Vector) RasterTiles(img *image.RGBA, bounds image.Rectangle, tileSize int) {
+	bounds = bounds.Intersect(img.Bounds())
+	if bounds.Empty() || len(vc.Ops) == 0 {
+		return
+	}
+	if tileSize <= 0 {
+		tileSize = TileSize
+	}
+	nx := (bounds.Dx() + tileSize - 1) / tileSize
+	ny := (bounds.Dy() + tileSize - 1) / tileSize
+	tiles := make([][]int, nx*ny) // op indexes for each tile
+	for oi := range vc.Ops {
+		op := &vc.Ops[oi]
+		ob := op.BBox.Intersect(op.Bounds).Intersect(bounds).Sub(bounds.Min)
+		if ob.Empty() {
+			continue
+		}
+		for ty := ob.Min.Y / tileSize; ty <= (ob.Max.Y-1)/tileSize; ty++ {
+			for tx := ob.Min.X / tileSize; tx <= (ob.Max.X-1)/tileSize; tx++ {
+				tiles[ty*nx+tx] = append(tiles[ty*nx+tx], oi)
+			}
+		}
+	}
+	tileRect := func(ti int) image.Rectangle {
+		tr := image.Rect(0, 0, tileSize, tileSize).Add(image.Pt((ti%nx)*tileSize, (ti/nx)*tileSize))
+		return tr.Add(bounds.Min).Intersect(bounds)
+	}
+	nw := runtime.GOMAXPROCS(0)
+	if nw > len(tiles) {
+		nw = len(tiles)
+	}
+	if nw <= 1 {
+		rp := newReplayer(img)
+		for ti, ops := range tiles {
+			tr := tileRect(ti)
+			for _, oi := range ops {
+				rp.replay(&vc.Ops[oi], image.Point{}, tr)
+			}
+		}
+		return
+	}
+	var next int64 = -1
+	var wg sync.WaitGroup
+	wg.Add(nw)
+	for w := 0; w < nw; w++ {
+		go func() {
+			defer wg.Done()
+			rp := newReplayer(img)
+			for {
+				ti := int(atomic.AddInt64(&next, 1))
+				if ti >= len(tiles) {
+					return
+				}
+				tr := tileRect(ti)
+				for _, oi := range tiles[ti] {
+					rp.replay(&vc.Ops[oi], image.Point{}, tr)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
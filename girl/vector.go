@@ -0,0 +1,497 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+	"github.com/srwiley/scanx"
+	"golang.org/x/image/font"
+)
+
+// vector.go has the recording of painting as vector graphics, for output
+// as PDF (see vectorpdf.go) or SVG (see vectorsvg.go).  Painting through a
+// State with a non-nil Vector is recorded in addition to being rendered
+// into the image: paths with their fill and stroke, clips, images and text
+// glyphs, with the glyphs drawn in the fonts they were rendered in.
+// Gradient fills are recorded as an image of the gradient clipped to the
+// path, and gradient strokes as an image of the rendered stroke.
+
+// PathOps are the kinds of segments in a recorded path
+type PathOps int32
+
+const (
+	// PathMoveTo starts a new subpath at Pts[0]
+	PathMoveTo PathOps = iota
+
+	// PathLineTo is a line to Pts[0]
+	PathLineTo
+
+	// PathQuadTo is a quadratic bezier curve with control point Pts[0] to Pts[1]
+	PathQuadTo
+
+	// PathCubicTo is a cubic bezier curve with control points Pts[0] and
+	// Pts[1] to Pts[2]
+	PathCubicTo
+
+	// PathClose closes the current subpath
+	PathClose
+)
+
+// PathSeg is one segment of a recorded path, in the coordinates of the
+// Vector recording
+type PathSeg struct {
+	Op  PathOps       `desc:"kind of path segment"`
+	Pts [3]mat32.Vec2 `desc:"points of the segment, as used by the Op"`
+}
+
+// VectorOps are the kinds of recorded paint operations
+type VectorOps int32
+
+const (
+	// VecFill fills the Path, with the Color, or the Image if non-nil
+	// (a gradient), which is clipped to the Path
+	VecFill VectorOps = iota
+
+	// VecStroke strokes the Path with the Stroke style and Color
+	VecStroke
+
+	// VecImage draws the Image with the XForm
+	VecImage
+
+	// VecGlyphs draws the Glyphs of text in the Color
+	VecGlyphs
+)
+
+// VecClip is a clipping path
+type VecClip struct {
+	Path    []PathSeg `desc:"path that is clipped to"`
+	EvenOdd bool      `desc:"use the even-odd fill rule for the path instead of non-zero"`
+}
+
+// VecStrokeStyle has the stroke parameters of a recorded stroke, in the
+// coordinates of the recording
+type VecStrokeStyle struct {
+	Width      float32        `desc:"line width"`
+	MiterLimit float32        `desc:"limit of the length of miter joins, as a multiple of the width"`
+	Cap        gist.LineCaps  `desc:"how to draw the end cap of lines"`
+	Join       gist.LineJoins `desc:"how to join line segments"`
+	Dashes     []float32      `desc:"dash pattern, or nil for solid lines"`
+}
+
+// VecGlyph is a recorded glyph of text, in a font of the FontLibrary
+type VecGlyph struct {
+	Font   string     `desc:"lower-case name of the font in the FontLibrary -- the font is in the Fonts of the recording"`
+	Size   float32    `desc:"size of the font, in the dots of the recording"`
+	Index  uint16     `desc:"index of the glyph in the font"`
+	Text   string     `desc:"text that the glyph renders -- can be empty for additional glyphs of a cluster of runes"`
+	Pos    mat32.Vec2 `desc:"position of the glyph origin, on the baseline"`
+	ScaleX float32    `desc:"scaling of the X dimension, 0 = no separate scaling"`
+	RotRad float32    `desc:"rotation in radians, around the position"`
+	face   font.Face  // face that the glyph was recorded from, for replaying it at exactly the same size
+}
+
+// XForm returns the transform of the glyph, from the coordinates of the
+// glyph outline (in units of the font size, with Y up), to the recording
+func (vg *VecGlyph) XForm() mat32.Mat2 {
+	scx := float32(1)
+	if vg.ScaleX != 0 {
+		scx = vg.ScaleX
+	}
+	return mat32.Translate2D(vg.Pos.X, vg.Pos.Y).Scale(scx, 1).Rotate(vg.RotRad).Scale(vg.Size, -vg.Size)
+}
+
+// VecFont is a font used for the glyphs of a recording
+type VecFont struct {
+	Name   string            `desc:"lower-case name of the font in the FontLibrary"`
+	Data   []byte            `desc:"font file data"`
+	Glyphs map[uint16]string `desc:"glyphs of the font that are used, with the text that each renders"`
+}
+
+// VectorOp is one recorded paint operation
+type VectorOp struct {
+	Op      VectorOps       `desc:"kind of operation"`
+	Bounds  image.Rectangle `desc:"rectangle that the operation is clipped to"`
+	Clips   []VecClip       `desc:"paths that the operation is clipped to, in addition to Bounds"`
+	BBox    image.Rectangle `desc:"bounding box of what the operation draws, within the Bounds"`
+	Path    []PathSeg       `desc:"path to fill or stroke"`
+	EvenOdd bool            `desc:"fill with the even-odd fill rule instead of non-zero"`
+	Color   color.NRGBA     `desc:"color of a fill, stroke or glyphs"`
+	Stroke  VecStrokeStyle  `desc:"stroke style, for VecStroke"`
+	Image   image.Image     `desc:"image to draw, for VecImage and gradient fills"`
+	XForm   mat32.Mat2      `desc:"transform from the pixels of the Image to the recording"`
+	Glyphs  []VecGlyph      `desc:"glyphs, for VecGlyphs"`
+	Src     bool            `desc:"fill replaces what is under it instead of drawing over it, for the fill of a rectangle with a uniform color"`
+}
+
+// Vector is a recording of painting as vector graphics -- set the Vector
+// of a State to record all of the painting done with it
+type Vector struct {
+	Size     image.Point         `desc:"size of the recorded area"`
+	Ops      []VectorOp          `desc:"recorded paint operations, in order"`
+	Fonts    map[string]*VecFont `desc:"fonts used by the glyphs, by lower-case name"`
+	Deferred bool                `desc:"painting is only recorded, not rendered into the image of the State, to be rendered later with RasterTiles"`
+
+	faces map[font.Face]*vecFace
+	list  bool // recording a display list, not for output
+}
+
+// vecFace is the font of a font face, for recording its glyphs -- font is
+// nil if the face is not from a TrueType font in the FontLibrary, which
+// can be embedded
+type vecFace struct {
+	font *VecFont
+	size int
+}
+
+// NewVector returns a new Vector recording of given size
+func NewVector(size image.Point) *Vector {
+	return &Vector{Size: size, Fonts: make(map[string]*VecFont)}
+}
+
+// face returns the vecFace for given font face, which is loaded the first
+// time -- must be called under TextFontRenderMu.
+func (vc *Vector) face(face font.Face) *vecFace {
+	if vf, ok := vc.faces[face]; ok {
+		return vf
+	}
+	if vc.faces == nil {
+		vc.faces = make(map[font.Face]*vecFace)
+	}
+	vf := &vecFace{}
+	fontnm, size := FontLibrary.FaceNameSize(face)
+	if fontnm != "" {
+		vf.size = size
+		vf.font = vc.Fonts[fontnm]
+		if vf.font == nil {
+			if data := fontData(fontnm); IsTrueTypeGlyf(data) {
+				vf.font = &VecFont{Name: fontnm, Data: data, Glyphs: make(map[uint16]string)}
+				vc.Fonts[fontnm] = vf.font
+			}
+		}
+	}
+	vc.faces[face] = vf
+	return vf
+}
+
+// PageRects returns the regions of the recording to put on each page, for
+// pages of given height -- the pages break between lines of text and
+// small images where possible.
+func (vc *Vector) PageRects(pageHt int) []image.Rectangle {
+	if pageHt <= 0 {
+		return []image.Rectangle{{Max: vc.Size}}
+	}
+	type span struct{ st, ed int }
+	var spans []span
+	for oi := range vc.Ops {
+		op := &vc.Ops[oi]
+		switch op.Op {
+		case VecGlyphs:
+			for gi := range op.Glyphs {
+				g := &op.Glyphs[gi]
+				spans = append(spans, span{int(g.Pos.Y - g.Size), int(mat32.Ceil(g.Pos.Y + 0.3*g.Size))})
+			}
+		case VecImage:
+			if op.BBox.Dy() < pageHt/4 {
+				spans = append(spans, span{op.BBox.Min.Y, op.BBox.Max.Y})
+			}
+		}
+	}
+	var pages []image.Rectangle
+	for y := 0; y < vc.Size.Y; {
+		ed := y + pageHt
+		if ed < vc.Size.Y {
+			brk := ed
+			for moved := true; moved; {
+				moved = false
+				for _, sp := range spans {
+					if sp.st < brk && sp.ed > brk && sp.st > y {
+						brk = sp.st
+						moved = true
+					}
+				}
+			}
+			if brk-y >= pageHt/2 {
+				ed = brk
+			}
+		}
+		pages = append(pages, image.Rect(0, y, vc.Size.X, ed))
+		y = ed
+	}
+	if len(pages) == 0 {
+		pages = append(pages, image.Rectangle{Max: vc.Size})
+	}
+	return pages
+}
+
+// PathBBox returns the bounding box of the points of given path
+func PathBBox(path []PathSeg) image.Rectangle {
+	var min, max mat32.Vec2
+	first := true
+	for _, sg := range path {
+		np := 0
+		switch sg.Op {
+		case PathMoveTo, PathLineTo:
+			np = 1
+		case PathQuadTo:
+			np = 2
+		case PathCubicTo:
+			np = 3
+		}
+		for _, p := range sg.Pts[:np] {
+			if first {
+				min, max = p, p
+				first = false
+			}
+			min.SetMin(p)
+			max.SetMax(p)
+		}
+	}
+	return image.Rect(int(mat32.Floor(min.X)), int(mat32.Floor(min.Y)), int(mat32.Ceil(max.X)), int(mat32.Ceil(max.Y)))
+}
+
+// XFormBBox returns the bounding box of given rectangle after transforming
+// it by given transform
+func XFormBBox(xf mat32.Mat2, r image.Rectangle) image.Rectangle {
+	fr := mat32.NewVec2FmPoint(r.Min)
+	to := mat32.NewVec2FmPoint(r.Max)
+	min := xf.MulVec2AsPt(fr)
+	max := min
+	for _, p := range []mat32.Vec2{{X: to.X, Y: fr.Y}, {X: fr.X, Y: to.Y}, to} {
+		tp := xf.MulVec2AsPt(p)
+		min.SetMin(tp)
+		max.SetMax(tp)
+	}
+	return image.Rect(int(mat32.Floor(min.X)), int(mat32.Floor(min.Y)), int(mat32.Ceil(max.X)), int(mat32.Ceil(max.Y)))
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+//  State recording
+
+// VectorFrom sets this state to record into the Vector of given parent
+// state, if it has one, for a state that renders into a region of the
+// parent's image (e.g., a sub-viewport): off is the position of this
+// state's image within the parent image, and clip is the region of the
+// parent image that is drawn into.
+func (rs *State) VectorFrom(prs *State, off image.Point, clip image.Rectangle) {
+	rs.Vector = prs.Vector
+	if rs.Vector == nil || rs.Vector.Deferred { // rendered into its own image, which is recorded
+		rs.Vector = nil
+		return
+	}
+	rs.VecOff = prs.VecOff.Add(off)
+	rs.VecBounds = prs.vecBounds().Intersect(clip.Add(prs.VecOff))
+}
+
+// RecordingVector returns true if painting is being recorded into a
+// Vector for output, e.g., to PDF, and not into a display list or for
+// deferred rendering
+func (rs *State) RecordingVector() bool {
+	return rs.Vector != nil && !rs.Vector.list && !rs.Vector.Deferred
+}
+
+// Deferred returns true if painting is only being recorded, to be
+// rendered later (see Vector.Deferred)
+func (rs *State) Deferred() bool {
+	return rs.Vector != nil && rs.Vector.Deferred
+}
+
+// vecBounds returns the current bounds in the coordinates of the Vector
+func (rs *State) vecBounds() image.Rectangle {
+	b := rs.Bounds
+	if b.Empty() && rs.Image != nil {
+		b = rs.Image.Bounds()
+	}
+	b = b.Add(rs.VecOff)
+	if !rs.VecBounds.Empty() {
+		b = b.Intersect(rs.VecBounds)
+	}
+	return b
+}
+
+// vecPathAdd adds a segment to the recorded path, with given points in the
+// coordinates of the image
+func (rs *State) vecPathAdd(op PathOps, pts ...mat32.Vec2) {
+	sg := PathSeg{Op: op}
+	off := mat32.NewVec2FmPoint(rs.VecOff)
+	for i, p := range pts {
+		sg.Pts[i] = p.Add(off)
+	}
+	rs.VecPath = append(rs.VecPath, sg)
+}
+
+// vecOp adds given operation to the recording, with the current bounds and
+// clips, and the BBox limited to the bounds -- the op is not added if it is
+// not within the bounds.  Consecutive glyphs with the same color and clips
+// are merged into one op.
+func (rs *State) vecOp(op VectorOp) {
+	vc := rs.Vector
+	op.Bounds = rs.vecBounds()
+	op.Clips = rs.VecClips
+	op.BBox = op.BBox.Intersect(op.Bounds)
+	if op.BBox.Empty() {
+		return
+	}
+	if n := len(vc.Ops); n > 0 && op.Op == VecGlyphs {
+		lop := &vc.Ops[n-1]
+		if lop.Op == VecGlyphs && lop.Color == op.Color && lop.Bounds == op.Bounds && sameVecClips(lop.Clips, op.Clips) {
+			lop.Glyphs = append(lop.Glyphs, op.Glyphs...)
+			lop.BBox = lop.BBox.Union(op.BBox)
+			return
+		}
+	}
+	vc.Ops = append(vc.Ops, op)
+}
+
+// sameVecClips returns true if the given clips are the same
+func sameVecClips(a, b []VecClip) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// vecPath returns a copy of the current recorded path
+func (rs *State) vecPath() []PathSeg {
+	return append([]PathSeg(nil), rs.VecPath...)
+}
+
+// vecClip adds the current path as a clip
+func (rs *State) vecClip(evenOdd bool) {
+	clips := make([]VecClip, len(rs.VecClips), len(rs.VecClips)+1)
+	copy(clips, rs.VecClips)
+	rs.VecClips = append(clips, VecClip{Path: rs.vecPath(), EvenOdd: evenOdd})
+}
+
+// vecColor returns the render color for the recording, either a solid
+// color or an image of the gradient within given bounds of the image
+func vecColor(clr interface{}, bbox image.Rectangle) (color.NRGBA, image.Image) {
+	switch c := clr.(type) {
+	case rasterx.ColorFunc:
+		img := image.NewNRGBA(bbox)
+		for y := bbox.Min.Y; y < bbox.Max.Y; y++ {
+			for x := bbox.Min.X; x < bbox.Max.X; x++ {
+				img.Set(x, y, c(x, y))
+			}
+		}
+		return color.NRGBA{}, img
+	case color.Color:
+		return color.NRGBAModel.Convert(c).(color.NRGBA), nil
+	}
+	return color.NRGBA{}, nil
+}
+
+// vecFill records the fill of the current path with given render color
+func (rs *State) vecFill(clr interface{}, evenOdd bool) {
+	bbox := rs.LastRenderBBox.Intersect(rs.Image.Bounds())
+	op := VectorOp{Op: VecFill, Path: rs.vecPath(), EvenOdd: evenOdd, BBox: PathBBox(rs.VecPath)}
+	op.Color, op.Image = vecColor(clr, bbox)
+	if op.Image != nil {
+		op.XForm = mat32.Translate2D(float32(rs.VecOff.X), float32(rs.VecOff.Y))
+	} else if op.Color.A == 0 {
+		return
+	}
+	rs.vecOp(op)
+}
+
+// vecStroke records the stroke of the current path with given render
+// color and stroke parameters -- gradient strokes are rendered into an
+// image that is recorded.
+func (rs *State) vecStroke(clr interface{}, st VecStrokeStyle, capfunc rasterx.CapFunc, join rasterx.JoinMode, dash []float64) {
+	bbox := rs.LastRenderBBox.Intersect(rs.Image.Bounds())
+	c, _ := vecColor(clr, image.Rectangle{})
+	if _, isf := clr.(rasterx.ColorFunc); isf {
+		if bbox.Empty() {
+			return
+		}
+		sz := rs.Image.Bounds().Size()
+		img := image.NewRGBA(image.Rectangle{Max: sz})
+		sc := scanx.NewScanner(scanx.NewImgSpanner(img), sz.X, sz.Y)
+		sc.SetClip(bbox)
+		ds := rasterx.NewDasher(sz.X, sz.Y, sc)
+		ds.SetStroke(mat32.ToFixed(st.Width), mat32.ToFixed(st.MiterLimit), capfunc, nil, nil, join, dash, 0)
+		rs.Path.AddTo(ds)
+		ds.SetColor(clr)
+		ds.Draw()
+		rs.vecOp(VectorOp{Op: VecImage, Image: img.SubImage(bbox), XForm: mat32.Translate2D(float32(rs.VecOff.X), float32(rs.VecOff.Y)), BBox: bbox.Add(rs.VecOff)})
+		return
+	}
+	if c.A == 0 || st.Width == 0 {
+		return
+	}
+	hw := int(mat32.Ceil(st.Width * mat32.Max(st.MiterLimit, 1) / 2))
+	rs.vecOp(VectorOp{Op: VecStroke, Path: rs.vecPath(), Color: c, Stroke: st, BBox: PathBBox(rs.VecPath).Inset(-hw)})
+}
+
+// vecRect records the fill of given rectangle in the image with given color
+func (rs *State) vecRect(r image.Rectangle, clr color.Color) {
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	if (c.A == 0 && !rs.Deferred()) || r.Empty() { // a transparent fill clears when deferred
+		return
+	}
+	fr := mat32.NewVec2FmPoint(r.Min.Add(rs.VecOff))
+	to := mat32.NewVec2FmPoint(r.Max.Add(rs.VecOff))
+	path := []PathSeg{{Op: PathMoveTo, Pts: [3]mat32.Vec2{fr}}, {Op: PathLineTo, Pts: [3]mat32.Vec2{{X: to.X, Y: fr.Y}}},
+		{Op: PathLineTo, Pts: [3]mat32.Vec2{to}}, {Op: PathLineTo, Pts: [3]mat32.Vec2{{X: fr.X, Y: to.Y}}}, {Op: PathClose}}
+	rs.vecOp(VectorOp{Op: VecFill, Path: path, Color: c, BBox: r.Add(rs.VecOff), Src: true})
+}
+
+// vecImage records the drawing of given image with given transform from
+// its pixels to the image of the state
+func (rs *State) vecImage(img image.Image, xf mat32.Mat2) {
+	xf = xf.Mul(mat32.Translate2D(float32(rs.VecOff.X), float32(rs.VecOff.Y)))
+	rs.vecOp(VectorOp{Op: VecImage, Image: img, XForm: xf, BBox: XFormBBox(xf, img.Bounds())})
+}
+
+// VectorImage records the drawing of the region of given image at sp into
+// the region r of the image of the state, if recording -- for code that
+// draws into the image directly with draw.Draw.
+func (rs *State) VectorImage(img image.Image, r image.Rectangle, sp image.Point) {
+	if rs.Vector == nil {
+		return
+	}
+	sr := image.Rectangle{Min: sp, Max: sp.Add(r.Size())}.Add(img.Bounds().Min)
+	sub := image.NewRGBA(image.Rectangle{Max: r.Size()})
+	draw.Draw(sub, sub.Rect, img, sr.Min, draw.Src)
+	rs.vecImage(sub, mat32.Translate2D(float32(r.Min.X), float32(r.Min.Y)))
+}
+
+// vecMask records the drawing of given color through given mask, at
+// given transform from the pixels of the mask region mr to the image
+func (rs *State) vecMask(clr color.Color, mask image.Image, mr image.Rectangle, xf mat32.Mat2) {
+	img := image.NewRGBA(image.Rectangle{Max: mr.Size()})
+	draw.DrawMask(img, img.Rect, image.NewUniform(clr), image.Point{}, mask, mr.Min, draw.Src)
+	rs.vecImage(img, xf)
+}
+
+// vectorGlyph records a glyph of given face, with given glyph index in
+// the font, or looked up from the rune r if idx < 0, rendering given
+// text at absolute position rp.  If the font of the face cannot be
+// embedded (it is not TrueType, or not from the FontLibrary), the glyph
+// mask (as returned by the face for rp) is recorded as an image.
+// Must be called under TextFontRenderMu.
+func (rr *Rune) vectorGlyph(rs *State, face font.Face, r rune, idx int, text string, rp mat32.Vec2, clr color.Color, dr image.Rectangle, mask image.Image, maskp image.Point) {
+	vf := rs.Vector.face(face)
+	if idx < 0 && vf.font != nil {
+		if tf := shapeFont(vf.font.Name); tf != nil {
+			if gid, has := tf.NominalGlyph(r); has {
+				idx = int(gid)
+			}
+		}
+	}
+	if vf.font == nil || idx < 0 || idx > 0xFFFF {
+		rs.vecMask(clr, mask, image.Rectangle{Min: maskp, Max: maskp.Add(dr.Size())}, rr.glyphXForm(rp, dr))
+		return
+	}
+	if _, has := vf.font.Glyphs[uint16(idx)]; !has || text != "" {
+		vf.font.Glyphs[uint16(idx)] = text
+	}
+	off := mat32.NewVec2FmPoint(rs.VecOff)
+	g := VecGlyph{Font: vf.font.Name, Size: float32(vf.size), Index: uint16(idx), Text: text, Pos: rp.Add(off), ScaleX: rr.ScaleX, RotRad: rr.RotRad, face: face}
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	rs.vecOp(VectorOp{Op: VecGlyphs, Color: c, Glyphs: []VecGlyph{g}, BBox: XFormBBox(rr.glyphXForm(rp, dr), dr.Sub(dr.Min)).Add(rs.VecOff)})
+}
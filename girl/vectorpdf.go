@@ -0,0 +1,473 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+)
+
+// PDFOptions are the options for writing a Vector recording as PDF
+type PDFOptions struct {
+	PageSize mat32.Vec2 `desc:"size of the pages, in points (1/72 inch) -- the recording is scaled to fit the width of the page within the margins, and broken into as many pages as needed"`
+	Margin   float32    `desc:"margin on each side of the page, in points"`
+	Title    string     `desc:"title of the document"`
+}
+
+// DefaultPDFOptions are the PDF options used if none are given: US Letter
+// pages with half inch margins
+var DefaultPDFOptions = PDFOptions{PageSize: mat32.Vec2{X: 612, Y: 792}, Margin: 36}
+
+// SavePDF saves the recording as a PDF file (see WritePDF)
+func (vc *Vector) SavePDF(path string, opts *PDFOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if err := vc.WritePDF(bw, opts); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WritePDF writes the recording as a PDF document, with the fonts of the
+// text embedded as subsets of the glyphs used -- the recording is scaled
+// to fit the width of the pages, and broken into pages (see PageRects).
+// nil opts uses the DefaultPDFOptions.
+func (vc *Vector) WritePDF(w io.Writer, opts *PDFOptions) error {
+	if opts == nil {
+		opts = &DefaultPDFOptions
+	}
+	pw := &pdfWriter{vc: vc, fonts: make(map[string]*pdfFont), images: make(map[image.Image]string), gstates: make(map[[2]uint8]string)}
+	pw.objs = make([][]byte, 3) // 1 = catalog, 2 = pages, 3 = resources
+	psz := opts.PageSize
+	if psz.X <= 0 || psz.Y <= 0 {
+		psz = DefaultPDFOptions.PageSize
+	}
+	scale := float32(1)
+	if vc.Size.X > 0 {
+		scale = (psz.X - 2*opts.Margin) / float32(vc.Size.X)
+	}
+	pages := vc.PageRects(int((psz.Y - 2*opts.Margin) / scale))
+	var kids []string
+	for _, pr := range pages {
+		var cb bytes.Buffer
+		// scale and flip the dots of the recording to points on the page
+		fmt.Fprintf(&cb, "%s 0 0 %s %s %s cm\n", pdfNum(scale), pdfNum(-scale), pdfNum(opts.Margin-float32(pr.Min.X)*scale), pdfNum(psz.Y-opts.Margin+float32(pr.Min.Y)*scale))
+		pdfRect(&cb, pr)
+		cb.WriteString("W n\n")
+		for oi := range vc.Ops {
+			op := &vc.Ops[oi]
+			if op.BBox.Overlaps(pr) {
+				pw.writeOp(&cb, op)
+			}
+		}
+		cid := pw.addStream("", cb.Bytes())
+		pid := pw.addObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources 3 0 R /Contents %d 0 R >>", pdfNum(psz.X), pdfNum(psz.Y), cid))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pid))
+	}
+	var fres strings.Builder
+	fnms := make([]string, 0, len(pw.fonts))
+	for fnm := range pw.fonts {
+		fnms = append(fnms, fnm)
+	}
+	sort.Strings(fnms)
+	for _, fnm := range fnms {
+		pf := pw.fonts[fnm]
+		fid, err := pw.addFont(pf)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&fres, "/%s %d 0 R ", pf.res, fid)
+	}
+	gsts := make([]string, 0, len(pw.gstates))
+	for a, nm := range pw.gstates {
+		gsts = append(gsts, fmt.Sprintf("/%s << /ca %s /CA %s >> ", nm, pdfNum(float32(a[0])/255), pdfNum(float32(a[1])/255)))
+	}
+	sort.Strings(gsts)
+	pw.objs[0] = []byte("<< /Type /Catalog /Pages 2 0 R >>")
+	pw.objs[1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	pw.objs[2] = []byte(fmt.Sprintf("<< /ProcSet [/PDF /Text /ImageB /ImageC] /Font << %s>> /XObject << %s>> /ExtGState << %s>> >>", fres.String(), pw.xobjs.String(), strings.Join(gsts, "")))
+	info := 0
+	if opts.Title != "" {
+		info = pw.addObj(fmt.Sprintf("<< /Title %s /Producer (GoGi) >>", pdfString(opts.Title)))
+	}
+	return pw.write(w, info)
+}
+
+// pdfWriter has the state for writing a PDF document
+type pdfWriter struct {
+	vc      *Vector
+	objs    [][]byte // objects, numbered from 1
+	fonts   map[string]*pdfFont
+	images  map[image.Image]string // resource name of each image
+	xobjs   strings.Builder        // image resources
+	gstates map[[2]uint8]string    // resource name of each fill, stroke alpha
+}
+
+// pdfFont is a font used in the document
+type pdfFont struct {
+	font *VecFont
+	res  string // resource name
+}
+
+// addObj adds an object, returning its number
+func (pw *pdfWriter) addObj(obj string) int {
+	pw.objs = append(pw.objs, []byte(obj))
+	return len(pw.objs)
+}
+
+// addStream adds a compressed stream object with given extra dictionary
+// entries, returning its number
+func (pw *pdfWriter) addStream(dict string, data []byte) int {
+	var zb bytes.Buffer
+	zw := zlib.NewWriter(&zb)
+	zw.Write(data)
+	zw.Close()
+	obj := fmt.Sprintf("<< %s/Length %d /Filter /FlateDecode >>\nstream\n", dict, zb.Len())
+	pw.objs = append(pw.objs, append(append([]byte(obj), zb.Bytes()...), "\nendstream"...))
+	return len(pw.objs)
+}
+
+// write writes the document, with the objects and their cross-reference table
+func (pw *pdfWriter) write(w io.Writer, info int) error {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offs := make([]int, len(pw.objs))
+	for i, obj := range pw.objs {
+		offs[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", i+1)
+		b.Write(obj)
+		b.WriteString("\nendobj\n")
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(pw.objs)+1)
+	for _, off := range offs {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R ", len(pw.objs)+1)
+	if info > 0 {
+		fmt.Fprintf(&b, "/Info %d 0 R ", info)
+	}
+	fmt.Fprintf(&b, ">>\nstartxref\n%d\n%%%%EOF\n", xref)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeOp writes the content stream commands for given op
+func (pw *pdfWriter) writeOp(b *bytes.Buffer, op *VectorOp) {
+	b.WriteString("q\n")
+	pdfRect(b, op.Bounds)
+	b.WriteString("W n\n")
+	for _, cl := range op.Clips {
+		pdfPath(b, cl.Path)
+		if cl.EvenOdd {
+			b.WriteString("W* n\n")
+		} else {
+			b.WriteString("W n\n")
+		}
+	}
+	switch op.Op {
+	case VecFill:
+		pdfPath(b, op.Path)
+		if op.Image != nil {
+			if op.EvenOdd {
+				b.WriteString("W* n\n")
+			} else {
+				b.WriteString("W n\n")
+			}
+			pw.writeImage(b, op.Image, op.XForm)
+			break
+		}
+		pw.writeColor(b, op.Color, false)
+		if op.EvenOdd {
+			b.WriteString("f*\n")
+		} else {
+			b.WriteString("f\n")
+		}
+	case VecStroke:
+		st := &op.Stroke
+		fmt.Fprintf(b, "%s w %d J %d j %s M\n", pdfNum(st.Width), pdfCap(st.Cap), pdfJoin(st.Join), pdfNum(mat32.Max(st.MiterLimit, 1)))
+		if len(st.Dashes) > 0 {
+			b.WriteString("[")
+			for _, d := range st.Dashes {
+				b.WriteString(pdfNum(d) + " ")
+			}
+			b.WriteString("] 0 d\n")
+		}
+		pw.writeColor(b, op.Color, true)
+		pdfPath(b, op.Path)
+		b.WriteString("S\n")
+	case VecImage:
+		pw.writeImage(b, op.Image, op.XForm)
+	case VecGlyphs:
+		pw.writeColor(b, op.Color, false)
+		b.WriteString("BT\n")
+		var curFont string
+		for gi := range op.Glyphs {
+			g := &op.Glyphs[gi]
+			pf := pw.font(g.Font)
+			if pf.res != curFont { // the size is in the text matrix
+				fmt.Fprintf(b, "/%s 1 Tf\n", pf.res)
+				curFont = pf.res
+			}
+			pdfMatrix(b, g.XForm())
+			fmt.Fprintf(b, " Tm <%04X> Tj\n", g.Index)
+		}
+		b.WriteString("ET\n")
+	}
+	b.WriteString("Q\n")
+}
+
+// writeColor writes the commands to set given fill or stroke color,
+// including its alpha
+func (pw *pdfWriter) writeColor(b *bytes.Buffer, c color.NRGBA, stroke bool) {
+	op := "rg"
+	if stroke {
+		op = "RG"
+	}
+	fmt.Fprintf(b, "%s %s %s %s\n", pdfNum(float32(c.R)/255), pdfNum(float32(c.G)/255), pdfNum(float32(c.B)/255), op)
+	if c.A < 255 {
+		a := [2]uint8{c.A, 255}
+		if stroke {
+			a = [2]uint8{255, c.A}
+		}
+		nm, has := pw.gstates[a]
+		if !has {
+			nm = fmt.Sprintf("GS%d", len(pw.gstates)+1)
+			pw.gstates[a] = nm
+		}
+		fmt.Fprintf(b, "/%s gs\n", nm)
+	}
+}
+
+// writeImage writes the commands to draw given image with given transform
+// from its pixels, adding the image to the document if it is new
+func (pw *pdfWriter) writeImage(b *bytes.Buffer, img image.Image, xf mat32.Mat2) {
+	nm, has := pw.images[img]
+	if !has {
+		nm = fmt.Sprintf("Im%d", len(pw.images)+1)
+		pw.images[img] = nm
+		fmt.Fprintf(&pw.xobjs, "/%s %d 0 R ", nm, pw.addImage(img))
+	}
+	// map the unit square of the image (with y up) to its pixels
+	ib := img.Bounds()
+	w, h := float32(ib.Dx()), float32(ib.Dy())
+	m := mat32.Mat2{XX: w, YY: -h, X0: float32(ib.Min.X), Y0: float32(ib.Min.Y) + h}.Mul(xf)
+	b.WriteString("q ")
+	pdfMatrix(b, m)
+	fmt.Fprintf(b, " cm /%s Do Q\n", nm)
+}
+
+// addImage adds given image as an image object, with a soft mask for its
+// alpha if it is not opaque, returning its number
+func (pw *pdfWriter) addImage(img image.Image) int {
+	ib := img.Bounds()
+	rgb := make([]byte, 0, 3*ib.Dx()*ib.Dy())
+	alpha := make([]byte, 0, ib.Dx()*ib.Dy())
+	opaque := true
+	for y := ib.Min.Y; y < ib.Max.Y; y++ {
+		for x := ib.Min.X; x < ib.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			if c.A < 255 {
+				opaque = false
+			}
+		}
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8 /Interpolate true ", ib.Dx(), ib.Dy())
+	smask := ""
+	if !opaque {
+		smask = fmt.Sprintf("/SMask %d 0 R ", pw.addStream(dict+"/ColorSpace /DeviceGray ", alpha))
+	}
+	return pw.addStream(dict+"/ColorSpace /DeviceRGB "+smask, rgb)
+}
+
+// font returns the pdfFont for given font name
+func (pw *pdfWriter) font(fontnm string) *pdfFont {
+	pf, has := pw.fonts[fontnm]
+	if !has {
+		pf = &pdfFont{font: pw.vc.Fonts[fontnm], res: fmt.Sprintf("F%d", len(pw.fonts)+1)}
+		pw.fonts[fontnm] = pf
+	}
+	return pf
+}
+
+// addFont adds the objects for given font, as a Type0 font with a
+// TrueType subset of the glyphs used, returning the number of the font
+func (pw *pdfWriter) addFont(pf *pdfFont) (int, error) {
+	vf := pf.font
+	gids := make([]int, 0, len(vf.Glyphs))
+	used := make(map[uint16]bool, len(vf.Glyphs))
+	for gid := range vf.Glyphs {
+		gids = append(gids, int(gid))
+		used[gid] = true
+	}
+	sort.Ints(gids)
+	sub, err := SubsetTTF(vf.Data, used)
+	if err != nil {
+		return 0, err
+	}
+	mt, _ := GetTTFMetrics(vf.Data)
+	em := func(v int) string {
+		return strconv.Itoa(v * 1000 / mt.UnitsPerEm)
+	}
+
+	// subset fonts are named with a tag from the glyphs
+	h := fnv.New32a()
+	for _, gid := range gids {
+		h.Write([]byte{byte(gid >> 8), byte(gid)})
+	}
+	tag := make([]byte, 6)
+	for i, hv := 0, h.Sum32(); i < 6; i, hv = i+1, hv/26 {
+		tag[i] = byte('A' + hv%26)
+	}
+	fnm := strings.Map(func(r rune) rune {
+		if r > 0x20 && r < 0x7F && !strings.ContainsRune("()<>[]{}/%#", r) && !unicode.IsSpace(r) {
+			return r
+		}
+		return -1
+	}, FontLibrary.FontInfoName(vf.Name))
+	basenm := string(tag) + "+" + fnm
+
+	ffid := pw.addStream(fmt.Sprintf("/Length1 %d ", len(sub)), sub)
+	fdid := pw.addObj(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%s %s %s %s] /ItalicAngle 0 /Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %d 0 R >>",
+		basenm, em(mt.BBox[0]), em(mt.BBox[1]), em(mt.BBox[2]), em(mt.BBox[3]), em(mt.Ascent), em(mt.Descent), em(mt.Ascent), ffid))
+	var ws strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&ws, "%d [%s] ", gid, em(mt.Advance(uint16(gid))))
+	}
+	cfid := pw.addObj(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		basenm, fdid, ws.String()))
+	tuid := pw.addStream("", pdfToUnicode(vf, gids))
+	return pw.addObj(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", basenm, cfid, tuid)), nil
+}
+
+// pdfToUnicode returns the ToUnicode CMap for the glyphs of given font
+func pdfToUnicode(vf *VecFont, gids []int) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var chars []string
+	for _, gid := range gids {
+		txt := vf.Glyphs[uint16(gid)]
+		if txt == "" {
+			continue
+		}
+		var hex strings.Builder
+		for _, u := range utf16.Encode([]rune(txt)) {
+			fmt.Fprintf(&hex, "%04X", u)
+		}
+		chars = append(chars, fmt.Sprintf("<%04X> <%s>\n", gid, hex.String()))
+	}
+	for st := 0; st < len(chars); st += 100 {
+		ed := st + 100
+		if ed > len(chars) {
+			ed = len(chars)
+		}
+		fmt.Fprintf(&b, "%d beginbfchar\n%sendbfchar\n", ed-st, strings.Join(chars[st:ed], ""))
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// pdfNum returns given number formatted for PDF
+func pdfNum(v float32) string {
+	s := strconv.FormatFloat(float64(v), 'f', 3, 32)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfString returns given text as a PDF text string
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// pdfMatrix writes given transform as the six numbers of a PDF matrix
+func pdfMatrix(b *bytes.Buffer, m mat32.Mat2) {
+	fmt.Fprintf(b, "%s %s %s %s %s %s", pdfNum(m.XX), pdfNum(m.YX), pdfNum(m.XY), pdfNum(m.YY), pdfNum(m.X0), pdfNum(m.Y0))
+}
+
+// pdfRect writes the path of given rectangle
+func pdfRect(b *bytes.Buffer, r image.Rectangle) {
+	fmt.Fprintf(b, "%d %d %d %d re\n", r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+}
+
+// pdfPath writes the commands for given path
+func pdfPath(b *bytes.Buffer, path []PathSeg) {
+	var cur, start mat32.Vec2
+	for _, sg := range path {
+		p := sg.Pts
+		switch sg.Op {
+		case PathMoveTo:
+			fmt.Fprintf(b, "%s %s m\n", pdfNum(p[0].X), pdfNum(p[0].Y))
+			cur, start = p[0], p[0]
+		case PathLineTo:
+			fmt.Fprintf(b, "%s %s l\n", pdfNum(p[0].X), pdfNum(p[0].Y))
+			cur = p[0]
+		case PathQuadTo: // as a cubic, which is all that PDF has
+			c1 := cur.Add(p[0].Sub(cur).MulScalar(2.0 / 3.0))
+			c2 := p[1].Add(p[0].Sub(p[1]).MulScalar(2.0 / 3.0))
+			fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(c1.X), pdfNum(c1.Y), pdfNum(c2.X), pdfNum(c2.Y), pdfNum(p[1].X), pdfNum(p[1].Y))
+			cur = p[1]
+		case PathCubicTo:
+			fmt.Fprintf(b, "%s %s %s %s %s %s c\n", pdfNum(p[0].X), pdfNum(p[0].Y), pdfNum(p[1].X), pdfNum(p[1].Y), pdfNum(p[2].X), pdfNum(p[2].Y))
+			cur = p[2]
+		case PathClose:
+			b.WriteString("h\n")
+			cur = start
+		}
+	}
+}
+
+// pdfCap returns the PDF line cap style for given line cap -- the caps
+// that PDF does not have are drawn as round caps
+func pdfCap(c gist.LineCaps) int {
+	switch c {
+	case gist.LineCapButt:
+		return 0
+	case gist.LineCapSquare:
+		return 2
+	}
+	return 1
+}
+
+// pdfJoin returns the PDF line join style for given line join -- the
+// joins that PDF does not have are drawn as the closest one it has
+func pdfJoin(j gist.LineJoins) int {
+	switch j {
+	case gist.LineJoinMiter, gist.LineJoinMiterClip:
+		return 0
+	case gist.LineJoinBevel:
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+)
+
+// SaveSVG saves the recording as an SVG file (see WriteSVG)
+func (vc *Vector) SaveSVG(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if err := vc.WriteSVG(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WriteSVG writes the recording as an SVG document, in the dots of the
+// recording.  Text is written as text elements in the font family, weight
+// and style of its fonts, which are not embedded, and images are embedded
+// as PNG.
+func (vc *Vector) WriteSVG(w io.Writer) error {
+	sw := &svgWriter{vc: vc, clips: make(map[string]string), images: make(map[image.Image]string)}
+	var body bytes.Buffer
+	for oi := range vc.Ops {
+		if err := sw.writeOp(&body, &vc.Ops[oi]); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", vc.Size.X, vc.Size.Y, vc.Size.X, vc.Size.Y)
+	if sw.defs.Len() > 0 {
+		fmt.Fprintf(&b, "<defs>\n%s</defs>\n", sw.defs.String())
+	}
+	b.Write(body.Bytes())
+	b.WriteString("</svg>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// svgWriter has the state for writing an SVG document
+type svgWriter struct {
+	vc     *Vector
+	defs   bytes.Buffer
+	clips  map[string]string      // id of the clipPath for each clip
+	images map[image.Image]string // id of each image, which is used again
+}
+
+// clipID returns the id of the clipPath with given path data and fill
+// rule, adding it to the defs if it is new
+func (sw *svgWriter) clipID(d string, evenOdd bool) string {
+	key := fmt.Sprintf("%v %s", evenOdd, d)
+	if id, has := sw.clips[key]; has {
+		return id
+	}
+	id := fmt.Sprintf("clip%d", len(sw.clips)+1)
+	sw.clips[key] = id
+	rule := "nonzero"
+	if evenOdd {
+		rule = "evenodd"
+	}
+	fmt.Fprintf(&sw.defs, "<clipPath id=\"%s\"><path d=\"%s\" clip-rule=\"%s\"/></clipPath>\n", id, d, rule)
+	return id
+}
+
+// writeOp writes the elements for given op, in a group for each clip --
+// SVG clip paths are intersected by nesting them
+func (sw *svgWriter) writeOp(b *bytes.Buffer, op *VectorOp) error {
+	r := op.Bounds
+	ngrp := 1
+	fmt.Fprintf(b, "<g clip-path=\"url(#%s)\">", sw.clipID(fmt.Sprintf("M%d %dH%dV%dH%dZ", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, r.Min.X), false))
+	for _, cl := range op.Clips {
+		fmt.Fprintf(b, "<g clip-path=\"url(#%s)\">", sw.clipID(svgPath(cl.Path), cl.EvenOdd))
+		ngrp++
+	}
+	b.WriteString("\n")
+	switch op.Op {
+	case VecFill:
+		rule := ""
+		if op.EvenOdd {
+			rule = " fill-rule=\"evenodd\""
+		}
+		if op.Image != nil {
+			fmt.Fprintf(b, "<g clip-path=\"url(#%s)\">", sw.clipID(svgPath(op.Path), op.EvenOdd))
+			if err := sw.writeImage(b, op.Image, op.XForm); err != nil {
+				return err
+			}
+			b.WriteString("</g>\n")
+			break
+		}
+		fmt.Fprintf(b, "<path d=\"%s\" fill=\"%s\"%s%s/>\n", svgPath(op.Path), svgColor(op.Color), svgOpacity("fill-opacity", op.Color), rule)
+	case VecStroke:
+		st := &op.Stroke
+		fmt.Fprintf(b, "<path d=\"%s\" fill=\"none\" stroke=\"%s\"%s stroke-width=\"%s\" stroke-linecap=\"%s\" stroke-linejoin=\"%s\" stroke-miterlimit=\"%s\"",
+			svgPath(op.Path), svgColor(op.Color), svgOpacity("stroke-opacity", op.Color), pdfNum(st.Width), svgCap(st.Cap), svgJoin(st.Join), pdfNum(mat32.Max(st.MiterLimit, 1)))
+		if len(st.Dashes) > 0 {
+			ds := make([]string, len(st.Dashes))
+			for i, d := range st.Dashes {
+				ds[i] = pdfNum(d)
+			}
+			fmt.Fprintf(b, " stroke-dasharray=\"%s\"", strings.Join(ds, " "))
+		}
+		b.WriteString("/>\n")
+	case VecImage:
+		if err := sw.writeImage(b, op.Image, op.XForm); err != nil {
+			return err
+		}
+	case VecGlyphs:
+		fmt.Fprintf(b, "<g fill=\"%s\"%s>\n", svgColor(op.Color), svgOpacity("fill-opacity", op.Color))
+		for gi := range op.Glyphs {
+			g := &op.Glyphs[gi]
+			if strings.TrimSpace(g.Text) == "" {
+				continue
+			}
+			fam, wt, sty := sw.fontStyle(g.Font)
+			fmt.Fprintf(b, "<text font-family=\"%s\" font-weight=\"%d\" font-style=\"%s\" font-size=\"%s\" ", fam, wt, sty, pdfNum(g.Size))
+			if g.RotRad == 0 && (g.ScaleX == 0 || g.ScaleX == 1) {
+				fmt.Fprintf(b, "x=\"%s\" y=\"%s\">", pdfNum(g.Pos.X), pdfNum(g.Pos.Y))
+			} else {
+				scx := g.ScaleX
+				if scx == 0 {
+					scx = 1
+				}
+				b.WriteString("transform=\"")
+				svgMatrix(b, mat32.Translate2D(g.Pos.X, g.Pos.Y).Scale(scx, 1).Rotate(g.RotRad))
+				b.WriteString("\">")
+			}
+			xml.EscapeText(b, []byte(g.Text))
+			b.WriteString("</text>\n")
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString(strings.Repeat("</g>", ngrp) + "\n")
+	return nil
+}
+
+// writeImage writes the element for given image with given transform from
+// its pixels, as a use of the image if it was already written
+func (sw *svgWriter) writeImage(b *bytes.Buffer, img image.Image, xf mat32.Mat2) error {
+	b.WriteString("<g transform=\"")
+	svgMatrix(b, xf)
+	b.WriteString("\">")
+	if id, has := sw.images[img]; has {
+		fmt.Fprintf(b, "<use xlink:href=\"#%s\"/></g>\n", id)
+		return nil
+	}
+	id := fmt.Sprintf("img%d", len(sw.images)+1)
+	sw.images[img] = id
+	var pb bytes.Buffer
+	if err := png.Encode(&pb, img); err != nil {
+		return err
+	}
+	ib := img.Bounds()
+	fmt.Fprintf(b, "<image id=\"%s\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" xlink:href=\"data:image/png;base64,%s\"/></g>\n", id, ib.Min.X, ib.Min.Y, ib.Dx(), ib.Dy(), base64.StdEncoding.EncodeToString(pb.Bytes()))
+	return nil
+}
+
+// fontStyle returns the family, weight and style of given font name
+func (sw *svgWriter) fontStyle(fontnm string) (fam string, wt int, sty string) {
+	basenm, _, fwt, fsty := gist.FontNameToMods(FontLibrary.FontInfoName(fontnm))
+	fam = basenm
+	var ebuf bytes.Buffer
+	xml.EscapeText(&ebuf, []byte(fam))
+	return ebuf.String(), svgWeight(fwt), strings.ToLower(strings.TrimPrefix(fsty.String(), "Font"))
+}
+
+// svgWeight returns the numerical CSS font weight for given font weight
+func svgWeight(wt gist.FontWeights) int {
+	switch wt {
+	case gist.Weight100, gist.WeightThin:
+		return 100
+	case gist.Weight200, gist.WeightExtraLight:
+		return 200
+	case gist.Weight300, gist.WeightLight:
+		return 300
+	case gist.Weight500, gist.WeightMedium:
+		return 500
+	case gist.Weight600, gist.WeightSemiBold:
+		return 600
+	case gist.Weight700, gist.WeightBold:
+		return 700
+	case gist.Weight800, gist.WeightExtraBold:
+		return 800
+	case gist.Weight900, gist.WeightBlack:
+		return 900
+	}
+	return 400
+}
+
+// svgPath returns the SVG path data for given path
+func svgPath(path []PathSeg) string {
+	var b strings.Builder
+	for _, sg := range path {
+		p := sg.Pts
+		switch sg.Op {
+		case PathMoveTo:
+			fmt.Fprintf(&b, "M%s %s", pdfNum(p[0].X), pdfNum(p[0].Y))
+		case PathLineTo:
+			fmt.Fprintf(&b, "L%s %s", pdfNum(p[0].X), pdfNum(p[0].Y))
+		case PathQuadTo:
+			fmt.Fprintf(&b, "Q%s %s %s %s", pdfNum(p[0].X), pdfNum(p[0].Y), pdfNum(p[1].X), pdfNum(p[1].Y))
+		case PathCubicTo:
+			fmt.Fprintf(&b, "C%s %s %s %s %s %s", pdfNum(p[0].X), pdfNum(p[0].Y), pdfNum(p[1].X), pdfNum(p[1].Y), pdfNum(p[2].X), pdfNum(p[2].Y))
+		case PathClose:
+			b.WriteString("Z")
+		}
+	}
+	return b.String()
+}
+
+// svgMatrix writes given transform as an SVG matrix
+func svgMatrix(b *bytes.Buffer, m mat32.Mat2) {
+	fmt.Fprintf(b, "matrix(%s %s %s %s %s %s)", pdfNum(m.XX), pdfNum(m.YX), pdfNum(m.XY), pdfNum(m.YY), pdfNum(m.X0), pdfNum(m.Y0))
+}
+
+// svgColor returns the SVG color for given color, without its alpha
+func svgColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// svgOpacity returns the given opacity attribute for the alpha of given
+// color, if it is not opaque
+func svgOpacity(attr string, c color.NRGBA) string {
+	if c.A == 255 {
+		return ""
+	}
+	return fmt.Sprintf(" %s=\"%s\"", attr, pdfNum(float32(c.A)/255))
+}
+
+// svgCap returns the SVG line cap for given line cap -- the caps that SVG
+// does not have are drawn as round caps
+func svgCap(c gist.LineCaps) string {
+	switch c {
+	case gist.LineCapButt:
+		return "butt"
+	case gist.LineCapSquare:
+		return "square"
+	}
+	return "round"
+}
+
+// svgJoin returns the SVG line join for given line join
+func svgJoin(j gist.LineJoins) string {
+	switch j {
+	case gist.LineJoinMiter:
+		return "miter"
+	case gist.LineJoinMiterClip:
+		return "miter-clip"
+	case gist.LineJoinBevel:
+		return "bevel"
+	case gist.LineJoinArcs:
+		return "arcs"
+	}
+	return "round"
+}
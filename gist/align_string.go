@@ -28,12 +28,15 @@ func _() {
 	_ = x[AlignTextBottom-12]
 	_ = x[AlignSub-13]
 	_ = x[AlignSuper-14]
-	_ = x[AlignN-15]
+	_ = x[AlignStretch-15]
+	_ = x[AlignSpaceEvenly-16]
+	_ = x[AlignAuto-17]
+	_ = x[AlignN-18]
 }
 
-const _Align_name = "AlignLeftAlignTopAlignCenterAlignMiddleAlignRightAlignBottomAlignBaselineAlignJustifyAlignSpaceAroundAlignFlexStartAlignFlexEndAlignTextTopAlignTextBottomAlignSubAlignSuperAlignN"
+const _Align_name = "AlignLeftAlignTopAlignCenterAlignMiddleAlignRightAlignBottomAlignBaselineAlignJustifyAlignSpaceAroundAlignFlexStartAlignFlexEndAlignTextTopAlignTextBottomAlignSubAlignSuperAlignStretchAlignSpaceEvenlyAlignAutoAlignN"
 
-var _Align_index = [...]uint8{0, 9, 17, 28, 39, 49, 60, 73, 85, 101, 115, 127, 139, 154, 162, 172, 178}
+var _Align_index = [...]uint8{0, 9, 17, 28, 39, 49, 60, 73, 85, 101, 115, 127, 139, 154, 162, 172, 184, 200, 209, 215}
 
 func (i Align) String() string {
 	if i < 0 || i >= Align(len(_Align_index)-1) {
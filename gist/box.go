@@ -5,6 +5,9 @@
 package gist
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/kit"
 )
@@ -73,12 +76,15 @@ func (ev *BorderStyles) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshal
 
 // IMPORTANT: any changes here must be updated in style_props.go StyleBorderFuncs
 
-// Border contains style parameters for borders
+// Border contains style parameters for borders, which can be different
+// for each side, or for each corner for Radius, as set by 1 to 4 value
+// CSS shorthands or by longhands, e.g., border-top-width or
+// border-top-left-radius
 type Border struct {
-	Style  BorderStyles `xml:"style" desc:"prop: border-style = how to draw the border"`
-	Width  units.Value  `xml:"width" desc:"prop: border-width = width of the border"`
-	Radius units.Value  `xml:"radius" desc:"prop: border-radius = rounding of the corners"`
-	Color  Color        `xml:"color" desc:"prop: border-color = color of the border"`
+	Style  SideBorderStyles `xml:"style" desc:"prop: border-style = how to draw the border, for each side"`
+	Width  SideValues       `xml:"width" desc:"prop: border-width = width of the border, for each side"`
+	Radius SideValues       `xml:"radius" desc:"prop: border-radius = rounding of the corners, for each corner: top-left, top-right, bottom-right, bottom-left"`
+	Color  SideColors       `xml:"color" desc:"prop: border-color = color of the border, for each side"`
 }
 
 // ToDots runs ToDots on unit values, to compile down to raw pixels
@@ -87,6 +93,32 @@ func (bs *Border) ToDots(uc *units.Context) {
 	bs.Radius.ToDots(uc)
 }
 
+// WidthDots returns the border width of each side in dots, which is 0 for
+// sides with BorderNone or BorderHidden style
+func (bs *Border) WidthDots() SideFloats {
+	wd := bs.Width.Dots()
+	hide := func(st BorderStyles) bool { return st == BorderNone || st == BorderHidden }
+	if hide(bs.Style.Top) {
+		wd.Top = 0
+	}
+	if hide(bs.Style.Right) {
+		wd.Right = 0
+	}
+	if hide(bs.Style.Bottom) {
+		wd.Bottom = 0
+	}
+	if hide(bs.Style.Left) {
+		wd.Left = 0
+	}
+	return wd
+}
+
+// IsUniform returns true if all sides of the border have the same
+// style, width and color, so it can be drawn as a single stroked box
+func (bs *Border) IsUniform() bool {
+	return bs.Style.IsUniform() && bs.Width.Dots().IsUniform() && bs.Color.IsUniform()
+}
+
 // IMPORTANT: any changes here must be updated in style_props.go StyleShadowFuncs
 
 // style parameters for shadows
@@ -99,8 +131,88 @@ type Shadow struct {
 	Inset   bool        `xml:".inset" desc:"prop: .inset = shadow is inset within box instead of outset outside of box"`
 }
 
+// HasShadow returns true if the shadow is visible: it has a color and
+// is offset, blurred or spread
 func (s *Shadow) HasShadow() bool {
-	return (s.HOffset.Dots > 0 || s.VOffset.Dots > 0)
+	if s.Color.IsNil() || s.Color.A == 0 {
+		return false
+	}
+	return s.HOffset.Dots != 0 || s.VOffset.Dots != 0 || s.Blur.Dots > 0 || s.Spread.Dots != 0
+}
+
+// String returns the CSS box-shadow representation of the shadow
+func (s *Shadow) String() string {
+	str := fmt.Sprintf("%s %s %s %s %s", s.HOffset.String(), s.VOffset.String(), s.Blur.String(), s.Spread.String(), s.Color.HexString())
+	if s.Inset {
+		return "inset " + str
+	}
+	return str
+}
+
+// SetString sets the shadow from a CSS box-shadow shadow specification:
+// [inset] h-offset v-offset [blur [spread]] [color], where the color
+// defaults to Black
+func (s *Shadow) SetString(str string, ctxt Context) error {
+	*s = Shadow{Color: Black}
+	nlen := 0
+	for _, fld := range splitTopLevel(strings.TrimSpace(str), ' ') {
+		switch {
+		case fld == "":
+		case fld == "inset":
+			s.Inset = true
+		case isShadowLength(fld):
+			val := units.StringToValue(fld)
+			switch nlen {
+			case 0:
+				s.HOffset = val
+			case 1:
+				s.VOffset = val
+			case 2:
+				s.Blur = val
+			case 3:
+				s.Spread = val
+			default:
+				return fmt.Errorf("gist.Shadow.SetString: too many lengths in: %v", str)
+			}
+			nlen++
+		default:
+			if err := s.Color.SetStringStyle(fld, nil, ctxt); err != nil {
+				return fmt.Errorf("gist.Shadow.SetString: %v", err)
+			}
+		}
+	}
+	if nlen < 2 {
+		return fmt.Errorf("gist.Shadow.SetString: h-offset and v-offset are required: %v", str)
+	}
+	return nil
+}
+
+// isShadowLength returns true if given box-shadow field is a length
+func isShadowLength(fld string) bool {
+	if units.IsCalc(fld) {
+		return true
+	}
+	c := fld[0]
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.'
+}
+
+// ParseBoxShadows parses a CSS box-shadow list of shadows, separated by
+// commas, e.g., "0 1px 2px #0004, inset 0 0 4px blue" -- "none" returns
+// no shadows.  The first shadow is drawn on top.
+func ParseBoxShadows(str string, ctxt Context) ([]Shadow, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "none" {
+		return nil, nil
+	}
+	var shs []Shadow
+	for _, ss := range splitTopLevel(str, ',') {
+		var sh Shadow
+		if err := sh.SetString(ss, ctxt); err != nil {
+			return shs, err
+		}
+		shs = append(shs, sh)
+	}
+	return shs, nil
 }
 
 // ToDots runs ToDots on unit values, to compile down to raw pixels
@@ -319,6 +319,9 @@ func cvtPctStringErr(gotpct bool, pctstr string) {
 // * saturate-PCT or pastel-PCT: manipulates the saturation level in HSL by PCT
 // * clearer-PCT or opaquer-PCT: manipulates the alpha level by PCT
 // * blend-PCT-color: blends given percent of given color name relative to base (or current)
+//
+// The CSS perceptual color functions oklab(L a b), oklch(L C H) and
+// lab(L a b) are also supported, with an optional / alpha.
 func (c *Color) SetString(str string, base color.Color) error {
 	if len(str) == 0 { // consider it null
 		c.SetToNil()
@@ -359,6 +362,9 @@ func (c *Color) SetString(str string, base color.Color) error {
 		format := "%d,%d,%d,%d"
 		fmt.Sscanf(val, format, &r, &g, &b, &a)
 		c.SetUInt8(uint8(r), uint8(g), uint8(b), uint8(a))
+	case strings.HasPrefix(lstr, "oklab("), strings.HasPrefix(lstr, "oklch("), strings.HasPrefix(lstr, "lab("):
+		_, err := c.setColorFunc(lstr)
+		return err
 	case strings.HasPrefix(lstr, "pref("):
 		val := lstr[5:]
 		val = strings.TrimRight(val, ")")
@@ -0,0 +1,152 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+)
+
+func colorNear(a, b Color, tol int) bool {
+	d := func(x, y uint8) bool { return int(x)-int(y) <= tol && int(y)-int(x) <= tol }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+}
+
+func TestColorSpaces(t *testing.T) {
+	red := Color{255, 0, 0, 255}
+	ok := red.ToOKLab()
+	if mat32.Abs(ok.L-0.628) > 0.001 || mat32.Abs(ok.A-0.2249) > 0.001 || mat32.Abs(ok.B-0.1258) > 0.001 {
+		t.Errorf("red OKLab: %+v", ok)
+	}
+	lab := red.ToLab()
+	if mat32.Abs(lab.L-53.24) > 0.05 || mat32.Abs(lab.A-80.09) > 0.1 || mat32.Abs(lab.B-67.20) > 0.1 {
+		t.Errorf("red Lab: %+v", lab)
+	}
+	for _, c := range []Color{red, {12, 200, 99, 255}, {250, 250, 250, 255}, {0, 0, 0, 255}, {20, 30, 100, 128}} {
+		var ck Color
+		ck.SetColor(c.ToOKLab())
+		var cc Color
+		cc.SetColor(c.ToOKLCH())
+		var cl Color
+		cl.SetColor(c.ToLab())
+		if !colorNear(ck, c, 1) || !colorNear(cc, c, 1) || !colorNear(cl, c, 1) {
+			t.Errorf("round trip %v: oklab %v oklch %v lab %v", c, ck, cc, cl)
+		}
+	}
+	// out of gamut: keeps lightness and hue, reduces chroma
+	var og Color
+	og.SetOKLCH(0.9, 0.4, 140, 1)
+	if lch := og.ToOKLCH(); mat32.Abs(lch.L-0.9) > 0.01 || mat32.Abs(lch.H-140) > 2 {
+		t.Errorf("gamut mapping: %+v", lch)
+	}
+
+	var pc Color
+	if err := pc.SetString("oklch(62.8% 0.2577 29.23deg)", nil); err != nil || !colorNear(pc, red, 1) {
+		t.Errorf("oklch string: %v %v", pc, err)
+	}
+	if err := pc.SetString("lab(53.24 80.09 67.2 / 50%)", nil); err != nil || !colorNear(pc, Color{128, 0, 0, 128}, 1) {
+		t.Errorf("lab string: %v %v", pc, err)
+	}
+	if err := pc.SetString("oklab(0.5 0.1)", nil); err == nil {
+		t.Errorf("oklab string: expected error")
+	}
+
+	blk := Black
+	mid := blk.BlendPerceptual(50, White)
+	if l := mid.ToOKLab().L; mat32.Abs(l-0.5) > 0.01 {
+		t.Errorf("BlendPerceptual lightness: %v", l)
+	}
+	lt := red.LighterPerceptual(50)
+	if lch, rlch := lt.ToOKLCH(), red.ToOKLCH(); mat32.Abs(lch.L-(rlch.L+1)/2) > 0.01 || mat32.Abs(lch.H-rlch.H) > 2 {
+		t.Errorf("LighterPerceptual: %+v", lch)
+	}
+}
+
+func TestContrast(t *testing.T) {
+	wht, blk := White, Black
+	if wht.Luminance() != 1 || blk.Luminance() != 0 {
+		t.Errorf("luminance: %v %v", wht.Luminance(), blk.Luminance())
+	}
+	if cr := blk.ContrastRatio(White); mat32.Abs(cr-21) > 0.001 {
+		t.Errorf("black on white: %v", cr)
+	}
+	gry := Color{0x77, 0x77, 0x77, 0xff}
+	if cr := gry.ContrastRatio(White); mat32.Abs(cr-4.48) > 0.01 {
+		t.Errorf("#777 on white: %v", cr)
+	}
+	// half transparent black on white is grey
+	clr := Color{0, 0, 0, 128}
+	if cr := clr.ContrastRatio(White); cr > 5 || cr < 3 {
+		t.Errorf("transparent black on white: %v", cr)
+	}
+	lgt := Color{0x99, 0x99, 0xcc, 0xff}
+	rd := lgt.ReadableOn(White, ContrastAA)
+	if cr := rd.ContrastRatio(White); cr < ContrastAA || cr > ContrastAA+0.2 {
+		t.Errorf("ReadableOn white: %v %v", rd, cr)
+	}
+	if rd.B <= rd.R {
+		t.Errorf("ReadableOn should keep hue: %v", rd)
+	}
+	if rd := gry.ReadableOn(Black, ContrastAAA); rd.ContrastRatio(Black) < ContrastAAA || rd.R <= gry.R {
+		t.Errorf("ReadableOn black: %v", rd)
+	}
+	if rd := wht.ReadableOn(Black, ContrastAA); rd != wht {
+		t.Errorf("ReadableOn should not change readable color: %v", rd)
+	}
+	// translucent colors are composited over the background
+	tlgt := Color{0x99, 0x99, 0xcc, 0xe6}
+	if rd := tlgt.ReadableOn(White, ContrastAA); rd.ContrastRatio(White) < ContrastAA || rd.A != tlgt.A {
+		t.Errorf("ReadableOn translucent: %v %v", rd, rd.ContrastRatio(White))
+	}
+	faint := Color{0x99, 0x99, 0xcc, 0x40} // cannot be readable on white even as black
+	if rd := faint.ReadableOn(White, ContrastAA); rd != Black || rd.ContrastRatio(White) < ContrastAA {
+		t.Errorf("ReadableOn faint: %v %v", rd, rd.ContrastRatio(White))
+	}
+}
+
+func TestGradients(t *testing.T) {
+	var cs ColorSpec
+	cs.SetString("conic-gradient(from 90deg at 25% 50%, red, blue 50%, red)", nil)
+	if cs.Source != ConicGradient || len(cs.Gradient.Stops) != 3 {
+		t.Fatalf("conic: %v %+v", cs.Source, cs.Gradient)
+	}
+	if pt := cs.Gradient.Points; pt[0] != 0.25 || pt[1] != 0.5 || pt[2] != 90 {
+		t.Errorf("conic points: %v", pt)
+	}
+	cf := cs.RenderColor(1, image.Rect(0, 0, 100, 100), mat32.Identity2D()).(rasterx.ColorFunc)
+	// from 90deg: start (red) is to the right of center, blue half-way round, to the left
+	if c := ColorFromColor(cf(60, 50)); !colorNear(c, Color{255, 0, 0, 255}, 8) {
+		t.Errorf("conic at start: %v", c)
+	}
+	if c := ColorFromColor(cf(2, 50)); !colorNear(c, Color{0, 0, 255, 255}, 8) {
+		t.Errorf("conic half-way: %v", c)
+	}
+
+	cs.SetString("repeating-conic-gradient(black 0 25%, white 25% 50%)", nil)
+	if n := len(cs.Gradient.Stops); n != 8 || cs.Gradient.Stops[n-1].Offset != 1 {
+		t.Errorf("repeating conic stops: %+v", cs.Gradient.Stops)
+	}
+
+	cs.SetString("repeating-linear-gradient(to right, red, blue 25%)", nil)
+	gr := cs.Gradient
+	if gr.Spread != rasterx.RepeatSpread || gr.Points[GpX2] != 0.25 || gr.Stops[1].Offset != 1 {
+		t.Errorf("repeating linear: %+v", gr)
+	}
+
+	cs.SetString("linear-gradient(60deg, rgb(255, 0, 0), 20%, blue)", nil)
+	gr = cs.Gradient
+	if len(gr.Stops) != GradientHintStops+1 {
+		t.Fatalf("hint stops: %+v", gr.Stops)
+	}
+	if mid := gr.Stops[GradientHintStops/2]; mid.Offset != 0.5 || ColorFromColor(mid.StopColor).B < 180 {
+		t.Errorf("hint mid stop should be mostly blue: %+v", mid)
+	}
+	if mat32.Abs(float32(gr.Points[GpX2]-gr.Points[GpX1])-1.366*0.866) > 0.001 {
+		t.Errorf("60deg points: %v", gr.Points)
+	}
+}
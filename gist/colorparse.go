@@ -16,6 +16,7 @@ import (
 	"image/color"
 	"io"
 	"log"
+	"math"
 	"strconv"
 	"strings"
 
@@ -85,25 +86,27 @@ func (cs *ColorSpec) SetString(clrstr string, ctxt Context) bool {
 		pars := rmdr[pidx+1:]
 		pars = strings.TrimSuffix(pars, ");")
 		pars = strings.TrimSuffix(pars, ")")
-		switch gtyp {
-		case "repeating-linear":
-			cs.Gradient = &rasterx.Gradient{Points: [5]float64{0, 0, 0, 1, 0}, IsRadial: false, Matrix: rasterx.Identity, Spread: rasterx.RepeatSpread}
-			cs.Source = LinearGradient
-			cs.parseLinearGrad(pars)
+		repeat := strings.HasPrefix(gtyp, "repeating-")
+		switch strings.TrimPrefix(gtyp, "repeating-") {
 		case "linear":
 			cs.Gradient = &rasterx.Gradient{Points: [5]float64{0, 0, 0, 1, 0}, IsRadial: false, Matrix: rasterx.Identity, Spread: rasterx.PadSpread}
 			cs.Source = LinearGradient
 			cs.parseLinearGrad(pars)
-		case "repeating-radial":
-			cs.Gradient = &rasterx.Gradient{Points: [5]float64{0.5, 0.5, 0.5, 0.5, 0.5}, IsRadial: true, Matrix: rasterx.Identity, Spread: rasterx.RepeatSpread}
-			cs.Source = RadialGradient
-			cs.parseRadialGrad(pars)
 		case "radial":
 			cs.Gradient = &rasterx.Gradient{Points: [5]float64{0.5, 0.5, 0.5, 0.5, 0.5}, IsRadial: true, Matrix: rasterx.Identity, Spread: rasterx.PadSpread}
 			cs.Source = RadialGradient
 			cs.parseRadialGrad(pars)
+		case "conic":
+			cs.Gradient = &rasterx.Gradient{Points: [5]float64{0.5, 0.5, 0, 0, 0}, IsRadial: false, Matrix: rasterx.Identity, Spread: rasterx.PadSpread}
+			cs.Source = ConicGradient
+			cs.parseConicGrad(pars)
+		default:
+			log.Printf("gi.ColorSpec.Parse unknown gradient type: %v\n", gtyp)
+			return false
+		}
+		if repeat {
+			cs.SetRepeatingStops()
 		}
-		FixGradientStops(cs.Gradient)
 		svcs := &ColorSpec{} // critical to save a copy..
 		svcs.CopyFrom(cs)
 		ColorSpecCache[fullnm] = svcs
@@ -135,146 +138,131 @@ var GradientDegToSides = map[string]string{
 	"-45deg":  "top left",
 }
 
+// parseLinearGrad parses the parameters of a CSS linear-gradient:
+// an optional direction (angle or "to" sides), then the color stops
 func (cs *ColorSpec) parseLinearGrad(pars string) bool {
-	plist := strings.Split(pars, ", ")
-	var prevColor color.Color
-	stopIdx := 0
-	for pidx := 0; pidx < len(plist); pidx++ {
-		par := strings.TrimRight(strings.TrimSpace(plist[pidx]), ",")
-		origPar := par
-		switch {
-		case strings.Contains(par, "deg"):
-			// can't use trig, b/c need to be full 1, 0 values -- just use map
-			var ok bool
-			par, ok = GradientDegToSides[par]
-			if !ok {
-				log.Printf("gi.ColorSpec.Parse invalid gradient angle -- must be at 45 degree increments: %v\n", origPar)
-			}
-			par = "to " + par
-			fallthrough
-		case strings.HasPrefix(par, "to "):
-			sides := strings.Split(par[3:], " ")
-			cs.Gradient.Points = [5]float64{0, 0, 0, 0, 0}
-			for _, side := range sides {
-				switch side {
-				case "bottom":
-					cs.Gradient.Points[GpY1] = 0
-					cs.Gradient.Points[GpY2] = 1
-				case "top":
-					cs.Gradient.Points[GpY1] = 1
-					cs.Gradient.Points[GpY2] = 0
-				case "right":
-					cs.Gradient.Points[GpX1] = 0
-					cs.Gradient.Points[GpX2] = 1
-				case "left":
-					cs.Gradient.Points[GpX1] = 1
-					cs.Gradient.Points[GpX2] = 0
-				}
+	plist := splitTopLevel(pars, ',')
+	par := strings.TrimSpace(plist[0])
+	switch {
+	case strings.HasPrefix(par, "to "):
+		sides := strings.Fields(par[3:])
+		cs.Gradient.Points = [5]float64{0, 0, 0, 0, 0}
+		for _, side := range sides {
+			switch side {
+			case "bottom":
+				cs.Gradient.Points[GpY1] = 0
+				cs.Gradient.Points[GpY2] = 1
+			case "top":
+				cs.Gradient.Points[GpY1] = 1
+				cs.Gradient.Points[GpY2] = 0
+			case "right":
+				cs.Gradient.Points[GpX1] = 0
+				cs.Gradient.Points[GpX2] = 1
+			case "left":
+				cs.Gradient.Points[GpX1] = 1
+				cs.Gradient.Points[GpX2] = 0
 			}
-		case strings.HasPrefix(par, ")"):
+		}
+		plist = plist[1:]
+	default:
+		ang, err := parseGradAngle(par)
+		if err != nil {
 			break
-		default: // must be a color stop
-			var stop *rasterx.GradStop
-			if len(cs.Gradient.Stops) > stopIdx {
-				stop = &(cs.Gradient.Stops[stopIdx])
-			} else {
-				stop = &rasterx.GradStop{Opacity: 1.0, StopColor: color.Black}
-			}
-			if stopIdx == 0 {
-				prevColor = cs.Color // base color
-				// fmt.Printf("starting prev color: %v\n", prevColor)
-			}
-			if parseColorStop(stop, prevColor, par) {
-				if len(cs.Gradient.Stops) <= stopIdx {
-					cs.Gradient.Stops = append(cs.Gradient.Stops, *stop)
-				}
-				if stopIdx == 0 {
-					cs.Color.SetColor(stop.StopColor) // keep first one
-				}
-				prevColor = stop.StopColor
-				stopIdx++
-			}
 		}
+		// the gradient line goes through the center, and is long enough that
+		// the corners of the (unit) box are at the start and end
+		rad := ang * math.Pi / 180
+		dx, dy := math.Sin(rad), -math.Cos(rad)
+		hl := 0.5 * (math.Abs(dx) + math.Abs(dy))
+		cs.Gradient.Points = [5]float64{0.5 - hl*dx, 0.5 - hl*dy, 0.5 + hl*dx, 0.5 + hl*dy, 0}
+		plist = plist[1:]
 	}
-	if len(cs.Gradient.Stops) > stopIdx {
-		cs.Gradient.Stops = cs.Gradient.Stops[:stopIdx]
-	}
-	return true
+	return cs.parseGradStops(plist)
 }
 
 // todo: this is complex:
 // https://www.w3.org/TR/css3-images/#radial-gradients
 
+// parseRadialGrad parses the parameters of a CSS radial-gradient:
+// an optional shape and "at" position, then the color stops
 func (cs *ColorSpec) parseRadialGrad(pars string) bool {
-	plist := strings.Split(pars, ", ")
-	var prevColor color.Color
-	stopIdx := 0
-	for pidx := 0; pidx < len(plist); pidx++ {
-		par := strings.TrimRight(strings.TrimSpace(plist[pidx]), ",")
-		// origPar := par
-		switch {
-		case strings.Contains(par, "circle"):
-			cs.Gradient.Points = [5]float64{0.5, 0.5, 0.5, 0.5, 0.5}
-		case strings.Contains(par, "ellipse"):
-			cs.Gradient.Points = [5]float64{0.5, 0.5, 0.5, 0.5, 0.5}
-		case strings.HasPrefix(par, "at "):
-			sides := strings.Split(par[3:], " ")
-			cs.Gradient.Points = [5]float64{0, 0, 0, 0, 0}
-			for _, side := range sides {
-				switch side {
-				case "bottom":
-					cs.Gradient.Points[GpY1] = 0
-				case "top":
-					cs.Gradient.Points[GpY1] = 1
-				case "right":
-					cs.Gradient.Points[GpX1] = 0
-				case "left":
-					cs.Gradient.Points[GpX1] = 1
-				}
-			}
-		case strings.HasPrefix(par, ")"):
-			break
-		default: // must be a color stop
-			var stop *rasterx.GradStop
-			if len(cs.Gradient.Stops) > stopIdx {
-				stop = &(cs.Gradient.Stops[stopIdx])
-			} else {
-				stop = &rasterx.GradStop{Opacity: 1.0, StopColor: color.Black}
-			}
-			if stopIdx == 0 {
-				prevColor = cs.Color // base color
-				// fmt.Printf("starting prev color: %v\n", prevColor)
+	plist := splitTopLevel(pars, ',')
+	par := strings.TrimSpace(plist[0])
+	if strings.Contains(par, "circle") || strings.Contains(par, "ellipse") || strings.HasPrefix(par, "at ") {
+		cs.Gradient.Points = [5]float64{0.5, 0.5, 0.5, 0.5, 0.5}
+		if ai := strings.Index(par, "at "); ai >= 0 {
+			x, y := parseGradPos(par[ai+3:])
+			cs.Gradient.Points = [5]float64{x, y, x, y, 0.5}
+		}
+		plist = plist[1:]
+	}
+	return cs.parseGradStops(plist)
+}
+
+// parseConicGrad parses the parameters of a CSS conic-gradient:
+// an optional "from" angle and "at" position, then the color stops
+func (cs *ColorSpec) parseConicGrad(pars string) bool {
+	plist := splitTopLevel(pars, ',')
+	par := strings.TrimSpace(plist[0])
+	if strings.HasPrefix(par, "from ") || strings.HasPrefix(par, "at ") {
+		if ai := strings.Index(par, "at "); ai >= 0 {
+			cs.Gradient.Points[0], cs.Gradient.Points[1] = parseGradPos(par[ai+3:])
+			par = par[:ai]
+		}
+		if strings.HasPrefix(par, "from ") {
+			ang, err := parseGradAngle(strings.TrimSpace(par[5:]))
+			if err != nil {
+				log.Printf("gi.ColorSpec.Parse invalid conic gradient angle: %v\n", err)
 			}
-			if parseColorStop(stop, prevColor, par) {
-				if len(cs.Gradient.Stops) <= stopIdx {
-					cs.Gradient.Stops = append(cs.Gradient.Stops, *stop)
-				}
-				if stopIdx == 0 {
-					cs.Color.SetColor(stop.StopColor) // keep first one
-				}
-				prevColor = stop.StopColor
-				stopIdx++
+			cs.Gradient.Points[2] = ang
+		}
+		plist = plist[1:]
+	}
+	return cs.parseGradStops(plist)
+}
+
+// parseGradStops parses the color stops of a CSS gradient, which can have
+// one or two positions, and color hints between them (a lone position
+// where the two colors are blended half-way), and regularizes the stops
+func (cs *ColorSpec) parseGradStops(plist []string) bool {
+	prevColor := color.Color(cs.Color) // base color
+	var hints map[int]float64
+	for _, par := range plist {
+		par = strings.TrimSpace(par)
+		if par == "" {
+			continue
+		}
+		if off, err := readFraction(par); err == nil {
+			if hints == nil {
+				hints = make(map[int]float64)
 			}
+			hints[len(cs.Gradient.Stops)] = off
+			continue
+		}
+		stops, ok := parseColorStop(prevColor, par)
+		if !ok {
+			continue
+		}
+		if len(cs.Gradient.Stops) == 0 {
+			cs.Color.SetColor(stops[0].StopColor) // keep first one
 		}
+		cs.Gradient.Stops = append(cs.Gradient.Stops, stops...)
+		prevColor = stops[0].StopColor
 	}
-	if len(cs.Gradient.Stops) > stopIdx {
-		cs.Gradient.Stops = cs.Gradient.Stops[:stopIdx]
+	FixGradientStops(cs.Gradient)
+	if hints != nil {
+		SetGradientHints(cs.Gradient, hints)
 	}
 	return true
 }
 
-func parseColorStop(stop *rasterx.GradStop, prevColor color.Color, par string) bool {
-	cnm := par
-	if spcidx := strings.Index(par, " "); spcidx > 0 {
-		cnm = par[:spcidx]
-		offs := strings.TrimSpace(par[spcidx+1:])
-		off, err := readFraction(offs)
-		if err != nil {
-			log.Printf("gi.ColorSpec.Parse invalid offset: %v\n", err)
-			return false
-		}
-		stop.Offset = off
-	}
+// parseColorStop parses a color stop: a color followed by optional
+// positions, returning a stop of that color at each position -- two
+// positions make a band of solid color
+func parseColorStop(prevColor color.Color, par string) ([]rasterx.GradStop, bool) {
+	flds := splitTopLevel(par, ' ')
+	cnm := flds[0]
+	stop := rasterx.GradStop{Opacity: 1.0, StopColor: color.Black}
 	// color blending doesn't work well in pre-multiplied alpha RGB space!
 	if prevColor != nil && strings.HasPrefix(cnm, "clearer-") {
 		pcts := strings.TrimPrefix(cnm, "clearer-")
@@ -288,12 +276,82 @@ func parseColorStop(stop *rasterx.GradStop, prevColor color.Color, par string) b
 		clr, err := ColorFromString(cnm, prevColor)
 		if err != nil {
 			log.Printf("gi.ColorSpec.Parse invalid color string: %v\n", err)
-			return false
+			return nil, false
 		}
 		stop.StopColor = clr
 	}
-	// fmt.Printf("color: %v from: %v\n", stop.StopColor, par)
-	return true
+	var stops []rasterx.GradStop
+	for _, offs := range flds[1:] {
+		if offs == "" {
+			continue
+		}
+		off, err := readFraction(offs)
+		if err != nil {
+			log.Printf("gi.ColorSpec.Parse invalid offset: %v\n", err)
+			return nil, false
+		}
+		stop.Offset = off
+		stops = append(stops, stop)
+	}
+	if len(stops) == 0 {
+		stops = append(stops, stop)
+	}
+	return stops, true
+}
+
+// parseGradAngle parses a CSS angle in deg, grad, rad or turn units,
+// returning degrees
+func parseGradAngle(str string) (float64, error) {
+	mul := 1.0
+	switch {
+	case strings.HasSuffix(str, "deg"):
+		str = strings.TrimSuffix(str, "deg")
+	case strings.HasSuffix(str, "grad"):
+		str = strings.TrimSuffix(str, "grad")
+		mul = 0.9
+	case strings.HasSuffix(str, "rad"):
+		str = strings.TrimSuffix(str, "rad")
+		mul = 180 / math.Pi
+	case strings.HasSuffix(str, "turn"):
+		str = strings.TrimSuffix(str, "turn")
+		mul = 360
+	case str != "0":
+		return 0, fmt.Errorf("gist.parseGradAngle: angle must have deg, grad, rad or turn units: %v", str)
+	}
+	ang, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	return ang * mul, err
+}
+
+// parseGradPos parses a CSS position, e.g., "top left" or "25% 75%",
+// returning it as fractions of the box size
+func parseGradPos(str string) (x, y float64) {
+	x, y = 0.5, 0.5
+	flds := strings.Fields(str)
+	for i, fld := range flds {
+		switch fld {
+		case "left":
+			x = 0
+		case "right":
+			x = 1
+		case "top":
+			y = 0
+		case "bottom":
+			y = 1
+		case "center":
+		default:
+			f, err := readFraction(fld)
+			if err != nil {
+				log.Printf("gi.ColorSpec.Parse invalid gradient position: %v\n", err)
+				continue
+			}
+			if i == 0 {
+				x = f
+			} else {
+				y = f
+			}
+		}
+	}
+	return
 }
 
 // ReadXML reads XML-formatted ColorSpec from io.Reader
@@ -353,6 +411,20 @@ func (cs *ColorSpec) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) err
 				// fmt.Printf("lingrad %v\n", cs.Gradient)
 				for _, attr := range se.Attr {
 					// fmt.Printf("attr: %v val: %v\n", attr.Name.Local, attr.Value)
+					if IsConicXMLAttr(attr) {
+						err = cs.readConicAttr(attr)
+						if err != nil {
+							log.Printf("gi.ColorSpec.UnmarshalXML conic gradient parsing error: %v\n", err)
+							return err
+						}
+						continue
+					}
+					switch attr.Name.Local {
+					case "x1", "y1", "x2", "y2", "gradientUnits":
+						if cs.Source == ConicGradient {
+							continue // only for the linear fallback
+						}
+					}
 					switch attr.Name.Local {
 					// note: id not processed here - must be done externally
 					case "x1":
@@ -410,6 +482,28 @@ func (cs *ColorSpec) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) err
 				if setFy == false { // set fy to cy by default
 					cs.Gradient.Points[3] = cs.Gradient.Points[1]
 				}
+			case "conicGradient": // not standard SVG, as written by earlier versions
+				if cs.Gradient == nil {
+					cs.Gradient = &rasterx.Gradient{Points: [5]float64{0.5, 0.5, 0, 0, 0},
+						IsRadial: false, Matrix: rasterx.Identity}
+				} else {
+					cs.Gradient.IsRadial = false
+				}
+				cs.Source = ConicGradient
+				for _, attr := range se.Attr {
+					switch attr.Name.Local {
+					// note: id not processed here - must be done externally
+					case "cx", "cy", "from":
+						attr.Name.Local = "conic-" + attr.Name.Local
+						err = cs.readConicAttr(attr)
+					default:
+						err = cs.ReadGradAttr(attr)
+					}
+					if err != nil {
+						log.Printf("gi.ColorSpec.UnmarshalXML conic gradient parsing error: %v\n", err)
+						return err
+					}
+				}
 			case "stop":
 				stop := rasterx.GradStop{Opacity: 1.0, StopColor: color.Black}
 				ats := se.Attr
@@ -458,7 +552,7 @@ func (cs *ColorSpec) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) err
 				log.Println(errStr)
 			}
 		case xml.EndElement:
-			if se.Name.Local == "linearGradient" || se.Name.Local == "radialGradient" {
+			if se.Name.Local == "linearGradient" || se.Name.Local == "radialGradient" || se.Name.Local == "conicGradient" {
 				// fmt.Printf("gi.ColorSpec got gradient end element: %v\n", se.Name.Local)
 				return nil
 			}
@@ -531,6 +625,74 @@ func (cs *ColorSpec) ReadGradAttr(attr xml.Attr) (err error) {
 	return nil
 }
 
+// ConicXMLSpace is the XML namespace of the attributes of a
+// linearGradient element that make it a conic gradient, which is not
+// supported in SVG -- see ColorSpec for the points:
+//
+//	<linearGradient gogi:conic-cx="0.5" gogi:conic-cy="0.5" gogi:conic-from="90"
+//		gogi:conic-units="objectBoundingBox">
+//
+// The gradient is drawn as a linear one, with the same stops, by other
+// SVG renderers, and the svg package declares the gogi prefix.
+const ConicXMLSpace = "https://github.com/goki/gi/gist/conic"
+
+// IsConicXMLAttr returns true if given attribute of a linearGradient
+// element is one that makes it a conic gradient -- see ConicXMLSpace
+func IsConicXMLAttr(attr xml.Attr) bool {
+	if attr.Name.Space != ConicXMLSpace && attr.Name.Space != "gogi" {
+		return false
+	}
+	return strings.HasPrefix(attr.Name.Local, "conic-")
+}
+
+// readConicAttr reads given conic gradient attribute, as written by
+// ConicXMLAttrs, and makes the gradient conic
+func (cs *ColorSpec) readConicAttr(attr xml.Attr) (err error) {
+	if cs.Source != ConicGradient {
+		cs.Source = ConicGradient
+		cs.Gradient.Points = [5]float64{0.5, 0.5, 0, 0, 0}
+		cs.Gradient.Units = rasterx.ObjectBoundingBox
+	}
+	switch attr.Name.Local {
+	case "conic-cx":
+		cs.Gradient.Points[0], err = readFraction(attr.Value)
+	case "conic-cy":
+		cs.Gradient.Points[1], err = readFraction(attr.Value)
+	case "conic-from": // degrees, with or without units
+		ang := strings.TrimSpace(attr.Value)
+		if cs.Gradient.Points[2], err = strconv.ParseFloat(ang, 64); err != nil {
+			cs.Gradient.Points[2], err = parseGradAngle(ang)
+		}
+	case "conic-units":
+		attr.Name.Local = "gradientUnits"
+		err = cs.ReadGradAttr(attr)
+	}
+	return err
+}
+
+// ConicXMLAttrs returns the attributes, in the ConicXMLSpace with the
+// gogi prefix, that make a linearGradient element the conic gradient of
+// this spec -- returns nil if it is not conic
+func (cs *ColorSpec) ConicXMLAttrs() []xml.Attr {
+	if cs.Source != ConicGradient || cs.Gradient == nil {
+		return nil
+	}
+	gr := cs.Gradient
+	units := "userSpaceOnUse"
+	if gr.Units == rasterx.ObjectBoundingBox {
+		units = "objectBoundingBox"
+	}
+	attr := func(nm, val string) xml.Attr {
+		return xml.Attr{Name: xml.Name{Local: "gogi:conic-" + nm}, Value: val}
+	}
+	return []xml.Attr{
+		attr("cx", fmt.Sprintf("%g", gr.Points[0])),
+		attr("cy", fmt.Sprintf("%g", gr.Points[1])),
+		attr("from", fmt.Sprintf("%g", gr.Points[2])),
+		attr("units", units),
+	}
+}
+
 // FixGradientStops applies the CSS rules to regularize the gradient stops: https://www.w3.org/TR/css3-images/#color-stop-syntax
 func FixGradientStops(grad *rasterx.Gradient) {
 	sz := len(grad.Stops)
@@ -571,3 +733,68 @@ func FixGradientStops(grad *rasterx.Gradient) {
 	// 	fmt.Printf("%v\t%v opacity: %v\n", i, st.Offset, st.Opacity)
 	// }
 }
+
+// GradientHintStops is the number of segments used to approximate the
+// non-linear blending of the colors on either side of a color hint
+var GradientHintStops = 8
+
+// SetGradientHints applies the CSS color hints (a position between two
+// stops at which their colors are blended half-way), given as a map from
+// the index of the stop after the hint to the hint position, by adding
+// intermediate stops that approximate the resulting non-linear blending:
+// https://www.w3.org/TR/css-images-4/#color-stop-syntax
+func SetGradientHints(grad *rasterx.Gradient, hints map[int]float64) {
+	sz := len(grad.Stops)
+	stops := make([]rasterx.GradStop, 0, sz+len(hints)*GradientHintStops)
+	for i := 0; i < sz; i++ {
+		st := grad.Stops[i]
+		hint, has := hints[i]
+		if i == 0 || !has {
+			stops = append(stops, st)
+			continue
+		}
+		pst := grad.Stops[i-1]
+		rng := st.Offset - pst.Offset
+		if rng <= 0 {
+			stops = append(stops, st)
+			continue
+		}
+		h := (hint - pst.Offset) / rng
+		switch {
+		case h <= 0: // hard switch to next color at previous stop
+			hst := st
+			hst.Offset = pst.Offset
+			stops = append(stops, hst)
+		case h >= 1: // hard switch to next color at this stop
+			hst := pst
+			hst.Offset = st.Offset
+			stops = append(stops, hst)
+		case h != 0.5:
+			exp := math.Log(0.5) / math.Log(h)
+			for j := 1; j < GradientHintStops; j++ {
+				p := float64(j) / float64(GradientHintStops)
+				hst := LerpGradStop(pst, st, math.Pow(p, exp))
+				hst.Offset = pst.Offset + p*rng
+				stops = append(stops, hst)
+			}
+		}
+		stops = append(stops, st)
+	}
+	grad.Stops = stops
+}
+
+// LerpGradStop returns the gradient stop that is t of the way from a to b,
+// blending the non-premultiplied colors as is done in rendering gradients
+func LerpGradStop(a, b rasterx.GradStop, t float64) rasterx.GradStop {
+	r1, g1, b1, _ := a.StopColor.RGBA()
+	r2, g2, b2, _ := b.StopColor.RGBA()
+	return rasterx.GradStop{
+		StopColor: color.RGBA{
+			uint8((float64(r1)*(1-t) + float64(r2)*t) / 256),
+			uint8((float64(g1)*(1-t) + float64(g2)*t) / 256),
+			uint8((float64(b1)*(1-t) + float64(b2)*t) / 256),
+			0xFF},
+		Offset:  a.Offset*(1-t) + b.Offset*t,
+		Opacity: a.Opacity*(1-t) + b.Opacity*t,
+	}
+}
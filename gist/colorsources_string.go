@@ -16,12 +16,13 @@ func _() {
 	_ = x[SolidColor-0]
 	_ = x[LinearGradient-1]
 	_ = x[RadialGradient-2]
-	_ = x[ColorSourcesN-3]
+	_ = x[ConicGradient-3]
+	_ = x[ColorSourcesN-4]
 }
 
-const _ColorSources_name = "SolidColorLinearGradientRadialGradientColorSourcesN"
+const _ColorSources_name = "SolidColorLinearGradientRadialGradientConicGradientColorSourcesN"
 
-var _ColorSources_index = [...]uint8{0, 10, 24, 38, 51}
+var _ColorSources_index = [...]uint8{0, 10, 24, 38, 51, 64}
 
 func (i ColorSources) String() string {
 	if i < 0 || i >= ColorSources(len(_ColorSources_index)-1) {
@@ -0,0 +1,492 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/goki/mat32"
+)
+
+// Perceptual color spaces: OKLab and its polar form OKLCH
+// (https://bottosson.github.io/posts/oklab/), and CIELAB (D65 white point),
+// where equal distances correspond to roughly equal perceived differences,
+// so blending and lightening give even steps without the muddy or washed out
+// intermediate colors of sRGB and HSL.  Also the WCAG relative luminance
+// and contrast ratio: https://www.w3.org/TR/WCAG21/#dfn-contrast-ratio
+
+// WCAG minimum contrast ratios between text and its background
+const (
+	// ContrastAA is the minimum contrast ratio for normal text, level AA
+	ContrastAA float32 = 4.5
+
+	// ContrastAALarge is the minimum contrast ratio for large text and
+	// for user interface components such as borders and icons, level AA
+	ContrastAALarge float32 = 3
+
+	// ContrastAAA is the minimum contrast ratio for normal text, level AAA
+	ContrastAAA float32 = 7
+)
+
+/////////////////////////////////////////////////////////////////////////////
+//  sRGB
+
+// SRGBToLinear converts a gamma-encoded sRGB component value in 0..1
+// to linear light
+func SRGBToLinear(v float32) float32 {
+	return float32(srgbToLinear(float64(v)))
+}
+
+// SRGBFromLinear converts a linear light component value in 0..1 to
+// gamma-encoded sRGB
+func SRGBFromLinear(v float32) float32 {
+	return float32(srgbFromLinear(float64(v)))
+}
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func srgbFromLinear(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// linearRGB returns the linear light, non alpha-premultiplied RGB values
+// of given color, and its alpha
+func linearRGB(c color.Color) (r, g, b, a float64) {
+	f := NRGBAf32Model.Convert(c).(NRGBAf32)
+	return srgbToLinear(float64(f.R)), srgbToLinear(float64(f.G)), srgbToLinear(float64(f.B)), float64(f.A)
+}
+
+// linearToRGBA returns the color.Color RGBA values for given linear light,
+// non alpha-premultiplied values, clamping out of gamut values
+func linearToRGBA(r, g, b, a float64) (ur, ug, ub, ua uint32) {
+	nc := NRGBAf32{
+		R: float32(srgbFromLinear(clamp01(r))),
+		G: float32(srgbFromLinear(clamp01(g))),
+		B: float32(srgbFromLinear(clamp01(b))),
+		A: float32(clamp01(a)),
+	}
+	return nc.RGBA()
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  OKLab
+
+// OKLab represents a color in the perceptual OKLab color space, with
+// lightness L [0..1], green-red A and blue-yellow B axes [about -0.4..0.4],
+// and a non alpha-premultiplied Alpha [0..1]
+type OKLab struct {
+	L, A, B, Alpha float32
+}
+
+// Implements the color.Color interface -- colors outside of the sRGB gamut
+// are mapped into it by reducing their chroma, keeping lightness and hue
+func (c OKLab) RGBA() (r, g, b, a uint32) {
+	lr, lg, lb := oklabToLinearGamut(float64(c.L), float64(c.A), float64(c.B))
+	return linearToRGBA(lr, lg, lb, float64(c.Alpha))
+}
+
+// OKLCH represents a color in the polar form of the OKLab color space,
+// with lightness L [0..1], chroma C [0..about 0.4], hue H [0..360] degrees,
+// and a non alpha-premultiplied Alpha [0..1]
+type OKLCH struct {
+	L, C, H, Alpha float32
+}
+
+// Implements the color.Color interface -- colors outside of the sRGB gamut
+// are mapped into it by reducing their chroma, keeping lightness and hue
+func (c OKLCH) RGBA() (r, g, b, a uint32) {
+	return c.OKLab().RGBA()
+}
+
+// OKLab returns the color in rectangular OKLab coordinates
+func (c OKLCH) OKLab() OKLab {
+	hr := float64(c.H) * math.Pi / 180
+	return OKLab{L: c.L, A: c.C * float32(math.Cos(hr)), B: c.C * float32(math.Sin(hr)), Alpha: c.Alpha}
+}
+
+// OKLCH returns the color in polar OKLCH coordinates
+func (c OKLab) OKLCH() OKLCH {
+	h := math.Atan2(float64(c.B), float64(c.A)) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return OKLCH{L: c.L, C: float32(math.Hypot(float64(c.A), float64(c.B))), H: float32(h), Alpha: c.Alpha}
+}
+
+func linearToOKLab(r, g, b float64) (L, A, B float64) {
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	L = 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	A = 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	B = 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+	return
+}
+
+func oklabToLinear(L, A, B float64) (r, g, b float64) {
+	l := L + 0.3963377774*A + 0.2158037573*B
+	m := L - 0.1055613458*A - 0.0638541728*B
+	s := L - 0.0894841775*A - 1.2914855480*B
+	l, m, s = l*l*l, m*m*m, s*s*s
+	r = 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	g = -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	b = -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+	return
+}
+
+// oklabToLinearGamut converts OKLab to linear RGB, reducing the chroma
+// by binary search until the color is within the sRGB gamut
+func oklabToLinearGamut(L, A, B float64) (r, g, b float64) {
+	L = clamp01(L)
+	r, g, b = oklabToLinear(L, A, B)
+	if inGamut(r, g, b) {
+		return
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 20; i++ {
+		mid := (lo + hi) / 2
+		if mr, mg, mb := oklabToLinear(L, A*mid, B*mid); inGamut(mr, mg, mb) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return oklabToLinear(L, A*lo, B*lo)
+}
+
+func inGamut(r, g, b float64) bool {
+	const eps = 1e-6
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  CIELAB
+
+// Lab represents a color in the CIELAB color space relative to the D65
+// white point (as used by sRGB), with lightness L [0..100], green-red A and
+// blue-yellow B axes [about -128..127], and a non alpha-premultiplied
+// Alpha [0..1]
+type Lab struct {
+	L, A, B, Alpha float32
+}
+
+// Implements the color.Color interface -- colors outside of the sRGB gamut
+// are clamped
+func (c Lab) RGBA() (r, g, b, a uint32) {
+	lr, lg, lb := labToLinear(float64(c.L), float64(c.A), float64(c.B))
+	return linearToRGBA(lr, lg, lb, float64(c.Alpha))
+}
+
+// D65 white point
+const (
+	labXn = 0.95047
+	labYn = 1.0
+	labZn = 1.08883
+)
+
+func labF(t float64) float64 {
+	const d = 6.0 / 29.0
+	if t > d*d*d {
+		return math.Cbrt(t)
+	}
+	return t/(3*d*d) + 4.0/29.0
+}
+
+func labFInv(t float64) float64 {
+	const d = 6.0 / 29.0
+	if t > d {
+		return t * t * t
+	}
+	return 3 * d * d * (t - 4.0/29.0)
+}
+
+func linearToLab(r, g, b float64) (L, A, B float64) {
+	fx := labF((0.4124564*r + 0.3575761*g + 0.1804375*b) / labXn)
+	fy := labF((0.2126729*r + 0.7151522*g + 0.0721750*b) / labYn)
+	fz := labF((0.0193339*r + 0.1191920*g + 0.9503041*b) / labZn)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+func labToLinear(L, A, B float64) (r, g, b float64) {
+	fy := (L + 16) / 116
+	x := labXn * labFInv(fy+A/500)
+	y := labYn * labFInv(fy)
+	z := labZn * labFInv(fy-B/200)
+	r = 3.2404542*x - 1.5371385*y - 0.4985314*z
+	g = -0.9692660*x + 1.8760108*y + 0.0415560*z
+	b = 0.0556434*x - 0.2040259*y + 1.0572252*z
+	return
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Models for conversion
+
+var (
+	OKLabModel color.Model = color.ModelFunc(oklabModel)
+	OKLCHModel color.Model = color.ModelFunc(oklchModel)
+	LabModel   color.Model = color.ModelFunc(labModel)
+)
+
+func oklabModel(c color.Color) color.Color {
+	if _, ok := c.(OKLab); ok {
+		return c
+	}
+	r, g, b, a := linearRGB(c)
+	L, A, B := linearToOKLab(r, g, b)
+	return OKLab{L: float32(L), A: float32(A), B: float32(B), Alpha: float32(a)}
+}
+
+func oklchModel(c color.Color) color.Color {
+	if _, ok := c.(OKLCH); ok {
+		return c
+	}
+	return oklabModel(c).(OKLab).OKLCH()
+}
+
+func labModel(c color.Color) color.Color {
+	if _, ok := c.(Lab); ok {
+		return c
+	}
+	r, g, b, a := linearRGB(c)
+	L, A, B := linearToLab(r, g, b)
+	return Lab{L: float32(L), A: float32(A), B: float32(B), Alpha: float32(a)}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Color methods
+
+// ToOKLab converts to the perceptual OKLab color space -- see OKLab
+func (c *Color) ToOKLab() OKLab {
+	return OKLabModel.Convert(*c).(OKLab)
+}
+
+// ToOKLCH converts to the polar form of the OKLab color space -- see OKLCH
+func (c *Color) ToOKLCH() OKLCH {
+	return OKLCHModel.Convert(*c).(OKLCH)
+}
+
+// ToLab converts to the CIELAB color space -- see Lab
+func (c *Color) ToLab() Lab {
+	return LabModel.Convert(*c).(Lab)
+}
+
+// SetOKLab sets the color from OKLab lightness [0..1], a, b and alpha [0..1]
+func (c *Color) SetOKLab(l, a, b, alpha float32) {
+	c.SetColor(OKLab{L: l, A: a, B: b, Alpha: alpha})
+}
+
+// SetOKLCH sets the color from OKLCH lightness [0..1], chroma, hue [0..360]
+// and alpha [0..1]
+func (c *Color) SetOKLCH(l, ch, h, alpha float32) {
+	c.SetColor(OKLCH{L: l, C: ch, H: h, Alpha: alpha})
+}
+
+// SetLab sets the color from CIELAB lightness [0..100], a, b and alpha [0..1]
+func (c *Color) SetLab(l, a, b, alpha float32) {
+	c.SetColor(Lab{L: l, A: a, B: b, Alpha: alpha})
+}
+
+// BlendPerceptual returns a color that is the given percent blend between
+// current color and given clr -- 10 = 10% of the clr and 90% of the current
+// color, etc -- blending is done in the perceptual OKLab color space, which
+// avoids the muddy intermediate colors of Blend
+func (c *Color) BlendPerceptual(pct float32, clr color.Color) Color {
+	me := c.ToOKLab()
+	othc := OKLabModel.Convert(clr).(OKLab)
+	oth := mat32.Clamp(pct, 0, 100.0) / 100.0
+	me.L += oth * (othc.L - me.L)
+	me.A += oth * (othc.A - me.A)
+	me.B += oth * (othc.B - me.B)
+	me.Alpha += oth * (othc.Alpha - me.Alpha)
+	return ColorModel.Convert(me).(Color)
+}
+
+// LighterPerceptual returns a color that is lighter by the given percent,
+// e.g., 50 = 50% lighter, relative to maximum possible lightness, as in
+// Lighter, but in the perceptual OKLCH color space, which preserves hue
+// and gives even steps
+func (c *Color) LighterPerceptual(pct float32) Color {
+	lch := c.ToOKLCH()
+	lch.L += (1.0 - lch.L) * (mat32.Clamp(pct, 0, 100.0) / 100.0)
+	return ColorModel.Convert(lch).(Color)
+}
+
+// DarkerPerceptual returns a color that is darker by the given percent,
+// e.g., 50 = 50% darker, relative to maximum possible darkness, as in
+// Darker, but in the perceptual OKLCH color space, which preserves hue
+// and gives even steps
+func (c *Color) DarkerPerceptual(pct float32) Color {
+	lch := c.ToOKLCH()
+	lch.L -= lch.L * (mat32.Clamp(pct, 0, 100.0) / 100.0)
+	return ColorModel.Convert(lch).(Color)
+}
+
+// Luminance returns the WCAG relative luminance of the color [0..1],
+// ignoring alpha: https://www.w3.org/TR/WCAG21/#dfn-relative-luminance
+func (c *Color) Luminance() float32 {
+	r, g, b, _ := linearRGB(*c)
+	return float32(0.2126*r + 0.7152*g + 0.0722*b)
+}
+
+// ContrastRatio returns the WCAG contrast ratio [1..21] between this color,
+// as the foreground, and given background color, which is treated as
+// opaque -- a foreground with alpha < 1 is first composited over the
+// background.  See ContrastAA etc for the minimum ratios for readable text.
+func (c *Color) ContrastRatio(bg color.Color) float32 {
+	bc := ColorFromColor(bg)
+	bc.A = 255
+	fc := *c
+	if fc.A < 255 {
+		f := NRGBAf32Model.Convert(fc).(NRGBAf32)
+		opaque := ColorModel.Convert(NRGBAf32{R: f.R, G: f.G, B: f.B, A: 1}).(Color)
+		fc = bc.Blend(100*f.A, opaque)
+	}
+	return ContrastRatio(fc.Luminance(), bc.Luminance())
+}
+
+// ContrastRatio returns the WCAG contrast ratio [1..21] between two colors
+// of given relative luminance (see Color.Luminance), in either order
+func ContrastRatio(lum1, lum2 float32) float32 {
+	if lum1 < lum2 {
+		lum1, lum2 = lum2, lum1
+	}
+	return (lum1 + 0.05) / (lum2 + 0.05)
+}
+
+// ReadableOn returns a version of this color that has at least the given
+// WCAG contrast ratio (e.g., ContrastAA) on given background color, by
+// changing only its OKLCH lightness as little as needed, toward white or
+// black, whichever can reach the ratio (preferring the direction the color
+// already contrasts in).  A color with alpha < 1 is composited over the
+// background to check the ratio (see ContrastRatio), and keeps its alpha.
+// Returns the color unchanged if it is already readable, and opaque white
+// or black, whichever contrasts more, if the ratio cannot be reached while
+// keeping the hue and alpha.
+func (c *Color) ReadableOn(bg color.Color, ratio float32) Color {
+	if c.ContrastRatio(bg) >= ratio {
+		return *c
+	}
+	bc := ColorFromColor(bg)
+	bl := bc.Luminance()
+	lch := c.ToOKLCH()
+	lighter := c.Luminance() > bl
+	for try := 0; try < 2; try++ {
+		end := float32(0)
+		if lighter {
+			end = 1
+		}
+		ec := lch
+		ec.L = end
+		ecl := ColorModel.Convert(ec).(Color)
+		if ecl.ContrastRatio(bc) >= ratio {
+			// binary search for the smallest lightness change that works
+			lo, hi := lch.L, end
+			for i := 0; i < 20; i++ {
+				mid := (lo + hi) / 2
+				mc := lch
+				mc.L = mid
+				mcl := ColorModel.Convert(mc).(Color)
+				if mcl.ContrastRatio(bc) >= ratio {
+					hi = mid
+				} else {
+					lo = mid
+				}
+			}
+			lch.L = hi
+			return ColorModel.Convert(lch).(Color)
+		}
+		lighter = !lighter
+	}
+	if ContrastRatio(1, bl) >= ContrastRatio(0, bl) {
+		return Color{255, 255, 255, 255}
+	}
+	return Color{0, 0, 0, 255}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  CSS parsing
+
+// setColorFunc sets the color from a CSS Color 4 perceptual color function
+// string: oklab(L a b [/ alpha]), oklch(L C H [/ alpha]) or lab(L a b [/ alpha]),
+// where L and alpha can be percentages -- returns false if not one of these
+func (c *Color) setColorFunc(lstr string) (bool, error) {
+	var fn string
+	for _, f := range []string{"oklab", "oklch", "lab"} {
+		if strings.HasPrefix(lstr, f+"(") && strings.HasSuffix(lstr, ")") {
+			fn = f
+			break
+		}
+	}
+	if fn == "" {
+		return false, nil
+	}
+	args := strings.Fields(strings.NewReplacer("/", " / ", ",", " ").Replace(lstr[len(fn)+1 : len(lstr)-1]))
+	alpha := float32(1)
+	if len(args) == 5 && args[3] == "/" {
+		a, err := parseColorArg(args[4], 1)
+		if err != nil {
+			return true, err
+		}
+		alpha = a
+		args = args[:3]
+	}
+	if len(args) != 3 {
+		return true, fmt.Errorf("gist.Color.SetString: %s requires 3 values and an optional / alpha: %q", fn, lstr)
+	}
+	lmax := float32(1)
+	if fn == "lab" {
+		lmax = 100
+	}
+	var vals [3]float32
+	for i, a := range args {
+		pmax := float32(0)
+		if i == 0 {
+			pmax = lmax
+		}
+		v, err := parseColorArg(strings.TrimSuffix(a, "deg"), pmax)
+		if err != nil {
+			return true, err
+		}
+		vals[i] = v
+	}
+	switch fn {
+	case "oklab":
+		c.SetOKLab(vals[0], vals[1], vals[2], alpha)
+	case "oklch":
+		c.SetOKLCH(vals[0], vals[1], vals[2], alpha)
+	case "lab":
+		c.SetLab(vals[0], vals[1], vals[2], alpha)
+	}
+	return true, nil
+}
+
+// parseColorArg parses a number, or a percentage of given max if max > 0
+func parseColorArg(str string, max float32) (float32, error) {
+	pct := strings.HasSuffix(str, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(str, "%"), 32)
+	if err != nil || (pct && max == 0) {
+		return 0, fmt.Errorf("gist.Color.SetString: invalid color function value: %q", str)
+	}
+	if pct {
+		return float32(v) * max / 100, nil
+	}
+	return float32(v), nil
+}
@@ -7,6 +7,8 @@ package gist
 import (
 	"fmt"
 	"image"
+	"math"
+	"sort"
 
 	"image/color"
 
@@ -19,7 +21,9 @@ import (
 // all the usual necessary conversion functions to / from names, strings, etc
 
 // ColorSpec fully specifies the color for rendering -- used in FillStyle and
-// StrokeStyle
+// StrokeStyle.  For a ConicGradient, the Gradient Points are the center
+// X, Y and the starting angle in degrees (clockwise from the top), and
+// the stop offsets are fractions of a full turn.
 type ColorSpec struct {
 	Source   ColorSources      `desc:"source of color (solid, gradient)"`
 	Color    Color             `desc:"color for solid color source"`
@@ -37,6 +41,7 @@ const (
 	SolidColor ColorSources = iota
 	LinearGradient
 	RadialGradient
+	ConicGradient
 	ColorSourcesN
 )
 
@@ -136,13 +141,26 @@ func (cs *ColorSpec) NewRadialGradient() {
 	cs.Gradient.Bounds.H = 1
 }
 
+// NewConicGradient creates a new Conic gradient in spec, sets Source
+// to ConicGradient.
+func (cs *ColorSpec) NewConicGradient() {
+	cs.Source = ConicGradient
+	cs.Gradient = &rasterx.Gradient{Points: [5]float64{0.5, 0.5, 0, 0, 0}, IsRadial: false, Matrix: rasterx.Identity, Spread: rasterx.PadSpread}
+	cs.Gradient.Bounds.W = 1
+	cs.Gradient.Bounds.H = 1
+}
+
 // SetGradientPoints sets UserSpaceOnUse points for gradient based on given bounding box
 func (cs *ColorSpec) SetGradientPoints(bbox mat32.Box2) {
 	if cs.Gradient == nil {
 		return
 	}
 	cs.Gradient.Units = rasterx.UserSpaceOnUse
-	if cs.Gradient.IsRadial {
+	if cs.Source == ConicGradient {
+		ctr := bbox.Min.Add(bbox.Max).MulScalar(.5)
+		cs.Gradient.Points[0] = float64(ctr.X)
+		cs.Gradient.Points[1] = float64(ctr.Y)
+	} else if cs.Gradient.IsRadial {
 		ctr := bbox.Min.Add(bbox.Max).MulScalar(.5)
 		rad := 0.5 * mat32.Max(bbox.Max.X-bbox.Min.X, bbox.Max.Y-bbox.Min.Y)
 		cs.Gradient.Points = [5]float64{float64(ctr.X), float64(ctr.Y), float64(ctr.X), float64(ctr.Y), float64(rad)}
@@ -201,10 +219,138 @@ func (cs *ColorSpec) RenderColor(opacity float32, bounds image.Rectangle, xform
 			cs.Gradient.IsRadial = false
 		}
 		SetGradientBounds(cs.Gradient, bounds)
+		if cs.Source == ConicGradient {
+			return ConicColorFunc(cs.Gradient, float64(opacity), MatToRasterx(&xform))
+		}
 		return cs.Gradient.GetColorFunctionUS(float64(opacity), MatToRasterx(&xform))
 	}
 }
 
+// ConicColorFunc returns the rasterx.ColorFunc for rendering given conic
+// gradient (which rasterx does not support), as for
+// rasterx.Gradient.GetColorFunctionUS
+func ConicColorFunc(grad *rasterx.Gradient, opacity float64, objMatrix rasterx.Matrix2D) interface{} {
+	switch len(grad.Stops) {
+	case 0:
+		return rasterx.ApplyOpacity(color.Black, opacity)
+	case 1:
+		return rasterx.ApplyOpacity(grad.Stops[0].StopColor, grad.Stops[0].Opacity*opacity)
+	}
+	stops := make([]rasterx.GradStop, len(grad.Stops))
+	copy(stops, grad.Stops)
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].Offset < stops[j].Offset
+	})
+	cx, cy := grad.Points[0], grad.Points[1]
+	from := grad.Points[2] / 360
+	var inv rasterx.Matrix2D
+	if grad.Units == rasterx.ObjectBoundingBox {
+		bx, by, bw, bh := grad.Bounds.X, grad.Bounds.Y, grad.Bounds.W, grad.Bounds.H
+		if bw == 0 || bh == 0 {
+			return rasterx.ApplyOpacity(stops[0].StopColor, stops[0].Opacity*opacity)
+		}
+		cx = bx + bw*cx
+		cy = by + bh*cy
+		inv = rasterx.Identity.Translate(bx, by).Scale(bw, bh).
+			Mult(grad.Matrix).Scale(1/bw, 1/bh).Translate(-bx, -by).Invert()
+	} else {
+		inv = objMatrix.Mult(grad.Matrix).Invert()
+	}
+	return rasterx.ColorFunc(func(xi, yi int) color.Color {
+		x, y := inv.Transform(float64(xi)+0.5, float64(yi)+0.5)
+		t := math.Atan2(x-cx, cy-y)/(2*math.Pi) - from // clockwise from top
+		t -= math.Floor(t)
+		return GradientStopsColor(stops, t, opacity)
+	})
+}
+
+// GradientStopsColor returns the color at position t along given stops,
+// which must be sorted by offset, using the first and last stop colors
+// outside of their range
+func GradientStopsColor(stops []rasterx.GradStop, t, opacity float64) color.Color {
+	sz := len(stops)
+	if t <= stops[0].Offset {
+		return rasterx.ApplyOpacity(stops[0].StopColor, stops[0].Opacity*opacity)
+	}
+	for i := 1; i < sz; i++ {
+		st := &stops[i]
+		if t > st.Offset {
+			continue
+		}
+		pst := &stops[i-1]
+		rng := st.Offset - pst.Offset
+		if rng <= 0 {
+			return rasterx.ApplyOpacity(st.StopColor, st.Opacity*opacity)
+		}
+		bst := LerpGradStop(*pst, *st, (t-pst.Offset)/rng)
+		return rasterx.ApplyOpacity(bst.StopColor, bst.Opacity*opacity)
+	}
+	return rasterx.ApplyOpacity(stops[sz-1].StopColor, stops[sz-1].Opacity*opacity)
+}
+
+// SetRepeatingStops sets a repeating gradient from the stops, which
+// are repeated for each multiple of the distance between the first and
+// last stop, as in the CSS repeating-*-gradient functions.  Linear and
+// radial gradients are scaled to span one repetition, with RepeatSpread,
+// and the stops of a conic gradient are repeated around the full turn.
+func (cs *ColorSpec) SetRepeatingStops() {
+	gr := cs.Gradient
+	if gr == nil || len(gr.Stops) < 2 {
+		return
+	}
+	sz := len(gr.Stops)
+	per := gr.Stops[sz-1].Offset - gr.Stops[0].Offset
+	if per <= 0 {
+		return
+	}
+	if cs.Source == ConicGradient {
+		gr.Stops = tileGradStops(gr.Stops, per, 1)
+		return
+	}
+	gr.Stops = tileGradStops(gr.Stops, per, per)
+	for i := range gr.Stops {
+		gr.Stops[i].Offset /= per
+	}
+	if cs.Source == RadialGradient {
+		gr.Points[4] *= per
+	} else {
+		gr.Points[GpX2] = gr.Points[GpX1] + per*(gr.Points[GpX2]-gr.Points[GpX1])
+		gr.Points[GpY2] = gr.Points[GpY1] + per*(gr.Points[GpY2]-gr.Points[GpY1])
+	}
+	gr.Spread = rasterx.RepeatSpread
+}
+
+// tileGradStops returns given (sorted) stops repeated every per offset
+// to cover the range from 0 to end, with stops at 0 and end
+func tileGradStops(stops []rasterx.GradStop, per, end float64) []rasterx.GradStop {
+	var tiled []rasterx.GradStop
+	k := math.Floor(-stops[0].Offset / per)
+	for ; stops[0].Offset+k*per < end; k++ {
+		for _, st := range stops {
+			st.Offset += k * per
+			tiled = append(tiled, st)
+		}
+	}
+	var res []rasterx.GradStop
+	for i, st := range tiled {
+		switch {
+		case st.Offset < 0:
+			if i+1 < len(tiled) && tiled[i+1].Offset > 0 {
+				res = append(res, LerpGradStop(st, tiled[i+1], -st.Offset/(tiled[i+1].Offset-st.Offset)))
+			}
+		case st.Offset > end:
+			if i > 0 && tiled[i-1].Offset < end {
+				pst := tiled[i-1]
+				res = append(res, LerpGradStop(pst, st, (end-pst.Offset)/(st.Offset-pst.Offset)))
+			}
+			return res
+		default:
+			res = append(res, st)
+		}
+	}
+	return res
+}
+
 // SetIFace sets the color spec from given interface value, e.g., for ki.Props
 // key is an optional property key for error -- always logs errors
 func (c *ColorSpec) SetIFace(val interface{}, ctxt Context, key string) error {
@@ -231,7 +377,7 @@ func (c *ColorSpec) ApplyXForm(xf mat32.Mat2) {
 	}
 	mat := RasterxToMat(&c.Gradient.Matrix)
 	rot := xf.ExtractRot()
-	if c.Gradient.IsRadial || rot != 0 || !mat.IsIdentity() { // radial uses transform instead of points
+	if c.Gradient.IsRadial || c.Source == ConicGradient || rot != 0 || !mat.IsIdentity() { // radial uses transform instead of points
 		mat = mat.Mul(xf)
 		c.Gradient.Matrix = MatToRasterx(&mat)
 	} else {
@@ -257,7 +403,7 @@ func (c *ColorSpec) ApplyXFormPt(xf mat32.Mat2, pt mat32.Vec2) {
 	}
 	mat := RasterxToMat(&c.Gradient.Matrix)
 	rot := xf.ExtractRot()
-	if c.Gradient.IsRadial || rot != 0 || !mat.IsIdentity() { // radial uses transform instead of points
+	if c.Gradient.IsRadial || c.Source == ConicGradient || rot != 0 || !mat.IsIdentity() { // radial uses transform instead of points
 		mat = mat.MulCtr(xf, pt)
 		c.Gradient.Matrix = MatToRasterx(&mat)
 	} else {
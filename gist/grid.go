@@ -0,0 +1,264 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/units"
+)
+
+// GridTrack specifies the sizing of one row or column track of a grid
+// layout, as in CSS grid-template-rows and grid-template-columns:
+// a fixed size (e.g., 100px, 8em), auto (sized to content),
+// a flexible fraction of the remaining space (e.g., 1fr), or
+// minmax(min, max) where min is fixed or auto and max is fixed,
+// auto or a fraction.  https://developer.mozilla.org/en-US/docs/Web/CSS/grid-template-columns
+type GridTrack struct {
+	Min     units.Value `desc:"fixed minimum size of the track -- only used if MinAuto is false"`
+	Max     units.Value `desc:"fixed maximum size of the track -- only used if MaxAuto is false and Fr is 0"`
+	MinAuto bool        `desc:"minimum size is the minimum (need) size of the content"`
+	MaxAuto bool        `desc:"maximum size is the preferred size of the content"`
+	Fr      float32     `desc:"if > 0, the track takes this fraction of the space remaining after sizing the other tracks, in proportion to the Fr of all such tracks"`
+}
+
+// String returns the CSS string representation of the track
+func (gt *GridTrack) String() string {
+	min := "auto"
+	if !gt.MinAuto {
+		min = gt.Min.String()
+	}
+	max := "auto"
+	switch {
+	case gt.Fr > 0:
+		max = strconv.FormatFloat(float64(gt.Fr), 'g', -1, 32) + "fr"
+	case !gt.MaxAuto:
+		max = gt.Max.String()
+	}
+	switch {
+	case gt.MinAuto && gt.MaxAuto:
+		return "auto"
+	case gt.MinAuto && gt.Fr > 0:
+		return max
+	case !gt.MinAuto && !gt.MaxAuto && gt.Fr == 0 && gt.Min == gt.Max:
+		return min
+	}
+	return "minmax(" + min + ", " + max + ")"
+}
+
+// SetString sets the track from a single CSS track size:
+// a length (which can be a calc() expression), auto, a fraction (fr),
+// or minmax(min, max)
+func (gt *GridTrack) SetString(str string) error {
+	*gt = GridTrack{}
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "minmax(") && strings.HasSuffix(str, ")") {
+		args := splitTopLevel(str[7:len(str)-1], ',')
+		if len(args) != 2 {
+			return fmt.Errorf("gist.GridTrack: minmax requires 2 args: %q", str)
+		}
+		min := strings.TrimSpace(args[0])
+		if min == "auto" {
+			gt.MinAuto = true
+		} else if strings.HasSuffix(min, "fr") {
+			return fmt.Errorf("gist.GridTrack: minmax min cannot be a fraction: %q", str)
+		} else {
+			gt.Min.SetString(min)
+		}
+		return gt.setMax(strings.TrimSpace(args[1]))
+	}
+	if strings.HasSuffix(str, "fr") {
+		gt.MinAuto = true
+		return gt.setMax(str)
+	}
+	if str == "auto" {
+		gt.MinAuto = true
+		gt.MaxAuto = true
+		return nil
+	}
+	if units.IsCalc(str) {
+		if err := gt.Min.SetCalc(str); err != nil {
+			return err
+		}
+	} else if _, err := strconv.ParseFloat(strings.TrimRight(str, "abcdefghijklmnopqrstuvwxyz%"), 32); err != nil {
+		return fmt.Errorf("gist.GridTrack: invalid track size: %q", str)
+	} else {
+		gt.Min.SetString(str)
+	}
+	gt.Max = gt.Min
+	return nil
+}
+
+// setMax sets the max of the track from a length, auto or fraction
+func (gt *GridTrack) setMax(str string) error {
+	switch {
+	case str == "auto":
+		gt.MaxAuto = true
+	case strings.HasSuffix(str, "fr"):
+		fr, err := strconv.ParseFloat(strings.TrimSuffix(str, "fr"), 32)
+		if err != nil || fr <= 0 {
+			return fmt.Errorf("gist.GridTrack: invalid fraction: %q", str)
+		}
+		gt.Fr = float32(fr)
+	default:
+		gt.Max.SetString(str)
+	}
+	return nil
+}
+
+// ToDots runs ToDots on unit values, to compile down to raw pixels
+func (gt *GridTrack) ToDots(uc *units.Context) {
+	gt.Min.ToDots(uc)
+	gt.Max.ToDots(uc)
+}
+
+// ParseGridTracks parses a CSS grid-template-rows or grid-template-columns
+// value, a space separated list of track sizes (see GridTrack), which can
+// include repeat(n, tracks...).  Returns nil for "none".
+func ParseGridTracks(str string) ([]GridTrack, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "none" {
+		return nil, nil
+	}
+	var gts []GridTrack
+	for _, fld := range splitTopLevel(str, ' ') {
+		fld = strings.TrimSpace(fld)
+		if fld == "" {
+			continue
+		}
+		if strings.HasPrefix(fld, "repeat(") && strings.HasSuffix(fld, ")") {
+			args := splitTopLevel(fld[7:len(fld)-1], ',')
+			if len(args) != 2 {
+				return nil, fmt.Errorf("gist.ParseGridTracks: repeat requires 2 args: %q", fld)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(args[0]))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("gist.ParseGridTracks: invalid repeat count: %q", fld)
+			}
+			rgts, err := ParseGridTracks(args[1])
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < n; i++ {
+				gts = append(gts, rgts...)
+			}
+			continue
+		}
+		var gt GridTrack
+		if err := gt.SetString(fld); err != nil {
+			return nil, err
+		}
+		gts = append(gts, gt)
+	}
+	return gts, nil
+}
+
+// ParseGridAreas parses a CSS grid-template-areas value, which is a list
+// of quoted strings, one per row, each with the space-separated area names
+// of the cells in that row, e.g., "head head" "side main" -- a . is an empty
+// cell.  All rows must have the same number of cells, and each area must be
+// a rectangle.  Returns nil for "none".
+func ParseGridAreas(str string) ([][]string, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "none" {
+		return nil, nil
+	}
+	var rows []string
+	for {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			break
+		}
+		q := str[0]
+		if q != '"' && q != '\'' {
+			return nil, fmt.Errorf("gist.ParseGridAreas: rows must be quoted strings: %q", str)
+		}
+		end := strings.IndexByte(str[1:], q)
+		if end < 0 {
+			return nil, fmt.Errorf("gist.ParseGridAreas: unterminated string: %q", str)
+		}
+		rows = append(rows, str[1:end+1])
+		str = str[end+2:]
+	}
+	return GridAreasFromRows(rows)
+}
+
+// GridAreasFromRows returns grid areas from a list of rows, each with the
+// space-separated area names of the cells in that row (see ParseGridAreas)
+func GridAreasFromRows(rows []string) ([][]string, error) {
+	areas := make([][]string, len(rows))
+	for i, r := range rows {
+		areas[i] = strings.Fields(r)
+		if len(areas[i]) == 0 || len(areas[i]) != len(areas[0]) {
+			return nil, fmt.Errorf("gist.GridAreas: rows must all have the same number of cells: %q", r)
+		}
+	}
+	// check that each area is a rectangle
+	for y, r := range areas {
+		for x, nm := range r {
+			if nm == "." {
+				continue
+			}
+			ar := GridAreaRect(areas, nm)
+			if ar.Dx()*ar.Dy() != gridAreaCount(areas, nm) {
+				return nil, fmt.Errorf("gist.GridAreas: area %q at row %d, col %d is not a rectangle", nm, y, x)
+			}
+		}
+	}
+	return areas, nil
+}
+
+// GridAreaRect returns the cells occupied by area of given name in given
+// grid areas, with X = columns and Y = rows, and the Max exclusive --
+// returns an empty rectangle if not found
+func GridAreaRect(areas [][]string, name string) image.Rectangle {
+	var ar image.Rectangle
+	found := false
+	for y, r := range areas {
+		for x, nm := range r {
+			if nm != name {
+				continue
+			}
+			cr := image.Rect(x, y, x+1, y+1)
+			if found {
+				ar = ar.Union(cr)
+			} else {
+				ar = cr
+				found = true
+			}
+		}
+	}
+	return ar
+}
+
+// gridAreaCount returns the number of cells with given area name
+func gridAreaCount(areas [][]string, name string) int {
+	n := 0
+	for _, r := range areas {
+		for _, nm := range r {
+			if nm == name {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// GridTracksToDots returns a copy of given tracks with ToDots run on them,
+// so that styles sharing the same tracks are not affected
+func GridTracksToDots(gts []GridTrack, uc *units.Context) []GridTrack {
+	if len(gts) == 0 {
+		return gts
+	}
+	ngts := make([]GridTrack, len(gts))
+	copy(ngts, gts)
+	for i := range ngts {
+		ngts[i].ToDots(uc)
+	}
+	return ngts
+}
@@ -5,6 +5,10 @@
 package gist
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -13,8 +17,7 @@ import (
 
 // todo: for style
 // Align = layouts
-// Flex -- flexbox -- https://www.w3schools.com/css/css3_flexbox.asp -- key to look at further for layout ideas
-// as is Position -- absolute, sticky, etc
+// Position -- absolute, sticky, etc
 // Resize: user-resizability
 // z-index
 
@@ -28,6 +31,10 @@ import (
 //
 // LayoutHoriz, Vert both allow explicit Top/Left Center/Middle, Right/Bottom
 // alignment along with Justify and SpaceAround -- they use IsAlign functions
+//
+// LayoutFlex implements the CSS flexbox model, using the standard flex-*
+// properties, justify-content, align-items, align-self and gap, instead of
+// AlignH and AlignV: https://developer.mozilla.org/en-US/docs/Web/CSS/CSS_flexible_box_layout
 
 // IMPORTANT: any changes here must be updated in style_props.go StyleLayoutFuncs
 
@@ -36,26 +43,39 @@ var ScrollBarWidthDefault = float32(16)
 
 // Layout contains style preferences on the layout of the element.
 type Layout struct {
-	ZIndex         int         `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
-	AlignH         Align       `xml:"horizontal-align" desc:"prop: horizontal-align specifies the horizontal alignment of widget elements within a *vertical* layout container (has no effect within horizontal layouts -- use space / stretch elements instead).  For text layout, use text-align. This is not a standard css property."`
-	AlignV         Align       `xml:"vertical-align" desc:"prop: vertical-align specifies the vertical alignment of widget elements within a *horizontal* layout container (has no effect within vertical layouts -- use space / stretch elements instead).  For text layout, use text-vertical-align.  This is not a standard css property"`
-	PosX           units.Value `xml:"x" desc:"prop: x = horizontal position -- often superseded by layout but otherwise used"`
-	PosY           units.Value `xml:"y" desc:"prop: y = vertical position -- often superseded by layout but otherwise used"`
-	Width          units.Value `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
-	Height         units.Value `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
-	MaxWidth       units.Value `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
-	MaxHeight      units.Value `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
-	MinWidth       units.Value `xml:"min-width" desc:"prop: min-width = specified minimum size of element -- 0 if not specified"`
-	MinHeight      units.Value `xml:"min-height" desc:"prop: min-height = specified minimum size of element -- 0 if not specified"`
-	Margin         units.Value `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
-	Padding        units.Value `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
-	Overflow       Overflow    `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
-	Columns        int         `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
-	Row            int         `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
-	Col            int         `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
-	RowSpan        int         `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout (todo: not currently supported)"`
-	ColSpan        int         `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
-	ScrollBarWidth units.Value `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
+	ZIndex              int            `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
+	AlignH              Align          `xml:"horizontal-align" desc:"prop: horizontal-align specifies the horizontal alignment of widget elements within a *vertical* layout container (has no effect within horizontal layouts -- use space / stretch elements instead).  For text layout, use text-align. This is not a standard css property."`
+	AlignV              Align          `xml:"vertical-align" desc:"prop: vertical-align specifies the vertical alignment of widget elements within a *horizontal* layout container (has no effect within vertical layouts -- use space / stretch elements instead).  For text layout, use text-vertical-align.  This is not a standard css property"`
+	PosX                units.Value    `xml:"x" desc:"prop: x = horizontal position -- often superseded by layout but otherwise used"`
+	PosY                units.Value    `xml:"y" desc:"prop: y = vertical position -- often superseded by layout but otherwise used"`
+	Width               units.Value    `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
+	Height              units.Value    `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
+	MaxWidth            units.Value    `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
+	MaxHeight           units.Value    `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
+	MinWidth            units.Value    `xml:"min-width" desc:"prop: min-width = specified minimum size of element -- 0 if not specified"`
+	MinHeight           units.Value    `xml:"min-height" desc:"prop: min-height = specified minimum size of element -- 0 if not specified"`
+	Margin              SideValues     `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- 1 to 4 values for top, right, bottom, left as in CSS, or margin-top etc for each side"`
+	Padding             SideValues     `xml:"padding" desc:"prop: padding = transparent space around central content of box -- 1 to 4 values for top, right, bottom, left as in CSS (3 is top, right&left, bottom; 2 is top & bottom, right and left), or padding-top etc for each side"`
+	Overflow            Overflow       `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
+	Columns             int            `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
+	Row                 int            `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
+	Col                 int            `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
+	RowSpan             int            `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout"`
+	ColSpan             int            `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
+	ScrollBarWidth      units.Value    `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
+	FlexDirection       FlexDirections `xml:"flex-direction" desc:"prop: flex-direction = main axis along which a flex layout arranges its items: row, row-reverse, column, column-reverse"`
+	FlexWrap            bool           `xml:"flex-wrap" desc:"prop: flex-wrap = whether a flex layout wraps its items onto multiple lines (wrap) or keeps them on a single line (nowrap, the default)"`
+	FlexGrow            float32        `xml:"flex-grow" desc:"prop: flex-grow = proportion of the extra space along the main axis of a flex layout that this item takes -- 0 (default) does not grow"`
+	FlexShrink          float32        `xml:"flex-shrink" desc:"prop: flex-shrink = proportion of the missing space along the main axis of a flex layout that this item gives up, weighted by its flex-basis -- default is 1 -- never shrinks below its minimum size"`
+	FlexBasis           units.Value    `xml:"flex-basis" desc:"prop: flex-basis = initial main size of this item in a flex layout, before extra or missing space is distributed -- auto (the default, stored as a negative value) uses the preferred size of the item"`
+	JustifyContent      Align          `xml:"justify-content" desc:"prop: justify-content = how a flex layout distributes extra space along the main axis between and around its items: flex-start, flex-end, center, space-between, space-around, space-evenly"`
+	AlignItems          Align          `xml:"align-items" desc:"prop: align-items = default cross axis alignment of the items in each line of a flex layout: stretch (default), flex-start, flex-end, center"`
+	AlignSelf           Align          `xml:"align-self" desc:"prop: align-self = cross axis alignment of this item within a flex layout, overriding the align-items of the layout -- auto (default) uses align-items"`
+	Gap                 units.Value    `xml:"gap" desc:"prop: gap = space between items along the main axis, and between lines along the cross axis, of a flex layout, and between the rows and columns of a grid layout, where it overrides the layout spacing"`
+	GridTemplateColumns []GridTrack    `xml:"grid-template-columns" desc:"prop: grid-template-columns = sizes of the column tracks of a grid layout, e.g., 100px 1fr minmax(4em, 2fr) auto -- sets the number of columns if larger than columns -- additional columns are auto sized"`
+	GridTemplateRows    []GridTrack    `xml:"grid-template-rows" desc:"prop: grid-template-rows = sizes of the row tracks of a grid layout, as for grid-template-columns -- additional rows are auto sized"`
+	GridTemplateAreas   [][]string     `xml:"grid-template-areas" desc:"prop: grid-template-areas = named areas of a grid layout, as a quoted string of area names per row, e.g., \"head head\" \"side main\" -- children are placed in areas using grid-area"`
+	GridArea            string         `xml:"grid-area" desc:"prop: grid-area = name of the grid-template-areas area of the grid layout that this element occupies, which determines its row, col and spans"`
 }
 
 func (ls *Layout) Defaults() {
@@ -63,6 +83,10 @@ func (ls *Layout) Defaults() {
 	ls.MinWidth.Set(2.0, units.Px)
 	ls.MinHeight.Set(2.0, units.Px)
 	ls.ScrollBarWidth.Set(ScrollBarWidthDefault, units.Px)
+	ls.FlexShrink = 1
+	ls.FlexBasis.Set(-1, units.Px)
+	ls.AlignItems = AlignStretch
+	ls.AlignSelf = AlignAuto
 }
 
 func (ls *Layout) SetStylePost(props ki.Props) {
@@ -78,6 +102,74 @@ func (ls *Layout) AlignDim(d mat32.Dims) Align {
 	}
 }
 
+// FlexDims returns the main and cross axis dimensions of a flex layout, and
+// whether items are arranged in reverse order along the main axis,
+// according to the flex-direction
+func (ls *Layout) FlexDims() (main, cross mat32.Dims, rev bool) {
+	switch ls.FlexDirection {
+	case FlexRowReverse:
+		return mat32.X, mat32.Y, true
+	case FlexColumn:
+		return mat32.Y, mat32.X, false
+	case FlexColumnReverse:
+		return mat32.Y, mat32.X, true
+	default:
+		return mat32.X, mat32.Y, false
+	}
+}
+
+// SetFlex sets the FlexGrow, FlexShrink and FlexBasis from a CSS flex
+// shorthand value: none, auto, or a grow factor, an optional shrink factor
+// and an optional basis, in that order, e.g., "1" is the same as "1 1 0",
+// which gives items sizes in proportion to their grow factors.
+func (ls *Layout) SetFlex(str string) error {
+	str = strings.TrimSpace(str)
+	switch str {
+	case "none":
+		ls.FlexGrow, ls.FlexShrink = 0, 0
+		ls.FlexBasis.Set(-1, units.Px)
+		return nil
+	case "auto":
+		ls.FlexGrow, ls.FlexShrink = 1, 1
+		ls.FlexBasis.Set(-1, units.Px)
+		return nil
+	}
+	flds := strings.Fields(str)
+	if len(flds) == 0 || len(flds) > 3 {
+		return fmt.Errorf("gist.SetFlex: invalid flex value: %q", str)
+	}
+	ls.FlexGrow, ls.FlexShrink = 1, 1
+	ls.FlexBasis.Set(0, units.Px)
+	nnum := 0
+	for _, fld := range flds {
+		if fv, err := strconv.ParseFloat(fld, 32); err == nil && nnum < 2 {
+			if nnum == 0 {
+				ls.FlexGrow = float32(fv)
+			} else {
+				ls.FlexShrink = float32(fv)
+			}
+			nnum++
+			continue
+		}
+		if fld == "auto" {
+			ls.FlexBasis.Set(-1, units.Px)
+		} else {
+			ls.FlexBasis.SetString(fld)
+		}
+	}
+	return nil
+}
+
+// FlexCSSEnumString converts a CSS flex keyword, e.g., flex-start or
+// row-reverse, into the lower-case name of the corresponding Align or
+// FlexDirections value, for setting with kit.Enums
+func FlexCSSEnumString(str string) string {
+	if str == "space-between" {
+		return "justify"
+	}
+	return strings.Replace(str, "-", "", -1)
+}
+
 // position settings, in dots
 func (ls *Layout) PosDots() mat32.Vec2 {
 	return mat32.NewVec2(ls.PosX.Dots, ls.PosY.Dots)
@@ -111,6 +203,10 @@ func (ly *Layout) ToDots(uc *units.Context) {
 	ly.Margin.ToDots(uc)
 	ly.Padding.ToDots(uc)
 	ly.ScrollBarWidth.ToDots(uc)
+	ly.FlexBasis.ToDots(uc)
+	ly.Gap.ToDots(uc)
+	ly.GridTemplateColumns = GridTracksToDots(ly.GridTemplateColumns, uc)
+	ly.GridTemplateRows = GridTracksToDots(ly.GridTemplateRows, uc)
 }
 
 // Align has all different types of alignment -- only some are applicable to
@@ -139,6 +235,12 @@ const (
 	AlignSub
 	// align to superscript
 	AlignSuper
+	// stretch to fill the line, for CSS align-items and align-self
+	AlignStretch
+	// same as CSS space-evenly
+	AlignSpaceEvenly
+	// use the align-items of the flex layout, for CSS align-self
+	AlignAuto
 	AlignN
 )
 
@@ -164,6 +266,33 @@ func IsAlignEnd(a Align) bool {
 	return (a == AlignRight || a == AlignBottom || a == AlignFlexEnd || a == AlignTextBottom)
 }
 
+// FlexDirections are the directions of the main axis of a flex layout,
+// as in the CSS flex-direction property
+type FlexDirections int32
+
+const (
+	// FlexRow arranges items from left to right
+	FlexRow FlexDirections = iota
+
+	// FlexRowReverse arranges items from right to left
+	FlexRowReverse
+
+	// FlexColumn arranges items from top to bottom
+	FlexColumn
+
+	// FlexColumnReverse arranges items from bottom to top
+	FlexColumnReverse
+
+	FlexDirectionsN
+)
+
+//go:generate stringer -type=FlexDirections
+
+var KiT_FlexDirections = kit.Enums.AddEnumAltLower(FlexDirectionsN, kit.NotBitFlag, StylePropProps, "Flex")
+
+func (ev FlexDirections) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *FlexDirections) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
 // overflow type -- determines what happens when there is too much stuff in a layout
 type Overflow int32
 
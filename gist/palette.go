@@ -0,0 +1,46 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import "github.com/goki/mat32"
+
+// TonalPalette generates colors of the same hue and chroma (colorfulness)
+// as a seed color, at any tone (lightness), in the perceptual OKLCH color
+// space, so that colors of the same tone from different palettes have the
+// same perceived lightness.  This is used to generate consistent color
+// themes from seed colors.
+type TonalPalette struct {
+	Hue    float32 `desc:"OKLCH hue, in degrees [0..360]"`
+	Chroma float32 `desc:"OKLCH chroma -- reduced as needed to fit the sRGB gamut at each tone"`
+}
+
+// NewTonalPalette returns a new tonal palette with the hue and chroma of
+// given seed color
+func NewTonalPalette(seed Color) TonalPalette {
+	lch := seed.ToOKLCH()
+	return TonalPalette{Hue: lch.H, Chroma: lch.C}
+}
+
+// Tone returns the color of the palette at given tone, which is the
+// OKLCH lightness in percent [0..100]: 0 = black, 100 = white
+func (tp TonalPalette) Tone(tone float32) Color {
+	var c Color
+	c.SetOKLCH(mat32.Clamp(tone, 0, 100)/100, tp.Chroma, tp.Hue, 1)
+	return c
+}
+
+// WithChroma returns a copy of the palette with chroma limited to
+// at most given max, e.g., for near-neutral colors
+func (tp TonalPalette) WithChroma(max float32) TonalPalette {
+	tp.Chroma = mat32.Min(tp.Chroma, max)
+	return tp
+}
+
+// WithHue returns a copy of the palette with the hue rotated by given
+// number of degrees
+func (tp TonalPalette) WithHue(rot float32) TonalPalette {
+	tp.Hue = mat32.Mod(tp.Hue+rot+360, 360)
+	return tp
+}
@@ -0,0 +1,404 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// CSS box properties such as margin, padding and the border can be
+// specified separately for each side of the box, using shorthands with
+// 1 to 4 values, in top, right, bottom, left order:
+//	1 value:  all sides
+//	2 values: top & bottom, right & left
+//	3 values: top, right & left, bottom
+//	4 values: top, right, bottom, left
+// The same applies to the corners of the box, for border-radius, in
+// top-left, top-right, bottom-right, bottom-left order.
+
+// sideIndexes returns, for given number of CSS shorthand values (1-4),
+// the index of the value to use for each side (top, right, bottom, left)
+func sideIndexes(n int) [BoxN]int {
+	switch n {
+	case 1:
+		return [BoxN]int{0, 0, 0, 0}
+	case 2:
+		return [BoxN]int{0, 1, 0, 1}
+	case 3:
+		return [BoxN]int{0, 1, 2, 1}
+	default:
+		return [BoxN]int{0, 1, 2, 3}
+	}
+}
+
+// splitSides splits a CSS shorthand value into its 1 to 4 fields,
+// returning an error if there are more than 4
+func splitSides(str string) ([]string, error) {
+	var flds []string
+	for _, fld := range splitTopLevel(strings.TrimSpace(str), ' ') {
+		if fld = strings.TrimSpace(fld); fld != "" {
+			flds = append(flds, fld)
+		}
+	}
+	if len(flds) == 0 || len(flds) > 4 {
+		return nil, fmt.Errorf("gist.Sides: must have 1 to 4 values: %q", str)
+	}
+	return flds, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+//  SideValues
+
+// SideValues contains a units.Value for each side of a box, as for margin,
+// padding and border-width, or for each corner of a box, for border-radius,
+// where Top is the top-left corner, Right is top-right, Bottom is
+// bottom-right and Left is bottom-left, following the CSS order.
+type SideValues struct {
+	Top    units.Value `xml:"top" desc:"top side, or top-left corner"`
+	Right  units.Value `xml:"right" desc:"right side, or top-right corner"`
+	Bottom units.Value `xml:"bottom" desc:"bottom side, or bottom-right corner"`
+	Left   units.Value `xml:"left" desc:"left side, or bottom-left corner"`
+}
+
+// NewSideValues returns new SideValues from 1 to 4 values, as in CSS shorthand
+func NewSideValues(vals ...units.Value) SideValues {
+	var sv SideValues
+	sv.Set(vals...)
+	return sv
+}
+
+// Set sets the sides from 1 to 4 values, as in CSS shorthand
+func (sv *SideValues) Set(vals ...units.Value) {
+	if len(vals) == 0 {
+		return
+	}
+	idx := sideIndexes(len(vals))
+	for s := BoxTop; s < BoxN; s++ {
+		*sv.Side(s) = vals[idx[s]]
+	}
+}
+
+// SetVal sets all sides to given value in given units
+func (sv *SideValues) SetVal(val float32, un units.Units) {
+	sv.Set(units.NewValue(val, un))
+}
+
+// Side returns a pointer to the value for given side
+func (sv *SideValues) Side(side BoxSides) *units.Value {
+	switch side {
+	case BoxTop:
+		return &sv.Top
+	case BoxRight:
+		return &sv.Right
+	case BoxBottom:
+		return &sv.Bottom
+	default:
+		return &sv.Left
+	}
+}
+
+// SetString sets the sides from a CSS shorthand string of 1 to 4 values
+func (sv *SideValues) SetString(str string) error {
+	flds, err := splitSides(str)
+	if err != nil {
+		return err
+	}
+	vals := make([]units.Value, len(flds))
+	for i, fld := range flds {
+		vals[i].SetString(fld)
+	}
+	sv.Set(vals...)
+	return nil
+}
+
+// SetIFace sets the sides from an interface value representation as from
+// ki.Props: a CSS shorthand string, a units.Value or a number (px) for all
+// sides, or SideValues.  key is optional property key for error message.
+func (sv *SideValues) SetIFace(iface interface{}, key string) error {
+	switch val := iface.(type) {
+	case string:
+		return sv.SetString(val)
+	case SideValues:
+		*sv = val
+	case *SideValues:
+		*sv = *val
+	default:
+		var v units.Value
+		if err := v.SetIFace(iface, key); err != nil {
+			return err
+		}
+		sv.Set(v)
+	}
+	return nil
+}
+
+// ToDots runs ToDots on unit values, to compile down to raw pixels
+func (sv *SideValues) ToDots(uc *units.Context) SideFloats {
+	sv.Top.ToDots(uc)
+	sv.Right.ToDots(uc)
+	sv.Bottom.ToDots(uc)
+	sv.Left.ToDots(uc)
+	return sv.Dots()
+}
+
+// Dots returns the dots values of the sides, as computed by ToDots
+func (sv *SideValues) Dots() SideFloats {
+	return SideFloats{Top: sv.Top.Dots, Right: sv.Right.Dots, Bottom: sv.Bottom.Dots, Left: sv.Left.Dots}
+}
+
+// String returns the CSS shorthand string representation of the sides
+func (sv *SideValues) String() string {
+	strs := []string{sv.Top.String(), sv.Right.String(), sv.Bottom.String(), sv.Left.String()}
+	return strings.Join(shortSides(strs), " ")
+}
+
+// shortSides returns the shortest CSS shorthand list of values
+// equivalent to given full list of 4 values
+func shortSides(strs []string) []string {
+	switch {
+	case strs[0] == strs[1] && strs[0] == strs[2] && strs[0] == strs[3]:
+		return strs[:1]
+	case strs[0] == strs[2] && strs[1] == strs[3]:
+		return strs[:2]
+	case strs[1] == strs[3]:
+		return strs[:3]
+	}
+	return strs
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+//  SideFloats
+
+// SideFloats contains a float32 value for each side of a box, or corner,
+// as for SideValues, typically in dots.
+type SideFloats struct {
+	Top    float32
+	Right  float32
+	Bottom float32
+	Left   float32
+}
+
+// NewSideFloats returns new SideFloats from 1 to 4 values, as in CSS shorthand
+func NewSideFloats(vals ...float32) SideFloats {
+	var sf SideFloats
+	if len(vals) == 0 {
+		return sf
+	}
+	idx := sideIndexes(len(vals))
+	sf.Top, sf.Right, sf.Bottom, sf.Left = vals[idx[0]], vals[idx[1]], vals[idx[2]], vals[idx[3]]
+	return sf
+}
+
+// Side returns the value for given side
+func (sf SideFloats) Side(side BoxSides) float32 {
+	switch side {
+	case BoxTop:
+		return sf.Top
+	case BoxRight:
+		return sf.Right
+	case BoxBottom:
+		return sf.Bottom
+	default:
+		return sf.Left
+	}
+}
+
+// Pos returns the offset of the content from the top-left of the box:
+// (Left, Top)
+func (sf SideFloats) Pos() mat32.Vec2 {
+	return mat32.Vec2{X: sf.Left, Y: sf.Top}
+}
+
+// Size returns the total space taken by the sides along each dimension:
+// (Left + Right, Top + Bottom)
+func (sf SideFloats) Size() mat32.Vec2 {
+	return mat32.Vec2{X: sf.Left + sf.Right, Y: sf.Top + sf.Bottom}
+}
+
+// Add returns the sum of the sides with the other sides
+func (sf SideFloats) Add(o SideFloats) SideFloats {
+	return SideFloats{Top: sf.Top + o.Top, Right: sf.Right + o.Right, Bottom: sf.Bottom + o.Bottom, Left: sf.Left + o.Left}
+}
+
+// Sub returns the sides minus the other sides
+func (sf SideFloats) Sub(o SideFloats) SideFloats {
+	return SideFloats{Top: sf.Top - o.Top, Right: sf.Right - o.Right, Bottom: sf.Bottom - o.Bottom, Left: sf.Left - o.Left}
+}
+
+// MulScalar returns the sides multiplied by given value
+func (sf SideFloats) MulScalar(s float32) SideFloats {
+	return SideFloats{Top: sf.Top * s, Right: sf.Right * s, Bottom: sf.Bottom * s, Left: sf.Left * s}
+}
+
+// Max returns the maximum value across the sides
+func (sf SideFloats) Max() float32 {
+	return mat32.Max(mat32.Max(sf.Top, sf.Right), mat32.Max(sf.Bottom, sf.Left))
+}
+
+// Min returns the minimum value across the sides
+func (sf SideFloats) Min() float32 {
+	return mat32.Min(mat32.Min(sf.Top, sf.Right), mat32.Min(sf.Bottom, sf.Left))
+}
+
+// IsUniform returns true if all sides have the same value
+func (sf SideFloats) IsUniform() bool {
+	return sf.Top == sf.Right && sf.Top == sf.Bottom && sf.Top == sf.Left
+}
+
+// IsZero returns true if all sides are zero
+func (sf SideFloats) IsZero() bool {
+	return sf == SideFloats{}
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+//  SideColors
+
+// SideColors contains a Color for each side of a box, as for border-color
+type SideColors struct {
+	Top    Color `xml:"top" desc:"top side"`
+	Right  Color `xml:"right" desc:"right side"`
+	Bottom Color `xml:"bottom" desc:"bottom side"`
+	Left   Color `xml:"left" desc:"left side"`
+}
+
+// Set sets the sides from 1 to 4 colors, as in CSS shorthand
+func (sc *SideColors) Set(clrs ...Color) {
+	if len(clrs) == 0 {
+		return
+	}
+	idx := sideIndexes(len(clrs))
+	for s := BoxTop; s < BoxN; s++ {
+		*sc.Side(s) = clrs[idx[s]]
+	}
+}
+
+// Side returns a pointer to the color for given side
+func (sc *SideColors) Side(side BoxSides) *Color {
+	switch side {
+	case BoxTop:
+		return &sc.Top
+	case BoxRight:
+		return &sc.Right
+	case BoxBottom:
+		return &sc.Bottom
+	default:
+		return &sc.Left
+	}
+}
+
+// IsUniform returns true if all sides have the same color
+func (sc *SideColors) IsUniform() bool {
+	return sc.Top == sc.Right && sc.Top == sc.Bottom && sc.Top == sc.Left
+}
+
+// SetIFace sets the sides from an interface value representation as from
+// ki.Props: a CSS shorthand string of 1 to 4 colors, a color for all sides,
+// or SideColors.  key is optional property key for error message.
+func (sc *SideColors) SetIFace(iface interface{}, ctxt Context, key string) error {
+	switch val := iface.(type) {
+	case string:
+		flds, err := splitSides(val)
+		if err != nil {
+			return err
+		}
+		clrs := make([]Color, len(flds))
+		for i, fld := range flds {
+			if err := clrs[i].SetIFace(fld, ctxt, key); err != nil {
+				return err
+			}
+		}
+		sc.Set(clrs...)
+	case SideColors:
+		*sc = val
+	case *SideColors:
+		*sc = *val
+	default:
+		var c Color
+		if err := c.SetIFace(iface, ctxt, key); err != nil {
+			return err
+		}
+		sc.Set(c)
+	}
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+//  SideBorderStyles
+
+// SideBorderStyles contains a BorderStyles for each side of a box,
+// as for border-style
+type SideBorderStyles struct {
+	Top    BorderStyles `xml:"top" desc:"top side"`
+	Right  BorderStyles `xml:"right" desc:"right side"`
+	Bottom BorderStyles `xml:"bottom" desc:"bottom side"`
+	Left   BorderStyles `xml:"left" desc:"left side"`
+}
+
+// Set sets the sides from 1 to 4 styles, as in CSS shorthand
+func (ss *SideBorderStyles) Set(sts ...BorderStyles) {
+	if len(sts) == 0 {
+		return
+	}
+	idx := sideIndexes(len(sts))
+	for s := BoxTop; s < BoxN; s++ {
+		*ss.Side(s) = sts[idx[s]]
+	}
+}
+
+// Side returns a pointer to the style for given side
+func (ss *SideBorderStyles) Side(side BoxSides) *BorderStyles {
+	switch side {
+	case BoxTop:
+		return &ss.Top
+	case BoxRight:
+		return &ss.Right
+	case BoxBottom:
+		return &ss.Bottom
+	default:
+		return &ss.Left
+	}
+}
+
+// IsUniform returns true if all sides have the same style
+func (ss *SideBorderStyles) IsUniform() bool {
+	return ss.Top == ss.Right && ss.Top == ss.Bottom && ss.Top == ss.Left
+}
+
+// SetIFace sets the sides from an interface value representation as from
+// ki.Props: a CSS shorthand string of 1 to 4 styles, a BorderStyles for
+// all sides, or SideBorderStyles.  key is optional property key for error
+// message.
+func (ss *SideBorderStyles) SetIFace(iface interface{}, key string) error {
+	switch val := iface.(type) {
+	case string:
+		flds, err := splitSides(val)
+		if err != nil {
+			return err
+		}
+		sts := make([]BorderStyles, len(flds))
+		for i, fld := range flds {
+			if err := kit.Enums.SetAnyEnumIfaceFromString(&sts[i], fld); err != nil {
+				return err
+			}
+		}
+		ss.Set(sts...)
+	case BorderStyles:
+		ss.Set(val)
+	case SideBorderStyles:
+		*ss = val
+	default:
+		iv, ok := kit.ToInt(iface)
+		if !ok {
+			return fmt.Errorf("gist.SideBorderStyles: could not set property: %v from: %v type: %T", key, iface, iface)
+		}
+		ss.Set(BorderStyles(iv))
+	}
+	return nil
+}
@@ -37,20 +37,23 @@ var StyleTemplatesMu sync.RWMutex
 
 // Style has all the CSS-based style elements -- used for widget-type objects
 type Style struct {
-	Template      string        `desc:"if present, then this should use unique template name for cached style -- critical for large numbers of repeated widgets in e.g., sliceview, tableview, etc"`
-	Display       bool          `xml:"display" desc:"todo big enum of how to display item -- controls layout etc"`
-	Visible       bool          `xml:"visible" desc:"is the item visible or not"`
-	Inactive      bool          `xml:"inactive" desc:"make a control inactive so it does not respond to input"`
-	Layout        Layout        `desc:"layout styles -- do not prefix with any xml"`
-	Border        Border        `xml:"border" desc:"border around the box element -- todo: can have separate ones for different sides"`
-	BoxShadow     Shadow        `xml:"box-shadow" desc:"prop: box-shadow = type of shadow to render around box"`
-	Font          Font          `desc:"font parameters -- no xml prefix -- also has color, background-color"`
-	Text          Text          `desc:"text parameters -- no xml prefix"`
-	Outline       Border        `xml:"outline" desc:"prop: outline = draw an outline around an element -- mostly same styles as border -- default to none"`
-	PointerEvents bool          `xml:"pointer-events" desc:"prop: pointer-events = does this element respond to pointer events -- default is true"`
-	UnContext     units.Context `xml:"-" desc:"units context -- parameters necessary for anchoring relative units"`
-	IsSet         bool          `desc:"has this style been set from object values yet?"`
-	PropsNil      bool          `desc:"set to true if parent node has no props -- allows optimization of styling"`
+	Template      string                 `desc:"if present, then this should use unique template name for cached style -- critical for large numbers of repeated widgets in e.g., sliceview, tableview, etc"`
+	Display       bool                   `xml:"display" desc:"todo big enum of how to display item -- controls layout etc"`
+	Visible       bool                   `xml:"visible" desc:"is the item visible or not"`
+	Inactive      bool                   `xml:"inactive" desc:"make a control inactive so it does not respond to input"`
+	Layout        Layout                 `desc:"layout styles -- do not prefix with any xml"`
+	Border        Border                 `xml:"border" desc:"border around the box element -- can be different for each side"`
+	BoxShadow     Shadow                 `xml:"box-shadow" desc:"prop: box-shadow = type of shadow to render around box"`
+	ExtraShadows  []Shadow               `xml:"-" desc:"additional shadows drawn below BoxShadow, from a box-shadow property with a list of shadows, e.g., 0 1px 2px #0004, 0 4px 12px #0002 -- never modified in place, so it can be shared -- see BoxShadows"`
+	Font          Font                   `desc:"font parameters -- no xml prefix -- also has color, background-color"`
+	Text          Text                   `desc:"text parameters -- no xml prefix"`
+	Outline       Border                 `xml:"outline" desc:"prop: outline = draw an outline around an element -- mostly same styles as border -- default to none"`
+	PointerEvents bool                   `xml:"pointer-events" desc:"prop: pointer-events = does this element respond to pointer events -- default is true"`
+	Transition    []Transition           `xml:"transition" desc:"prop: transition = animated transitions of style properties when they change, e.g., on hover: background-color 150ms ease-out -- see Transition"`
+	Vars          map[string]interface{} `xml:"-" view:"-" desc:"custom properties (--name), inherited from the parent, which can be referenced in other properties with var(--name, fallback) -- shared and never modified in place -- see SetVars"`
+	UnContext     units.Context          `xml:"-" desc:"units context -- parameters necessary for anchoring relative units"`
+	IsSet         bool                   `desc:"has this style been set from object values yet?"`
+	PropsNil      bool                   `desc:"set to true if parent node has no props -- allows optimization of styling"`
 	dotsSet       bool
 	lastUnCtxt    units.Context
 }
@@ -59,7 +62,7 @@ func (s *Style) Defaults() {
 	// mostly all the defaults are 0 initial values, except these..
 	s.IsSet = false
 	s.UnContext.Defaults()
-	s.Outline.Style = BorderNone
+	s.Outline.Style.Set(BorderNone)
 	s.Display = true
 	s.PointerEvents = true
 	s.Layout.Defaults()
@@ -81,8 +84,6 @@ func (s *Style) Defaults() {
 
 // visibility -- support more than just hidden  inherit:"true"
 
-// transition -- animation of hover, etc
-
 // RebuildDefaultStyles is a global state var used by Prefs to trigger rebuild
 // of all the default styles, which are otherwise compiled and not updated
 var RebuildDefaultStyles bool
@@ -212,8 +213,8 @@ func (s *Style) SetStyleProps(par *Style, props ki.Props, ctxt Context) {
 		s.InheritFields(par)
 	}
 	s.StyleFromProps(par, props, ctxt)
-	if s.Layout.Margin.Val > 0 && s.Text.ParaSpacing.Val == 0 {
-		s.Text.ParaSpacing = s.Layout.Margin
+	if s.Layout.Margin.Top.Val > 0 && s.Text.ParaSpacing.Val == 0 {
+		s.Text.ParaSpacing = s.Layout.Margin.Top
 	}
 	s.Layout.SetStylePost(props)
 	s.Font.SetStylePost(props)
@@ -236,6 +237,29 @@ func (s *Style) ToDotsImpl(uc *units.Context) {
 	s.Border.ToDots(uc)
 	s.Outline.ToDots(uc)
 	s.BoxShadow.ToDots(uc)
+	if len(s.ExtraShadows) > 0 {
+		shs := make([]Shadow, len(s.ExtraShadows))
+		copy(shs, s.ExtraShadows)
+		for i := range shs {
+			shs[i].ToDots(uc)
+		}
+		s.ExtraShadows = shs
+	}
+}
+
+// BoxShadows returns all of the visible box shadows, BoxShadow and then
+// any ExtraShadows, in CSS order: the first one is drawn on top
+func (s *Style) BoxShadows() []Shadow {
+	var shs []Shadow
+	if s.BoxShadow.HasShadow() {
+		shs = append(shs, s.BoxShadow)
+	}
+	for i := range s.ExtraShadows {
+		if s.ExtraShadows[i].HasShadow() {
+			shs = append(shs, s.ExtraShadows[i])
+		}
+	}
+	return shs
 }
 
 // ToDots caches all style elements in terms of raw pixel
@@ -257,10 +281,11 @@ func (s *Style) CopyUnitContext(ctxt *units.Context) {
 }
 
 // BoxSpace returns extra space around the central content in the box model,
-// in dots -- todo: must complicate this if we want different spacing on
-// different sides box outside-in: margin | border | padding | content
-func (s *Style) BoxSpace() float32 {
-	return s.Layout.Margin.Dots + s.Border.Width.Dots + s.Layout.Padding.Dots
+// in dots, for each side -- box outside-in: margin | border | padding | content.
+// Use Pos() for the offset of the content and Size() for the total space.
+// Sides with border-style none or hidden have no border (see Border.WidthDots).
+func (s *Style) BoxSpace() SideFloats {
+	return s.Layout.Margin.Dots().Add(s.Border.WidthDots()).Add(s.Layout.Padding.Dots())
 }
 
 // SubProps returns a sub-property map from given prop map for a given styling
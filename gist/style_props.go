@@ -6,6 +6,7 @@ package gist
 
 import (
 	"log"
+	"strings"
 
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
@@ -37,69 +38,93 @@ func StyleSetError(key string, val interface{}) {
 
 type StyleFunc func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context)
 
-// StyleFromProps sets style field values based on ki.Props properties
+// StyleFromProps sets style field values based on ki.Props properties.
+// Per-side longhand properties such as margin-top are applied after all
+// other properties, so they override the corresponding shorthand, as in CSS.
+// Custom properties (--name) are set first, so they can be referenced
+// with var(--name) in the other properties -- see SetVars.
 func (s *Style) StyleFromProps(par *Style, props ki.Props, ctxt Context) {
 	// pr := prof.Start("StyleFromProps")
 	// defer pr.End()
+	s.SetVars(par, props)
+	var sides []string
 	for key, val := range props {
 		if len(key) == 0 {
 			continue
 		}
-		if key[0] == '#' || key[0] == '.' || key[0] == ':' || key[0] == '_' {
+		if key[0] == '#' || key[0] == '.' || key[0] == ':' || key[0] == '_' || IsStyleVar(key) {
 			continue
 		}
-		if sfunc, ok := StyleLayoutFuncs[key]; ok {
-			if par != nil {
-				sfunc(&s.Layout, key, val, &par.Layout, ctxt)
-			} else {
-				sfunc(&s.Layout, key, val, nil, ctxt)
-			}
+		if _, ok := StyleSideProps[key]; ok {
+			sides = append(sides, key)
 			continue
 		}
-		if sfunc, ok := StyleFontFuncs[key]; ok {
-			if par != nil {
-				sfunc(&s.Font, key, val, &par.Font, ctxt)
-			} else {
-				sfunc(&s.Font, key, val, nil, ctxt)
-			}
-			continue
+		s.StyleFromProp(par, key, val, ctxt)
+	}
+	for _, key := range sides {
+		s.StyleFromProp(par, key, props[key], ctxt)
+	}
+}
+
+// StyleFromProp sets style field value for given property key and value,
+// resolving any var() references -- the property is not set if they
+// cannot be resolved, as in CSS
+func (s *Style) StyleFromProp(par *Style, key string, val interface{}, ctxt Context) {
+	val, ok := s.ResolveVars(val)
+	if !ok {
+		return
+	}
+	if sfunc, ok := StyleLayoutFuncs[key]; ok {
+		if par != nil {
+			sfunc(&s.Layout, key, val, &par.Layout, ctxt)
+		} else {
+			sfunc(&s.Layout, key, val, nil, ctxt)
 		}
-		if sfunc, ok := StyleTextFuncs[key]; ok {
-			if par != nil {
-				sfunc(&s.Text, key, val, &par.Text, ctxt)
-			} else {
-				sfunc(&s.Text, key, val, nil, ctxt)
-			}
-			continue
+		return
+	}
+	if sfunc, ok := StyleFontFuncs[key]; ok {
+		if par != nil {
+			sfunc(&s.Font, key, val, &par.Font, ctxt)
+		} else {
+			sfunc(&s.Font, key, val, nil, ctxt)
 		}
-		if sfunc, ok := StyleBorderFuncs[key]; ok {
-			if par != nil {
-				sfunc(&s.Border, key, val, &par.Border, ctxt)
-			} else {
-				sfunc(&s.Border, key, val, nil, ctxt)
-			}
-			continue
+		return
+	}
+	if sfunc, ok := StyleTextFuncs[key]; ok {
+		if par != nil {
+			sfunc(&s.Text, key, val, &par.Text, ctxt)
+		} else {
+			sfunc(&s.Text, key, val, nil, ctxt)
 		}
-		if sfunc, ok := StyleStyleFuncs[key]; ok {
-			sfunc(s, key, val, par, ctxt)
-			continue
+		return
+	}
+	if sfunc, ok := StyleBorderFuncs[key]; ok {
+		if par != nil {
+			sfunc(&s.Border, key, val, &par.Border, ctxt)
+		} else {
+			sfunc(&s.Border, key, val, nil, ctxt)
 		}
-		if sfunc, ok := StyleOutlineFuncs[key]; ok {
-			if par != nil {
-				sfunc(&s.Outline, key, val, &par.Outline, ctxt)
-			} else {
-				sfunc(&s.Outline, key, val, nil, ctxt)
-			}
-			continue
+		return
+	}
+	if sfunc, ok := StyleStyleFuncs[key]; ok {
+		sfunc(s, key, val, par, ctxt)
+		return
+	}
+	if sfunc, ok := StyleOutlineFuncs[key]; ok {
+		if par != nil {
+			sfunc(&s.Outline, key, val, &par.Outline, ctxt)
+		} else {
+			sfunc(&s.Outline, key, val, nil, ctxt)
 		}
-		if sfunc, ok := StyleShadowFuncs[key]; ok {
-			if par != nil {
-				sfunc(&s.BoxShadow, key, val, &par.BoxShadow, ctxt)
-			} else {
-				sfunc(&s.BoxShadow, key, val, nil, ctxt)
-			}
-			continue
+		return
+	}
+	if sfunc, ok := StyleShadowFuncs[key]; ok {
+		if par != nil {
+			sfunc(&s.BoxShadow, key, val, &par.BoxShadow, ctxt)
+		} else {
+			sfunc(&s.BoxShadow, key, val, nil, ctxt)
 		}
+		return
 	}
 }
 
@@ -108,6 +133,42 @@ func (s *Style) StyleFromProps(par *Style, props ki.Props, ctxt Context) {
 
 // StyleStyleFuncs are functions for styling the Style object itself
 var StyleStyleFuncs = map[string]StyleFunc{
+	"box-shadow": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		s := obj.(*Style)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				s.BoxShadow = par.(*Style).BoxShadow
+				s.ExtraShadows = par.(*Style).ExtraShadows
+			} else if init {
+				s.BoxShadow = Shadow{}
+				s.ExtraShadows = nil
+			}
+			return
+		}
+		var shs []Shadow
+		switch vt := val.(type) {
+		case []Shadow:
+			shs = vt
+		case Shadow:
+			shs = []Shadow{vt}
+		case *Shadow:
+			shs = []Shadow{*vt}
+		default:
+			var err error
+			shs, err = ParseBoxShadows(kit.ToString(val), ctxt)
+			if err != nil {
+				log.Printf("gist.Style box-shadow: %v\n", err)
+			}
+		}
+		s.BoxShadow = Shadow{}
+		s.ExtraShadows = nil
+		if len(shs) > 0 {
+			s.BoxShadow = shs[0]
+		}
+		if len(shs) > 1 {
+			s.ExtraShadows = append([]Shadow(nil), shs[1:]...)
+		}
+	},
 	"display": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
 		s := obj.(*Style)
 		if inh, init := StyleInhInit(val, par); inh || init {
@@ -168,6 +229,28 @@ var StyleStyleFuncs = map[string]StyleFunc{
 			s.PointerEvents = bv
 		}
 	},
+	"transition": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		s := obj.(*Style)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				s.Transition = par.(*Style).Transition
+			} else if init {
+				s.Transition = nil
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case []Transition:
+			s.Transition = vt
+		default:
+			trs, err := ParseTransitions(kit.ToString(val))
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			s.Transition = trs
+		}
+	},
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -337,7 +420,7 @@ var StyleLayoutFuncs = map[string]StyleFunc{
 			if inh {
 				ly.Margin = par.(*Layout).Margin
 			} else if init {
-				ly.Margin.Val = 0
+				ly.Margin = SideValues{}
 			}
 			return
 		}
@@ -349,7 +432,7 @@ var StyleLayoutFuncs = map[string]StyleFunc{
 			if inh {
 				ly.Padding = par.(*Layout).Padding
 			} else if init {
-				ly.Padding.Val = 0
+				ly.Padding = SideValues{}
 			}
 			return
 		}
@@ -460,6 +543,287 @@ var StyleLayoutFuncs = map[string]StyleFunc{
 		}
 		ly.ScrollBarWidth.SetIFace(val, key)
 	},
+	"flex-direction": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexDirection = par.(*Layout).FlexDirection
+			} else if init {
+				ly.FlexDirection = FlexRow
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ly.FlexDirection, FlexCSSEnumString(vt))
+		case FlexDirections:
+			ly.FlexDirection = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ly.FlexDirection = FlexDirections(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"flex-wrap": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexWrap = par.(*Layout).FlexWrap
+			} else if init {
+				ly.FlexWrap = false
+			}
+			return
+		}
+		switch kit.ToString(val) {
+		case "wrap", "wrap-reverse":
+			ly.FlexWrap = true
+		case "nowrap":
+			ly.FlexWrap = false
+		default:
+			if bv, ok := kit.ToBool(val); ok {
+				ly.FlexWrap = bv
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"flex-grow": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexGrow = par.(*Layout).FlexGrow
+			} else if init {
+				ly.FlexGrow = 0
+			}
+			return
+		}
+		if fv, ok := kit.ToFloat32(val); ok {
+			ly.FlexGrow = fv
+		} else {
+			StyleSetError(key, val)
+		}
+	},
+	"flex-shrink": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexShrink = par.(*Layout).FlexShrink
+			} else if init {
+				ly.FlexShrink = 1
+			}
+			return
+		}
+		if fv, ok := kit.ToFloat32(val); ok {
+			ly.FlexShrink = fv
+		} else {
+			StyleSetError(key, val)
+		}
+	},
+	"flex-basis": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexBasis = par.(*Layout).FlexBasis
+			} else if init {
+				ly.FlexBasis.Set(-1, units.Px)
+			}
+			return
+		}
+		if kit.ToString(val) == "auto" {
+			ly.FlexBasis.Set(-1, units.Px)
+			return
+		}
+		ly.FlexBasis.SetIFace(val, key)
+	},
+	"flex": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				pl := par.(*Layout)
+				ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = pl.FlexGrow, pl.FlexShrink, pl.FlexBasis
+			} else if init {
+				ly.FlexGrow, ly.FlexShrink = 0, 1
+				ly.FlexBasis.Set(-1, units.Px)
+			}
+			return
+		}
+		if err := ly.SetFlex(kit.ToString(val)); err != nil {
+			StyleSetError(key, val)
+		}
+	},
+	"justify-content": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.JustifyContent = par.(*Layout).JustifyContent
+			} else if init {
+				ly.JustifyContent = AlignFlexStart
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ly.JustifyContent, FlexCSSEnumString(vt))
+		case Align:
+			ly.JustifyContent = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ly.JustifyContent = Align(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"align-items": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.AlignItems = par.(*Layout).AlignItems
+			} else if init {
+				ly.AlignItems = AlignStretch
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ly.AlignItems, FlexCSSEnumString(vt))
+		case Align:
+			ly.AlignItems = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ly.AlignItems = Align(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"align-self": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.AlignSelf = par.(*Layout).AlignSelf
+			} else if init {
+				ly.AlignSelf = AlignAuto
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ly.AlignSelf, FlexCSSEnumString(vt))
+		case Align:
+			ly.AlignSelf = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ly.AlignSelf = Align(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"gap": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.Gap = par.(*Layout).Gap
+			} else if init {
+				ly.Gap.Val = 0
+			}
+			return
+		}
+		ly.Gap.SetIFace(val, key)
+	},
+	"grid-template-columns": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.GridTemplateColumns = par.(*Layout).GridTemplateColumns
+			} else if init {
+				ly.GridTemplateColumns = nil
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			gts, err := ParseGridTracks(vt)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			ly.GridTemplateColumns = gts
+		case []GridTrack:
+			ly.GridTemplateColumns = vt
+		default:
+			StyleSetError(key, val)
+		}
+	},
+	"grid-template-rows": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.GridTemplateRows = par.(*Layout).GridTemplateRows
+			} else if init {
+				ly.GridTemplateRows = nil
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			gts, err := ParseGridTracks(vt)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			ly.GridTemplateRows = gts
+		case []GridTrack:
+			ly.GridTemplateRows = vt
+		default:
+			StyleSetError(key, val)
+		}
+	},
+	"grid-template-areas": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.GridTemplateAreas = par.(*Layout).GridTemplateAreas
+			} else if init {
+				ly.GridTemplateAreas = nil
+			}
+			return
+		}
+		var areas [][]string
+		var err error
+		switch vt := val.(type) {
+		case string:
+			areas, err = ParseGridAreas(vt)
+		case []string:
+			areas, err = GridAreasFromRows(vt)
+		case [][]string:
+			ly.GridTemplateAreas = vt
+			return
+		default:
+			StyleSetError(key, val)
+			return
+		}
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		ly.GridTemplateAreas = areas
+	},
+	"grid-area": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.GridArea = par.(*Layout).GridArea
+			} else if init {
+				ly.GridArea = ""
+			}
+			return
+		}
+		ly.GridArea = kit.ToString(val)
+	},
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -952,6 +1316,154 @@ var StyleTextFuncs = map[string]StyleFunc{
 			ts.TabSize = int(iv)
 		}
 	},
+	"text-overflow": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.Overflow = par.(*Text).Overflow
+			} else if init {
+				ts.Overflow = TextOverflowClip
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ts.Overflow, strings.Replace(vt, "-", "", -1))
+		case TextOverflows:
+			ts.Overflow = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ts.Overflow = TextOverflows(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"max-lines":          StyleTextMaxLines,
+	"line-clamp":         StyleTextMaxLines,
+	"-webkit-line-clamp": StyleTextMaxLines,
+	"shrink-to-fit": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.ShrinkToFit = par.(*Text).ShrinkToFit
+			} else if init {
+				ts.ShrinkToFit = 0
+			}
+			return
+		}
+		if iv, ok := kit.ToFloat32(val); ok {
+			ts.ShrinkToFit = iv
+		}
+	},
+	"hyphens": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.Hyphens = par.(*Text).Hyphens
+			} else if init {
+				ts.Hyphens = HyphensManual
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ts.Hyphens, vt)
+		case Hyphens:
+			ts.Hyphens = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ts.Hyphens = Hyphens(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"lang": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.Lang = par.(*Text).Lang
+			} else if init {
+				ts.Lang = ""
+			}
+			return
+		}
+		ts.Lang = kit.ToString(val)
+	},
+	"hanging-punctuation": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.HangingPunct = par.(*Text).HangingPunct
+			} else if init {
+				ts.HangingPunct = 0
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			ts.HangingPunct = 0
+			if vt != "none" { // space-separated list, e.g., first allow-end
+				flgs := strings.Join(strings.Fields(strings.Replace(vt, "-", "", -1)), "|")
+				kit.Enums.SetAnyEnumIfaceFromString(&ts.HangingPunct, flgs)
+			}
+		case HangingPuncts:
+			ts.HangingPunct = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ts.HangingPunct = HangingPuncts(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+	"text-wrap-style": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.WrapStyle = par.(*Text).WrapStyle
+			} else if init {
+				ts.WrapStyle = WrapStyleAuto
+			}
+			return
+		}
+		switch vt := val.(type) {
+		case string:
+			kit.Enums.SetAnyEnumIfaceFromString(&ts.WrapStyle, vt)
+		case TextWrapStyles:
+			ts.WrapStyle = vt
+		default:
+			if iv, ok := kit.ToInt(val); ok {
+				ts.WrapStyle = TextWrapStyles(iv)
+			} else {
+				StyleSetError(key, val)
+			}
+		}
+	},
+}
+
+// StyleTextMaxLines is the StyleFunc for the max-lines property, and its
+// line-clamp and -webkit-line-clamp aliases
+func StyleTextMaxLines(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+	ts := obj.(*Text)
+	if inh, init := StyleInhInit(val, par); inh || init {
+		if inh {
+			ts.MaxLines = par.(*Text).MaxLines
+		} else if init {
+			ts.MaxLines = 0
+		}
+		return
+	}
+	if str, ok := val.(string); ok && str == "none" {
+		ts.MaxLines = 0
+		return
+	}
+	if iv, ok := kit.ToInt(val); ok {
+		ts.MaxLines = int(iv)
+	} else {
+		StyleSetError(key, val)
+	}
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -965,21 +1477,12 @@ var StyleBorderFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Style = par.(*Border).Style
 			} else if init {
-				bs.Style = BorderSolid
+				bs.Style.Set(BorderSolid)
 			}
 			return
 		}
-		switch vt := val.(type) {
-		case string:
-			kit.Enums.SetAnyEnumIfaceFromString(&bs.Style, vt)
-		case BorderStyles:
-			bs.Style = vt
-		default:
-			if iv, ok := kit.ToInt(val); ok {
-				bs.Style = BorderStyles(iv)
-			} else {
-				StyleSetError(key, val)
-			}
+		if err := bs.Style.SetIFace(val, key); err != nil {
+			StyleSetError(key, val)
 		}
 	},
 	"border-width": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
@@ -988,7 +1491,7 @@ var StyleBorderFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Width = par.(*Border).Width
 			} else if init {
-				bs.Width.Val = 0
+				bs.Width = SideValues{}
 			}
 			return
 		}
@@ -1000,7 +1503,7 @@ var StyleBorderFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Radius = par.(*Border).Radius
 			} else if init {
-				bs.Radius.Val = 0
+				bs.Radius = SideValues{}
 			}
 			return
 		}
@@ -1012,7 +1515,7 @@ var StyleBorderFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Color = par.(*Border).Color
 			} else if init {
-				bs.Color = Black
+				bs.Color.Set(Black)
 			}
 			return
 		}
@@ -1020,6 +1523,103 @@ var StyleBorderFuncs = map[string]StyleFunc{
 	},
 }
 
+/////////////////////////////////////////////////////////////////////////////////
+//  Sides
+
+// StyleSideProps are the per-side (and per-corner) longhand properties,
+// e.g., margin-top, border-left-color or border-top-left-radius, which
+// are added to StyleLayoutFuncs and StyleBorderFuncs, and applied after
+// the shorthand properties
+var StyleSideProps = map[string]BoxSides{}
+
+// StyleSideNames are the CSS names of the sides, in BoxSides order
+var StyleSideNames = [BoxN]string{"top", "right", "bottom", "left"}
+
+// StyleCornerNames are the CSS names of the corners, in the order used
+// for border-radius in SideValues
+var StyleCornerNames = [BoxN]string{"top-left", "top-right", "bottom-right", "bottom-left"}
+
+func init() {
+	for side := BoxTop; side < BoxN; side++ {
+		sd := side
+		nm := StyleSideNames[sd]
+		lyfun := func(fld func(ly *Layout) *SideValues) StyleFunc {
+			return func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+				sv := fld(obj.(*Layout)).Side(sd)
+				if inh, init := StyleInhInit(val, par); inh || init {
+					if inh {
+						*sv = *fld(par.(*Layout)).Side(sd)
+					} else if init {
+						*sv = units.Value{}
+					}
+					return
+				}
+				sv.SetIFace(val, key)
+			}
+		}
+		StyleLayoutFuncs["margin-"+nm] = lyfun(func(ly *Layout) *SideValues { return &ly.Margin })
+		StyleLayoutFuncs["padding-"+nm] = lyfun(func(ly *Layout) *SideValues { return &ly.Padding })
+		StyleBorderFuncs["border-"+nm+"-style"] = func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+			st := obj.(*Border).Style.Side(sd)
+			if inh, init := StyleInhInit(val, par); inh || init {
+				if inh {
+					*st = *par.(*Border).Style.Side(sd)
+				} else if init {
+					*st = BorderSolid
+				}
+				return
+			}
+			var ss SideBorderStyles
+			if err := ss.SetIFace(val, key); err != nil {
+				StyleSetError(key, val)
+				return
+			}
+			*st = ss.Top
+		}
+		StyleBorderFuncs["border-"+nm+"-width"] = func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+			wd := obj.(*Border).Width.Side(sd)
+			if inh, init := StyleInhInit(val, par); inh || init {
+				if inh {
+					*wd = *par.(*Border).Width.Side(sd)
+				} else if init {
+					*wd = units.Value{}
+				}
+				return
+			}
+			wd.SetIFace(val, key)
+		}
+		StyleBorderFuncs["border-"+nm+"-color"] = func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+			clr := obj.(*Border).Color.Side(sd)
+			if inh, init := StyleInhInit(val, par); inh || init {
+				if inh {
+					*clr = *par.(*Border).Color.Side(sd)
+				} else if init {
+					*clr = Black
+				}
+				return
+			}
+			if err := clr.SetIFace(val, ctxt, key); err != nil {
+				StyleSetError(key, val)
+			}
+		}
+		StyleBorderFuncs["border-"+StyleCornerNames[sd]+"-radius"] = func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+			rad := obj.(*Border).Radius.Side(sd)
+			if inh, init := StyleInhInit(val, par); inh || init {
+				if inh {
+					*rad = *par.(*Border).Radius.Side(sd)
+				} else if init {
+					*rad = units.Value{}
+				}
+				return
+			}
+			rad.SetIFace(val, key)
+		}
+		for _, pnm := range []string{"margin-" + nm, "padding-" + nm, "border-" + nm + "-style", "border-" + nm + "-width", "border-" + nm + "-color", "border-" + StyleCornerNames[sd] + "-radius"} {
+			StyleSideProps[pnm] = sd
+		}
+	}
+}
+
 /////////////////////////////////////////////////////////////////////////////////
 //  Outline
 
@@ -1031,21 +1631,12 @@ var StyleOutlineFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Style = par.(*Border).Style
 			} else if init {
-				bs.Style = BorderNone
+				bs.Style.Set(BorderNone)
 			}
 			return
 		}
-		switch vt := val.(type) {
-		case string:
-			kit.Enums.SetAnyEnumIfaceFromString(&bs.Style, vt)
-		case BorderStyles:
-			bs.Style = vt
-		default:
-			if iv, ok := kit.ToInt(val); ok {
-				bs.Style = BorderStyles(iv)
-			} else {
-				StyleSetError(key, val)
-			}
+		if err := bs.Style.SetIFace(val, key); err != nil {
+			StyleSetError(key, val)
 		}
 	},
 	"outline-width": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
@@ -1054,7 +1645,7 @@ var StyleOutlineFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Width = par.(*Border).Width
 			} else if init {
-				bs.Width.Val = 0
+				bs.Width = SideValues{}
 			}
 			return
 		}
@@ -1066,7 +1657,7 @@ var StyleOutlineFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Radius = par.(*Border).Radius
 			} else if init {
-				bs.Radius.Val = 0
+				bs.Radius = SideValues{}
 			}
 			return
 		}
@@ -1078,7 +1669,7 @@ var StyleOutlineFuncs = map[string]StyleFunc{
 			if inh {
 				bs.Color = par.(*Border).Color
 			} else if init {
-				bs.Color = Black
+				bs.Color.Set(Black)
 			}
 			return
 		}
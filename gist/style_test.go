@@ -6,8 +6,10 @@ package gist
 
 import (
 	"fmt"
+	"image"
 	// "reflect"
 	"testing"
+	"time"
 
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
@@ -36,3 +38,261 @@ func TestStyle(t *testing.T) {
 	fmt.Printf("style box-shadow.v-offset: %v\n", s.BoxShadow.VOffset)
 	fmt.Printf("style border-style: %v\n", s.Border.Style)
 }
+
+func TestParseTransitions(t *testing.T) {
+	trs, err := ParseTransitions("background-color 150ms ease-out, color .2s cubic-bezier(0.1, 0.7, 1.0, 0.1) 50ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trs) != 2 {
+		t.Fatalf("ParseTransitions: got %d transitions, not 2", len(trs))
+	}
+	exp := Transition{Prop: "background-color", Duration: 150 * time.Millisecond, Ease: "ease-out"}
+	if trs[0] != exp {
+		t.Errorf("ParseTransitions: got %v, not %v", trs[0], exp)
+	}
+	exp = Transition{Prop: "color", Duration: 200 * time.Millisecond, Delay: 50 * time.Millisecond, Ease: "cubic-bezier(0.1, 0.7, 1.0, 0.1)"}
+	if trs[1] != exp {
+		t.Errorf("ParseTransitions: got %v, not %v", trs[1], exp)
+	}
+	if _, err := ParseTransitions("color ease"); err == nil {
+		t.Errorf("ParseTransitions: expected error for missing duration")
+	}
+
+	var s Style
+	s.Defaults()
+	s.SetStyleProps(nil, ki.Props{"transition": "all 1s"}, nil)
+	if tr, ok := s.TransitionFor("opacity"); !ok || tr.Duration != time.Second || tr.Ease != "ease" {
+		t.Errorf("TransitionFor: got %v, %v", tr, ok)
+	}
+}
+
+func TestFlexProps(t *testing.T) {
+	props := ki.Props{
+		"flex":            "2",
+		"flex-direction":  "column-reverse",
+		"flex-wrap":       "wrap",
+		"justify-content": "space-between",
+		"align-items":     "flex-end",
+		"align-self":      "center",
+		"gap":             "4px",
+	}
+	var s Style
+	s.Defaults()
+	if s.Layout.FlexShrink != 1 || s.Layout.FlexBasis.Val >= 0 || s.Layout.AlignItems != AlignStretch || s.Layout.AlignSelf != AlignAuto {
+		t.Errorf("flex defaults: %+v", s.Layout)
+	}
+	s.SetStyleProps(nil, props, nil)
+	ly := &s.Layout
+	if ly.FlexGrow != 2 || ly.FlexShrink != 1 || ly.FlexBasis.Val != 0 {
+		t.Errorf("flex shorthand: grow %v shrink %v basis %v", ly.FlexGrow, ly.FlexShrink, ly.FlexBasis)
+	}
+	if ly.FlexDirection != FlexColumnReverse || !ly.FlexWrap {
+		t.Errorf("flex-direction: %v flex-wrap: %v", ly.FlexDirection, ly.FlexWrap)
+	}
+	if ly.JustifyContent != AlignJustify || ly.AlignItems != AlignFlexEnd || ly.AlignSelf != AlignCenter {
+		t.Errorf("justify-content: %v align-items: %v align-self: %v", ly.JustifyContent, ly.AlignItems, ly.AlignSelf)
+	}
+	if ly.Gap.Val != 4 {
+		t.Errorf("gap: %v", ly.Gap)
+	}
+}
+
+func TestParseGrid(t *testing.T) {
+	gts, err := ParseGridTracks("100px repeat(2, 1fr) minmax(4em, auto) auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strs := []string{"100px", "1fr", "1fr", "minmax(4em, auto)", "auto"}
+	if len(gts) != len(strs) {
+		t.Fatalf("ParseGridTracks: got %d tracks, not %d", len(gts), len(strs))
+	}
+	for i, gt := range gts {
+		if gt.String() != strs[i] {
+			t.Errorf("ParseGridTracks: track %d: %v != %v", i, gt.String(), strs[i])
+		}
+	}
+	if _, err := ParseGridTracks("minmax(1fr, 2fr)"); err == nil {
+		t.Errorf("ParseGridTracks: expected error for fraction min")
+	}
+	areas, err := ParseGridAreas(`"head head" "side main" "side ."`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ar := GridAreaRect(areas, "side"); ar != image.Rect(0, 1, 1, 3) {
+		t.Errorf("GridAreaRect side: %v", ar)
+	}
+	if _, err := ParseGridAreas(`"a b" "b a"`); err == nil {
+		t.Errorf("ParseGridAreas: expected error for non-rectangular area")
+	}
+}
+
+func TestSideProps(t *testing.T) {
+	props := ki.Props{
+		"margin":                 "1px 2px 3px",
+		"margin-top":             "5px",
+		"padding":                "4px 8px",
+		"border-width":           "1px",
+		"border-bottom-width":    "3px",
+		"border-color":           "red blue",
+		"border-style":           "solid none",
+		"border-radius":          "2px 4px",
+		"border-top-left-radius": "6px",
+		"border-left-color":      "green",
+	}
+	var s Style
+	s.Defaults()
+	s.SetStyleProps(nil, props, nil)
+	mg := &s.Layout.Margin
+	if mg.Top.Val != 5 || mg.Right.Val != 2 || mg.Bottom.Val != 3 || mg.Left.Val != 2 {
+		t.Errorf("margin: %v", mg.String())
+	}
+	pd := &s.Layout.Padding
+	if pd.Top.Val != 4 || pd.Right.Val != 8 || pd.Bottom.Val != 4 || pd.Left.Val != 8 || pd.String() != "4px 8px" {
+		t.Errorf("padding: %v", pd.String())
+	}
+	bs := &s.Border
+	if bs.Width.Top.Val != 1 || bs.Width.Bottom.Val != 3 || bs.Width.String() != "1px 1px 3px" {
+		t.Errorf("border-width: %v", bs.Width.String())
+	}
+	if bs.Style.Top != BorderSolid || bs.Style.Right != BorderNone || bs.Style.Left != BorderNone {
+		t.Errorf("border-style: %+v", bs.Style)
+	}
+	if bs.Color.Top != (Color{255, 0, 0, 255}) || bs.Color.Right != (Color{0, 0, 255, 255}) || bs.Color.Left.G == 0 {
+		t.Errorf("border-color: %+v", bs.Color)
+	}
+	rd := &bs.Radius
+	if rd.Top.Val != 6 || rd.Right.Val != 4 || rd.Bottom.Val != 2 || rd.Left.Val != 4 {
+		t.Errorf("border-radius: %v", rd.String())
+	}
+
+	s.ToDots()
+	spc := s.BoxSpace() // no space for the sides with border-style none
+	if spc != (SideFloats{Top: 5 + 1 + 4, Right: 2 + 0 + 8, Bottom: 3 + 3 + 4, Left: 2 + 0 + 8}) {
+		t.Errorf("box space: %+v", spc)
+	}
+	if wd := bs.WidthDots(); wd.Right != 0 || wd.Bottom != 3 {
+		t.Errorf("border width dots: %+v", wd)
+	}
+	s.SetStyleProps(nil, ki.Props{"border-style": "hidden"}, nil)
+	s.ToDots()
+	if spc := s.BoxSpace(); spc != (SideFloats{Top: 5 + 4, Right: 2 + 8, Bottom: 3 + 4, Left: 2 + 8}) {
+		t.Errorf("box space with hidden border: %+v", spc)
+	}
+}
+
+func TestStyleVars(t *testing.T) {
+	var par Style
+	par.Defaults()
+	par.SetStyleProps(nil, ki.Props{
+		"--gap":    "2em",
+		"--accent": "red",
+		"--pad":    "var(--gap)",
+	}, nil)
+	var s Style
+	s.Defaults()
+	s.SetStyleProps(&par, ki.Props{
+		"--gap":         "10px",
+		"width":         "calc(100% - var(--gap))",
+		"padding":       "var(--pad)",
+		"margin":        "var(--none, 3px)",
+		"border-color":  "var(--accent)",
+		"border-radius": "var(--none)",
+	}, nil)
+	if _, has := par.Var("--gap"); !has || len(par.Vars) != 3 {
+		t.Errorf("parent vars: %v", par.Vars)
+	}
+	if gap, _ := s.Var("--gap"); gap != "10px" {
+		t.Errorf("child --gap: %v", gap)
+	}
+	if pd := s.Layout.Padding.Top; pd.Val != 2 || pd.Un != units.Em {
+		t.Errorf("padding: %v", s.Layout.Padding.String())
+	}
+	if s.Layout.Margin.Left.Val != 3 {
+		t.Errorf("margin fallback: %v", s.Layout.Margin.String())
+	}
+	if s.Border.Color.Top != (Color{255, 0, 0, 255}) {
+		t.Errorf("border-color: %v", s.Border.Color.Top)
+	}
+	if !s.Border.Radius.Dots().IsZero() {
+		t.Errorf("border-radius should not be set: %v", s.Border.Radius.String())
+	}
+	if s.Layout.Width.String() != "calc(100pct - 10px)" {
+		t.Errorf("width: %v", s.Layout.Width.String())
+	}
+	var uc units.Context
+	uc.Defaults()
+	uc.ElW = 200
+	if wd := s.Layout.Width.ToDots(&uc); wd != 190 {
+		t.Errorf("width dots: %v", wd)
+	}
+}
+
+func TestBoxShadows(t *testing.T) {
+	var s Style
+	s.Defaults()
+	s.SetStyleProps(nil, ki.Props{
+		"box-shadow": "0 1px 2px rgba(0, 0, 0, 64), inset 2px 2px 4px 1px blue, 3px 3px",
+	}, nil)
+	var uc units.Context
+	uc.Defaults()
+	s.ToDotsImpl(&uc)
+	shs := s.BoxShadows()
+	if len(shs) != 3 {
+		t.Fatalf("box shadows: %+v", shs)
+	}
+	if sh := shs[0]; sh.Inset || sh.VOffset.Dots != 1 || sh.Blur.Dots != 2 || sh.Color.A != 64 {
+		t.Errorf("first shadow: %v", sh.String())
+	}
+	if sh := shs[1]; !sh.Inset || sh.Spread.Dots != 1 || sh.Color != (Color{0, 0, 255, 255}) {
+		t.Errorf("inset shadow: %v", sh.String())
+	}
+	if sh := shs[2]; sh.HOffset.Dots != 3 || sh.Color != Black {
+		t.Errorf("default color shadow: %v", sh.String())
+	}
+	s.SetStyleProps(nil, ki.Props{"box-shadow": "none"}, nil)
+	if shs := s.BoxShadows(); len(shs) != 0 {
+		t.Errorf("box-shadow none: %+v", shs)
+	}
+	if _, err := ParseBoxShadows("2px red", nil); err == nil {
+		t.Errorf("box-shadow with one offset should be an error")
+	}
+}
+
+func TestTextOverflowProps(t *testing.T) {
+	var s Style
+	s.Defaults()
+	s.SetStyleProps(nil, ki.Props{
+		"text-overflow":      "ellipsis-middle",
+		"-webkit-line-clamp": 3,
+		"shrink-to-fit":      0.75,
+	}, nil)
+	ts := &s.Text
+	if ts.Overflow != TextOverflowEllipsisMiddle || ts.MaxLines != 3 || ts.ShrinkToFit != 0.75 {
+		t.Errorf("text overflow props: %v %v %v", ts.Overflow, ts.MaxLines, ts.ShrinkToFit)
+	}
+	s.SetStyleProps(nil, ki.Props{"text-overflow": "ellipsis", "line-clamp": "none"}, nil)
+	if ts.Overflow != TextOverflowEllipsis || ts.MaxLines != 0 || !ts.HasTextOverflow() {
+		t.Errorf("text overflow props: %v %v", ts.Overflow, ts.MaxLines)
+	}
+}
+
+func TestParaProps(t *testing.T) {
+	props := ki.Props{
+		"text-align":          "justify",
+		"hyphens":             "auto",
+		"lang":                "de",
+		"hanging-punctuation": "first allow-end",
+		"text-wrap-style":     "pretty",
+	}
+	var s Style
+	s.Defaults()
+	s.SetStyleProps(nil, props, nil)
+	ts := &s.Text
+	if ts.Align != AlignJustify || ts.Hyphens != HyphensAuto || ts.Lang != "de" || ts.WrapStyle != WrapStylePretty {
+		t.Errorf("paragraph props: %v %v %v %v", ts.Align, ts.Hyphens, ts.Lang, ts.WrapStyle)
+	}
+	if !ts.HasHangingPunct(HangFirst) || !ts.HasHangingPunct(HangAllowEnd) || ts.HasHangingPunct(HangLast) {
+		t.Errorf("hanging-punctuation: %v", ts.HangingPunct)
+	}
+}
@@ -6,6 +6,7 @@ package gist
 
 import (
 	"github.com/goki/gi/units"
+	"github.com/goki/ki/bitflag"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 )
@@ -24,17 +25,23 @@ type Text struct {
 	LineHeight       float32        `xml:"line-height" inherit:"true" desc:"prop: line-height (inherited) = specified height of a line of text, in proportion to default font height, 0 = 1 = normal (todo: specific values such as pixels are not supported, in order to properly support percentage) -- text is centered within the overall lineheight"`
 	WhiteSpace       WhiteSpaces    `xml:"white-space" desc:"prop: white-space (*not* inherited) = specifies how white space is processed, and how lines are wrapped"`
 	UnicodeBidi      UnicodeBidi    `xml:"unicode-bidi" inherit:"true" desc:"prop: unicode-bidi (inherited) = determines how to treat unicode bidirectional information"`
-	Direction        TextDirections `xml:"direction" inherit:"true" desc:"prop: direction (inherited) = direction of text -- only applicable for unicode-bidi = bidi-override or embed -- applies to all text elements"`
+	Direction        TextDirections `xml:"direction" inherit:"true" desc:"prop: direction (inherited) = direction of text -- the base direction of each paragraph for the unicode bidi algorithm (rtl for Arabic, Hebrew etc), and the direction of all of the text for unicode-bidi = bidi-override -- applies to all text elements"`
 	WritingMode      TextDirections `xml:"writing-mode" inherit:"true" desc:"prop: writing-mode (inherited) = overall writing mode -- only for text elements, not tspan"`
 	OrientationVert  float32        `xml:"glyph-orientation-vertical" inherit:"true" desc:"prop: glyph-orientation-vertical (inherited) = for TBRL writing mode (only), determines orientation of alphabetic characters -- 90 is default (rotated) -- 0 means keep upright"`
 	OrientationHoriz float32        `xml:"glyph-orientation-horizontal" inherit:"true" desc:"prop: glyph-orientation-horizontal (inherited) = for horizontal LR/RL writing mode (only), determines orientation of all characters -- 0 is default (upright)"`
 	Indent           units.Value    `xml:"text-indent" inherit:"true" desc:"prop: text-indent (inherited) = how much to indent the first line in a paragraph"`
 	ParaSpacing      units.Value    `xml:"para-spacing" inherit:"true" desc:"prop: para-spacing (inherited) = extra spacing between paragraphs -- copied from Style.Layout.Margin per CSS spec if that is non-zero, else can be set directly with para-spacing"`
 	TabSize          int            `xml:"tab-size" inherit:"true" desc:"prop: tab-size (inherited) = tab size, in number of characters"`
+	Overflow         TextOverflows  `xml:"text-overflow" desc:"prop: text-overflow (*not* inherited) = how text that does not fit within the available width is shown: clipped, or elided with an ellipsis at the end, start or middle -- the full text can then be shown in a tooltip"`
+	MaxLines         int            `xml:"max-lines" desc:"prop: max-lines, line-clamp, -webkit-line-clamp (*not* inherited) = maximum number of lines of text to show -- any further lines are dropped, and the last line shown ends with an ellipsis -- 0 = no limit"`
+	ShrinkToFit      float32        `xml:"shrink-to-fit" desc:"prop: shrink-to-fit (*not* inherited) = if > 0, text that does not fit within the available width (and is not word-wrapped) is rendered with a smaller font, scaled down by at most this factor (e.g., .75) -- any remaining overflow is then handled per text-overflow -- 0 = off"`
+	Hyphens          Hyphens        `xml:"hyphens" inherit:"true" desc:"prop: hyphens (inherited) = how words are hyphenated when wrapping lines: manual = only at soft hyphens (&shy;) in the text, auto = also at the hyphenation points given by the patterns for the Lang language"`
+	Lang             string         `xml:"lang" inherit:"true" desc:"prop: lang (inherited) = language of the text, e.g., en-us -- used to select the hyphenation patterns for hyphens = auto -- empty = default language"`
+	HangingPunct     HangingPuncts  `xml:"hanging-punctuation" inherit:"true" desc:"prop: hanging-punctuation (inherited) = punctuation that hangs outside the line box: first = opening brackets and quotes at the start of a paragraph, last = closing brackets and quotes at the end of a paragraph, allow-end / force-end = stops and commas at the end of each line"`
+	WrapStyle        TextWrapStyles `xml:"text-wrap-style" inherit:"true" desc:"prop: text-wrap-style (inherited) = how lines are broken when wrapping: auto = fill each line in turn, pretty = choose the breaks for the whole paragraph together for even line lengths (Knuth-Plass optimal line breaking)"`
 	// todo:
 	// page-break options
 	// text-justify  inherit:"true" -- how to justify text
-	// text-overflow -- clip, ellipsis, string..
 	// text-shadow  inherit:"true"
 	// text-transform --  inherit:"true" uppercase, lowercase, capitalize
 	// user-select -- can user select text?
@@ -78,6 +85,10 @@ func (ts *Text) InheritFields(par *Text) {
 	ts.Indent = par.Indent
 	ts.ParaSpacing = par.ParaSpacing
 	ts.TabSize = par.TabSize
+	ts.Hyphens = par.Hyphens
+	ts.Lang = par.Lang
+	ts.HangingPunct = par.HangingPunct
+	ts.WrapStyle = par.WrapStyle
 }
 
 // EffLineHeight returns the effective line height (taking into account 0 value)
@@ -210,6 +221,134 @@ var KiT_WhiteSpaces = kit.Enums.AddEnumAltLower(WhiteSpacesN, kit.NotBitFlag, St
 func (ev WhiteSpaces) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
 func (ev *WhiteSpaces) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
 
+// TextOverflows determine how text that does not fit within the available
+// width is shown
+type TextOverflows int32
+
+const (
+	// TextOverflowClip means that overflowing text is simply clipped at the
+	// edge of the available space
+	TextOverflowClip TextOverflows = iota
+
+	// TextOverflowEllipsis means that the end of overflowing text is
+	// replaced with an ellipsis
+	TextOverflowEllipsis
+
+	// TextOverflowEllipsisStart means that the start of overflowing text is
+	// replaced with an ellipsis, keeping the end visible
+	TextOverflowEllipsisStart
+
+	// TextOverflowEllipsisMiddle means that the middle of overflowing text
+	// is replaced with an ellipsis, keeping both the start and the end
+	// visible, e.g., for file names and paths
+	TextOverflowEllipsisMiddle
+
+	TextOverflowsN
+)
+
+//go:generate stringer -type=TextOverflows
+
+var KiT_TextOverflows = kit.Enums.AddEnumAltLower(TextOverflowsN, kit.NotBitFlag, StylePropProps, "TextOverflow")
+
+func (ev TextOverflows) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TextOverflows) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// Hyphens determine how words are hyphenated when wrapping lines
+type Hyphens int32
+
+const (
+	// HyphensManual means that words are only hyphenated at soft hyphens
+	// (U+00AD, &shy;) in the text
+	HyphensManual Hyphens = iota
+
+	// HyphensNone means that words are never hyphenated, even at soft hyphens
+	HyphensNone
+
+	// HyphensAuto means that words are hyphenated at soft hyphens, and at
+	// the hyphenation points given by the patterns for the language
+	HyphensAuto
+
+	HyphensN
+)
+
+//go:generate stringer -type=Hyphens
+
+var KiT_Hyphens = kit.Enums.AddEnumAltLower(HyphensN, kit.NotBitFlag, StylePropProps, "Hyphens")
+
+func (ev Hyphens) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *Hyphens) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// HangingPuncts are bit flags for the punctuation that hangs outside the
+// line box, as in the CSS hanging-punctuation property
+type HangingPuncts int32
+
+const (
+	// HangFirst hangs an opening bracket or quote at the start of the first
+	// line of a paragraph outside the start edge
+	HangFirst HangingPuncts = iota
+
+	// HangLast hangs a closing bracket or quote at the end of the last line
+	// of a paragraph outside the end edge
+	HangLast
+
+	// HangAllowEnd hangs a stop or comma at the end of a line outside the
+	// end edge, if it does not otherwise fit
+	HangAllowEnd
+
+	// HangForceEnd always hangs a stop or comma at the end of a line outside
+	// the end edge
+	HangForceEnd
+
+	HangingPunctsN
+)
+
+//go:generate stringer -type=HangingPuncts
+
+var KiT_HangingPuncts = kit.Enums.AddEnumAltLower(HangingPunctsN, kit.BitFlag, StylePropProps, "Hang")
+
+func (ev HangingPuncts) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *HangingPuncts) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// TextWrapStyles determine how lines are broken when wrapping text
+type TextWrapStyles int32
+
+const (
+	// WrapStyleAuto fills each line in turn with as many words as fit
+	WrapStyleAuto TextWrapStyles = iota
+
+	// WrapStylePretty chooses the line breaks for the whole paragraph
+	// together, to minimize the variation in line lengths (or in the word
+	// spacing for justified text), using the Knuth-Plass algorithm
+	WrapStylePretty
+
+	TextWrapStylesN
+)
+
+//go:generate stringer -type=TextWrapStyles
+
+var KiT_TextWrapStyles = kit.Enums.AddEnumAltLower(TextWrapStylesN, kit.NotBitFlag, StylePropProps, "WrapStyle")
+
+func (ev TextWrapStyles) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TextWrapStyles) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// HasHangingPunct returns true if given hanging punctuation flag is set
+func (ts *Text) HasHangingPunct(flag HangingPuncts) bool {
+	return bitflag.Has32(int32(ts.HangingPunct), int(flag))
+}
+
+// HasTextOverflow returns true if text that does not fit within the
+// available width is elided with an ellipsis or shrunk to fit, instead of
+// just being clipped -- the width needed can then be less than the full
+// width of the text
+func (ts *Text) HasTextOverflow() bool {
+	return ts.Overflow != TextOverflowClip || ts.ShrinkToFit > 0
+}
+
+// IsRTL returns true if the base Direction of the text is right-to-left
+func (ts *Text) IsRTL() bool {
+	return ts.Direction == RTL || ts.Direction == RLTB || ts.Direction == RL
+}
+
 // HasWordWrap returns true if current white space option supports word wrap
 func (ts *Text) HasWordWrap() bool {
 	switch ts.WhiteSpace {
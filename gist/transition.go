@@ -0,0 +1,154 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transition specifies an animated transition of a style property when it
+// changes, e.g., when a button changes state on hover, as in the CSS
+// transition property: "background-color 150ms ease-out"
+// https://developer.mozilla.org/en-US/docs/Web/CSS/transition
+// The transition is run by the widget, using the gi.Window animations.
+type Transition struct {
+	Prop     string        `desc:"name of the style property to animate, or all for all properties that can be animated"`
+	Duration time.Duration `desc:"duration of the transition"`
+	Delay    time.Duration `desc:"delay before the transition starts"`
+	Ease     string        `desc:"easing (timing) function: linear, ease, ease-in, ease-out, ease-in-out, or cubic-bezier(x1, y1, x2, y2)"`
+}
+
+// TransitionEases are the names of the standard easing functions
+// that can be used in a Transition, in addition to cubic-bezier(...)
+var TransitionEases = []string{"linear", "ease", "ease-in", "ease-out", "ease-in-out"}
+
+// IsTransitionEase returns true if given string is a valid easing
+// function for a Transition (see TransitionEases).
+func IsTransitionEase(str string) bool {
+	if strings.HasPrefix(str, "cubic-bezier(") && strings.HasSuffix(str, ")") {
+		return true
+	}
+	for _, e := range TransitionEases {
+		if str == e {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the CSS string representation of the transition
+func (tr *Transition) String() string {
+	str := fmt.Sprintf("%s %s %s", tr.Prop, FormatCSSTime(tr.Duration), tr.Ease)
+	if tr.Delay != 0 {
+		str += " " + FormatCSSTime(tr.Delay)
+	}
+	return str
+}
+
+// ParseTransitions parses a CSS transition property value, which is a comma
+// separated list of transitions, each with a property name, a duration,
+// an optional easing function and an optional delay, in any order, except
+// that the duration comes before the delay.  The property defaults to all
+// and the easing function to ease.  Returns nil for "none".
+func ParseTransitions(str string) ([]Transition, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "none" {
+		return nil, nil
+	}
+	var trs []Transition
+	for _, item := range splitTopLevel(str, ',') {
+		tr := Transition{Prop: "all", Ease: "ease"}
+		ntime := 0
+		for _, fld := range splitTopLevel(item, ' ') {
+			fld = strings.TrimSpace(fld)
+			if fld == "" {
+				continue
+			}
+			if dur, err := ParseCSSTime(fld); err == nil {
+				switch ntime {
+				case 0:
+					tr.Duration = dur
+				case 1:
+					tr.Delay = dur
+				default:
+					return nil, fmt.Errorf("gist.ParseTransitions: too many times in: %q", item)
+				}
+				ntime++
+				continue
+			}
+			if IsTransitionEase(fld) {
+				tr.Ease = fld
+				continue
+			}
+			tr.Prop = strings.ToLower(fld)
+		}
+		if ntime == 0 {
+			return nil, fmt.Errorf("gist.ParseTransitions: no duration in: %q", item)
+		}
+		trs = append(trs, tr)
+	}
+	return trs, nil
+}
+
+// splitTopLevel splits given string at given separator, except within
+// parentheses, e.g., for the arguments of cubic-bezier(...)
+func splitTopLevel(str string, sep rune) []string {
+	var strs []string
+	depth := 0
+	st := 0
+	for i, r := range str {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == sep && depth == 0:
+			strs = append(strs, str[st:i])
+			st = i + 1
+		}
+	}
+	return append(strs, str[st:])
+}
+
+// ParseCSSTime parses a CSS time value in s or ms units, e.g., 150ms or .2s
+func ParseCSSTime(str string) (time.Duration, error) {
+	mult := time.Second
+	num := str
+	switch {
+	case strings.HasSuffix(str, "ms"):
+		mult = time.Millisecond
+		num = strings.TrimSuffix(str, "ms")
+	case strings.HasSuffix(str, "s"):
+		num = strings.TrimSuffix(str, "s")
+	default:
+		return 0, fmt.Errorf("gist.ParseCSSTime: time: %q must have s or ms units", str)
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("gist.ParseCSSTime: time: %q is not a number", str)
+	}
+	return time.Duration(f * float64(mult)), nil
+}
+
+// FormatCSSTime returns the CSS string representation of given
+// duration, in ms units
+func FormatCSSTime(dur time.Duration) string {
+	return strconv.FormatFloat(float64(dur)/float64(time.Millisecond), 'g', -1, 64) + "ms"
+}
+
+// TransitionFor returns the transition that applies to given style
+// property, if any, with the last matching one taking precedence, as in CSS.
+func (s *Style) TransitionFor(prop string) (Transition, bool) {
+	for i := len(s.Transition) - 1; i >= 0; i-- {
+		tr := s.Transition[i]
+		if tr.Prop == prop || tr.Prop == "all" {
+			return tr, true
+		}
+	}
+	return Transition{}, false
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// CSS custom properties (variables): any property whose name starts with --,
+// e.g., "--accent": "#48f", is recorded in Style.Vars, which are inherited
+// by all children in the tree, and can be referenced in the value of any
+// other property using var(--name) or var(--name, fallback), e.g.,
+// "border-color": "var(--accent, black)"
+// https://developer.mozilla.org/en-US/docs/Web/CSS/Using_CSS_custom_properties
+
+// StyleVarMaxDepth is the maximum depth of var() references within the
+// values of custom properties, which also guards against cycles
+var StyleVarMaxDepth = 16
+
+// IsStyleVar returns true if given property key is a custom property (--name)
+func IsStyleVar(key string) bool {
+	return len(key) > 2 && key[0] == '-' && key[1] == '-'
+}
+
+// SetVars sets the custom properties (--name keys) from given props,
+// on top of those inherited from the parent, resolving any var()
+// references in their values.  The Vars map is shared
+// with the parent if there are no new custom properties, and is never
+// modified in place, so it can be shared safely.
+func (s *Style) SetVars(par *Style, props ki.Props) {
+	nv := 0
+	for key := range props {
+		if IsStyleVar(key) {
+			nv++
+		}
+	}
+	if nv == 0 {
+		if s.Vars == nil && par != nil {
+			s.Vars = par.Vars
+		}
+		return
+	}
+	vars := make(map[string]interface{}, len(s.Vars)+nv)
+	if par != nil {
+		for k, v := range par.Vars {
+			vars[k] = v
+		}
+	}
+	for k, v := range s.Vars {
+		vars[k] = v
+	}
+	for key, val := range props {
+		if IsStyleVar(key) {
+			vars[key] = val
+		}
+	}
+	// var() references in new values are resolved here, as in CSS,
+	// so children inherit the values computed at this level
+	rs := Style{Vars: vars}
+	res := make(map[string]interface{}, nv)
+	for key := range props {
+		if IsStyleVar(key) {
+			res[key], _ = rs.ResolveVars(vars[key])
+		}
+	}
+	for key, val := range res {
+		if val == nil {
+			delete(vars, key)
+		} else {
+			vars[key] = val
+		}
+	}
+	s.Vars = vars
+}
+
+// Var returns the value of the custom property of given name (--name)
+func (s *Style) Var(name string) (interface{}, bool) {
+	v, ok := s.Vars[name]
+	return v, ok
+}
+
+// ResolveVars returns given property value with any var(--name, fallback)
+// references replaced by the values of the corresponding custom properties
+// in Vars.  If the value is a single var() reference, the custom property
+// value is returned as is, so it can be any type (e.g., a Color);
+// otherwise the references are replaced in the string.  Returns false if
+// a reference could not be resolved and has no fallback.
+func (s *Style) ResolveVars(val interface{}) (interface{}, bool) {
+	return s.resolveVars(val, 0)
+}
+
+func (s *Style) resolveVars(val interface{}, depth int) (interface{}, bool) {
+	str, ok := val.(string)
+	if !ok || !strings.Contains(str, "var(") {
+		return val, true
+	}
+	if depth >= StyleVarMaxDepth {
+		return nil, false
+	}
+	tstr := strings.TrimSpace(str)
+	if st, ed := varRefIndex(tstr, 0); st == 0 && ed == len(tstr) {
+		return s.resolveVarRef(tstr, depth)
+	}
+	var sb strings.Builder
+	pos := 0
+	for {
+		st, ed := varRefIndex(str, pos)
+		if st < 0 {
+			break
+		}
+		rv, ok := s.resolveVarRef(str[st:ed], depth)
+		if !ok {
+			return nil, false
+		}
+		sb.WriteString(str[pos:st])
+		sb.WriteString(styleVarString(rv))
+		pos = ed
+	}
+	if pos == 0 {
+		return nil, false
+	}
+	sb.WriteString(str[pos:])
+	return sb.String(), true
+}
+
+// resolveVarRef resolves a single var(--name, fallback) reference
+func (s *Style) resolveVarRef(ref string, depth int) (interface{}, bool) {
+	args := strings.TrimSpace(ref[4 : len(ref)-1])
+	name := args
+	fallback := ""
+	hasfb := false
+	if ci := strings.IndexByte(args, ','); ci >= 0 {
+		name = strings.TrimSpace(args[:ci])
+		fallback = strings.TrimSpace(args[ci+1:])
+		hasfb = true
+	}
+	if v, ok := s.Vars[name]; ok {
+		if rv, ok := s.resolveVars(v, depth+1); ok {
+			return rv, true
+		}
+	}
+	if hasfb {
+		return s.resolveVars(fallback, depth+1)
+	}
+	return nil, false
+}
+
+// varRefIndex returns the start and end indexes of the first var(...)
+// reference in given string at or after given position, or -1 if none
+func varRefIndex(str string, pos int) (int, int) {
+	si := strings.Index(str[pos:], "var(")
+	if si < 0 {
+		return -1, -1
+	}
+	st := pos + si
+	depth := 0
+	for i := st + 3; i < len(str); i++ {
+		switch str[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return st, i + 1
+			}
+		}
+	}
+	return -1, -1
+}
+
+// styleVarString returns the string representation of a custom property
+// value, for substitution into another property value
+func styleVarString(val interface{}) string {
+	switch v := val.(type) {
+	case Color:
+		return v.HexString()
+	case *Color:
+		return v.HexString()
+	case units.Value:
+		return v.String()
+	case *units.Value:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return kit.ToString(val)
+}
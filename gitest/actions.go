@@ -0,0 +1,396 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+//   Mouse
+
+// moveTo moves the mouse to given window position
+func (tt *Tester) moveTo(pos image.Point) {
+	me := &mouse.MoveEvent{Event: mouse.Event{Where: pos, Action: mouse.Move}, From: pos, LastTime: time.Now()}
+	tt.Send(me, true)
+}
+
+// ClickPos clicks the given mouse button at given window position,
+// after moving the mouse there.
+func (tt *Tester) ClickPos(pos image.Point, but mouse.Buttons) {
+	tt.moveTo(pos)
+	tt.Send(&mouse.Event{Where: pos, Button: but, Action: mouse.Press}, true)
+	tt.Send(&mouse.Event{Where: pos, Button: but, Action: mouse.Release}, true)
+}
+
+// Click clicks the left mouse button at the center of given widget.
+func (tt *Tester) Click(nd gi.Node2D) error {
+	pos, err := WidgetCenter(nd)
+	if err != nil {
+		return err
+	}
+	tt.ClickPos(pos, mouse.Left)
+	return nil
+}
+
+// RightClick clicks the right mouse button at the center of given widget,
+// which typically brings up a context menu.
+func (tt *Tester) RightClick(nd gi.Node2D) error {
+	pos, err := WidgetCenter(nd)
+	if err != nil {
+		return err
+	}
+	tt.ClickPos(pos, mouse.Right)
+	return nil
+}
+
+// DoubleClick double-clicks the left mouse button at the center of given
+// widget, sending the same sequence of events as the oswin drivers.
+func (tt *Tester) DoubleClick(nd gi.Node2D) error {
+	pos, err := WidgetCenter(nd)
+	if err != nil {
+		return err
+	}
+	tt.ClickPos(pos, mouse.Left)
+	tt.Send(&mouse.Event{Where: pos, Button: mouse.Left, Action: mouse.DoubleClick}, true)
+	tt.Send(&mouse.Event{Where: pos, Button: mouse.Left, Action: mouse.Release}, true)
+	return nil
+}
+
+// ClickAction finds the widget at given path (see Find) and clicks it.
+// Despite the name, it works for any widget, not just gi.Action's.
+func (tt *Tester) ClickAction(path string) error {
+	nd, err := tt.Find(path)
+	if err != nil {
+		return err
+	}
+	return tt.Click(nd)
+}
+
+// ClickText finds the button, action or label with given text
+// (see FindByText) and clicks it.
+func (tt *Tester) ClickText(text string) error {
+	nd, err := tt.FindByText(text)
+	if err != nil {
+		return err
+	}
+	return tt.Click(nd)
+}
+
+// DragPos drags with the left mouse button from one window position to
+// another, in given number of steps (minimum 2), with timing such that the
+// EventMgr starts a drag (and drag-n-drop where supported by the widget).
+func (tt *Tester) DragPos(from, to image.Point, steps int) {
+	if steps < 2 {
+		steps = 2
+	}
+	tt.moveTo(from)
+	tt.Send(&mouse.Event{Where: from, Button: mouse.Left, Action: mouse.Press}, true)
+	// the first drag event is dated back so the drag starts right away,
+	// instead of waiting for gi.DragStartMSec
+	start := time.Now().Add(-time.Duration(gi.DragStartMSec+1) * time.Millisecond)
+	prv := from
+	for i := 0; i <= steps; i++ {
+		pos := from.Add(to.Sub(from).Mul(i).Div(steps))
+		de := &mouse.DragEvent{MoveEvent: mouse.MoveEvent{Event: mouse.Event{Where: pos, Button: mouse.Left, Action: mouse.Drag}, From: prv, LastTime: start}, Start: from}
+		de.Init()
+		if i == 0 {
+			de.GenTime.SetTime(start)
+		}
+		tt.Send(de, false)
+		prv = pos
+	}
+	tt.Send(&mouse.Event{Where: to, Button: mouse.Left, Action: mouse.Release}, true)
+}
+
+// Drag drags with the left mouse button from the center of one widget to
+// the center of another, as in drag-n-drop.
+func (tt *Tester) Drag(from, to gi.Node2D) error {
+	fpos, err := WidgetCenter(from)
+	if err != nil {
+		return err
+	}
+	tpos, err := WidgetCenter(to)
+	if err != nil {
+		return err
+	}
+	tt.DragPos(fpos, tpos, 10)
+	return nil
+}
+
+// Scroll sends a mouse scroll event at the center of given widget,
+// with given delta.
+func (tt *Tester) Scroll(nd gi.Node2D, delta image.Point) error {
+	pos, err := WidgetCenter(nd)
+	if err != nil {
+		return err
+	}
+	tt.moveTo(pos)
+	tt.Send(&mouse.ScrollEvent{Event: mouse.Event{Where: pos, Action: mouse.Scroll}, Delta: delta}, true)
+	return nil
+}
+
+// Hover moves the mouse over the center of given widget, without clicking
+func (tt *Tester) Hover(nd gi.Node2D) error {
+	pos, err := WidgetCenter(nd)
+	if err != nil {
+		return err
+	}
+	tt.moveTo(pos)
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Keyboard
+
+// ChordEvent returns a new key.ChordEvent for given chord, e.g., "a",
+// "Control+A" or "Shift+ReturnEnter" (see key.Chord) -- chords with a
+// code name instead of a single rune set the Code, and Command+ is
+// translated using key.Chord.OSShortcut.
+func ChordEvent(ch key.Chord) (*key.ChordEvent, error) {
+	mods, cs := key.ModsFmString(string(ch.OSShortcut()))
+	ke := &key.ChordEvent{}
+	ke.Modifiers = mods
+	ke.Action = key.Press
+	rs := []rune(cs)
+	if len(rs) == 1 {
+		ke.Rune = rs[0]
+		ke.Code = RuneCode(rs[0])
+		return ke, nil
+	}
+	code, ok := CodeByName(cs)
+	if !ok {
+		return nil, fmt.Errorf("gitest.ChordEvent: chord: %v is not a single rune or key code name", ch)
+	}
+	ke.Code = code
+	return ke, nil
+}
+
+// RuneCode returns the key code for runes that have their own key code
+// for which the chord is the code name: space, tab, return and backspace.
+func RuneCode(r rune) key.Codes {
+	switch r {
+	case ' ':
+		return key.CodeSpacebar
+	case '\t':
+		return key.CodeTab
+	case '\n', '\r':
+		return key.CodeReturnEnter
+	case '\b':
+		return key.CodeDeleteBackspace
+	}
+	return key.CodeUnknown
+}
+
+// CodeByName returns the key code with given name, without the
+// Code prefix, e.g., ReturnEnter.
+func CodeByName(name string) (key.Codes, bool) {
+	cnm := "Code" + name
+	for c := key.CodeUnknown; c <= key.CodeRightMeta; c++ {
+		if c.String() == cnm {
+			return c, true
+		}
+	}
+	if key.CodeCompose.String() == cnm {
+		return key.CodeCompose, true
+	}
+	return key.CodeUnknown, false
+}
+
+// KeyChord sends given key chord (see ChordEvent) to the widget in focus.
+func (tt *Tester) KeyChord(ch key.Chord) error {
+	ke, err := ChordEvent(ch)
+	if err != nil {
+		return err
+	}
+	tt.Send(ke, true)
+	return nil
+}
+
+// Type types given text into the widget in focus, as one key chord event
+// per rune -- newlines and tabs are sent as the ReturnEnter and Tab keys.
+func (tt *Tester) Type(text string) {
+	for _, r := range text {
+		ke := &key.ChordEvent{}
+		ke.Rune = r
+		ke.Code = RuneCode(r)
+		ke.Action = key.Press
+		tt.Send(ke, true)
+	}
+}
+
+// TypeInto clicks on the text field (or other text editing widget) at
+// given path to give it focus, moves to the end of any existing text,
+// and types given text.
+func (tt *Tester) TypeInto(path string, text string) error {
+	nd, err := tt.Find(path)
+	if err != nil {
+		return err
+	}
+	err = tt.Click(nd)
+	if err != nil {
+		return err
+	}
+	if !tt.hasFocus(nd) {
+		return fmt.Errorf("gitest.TypeInto: widget: %v did not get focus on click", nd.Path())
+	}
+	tt.KeyChord("End")
+	tt.Type(text)
+	return nil
+}
+
+// hasFocus returns true if focus is on given node or one of its children
+// (e.g., for a composite widget such as a SpinBox)
+func (tt *Tester) hasFocus(nd gi.Node2D) bool {
+	foc := tt.Focus()
+	if foc == nil {
+		return false
+	}
+	return foc == nd.This() || foc.ParentLevel(nd.This()) >= 0
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Animations
+
+// ManualAnims switches the window animations to a manual clock, so that
+// they only advance by calling AdvanceAnims, for deterministic testing.
+func (tt *Tester) ManualAnims() {
+	tt.Win.SendSyncEvent(func(w *gi.Window) { w.Anims.ManualClock = true })
+}
+
+// AdvanceAnims advances the window animations by given duration, which
+// requires ManualAnims, and waits for the resulting updates to finish.
+func (tt *Tester) AdvanceAnims(d time.Duration) {
+	tt.Win.SendSyncEvent(func(w *gi.Window) { w.Anims.Advance(d) })
+	tt.Wait()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   IME
+
+// IME sends an IME text composition event with given action, text and
+// cursor position within the text (in runes) to the widget in focus.
+func (tt *Tester) IME(act ime.Actions, text string, cursor int) {
+	tt.Send(ime.NewEvent(act, text, cursor), false)
+}
+
+// Compose sends a complete IME composition to the widget in focus:
+// a Start event, a Preedit event for each of the preedit strings, with the
+// cursor at its end, and a Commit event with given commit text, or a
+// Cancel event if commit is empty.
+func (tt *Tester) Compose(commit string, preedits ...string) {
+	tt.IME(ime.Start, "", 0)
+	for _, pe := range preedits {
+		tt.IME(ime.Preedit, pe, len([]rune(pe)))
+	}
+	if commit == "" {
+		tt.IME(ime.Cancel, "", 0)
+		return
+	}
+	tt.IME(ime.Commit, commit, 0)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Menus and Tabs
+
+// SelectMenu clicks the menu button (or action) at given path (see Find),
+// and then clicks the items with given text in the resulting menu popup,
+// and each successive sub-menu.
+func (tt *Tester) SelectMenu(path string, items ...string) error {
+	err := tt.ClickAction(path)
+	if err != nil {
+		return err
+	}
+	return tt.selectMenuItems(path, items)
+}
+
+// SelectContextMenu right-clicks the widget at given path (see Find) to
+// bring up its context menu, and then clicks the items with given text in
+// the menu popup and each successive sub-menu.
+func (tt *Tester) SelectContextMenu(path string, items ...string) error {
+	nd, err := tt.Find(path)
+	if err != nil {
+		return err
+	}
+	err = tt.RightClick(nd)
+	if err != nil {
+		return err
+	}
+	return tt.selectMenuItems(path, items)
+}
+
+func (tt *Tester) selectMenuItems(path string, items []string) error {
+	for _, it := range items {
+		pop, err := tt.waitPopup()
+		if err != nil {
+			return fmt.Errorf("gitest: selecting menu item: %v from: %v: %v", it, path, err)
+		}
+		var ac ki.Ki
+		pop.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+			if ac != nil {
+				return ki.Break
+			}
+			if WidgetText(k) == it {
+				ac = k
+				return ki.Break
+			}
+			return ki.Continue
+		})
+		if ac == nil {
+			return fmt.Errorf("gitest: menu item: %v from: %v not found", it, path)
+		}
+		err = tt.Click(ac.(gi.Node2D))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitPopup waits for a (non-tooltip) popup to be shown, returning it
+func (tt *Tester) waitPopup() (ki.Ki, error) {
+	start := time.Now()
+	for {
+		if cpop := tt.Win.CurPopup(); cpop != nil && !gi.PopupIsTooltip(cpop) {
+			return cpop, nil
+		}
+		if time.Since(start) > tt.Timeout {
+			return nil, fmt.Errorf("no menu popup shown within: %v", tt.Timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+		tt.Wait()
+	}
+}
+
+// SelectTab clicks the tab with given label in the gi.TabView at given
+// path (see Find).
+func (tt *Tester) SelectTab(path string, label string) error {
+	nd, err := tt.Find(path)
+	if err != nil {
+		return err
+	}
+	tvk := nd.Embed(gi.KiT_TabView)
+	if tvk == nil {
+		return fmt.Errorf("gitest.SelectTab: widget: %v is not a gi.TabView", nd.Path())
+	}
+	tv := tvk.(*gi.TabView)
+	idx, err := tv.TabIndexByName(label)
+	if err != nil {
+		return err
+	}
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok {
+		return fmt.Errorf("gitest.SelectTab: tab: %v not found in: %v", label, nd.Path())
+	}
+	return tt.Click(tab)
+}
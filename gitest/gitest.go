@@ -0,0 +1,313 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package gitest provides UI automation for driving the widgets in a GoGi
+Window from Go tests (or any other code), in the style of Selenium:
+widgets are found by Ki path, name, type or text, and then clicked, typed
+into, dragged etc by synthesizing the same mouse and key events that the
+oswin driver generates for a real user, at the widget's WinBBox location.
+After each action, the Tester waits for the window event loop to process
+the events and for any resulting updates to settle, so the state of the
+widgets can then be checked directly.
+
+Tests must run the GUI driver on the main thread, which is done by calling
+gitest.Main from TestMain.  This uses the offscreen driver by default, so
+no display is needed:
+
+	func TestMain(m *testing.M) {
+		gitest.Main(m)
+	}
+
+	func TestApp(t *testing.T) {
+		win := gi.NewMainWindow("test", "Test", 600, 400)
+		... configure window ...
+		win.GoStartEventLoop()
+		tt, err := gitest.New(win)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tt.Close()
+		if err := tt.TypeInto("main-vlay/main-frame/name", "Hello"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tt.ClickAction("main-vlay/main-frame/ok"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+All Tester methods must be called from the test goroutine, and never from
+the window event loop.
+
+For visual regression tests, RenderNode renders any widget or svg.SVG into
+an image at a fixed size and DPI, and AssertGolden compares it against a
+stored golden PNG image in testdata, within a given tolerance, writing a
+diff image if it fails.  Run the tests with -update to regenerate the
+golden images:
+
+	go test -run TestMyWidget -update
+*/
+package gitest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gimain"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver"
+	"github.com/goki/ki/ki"
+)
+
+// DefaultTimeout is the default Tester Timeout
+var DefaultTimeout = 5 * time.Second
+
+// Main runs the tests in m with the GUI driver running on the main thread,
+// and exits with the result -- call this from TestMain.  gi.Init is called
+// first, so widgets can be created and rendered (see RenderNode) without
+// opening a window.  The offscreen
+// driver is used unless the GOGI_OFFSCREEN environment variable is set
+// (e.g., to 0 to watch the tests run in a real window).
+func Main(m *testing.M) {
+	if _, has := os.LookupEnv(driver.OffscreenEnv); !has {
+		os.Setenv(driver.OffscreenEnv, "1")
+	}
+	code := 0
+	gimain.Main(func() {
+		gi.Init()
+		code = m.Run()
+	})
+	os.Exit(code)
+}
+
+// Tester drives the widgets in a Window by synthesizing events --
+// see package docs for usage.
+type Tester struct {
+	Win     *gi.Window    `desc:"the window being tested"`
+	Timeout time.Duration `desc:"maximum time to wait for the window to be shown, for updates to settle, and for widgets to be found"`
+}
+
+// New returns a new Tester for given window, after waiting for the
+// window to be shown, which requires that the event loop has been
+// started (e.g., with GoStartEventLoop).
+func New(win *gi.Window) (*Tester, error) {
+	tt := &Tester{Win: win, Timeout: DefaultTimeout}
+	start := time.Now()
+	for !win.HasFlag(int(gi.WinFlagSentShow)) {
+		if win.IsClosed() {
+			return nil, errors.New("gitest.New: window was closed")
+		}
+		if time.Since(start) > tt.Timeout {
+			return nil, fmt.Errorf("gitest.New: window: %v was not shown within: %v", win.Nm, tt.Timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tt.Wait()
+	return tt, nil
+}
+
+// Close closes the window and waits for it to be closed.
+func (tt *Tester) Close() {
+	if tt.Win.IsClosed() {
+		return
+	}
+	tt.Win.Close()
+	start := time.Now()
+	for !tt.Win.IsClosed() && time.Since(start) < tt.Timeout {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Wait waits until all events sent so far have been processed, and
+// any resulting window and viewport updates have finished.
+func (tt *Tester) Wait() {
+	tt.Win.SendSyncEvent(nil)
+	start := time.Now()
+	for tt.updating() && time.Since(start) < tt.Timeout {
+		time.Sleep(10 * time.Millisecond)
+		tt.Win.SendSyncEvent(nil)
+	}
+}
+
+// updating returns true if the window or its viewports are updating
+func (tt *Tester) updating() bool {
+	if tt.Win.IsWinUpdating() || tt.Win.IsClosed() {
+		return !tt.Win.IsClosed()
+	}
+	if tt.Win.Viewport.IsUpdating() {
+		return true
+	}
+	if cpop := tt.Win.CurPopup(); cpop != nil && cpop.IsUpdating() {
+		return true
+	}
+	return false
+}
+
+// Send sends the given event to the window, and waits for it to be processed.
+// The event is initialized to the current time unless Init is false.
+func (tt *Tester) Send(evi oswin.Event, init bool) {
+	if init {
+		evi.Init()
+	}
+	tt.Win.OSWin.Send(evi)
+	tt.Wait()
+}
+
+// Focus returns the widget currently in focus, or nil if none.
+func (tt *Tester) Focus() ki.Ki {
+	return tt.Win.EventMgr.CurFocus()
+}
+
+// State returns a string summarizing the state of the widget at given
+// path (see Find), as in an event recording checkpoint:
+// see gi.EventRecWidgetState.
+func (tt *Tester) State(path string) (string, error) {
+	nd, err := tt.Find(path)
+	if err != nil {
+		return "", err
+	}
+	return gi.EventRecWidgetState(nd), nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Finding widgets
+
+// roots returns the roots of the trees to search for widgets, with the
+// current popup (if any) first, and then the window Viewport.
+func (tt *Tester) roots() []ki.Ki {
+	var rts []ki.Ki
+	if cpop := tt.Win.CurPopup(); cpop != nil && !gi.PopupIsTooltip(cpop) {
+		rts = append(rts, cpop)
+	}
+	return append(rts, tt.Win.Viewport.This())
+}
+
+// findRetry calls fun repeatedly until it returns a non-nil result,
+// or Timeout, and returns an error with given description in that case.
+func (tt *Tester) findRetry(desc string, fun func() ki.Ki) (gi.Node2D, error) {
+	start := time.Now()
+	for {
+		if k := fun(); k != nil {
+			if nd, ok := k.(gi.Node2D); ok {
+				return nd, nil
+			}
+			return nil, fmt.Errorf("gitest: %v is not a gi.Node2D: %v", desc, k.Path())
+		}
+		if time.Since(start) > tt.Timeout {
+			return nil, fmt.Errorf("gitest: %v not found in window: %v", desc, tt.Win.Nm)
+		}
+		time.Sleep(10 * time.Millisecond)
+		tt.Wait()
+	}
+}
+
+// findFunc returns the first node for which fun returns true, searching
+// the current popup and then the window Viewport, depth first.
+func (tt *Tester) findFunc(fun func(k ki.Ki) bool) ki.Ki {
+	for _, rt := range tt.roots() {
+		var fk ki.Ki
+		rt.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+			if fk != nil {
+				return ki.Break
+			}
+			if fun(k) {
+				fk = k
+				return ki.Break
+			}
+			return ki.Continue
+		})
+		if fk != nil {
+			return fk
+		}
+	}
+	return nil
+}
+
+// Find finds the widget at given Ki path, waiting up to Timeout for it to
+// appear.  The path can be relative to the current popup or the window
+// Viewport (e.g., "main-vlay/main-frame/ok"), or an absolute path
+// starting with / as returned by the Path method.
+func (tt *Tester) Find(path string) (gi.Node2D, error) {
+	return tt.findRetry("path: "+path, func() ki.Ki {
+		if strings.HasPrefix(path, "/") {
+			if cpop := tt.Win.CurPopup(); cpop != nil {
+				if k := cpop.FindPath(path); k != nil && k.Path() == path {
+					return k
+				}
+			}
+			return tt.Win.FindPath(path)
+		}
+		for _, rt := range tt.roots() {
+			if k := rt.FindPath(path); k != nil {
+				return k
+			}
+		}
+		return nil
+	})
+}
+
+// FindByName finds the first widget with given name, searching the
+// current popup and then the window Viewport, waiting up to Timeout
+// for it to appear.
+func (tt *Tester) FindByName(name string) (gi.Node2D, error) {
+	return tt.findRetry("name: "+name, func() ki.Ki {
+		return tt.findFunc(func(k ki.Ki) bool {
+			return k.Name() == name
+		})
+	})
+}
+
+// FindByType finds the first widget of given type, or that embeds
+// given type, searching the current popup and then the window Viewport,
+// waiting up to Timeout for it to appear.
+func (tt *Tester) FindByType(typ reflect.Type) (gi.Node2D, error) {
+	return tt.findRetry("type: "+typ.String(), func() ki.Ki {
+		return tt.findFunc(func(k ki.Ki) bool {
+			return ki.TypeEmbeds(k, typ)
+		})
+	})
+}
+
+// FindByText finds the first button (including actions and menu items)
+// or label with given text, searching the current popup and then the
+// window Viewport, waiting up to Timeout for it to appear.
+func (tt *Tester) FindByText(text string) (gi.Node2D, error) {
+	return tt.findRetry("text: "+text, func() ki.Ki {
+		return tt.findFunc(func(k ki.Ki) bool {
+			return WidgetText(k) == text
+		})
+	})
+}
+
+// WidgetText returns the text of a button or label widget, or ""
+// for other widgets.
+func WidgetText(k ki.Ki) string {
+	switch w := k.(type) {
+	case gi.ButtonWidget:
+		return w.AsButtonBase().Text
+	case *gi.Label:
+		return w.Text
+	}
+	return ""
+}
+
+// WidgetCenter returns the center of given widget's WinBBox, in
+// window coordinates, returning an error if it is not visible.
+func WidgetCenter(nd gi.Node2D) (image.Point, error) {
+	nb := nd.AsNode2D()
+	nb.BBoxMu.RLock()
+	wb := nb.WinBBox
+	nb.BBoxMu.RUnlock()
+	if wb.Empty() || nb.IsInvisible() {
+		return image.Point{}, fmt.Errorf("gitest: widget: %v is not visible", nd.Path())
+	}
+	return image.Point{(wb.Min.X + wb.Max.X) / 2, (wb.Min.Y + wb.Max.Y) / 2}, nil
+}
@@ -0,0 +1,1206 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/lex"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestWidgets(t *testing.T) {
+	win := gi.NewMainWindow("gitest", "GiTest", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+
+	tf := gi.AddNewTextField(mfr, "name")
+	tf.SetStretchMaxWidth()
+	clicks := 0
+	but := gi.AddNewButton(mfr, "ok")
+	but.SetText("OK")
+	but.ButtonSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			clicks++
+		}
+	})
+	mb := gi.AddNewMenuButton(mfr, "menu")
+	mb.SetText("Menu")
+	picked := ""
+	mb.Menu.AddAction(gi.ActOpts{Label: "First"}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		picked = "First"
+	})
+	mb.Menu.AddAction(gi.ActOpts{Label: "Second"}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		picked = "Second"
+	})
+	tv := gi.AddNewTabView(mfr, "tabs")
+	tv.AddNewTab(gi.KiT_Label, "Tab 1").(*gi.Label).SetText("one")
+	tv.AddNewTab(gi.KiT_Label, "Tab 2").(*gi.Label).SetText("two")
+	tv.SelectTabIndex(0)
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	if err := tt.TypeInto("main-vlay/main-frame/name", "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := tt.State("main-vlay/main-frame/name"); st != `focus text: "Hello"` {
+		t.Errorf("text field state: %v", st)
+	}
+	if err := tt.ClickAction("main-vlay/main-frame/ok"); err != nil {
+		t.Fatal(err)
+	}
+	if clicks != 1 {
+		t.Errorf("button clicks: %d != 1", clicks)
+	}
+	if tf.Txt != "Hello" {
+		t.Errorf("text field text after losing focus: %q", tf.Txt)
+	}
+	if err := tt.SelectMenu("main-vlay/main-frame/menu", "Second"); err != nil {
+		t.Fatal(err)
+	}
+	if picked != "Second" {
+		t.Errorf("menu item picked: %q != Second", picked)
+	}
+	if err := tt.SelectTab("main-vlay/main-frame/tabs", "Tab 2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, idx, _ := tv.CurTab(); idx != 1 {
+		t.Errorf("current tab: %d != 1", idx)
+	}
+	if _, err := tt.FindByText("two"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSyncEvent(t *testing.T) {
+	win := gi.NewMainWindow("gitest-sync", "GiTest Sync", 300, 200)
+	win.SetMainFrame()
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nested := false
+	win.SendSyncEvent(func(w *gi.Window) {
+		w.SendSyncEvent(func(w *gi.Window) { nested = true })
+	})
+	if !nested {
+		t.Error("nested SendSyncEvent from the event loop was not run")
+	}
+
+	win.StopEventLoop()
+	done := make(chan struct{})
+	go func() {
+		win.SendSyncEvent(nil) // stops the loop before being processed
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(tt.Timeout):
+		t.Error("SendSyncEvent did not return after the event loop stopped")
+	}
+	win.Close() // no event loop to wait on with tt.Close
+}
+
+// recWindow returns a new window for TestEventRec, with a text field and
+// a button whose clicks are counted in clicks.
+func recWindow(clicks *int) (*gi.Window, *gi.TextField) {
+	win := gi.NewMainWindow("gitest-rec", "GiTest EventRec", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	tf := gi.AddNewTextField(mfr, "name")
+	tf.SetStretchMaxWidth()
+	but := gi.AddNewButton(mfr, "ok")
+	but.SetText("OK")
+	but.ButtonSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			*clicks++
+		}
+	})
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return win, tf
+}
+
+func TestEventRec(t *testing.T) {
+	recClicks := 0
+	win, tf := recWindow(&recClicks)
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := win.StartEventRecording(true, "main-vlay/main-frame/name", "main-vlay/main-frame/ok")
+	if err := tt.TypeInto("main-vlay/main-frame/name", "Hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tt.ClickAction("main-vlay/main-frame/ok"); err != nil {
+		t.Fatal(err)
+	}
+	tt.Win.SendSyncEvent(func(w *gi.Window) { rec.Checkpoint(w, "end") })
+	recd := win.StopEventRecording()
+	recText := tf.Txt
+	tt.Close()
+
+	nmouse, nkey, nck := 0, 0, 0
+	for _, re := range recd.Events {
+		switch {
+		case re.Mouse != nil:
+			nmouse++
+		case re.Key != nil:
+			nkey++
+		case re.Check != nil:
+			nck++
+		}
+	}
+	if nmouse == 0 || nkey != 3 || nck == 0 {
+		t.Fatalf("recorded mouse events: %d, key events: %d (!= 3), checkpoints: %d", nmouse, nkey, nck)
+	}
+	if recText != "Hi" || recClicks != 1 {
+		t.Errorf("recorded text: %q clicks: %d", recText, recClicks)
+	}
+	if ck := recd.Events[len(recd.Events)-1].Check; ck == nil || ck.Widgets["main-vlay/main-frame/name"] != `focus text: "Hi"` {
+		t.Errorf("end checkpoint: %+v", ck)
+	}
+
+	fn := filepath.Join(t.TempDir(), "rec.json")
+	if err := recd.SaveJSON(fn); err != nil {
+		t.Fatal(err)
+	}
+	var opnd gi.EventRecording
+	if err := opnd.OpenJSON(fn); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opnd.Events, recd.Events) || !opnd.Created.Equal(recd.Created) || opnd.Size != recd.Size {
+		t.Errorf("recording changed after SaveJSON / OpenJSON")
+	}
+
+	playClicks := 0
+	win, tf = recWindow(&playClicks)
+	tt, err = New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+	pl := gi.NewEventPlayer(&opnd, false, true)
+	if err := pl.Play(win); err != nil {
+		t.Error(err)
+	}
+	if len(pl.Mismatches) != 0 {
+		t.Errorf("checkpoint mismatches: %v", pl.Mismatches)
+	}
+	tt.Wait()
+	if tf.Txt != recText || playClicks != recClicks {
+		t.Errorf("replayed text: %q clicks: %d, recorded: %q %d", tf.Txt, playClicks, recText, recClicks)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	sv := &svg.SVG{}
+	sv.InitName(sv, "golden")
+	sv.Fill = true
+	sv.ViewBox.Size.Set(64, 48)
+	rect := svg.AddNewRect(sv, "rect", 8, 8, 32, 24)
+	rect.SetProp("fill", "#4080ff")
+	rect.SetProp("stroke", "black")
+	circ := svg.AddNewCircle(sv, "circ", 44, 28, 12)
+	circ.SetProp("fill", "orange")
+	circ.SetProp("stroke", "none")
+
+	img, err := RenderNode(sv, image.Point{64, 48}, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, "svg-shapes", img, nil)
+
+	exp := image.NewRGBA(img.Bounds())
+	draw.Draw(exp, exp.Bounds(), img, image.Point{}, draw.Src)
+	exp.Set(0, 0, color.RGBA{1, 2, 3, 255})
+	if n, _ := DiffImages(img, exp, 0); n != 1 {
+		t.Errorf("DiffImages: %d pixels differ, not 1", n)
+	}
+	if n, _ := DiffImages(img, exp, 255); n != 0 {
+		t.Errorf("DiffImages with max tolerance: %d pixels differ, not 0", n)
+	}
+}
+
+func TestIME(t *testing.T) {
+	win := gi.NewMainWindow("gitest-ime", "GiTest IME", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	tf := gi.AddNewTextField(mfr, "name")
+	tf.SetStretchMaxWidth()
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	if err := tt.TypeInto("main-vlay/main-frame/name", "ab"); err != nil {
+		t.Fatal(err)
+	}
+	st := win.OSWin.IMECaret()
+	tt.IME(ime.Start, "", 0)
+	tt.IME(ime.Preedit, "にほ", 2)
+	if txt := string(tf.EditTxt); txt != "abにほ" {
+		t.Errorf("text with preedit: %q", txt)
+	}
+	cr := win.OSWin.IMECaret()
+	if cr.Empty() || cr.Min.X <= st.Min.X {
+		t.Errorf("caret rect: %v did not move right of: %v", cr, st)
+	}
+	tt.IME(ime.Commit, "日本", 0)
+	if txt := string(tf.EditTxt); txt != "ab日本" {
+		t.Errorf("text after commit: %q", txt)
+	}
+	tt.Compose("", "x")
+	if txt := string(tf.EditTxt); txt != "ab日本" || tf.CursorPos != 4 {
+		t.Errorf("text after cancel: %q cursor: %d", txt, tf.CursorPos)
+	}
+}
+
+func TestTextViewIME(t *testing.T) {
+	win := gi.NewMainWindow("gitest-textview-ime", "GiTest TextView IME", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	buf := giv.NewTextBuf()
+	buf.SetText([]byte("ab\n"))
+	tv := giv.AddNewTextView(mfr, "tv")
+	tv.SetStretchMax()
+	tv.SetBuf(buf)
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	if err := tt.Click(tv); err != nil {
+		t.Fatal(err)
+	}
+	win.SendSyncEvent(func(w *gi.Window) { tv.SetCursorShow(lex.Pos{Ln: 0, Ch: 2}) })
+	tt.Wait()
+	before := gi.GrabRenderFrom(tv)
+	tt.IME(ime.Start, "", 0)
+	st := win.OSWin.IMECaret()
+	tt.IME(ime.Preedit, "にほ", 2)
+	if txt := string(buf.Line(0)); txt != "ab" || string(tv.Preedit.Text) != "にほ" {
+		t.Errorf("preedit inserted into buffer: %q preedit: %q", txt, string(tv.Preedit.Text))
+	}
+	after := gi.GrabRenderFrom(tv)
+	cpos := tv.CharStartPos(lex.Pos{Ln: 0, Ch: 2}).ToPointFloor().Sub(tv.VpBBox.Min)
+	ndiff := 0
+	for y := cpos.Y; y < cpos.Y+int(tv.FontHeight); y++ {
+		for x := cpos.X + 2; x < cpos.X+int(2*tv.FontHeight); x++ {
+			if before.RGBAAt(x, y) != after.RGBAAt(x, y) {
+				ndiff++
+			}
+		}
+	}
+	if ndiff == 0 {
+		t.Errorf("preedit text not displayed at the cursor")
+	}
+	cr := win.OSWin.IMECaret()
+	if cr.Empty() || cr.Min.X <= st.Min.X || cr.Min.Y != st.Min.Y {
+		t.Errorf("caret rect: %v did not move right of: %v", cr, st)
+	}
+	tt.IME(ime.Commit, "日本", 0)
+	if txt := string(buf.Line(0)); txt != "ab日本" || tv.Preedit.IsActive() {
+		t.Errorf("text after commit: %q preedit: %q", txt, string(tv.Preedit.Text))
+	}
+	if tv.CursorPos != (lex.Pos{Ln: 0, Ch: 4}) {
+		t.Errorf("cursor after commit: %v", tv.CursorPos)
+	}
+	if cr := win.OSWin.IMECaret(); cr != tv.IMECaretRect() || cr.Min.X <= st.Min.X {
+		t.Errorf("caret rect after commit: %v, at cursor: %v", cr, tv.IMECaretRect())
+	}
+	tt.Compose("", "x")
+	if txt := string(buf.Line(0)); txt != "ab日本" || tv.CursorPos.Ch != 4 {
+		t.Errorf("text after cancel: %q cursor: %v", txt, tv.CursorPos)
+	}
+}
+
+func TestAnim(t *testing.T) {
+	win := gi.NewMainWindow("gitest-anim", "GiTest Anim", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	but := gi.AddNewButton(mfr, "fade")
+	but.SetText("Fade")
+	but.SetProp("transition", "background-color 150ms linear")
+	but.SetProp(":active", ki.Props{"background-color": "#000000"})
+	but.SetProp(":hover", ki.Props{"background-color": "#ffffff"})
+	lbl := gi.AddNewLabel(mfr, "lbl", "label")
+	shb := gi.AddNewButton(mfr, "shadow")
+	shb.SetText("Shadow")
+	shb.SetProp("transition", "box-shadow 100ms linear")
+	shb.SetProp(":active", ki.Props{"box-shadow": "none"})
+	shb.SetProp(":hover", ki.Props{"box-shadow": "0 4px 8px #000000, 0 0 2px #00000080"})
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+	tt.ManualAnims()
+
+	bgR := func() uint8 { // red of the background, at left-middle of the box
+		img := gi.GrabRenderFrom(but)
+		st := &but.Sty
+		x := int(st.Layout.Margin.Left.Dots+st.Border.Width.Left.Dots) + 2
+		return img.RGBAAt(x, img.Bounds().Dy()/2).R
+	}
+	if err := tt.Hover(but); err != nil {
+		t.Fatal(err)
+	}
+	if but.State != gi.ButtonHover {
+		t.Fatalf("button state: %v != ButtonHover", but.State)
+	}
+	if r := bgR(); r != 0 {
+		t.Errorf("bg at start of transition: %d != 0", r)
+	}
+	tt.AdvanceAnims(75 * time.Millisecond)
+	if r := bgR(); r < 120 || r > 135 {
+		t.Errorf("bg half way through transition: %d", r)
+	}
+	tt.AdvanceAnims(100 * time.Millisecond)
+	if r := bgR(); r != 255 {
+		t.Errorf("bg at end of transition: %d != 255", r)
+	}
+	if win.Anims.IsAnimating() {
+		t.Errorf("transition still running after it should be done")
+	}
+
+	// box-shadow transitions the whole list of shadows
+	if err := tt.Hover(shb); err != nil {
+		t.Fatal(err)
+	}
+	tt.AdvanceAnims(50 * time.Millisecond)
+	shs := shb.TransStyle(&shb.Sty).BoxShadows()
+	if len(shs) != 2 || shs[0].VOffset.Dots <= 0 || shs[0].VOffset.Dots >= shb.Sty.BoxShadow.VOffset.Dots ||
+		shs[0].Color.A < 100 || shs[0].Color.A > 155 || shs[1].Blur.Dots >= shb.Sty.ExtraShadows[0].Blur.Dots {
+		t.Errorf("box shadows half way through transition: %v", shs)
+	}
+	tt.AdvanceAnims(60 * time.Millisecond)
+	if shs := shb.TransStyle(&shb.Sty).BoxShadows(); len(shs) != 2 || shs[0] != shb.Sty.BoxShadow {
+		t.Errorf("box shadows at end of transition: %v", shs)
+	}
+
+	var ts []float32
+	var done []bool
+	an := win.Animate(&gi.Anim{Name: "test", Target: lbl, Duration: 100 * time.Millisecond, Ease: gi.EaseLinear,
+		Update: func(t float32) { ts = append(ts, t) },
+		Done:   func(completed bool) { done = append(done, completed) }})
+	tt.AdvanceAnims(50 * time.Millisecond)
+	tt.AdvanceAnims(50 * time.Millisecond)
+	if len(ts) != 2 || ts[0] != 0.5 || ts[1] != 1 || len(done) != 1 || !done[0] || !an.IsOver() {
+		t.Errorf("anim updates: %v done: %v", ts, done)
+	}
+	done = nil
+	an = win.Animate(&gi.Anim{Name: "test", Target: lbl, Duration: 100 * time.Millisecond,
+		Done: func(completed bool) { done = append(done, completed) }})
+	win.Animate(&gi.Anim{Name: "test", Target: lbl, Duration: 100 * time.Millisecond})
+	if len(done) != 1 || done[0] || !an.IsOver() {
+		t.Errorf("anim not cancelled by another with same name: %v", done)
+	}
+	win.Anims.CancelTarget(lbl)
+	if win.Anims.IsAnimating() {
+		t.Errorf("anims still running after CancelTarget")
+	}
+
+	if ease, err := gi.EaseByName("cubic-bezier(0, 0, 1, 1)"); err != nil || math.Abs(float64(ease(0.3)-0.3)) > 0.001 {
+		t.Errorf("cubic-bezier linear: %v %v", ease(0.3), err)
+	}
+}
+
+func TestOpenAnims(t *testing.T) {
+	win := gi.NewMainWindow("gitest-open-anims", "GiTest Open Anims", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	tf := gi.AddNewTextField(mfr, "name")
+	tf.SetProp("transition", "background-color 100ms linear")
+	tf.SetProp(":active", ki.Props{"background-color": "#000000"})
+	tf.SetProp(":focus", ki.Props{"background-color": "#ffffff"})
+	but := gi.AddNewButton(mfr, "ok")
+	but.SetText("OK")
+	mb := gi.AddNewMenuButton(mfr, "menu")
+	mb.SetText("Menu")
+	mb.Menu.AddAction(gi.ActOpts{Label: "First"}, nil, nil)
+	mb.Menu.AddAction(gi.ActOpts{Label: "Second"}, nil, nil)
+	root := &ki.Node{}
+	root.InitName(root, "root")
+	for _, nm := range []string{"a", "b", "c"} {
+		root.AddNewChild(ki.KiT_Node, nm)
+	}
+	tv := giv.AddNewTreeView(mfr, "tree")
+	tv.SetRootNode(root)
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+	tt.ManualAnims()
+
+	// state style transitions on widgets other than buttons
+	bgR := func() uint8 {
+		img := gi.GrabRenderFrom(tf)
+		st := &tf.Sty
+		x := int(st.Layout.Margin.Left.Dots+st.Border.Width.Left.Dots) + 1
+		return img.RGBAAt(x, img.Bounds().Dy()/2).R
+	}
+	if err := tt.Click(tf); err != nil {
+		t.Fatal(err)
+	}
+	if err := tt.Click(but); err != nil { // focus away from the text field
+		t.Fatal(err)
+	}
+	if r := bgR(); r < 240 {
+		t.Errorf("text field bg at start of transition: %d", r)
+	}
+	tt.AdvanceAnims(50 * time.Millisecond)
+	if r := bgR(); r < 110 || r > 140 {
+		t.Errorf("text field bg half way through transition: %d", r)
+	}
+	tt.AdvanceAnims(60 * time.Millisecond)
+	if r := bgR(); r != 0 {
+		t.Errorf("text field bg at end of transition: %d != 0", r)
+	}
+
+	// tree view expand
+	treeH := func() (h float32) {
+		win.SendSyncEvent(func(w *gi.Window) { h = tv.LayState.Alloc.Size.Y })
+		return
+	}
+	openH := treeH()
+	win.SendSyncEvent(func(w *gi.Window) { tv.Close() })
+	tt.Wait()
+	closedH := treeH()
+	if closedH >= openH {
+		t.Fatalf("closed tree height: %v not less than open: %v", closedH, openH)
+	}
+	win.SendSyncEvent(func(w *gi.Window) { tv.Open() })
+	tt.Wait()
+	if h := treeH(); h != closedH {
+		t.Errorf("tree height at start of open: %v != %v", h, closedH)
+	}
+	tt.AdvanceAnims(50 * time.Millisecond)
+	if h := treeH(); h <= closedH || h >= openH {
+		t.Errorf("tree height during open: %v not between %v and %v", h, closedH, openH)
+	}
+	tt.AdvanceAnims(150 * time.Millisecond)
+	if h := treeH(); h != openH {
+		t.Errorf("tree height at end of open: %v != %v", h, openH)
+	}
+
+	// popup open
+	if err := tt.Click(mb); err != nil {
+		t.Fatal(err)
+	}
+	pop, err := tt.waitPopup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pvp := pop.(gi.Node2D).AsViewport2D()
+	popH := func() (h, full int) {
+		win.SendSyncEvent(func(w *gi.Window) {
+			bb, _ := w.PopDraws.Nodes.ValByKey(pvp.AsGiNode())
+			h, full = bb.Dy(), pvp.WinBBox.Dy()
+		})
+		return
+	}
+	if h, full := popH(); h != 1 || full <= 1 {
+		t.Errorf("popup height at start of open: %d of %d", h, full)
+	}
+	tt.AdvanceAnims(50 * time.Millisecond)
+	if h, full := popH(); h <= 1 || h >= full {
+		t.Errorf("popup height during open: %d of %d", h, full)
+	}
+	tt.AdvanceAnims(60 * time.Millisecond)
+	if h, full := popH(); h != full {
+		t.Errorf("popup height at end of open: %d of %d", h, full)
+	}
+	if win.Anims.IsAnimating() {
+		t.Errorf("open animations still running after they should be done")
+	}
+}
+
+func TestFlex(t *testing.T) {
+	win := gi.NewMainWindow("gitest-flex", "GiTest Flex", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+
+	row := gi.AddNewLayout(mfr, "row", gi.LayoutFlex)
+	row.SetProp("width", "300px")
+	row.SetProp("height", "100px")
+	row.SetProp("gap", "10px")
+	row.SetProp("justify-content", "space-between")
+	item := func(par ki.Ki, name string, flex string) *gi.Frame {
+		fr := gi.AddNewFrame(par, name, gi.LayoutHoriz)
+		fr.SetProp("width", "20px")
+		fr.SetProp("height", "20px")
+		fr.SetProp("min-width", "20px")
+		fr.SetProp("min-height", "20px")
+		if flex != "" {
+			fr.SetProp("flex", flex)
+		}
+		return fr
+	}
+	a := item(row, "a", "1")
+	b := item(row, "b", "2")
+	c := item(row, "c", "none")
+	c.SetProp("align-self", "center")
+
+	wrap := gi.AddNewLayout(mfr, "wrap", gi.LayoutFlex)
+	wrap.SetProp("width", "100px")
+	wrap.SetProp("flex-wrap", "wrap")
+	wrap.SetProp("flex-direction", "row-reverse")
+	var ws []*gi.Frame
+	for _, nm := range []string{"w1", "w2", "w3"} {
+		fr := item(wrap, nm, "")
+		fr.SetProp("width", "40px")
+		ws = append(ws, fr)
+	}
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	near := func(a, b float32) bool { return math.Abs(float64(a-b)) < 0.5 }
+	as, bs, cs := a.LayState.Alloc, b.LayState.Alloc, c.LayState.Alloc
+	spc := row.BoxSpace()
+	avail := row.LayState.Alloc.Size.X - spc.Size().X
+	if !near(cs.Size.X, 20) {
+		t.Errorf("flex none width: %v != 20", cs.Size.X)
+	}
+	if !near(as.Size.X+bs.Size.X+cs.Size.X+20, avail) {
+		t.Errorf("flex items do not fill the layout: %v + %v + %v + gaps != %v", as.Size.X, bs.Size.X, cs.Size.X, avail)
+	}
+	if !near(bs.Size.X, 2*as.Size.X) {
+		t.Errorf("flex 2 width: %v is not twice flex 1 width: %v", bs.Size.X, as.Size.X)
+	}
+	if !near(bs.PosRel.X, as.PosRel.X+as.Size.X+10) {
+		t.Errorf("gap not respected: b at %v, a at %v size %v", bs.PosRel.X, as.PosRel.X, as.Size.X)
+	}
+	lh := row.LayState.Alloc.Size.Y - spc.Size().Y
+	if !near(as.Size.Y, lh) {
+		t.Errorf("align-items stretch height: %v != %v", as.Size.Y, lh)
+	}
+	if !near(cs.Size.Y, 20) || !near(cs.PosRel.Y, spc.Top+0.5*(lh-20)) {
+		t.Errorf("align-self center: pos %v size %v in line %v", cs.PosRel.Y, cs.Size.Y, lh)
+	}
+
+	if len(wrap.FlowBreaks) != 2 {
+		t.Fatalf("flex-wrap lines: %v", wrap.FlowBreaks)
+	}
+	w1, w2, w3 := ws[0].LayState.Alloc, ws[1].LayState.Alloc, ws[2].LayState.Alloc
+	if !(w1.PosRel.X > w2.PosRel.X) || !near(w1.PosRel.Y, w2.PosRel.Y) {
+		t.Errorf("row-reverse first line: w1 %v, w2 %v", w1.PosRel, w2.PosRel)
+	}
+	if !near(w3.PosRel.X, w1.PosRel.X) || !(w3.PosRel.Y >= w1.PosRel.Y+w1.Size.Y) {
+		t.Errorf("wrapped second line: w1 %v, w3 %v", w1.PosRel, w3.PosRel)
+	}
+	if wrap.LayState.Alloc.Size.Y < w3.PosRel.Y+w3.Size.Y {
+		t.Errorf("wrapped layout height: %v does not fit second line at %v", wrap.LayState.Alloc.Size.Y, w3.PosRel.Y+w3.Size.Y)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	win := gi.NewMainWindow("gitest-grid", "GiTest Grid", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+
+	item := func(par ki.Ki, name string) *gi.Frame {
+		fr := gi.AddNewFrame(par, name, gi.LayoutHoriz)
+		fr.SetProp("width", "20px")
+		fr.SetProp("height", "20px")
+		fr.SetProp("min-width", "20px")
+		fr.SetProp("min-height", "20px")
+		fr.SetProp("max-width", -1)
+		fr.SetProp("max-height", -1)
+		return fr
+	}
+	grid := gi.AddNewLayout(mfr, "grid", gi.LayoutGrid)
+	grid.SetProp("width", "300px")
+	grid.SetProp("height", "200px")
+	grid.SetProp("gap", "10px")
+	grid.SetProp("grid-template-columns", "100px 1fr 2fr")
+	grid.SetProp("grid-template-rows", "40px auto 1fr")
+	grid.SetProp("grid-template-areas", `"head head head" "side main main" "side foot foot"`)
+	areas := map[string]*gi.Frame{}
+	for _, nm := range []string{"foot", "main", "side", "head"} {
+		areas[nm] = item(grid, nm)
+		areas[nm].SetProp("grid-area", nm)
+	}
+
+	spans := gi.AddNewLayout(mfr, "spans", gi.LayoutGrid)
+	spans.SetProp("columns", 2)
+	wide := item(spans, "wide")
+	wide.SetProp("width", "100px")
+	wide.SetProp("min-width", "100px")
+	wide.SetProp("col-span", 2)
+	item(spans, "s1")
+	item(spans, "s2")
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	near := func(a, b float32) bool { return math.Abs(float64(a-b)) < 0.5 }
+	spc := grid.BoxSpace()
+	w := grid.LayState.Alloc.Size.X - spc.Size().X
+	h := grid.LayState.Alloc.Size.Y - spc.Size().Y
+	exp := map[string][4]float32{ // x, y, width, height
+		"head": {0, 0, w, 40},
+		"side": {0, 50, 100, h - 50},
+		"main": {110, 50, w - 110, 20},
+		"foot": {110, 80, w - 110, h - 80},
+	}
+	for nm, e := range exp {
+		al := areas[nm].LayState.Alloc
+		if !near(al.PosRel.X, spc.Left+e[0]) || !near(al.PosRel.Y, spc.Top+e[1]) || !near(al.Size.X, e[2]) || !near(al.Size.Y, e[3]) {
+			t.Errorf("grid area %v: pos %v size %v, expected %v", nm, al.PosRel, al.Size, e)
+		}
+	}
+	if gd := grid.GridData[gi.Col]; !near(gd[2].AllocSize, 2*gd[1].AllocSize) {
+		t.Errorf("2fr col %v is not twice 1fr col %v", gd[2].AllocSize, gd[1].AllocSize)
+	}
+
+	if spans.GridSize != image.Pt(2, 2) {
+		t.Errorf("span grid size: %v", spans.GridSize)
+	}
+	if spans.GridPos[0] != image.Rect(0, 0, 2, 1) || spans.GridPos[2] != image.Rect(1, 1, 2, 2) {
+		t.Errorf("span grid placement: %v", spans.GridPos)
+	}
+	gd := spans.GridData[gi.Col]
+	if sum := gd[0].AllocSize + gd[1].AllocSize + spans.GridGap(); sum < 100 {
+		t.Errorf("spanned cols: %v do not fit the spanning item", sum)
+	}
+	if w := wide.LayState.Alloc.Size.X; w < 100 {
+		t.Errorf("spanning item width: %v", w)
+	}
+}
+
+func TestBoxSides(t *testing.T) {
+	win := gi.NewMainWindow("gitest-sides", "GiTest Sides", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+
+	box := gi.AddNewFrame(mfr, "box", gi.LayoutHoriz)
+	box.SetProp("margin", "0")
+	box.SetProp("padding", "2px 10px 4px 20px")
+	box.SetProp("border-width", "0")
+	box.SetProp("border-left-width", "3px")
+	box.SetProp("border-top-left-radius", "6px")
+	kid := gi.AddNewFrame(box, "kid", gi.LayoutHoriz)
+	kid.SetProp("margin", "0")
+	kid.SetProp("padding", "0")
+	kid.SetProp("width", "40px")
+	kid.SetProp("height", "20px")
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	spc := box.BoxSpace()
+	if spc != (gist.SideFloats{Top: 2, Right: 10, Bottom: 4, Left: 23}) {
+		t.Errorf("box space: %+v", spc)
+	}
+	if pr := kid.LayState.Alloc.PosRel; pr.X != 23 || pr.Y != 2 {
+		t.Errorf("child position: %v, expected 23, 2", pr)
+	}
+	if sz := box.LayState.Size.Pref; sz.X < 40+33 || sz.Y < 20+6 {
+		t.Errorf("box pref size: %v does not include the sides", sz)
+	}
+}
+
+func TestCSS(t *testing.T) {
+	sel, err := gi.ParseCSSSelector("Frame > button.primary[variant^=\"ta\"]:hover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp := sel.Specificity(); sp != [3]int{0, 3, 2} || sel.State() != ":hover" {
+		t.Errorf("selector specificity: %v state: %v", sp, sel.State())
+	}
+	if str := sel.String(); str != `frame > button.primary[variant^="ta"]:hover` {
+		t.Errorf("selector string: %v", str)
+	}
+	if _, err := gi.ParseCSSSelector("button >"); err == nil {
+		t.Errorf("incomplete selector should be an error")
+	}
+	sels, err := gi.ParseCSSSelectors(`button[title="a,b"], label[title='c, d'], frame`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sels) != 3 || sels[0].Parts[0].Attrs[0].Val != "a,b" || sels[1].Parts[0].Attrs[0].Val != "c, d" {
+		t.Errorf("selector list with commas in quotes: %v", sels)
+	}
+
+	css, err := gi.ParseCSS(`
+		button { margin: 1px }
+		button.primary { margin: 2px }
+		#ok { padding: 7px }
+		frame > button { border-width: 4px }
+		.tabs button:hover, button:active { min-width: 50px }
+		button[variant=tab] { max-width: 90px }
+		@media (prefers-color-scheme: dark) { button { min-height: 5px } }
+		@media (prefers-color-scheme: light) { button { min-height: 3px } }
+		.zz { max-height: 10px; width: 10px }
+		.aa { max-height: 20px; width: 20px }
+		.zz { height: 8px }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	win := gi.NewMainWindow("gitest-css", "GiTest CSS", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	mfr.CSS = css
+
+	tabs := gi.AddNewFrame(mfr, "tabs", gi.LayoutHoriz)
+	tabs.Class = "tabs"
+	ok := gi.AddNewButton(tabs, "ok")
+	ok.SetText("OK")
+	ok.Class = "primary zz aa"
+	ok.SetProp("variant", "tab")
+	lay := gi.AddNewLayout(tabs, "lay", gi.LayoutHoriz)
+	other := gi.AddNewButton(lay, "other")
+	other.SetText("Other")
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	ost, pst := &ok.Sty.Layout, &other.Sty.Layout
+	if ost.Margin.Top.Val != 2 || pst.Margin.Top.Val != 1 {
+		t.Errorf("specificity: class margin %v, type margin %v", ost.Margin.Top, pst.Margin.Top)
+	}
+	if ost.Padding.Top.Val != 7 {
+		t.Errorf("#id padding: %v", ost.Padding.Top)
+	}
+	obw, pbw := ok.StateStyles[gi.ButtonActive].Border.Width.Top, other.StateStyles[gi.ButtonActive].Border.Width.Top
+	if obw.Val != 4 || pbw.Val == 4 {
+		t.Errorf("child combinator: %v %v", obw, pbw)
+	}
+	if ost.MaxWidth.Val != 90 || pst.MaxWidth.Val == 90 {
+		t.Errorf("attribute selector: %v %v", ost.MaxWidth, pst.MaxWidth)
+	}
+	if ost.MinWidth.Val == 50 || ok.StateStyles[gi.ButtonHover].Layout.MinWidth.Val != 50 || other.StateStyles[gi.ButtonDown].Layout.MinWidth.Val != 50 {
+		t.Errorf("pseudo-class state styles: %v %v %v", ost.MinWidth, ok.StateStyles[gi.ButtonHover].Layout.MinWidth, other.StateStyles[gi.ButtonDown].Layout.MinWidth)
+	}
+	mh := float32(3)
+	if gi.Prefs.IsDarkMode() {
+		mh = 5
+	}
+	if ost.MinHeight.Val != mh {
+		t.Errorf("@media prefers-color-scheme: min-height %v != %v", ost.MinHeight, mh)
+	}
+	// equal specificity: the later rule wins, even if its selector sorts
+	// first or the earlier selector appears again after it
+	if ost.MaxHeight.Val != 20 || ost.Width.Val != 20 || ost.Height.Val != 8 {
+		t.Errorf("source order: max-height %v width %v height %v", ost.MaxHeight, ost.Width, ost.Height)
+	}
+}
+
+func TestStyleVars(t *testing.T) {
+	win := gi.NewMainWindow("gitest-vars", "GiTest Vars", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	mfr.SetProp("--gap", "6px")
+	mfr.SetProp("--wide", "calc(100px - var(--gap))")
+
+	lay := gi.AddNewLayout(mfr, "lay", gi.LayoutHoriz)
+	lay.SetProp("--gap", "2px")
+	lbl := gi.AddNewLabel(lay, "lbl", "Label")
+	lbl.SetProp("margin", "var(--gap)")
+	lbl.SetProp("width", "var(--wide)")
+	lbl.SetProp("padding", "var(--none, 3px)")
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	ls := &lbl.Sty.Layout
+	if ls.Margin.Top.Val != 2 {
+		t.Errorf("inherited var: margin %v", ls.Margin.Top)
+	}
+	if ls.Width.String() != "calc(100px - 6px)" {
+		t.Errorf("var in var: width %v", ls.Width.String())
+	}
+	if ls.Padding.Top.Val != 3 {
+		t.Errorf("var fallback: padding %v", ls.Padding.Top)
+	}
+	if w, e := ls.Width.Dots, lbl.Sty.UnContext.ToDots(94, units.Px); w != e {
+		t.Errorf("calc dots: %v != %v", w, e)
+	}
+}
+
+func TestTheme(t *testing.T) {
+	seed := gist.Color{R: 0x30, G: 0x60, B: 0xc0, A: 0xff}
+	for _, dark := range []bool{false, true} {
+		var cp gi.ColorPrefs
+		cp.GenerateTheme(seed, gist.Color{}, dark)
+		if cp.Background.IsDark() != dark {
+			t.Errorf("dark %v: background %v", dark, cp.Background.HexString())
+		}
+		for _, cc := range cp.ContrastIssues() {
+			t.Errorf("dark %v: %v", dark, cc.String())
+		}
+		// accents keep the hue of the seed
+		if dh := cp.Link.ToOKLCH().H - seed.ToOKLCH().H; dh > 5 || dh < -5 {
+			t.Errorf("dark %v: link hue differs from seed by %v", dark, dh)
+		}
+		if hi := cp.Highlight.ToOKLCH().H - seed.ToOKLCH().H; hi < 50 || hi > 70 {
+			t.Errorf("dark %v: derived secondary hue differs by %v", dark, hi)
+		}
+		// the generated highlighting style must survive a restart
+		var saved histyle.Styles
+		if err := saved.OpenPrefs(); err != nil {
+			t.Fatal(err)
+		}
+		if _, has := saved[string(cp.HiStyle)]; !has {
+			t.Errorf("dark %v: hi style %v not saved in prefs", dark, cp.HiStyle)
+		}
+	}
+	// generated styles that are no longer used do not accumulate in the prefs
+	var cp2 gi.ColorPrefs
+	cp2.GenerateTheme(gist.Color{R: 0xc0, G: 0x40, B: 0x30, A: 0xff}, gist.Color{}, false)
+	var saved histyle.Styles
+	if err := saved.OpenPrefs(); err != nil {
+		t.Fatal(err)
+	}
+	if _, has := saved[string(gi.ThemeHiStyleName(seed, gist.Color{}, true))]; has {
+		t.Errorf("unused hi style %v still saved in prefs", gi.ThemeHiStyleName(seed, gist.Color{}, true))
+	}
+	if _, has := saved[string(cp2.HiStyle)]; !has {
+		t.Errorf("hi style %v not saved in prefs", cp2.HiStyle)
+	}
+	var pf gi.Preferences
+	cp := pf.NewColorScheme("Ocean", seed, gist.Color{R: 0, G: 0x90, B: 0x80, A: 0xff}, true)
+	if pf.ColorSchemes["Ocean"] != cp || len(pf.ColorSchemes) != 3 || !pf.Changed {
+		t.Errorf("NewColorScheme: %v", pf.ColorSchemes)
+	}
+	// schemes that differ only in their secondary seed have their own style
+	var cp3 gi.ColorPrefs
+	cp3.GenerateTheme(seed, gist.Color{R: 0x90, G: 0x30, B: 0x80, A: 0xff}, true)
+	if cp3.HiStyle == cp.HiStyle || cp3.HiStyle == gi.ThemeHiStyleName(seed, gist.Color{}, true) {
+		t.Errorf("hi style should depend on the secondary seed: %v", cp3.HiStyle)
+	}
+}
+
+func TestConicGradient(t *testing.T) {
+	sv := &svg.SVG{}
+	sv.InitName(sv, "conic")
+	sv.Fill = true
+	sv.ViewBox.Size.Set(64, 64)
+	gr := gi.AddNewGradient(&sv.Defs, "pie")
+	gr.Grad.SetString("conic-gradient(from 90deg, red 0 25%, blue 25% 100%)", nil)
+	rect := svg.AddNewRect(sv, "rect", 0, 0, 64, 64)
+	rect.SetProp("fill", "url(#pie)")
+	rect.SetProp("stroke", "none")
+
+	img, err := RenderNode(sv, image.Point{64, 64}, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first quarter, clockwise from the right, is red
+	if r, _, b, _ := img.At(50, 40).RGBA(); r < 0xf000 || b > 0x1000 {
+		t.Errorf("conic fill first quarter not red: %v", img.At(50, 40))
+	}
+	if r, _, b, _ := img.At(14, 24).RGBA(); b < 0xf000 || r > 0x1000 {
+		t.Errorf("conic fill remainder not blue: %v", img.At(14, 24))
+	}
+
+	var buf bytes.Buffer
+	if err := sv.WriteXML(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	// other renderers see a standard linear gradient with the same stops
+	xs := buf.String()
+	if strings.Contains(xs, "<conicGradient") || strings.Count(xs, "<linearGradient") != 1 ||
+		strings.Count(xs, "<stop") != 4 || !strings.Contains(xs, `gogi:conic-from="90"`) {
+		t.Errorf("conic gradient not written as a linearGradient with gogi:conic attributes: %s", xs)
+	}
+	rsv := &svg.SVG{}
+	rsv.InitName(rsv, "conic-read")
+	if err := rsv.ReadXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rgr, ok := rsv.Defs.ChildByName("pie", 0).(*gi.Gradient)
+	if !ok {
+		t.Fatalf("conic gradient not read back from: %s", xs)
+	}
+	if rgr.Grad.Source != gist.ConicGradient || rgr.Grad.Gradient.Points != gr.Grad.Gradient.Points ||
+		rgr.Grad.Gradient.Units != gr.Grad.Gradient.Units || len(rgr.Grad.Gradient.Stops) != 4 {
+		t.Errorf("conic gradient read back as: %v %+v", rgr.Grad.Source, rgr.Grad.Gradient)
+	}
+
+	// files with the non-standard element written previously can still be read
+	old := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 64 64"><defs>
+		<conicGradient id="old" cx="0.25" cy="0.5" from="45">
+		<stop offset="0" stop-color="red"/><stop offset="1" stop-color="blue"/>
+		</conicGradient></defs></svg>`
+	if err := rsv.ReadXML(strings.NewReader(old)); err != nil {
+		t.Fatal(err)
+	}
+	ogr, ok := rsv.Defs.ChildByName("old", 0).(*gi.Gradient)
+	if !ok || ogr.Grad.Source != gist.ConicGradient || ogr.Grad.Gradient.Points[0] != 0.25 || ogr.Grad.Gradient.Points[2] != 45 {
+		t.Errorf("legacy conicGradient not read: %v", ogr)
+	}
+}
+
+func TestDatePicker(t *testing.T) {
+	win := gi.NewMainWindow("gitest-date", "GiTest Date", 600, 500)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+
+	dp := gi.AddNewDatePicker(mfr, "date")
+	dp.WeekNums = true
+	dp.SetMinMax(time.Date(2023, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2023, 4, 20, 0, 0, 0, 0, time.UTC))
+	dp.SetDate(time.Date(2023, 3, 15, 9, 30, 0, 0, time.UTC))
+	changes := 0
+	dp.DatePickerSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		changes++
+	})
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 30, 0, 0, time.UTC)
+	}
+	if wk, _ := tt.Find("main-vlay/main-frame/date/grid/wk-0"); wk == nil || wk.(*gi.Label).Text != "9" {
+		t.Errorf("week number of the first week of March 2023 is not 9")
+	}
+	if bt, err := tt.FindByText("4"); err != nil || !bt.AsNode2D().IsInactive() {
+		t.Errorf("day before the minimum date is not inactive: %v", err)
+	}
+	bt, err := tt.FindByText("20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt.Click(bt)
+	if !dp.Date.Equal(day(2023, 3, 20)) || changes != 1 {
+		t.Errorf("date after click: %v, changes: %d", dp.Date, changes)
+	}
+	if tt.Focus() != dp.This() {
+		t.Errorf("date picker does not have the focus after click: %v", tt.Focus())
+	}
+
+	keys := []struct {
+		chord key.Chord
+		date  time.Time
+	}{
+		{"RightArrow", day(2023, 3, 21)},
+		{"DownArrow", day(2023, 3, 28)},
+		{"PageDown", day(2023, 4, 20)}, // clamped to the maximum
+		{"Home", day(2023, 4, 1)},
+		{"PageUp", day(2023, 3, 5)}, // clamped to the minimum
+		{"End", day(2023, 3, 31)},
+		{"UpArrow", day(2023, 3, 24)},
+		{"Home", day(2023, 3, 5)},
+	}
+	for _, k := range keys {
+		tt.KeyChord(k.chord)
+		if !dp.Date.Equal(k.date) {
+			t.Errorf("date after %v: %v != %v", k.chord, dp.Date, k.date)
+		}
+	}
+	if dp.Month.Month() != time.March {
+		t.Errorf("month shown: %v", dp.Month.Month())
+	}
+
+	dp.Range = true
+	tt.KeyChord("Shift+RightArrow")
+	tt.KeyChord("Shift+RightArrow")
+	if st, ed := dp.DateRange(); !st.Equal(day(2023, 3, 5)) || !ed.Equal(day(2023, 3, 7)) {
+		t.Errorf("range after extending: %v - %v", st, ed)
+	}
+}
+
+func TestRangeSlider(t *testing.T) {
+	win := gi.NewMainWindow("gitest-range", "GiTest Range", 600, 400)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+
+	sl := gi.AddNewSlider(mfr, "range")
+	sl.Defaults()
+	sl.SetMinPrefWidth(units.NewPx(400))
+	sl.Max = 10
+	sl.Step = 1
+	sl.PageStep = 2
+	sl.Snap = true
+	sl.MajorTick = 2
+	sl.MinorTicks = 1
+	sl.TickLabels = true
+	sl.SetRangeValues(6, 2)
+	changes := 0
+	sl.SliderSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.SliderValueChanged) {
+			changes++
+		}
+	})
+
+	data := struct {
+		Span struct{ Min, Max float32 } `view:"range" max:"100" step:"5"`
+	}{}
+	data.Span.Min, data.Span.Max = 10, 20
+	sv := giv.AddNewStructView(mfr, "sv")
+	sv.SetStruct(&data)
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+
+	tt, err := New(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tt.Close()
+
+	major, minor := sl.TickValues()
+	if len(major) != 6 || major[5] != 10 || len(minor) != 5 || minor[0] != 1 {
+		t.Errorf("ticks: major %v, minor %v", major, minor)
+	}
+	if sl.Value != 2 || sl.HighValue != 6 {
+		t.Errorf("range values not ordered: %v - %v", sl.Value, sl.HighValue)
+	}
+
+	// dragging the middle segment moves the whole range
+	ctr, err := WidgetCenter(sl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	del := int(mat32.Round(2 * sl.Size / 10))
+	tt.DragPos(ctr, ctr.Add(image.Pt(del, 0)), 4)
+	if sl.Value != 4 || sl.HighValue != 8 || changes != 1 {
+		t.Errorf("range after drag: %v - %v, changes: %d", sl.Value, sl.HighValue, changes)
+	}
+
+	keys := []struct {
+		chord  key.Chord
+		lo, hi float32
+	}{
+		{"RightArrow", 5, 8},
+		{"End", 8, 8}, // stops at the high thumb
+		{" ", 8, 8},
+		{"End", 8, 10},
+		{"Shift+LeftArrow", 7, 9},
+		{"Home", 7, 7}, // stops at the low thumb
+		{" ", 7, 7},
+		{"PageUp", 5, 7},
+	}
+	if tt.Focus() != sl.This() {
+		t.Fatalf("range slider does not have the focus: %v", tt.Focus())
+	}
+	for _, k := range keys {
+		tt.KeyChord(k.chord)
+		if sl.Value != k.lo || sl.HighValue != k.hi {
+			t.Errorf("range after %q: %v - %v != %v - %v", k.chord, sl.Value, sl.HighValue, k.lo, k.hi)
+		}
+	}
+
+	sl.Log = true
+	sl.Min, sl.Max = 1, 1000
+	sl.MajorTick, sl.MinorTicks = 10, 8
+	if fr := sl.ValueToFrac(10); math.Abs(float64(fr-1.0/3)) > 1.0e-5 {
+		t.Errorf("log fraction of 10 in 1-1000: %v", fr)
+	}
+	major, minor = sl.TickValues()
+	if len(major) != 4 || major[3] != 1000 || len(minor) != 24 || minor[0] != 2 {
+		t.Errorf("log ticks: major %v, minor %v", major, minor)
+	}
+
+	// the giv range value view sets both fields
+	rs, err := tt.FindByName("slider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsl := rs.(*gi.Slider)
+	if !rsl.Range || rsl.Max != 100 || rsl.Value != 10 || rsl.HighValue != 20 {
+		t.Errorf("range value view slider: %v %v %v - %v", rsl.Range, rsl.Max, rsl.Value, rsl.HighValue)
+	}
+	tt.Click(rsl) // the high thumb is closer to the middle
+	if data.Span.Min != 10 || data.Span.Max != 50 {
+		t.Errorf("range field after click: %v", data.Span)
+	}
+}
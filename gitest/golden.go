@@ -0,0 +1,185 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+)
+
+// Update is the -update test flag: if set, CompareGolden saves the given
+// images as the new golden images, instead of comparing to them.
+var Update = flag.Bool("update", false, "update golden image files instead of comparing to them")
+
+// GoldenOpts are the options for comparing images against golden images.
+type GoldenOpts struct {
+	Dir         string  `desc:"directory where the golden images are stored, along with the actual and diff images for failed comparisons"`
+	Tolerance   uint8   `desc:"maximum difference in any one color channel for a pixel to be considered the same"`
+	MaxDiffFrac float64 `desc:"maximum fraction of pixels that can differ for the comparison to pass"`
+}
+
+// DefaultGoldenOpts returns the default golden image options: images in
+// testdata, with a tolerance of 2 per channel, and no differing pixels.
+func DefaultGoldenOpts() *GoldenOpts {
+	return &GoldenOpts{Dir: "testdata", Tolerance: 2}
+}
+
+// RenderNode renders the given widget into a new image of given size, with
+// widget styles computed at given logical DPI, in a viewport that is not in
+// any window (see gi.VpFlagOffscreen), so the result does not depend on
+// the screen.  If the widget
+// is itself a Viewport2D (including an svg.SVG), it is resized and rendered
+// directly.  Otherwise, it must not have a parent yet: it is added to a new
+// viewport for rendering, and removed again afterward.  gi.Init is called
+// to ensure preferences and fonts are initialized, which requires that the
+// driver is running, as in Main.  Rendering is not safe to do concurrently.
+func RenderNode(nd gi.Node2D, size image.Point, dpi float32) (*image.RGBA, error) {
+	gi.Init()
+	odpi := gi.NoWinDPI
+	gi.NoWinDPI = dpi
+	defer func() { gi.NoWinDPI = odpi }()
+
+	if vp := nd.AsViewport2D(); vp != nil {
+		if vp.Parent() != nil {
+			return nil, fmt.Errorf("gitest.RenderNode: viewport: %v has a parent -- use gi.GrabRenderFrom", vp.Path())
+		}
+		vp.SetFlag(int(gi.VpFlagOffscreen))
+		vp.Resize(size)
+		vp.FullRender2DTree()
+		return gi.GrabRenderFrom(vp), nil
+	}
+	if nd.Parent() != nil {
+		return nil, fmt.Errorf("gitest.RenderNode: widget: %v has a parent -- use gi.GrabRenderFrom", nd.Path())
+	}
+	vp := gi.NewViewport2D(size.X, size.Y)
+	vp.InitName(vp, "gitest-render")
+	vp.Fill = true
+	vp.SetFlag(int(gi.VpFlagOffscreen))
+	vp.AddChild(nd)
+	vp.FullRender2DTree()
+	img := gi.GrabRenderFrom(vp)
+	vp.DeleteChild(nd, ki.NoDestroyKids)
+	return img, nil
+}
+
+// DiffImages compares the image with the expected one, returning the number
+// of pixels that differ by more than tol in any color channel, and a diff
+// image highlighting those pixels in red over a faded copy of the expected
+// image.  If the sizes differ, all pixels outside of the common area are
+// counted as different.
+func DiffImages(img, exp image.Image, tol uint8) (int, *image.RGBA) {
+	ib := img.Bounds()
+	eb := exp.Bounds()
+	sz := image.Point{ib.Dx(), ib.Dy()}
+	if eb.Dx() > sz.X {
+		sz.X = eb.Dx()
+	}
+	if eb.Dy() > sz.Y {
+		sz.Y = eb.Dy()
+	}
+	diff := image.NewRGBA(image.Rectangle{Max: sz})
+	red := color.RGBA{255, 0, 0, 255}
+	ndiff := 0
+	for y := 0; y < sz.Y; y++ {
+		for x := 0; x < sz.X; x++ {
+			ip := image.Point{ib.Min.X + x, ib.Min.Y + y}
+			ep := image.Point{eb.Min.X + x, eb.Min.Y + y}
+			if !ip.In(ib) || !ep.In(eb) {
+				diff.SetRGBA(x, y, red)
+				ndiff++
+				continue
+			}
+			ic := color.RGBAModel.Convert(img.At(ip.X, ip.Y)).(color.RGBA)
+			ec := color.RGBAModel.Convert(exp.At(ep.X, ep.Y)).(color.RGBA)
+			if chanDiff(ic.R, ec.R) > tol || chanDiff(ic.G, ec.G) > tol || chanDiff(ic.B, ec.B) > tol || chanDiff(ic.A, ec.A) > tol {
+				diff.SetRGBA(x, y, red)
+				ndiff++
+				continue
+			}
+			gr := uint8((uint16(ec.R) + uint16(ec.G) + uint16(ec.B)) / 3)
+			gr = 255 - (255-gr)/4 // faded gray
+			diff.SetRGBA(x, y, color.RGBA{gr, gr, gr, 255})
+		}
+	}
+	return ndiff, diff
+}
+
+func chanDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// CompareGolden compares the image against the golden image with given name
+// (without the .png extension) in opts.Dir (DefaultGoldenOpts if nil),
+// returning an error if it is missing, or if too many pixels differ, in
+// which case the actual image and a diff image (see DiffImages) are saved
+// next to the golden image as name_actual.png and name_diff.png.
+// If the -update flag is set, the image is saved as the new golden image.
+func CompareGolden(name string, img image.Image, opts *GoldenOpts) error {
+	if opts == nil {
+		opts = DefaultGoldenOpts()
+	}
+	fnm := filepath.Join(opts.Dir, name+".png")
+	actfn := filepath.Join(opts.Dir, name+"_actual.png")
+	difffn := filepath.Join(opts.Dir, name+"_diff.png")
+	if *Update {
+		err := os.MkdirAll(filepath.Dir(fnm), 0755)
+		if err != nil {
+			return err
+		}
+		os.Remove(actfn)
+		os.Remove(difffn)
+		return gi.SavePNG(fnm, img)
+	}
+	exp, err := gi.OpenPNG(fnm)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("gitest.CompareGolden: golden image: %v does not exist -- run the test with -update to create it", fnm)
+		}
+		return err
+	}
+	ndiff, diff := DiffImages(img, exp, opts.Tolerance)
+	sz := diff.Bounds().Size()
+	frac := float64(ndiff) / float64(sz.X*sz.Y)
+	if ndiff == 0 || frac <= opts.MaxDiffFrac {
+		os.Remove(actfn)
+		os.Remove(difffn)
+		return nil
+	}
+	gi.SavePNG(actfn, img)
+	gi.SavePNG(difffn, diff)
+	return fmt.Errorf("gitest.CompareGolden: image: %v differs from golden: %d pixels (%.4g%%) differ, more than max: %.4g%% -- see: %v", name, ndiff, 100*frac, 100*opts.MaxDiffFrac, difffn)
+}
+
+// AssertGolden calls CompareGolden and reports any error to t.
+func AssertGolden(t testing.TB, name string, img image.Image, opts *GoldenOpts) {
+	t.Helper()
+	if err := CompareGolden(name, img, opts); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertNodeGolden renders the widget with RenderNode, and compares it
+// to the golden image with given name using AssertGolden.
+func AssertNodeGolden(t testing.TB, name string, nd gi.Node2D, size image.Point, dpi float32, opts *GoldenOpts) {
+	t.Helper()
+	img, err := RenderNode(nd, size, dpi)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	AssertGolden(t, name, img, opts)
+}
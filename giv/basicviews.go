@@ -14,6 +14,7 @@ import (
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
 )
 
 // basicviews contains all the ValueView's for basic builtin types
@@ -699,6 +700,173 @@ func (vv *FloatValueView) ConfigWidget(widg gi.Node2D) {
 	vv.UpdateWidget()
 }
 
+////////////////////////////////////////////////////////////////////////////////////////
+//  RangeValueView
+
+// RangeValueView presents a range slider, with a low and a high thumb, for a
+// struct field tagged view:"range" with a low and a high float field -- the
+// Min and Max fields, or else the first two float fields.  The min and max
+// tags set the ends of the slider (by default 0 and 1, extended to include
+// the current values), and the step (also snapping to it), format (for the
+// values and tick labels), log:"true", ticks (major tick interval, labeled)
+// and minor-ticks tags configure it further.
+type RangeValueView struct {
+	ValueViewBase
+}
+
+var KiT_RangeValueView = kit.Types.AddType(&RangeValueView{}, nil)
+
+// RangeFields returns the indexes of the low and high fields of given struct
+// type for a RangeValueView -- false if it does not have them
+func RangeFields(typ reflect.Type) (lo, hi int, ok bool) {
+	if typ.Kind() != reflect.Struct {
+		return -1, -1, false
+	}
+	lo, hi = -1, -1
+	var flts []int
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || (f.Type.Kind() != reflect.Float32 && f.Type.Kind() != reflect.Float64) {
+			continue
+		}
+		switch f.Name {
+		case "Min":
+			lo = i
+		case "Max":
+			hi = i
+		}
+		flts = append(flts, i)
+	}
+	switch {
+	case lo >= 0 && hi >= 0:
+		return lo, hi, true
+	case len(flts) >= 2:
+		return flts[0], flts[1], true
+	}
+	return -1, -1, false
+}
+
+func (vv *RangeValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Layout
+	return vv.WidgetTyp
+}
+
+// Range returns the current low and high values
+func (vv *RangeValueView) Range() (lo, hi float32) {
+	npv := kit.NonPtrValue(vv.Value)
+	lf, hf, ok := RangeFields(npv.Type())
+	if !ok {
+		return 0, 0
+	}
+	return float32(npv.Field(lf).Float()), float32(npv.Field(hf).Float())
+}
+
+// SetRange sets the low and high values, through SetValue
+func (vv *RangeValueView) SetRange(lo, hi float32) bool {
+	npv := kit.NonPtrValue(vv.Value)
+	lf, hf, ok := RangeFields(npv.Type())
+	if !ok {
+		return false
+	}
+	nv := reflect.New(npv.Type()).Elem()
+	nv.Set(npv)
+	nv.Field(lf).SetFloat(float64(lo))
+	nv.Field(hf).SetFloat(float64(hi))
+	return vv.SetValue(nv.Interface())
+}
+
+// RangeLabel returns the text for the label showing the given low and high
+// values, formatted with the format tag
+func (vv *RangeValueView) RangeLabel(lo, hi float32) string {
+	fmtstr := "%g"
+	if fmttag, ok := vv.Tag("format"); ok {
+		fmtstr = fmttag
+	}
+	return fmt.Sprintf(fmtstr+" to "+fmtstr, lo, hi)
+}
+
+func (vv *RangeValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ly := vv.Widget.(*gi.Layout)
+	sl := ly.ChildByName("slider", 0).(*gi.Slider)
+	lo, hi := vv.Range()
+	sl.SetRangeValues(lo, hi)
+	ly.ChildByName("value", 1).(*gi.Label).SetText(vv.RangeLabel(sl.Value, sl.HighValue))
+}
+
+func (vv *RangeValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	ly := vv.Widget.(*gi.Layout)
+	ly.Lay = gi.LayoutHoriz
+	ly.SetStretchMaxWidth()
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Slider, "slider")
+	config.Add(gi.KiT_Label, "value")
+	ly.ConfigChildren(config)
+
+	sl := ly.Child(0).(*gi.Slider)
+	sl.Tooltip, _ = vv.Tag("desc")
+	sl.SetInactiveState(vv.This().(ValueView).IsInactive())
+	sl.SetMinPrefWidth(units.NewCh(20))
+	sl.SetStretchMaxWidth()
+	sl.Defaults()
+	sl.Range = true
+	lo, hi := vv.Range()
+	sl.Min = mat32.Min(0, lo)
+	sl.Max = mat32.Max(1, hi)
+	if mintag, ok := vv.Tag("min"); ok {
+		if minv, ok := kit.ToFloat32(mintag); ok {
+			sl.Min = minv
+		}
+	}
+	if maxtag, ok := vv.Tag("max"); ok {
+		if maxv, ok := kit.ToFloat32(maxtag); ok {
+			sl.Max = maxv
+		}
+	}
+	sl.Step = (sl.Max - sl.Min) / 100
+	if steptag, ok := vv.Tag("step"); ok {
+		if step, ok := kit.ToFloat32(steptag); ok {
+			sl.Step = step
+			sl.Snap = true
+		}
+	}
+	sl.PageStep = 10 * sl.Step
+	if logtag, ok := vv.Tag("log"); ok {
+		sl.Log, _ = kit.ToBool(logtag)
+	}
+	if tickstag, ok := vv.Tag("ticks"); ok {
+		if ticks, ok := kit.ToFloat32(tickstag); ok {
+			sl.MajorTick = ticks
+			sl.TickLabels = true
+		}
+	}
+	if mintag, ok := vv.Tag("minor-ticks"); ok {
+		if minor, ok := kit.ToInt(mintag); ok {
+			sl.MinorTicks = int(minor)
+		}
+	}
+	sl.TickFormat, _ = vv.Tag("format")
+
+	sl.SliderSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_RangeValueView).(*RangeValueView)
+		slr := send.Embed(gi.KiT_Slider).(*gi.Slider)
+		switch sig {
+		case int64(gi.SliderMoved):
+			lb := vvv.Widget.ChildByName("value", 1).(*gi.Label)
+			lb.SetText(vvv.RangeLabel(slr.Value, slr.HighValue))
+		case int64(gi.SliderValueChanged):
+			if vvv.SetRange(slr.Value, slr.HighValue) {
+				vvv.UpdateWidget()
+			}
+		}
+	})
+	vv.UpdateWidget()
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 //  EnumValueView
 
@@ -1036,7 +1204,13 @@ func (vv *NilValueView) ConfigWidget(widg gi.Node2D) {
 
 var DefaultTimeFormat = "2006-01-02 15:04:05 MST"
 
-// TimeValueView presents a checkbox for a boolean
+// DefaultDateFormat is the format of the min and max tags of time values
+var DefaultDateFormat = "2006-01-02"
+
+// TimeValueView presents a text field for a time.Time (or FileTime), with
+// an action that opens a gi.DateTimeDialog to choose the date and time --
+// the min and max tags, in the DefaultDateFormat, limit the dates that can
+// be chosen
 type TimeValueView struct {
 	ValueViewBase
 }
@@ -1044,7 +1218,7 @@ type TimeValueView struct {
 var KiT_TimeValueView = kit.Types.AddType(&TimeValueView{}, nil)
 
 func (vv *TimeValueView) WidgetType() reflect.Type {
-	vv.WidgetTyp = gi.KiT_TextField
+	vv.WidgetTyp = gi.KiT_Layout
 	return vv.WidgetTyp
 }
 
@@ -1064,7 +1238,8 @@ func (vv *TimeValueView) UpdateWidget() {
 	if vv.Widget == nil {
 		return
 	}
-	tf := vv.Widget.(*gi.TextField)
+	ly := vv.Widget.(*gi.Layout)
+	tf := ly.ChildByName("text", 0).(*gi.TextField)
 	tm := vv.TimeVal()
 	tf.SetText(tm.Format(DefaultTimeFormat))
 }
@@ -1072,10 +1247,18 @@ func (vv *TimeValueView) UpdateWidget() {
 func (vv *TimeValueView) ConfigWidget(widg gi.Node2D) {
 	vv.Widget = widg
 	vv.StdConfigWidget(widg)
-	tf := vv.Widget.(*gi.TextField)
-	tf.SetStretchMaxWidth()
+	ly := vv.Widget.(*gi.Layout)
+	ly.Lay = gi.LayoutHoriz
+	ly.SetStretchMaxWidth()
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_TextField, "text")
+	config.Add(gi.KiT_Action, "edit")
+	ly.ConfigChildren(config)
+	inact := vv.This().(ValueView).IsInactive()
+
+	tf := ly.Child(0).(*gi.TextField)
 	tf.Tooltip, _ = vv.Tag("desc")
-	tf.SetInactiveState(vv.This().(ValueView).IsInactive())
+	tf.SetInactiveState(inact)
 	tf.SetProp("min-width", units.NewCh(float32(len(DefaultTimeFormat)+2)))
 	tf.TextFieldSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
@@ -1092,5 +1275,58 @@ func (vv *TimeValueView) ConfigWidget(widg gi.Node2D) {
 			}
 		}
 	})
+
+	ac := ly.Child(1).(*gi.Action)
+	ac.SetIcon("calendar")
+	ac.Tooltip = "choose the date and time"
+	ac.SetInactiveState(inact)
+	ac.ActionSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_TimeValueView).(*TimeValueView)
+		ac := send.Embed(gi.KiT_Action).(*gi.Action)
+		vvv.Activate(ac.Viewport, nil, nil)
+	})
 	vv.UpdateWidget()
 }
+
+func (vv *TimeValueView) HasAction() bool {
+	return true
+}
+
+func (vv *TimeValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.RecvFunc) {
+	if vv.IsInactive() {
+		return
+	}
+	tm := vv.TimeVal()
+	if tm == nil {
+		return
+	}
+	desc, _ := vv.Tag("desc")
+	dlg := gi.DateTimeDialog(vp, *tm, gi.DlgOpts{Title: vv.Name(), Prompt: desc},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				dlg, _ := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
+				*tm = gi.DateTimeDialogValue(dlg)
+				vv.ViewSig.Emit(vv.This(), 0, nil)
+				vv.UpdateWidget()
+			}
+			if dlgRecv != nil && dlgFunc != nil {
+				dlgFunc(dlgRecv, send, sig, data)
+			}
+		})
+	dp := dlg.Frame().ChildByName("date-time", 0).(*gi.DateTimePicker).DatePicker()
+	if mintag, ok := vv.Tag("min"); ok {
+		if min, err := time.Parse(DefaultDateFormat, mintag); err == nil {
+			dp.SetMin(min)
+		} else {
+			log.Println(err)
+		}
+	}
+	if maxtag, ok := vv.Tag("max"); ok {
+		if max, err := time.Parse(DefaultDateFormat, maxtag); err == nil {
+			dp.SetMax(max)
+		} else {
+			log.Println(err)
+		}
+	}
+	dp.UpdateMonth()
+}
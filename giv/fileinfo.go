@@ -27,7 +27,7 @@ import (
 // including icon, mimetype, etc
 type FileInfo struct {
 	Ic      gi.IconName       `tableview:"no-header" desc:"icon for file"`
-	Name    string            `width:"40" desc:"name of the file, without any path"`
+	Name    string            `width:"40" text-overflow:"ellipsis-middle" desc:"name of the file, without any path"`
 	Size    FileSize          `desc:"size of the file in bytes"`
 	Kind    string            `width:"20" max-width:"20" desc:"type of file / directory -- shorter, more user-friendly version of mime type, based on category"`
 	Mime    string            `tableview:"-" desc:"full official mime type of the contents"`
@@ -340,7 +340,8 @@ var FileInfoProps = ki.Props{
 // 	"github.com/djherbis/times"
 
 // FileTime provides a default String format for file modification times, and
-// other useful methods -- will plug into ValueView with date / time editor.
+// other useful methods -- plugs into ValueView with the TimeValueView date /
+// time editor.
 type FileTime time.Time
 
 // Int satisfies the ints.Inter interface for sorting etc
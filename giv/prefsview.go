@@ -163,6 +163,9 @@ func PrefsDbgView(pf *gi.PrefsDebug) *gi.Window {
 	sv.SetStruct(pf)
 	sv.SetStretchMaxWidth()
 	sv.SetStretchMax()
+	sv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pf.Apply()
+	})
 
 	// mmen := win.MainMenu
 	// MainMenuView(pf, win, mmen)
@@ -509,7 +509,7 @@ func (sv *SliceViewBase) AvailHeight() float32 {
 	if sgHt == 0 {
 		return 0
 	}
-	sgHt -= sg.ExtraSize.Y + sg.Sty.BoxSpace()*2
+	sgHt -= sg.ExtraSize.Y + sg.Sty.BoxSpace().Size().Y
 	return sgHt
 }
 
@@ -549,7 +549,7 @@ func (sv *SliceViewBase) LayoutSliceGrid() bool {
 
 	mvp := sv.ViewportSafe()
 	if mvp != nil && mvp.HasFlag(int(gi.VpFlagPrefSizing)) {
-		sv.VisRows = gi.LayoutPrefMaxRows
+		sv.VisRows = gi.PrefMaxRows(mvp, sv.SliceSize)
 		sv.LayoutHeight = float32(sv.VisRows) * sv.RowHeight
 	} else {
 		sgHt := sv.AvailHeight()
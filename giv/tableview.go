@@ -457,7 +457,7 @@ func (tv *TableView) LayoutSliceGrid() bool {
 
 	mvp := tv.ViewportSafe()
 	if mvp != nil && mvp.HasFlag(int(gi.VpFlagPrefSizing)) {
-		tv.VisRows = ints.MinInt(gi.LayoutPrefMaxRows, tv.SliceSize)
+		tv.VisRows = ints.MinInt(gi.PrefMaxRows(mvp, tv.SliceSize), tv.SliceSize)
 		tv.LayoutHeight = float32(tv.VisRows) * tv.RowHeight
 	} else {
 		sgHt := tv.AvailHeight()
@@ -637,6 +637,7 @@ func (tv *TableView) UpdateSliceGrid() {
 			} else {
 				widg = ki.NewOfType(vtyp).(gi.Node2D)
 				sg.SetChild(widg, cidx, valnm)
+				widg.AsNode2D().SetProp("text-overflow", gist.TextOverflowEllipsis) // full text in tooltip
 				vv.ConfigWidget(widg)
 				wb := widg.AsWidget()
 				if wb != nil {
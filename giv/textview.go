@@ -23,6 +23,7 @@ import (
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/ime"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
@@ -79,6 +80,8 @@ type TextView struct {
 	BlinkOn                bool                        `json:"-" xml:"-" desc:"oscillates between on and off for blinking"`
 	CursorMu               sync.Mutex                  `json:"-" xml:"-" view:"-" desc:"mutex protecting cursor rendering -- shared between blink and main code"`
 	HasLinks               bool                        `json:"-" xml:"-" desc:"at least one of the renders has links -- determines if we set the cursor for hand movements"`
+	Preedit                gi.Preedit                  `json:"-" xml:"-" desc:"input method editor (IME) text composition in progress -- the preedit text is rendered at the cursor, and only inserted into the buffer when committed"`
+	PreeditRender          girl.Text                   `json:"-" xml:"-" desc:"render of the IME preedit text"`
 	lastRecenter           int
 	lastAutoInsert         rune
 	lastFilename           gi.FileName
@@ -316,6 +319,7 @@ func (tv *TextView) Clear() {
 // ResetState resets all the random state variables, when opening a new buffer etc
 func (tv *TextView) ResetState() {
 	tv.SelectReset()
+	tv.Preedit.Reset()
 	tv.Highlights = nil
 	tv.ISearch.On = false
 	tv.QReplace.On = false
@@ -475,8 +479,8 @@ func (tv *TextView) RenderSize() mat32.Vec2 {
 	paloc := parw.LayState.Alloc.SizeOrig
 	if !paloc.IsNil() {
 		// fmt.Printf("paloc: %v, pvp: %v  lineonoff: %v\n", paloc, parw.VpBBox, tv.LineNoOff)
-		tv.RenderSz = paloc.Sub(parw.ExtraSize).SubScalar(spc * 2)
-		tv.RenderSz.X -= spc // extra space
+		tv.RenderSz = paloc.Sub(parw.ExtraSize).Sub(spc.Size())
+		tv.RenderSz.X -= spc.Right // extra space
 		// fmt.Printf("alloc rendersz: %v\n", tv.RenderSz)
 	} else {
 		sz := tv.LayState.Alloc.SizeOrig
@@ -484,7 +488,7 @@ func (tv *TextView) RenderSize() mat32.Vec2 {
 			sz = tv.LayState.SizePrefOrMax()
 		}
 		if !sz.IsNil() {
-			sz.SetSubScalar(2 * spc)
+			sz.SetSub(spc.Size())
 		}
 		tv.RenderSz = sz
 		// fmt.Printf("fallback rendersz: %v\n", tv.RenderSz)
@@ -587,7 +591,7 @@ func (tv *TextView) SetSize() bool {
 	rndsz := tv.RenderSz
 	rndsz.X += tv.LineNoOff
 	netsz := mat32.Vec2{float32(tv.LinesSize.X) + tv.LineNoOff, float32(tv.LinesSize.Y)}
-	cursz := tv.LayState.Alloc.Size.SubScalar(2 * spc)
+	cursz := tv.LayState.Alloc.Size.Sub(spc.Size())
 	if cursz.X < 10 || cursz.Y < 10 {
 		nwsz := netsz.Max(rndsz)
 		tv.Size2DFromWH(nwsz.X, nwsz.Y)
@@ -2441,6 +2445,87 @@ func (tv *TextView) InsertAtCursor(txt []byte) {
 	tv.SetCursorCol(tv.CursorPos)
 }
 
+///////////////////////////////////////////////////////////
+//  IME text composition
+
+// SetPreedit sets the input method editor (IME) preedit text and cursor from
+// given ime.Preedit event: the preedit text is rendered inline at the cursor,
+// underlined, but it is not inserted into the buffer until it is committed.
+func (tv *TextView) SetPreedit(e *ime.Event) {
+	if !tv.Preedit.IsActive() && tv.HasSelection() {
+		tbe := tv.DeleteSelection()
+		tv.CursorPos = tbe.AdjustPos(tv.CursorPos, textbuf.AdjustPosDelStart)
+	}
+	tv.Preedit.Set(e)
+	sty := &tv.Sty
+	tv.PreeditRender.SetRunes(tv.Preedit.Text, &sty.Font, &sty.UnContext, &sty.Text, true, 0, 0)
+	tv.Preedit.Decorate(&tv.PreeditRender, 0, &tv.StateStyles[TextViewSel].Font.BgColor.Color)
+	tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
+	tv.RenderCursor(true)
+}
+
+// ClearPreedit removes any IME preedit text, e.g., when the composition
+// is cancelled.
+func (tv *TextView) ClearPreedit() {
+	if !tv.Preedit.IsActive() {
+		return
+	}
+	tv.Preedit.Reset()
+	tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
+	tv.RenderCursor(true)
+}
+
+// CommitPreedit replaces any IME preedit text with given final text,
+// which is inserted into the buffer at the cursor.
+func (tv *TextView) CommitPreedit(txt string) {
+	tv.ClearPreedit()
+	if txt != "" {
+		tv.InsertAtCursor([]byte(txt))
+	}
+}
+
+// CaretPos returns the render position of the caret, which is the
+// cursor position, offset by the caret position within any IME
+// preedit text.
+func (tv *TextView) CaretPos() mat32.Vec2 {
+	pos := tv.CharStartPos(tv.CursorPos)
+	if tv.Preedit.IsActive() {
+		rp, _, _, _ := tv.PreeditRender.RuneRelPos(tv.Preedit.Cursor)
+		pos.X += rp.X
+	}
+	return pos
+}
+
+// IMECaretRect returns the rectangle of the caret, in window coordinates,
+// for positioning the IME candidate window.
+func (tv *TextView) IMECaretRect() image.Rectangle {
+	cpos := tv.CaretPos().ToPointFloor().Add(tv.WinBBox.Min.Sub(tv.VpBBox.Min))
+	csz := image.Point{int(mat32.Ceil(tv.CursorWidth.Dots)), int(mat32.Ceil(tv.FontHeight))}
+	return image.Rectangle{Min: cpos, Max: cpos.Add(csz)}
+}
+
+// IMEInput handles IME text composition events
+func (tv *TextView) IMEInput(e *ime.Event) {
+	if tv.IsInactive() || tv.Buf == nil {
+		return
+	}
+	e.SetProcessed()
+	switch e.Action {
+	case ime.Start:
+		tv.CancelComplete()
+		tv.ClearPreedit()
+	case ime.Preedit:
+		tv.SetPreedit(e)
+	case ime.Commit:
+		tv.CommitPreedit(e.Text)
+	case ime.Cancel:
+		tv.ClearPreedit()
+	}
+	if win := tv.ParentWindow(); win != nil {
+		win.SetIMECaret(tv.IMECaretRect())
+	}
+}
+
 ///////////////////////////////////////////////////////////
 //  Rectangular regions
 
@@ -2954,7 +3039,7 @@ func (tv *TextView) ScrollToLeft(pos int) bool {
 func (tv *TextView) ScrollCursorToLeft() bool {
 	_, ri, _ := tv.WrappedLineNo(tv.CursorPos)
 	if ri <= 0 {
-		return tv.ScrollToLeft(tv.ObjBBox.Min.X - int(tv.Sty.BoxSpace()) - 2)
+		return tv.ScrollToLeft(tv.ObjBBox.Min.X - int(tv.Sty.BoxSpace().Left) - 2)
 	}
 	curBBox := tv.CursorBBox(tv.CursorPos)
 	return tv.ScrollToLeft(curBBox.Min.X)
@@ -3183,7 +3268,7 @@ func (tv *TextView) RenderCursor(on bool) {
 	} else {
 		win.InactivateSprite(sp.Name)
 	}
-	sp.Geom.Pos = tv.CharStartPos(tv.CursorPos).ToPointFloor()
+	sp.Geom.Pos = tv.CaretPos().ToPointFloor()
 	win.UpdateSig()
 }
 
@@ -3385,7 +3470,7 @@ func (tv *TextView) RenderRegionBoxSty(reg textbuf.Region, sty *gist.Style, bgcl
 	spc := sty.BoxSpace()
 
 	rst := tv.RenderStartPos()
-	ex := float32(tv.VpBBox.Max.X) - spc
+	ex := float32(tv.VpBBox.Max.X) - spc.Right
 	sx := rst.X + tv.LineNoOff
 
 	// fmt.Printf("select: %v -- %v\n", st, ed)
@@ -3435,7 +3520,7 @@ func (tv *TextView) RenderRegionToEnd(st lex.Pos, sty *gist.Style, bgclr *gist.C
 func (tv *TextView) RenderStartPos() mat32.Vec2 {
 	st := &tv.Sty
 	spc := st.BoxSpace()
-	pos := tv.LayState.Alloc.Pos.AddScalar(spc)
+	pos := tv.LayState.Alloc.Pos.Add(spc.Pos())
 	return pos
 }
 
@@ -3467,7 +3552,7 @@ func (tv *TextView) VisSizes() {
 	}
 	if lno {
 		tv.SetFlag(int(TextViewHasLineNos))
-		tv.LineNoOff = float32(tv.LineNoDigs+3)*sty.Font.Face.Metrics.Ch + spc // space for icon
+		tv.LineNoOff = float32(tv.LineNoDigs+3)*sty.Font.Face.Metrics.Ch + spc.Left // space for icon
 	} else {
 		tv.ClearFlag(int(TextViewHasLineNos))
 		tv.LineNoOff = 0
@@ -3505,7 +3590,7 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	tv.VisSizes()
 	pos := mat32.NewVec2FmPoint(tv.VpBBox.Min)
 	epos := mat32.NewVec2FmPoint(tv.VpBBox.Max)
-	pc.FillBox(rs, pos, epos.Sub(pos), &sty.Font.BgColor)
+	pc.FillBox(rs, pos, epos.Sub(pos), &tv.TransStyle(sty).Font.BgColor)
 	pos = tv.RenderStartPos()
 	stln := -1
 	edln := -1
@@ -3552,7 +3637,7 @@ func (tv *TextView) RenderAllLinesInBounds() {
 		lp := pos
 		lp.Y = lst
 		lp.X += tv.LineNoOff
-		tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
+		tv.RenderLine(rs, ln, lp) // not top pos -- already has baseline offset
 	}
 	rs.Unlock()
 	if tv.HasLineNos() {
@@ -3560,6 +3645,33 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	}
 }
 
+// RenderLine renders given line at given position, with any IME preedit
+// text inserted at the cursor, by shifting over the rest of the line after
+// the cursor (within its wrapped display line) to make room for it.
+func (tv *TextView) RenderLine(rs *girl.State, ln int, lp mat32.Vec2) {
+	tr := &tv.Renders[ln]
+	if !tv.Preedit.IsActive() || ln != tv.CursorPos.Ln || len(tv.PreeditRender.Spans) == 0 {
+		tr.Render(rs, lp)
+		return
+	}
+	wd := tv.PreeditRender.Spans[0].LastPos.X
+	si, ri, ok := tr.RuneSpanPos(tv.CursorPos.Ch)
+	if ok {
+		sr := &tr.Spans[si]
+		for i := ri; i < len(sr.Render); i++ {
+			sr.Render[i].RelPos.X += wd
+		}
+	}
+	tr.Render(rs, lp)
+	if ok {
+		sr := &tr.Spans[si]
+		for i := ri; i < len(sr.Render); i++ {
+			sr.Render[i].RelPos.X -= wd
+		}
+	}
+	tv.PreeditRender.RenderTopPos(rs, tv.CharStartPos(tv.CursorPos))
+}
+
 // RenderLineNosBoxAll renders the background for the line numbers in a darker shade
 func (tv *TextView) RenderLineNosBoxAll() {
 	if !tv.HasLineNos() {
@@ -3572,7 +3684,7 @@ func (tv *TextView) RenderLineNosBoxAll() {
 	clr := sty.Font.BgColor.Color.Highlight(10)
 	spos := mat32.NewVec2FmPoint(tv.VpBBox.Min)
 	epos := mat32.NewVec2FmPoint(tv.VpBBox.Max)
-	epos.X = spos.X + tv.LineNoOff - spc
+	epos.X = spos.X + tv.LineNoOff - spc.Left
 	pc.FillBoxColor(rs, spos, epos.Sub(spos), clr)
 }
 
@@ -3590,7 +3702,7 @@ func (tv *TextView) RenderLineNosBox(st, ed int) {
 	spos.X = float32(tv.VpBBox.Min.X)
 	epos := tv.CharEndPos(lex.Pos{Ln: ed + 1})
 	epos.Y -= tv.LineHeight
-	epos.X = spos.X + tv.LineNoOff - spc
+	epos.X = spos.X + tv.LineNoOff - spc.Left
 	// fmt.Printf("line box: st %v ed: %v spos %v  epos %v\n", st, ed, spos, epos)
 	pc.FillBoxColor(rs, spos, epos.Sub(spos), clr)
 }
@@ -3618,7 +3730,7 @@ func (tv *TextView) RenderLineNo(ln int, defFill bool, vpUpload bool) {
 	if ln < tv.NLines-1 {
 		ebox.Y -= tv.LineHeight
 	}
-	ebox.X = sbox.X + tv.LineNoOff - spc
+	ebox.X = sbox.X + tv.LineNoOff - spc.Left
 	bsz := ebox.Sub(sbox)
 	lclr, hasLClr := tv.Buf.LineColors[ln]
 	if tv.CursorPos.Ln == ln {
@@ -3647,7 +3759,7 @@ func (tv *TextView) RenderLineNo(ln int, defFill bool, vpUpload bool) {
 	pos := mat32.Vec2{}
 	lst := tv.CharStartPos(lex.Pos{Ln: ln}).Y // note: charstart pos includes descent
 	pos.Y = lst + mat32.FromFixed(sty.Font.Face.Face.Metrics().Ascent) - +mat32.FromFixed(sty.Font.Face.Face.Metrics().Descent)
-	pos.X = float32(tv.VpBBox.Min.X) + spc
+	pos.X = float32(tv.VpBBox.Min.X) + spc.Left
 
 	tv.LineNoRender.Render(rs, pos)
 	// todo: need an SvgRender interface that just takes an svg file or object
@@ -3764,7 +3876,7 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 			lp := pos
 			lp.Y = lst
 			lp.X += tv.LineNoOff
-			tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
+			tv.RenderLine(rs, ln, lp) // not top pos -- already has baseline offset
 		}
 		rs.Unlock()
 		if tv.HasLineNos() {
@@ -4032,6 +4144,7 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("TextView KeyInput: %v\n", tv.Path())
 	}
+	tv.ClearPreedit() // keys are normally consumed by the IME while composing
 	kf := gi.KeyFun(kt.Chord())
 	win := tv.ParentWindow()
 	tv.ClearScopelights()
@@ -4682,6 +4795,10 @@ func (tv *TextView) TextViewEvents() {
 		kt := d.(*key.ChordEvent)
 		txf.KeyInput(kt)
 	})
+	tv.ConnectEvent(oswin.IMEEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TextView).(*TextView)
+		txf.IMEInput(d.(*ime.Event))
+	})
 	if dlg, ok := tv.Viewport.This().(*gi.Dialog); ok {
 		dlg.DialogSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			txf, _ := recv.Embed(KiT_TextView).(*TextView)
@@ -4805,18 +4922,18 @@ func (tv *TextView) Render2D() {
 		tv.This().(gi.Node2D).ConnectEvents2D()
 		if tv.IsInactive() {
 			if tv.IsSelected() {
-				tv.Sty = tv.StateStyles[TextViewSel]
+				tv.SetCurStyle(&tv.StateStyles[TextViewSel])
 			} else {
-				tv.Sty = tv.StateStyles[TextViewInactive]
+				tv.SetCurStyle(&tv.StateStyles[TextViewInactive])
 			}
 		} else if tv.NLines == 0 {
-			tv.Sty = tv.StateStyles[TextViewInactive]
+			tv.SetCurStyle(&tv.StateStyles[TextViewInactive])
 		} else if tv.HasFocus() {
-			tv.Sty = tv.StateStyles[TextViewFocus]
+			tv.SetCurStyle(&tv.StateStyles[TextViewFocus])
 		} else if tv.IsSelected() {
-			tv.Sty = tv.StateStyles[TextViewSel]
+			tv.SetCurStyle(&tv.StateStyles[TextViewSel])
 		} else {
-			tv.Sty = tv.StateStyles[TextViewActive]
+			tv.SetCurStyle(&tv.StateStyles[TextViewActive])
 		}
 
 		tv.RenderAllLinesInBounds()
@@ -4851,6 +4968,7 @@ func (tv *TextView) FocusChanged2D(change gi.FocusChanges) {
 	switch change {
 	case gi.FocusLost:
 		tv.ClearFlag(int(TextViewFocusActive))
+		tv.Preedit.Reset()
 		// tv.EditDone()
 		tv.StopCursor() // make sure no cursor
 		tv.UpdateSig()
@@ -11,6 +11,7 @@ import (
 	"image/color"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -52,10 +53,16 @@ type TreeView struct {
 	WidgetSize       mat32.Vec2                  `desc:"just the size of our widget -- our alloc includes all of our children, but we only draw us"`
 	Icon             gi.IconName                 `json:"-" xml:"icon" view:"show-name" desc:"optional icon, displayed to the the left of the text label"`
 	RootView         *TreeView                   `json:"-" xml:"-" desc:"cached root of the view"`
+	openAnim         *gi.Anim
+	openFrac         float32
 }
 
 var KiT_TreeView = kit.Types.AddType(&TreeView{}, nil)
 
+// TreeViewOpenDur is the duration of the animation that reveals the
+// children of a node when it is opened -- set to 0 to open instantly
+var TreeViewOpenDur = 150 * time.Millisecond
+
 // AddNewTreeView adds a new treeview to given parent node, with given name.
 func AddNewTreeView(parent ki.Ki, name string) *TreeView {
 	tv := parent.AddNewChild(KiT_TreeView, name).(*TreeView)
@@ -869,6 +876,9 @@ func (tv *TreeView) MoveEndAction(selMode mouse.SelectModes) *TreeView {
 // Close closes the given node and updates the view accordingly (if it is not already closed)
 func (tv *TreeView) Close() {
 	if !tv.IsClosed() {
+		if tv.openAnim != nil {
+			tv.openAnim.Cancel()
+		}
 		updt := tv.UpdateStart()
 		if tv.HasChildren() {
 			tv.SetFullReRender()
@@ -888,6 +898,7 @@ func (tv *TreeView) Open() {
 		}
 		if tv.HasChildren() {
 			tv.SetClosedState(false)
+			tv.AnimateOpen()
 		}
 		tv.RootView.TreeViewSig.Emit(tv.RootView.This(), int64(TreeViewOpened), tv.This())
 		tv.UpdateEnd(updt)
@@ -897,6 +908,30 @@ func (tv *TreeView) Open() {
 	}
 }
 
+// AnimateOpen starts the animation that reveals the children of this node
+// after it has been opened, over TreeViewOpenDur, by growing the height
+// allocated to them from 0 to their full height.
+func (tv *TreeView) AnimateOpen() {
+	win := tv.ParentWindow()
+	if TreeViewOpenDur <= 0 || win == nil || tv.IsClosed() {
+		return
+	}
+	tv.openFrac = 0
+	an := &gi.Anim{Name: "open", Target: tv.This(), Duration: TreeViewOpenDur, Ease: gi.EaseOutCSS}
+	an.Update = func(t float32) {
+		tv.openFrac = t
+		updt := tv.UpdateStart()
+		tv.SetFullReRender()
+		tv.UpdateEnd(updt)
+	}
+	an.Done = func(completed bool) {
+		if tv.openAnim == an {
+			tv.openAnim = nil
+		}
+	}
+	tv.openAnim = win.Animate(an)
+}
+
 // ToggleClose toggles the close / open status: if closed, opens, and vice-versa
 func (tv *TreeView) ToggleClose() {
 	if tv.IsClosed() {
@@ -2161,14 +2196,19 @@ func (tv *TreeView) Size2D(iter int) {
 
 	if !tv.IsClosed() {
 		// we layout children under us
+		kh := float32(0)
 		for _, kid := range tv.Kids {
 			gis := kid.(gi.Node2D).AsWidget()
 			if gis == nil || gis.This() == nil {
 				continue
 			}
-			h += mat32.Ceil(gis.LayState.Alloc.Size.Y)
+			kh += mat32.Ceil(gis.LayState.Alloc.Size.Y)
 			w = mat32.Max(w, tv.Indent.Dots+gis.LayState.Alloc.Size.X)
 		}
+		if tv.openAnim != nil { // only part of the children are revealed
+			kh = mat32.Ceil(kh * tv.openFrac)
+		}
+		h += kh
 	}
 	tv.LayState.Alloc.Size = mat32.Vec2{w, h}
 	tv.WidgetSize.X = w // stretch
@@ -2176,9 +2216,9 @@ func (tv *TreeView) Size2D(iter int) {
 
 func (tv *TreeView) Layout2DParts(parBBox image.Rectangle, iter int) {
 	spc := tv.Sty.BoxSpace()
-	tv.Parts.LayState.Alloc.Pos = tv.LayState.Alloc.Pos.AddScalar(spc)
+	tv.Parts.LayState.Alloc.Pos = tv.LayState.Alloc.Pos.Add(spc.Pos())
 	tv.Parts.LayState.Alloc.PosOrig = tv.Parts.LayState.Alloc.Pos
-	tv.Parts.LayState.Alloc.Size = tv.WidgetSize.AddScalar(-2.0 * spc)
+	tv.Parts.LayState.Alloc.Size = tv.WidgetSize.Sub(spc.Size())
 	tv.Parts.Layout2D(parBBox, iter)
 }
 
@@ -2275,6 +2315,7 @@ func (tv *TreeView) PushBounds() bool {
 	}
 	rs := tv.Render()
 	rs.PushBounds(tv.VpBBox)
+	rs.PushList(tv.This())
 	tv.ConnectToViewport()
 	if gi.Render2DTrace {
 		fmt.Printf("Render: %v at %v\n", tv.Path(), tv.VpBBox)
@@ -2298,27 +2339,29 @@ func (tv *TreeView) Render2D() {
 		if !tv.VpBBox.Empty() { // we are root and just here for the connections :)
 			tv.UpdateInactive()
 			if tv.IsSelected() {
-				tv.Sty = tv.StateStyles[TreeViewSel]
+				tv.SetCurStyle(&tv.StateStyles[TreeViewSel])
 			} else if tv.HasFocus() {
-				tv.Sty = tv.StateStyles[TreeViewFocus]
+				tv.SetCurStyle(&tv.StateStyles[TreeViewFocus])
 			} else if tv.IsInactive() {
-				tv.Sty = tv.StateStyles[TreeViewInactive]
+				tv.SetCurStyle(&tv.StateStyles[TreeViewInactive])
 			} else {
-				tv.Sty = tv.StateStyles[TreeViewActive]
+				tv.SetCurStyle(&tv.StateStyles[TreeViewActive])
 			}
 			tv.ConfigPartsIfNeeded()
 			tv.This().(gi.Node2D).ConnectEvents2D()
 
 			// note: this is std except using WidgetSize instead of AllocSize
 			rs, pc, st := tv.RenderLock()
+			st = tv.TransStyle(st)
 			pc.FontStyle = st.Font
-			pc.StrokeStyle.SetColor(&st.Border.Color)
-			pc.StrokeStyle.Width = st.Border.Width
+			pc.StrokeStyle.SetColor(nil)
 			pc.FillStyle.SetColorSpec(&st.Font.BgColor)
 			// tv.RenderStdBox()
-			pos := tv.LayState.Alloc.Pos.AddScalar(st.Layout.Margin.Dots)
-			sz := tv.WidgetSize.AddScalar(-2.0 * st.Layout.Margin.Dots)
-			tv.RenderBoxImpl(pos, sz, st.Border.Radius.Dots)
+			mg := st.Layout.Margin.Dots()
+			pos := tv.LayState.Alloc.Pos.Add(mg.Pos())
+			sz := tv.WidgetSize.Sub(mg.Size())
+			tv.RenderBoxImpl(pos, sz, st.Border.Radius.Dots())
+			tv.RenderBorder(pos, sz, &st.Border)
 			tv.RenderUnlock(rs)
 			tv.Render2DParts()
 		}
@@ -188,6 +188,7 @@ func ToValueView(it interface{}, tags string) ValueView {
 
 	forceInline := false
 	forceNoInline := false
+	forceRange := false
 
 	tprops := kit.Types.Properties(typ, false) // don't make
 	if tprops != nil {
@@ -207,6 +208,8 @@ func ToValueView(it interface{}, tags string) ValueView {
 				forceInline = true
 			case "no-inline":
 				forceNoInline = true
+			case "range":
+				forceRange = true
 			}
 		}
 	}
@@ -312,6 +315,13 @@ func ToValueView(it interface{}, tags string) ValueView {
 			ki.InitNode(vv)
 			return vv
 		}
+		if forceRange {
+			if _, _, ok := RangeFields(nptyp); ok {
+				vv := &RangeValueView{}
+				ki.InitNode(vv)
+				return vv
+			}
+		}
 		nfld := kit.AllFieldsN(nptyp)
 		if nfld > 0 && !forceNoInline && (forceInline || nfld <= StructInlineLen) {
 			vv := &StructInlineValueView{}
@@ -941,6 +951,9 @@ func (vv *ValueViewBase) StdConfigWidget(widg gi.Node2D) {
 			nb.SetProp("max-width", units.NewCh(width))
 		}
 	}
+	if ovtag, ok := vv.Tag("text-overflow"); ok {
+		nb.SetProp("text-overflow", ovtag)
+	}
 	if heighttag, ok := vv.Tag("height"); ok {
 		height, ok := kit.ToFloat32(heighttag)
 		if ok {
@@ -998,6 +1011,39 @@ func (vi *ViewIFace) SetHiStyleDefault(hsty gi.HiStyleName) {
 	histyle.StyleDefault = hsty
 }
 
+func (vi *ViewIFace) SetHiStyleFromColors(name gi.HiStyleName, colors *gi.ColorPrefs, primary, secondary gist.Color) error {
+	if histyle.AvailStyles == nil {
+		histyle.Init()
+	}
+	hs := histyle.FromColors(colors, primary, secondary)
+	pruned := pruneThemeHiStyles(name)
+	if cur, has := histyle.CustomStyles[string(name)]; has && !pruned && reflect.DeepEqual(cur, hs) {
+		return nil // already saved
+	}
+	histyle.CustomStyles[string(name)] = hs
+	// save so that a ColorPrefs.HiStyle set to it still exists after restart
+	return histyle.CustomStyles.SavePrefs()
+}
+
+// pruneThemeHiStyles removes the highlighting styles generated by
+// ColorPrefs.GenerateTheme, other than given one, that are no longer used
+// by the color preferences or any of the color schemes, so they do not
+// accumulate in the custom styles -- returns true if any were removed
+func pruneThemeHiStyles(keep gi.HiStyleName) bool {
+	used := map[gi.HiStyleName]bool{keep: true, gi.Prefs.Colors.HiStyle: true}
+	for _, cp := range gi.Prefs.ColorSchemes {
+		used[cp.HiStyle] = true
+	}
+	pruned := false
+	for nm := range histyle.CustomStyles {
+		if strings.HasPrefix(nm, gi.ThemeHiStylePrefix) && !used[gi.HiStyleName(nm)] {
+			delete(histyle.CustomStyles, nm)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
 func (vi *ViewIFace) PrefsDetDefaults(pf *gi.PrefsDetailed) {
 	pf.TextViewClipHistMax = TextViewClipHistMax
 	pf.TextBufMaxScopeLines = TextBufMaxScopeLines
@@ -14,6 +14,7 @@ require (
 	github.com/fsnotify/fsnotify v1.6.0
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b
 	github.com/go-gl/mathgl v1.0.0
+	github.com/go-text/typesetting v0.2.1
 	github.com/goki/freetype v0.0.0-20220119013949-7a161fd3728c
 	github.com/goki/go-difflib v1.2.1
 	github.com/goki/gosl v1.0.6
@@ -31,7 +32,8 @@ require (
 	github.com/srwiley/scanx v0.0.0-20190309010443-e94503791388
 	golang.org/x/exp v0.0.0-20230105202349-8879d0199aa3
 	golang.org/x/image v0.3.0
-	golang.org/x/net v0.5.0
+	golang.org/x/net v0.6.0
+	golang.org/x/text v0.9.0
 )
 
 require (
@@ -47,8 +49,7 @@ require (
 	github.com/jinzhu/copier v0.3.5 // indirect
 	github.com/srwiley/oksvg v0.0.0-20220128195007-1f435e4c2b44 // indirect
 	github.com/srwiley/scanFT v0.0.0-20220128184157-0d1ee492111f // indirect
-	golang.org/x/mod v0.7.0 // indirect
-	golang.org/x/sys v0.4.0 // indirect
-	golang.org/x/text v0.6.0 // indirect
-	golang.org/x/tools v0.5.0 // indirect
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+	golang.org/x/tools v0.6.0 // indirect
 )
@@ -0,0 +1,68 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histyle
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/pi/token"
+)
+
+// FromColors returns a new highlighting style that matches given colors,
+// using the font and background colors for plain text, and tonal palettes
+// of the primary and secondary accent colors (and hue rotations of them)
+// for keywords, names, literals etc, at tones that are readable on the
+// background -- used by gi.ColorPrefs.GenerateTheme
+func FromColors(colors *gi.ColorPrefs, primary, secondary gist.Color) *Style {
+	dark := colors.Background.IsDark()
+	tone := float32(42)
+	if dark {
+		tone = 78
+	}
+	pp := gist.NewTonalPalette(primary).WithChroma(0.16)
+	sp := gist.NewTonalPalette(secondary).WithChroma(0.16)
+	tp := pp.WithHue(-120)
+	np := pp.WithChroma(0.02)
+	errp := gist.TonalPalette{Hue: 29, Chroma: 0.2}   // red
+	insp := gist.TonalPalette{Hue: 145, Chroma: 0.15} // green
+	accent := func(pal gist.TonalPalette) gist.Color {
+		c := pal.Tone(tone)
+		return c.ReadableOn(colors.Background, gist.ContrastAA)
+	}
+	comment := np.Tone(tone + 12)
+	if dark {
+		comment = np.Tone(tone - 18)
+	}
+	comment = comment.ReadableOn(colors.Background, gist.ContrastAALarge)
+
+	hs := Style{}
+	set := func(tag token.Tokens, se StyleEntry) {
+		hs[tag] = &se
+	}
+	set(token.Background, StyleEntry{Background: colors.Background})
+	set(token.Text, StyleEntry{Color: colors.Font})
+	set(token.Keyword, StyleEntry{Color: accent(pp), Bold: Yes})
+	set(token.KeywordType, StyleEntry{Color: accent(sp)})
+	set(token.NameType, StyleEntry{Color: accent(sp)})
+	set(token.NameClass, StyleEntry{Color: accent(sp), Bold: Yes})
+	set(token.NameFunction, StyleEntry{Color: accent(pp.WithHue(30))})
+	set(token.NameBuiltin, StyleEntry{Color: accent(tp)})
+	set(token.NameConstant, StyleEntry{Color: accent(tp)})
+	set(token.NameDecorator, StyleEntry{Color: accent(tp)})
+	set(token.Literal, StyleEntry{Color: accent(tp)})
+	set(token.LitStr, StyleEntry{Color: accent(sp.WithHue(60))})
+	set(token.LitNum, StyleEntry{Color: accent(tp)})
+	set(token.Operator, StyleEntry{Color: colors.Font})
+	set(token.OperatorWord, StyleEntry{Color: accent(pp), Bold: Yes})
+	set(token.Comment, StyleEntry{Color: comment, Italic: Yes})
+	set(token.CommentPreproc, StyleEntry{Color: accent(pp), Italic: No})
+	set(token.Error, StyleEntry{Color: accent(errp)})
+	set(token.TextStyleHeading, StyleEntry{Color: accent(pp), Bold: Yes})
+	set(token.TextStyleSubheading, StyleEntry{Color: accent(sp), Bold: Yes})
+	set(token.TextStyleInserted, StyleEntry{Color: accent(insp)})
+	set(token.TextStyleError, StyleEntry{Color: accent(errp)})
+	set(token.TextStyleLink, StyleEntry{Color: colors.Link, Underline: Yes})
+	return &hs
+}
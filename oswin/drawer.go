@@ -0,0 +1,90 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oswin
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Drawer is the image compositing system attached to a Window surface.
+// Images are uploaded into numbered slots (each of which can have multiple
+// layers), and then drawn onto the surface in between StartDraw and EndDraw
+// calls.  The GPU-based driver (vkos) implements this via vgpu vdraw.Drawer,
+// and other drivers (e.g., offscreen) can implement it entirely in memory.
+//
+// Image indexes are global, but are organized into sets of 16 for GPU
+// descriptor binding, and UseTextureSet must be called to select the set
+// containing the images used in subsequent Copy and Scale calls.
+type Drawer interface {
+
+	// SetMaxTextures updates the max number of textures for drawing.
+	// Must call this prior to doing any allocation of images.
+	SetMaxTextures(maxTextures int)
+
+	// DestBounds returns the bounds of the render destination (i.e., the
+	// window surface), in raw pixels.
+	DestBounds() image.Rectangle
+
+	// SetGoImage sets given Go image as a drawing source to given image index
+	// and layer.  Image is copied into the Drawer, so it can be further
+	// modified by the caller.  flipY is whether to flip the Y axis.
+	SetGoImage(idx, layer int, img image.Image, flipY bool)
+
+	// ConfigImageDefaultFormat configures the image at given index
+	// to have given size and number of layers, using the standard
+	// RGBA format.  Must call SyncImages after configuring images.
+	ConfigImageDefaultFormat(idx int, width int, height int, layers int)
+
+	// IsImageActive returns true if the image at given index has been set.
+	IsImageActive(idx int) bool
+
+	// SyncImages must be called after images have been updated, to sync
+	// memory up to the GPU (or equivalent).
+	SyncImages()
+
+	// UseTextureSet selects the descriptor set to use -- choose this prior
+	// to a given set of Copy / Scale calls.
+	UseTextureSet(descIdx int)
+
+	// StartDraw starts image drawing rendering process on render target.
+	// No images can be added or set after this point.
+	// descIdx is the descriptor set to use -- choose this based on
+	// the bank of 16 texture values if number of textures > MaxTexturesPerSet.
+	StartDraw(descIdx int)
+
+	// EndDraw ends image drawing rendering process on render target
+	EndDraw()
+
+	// Copy copies texture at given index and layer to render target.
+	// dp is the destination point,
+	// sr is the source region (set to image.ZR zero rect for all),
+	// op is the drawing operation: Src = copy source directly (blit),
+	// Over = alpha blend with existing
+	// flipY = flipY axis when drawing this image
+	Copy(idx, layer int, dp image.Point, sr image.Rectangle, op draw.Op, flipY bool) error
+
+	// Scale copies texture at given index and layer to render target,
+	// scaling the region defined by src and sr to the destination
+	// such that sr in src-space is mapped to dr in dst-space.
+	// dr is the destination rectangle
+	// sr is the source region (set to image.ZR zero rect for all),
+	// op is the drawing operation: Src = copy source directly (blit),
+	// Over = alpha blend with existing
+	// flipY = flipY axis when drawing this image
+	Scale(idx, layer int, dr image.Rectangle, sr image.Rectangle, op draw.Op, flipY bool) error
+
+	// StartFill starts color fill drawing rendering process on render target
+	StartFill()
+
+	// EndFill ends color filling rendering process on render target
+	EndFill()
+
+	// FillRect fills given color to to render target, to given region.
+	// op is the drawing operation: Src = copy source directly (blit),
+	// Over = alpha blend with existing
+	FillRect(clr color.Color, reg image.Rectangle, op draw.Op) error
+}
@@ -8,9 +8,24 @@
 // license that can be found in the LICENSE file.
 
 // Package driver provides the default driver for accessing a screen.
+//
+// The offscreen driver, which renders windows in memory without any
+// display or GPU, is used instead of the default vkos driver when
+// building with the offscreen build tag (which excludes the vkos driver
+// entirely), or when the OffscreenEnv environment variable is set to
+// a true value.
 package driver
 
-import "github.com/goki/gi/oswin"
+import (
+	"os"
+	"strconv"
+
+	"github.com/goki/gi/oswin"
+)
+
+// OffscreenEnv is the name of the environment variable that selects the
+// offscreen driver when set to a true value (1, t, true, etc).
+const OffscreenEnv = "GOGI_OFFSCREEN"
 
 // TODO: figure out what to say about the responsibility for users of this
 // package to check any implicit dependencies' LICENSEs. For example, the
@@ -26,3 +41,10 @@ import "github.com/goki/gi/oswin"
 func Main(f func(oswin.App)) {
 	driverMain(f)
 }
+
+// UseOffscreen returns true if the OffscreenEnv environment variable
+// requests the offscreen driver.
+func UseOffscreen() bool {
+	use, _ := strconv.ParseBool(os.Getenv(OffscreenEnv))
+	return use
+}
@@ -7,13 +7,20 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build !offscreen
+
 package driver
 
 import (
 	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/offscreen"
 	"github.com/goki/gi/oswin/driver/vkos"
 )
 
 func driverMain(f func(oswin.App)) {
+	if UseOffscreen() {
+		offscreen.Main(f)
+		return
+	}
 	vkos.Main(f)
 }
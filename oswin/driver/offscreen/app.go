@@ -0,0 +1,410 @@
+// Copyright 2023 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/clip"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/window"
+)
+
+var (
+	// ScreenSize is the size of the single virtual screen, in pixels.
+	// Must be set prior to calling Main.
+	ScreenSize = image.Point{1920, 1080}
+
+	// ScreenDPI is the physical DPI of the virtual screen.  The logical DPI
+	// is computed from this using the standard oswin LogicalDPIScale and
+	// ZoomFactor.  Must be set prior to calling Main.
+	ScreenDPI = float32(96)
+
+	// PrefsDir is the preferences directory returned by App.PrefsDir.
+	// If empty, a gogi-offscreen directory in the system temporary
+	// directory is used, so that rendering does not depend on the
+	// preferences of the user running the program.
+	PrefsDir = ""
+)
+
+var theApp = &appImpl{
+	winlist:      make([]*windowImpl, 0),
+	screens:      make([]*oswin.Screen, 0),
+	name:         "GoGi",
+	quitCloseCnt: make(chan struct{}),
+}
+
+type appImpl struct {
+	mu            sync.Mutex
+	mainQueue     chan funcRun
+	mainDone      chan struct{}
+	winlist       []*windowImpl
+	screens       []*oswin.Screen
+	ctxtwin       *windowImpl // context window, dynamically set, for e.g., pointer and other methods
+	name          string
+	about         string
+	openFiles     []string
+	quitting      bool          // set to true when quitting and closing windows
+	quitCloseCnt  chan struct{} // counts windows to make sure all are closed before done
+	quitReqFunc   func()
+	quitCleanFunc func()
+}
+
+var mainCallback func(oswin.App)
+
+// Main is called from main thread when it is time to start running the
+// main loop.  When function f returns, the app ends automatically.
+func Main(f func(oswin.App)) {
+	mainCallback = f
+	theApp.mainQueue = make(chan funcRun)
+	theApp.mainDone = make(chan struct{})
+	theApp.GetScreens()
+	oswin.TheApp = theApp
+	go func() {
+		mainCallback(theApp)
+		theApp.stopMain()
+	}()
+	theApp.mainLoop()
+}
+
+type funcRun struct {
+	f    func()
+	done chan bool
+}
+
+// RunOnMain runs given function on main thread
+func (app *appImpl) RunOnMain(f func()) {
+	if app.mainQueue == nil {
+		f()
+	} else {
+		done := make(chan bool)
+		app.mainQueue <- funcRun{f: f, done: done}
+		<-done
+	}
+}
+
+// GoRunOnMain runs given function on main thread and returns immediately
+func (app *appImpl) GoRunOnMain(f func()) {
+	go func() {
+		app.mainQueue <- funcRun{f: f, done: nil}
+	}()
+}
+
+// SendEmptyEvent sends an empty, blank event to global event processing
+// system, which has the effect of pushing the system along during cases when
+// the event loop needs to be "pinged" to get things moving along..
+func (app *appImpl) SendEmptyEvent() {
+	// nop: there are no OS events to wait for
+}
+
+// PollEvents tells the main event loop to check for any gui events right now.
+// Call this periodically from longer-running functions to ensure
+// GUI responsiveness.
+func (app *appImpl) PollEvents() {
+	// nop: there are no OS events to poll
+}
+
+// mainLoop starts running event loop on main thread (must be called
+// from the main thread).
+func (app *appImpl) mainLoop() {
+	for {
+		select {
+		case <-app.mainDone:
+			return
+		case f := <-app.mainQueue:
+			f.f()
+			if f.done != nil {
+				f.done <- true
+			}
+		}
+	}
+}
+
+// stopMain stops the main loop and thus terminates the app
+func (app *appImpl) stopMain() {
+	app.mainDone <- struct{}{}
+}
+
+////////////////////////////////////////////////////////
+//  Window
+
+func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error) {
+	if len(app.winlist) == 0 && oswin.InitScreenLogicalDPIFunc != nil {
+		oswin.InitScreenLogicalDPIFunc()
+	}
+
+	sc := app.screens[0]
+
+	if opts == nil {
+		opts = &oswin.NewWindowOptions{}
+	}
+	opts.Fixup()
+
+	w := &windowImpl{
+		app:      app,
+		runQueue: make(chan funcRun),
+		winClose: make(chan struct{}),
+		WindowBase: oswin.WindowBase{
+			Titl:        opts.GetTitle(),
+			Flag:        opts.Flags,
+			Pos:         opts.Pos,
+			WnSize:      sc.WinSizeFmPix(opts.Size),
+			PxSize:      opts.Size,
+			DevPixRatio: sc.DevicePixelRatio,
+			PhysDPI:     sc.PhysicalDPI,
+			LogDPI:      sc.LogicalDPI,
+		},
+	}
+	w.draw.setSize(w.PxSize)
+
+	app.mu.Lock()
+	for _, ow := range app.winlist {
+		ow.setFocus(false)
+	}
+	app.winlist = append(app.winlist, w)
+	app.mu.Unlock()
+
+	go w.winLoop() // start window's own dedicated run loop
+
+	w.sendWindowEvent(window.Resize)
+	w.sendWindowEvent(window.Paint)
+	w.setFocus(true) // starts out focused
+	w.sendWindowEvent(window.Show)
+
+	return w, nil
+}
+
+func (app *appImpl) DeleteWin(w *windowImpl) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for i, wl := range app.winlist {
+		if wl == w {
+			app.winlist = append(app.winlist[:i], app.winlist[i+1:]...)
+			break
+		}
+	}
+	if app.ctxtwin == w {
+		app.ctxtwin = nil
+	}
+}
+
+// GetScreens configures the single virtual screen from ScreenSize
+// and ScreenDPI.
+func (app *appImpl) GetScreens() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sc := &oswin.Screen{
+		Name:             "Offscreen",
+		Geometry:         image.Rectangle{Max: ScreenSize},
+		DevicePixelRatio: 1,
+		PixSize:          ScreenSize,
+		PhysicalSize:     image.Point{int(25.4 * float32(ScreenSize.X) / ScreenDPI), int(25.4 * float32(ScreenSize.Y) / ScreenDPI)},
+		PhysicalDPI:      ScreenDPI,
+		Depth:            24,
+		RefreshRate:      60,
+	}
+	sc.UpdateLogicalDPI()
+	app.screens = []*oswin.Screen{sc}
+}
+
+func (app *appImpl) NScreens() int {
+	return len(app.screens)
+}
+
+func (app *appImpl) Screen(scrN int) *oswin.Screen {
+	sz := len(app.screens)
+	if scrN < sz {
+		return app.screens[scrN]
+	}
+	return nil
+}
+
+func (app *appImpl) ScreenByName(name string) *oswin.Screen {
+	for _, sc := range app.screens {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) NoScreens() bool {
+	return false
+}
+
+func (app *appImpl) NWindows() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return len(app.winlist)
+}
+
+func (app *appImpl) Window(win int) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sz := len(app.winlist)
+	if win < sz {
+		return app.winlist[win]
+	}
+	return nil
+}
+
+func (app *appImpl) WindowByName(name string) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.Name() == name {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) WindowInFocus() oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.IsFocus() {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) ContextWindow() oswin.Window {
+	app.mu.Lock()
+	cw := app.ctxtwin
+	app.mu.Unlock()
+	return cw
+}
+
+func (app *appImpl) Platform() oswin.Platforms {
+	switch runtime.GOOS {
+	case "darwin":
+		return oswin.MacOS
+	case "windows":
+		return oswin.Windows
+	}
+	return oswin.LinuxX11
+}
+
+func (app *appImpl) Name() string {
+	return app.name
+}
+
+func (app *appImpl) SetName(name string) {
+	app.name = name
+}
+
+func (app *appImpl) About() string {
+	return app.about
+}
+
+func (app *appImpl) SetAbout(about string) {
+	app.about = about
+}
+
+func (app *appImpl) OpenFiles() []string {
+	return app.openFiles
+}
+
+func (app *appImpl) OpenURL(url string) {
+	// nop: no browser available
+}
+
+func (app *appImpl) FontPaths() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/System/Library/Fonts", "/Library/Fonts"}
+	case "windows":
+		return []string{"C:\\Windows\\Fonts"}
+	}
+	return []string{"/usr/share/fonts/truetype"}
+}
+
+func (app *appImpl) PrefsDir() string {
+	if PrefsDir != "" {
+		return PrefsDir
+	}
+	return filepath.Join(os.TempDir(), "gogi-offscreen")
+}
+
+func (app *appImpl) GoGiPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), "GoGi")
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) AppPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), app.Name())
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) ClipBoard(win oswin.Window) clip.Board {
+	app.mu.Lock()
+	app.ctxtwin = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theClip
+}
+
+func (app *appImpl) Cursor(win oswin.Window) cursor.Cursor {
+	app.mu.Lock()
+	app.ctxtwin = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theCursor
+}
+
+func (app *appImpl) SetQuitReqFunc(fun func()) {
+	app.quitReqFunc = fun
+}
+
+func (app *appImpl) SetQuitCleanFunc(fun func()) {
+	app.quitCleanFunc = fun
+}
+
+func (app *appImpl) QuitReq() {
+	if app.quitting {
+		return
+	}
+	if app.quitReqFunc != nil {
+		app.quitReqFunc()
+	} else {
+		app.Quit()
+	}
+}
+
+func (app *appImpl) IsQuitting() bool {
+	return app.quitting
+}
+
+func (app *appImpl) QuitClean() {
+	app.quitting = true
+	if app.quitCleanFunc != nil {
+		app.quitCleanFunc()
+	}
+	app.mu.Lock()
+	nwin := len(app.winlist)
+	for i := nwin - 1; i >= 0; i-- {
+		win := app.winlist[i]
+		go win.Close()
+	}
+	app.mu.Unlock()
+	for i := 0; i < nwin; i++ {
+		<-app.quitCloseCnt
+	}
+}
+
+func (app *appImpl) Quit() {
+	if app.quitting {
+		return
+	}
+	app.QuitClean()
+	app.stopMain()
+}
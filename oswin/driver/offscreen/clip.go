@@ -0,0 +1,129 @@
+// Copyright 2023 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"sync"
+
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+/////////////////////////////////////////////////////////////////
+//   Clipboard
+
+// clipImpl is an in-memory clipboard, shared by all windows
+type clipImpl struct {
+	data mimedata.Mimes
+	mu   sync.Mutex
+}
+
+var theClip = clipImpl{}
+
+func (ci *clipImpl) IsEmpty() bool {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return len(ci.data) == 0
+}
+
+func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(ci.data) == 0 || len(types) == 0 {
+		return nil
+	}
+	for _, typ := range types {
+		for _, d := range ci.data {
+			if d.Type == typ {
+				return ci.data
+			}
+		}
+	}
+	if mimedata.IsText(types[0]) {
+		for _, d := range ci.data {
+			if mimedata.IsText(d.Type) {
+				return ci.data
+			}
+		}
+	}
+	return nil
+}
+
+func (ci *clipImpl) Write(data mimedata.Mimes) error {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.data = make(mimedata.Mimes, len(data))
+	for i, d := range data {
+		ci.data[i] = &mimedata.Data{Type: d.Type, Data: append([]byte(nil), d.Data...)}
+	}
+	return nil
+}
+
+func (ci *clipImpl) Clear() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.data = nil
+}
+
+//////////////////////////////////////////////////////
+//  Cursor
+
+// cursorImpl maintains the cursor state without any display
+type cursorImpl struct {
+	cursor.CursorBase
+	mu sync.Mutex
+}
+
+var theCursor = cursorImpl{CursorBase: cursor.CursorBase{Vis: true}}
+
+func (c *cursorImpl) Set(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.Cur = sh
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Push(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.PushStack(sh)
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Pop() {
+	c.mu.Lock()
+	c.PopStack()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Hide() {
+	c.mu.Lock()
+	c.Vis = false
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Show() {
+	c.mu.Lock()
+	c.Vis = true
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) PushIfNot(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Cur == sh {
+		return false
+	}
+	c.PushStack(sh)
+	return true
+}
+
+func (c *cursorImpl) PopIf(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Cur == sh {
+		c.PopStack()
+		return true
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+// Copyright 2023 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package offscreen provides an oswin driver that renders windows
+// entirely in memory, without any display or GPU device.  It is intended
+// for running GoGi apps in CI and on build servers, e.g., for testing.
+//
+// Each Window composites its Viewport2D, popups and sprites into an
+// image.RGBA, which is available via the Image method of the Window
+// interface defined here:
+//
+//	img := win.OSWin.(offscreen.Window).Image()
+//
+// There is a single virtual screen, configured by ScreenSize and ScreenDPI,
+// and the clipboard and cursor are maintained in memory.  3D Scenes
+// (gi3d) require a GPU and are not rendered.
+//
+// The offscreen driver is selected in the oswin/driver package by building
+// with the offscreen build tag, or by setting the GOGI_OFFSCREEN
+// environment variable to a true value (e.g., 1).
+package offscreen
@@ -0,0 +1,253 @@
+// Copyright 2023 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/draw"
+)
+
+// drawerImpl implements oswin.Drawer by compositing the images
+// entirely in memory onto an image.RGBA render target.
+// Drawing happens on a back buffer, which is copied to the
+// front buffer (returned by Image) at EndDraw and EndFill.
+type drawerImpl struct {
+	images [][]*image.RGBA // images by index and then layer
+	back   *image.RGBA     // render target drawn into between Start / End
+	front  *image.RGBA     // last completed render
+	mu     sync.Mutex      // protects all of the above
+}
+
+// setSize sets the size of the render target, preserving any
+// existing content that still fits.
+func (dw *drawerImpl) setSize(sz image.Point) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if dw.back != nil && dw.back.Bounds().Size() == sz {
+		return
+	}
+	nb := image.NewRGBA(image.Rectangle{Max: sz})
+	nf := image.NewRGBA(image.Rectangle{Max: sz})
+	if dw.back != nil {
+		draw.Draw(nb, nb.Bounds(), dw.back, image.ZP, draw.Src)
+		draw.Draw(nf, nf.Bounds(), dw.front, image.ZP, draw.Src)
+	}
+	dw.back = nb
+	dw.front = nf
+}
+
+// image returns a copy of the last completed render
+func (dw *drawerImpl) image() *image.RGBA {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if dw.front == nil {
+		return nil
+	}
+	img := image.NewRGBA(dw.front.Bounds())
+	copy(img.Pix, dw.front.Pix)
+	return img
+}
+
+// publish copies the back buffer to the front -- must be locked
+func (dw *drawerImpl) publish() {
+	copy(dw.front.Pix, dw.back.Pix)
+}
+
+// imageAt returns the image at given index and layer -- nil if not set.
+// must be locked.
+func (dw *drawerImpl) imageAt(idx, layer int) *image.RGBA {
+	if idx < 0 || idx >= len(dw.images) {
+		return nil
+	}
+	ly := dw.images[idx]
+	if layer < 0 || layer >= len(ly) {
+		return nil
+	}
+	return ly[layer]
+}
+
+func (dw *drawerImpl) SetMaxTextures(maxTextures int) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if len(dw.images) >= maxTextures {
+		return
+	}
+	ni := make([][]*image.RGBA, maxTextures)
+	copy(ni, dw.images)
+	dw.images = ni
+}
+
+func (dw *drawerImpl) DestBounds() image.Rectangle {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if dw.back == nil {
+		return image.Rectangle{}
+	}
+	return dw.back.Bounds()
+}
+
+func (dw *drawerImpl) SetGoImage(idx, layer int, img image.Image, flipY bool) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if idx < 0 || idx >= len(dw.images) || layer < 0 {
+		return
+	}
+	ly := dw.images[idx]
+	if layer >= len(ly) {
+		nl := make([]*image.RGBA, layer+1)
+		copy(nl, ly)
+		ly = nl
+		dw.images[idx] = ly
+	}
+	ib := img.Bounds()
+	rgba := ly[layer]
+	if rgba == nil || rgba.Bounds().Size() != ib.Size() {
+		rgba = image.NewRGBA(image.Rectangle{Max: ib.Size()})
+		ly[layer] = rgba
+	}
+	if simg, ok := img.(*image.RGBA); ok {
+		// as in vgpu, the Pix of an image.RGBA are assumed to start at 0,0
+		// regardless of Rect.Min, so that a sub-region can be set by just
+		// changing the Rect (as in gi.Window.UploadVpRegion)
+		rsz := ib.Dx() * 4
+		sti := ib.Min.Y*simg.Stride + ib.Min.X*4
+		for y := 0; y < ib.Dy(); y++ {
+			si := sti + y*simg.Stride
+			copy(rgba.Pix[y*rgba.Stride:y*rgba.Stride+rsz], simg.Pix[si:si+rsz])
+		}
+	} else {
+		draw.Draw(rgba, rgba.Bounds(), img, ib.Min, draw.Src)
+	}
+	if flipY {
+		flipImageY(rgba)
+	}
+}
+
+func (dw *drawerImpl) ConfigImageDefaultFormat(idx int, width int, height int, layers int) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if idx < 0 || idx >= len(dw.images) {
+		return
+	}
+	ly := make([]*image.RGBA, layers)
+	for i := range ly {
+		ly[i] = image.NewRGBA(image.Rect(0, 0, width, height))
+	}
+	dw.images[idx] = ly
+}
+
+func (dw *drawerImpl) IsImageActive(idx int) bool {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	return dw.imageAt(idx, 0) != nil
+}
+
+func (dw *drawerImpl) SyncImages() {
+	// nop: images are already in memory
+}
+
+func (dw *drawerImpl) UseTextureSet(descIdx int) {
+	// nop: all images are always available
+}
+
+func (dw *drawerImpl) StartDraw(descIdx int) {
+	// nop: back buffer retains prior content, as for GPU surface
+}
+
+func (dw *drawerImpl) EndDraw() {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	dw.publish()
+}
+
+func (dw *drawerImpl) Copy(idx, layer int, dp image.Point, sr image.Rectangle, op draw.Op, flipY bool) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	src := dw.imageAt(idx, layer)
+	if src == nil || dw.back == nil {
+		return nil
+	}
+	if sr == image.ZR {
+		sr = src.Bounds()
+	}
+	var simg image.Image = src
+	if flipY {
+		simg = flippedSubImage(src, sr)
+		sr = simg.Bounds()
+	}
+	dr := image.Rectangle{Min: dp, Max: dp.Add(sr.Size())}
+	draw.Draw(dw.back, dr, simg, sr.Min, op)
+	return nil
+}
+
+func (dw *drawerImpl) Scale(idx, layer int, dr image.Rectangle, sr image.Rectangle, op draw.Op, flipY bool) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	src := dw.imageAt(idx, layer)
+	if src == nil || dw.back == nil {
+		return nil
+	}
+	if sr == image.ZR {
+		sr = src.Bounds()
+	}
+	var simg image.Image = src
+	if flipY {
+		simg = flippedSubImage(src, sr)
+		sr = simg.Bounds()
+	}
+	if dr.Size() == sr.Size() {
+		draw.Draw(dw.back, dr, simg, sr.Min, op)
+		return nil
+	}
+	draw.ApproxBiLinear.Scale(dw.back, dr, simg, sr, op, nil)
+	return nil
+}
+
+func (dw *drawerImpl) StartFill() {
+	// nop: back buffer retains prior content, as for GPU surface
+}
+
+func (dw *drawerImpl) EndFill() {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	dw.publish()
+}
+
+func (dw *drawerImpl) FillRect(clr color.Color, reg image.Rectangle, op draw.Op) error {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if dw.back == nil {
+		return nil
+	}
+	draw.Draw(dw.back, reg, &image.Uniform{clr}, image.ZP, op)
+	return nil
+}
+
+// flipImageY flips the given image in place along the Y axis
+func flipImageY(img *image.RGBA) {
+	sz := img.Bounds().Size()
+	rowb := sz.X * 4
+	tmp := make([]byte, rowb)
+	for y := 0; y < sz.Y/2; y++ {
+		top := img.Pix[y*img.Stride : y*img.Stride+rowb]
+		by := sz.Y - 1 - y
+		bot := img.Pix[by*img.Stride : by*img.Stride+rowb]
+		copy(tmp, top)
+		copy(top, bot)
+		copy(bot, tmp)
+	}
+}
+
+// flippedSubImage returns a copy of the given region of the image,
+// flipped along the Y axis, with bounds starting at 0,0
+func flippedSubImage(img *image.RGBA, sr image.Rectangle) *image.RGBA {
+	fi := image.NewRGBA(image.Rectangle{Max: sr.Size()})
+	draw.Draw(fi, fi.Bounds(), img, sr.Min, draw.Src)
+	flipImageY(fi)
+	return fi
+}
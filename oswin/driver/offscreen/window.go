@@ -0,0 +1,312 @@
+// Copyright 2023 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"image"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/event"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+)
+
+// Window is the interface implemented by offscreen oswin.Window's,
+// providing access to the rendered contents of the window.
+type Window interface {
+	oswin.Window
+
+	// Image returns a copy of the most recently rendered window image,
+	// including all popups and sprites -- nil if nothing rendered yet.
+	Image() *image.RGBA
+}
+
+type windowImpl struct {
+	oswin.WindowBase
+	event.Deque
+	app            *appImpl
+	draw           drawerImpl
+	runQueue       chan funcRun
+	winClose       chan struct{}
+	closed         bool
+	mu             sync.Mutex
+	closeReqFunc   func(win oswin.Window)
+	closeCleanFunc func(win oswin.Window)
+}
+
+// Handle returns the driver-specific handle for this window,
+// which is nil for the offscreen driver.
+func (w *windowImpl) Handle() interface{} {
+	return nil
+}
+
+func (w *windowImpl) OSHandle() uintptr {
+	return 0
+}
+
+func (w *windowImpl) Drawer() oswin.Drawer {
+	return &w.draw
+}
+
+func (w *windowImpl) Image() *image.RGBA {
+	return w.draw.image()
+}
+
+func (w *windowImpl) MainMenu() oswin.MainMenu {
+	return nil
+}
+
+func (w *windowImpl) IsClosed() bool {
+	if w == nil {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+func (w *windowImpl) IsVisible() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.closed && !w.IsMinimized()
+}
+
+// for sending window.Event's
+func (w *windowImpl) sendWindowEvent(act window.Actions) {
+	winEv := window.Event{
+		Action: act,
+	}
+	winEv.Init()
+	w.Send(&winEv)
+}
+
+// winLoop is the window's own locked processing loop.
+func (w *windowImpl) winLoop() {
+	for {
+		select {
+		case <-w.winClose:
+			return
+		case f := <-w.runQueue:
+			f.f()
+			if f.done != nil {
+				f.done <- true
+			}
+		}
+	}
+}
+
+// RunOnWin runs given function on the window's unique locked thread.
+func (w *windowImpl) RunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	done := make(chan bool)
+	w.runQueue <- funcRun{f: f, done: done}
+	<-done
+}
+
+// GoRunOnWin runs given function on window's unique locked thread and returns immediately
+func (w *windowImpl) GoRunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	go func() {
+		w.runQueue <- funcRun{f: f, done: nil}
+	}()
+}
+
+// SendEmptyEvent sends an empty, blank event to this window, which just has
+// the effect of pushing the system along during cases when the window
+// event loop needs to be "pinged" to get things moving along..
+func (w *windowImpl) SendEmptyEvent() {
+	if w.IsClosed() {
+		return
+	}
+	oswin.SendCustomEvent(w, nil)
+}
+
+////////////////////////////////////////////////////////////
+//  Geom etc
+
+func (w *windowImpl) Screen() *oswin.Screen {
+	return w.app.screens[0]
+}
+
+func (w *windowImpl) Size() image.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PxSize
+}
+
+func (w *windowImpl) WinSize() image.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.WnSize
+}
+
+func (w *windowImpl) Position() image.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Pos
+}
+
+func (w *windowImpl) PhysicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PhysDPI
+}
+
+func (w *windowImpl) LogicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.LogDPI
+}
+
+func (w *windowImpl) SetLogicalDPI(dpi float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.LogDPI = dpi
+}
+
+func (w *windowImpl) SetTitle(title string) {
+	w.Titl = title
+}
+
+func (w *windowImpl) SetWinSize(sz image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	sc := w.Screen()
+	w.mu.Lock()
+	w.WnSize = sz
+	w.PxSize = sc.WinSizeToPix(sz)
+	w.draw.setSize(w.PxSize)
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Resize)
+}
+
+func (w *windowImpl) SetSize(sz image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	sc := w.Screen()
+	w.SetWinSize(sc.WinSizeFmPix(sz))
+}
+
+func (w *windowImpl) SetPos(pos image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	w.mu.Lock()
+	w.Pos = pos
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Move)
+}
+
+func (w *windowImpl) SetGeom(pos image.Point, sz image.Point) {
+	w.SetSize(sz)
+	w.SetPos(pos)
+}
+
+// setFocus sets the focus state of the window, sending the
+// corresponding window event if it changes.
+func (w *windowImpl) setFocus(focused bool) {
+	if focused == w.IsFocus() {
+		return
+	}
+	if focused {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Minimized))
+		bitflag.SetAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.Focus)
+	} else {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.DeFocus)
+	}
+}
+
+func (w *windowImpl) Raise() {
+	if w.IsClosed() {
+		return
+	}
+	w.app.mu.Lock()
+	for _, ow := range w.app.winlist {
+		if ow != w {
+			ow.setFocus(false)
+		}
+	}
+	w.app.mu.Unlock()
+	w.setFocus(true)
+}
+
+func (w *windowImpl) Minimize() {
+	if w.IsClosed() {
+		return
+	}
+	bitflag.SetAtomic(&w.Flag, int(oswin.Minimized))
+	w.setFocus(false)
+	w.sendWindowEvent(window.Minimize)
+}
+
+func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeReqFunc = fun
+}
+
+func (w *windowImpl) SetCloseCleanFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeCleanFunc = fun
+}
+
+func (w *windowImpl) CloseReq() {
+	if theApp.quitting {
+		w.Close()
+		return
+	}
+	if w.closeReqFunc != nil {
+		w.closeReqFunc(w)
+	} else {
+		w.Close()
+	}
+}
+
+func (w *windowImpl) CloseClean() {
+	if w.closeCleanFunc != nil {
+		w.closeCleanFunc(w)
+	}
+}
+
+func (w *windowImpl) Close() {
+	// this is actually the final common pathway for closing here
+	if w.IsClosed() {
+		return
+	}
+	w.winClose <- struct{}{} // break out of run loop
+	w.CloseClean()
+	w.sendWindowEvent(window.Close)
+	theApp.DeleteWin(w)
+	if w.DestroyGPUfunc != nil {
+		w.DestroyGPUfunc()
+	}
+	w.mu.Lock()
+	w.closed = true // marks as closed for all other calls
+	w.mu.Unlock()
+	if theApp.quitting {
+		theApp.quitCloseCnt <- struct{}{}
+	}
+}
+
+func (w *windowImpl) SetMousePos(x, y float64) {
+	// nop: there is no mouse
+}
+
+func (w *windowImpl) SetCursorEnabled(enabled, raw bool) {
+	// nop: there is no mouse cursor
+}
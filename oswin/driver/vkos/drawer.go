@@ -0,0 +1,40 @@
+// Copyright 2023 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vkos
+
+import (
+	"image"
+
+	"github.com/goki/vgpu/vdraw"
+	"github.com/goki/vgpu/vgpu"
+)
+
+// drawerImpl implements oswin.Drawer using the vgpu vdraw.Drawer,
+// which renders directly to the window Surface.
+type drawerImpl struct {
+	vdraw.Drawer
+}
+
+// VkDrawer returns the underlying vdraw.Drawer, for code such as gi3d
+// that needs direct access to the GPU system.
+func (dw *drawerImpl) VkDrawer() *vdraw.Drawer {
+	return &dw.Drawer
+}
+
+func (dw *drawerImpl) DestBounds() image.Rectangle {
+	return image.Rectangle{Max: dw.DestSize()}
+}
+
+func (dw *drawerImpl) ConfigImageDefaultFormat(idx int, width int, height int, layers int) {
+	dw.ConfigImage(idx, vgpu.NewImageFormat(width, height, layers))
+}
+
+func (dw *drawerImpl) IsImageActive(idx int) bool {
+	tx := dw.GetImageVal(idx)
+	if tx == nil {
+		return false
+	}
+	return tx.Texture.IsActive()
+}
@@ -20,7 +20,6 @@ import (
 	"github.com/goki/gi/oswin/driver/internal/event"
 	"github.com/goki/gi/oswin/window"
 	"github.com/goki/ki/bitflag"
-	"github.com/goki/vgpu/vdraw"
 	"github.com/goki/vgpu/vgpu"
 
 	vk "github.com/goki/vulkan"
@@ -32,7 +31,7 @@ type windowImpl struct {
 	app            *appImpl
 	glw            *glfw.Window
 	Surface        *vgpu.Surface
-	Draw           vdraw.Drawer
+	Draw           drawerImpl
 	scrnName       string // last known screen name
 	runQueue       chan funcRun
 	publish        chan struct{}
@@ -55,7 +54,7 @@ func (w *windowImpl) Handle() interface{} {
 	return w.glw
 }
 
-func (w *windowImpl) Drawer() *vdraw.Drawer {
+func (w *windowImpl) Drawer() oswin.Drawer {
 	return &w.Draw
 }
 
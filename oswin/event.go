@@ -82,6 +82,10 @@ const (
 	// suitable for translation into keyboard commands, emacs-style etc
 	KeyChordEvent
 
+	// IMEEvent is for text composition by an input method editor (IME),
+	// with the uncommitted preedit text and the final committed text
+	IMEEvent
+
 	// TouchEvent is a generic touch-based event
 	TouchEvent
 
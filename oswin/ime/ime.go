@@ -0,0 +1,103 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ime defines events for text composition by an input method editor
+// (IME), as used for typing Chinese, Japanese, Korean and other languages
+// that have many more characters than keys.
+//
+// While composing, the IME sends a sequence of Preedit events with the
+// uncommitted "preedit" text, which the text widget in focus renders inline
+// at its cursor (underlined), and then a Commit event with the final text to
+// insert, or a Cancel event if the composition was abandoned.  The widget
+// reports the location of its caret back to the window using
+// oswin.Window.SetIMECaret, so the IME can position its candidate window
+// next to the text being composed.
+package ime
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/kit"
+)
+
+// ime.Event reports a change in the state of an IME text composition
+type Event struct {
+	oswin.EventBase
+
+	// Action is the composition phase: Start, Preedit, Commit or Cancel
+	Action Actions
+
+	// Text is the preedit text for Preedit, and the final text to insert
+	// for Commit -- it is empty for Start and Cancel
+	Text string
+
+	// Cursor is the caret position within the preedit Text, in runes
+	Cursor int
+
+	// SelStart and SelEnd are the range of the preedit Text, in runes, for
+	// the clause that is currently being converted, which is highlighted
+	// -- equal if there is no such clause
+	SelStart, SelEnd int
+}
+
+// Actions are the phases of an IME composition
+type Actions int32
+
+const (
+	// Start means that a new composition has started, with empty preedit text
+	Start Actions = iota
+
+	// Preedit means that the preedit text and / or cursor have changed
+	Preedit
+
+	// Commit means that the composition has finished, and Text is the
+	// final text to insert in place of the preedit text
+	Commit
+
+	// Cancel means that the composition was cancelled, and the preedit text
+	// should be removed
+	Cancel
+
+	ActionsN
+)
+
+//go:generate stringer -type=Actions
+
+var KiT_Actions = kit.Enums.AddEnum(ActionsN, kit.NotBitFlag, nil)
+
+/////////////////////////////
+// oswin.Event interface
+
+func (ev *Event) Type() oswin.EventType {
+	return oswin.IMEEvent
+}
+
+func (ev *Event) HasPos() bool {
+	return false
+}
+
+func (ev *Event) Pos() image.Point {
+	return image.ZP
+}
+
+func (ev *Event) OnFocus() bool {
+	return true
+}
+
+func (ev *Event) String() string {
+	return fmt.Sprintf("Type: %v Action: %v  Text: %q  Cursor: %v  Sel: %v-%v  Time: %v", ev.Type(), ev.Action, ev.Text, ev.Cursor, ev.SelStart, ev.SelEnd, ev.Time())
+}
+
+// check for interface implementation
+var _ oswin.Event = &Event{}
+
+// NewEvent returns a new ime.Event with given action, text and cursor,
+// initialized to the current time.
+func NewEvent(act Actions, text string, cursor int) *Event {
+	ev := &Event{Action: act, Text: text, Cursor: cursor}
+	ev.Init()
+	return ev
+}
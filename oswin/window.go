@@ -15,16 +15,16 @@ import (
 
 	"github.com/goki/ki/bitflag"
 	"github.com/goki/ki/kit"
-	"github.com/goki/vgpu/vdraw"
 )
 
 // Window is a double-buffered OS-specific hardware window.
 //
 // It provides basic GPU support functions, and is currently implemented
 // via Vulkan on top of glfw cross-platform window mgmt toolkit (see driver/vkos).
-// using the vgpu framework.
+// using the vgpu framework, or entirely in memory without any display or
+// GPU (see driver/offscreen).
 //
-// The Window maintains its own vdraw.Drawer drawing system for rendering
+// The Window maintains its own Drawer drawing system for rendering
 // bitmap images and filled regions onto the window surface.
 //
 // The base full-window image should be drawn with a Scale call first,
@@ -211,9 +211,18 @@ type Window interface {
 	// which can provide better control in a game environment (not avail on Mac).
 	SetCursorEnabled(enabled, raw bool)
 
+	// SetIMECaret sets the rectangle of the text caret of the widget in
+	// focus, in window pixel coordinates, which the input method editor (IME)
+	// uses to position its candidate window during text composition
+	// (see the ime package).
+	SetIMECaret(r image.Rectangle)
+
+	// IMECaret returns the last caret rectangle set by SetIMECaret.
+	IMECaret() image.Rectangle
+
 	// Drawer returns the drawing system attached to this window surface.
 	// This is typically used for high-performance rendering to the surface.
-	Drawer() *vdraw.Drawer
+	Drawer() Drawer
 
 	// SetDestroyGPUResourcesFunc sets the given function
 	// that will be called on the main thread just prior
@@ -241,6 +250,8 @@ type WindowBase struct {
 	// and the surface -- otherwise it is difficult to
 	// ensure that the proper ordering of destruction applies.
 	DestroyGPUfunc func()
+	// text caret rectangle for positioning the IME candidate window
+	IMECaretRect image.Rectangle
 }
 
 func (w WindowBase) Name() string {
@@ -295,6 +306,14 @@ func (w *WindowBase) SetDestroyGPUResourcesFunc(f func()) {
 	w.DestroyGPUfunc = f
 }
 
+func (w *WindowBase) SetIMECaret(r image.Rectangle) {
+	w.IMECaretRect = r
+}
+
+func (w *WindowBase) IMECaret() image.Rectangle {
+	return w.IMECaretRect
+}
+
 ////////////////////////////////////////////////////////////////////////////
 // WindowOptions
 
@@ -114,7 +114,7 @@ func (ic *Icon) Render2D() {
 		ic.FullRender2DTree()
 		return
 	}
-	if ic.NeedsReRender() {
+	if ic.NeedsReRender() || ic.Viewport.Render.RecordingVector() { // vector output needs the drawing
 		if ic.PushBounds() {
 			rs := &ic.Render
 			if ic.Fill {
@@ -398,6 +398,23 @@ func MakeDefaultIcons() IconSet {
 			// bx.Radius.Set(0.02, 0.02) // not rendering well at small sizes
 			iset[ic.Nm] = ic
 		}
+		{
+			ic := &Icon{}
+			ic.InitName(ic, "calendar")
+			ic.ViewBox.Size.Set(1, 1)
+			bx := AddNewRect(ic, "bx", 0.1, 0.15, 0.8, 0.75)
+			bx.SetProp("fill", "none")
+			bx.SetProp("stroke-width", units.NewPct(8))
+			hd := AddNewRect(ic, "hd", 0.1, 0.15, 0.8, 0.2)
+			hd.SetProp("stroke-width", units.NewPct(8))
+			rg := AddNewPath(ic, "rg", "M 0.3 0.05 .3 .25 M .7 .05 .7 .25")
+			rg.SetProp("fill", "none")
+			rg.SetProp("stroke-width", units.NewPct(10))
+			dy := AddNewPath(ic, "dy", "M 0.25 0.55 .35 .55 M .45 .55 .55 .55 M .65 .55 .75 .55 M .25 .72 .35 .72 M .45 .72 .55 .72")
+			dy.SetProp("fill", "none")
+			dy.SetProp("stroke-width", units.NewPct(10))
+			iset[ic.Nm] = ic
+		}
 		{
 			ic := &Icon{}
 			ic.InitName(ic, "circlebutton-on")
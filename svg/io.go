@@ -102,6 +102,24 @@ func (sv *SVG) ReadXML(reader io.Reader) error {
 	return err
 }
 
+// gradNames are the initial names of the gradients read from each
+// gradient element, prior to setting their id
+var gradNames = map[string]string{
+	"linearGradient": "lin-grad",
+	"radialGradient": "rad-grad",
+	"conicGradient":  "conic-grad",
+}
+
+// readGradHref sets the gradient to use the stops of the gradient with
+// given href name in the same parent, if it has already been read
+func readGradHref(grad *gi.Gradient, par ki.Ki, href string) {
+	nm := strings.TrimPrefix(href, "#")
+	if hrg, ok := par.ChildByName(nm, 0).(*gi.Gradient); ok {
+		grad.StopsName = nm
+		grad.Grad.CopyFrom(&hrg.Grad)
+	}
+}
+
 // UnmarshalXML unmarshals the svg using xml.Decoder
 func (sv *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 	updt := sv.UpdateStart()
@@ -491,52 +509,14 @@ func (sv *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						return err
 					}
 				}
-			case nm == "linearGradient":
-				grad := gi.AddNewGradient(curPar, "lin-grad")
+			case nm == "linearGradient" || nm == "radialGradient" || nm == "conicGradient":
+				grad := gi.AddNewGradient(curPar, gradNames[nm])
 				for _, attr := range se.Attr {
 					if gi.SetStdXMLAttr(grad, attr.Name.Local, attr.Value) {
 						continue
 					}
-					switch attr.Name.Local {
-					case "href":
-						nm := attr.Value
-						if strings.HasPrefix(nm, "#") {
-							nm = strings.TrimPrefix(nm, "#")
-						}
-						hr := curPar.ChildByName(nm, 0)
-						if hr != nil {
-							if hrg, ok := hr.(*gi.Gradient); ok {
-								grad.StopsName = nm
-								grad.Grad.CopyFrom(&hrg.Grad)
-								// fmt.Printf("successful href: %v\n", nm)
-							}
-						}
-					}
-				}
-				err = grad.Grad.UnmarshalXML(decoder, se)
-				if err != nil {
-					return err
-				}
-			case nm == "radialGradient":
-				grad := gi.AddNewGradient(curPar, "rad-grad")
-				for _, attr := range se.Attr {
-					if gi.SetStdXMLAttr(grad, attr.Name.Local, attr.Value) {
-						continue
-					}
-					switch attr.Name.Local {
-					case "href":
-						nm := attr.Value
-						if strings.HasPrefix(nm, "#") {
-							nm = strings.TrimPrefix(nm, "#")
-						}
-						hr := curPar.ChildByName(nm, 0)
-						if hr != nil {
-							if hrg, ok := hr.(*gi.Gradient); ok {
-								grad.StopsName = nm
-								grad.Grad.CopyFrom(&hrg.Grad)
-								// fmt.Printf("successful href: %v\n", nm)
-							}
-						}
+					if attr.Name.Local == "href" {
+						readGradHref(grad, curPar, attr.Value)
 					}
 				}
 				err = grad.Grad.UnmarshalXML(decoder, se)
@@ -724,6 +704,7 @@ func (sv *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 			case "use":
 			case "linearGradient":
 			case "radialGradient":
+			case "conicGradient":
 			default:
 				if curPar == sv.This() {
 					break
@@ -989,15 +970,21 @@ func SVGNodeXMLGrad(nd *gi.Gradient, name string, enc *XMLEncoder) {
 	XMLAddAttr(&me.Attr, "id", name)
 
 	linear := true
-	if cs.Source == gist.LinearGradient {
+	switch cs.Source {
+	case gist.LinearGradient:
+		me.Name.Local = "linearGradient"
+	case gist.ConicGradient:
+		// not in SVG, so written as a linearGradient with the same stops,
+		// with the conic points in gogi: attributes that are read back
 		me.Name.Local = "linearGradient"
-	} else {
+		me.Attr = append(me.Attr, cs.ConicXMLAttrs()...)
+	default:
 		linear = false
 		me.Name.Local = "radialGradient"
 	}
 
 	nilpts := gr.Points[0] == 0 && gr.Points[1] == 0 && gr.Points[2] == 1 && gr.Points[3] == 0 && gr.Points[4] == 0
-	if !nilpts {
+	if !nilpts && cs.Source != gist.ConicGradient { // conic uses the default linear points
 		if linear {
 			XMLAddAttr(&me.Attr, "x1", fmt.Sprintf("%g", gr.Points[0]))
 			XMLAddAttr(&me.Attr, "y1", fmt.Sprintf("%g", gr.Points[1]))
@@ -1080,6 +1067,7 @@ func (sv *SVG) MarshalXMLx(enc *XMLEncoder, se xml.StartElement) error {
 	XMLAddAttr(&me.Attr, "xmlns:inkscape", "http://www.inkscape.org/namespaces/inkscape")
 	XMLAddAttr(&me.Attr, "xmlns:sodipodi", "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd")
 	XMLAddAttr(&me.Attr, "xmlns:xlink", "http://www.w3.org/1999/xlink")
+	XMLAddAttr(&me.Attr, "xmlns:gogi", gist.ConicXMLSpace)
 	XMLAddAttr(&me.Attr, "xmlns", "http://www.w3.org/2000/svg")
 	enc.EncodeToken(me)
 
@@ -13,8 +13,8 @@ func _() {
 	// An "invalid array index" compiler error signifies that the constant values have changed.
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
-	_ = x[Rendering-35]
-	_ = x[SVGFlagsN-36]
+	_ = x[Rendering-37]
+	_ = x[SVGFlagsN-38]
 }
 
 const _SVGFlags_name = "RenderingSVGFlagsN"
@@ -22,9 +22,9 @@ const _SVGFlags_name = "RenderingSVGFlagsN"
 var _SVGFlags_index = [...]uint8{0, 9, 18}
 
 func (i SVGFlags) String() string {
-	i -= 35
+	i -= 37
 	if i < 0 || i >= SVGFlags(len(_SVGFlags_index)-1) {
-		return "SVGFlags(" + strconv.FormatInt(int64(i+35), 10) + ")"
+		return "SVGFlags(" + strconv.FormatInt(int64(i+37), 10) + ")"
 	}
 	return _SVGFlags_name[_SVGFlags_index[i]:_SVGFlags_index[i+1]]
 }
@@ -32,7 +32,7 @@ func (i SVGFlags) String() string {
 func StringToSVGFlags(s string) (SVGFlags, error) {
 	for i := 0; i < len(_SVGFlags_index)-1; i++ {
 		if s == _SVGFlags_name[_SVGFlags_index[i]:_SVGFlags_index[i+1]] {
-			return SVGFlags(i + 35), nil
+			return SVGFlags(i + 37), nil
 		}
 	}
 	return 0, errors.New("String: " + s + " is not a valid option for type: SVGFlags")
@@ -0,0 +1,327 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package units
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Calc is a parsed CSS math expression, as in calc(), min(), max() and
+// clamp(), which can mix values in different units, e.g., calc(100% - 2em).
+// It is evaluated in raw display pixels (dots) against a Context at ToDots
+// time.  Numbers without units can be used for multiplication and division,
+// e.g., calc(2 * 1em).  https://developer.mozilla.org/en-US/docs/Web/CSS/calc
+type Calc struct {
+	Op   string  `desc:"operation: empty for a leaf value, one of + - * / for arithmetic, or the function name: calc, min, max or clamp"`
+	Val  Value   `desc:"value, for a leaf"`
+	Num  bool    `desc:"true if this evaluates to a plain number without units, e.g., the 2 in 2 * 1em"`
+	Args []*Calc `desc:"the two operands for arithmetic, or the function arguments"`
+}
+
+// CalcFuncs are the names of the CSS math functions supported by Calc
+var CalcFuncs = []string{"calc", "min", "max", "clamp"}
+
+// IsCalc returns true if given string is a CSS math function expression
+// supported by Calc, e.g., calc(100% - 2em)
+func IsCalc(str string) bool {
+	str = strings.ToLower(strings.TrimSpace(str))
+	for _, fn := range CalcFuncs {
+		if strings.HasPrefix(str, fn+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCalc parses a CSS math function expression: calc(), min(), max()
+// or clamp(), which can contain nested expressions and functions.
+func ParseCalc(str string) (*Calc, error) {
+	cp := calcParser{str: strings.ToLower(strings.TrimSpace(str))}
+	if !IsCalc(cp.str) {
+		return nil, fmt.Errorf("units.ParseCalc: not a calc, min, max or clamp function: %q", str)
+	}
+	c, err := cp.factor()
+	if err == nil {
+		cp.space()
+		if cp.pos < len(cp.str) {
+			err = cp.errorf("unexpected text")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Eval returns the value of the expression in raw display pixels (dots),
+// or as a plain number if Num is true
+func (c *Calc) Eval(ctxt *Context) float32 {
+	switch c.Op {
+	case "":
+		if c.Num {
+			return c.Val.Val
+		}
+		return ctxt.ToDots(c.Val.Val, c.Val.Un)
+	case "+":
+		return c.Args[0].Eval(ctxt) + c.Args[1].Eval(ctxt)
+	case "-":
+		return c.Args[0].Eval(ctxt) - c.Args[1].Eval(ctxt)
+	case "*":
+		return c.Args[0].Eval(ctxt) * c.Args[1].Eval(ctxt)
+	case "/":
+		d := c.Args[1].Eval(ctxt)
+		if d == 0 {
+			return 0
+		}
+		return c.Args[0].Eval(ctxt) / d
+	case "calc":
+		return c.Args[0].Eval(ctxt)
+	case "min", "max":
+		v := c.Args[0].Eval(ctxt)
+		for _, a := range c.Args[1:] {
+			av := a.Eval(ctxt)
+			if (c.Op == "min" && av < v) || (c.Op == "max" && av > v) {
+				v = av
+			}
+		}
+		return v
+	case "clamp":
+		v := c.Args[1].Eval(ctxt)
+		if max := c.Args[2].Eval(ctxt); v > max {
+			v = max
+		}
+		if min := c.Args[0].Eval(ctxt); v < min {
+			v = min
+		}
+		return v
+	}
+	return 0
+}
+
+// ToDots returns the value of the expression in raw display pixels (dots),
+// treating a plain number as Px, as for other values without units
+func (c *Calc) ToDots(ctxt *Context) float32 {
+	v := c.Eval(ctxt)
+	if c.Num {
+		v = ctxt.ToDots(v, Px)
+	}
+	return v
+}
+
+// String returns the CSS string representation of the expression
+func (c *Calc) String() string {
+	switch c.Op {
+	case "":
+		if c.Num {
+			return strconv.FormatFloat(float64(c.Val.Val), 'g', -1, 32)
+		}
+		return c.Val.String()
+	case "+", "-", "*", "/":
+		return c.Args[0].operand(c.Op, false) + " " + c.Op + " " + c.Args[1].operand(c.Op, true)
+	}
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = a.String()
+	}
+	return c.Op + "(" + strings.Join(args, ", ") + ")"
+}
+
+// operand returns the string for this expression as an operand of given
+// arithmetic operator, on the right side or not, with parentheses if needed
+func (c *Calc) operand(op string, right bool) string {
+	str := c.String()
+	if c.Op == "" || len(c.Op) > 1 {
+		return str
+	}
+	lower := (op == "*" || op == "/") && (c.Op == "+" || c.Op == "-")
+	if lower || (right && (op == "-" || op == "/")) {
+		return "(" + str + ")"
+	}
+	return str
+}
+
+// calcParser is a recursive descent parser for Calc expressions
+type calcParser struct {
+	str string
+	pos int
+}
+
+func (cp *calcParser) errorf(msg string) error {
+	return fmt.Errorf("units.ParseCalc: %s at position %d in: %q", msg, cp.pos, cp.str)
+}
+
+func (cp *calcParser) space() {
+	for cp.pos < len(cp.str) && (cp.str[cp.pos] == ' ' || cp.str[cp.pos] == '\t') {
+		cp.pos++
+	}
+}
+
+// peek returns the next non-space character, or 0 at the end
+func (cp *calcParser) peek() byte {
+	cp.space()
+	if cp.pos >= len(cp.str) {
+		return 0
+	}
+	return cp.str[cp.pos]
+}
+
+// sum parses a sum or difference of products
+func (cp *calcParser) sum() (*Calc, error) {
+	c, err := cp.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := cp.peek()
+		if op != '+' && op != '-' {
+			return c, nil
+		}
+		cp.pos++
+		b, err := cp.product()
+		if err != nil {
+			return nil, err
+		}
+		if c.Num != b.Num {
+			return nil, cp.errorf("cannot add or subtract a number and a length")
+		}
+		c = &Calc{Op: string(op), Num: c.Num, Args: []*Calc{c, b}}
+	}
+}
+
+// product parses a product or quotient of factors
+func (cp *calcParser) product() (*Calc, error) {
+	c, err := cp.factor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := cp.peek()
+		if op != '*' && op != '/' {
+			return c, nil
+		}
+		cp.pos++
+		b, err := cp.factor()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case op == '*' && !c.Num && !b.Num:
+			return nil, cp.errorf("cannot multiply two lengths")
+		case op == '/' && !b.Num:
+			return nil, cp.errorf("cannot divide by a length")
+		}
+		c = &Calc{Op: string(op), Num: c.Num && b.Num, Args: []*Calc{c, b}}
+	}
+}
+
+// factor parses a value, a parenthesized expression or a function
+func (cp *calcParser) factor() (*Calc, error) {
+	ch := cp.peek()
+	switch {
+	case ch == 0:
+		return nil, cp.errorf("unexpected end")
+	case ch == '(':
+		cp.pos++
+		c, err := cp.sum()
+		if err != nil {
+			return nil, err
+		}
+		if cp.peek() != ')' {
+			return nil, cp.errorf("missing )")
+		}
+		cp.pos++
+		return c, nil
+	case ch >= 'a' && ch <= 'z':
+		return cp.function()
+	}
+	return cp.value()
+}
+
+// function parses one of the CalcFuncs and its arguments
+func (cp *calcParser) function() (*Calc, error) {
+	st := cp.pos
+	for cp.pos < len(cp.str) && cp.str[cp.pos] >= 'a' && cp.str[cp.pos] <= 'z' {
+		cp.pos++
+	}
+	c := &Calc{Op: cp.str[st:cp.pos]}
+	switch c.Op {
+	case "calc", "min", "max", "clamp":
+	default:
+		cp.pos = st
+		return nil, cp.errorf("unknown function: " + c.Op)
+	}
+	if cp.peek() != '(' {
+		return nil, cp.errorf("missing (")
+	}
+	cp.pos++
+	for {
+		a, err := cp.sum()
+		if err != nil {
+			return nil, err
+		}
+		if len(c.Args) > 0 && a.Num != c.Args[0].Num {
+			return nil, cp.errorf("cannot mix numbers and lengths in " + c.Op)
+		}
+		c.Args = append(c.Args, a)
+		ch := cp.peek()
+		cp.pos++
+		if ch == ')' {
+			break
+		}
+		if ch != ',' {
+			cp.pos--
+			return nil, cp.errorf("expected , or )")
+		}
+	}
+	switch {
+	case c.Op == "calc" && len(c.Args) != 1:
+		return nil, cp.errorf("calc requires 1 arg")
+	case c.Op == "clamp" && len(c.Args) != 3:
+		return nil, cp.errorf("clamp requires 3 args")
+	}
+	c.Num = c.Args[0].Num
+	return c, nil
+}
+
+// value parses a number with optional units, e.g., 2, 2.5em or 100%
+func (cp *calcParser) value() (*Calc, error) {
+	st := cp.pos
+	if ch := cp.str[cp.pos]; ch == '+' || ch == '-' {
+		cp.pos++
+	}
+	for cp.pos < len(cp.str) && (cp.str[cp.pos] >= '0' && cp.str[cp.pos] <= '9' || cp.str[cp.pos] == '.') {
+		cp.pos++
+	}
+	val, err := strconv.ParseFloat(cp.str[st:cp.pos], 32)
+	if err != nil {
+		cp.pos = st
+		return nil, cp.errorf("invalid number")
+	}
+	c := &Calc{Val: Value{Val: float32(val), Un: Px}}
+	ust := cp.pos
+	if cp.pos < len(cp.str) && cp.str[cp.pos] == '%' {
+		cp.pos++
+		c.Val.Un = Pct
+		return c, nil
+	}
+	for cp.pos < len(cp.str) && cp.str[cp.pos] >= 'a' && cp.str[cp.pos] <= 'z' {
+		cp.pos++
+	}
+	un := cp.str[ust:cp.pos]
+	if un == "" {
+		c.Num = true
+		return c, nil
+	}
+	for i, nm := range UnitNames {
+		if nm == un {
+			c.Val.Un = Units(i)
+			return c, nil
+		}
+	}
+	cp.pos = ust
+	return nil, cp.errorf("unknown units: " + un)
+}
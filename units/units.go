@@ -270,11 +270,14 @@ func (uc *Context) DotsToPx(val float32) float32 {
 ////////////////////////////////////////////////////////////////////////
 //   Value
 
-// Value and units, and converted value into raw pixels (dots in DPI)
+// Value and units, and converted value into raw pixels (dots in DPI).
+// If Calc is set, the value is instead computed from that CSS math
+// expression, e.g., calc(100% - 2em), at ToDots time, in Dot units.
 type Value struct {
 	Val  float32
 	Un   Units
 	Dots float32
+	Calc *Calc `json:",omitempty" xml:"-"`
 }
 
 var KiT_Value = kit.Types.AddType(&Value{}, ValueProps)
@@ -285,107 +288,121 @@ var ValueProps = ki.Props{
 
 // NewValue creates a new value with given units
 func NewValue(val float32, un Units) Value {
-	return Value{val, un, 0.0}
+	return Value{Val: val, Un: un}
 }
 
 // NewPx creates a new Px value
 func NewPx(val float32) Value {
-	return Value{val, Px, 0.0}
+	return Value{Val: val, Un: Px}
 }
 
 // NewEm creates a new Em value
 func NewEm(val float32) Value {
-	return Value{val, Em, 0.0}
+	return Value{Val: val, Un: Em}
 }
 
 // NewEx creates a new Ex value
 func NewEx(val float32) Value {
-	return Value{val, Ex, 0.0}
+	return Value{Val: val, Un: Ex}
 }
 
 // NewCh creates a new Ch value
 func NewCh(val float32) Value {
-	return Value{val, Ch, 0.0}
+	return Value{Val: val, Un: Ch}
 }
 
 // NewPt creates a new Pt value
 func NewPt(val float32) Value {
-	return Value{val, Pt, 0.0}
+	return Value{Val: val, Un: Pt}
 }
 
 // NewPct creates a new Pct value
 func NewPct(val float32) Value {
-	return Value{val, Pct, 0.0}
+	return Value{Val: val, Un: Pct}
 }
 
 // NewDp creates a new Dp value
 func NewDp(val float32) Value {
-	return Value{val, Dp, 0.0}
+	return Value{Val: val, Un: Dp}
 }
 
 // NewDot creates a new Dot value
 func NewDot(val float32) Value {
-	return Value{val, Dot, 0.0}
+	return Value{Val: val, Un: Dot}
 }
 
 // Set sets value and units of an existing value
 func (v *Value) Set(val float32, un Units) {
 	v.Val = val
 	v.Un = un
+	v.Calc = nil
 }
 
 // SetPx sets value in Px
 func (v *Value) SetPx(val float32) {
 	v.Val = val
 	v.Un = Px
+	v.Calc = nil
 }
 
 // SetEm sets value in Em
 func (v *Value) SetEm(val float32) {
 	v.Val = val
 	v.Un = Em
+	v.Calc = nil
 }
 
 // SetEx sets value in Ex
 func (v *Value) SetEx(val float32) {
 	v.Val = val
 	v.Un = Ex
+	v.Calc = nil
 }
 
 // SetCh sets value in Ch
 func (v *Value) SetCh(val float32) {
 	v.Val = val
 	v.Un = Ch
+	v.Calc = nil
 }
 
 // SetPt sets value in Pt
 func (v *Value) SetPt(val float32) {
 	v.Val = val
 	v.Un = Pt
+	v.Calc = nil
 }
 
 // SetPct sets value in Pct
 func (v *Value) SetPct(val float32) {
 	v.Val = val
 	v.Un = Pct
+	v.Calc = nil
 }
 
 // SetDp sets value in Dp
 func (v *Value) SetDp(val float32) {
 	v.Val = val
 	v.Un = Px
+	v.Calc = nil
 }
 
 // SetDot sets value in Dots directly
 func (v *Value) SetDot(val float32) {
 	v.Val = val
 	v.Un = Dot
+	v.Calc = nil
 	v.Dots = val
 }
 
 // ToDots converts value to raw display pixels (dots as in DPI), setting also
 // the Dots field
 func (v *Value) ToDots(ctxt *Context) float32 {
+	if v.Calc != nil {
+		v.Dots = v.Calc.ToDots(ctxt)
+		v.Val = v.Dots
+		return v.Dots
+	}
 	v.Dots = ctxt.ToDots(v.Val, v.Un)
 	return v.Dots
 }
@@ -399,22 +416,31 @@ func (v *Value) ToDotsFixed(ctxt *Context) fixed.Int26_6 {
 // Convert converts value to the given units, given unit context
 func (v *Value) Convert(to Units, ctxt *Context) Value {
 	dots := v.ToDots(ctxt)
-	return Value{dots / ctxt.ToDotsFactor(to), to, dots}
+	return Value{Val: dots / ctxt.ToDotsFactor(to), Un: to, Dots: dots}
 }
 
 // String implements the fmt.Stringer interface.
 func (v *Value) String() string {
+	if v.Calc != nil {
+		return v.Calc.String()
+	}
 	return fmt.Sprintf("%g%s", v.Val, UnitNames[v.Un])
 }
 
-// SetString sets value from a string
+// SetString sets value from a string, which can also be a CSS math
+// expression: calc(), min(), max() or clamp() -- see SetCalc
 func (v *Value) SetString(str string) {
+	if IsCalc(str) {
+		if err := v.SetCalc(str); err != nil {
+			log.Println(err)
+		}
+		return
+	}
 	trstr := strings.TrimSpace(strings.Replace(str, "%", "pct", -1))
 	sz := len(trstr)
 	if sz < 2 {
 		vc, _ := kit.ToFloat(str)
-		v.Val = float32(vc)
-		v.Un = Px
+		v.Set(float32(vc), Px)
 		return
 	}
 	var ends [4]string
@@ -445,6 +471,20 @@ func (v *Value) SetString(str string) {
 	v.Set(val, un)
 }
 
+// SetCalc sets value from a CSS math expression string: calc(), min(),
+// max() or clamp() (see Calc), which is evaluated at ToDots time
+func (v *Value) SetCalc(str string) error {
+	c, err := ParseCalc(str)
+	if err != nil {
+		return err
+	}
+	v.Val = 0
+	v.Un = Dot
+	v.Dots = 0
+	v.Calc = c
+	return nil
+}
+
 // StringToValue converts a string to a value representation.
 func StringToValue(str string) Value {
 	var v Value
@@ -31,3 +31,45 @@ func TestValCvt(t *testing.T) {
 		t.Errorf("strings don't match: %v != %v\n", s1, s2)
 	}
 }
+
+func TestCalc(t *testing.T) {
+	var ctxt Context
+	ctxt.Defaults()
+	ctxt.ElW = 200
+	tests := []struct {
+		str  string
+		dots float32
+		out  string
+	}{
+		{"calc(100% - 2em)", 176, "calc(100pct - 2em)"},
+		{"calc(2 * (10px + 5px))", 30, "calc(2 * (10px + 5px))"},
+		{"calc(1in / 4 - -2px)", 26, "calc(1in / 4 - -2px)"},
+		{"min(50%, 80px, 1in)", 80, "min(50pct, 80px, 1in)"},
+		{"max(10px, calc(1em + 1px))", 13, "max(10px, calc(1em + 1px))"},
+		{"clamp(10px, 50%, 60px)", 60, "clamp(10px, 50pct, 60px)"},
+		{"calc(3 + 4)", 7, "calc(3 + 4)"},
+	}
+	for _, tt := range tests {
+		v := StringToValue(tt.str)
+		if v.Calc == nil {
+			t.Errorf("%s: not parsed", tt.str)
+			continue
+		}
+		if d := v.ToDots(&ctxt); d != tt.dots {
+			t.Errorf("%s: dots %v != %v", tt.str, d, tt.dots)
+		}
+		if v.String() != tt.out {
+			t.Errorf("%s: string %v != %v", tt.str, v.String(), tt.out)
+		}
+	}
+	for _, str := range []string{"calc(1px * 2px)", "calc(1px + 2)", "calc(2 / 1px)", "clamp(1px, 2px)", "calc(1px", "calc(1foo)", "min()"} {
+		if _, err := ParseCalc(str); err == nil {
+			t.Errorf("%s: expected error", str)
+		}
+	}
+	v := StringToValue("calc(1px + 1px)")
+	v.SetPx(4)
+	if v.Calc != nil || v.ToDots(&ctxt) != 4 {
+		t.Errorf("SetPx should clear Calc")
+	}
+}